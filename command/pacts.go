@@ -0,0 +1,179 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+
+	"github.com/spf13/cobra"
+)
+
+var pactDir string
+var cleanBefore string
+var cleanProviders []string
+var cleanDryRun bool
+var statsFormat string
+
+var pactsCmd = &cobra.Command{
+	Use:   "pacts",
+	Short: "Manage the pact files in a directory",
+	Long:  "List, validate, clean up and report statistics of the pact files written by consumer tests",
+}
+
+var pactsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pact files",
+	Long:  "Lists each pact file with its pacticipants, specification version and interaction count",
+	Run: func(cmd *cobra.Command, args []string) {
+		setLogLevel(verbose, logLevel)
+
+		if err := listPacts(os.Stdout, pactDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+var pactsValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate pact files",
+	Long:  "Validates every pact file, such as before publishing, exiting with an error if any are invalid",
+	Run: func(cmd *cobra.Command, args []string) {
+		setLogLevel(verbose, logLevel)
+
+		if err := validatePacts(os.Stdout, pactDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+var pactsCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove stale pact files",
+	Long: `Removes stale pact files, such as those of renamed providers. A file is
+stale if it was last written before --before, or is for a provider not in
+--provider.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setLogLevel(verbose, logLevel)
+
+		if err := cleanPacts(os.Stdout, pactDir, cleanBefore, cleanProviders, cleanDryRun); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+var pactsStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report statistics of pact files",
+	Long: `Reports the interactions of each consumer, the matchers used, the endpoints
+by popularity and the average body sizes of the pact files, as text, json or
+csv.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setLogLevel(verbose, logLevel)
+
+		if err := statsPacts(os.Stdout, pactDir, statsFormat); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func listPacts(out io.Writer, dir string) error {
+	summaries, err := pactfile.List(dir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tCONSUMER\tPROVIDER\tSPECIFICATION\tINTERACTIONS\tMESSAGES")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", s.File, s.Consumer, s.Provider, s.SpecificationVersion, s.Interactions, s.Messages)
+	}
+
+	return w.Flush()
+}
+
+func validatePacts(out io.Writer, dir string) error {
+	problems, err := pactfile.Validate(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintln(out, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problem(s) in %s", len(problems), dir)
+	}
+	fmt.Fprintln(out, "all pact files in", dir, "are valid")
+
+	return nil
+}
+
+// cleanPacts removes the stale pact files. The before time may be RFC 3339
+// formatted or a duration ago e.g. "24h".
+func cleanPacts(out io.Writer, dir string, before string, providers []string, dryRun bool) error {
+	options := pactfile.CleanOptions{
+		Providers: providers,
+		DryRun:    dryRun,
+	}
+
+	if before != "" {
+		if d, err := time.ParseDuration(before); err == nil {
+			options.ModifiedBefore = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, before); err == nil {
+			options.ModifiedBefore = t
+		} else {
+			return fmt.Errorf("invalid --before '%s', expected a duration (e.g. 24h) or RFC 3339 time", before)
+		}
+	}
+
+	removed, err := pactfile.Clean(dir, options)
+	for _, file := range removed {
+		if dryRun {
+			fmt.Fprintln(out, "would remove", file)
+		} else {
+			fmt.Fprintln(out, "removed", file)
+		}
+	}
+
+	return err
+}
+
+func statsPacts(out io.Writer, dir string, format string) error {
+	pacts, err := pactfile.ReadAll(dir)
+	if err != nil {
+		return err
+	}
+	stats := pactfile.Stats(pacts...)
+
+	switch format {
+	case "", "text":
+		_, err = fmt.Fprint(out, stats)
+	case "json":
+		err = stats.WriteJSON(out)
+	case "csv":
+		err = stats.WriteCSV(out)
+	default:
+		err = fmt.Errorf("invalid --format '%s', expected text, json or csv", format)
+	}
+
+	return err
+}
+
+func init() {
+	pactsCmd.PersistentFlags().StringVarP(&pactDir, "dir", "d", "./pacts", "Directory containing the pact files")
+	pactsCleanCmd.Flags().StringVar(&cleanBefore, "before", "", "Remove files last written before this time (RFC 3339) or duration ago (e.g. 24h)")
+	pactsCleanCmd.Flags().StringSliceVar(&cleanProviders, "provider", nil, "The current providers, files for any other provider are removed")
+	pactsCleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "Report the stale files without removing them")
+	pactsStatsCmd.Flags().StringVar(&statsFormat, "format", "text", "The output format: text, json or csv")
+
+	pactsCmd.AddCommand(pactsListCmd, pactsValidateCmd, pactsCleanCmd, pactsStatsCmd)
+	RootCmd.AddCommand(pactsCmd)
+}
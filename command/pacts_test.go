@@ -0,0 +1,69 @@
+package command
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPact = `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "interactions": [{"description": "a request", "request": {"method": "GET", "path": "/"}, "response": {"status": 200}}],
+  "metadata": {"pactSpecification": {"version": "2.0.0"}}
+}`
+
+func TestPactsCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-go-command")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "billing-accounts.json"), []byte(testPact), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "billing-old.json"), []byte(strings.Replace(testPact, `"accounts"`, `"old"`, 1)), 0644)
+
+	var out bytes.Buffer
+	if err = listPacts(&out, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "billing   accounts  2.0.0          1") {
+		t.Fatalf("unexpected list output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err = validatePacts(&out, dir); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+
+	out.Reset()
+	if err = statsPacts(&out, dir, "csv"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "consumer_interactions,billing,2") {
+		t.Fatalf("unexpected stats output:\n%s", out.String())
+	}
+	if err = statsPacts(&out, dir, "xml"); err == nil {
+		t.Fatal("expected an invalid --format error")
+	}
+
+	out.Reset()
+	if err = cleanPacts(&out, dir, "", []string{"accounts"}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "removed "+filepath.Join(dir, "billing-old.json")) {
+		t.Fatalf("unexpected clean output:\n%s", out.String())
+	}
+
+	if err = cleanPacts(&out, dir, "yesterday", nil, true); err == nil {
+		t.Fatal("expected an invalid --before error")
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644)
+	out.Reset()
+	if err = validatePacts(&out, dir); err == nil {
+		t.Fatalf("expected a validation error, got:\n%s", out.String())
+	}
+}
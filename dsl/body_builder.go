@@ -0,0 +1,86 @@
+package dsl
+
+import (
+	"reflect"
+)
+
+// pactBodyBuilder converts a body, potentially containing nested Matchers,
+// into its example (generated) form along with the matching rules for the
+// body. Rule paths are built relative to the given root (e.g. "$.body") and
+// object keys are escaped as required, so keys containing '.', '@' or spaces
+// (common in JSON-LD and HAL documents) produce valid paths.
+func pactBodyBuilder(root RulePath, body interface{}) (interface{}, MatchingRules) {
+	rules := MatchingRules{}
+	example := buildBody(body, root, rules)
+
+	return example, rules
+}
+
+// buildBody recursively walks the body, recording rules for any matchers
+// found and returning the example value
+func buildBody(value interface{}, path RulePath, rules MatchingRules) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case described:
+		return buildBody(v.Matcher, path, rules)
+	case generated:
+		return buildBody(v.Matcher, path, rules)
+	case like:
+		rules.Add(path, TypeRule())
+		return buildBody(v.Contents, path, rules)
+	case eachLike:
+		min := v.Min
+		if min < 1 {
+			min = 1
+		}
+		rules.Add(path, MinTypeRule(v.Min))
+
+		example := make([]interface{}, min)
+		for i := range example {
+			example[i] = buildBody(v.Contents, path.AnyIndex(), rules)
+		}
+		return example
+	case term:
+		rules.Add(path, RegexRule(v.Data.Matcher.Regex.(string)))
+		return v.Data.Generate
+	case StructMatcher:
+		example := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			example[key] = buildBody(child, path.Key(key), rules)
+		}
+		return example
+	case S:
+		return string(v)
+	case String:
+		return string(v)
+	case []byte:
+		return string(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		example := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			example[key.String()] = buildBody(rv.MapIndex(key).Interface(), path.Key(key.String()), rules)
+		}
+		return example
+	case reflect.Slice, reflect.Array:
+		example := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			example[i] = buildBody(rv.Index(i).Interface(), path.Index(i), rules)
+		}
+		return example
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return buildBody(rv.Elem().Interface(), path, rules)
+	}
+
+	return value
+}
@@ -0,0 +1,187 @@
+package dsl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPactBodyBuilder_Matchers(t *testing.T) {
+	body := StructMatcher{
+		"name": Like("billy"),
+		"id":   Term("1234", `\d+`),
+		"tags": EachLike(Like("admin"), 2),
+		"address": StructMatcher{
+			"street": S("Main Street"),
+		},
+		"plain": "value",
+	}
+
+	example, rules := pactBodyBuilder(BodyPath(), body)
+
+	expectedExample := map[string]interface{}{
+		"name": "billy",
+		"id":   "1234",
+		"tags": []interface{}{"admin", "admin"},
+		"address": map[string]interface{}{
+			"street": "Main Street",
+		},
+		"plain": "value",
+	}
+
+	expectedRules := MatchingRules{
+		"$.body.name":    TypeRule(),
+		"$.body.id":      RegexRule(`\d+`),
+		"$.body.tags":    MinTypeRule(2),
+		"$.body.tags[*]": TypeRule(),
+	}
+
+	if !reflect.DeepEqual(example, expectedExample) {
+		t.Fatalf("want example %v, got %v", expectedExample, example)
+	}
+	if !reflect.DeepEqual(rules, expectedRules) {
+		t.Fatalf("want rules %v, got %v", expectedRules, rules)
+	}
+}
+
+func TestPactBodyBuilder_SpecialKeys(t *testing.T) {
+	body := map[string]interface{}{
+		"@context":   Like("https://schema.org"),
+		"first name": Like("billy"),
+		"_links": map[string]interface{}{
+			"self.href": Term("http://localhost/1", `^http`),
+		},
+		"items": []interface{}{
+			map[string]interface{}{"a.b": Like(1)},
+		},
+	}
+
+	_, rules := pactBodyBuilder(BodyPath(), body)
+
+	expectedRules := MatchingRules{
+		"$.body['@context']":         TypeRule(),
+		"$.body['first name']":       TypeRule(),
+		"$.body._links['self.href']": RegexRule(`^http`),
+		"$.body.items[0]['a.b']":     TypeRule(),
+	}
+
+	if !reflect.DeepEqual(rules, expectedRules) {
+		t.Fatalf("want rules %v, got %v", expectedRules, rules)
+	}
+
+	for path := range rules {
+		if _, err := ParseRulePath(path); err != nil {
+			t.Fatalf("generated an invalid path: %v", err)
+		}
+	}
+}
+
+func TestPactBodyBuilder_MatchStructTags(t *testing.T) {
+	type dto struct {
+		Context string `json:"@context"`
+		Dotted  int    `json:"a.b"`
+	}
+
+	_, rules := pactBodyBuilder(BodyPath(), Match(dto{}))
+
+	for _, path := range []string{"$.body['@context']", "$.body['a.b']"} {
+		if _, ok := rules[path]; !ok {
+			t.Fatalf("expected a rule for %s, got %v", path, rules)
+		}
+	}
+}
+
+func TestPactBodyBuilder_RootLevelBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		root        RulePath
+		body        interface{}
+		wantExample interface{}
+		wantRules   MatchingRules
+	}{
+		{
+			name:        "array of objects at the root",
+			root:        NewRulePath(),
+			body:        EachLike(StructMatcher{"id": Like(1)}, 1),
+			wantExample: []interface{}{map[string]interface{}{"id": 1}},
+			wantRules: MatchingRules{
+				"$":       MinTypeRule(1),
+				"$[*].id": TypeRule(),
+			},
+		},
+		{
+			name:        "array of primitives at the root",
+			root:        NewRulePath(),
+			body:        EachLike(Term("abc", `[a-z]+`), 2),
+			wantExample: []interface{}{"abc", "abc"},
+			wantRules: MatchingRules{
+				"$":    MinTypeRule(2),
+				"$[*]": RegexRule(`[a-z]+`),
+			},
+		},
+		{
+			name:        "array at the body root",
+			root:        BodyPath(),
+			body:        EachLike(Like("cat"), 1),
+			wantExample: []interface{}{"cat"},
+			wantRules: MatchingRules{
+				"$.body":    MinTypeRule(1),
+				"$.body[*]": TypeRule(),
+			},
+		},
+		{
+			name:        "plain array at the root",
+			root:        NewRulePath(),
+			body:        []interface{}{Like(1), 2},
+			wantExample: []interface{}{1, 2},
+			wantRules: MatchingRules{
+				"$[0]": TypeRule(),
+			},
+		},
+		{
+			name:        "matched primitive at the root",
+			root:        NewRulePath(),
+			body:        Like(42),
+			wantExample: 42,
+			wantRules: MatchingRules{
+				"$": TypeRule(),
+			},
+		},
+		{
+			name:        "bare string at the root",
+			root:        NewRulePath(),
+			body:        "foo",
+			wantExample: "foo",
+			wantRules:   MatchingRules{},
+		},
+		{
+			name:        "bare number at the root",
+			root:        BodyPath(),
+			body:        3.14,
+			wantExample: 3.14,
+			wantRules:   MatchingRules{},
+		},
+		{
+			name:        "slice of structs matched at the root",
+			root:        NewRulePath(),
+			body:        Match([]struct{ Name string }{}),
+			wantExample: []interface{}{map[string]interface{}{"Name": "string"}},
+			wantRules: MatchingRules{
+				"$":         MinTypeRule(1),
+				"$[*].Name": TypeRule(),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			example, rules := pactBodyBuilder(tt.root, tt.body)
+
+			if !reflect.DeepEqual(example, tt.wantExample) {
+				t.Fatalf("want example %v, got %v", tt.wantExample, example)
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Fatalf("want rules %v, got %v", tt.wantRules, rules)
+			}
+		})
+	}
+}
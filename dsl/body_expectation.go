@@ -0,0 +1,280 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// BodyExpectation is an explicit expectation of a request or response body,
+// used as its Body. A nil Body is the same as AnyBody, so that a body the
+// test forgot to specify is ignored; set ExplicitBodies on the Pact to
+// require one.
+type BodyExpectation string
+
+const (
+	// NoBody expects no body at all, nor a Content-Type header, e.g. a GET
+	// request or a 204 or 304 response
+	NoBody BodyExpectation = "no body"
+
+	// EmptyBody expects a body of zero length, which may still have a
+	// Content-Type header e.g. a 200 response with "Content-Length: 0"
+	EmptyBody BodyExpectation = "empty body"
+
+	// AnyBody ignores the body, whatever it contains
+	AnyBody BodyExpectation = "any body"
+)
+
+// MarshalJSON writes NoBody and EmptyBody as an empty body, and AnyBody as
+// no body, which is how they are recorded in the pact file
+func (b BodyExpectation) MarshalJSON() ([]byte, error) {
+	if b == AnyBody {
+		return []byte("null"), nil
+	}
+
+	return []byte(`""`), nil
+}
+
+// requestBodyExpectation is the expectation of the interaction's request
+// body. Without ExplicitBodies only NoBody and EmptyBody are enforced, with
+// it a GET or HEAD request without a body expects NoBody.
+func (i *Interaction) requestBodyExpectation() BodyExpectation {
+	if b, ok := i.Request.Body.(BodyExpectation); ok {
+		return b
+	}
+
+	method := strings.ToUpper(i.Request.Method)
+	if i.Request.Body == nil && i.explicitBodies && (method == http.MethodGet || method == http.MethodHead) {
+		return NoBody
+	}
+
+	return ""
+}
+
+// responseBodyExpectation is the expectation of the interaction's response
+// body
+func (i *Interaction) responseBodyExpectation() BodyExpectation {
+	b, _ := i.Response.Body.(BodyExpectation)
+
+	return b
+}
+
+// bodyExpectationErrors checks that bodies are explicit when required, and
+// that NoBody is not given a Content-Type. The response to a HEAD request
+// never has a body, but may have the Content-Type of the GET response.
+func (i *Interaction) bodyExpectationErrors() []FieldError {
+	var errs []FieldError
+
+	if i.explicitBodies {
+		if i.Request.Body == nil && i.requestBodyExpectation() == "" {
+			errs = append(errs, FieldError{Field: "request.body", Message: "is required, use NoBody, EmptyBody or AnyBody if there is none"})
+		}
+		if i.Response.Body == nil && !i.isHead() {
+			errs = append(errs, FieldError{Field: "response.body", Message: "is required, use NoBody, EmptyBody or AnyBody if there is none"})
+		}
+	}
+
+	if i.requestBodyExpectation() == NoBody && hasHeader(i.Request.Headers, "Content-Type") {
+		errs = append(errs, FieldError{Field: "request.headers.Content-Type", Message: "should not be set for a request with NoBody, use EmptyBody"})
+	}
+	if i.responseBodyExpectation() == NoBody && hasHeader(i.Response.Headers, "Content-Type") && !i.isHead() {
+		errs = append(errs, FieldError{Field: "response.headers.Content-Type", Message: "should not be set for a response with NoBody, use EmptyBody"})
+	}
+
+	return errs
+}
+
+// hasBody determines if the body is expected to have content
+func hasBody(body interface{}) bool {
+	switch body {
+	case nil, NoBody, EmptyBody, AnyBody:
+		return false
+	}
+
+	return true
+}
+
+func hasHeader(headers MapMatcher, name string) bool {
+	for existing := range headers {
+		if strings.EqualFold(existing, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withoutContentType removes the Content-Type from the default headers for
+// a body expecting NoBody
+func withoutContentType(body interface{}, defaults MapMatcher) MapMatcher {
+	if body != NoBody || !hasHeader(defaults, "Content-Type") {
+		return defaults
+	}
+
+	headers := MapMatcher{}
+	for name, value := range defaults {
+		if !strings.EqualFold(name, "Content-Type") {
+			headers[name] = value
+		}
+	}
+
+	return headers
+}
+
+// emptyBodySides are the sides ("request" and/or "response") of the
+// interaction recorded with an empty body. The response to a HEAD request
+// is left without a body, as the verifier receives none.
+func (i *Interaction) emptyBodySides() []string {
+	var sides []string
+	if b := i.requestBodyExpectation(); b == NoBody || b == EmptyBody {
+		sides = append(sides, "request")
+	}
+	if b := i.responseBodyExpectation(); (b == NoBody || b == EmptyBody) && !i.isHead() {
+		sides = append(sides, "response")
+	}
+
+	return sides
+}
+
+// clearBodyExpectations removes the body expectations before the interaction
+// is registered with the mock server. The mock server then ignores the body,
+// with requests checked by the mock server proxy instead, and they are
+// recorded as an empty body once the pact file is written.
+func (i *Interaction) clearBodyExpectations() {
+	if _, ok := i.Request.Body.(BodyExpectation); ok {
+		i.Request.Body = nil
+	}
+	if _, ok := i.Response.Body.(BodyExpectation); ok {
+		i.Response.Body = nil
+	}
+}
+
+// writeEmptyBodies records an empty body for the sides of each interaction,
+// keyed by description, expecting NoBody or EmptyBody
+func writeEmptyBodies(file string, emptyBodies map[string][]string) error {
+	if len(emptyBodies) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		for _, side := range emptyBodies[description] {
+			if part, ok := interaction[side].(map[string]interface{}); ok {
+				part["body"] = ""
+			}
+		}
+	})
+}
+
+// bodyRule is the body expected of requests with a method and path
+type bodyRule struct {
+	expectation BodyExpectation
+	description string
+}
+
+// bodyChecker rejects requests with a body (or Content-Type) for
+// interactions expecting NoBody or EmptyBody, which the mock server would
+// otherwise accept
+type bodyChecker struct {
+	mu sync.Mutex
+
+	// rules are keyed by method and path. Requests for interactions with
+	// differing expectations are not checked.
+	rules map[string]bodyRule
+}
+
+func newBodyChecker() *bodyChecker {
+	return &bodyChecker{rules: make(map[string]bodyRule)}
+}
+
+// register records the request body expected by the interaction
+func (c *bodyChecker) register(i *Interaction) {
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		if b := i.requestBodyExpectation(); b == NoBody || b == EmptyBody {
+			log.Printf("[WARN] interaction '%s' expects %s but its path is not a plain string, it will not be checked\n", i.Description, b)
+		}
+		return
+	}
+
+	key := negotiationKey(i.Request.Method, path)
+	rule := bodyRule{expectation: i.requestBodyExpectation(), description: i.Description}
+
+	c.mu.Lock()
+	if existing, ok := c.rules[key]; ok && existing.expectation != rule.expectation {
+		rule.expectation = ""
+	}
+	c.rules[key] = rule
+	c.mu.Unlock()
+}
+
+// reset forgets all registered expectations
+func (c *bodyChecker) reset() {
+	c.mu.Lock()
+	c.rules = make(map[string]bodyRule)
+	c.mu.Unlock()
+}
+
+// middleware answers requests that don't meet the expectation with the
+// mock server's response for a request matching no interaction
+func (c *bodyChecker) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		c.mu.Lock()
+		rule := c.rules[negotiationKey(r.Method, r.URL.Path)]
+		c.mu.Unlock()
+
+		if rule.expectation != NoBody && rule.expectation != EmptyBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			if body, err = ioutil.ReadAll(r.Body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body.Close()
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		diff := map[string]interface{}{"description": rule.description}
+		if len(body) > 0 {
+			diff["body"] = map[string]interface{}{"EXPECTED": "", "ACTUAL": string(body)}
+		}
+		if contentType := r.Header.Get("Content-Type"); rule.expectation == NoBody && contentType != "" {
+			diff["headers"] = map[string]interface{}{
+				"Content-Type": map[string]interface{}{"EXPECTED": nil, "ACTUAL": contentType},
+			}
+		}
+		if len(diff) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Printf("[WARN] %s %s expected %s\n", r.Method, r.URL.Path, rule.expectation)
+		response, err := json.Marshal(map[string]interface{}{
+			"message":           fmt.Sprintf("No interaction found for %s %s", r.Method, r.URL.Path),
+			"interaction_diffs": []interface{}{diff},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(response) // nolint:errcheck
+	})
+}
@@ -0,0 +1,244 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestBodyExpectation_MarshalJSON(t *testing.T) {
+	for b, want := range map[BodyExpectation]string{NoBody: `""`, EmptyBody: `""`, AnyBody: `null`} {
+		got, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("want %s for %s, got %s", want, b, got)
+		}
+	}
+}
+
+func TestInteraction_ValidateBodyExpectations(t *testing.T) {
+	i := (&Interaction{}).
+		UponReceiving("a request to delete a user").
+		WithRequest(Request{Method: "DELETE", Path: String("/users/1"), Body: NoBody}).
+		WillRespondWith(Response{Status: 204, Body: NoBody})
+
+	if err := i.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	i.Request.Headers = MapMatcher{"content-type": String("application/json")}
+	if err := i.Validate(); err == nil || !strings.Contains(err.Error(), "request.headers.Content-Type: should not be set for a request with NoBody, use EmptyBody") {
+		t.Fatalf("expected a Content-Type with NoBody to be rejected, got %v", err)
+	}
+
+	i.Request.Body = EmptyBody
+	if err := i.Validate(); err != nil {
+		t.Fatalf("expected a Content-Type with EmptyBody to be allowed, got %v", err)
+	}
+}
+
+func TestInteraction_ValidateExplicitBodies(t *testing.T) {
+	i := (&Interaction{explicitBodies: true}).
+		UponReceiving("a request to create a user").
+		WithRequest(Request{Method: "POST", Path: String("/users")}).
+		WillRespondWith(Response{Status: 201})
+
+	err := i.Validate()
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	want := []FieldError{
+		{Field: "request.body", Message: "is required, use NoBody, EmptyBody or AnyBody if there is none"},
+		{Field: "response.body", Message: "is required, use NoBody, EmptyBody or AnyBody if there is none"},
+	}
+	if len(validationErr.Errors) != len(want) || validationErr.Errors[0] != want[0] || validationErr.Errors[1] != want[1] {
+		t.Fatalf("want %v, got %v", want, validationErr.Errors)
+	}
+
+	i.Request.Body = AnyBody
+	i.Response.Body = EmptyBody
+	if err = i.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	i.Request = Request{Method: "GET", Path: String("/users")}
+	if err = i.Validate(); err != nil {
+		t.Fatalf("expected a GET request without a body to expect NoBody, got %v", err)
+	}
+	if i.requestBodyExpectation() != NoBody {
+		t.Fatalf("want %s, got %q", NoBody, i.requestBodyExpectation())
+	}
+}
+
+func TestBodyChecker_middleware(t *testing.T) {
+	c := newBodyChecker()
+	c.register((&Interaction{}).
+		UponReceiving("a request to delete a user").
+		WithRequest(Request{Method: "DELETE", Path: String("/users/1"), Body: NoBody}))
+	c.register((&Interaction{}).
+		UponReceiving("a request to ping").
+		WithRequest(Request{Method: "POST", Path: String("/ping"), Body: EmptyBody}))
+	c.register((&Interaction{}).
+		UponReceiving("a request to create a user").
+		WithRequest(Request{Method: "POST", Path: String("/users"), Body: map[string]string{"name": "billy"}}))
+
+	var forwarded bool
+	handler := c.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = true
+	}))
+
+	tests := []struct {
+		method, path, contentType, body string
+		forwarded                       bool
+	}{
+		{"DELETE", "/users/1", "", "", true},
+		{"DELETE", "/users/1", "", `{"force":true}`, false},
+		{"DELETE", "/users/1", "application/json", "", false},
+		{"POST", "/ping", "application/json", "", true},
+		{"POST", "/ping", "text/plain", "hello", false},
+		{"POST", "/users", "application/json", `{"name":"billy"}`, true},
+	}
+	for _, test := range tests {
+		forwarded = false
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		if forwarded != test.forwarded {
+			t.Fatalf("%s %s with %q: want forwarded %v, got %v", test.method, test.path, test.body, test.forwarded, forwarded)
+		}
+		if !forwarded && res.Code != http.StatusInternalServerError {
+			t.Fatalf("want status 500, got %d", res.Code)
+		}
+	}
+
+	c.reset()
+	forwarded = false
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/users/1", strings.NewReader("body")))
+	if !forwarded {
+		t.Fatal("expected requests to be forwarded after reset")
+	}
+}
+
+func TestBodyChecker_recordsMismatches(t *testing.T) {
+	c := newBodyChecker()
+	c.register((&Interaction{}).
+		UponReceiving("a request to delete a user").
+		WithRequest(Request{Method: "DELETE", Path: String("/users/1"), Body: NoBody}))
+
+	m := newMismatchRecorder()
+	handler := m.middleware(c.middleware(http.NotFoundHandler()))
+	req := httptest.NewRequest("DELETE", "/users/1", strings.NewReader("force"))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	mismatches := m.take()
+	if len(mismatches) != 1 || mismatches[0].Description != "a request to delete a user" {
+		t.Fatalf("expected a mismatch for the interaction, got %+v", mismatches)
+	}
+	want := []types.Mismatch{
+		types.BodyMismatch{Path: "$", Expected: "", Actual: "force", Rule: "equality"},
+		types.HeaderMismatch{Key: "Content-Type", Expected: "", Actual: "text/plain", Rule: "equality"},
+	}
+	if len(mismatches[0].Mismatches) != len(want) {
+		t.Fatalf("want %v, got %v", want, mismatches[0].Mismatches)
+	}
+	for n := range want {
+		if mismatches[0].Mismatches[n] != want[n] {
+			t.Fatalf("want %v, got %v", want[n], mismatches[0].Mismatches[n])
+		}
+	}
+}
+
+func TestBodyChecker_conflictingInteractions(t *testing.T) {
+	c := newBodyChecker()
+	c.register((&Interaction{}).WithRequest(Request{Method: "POST", Path: String("/users"), Body: NoBody}))
+	c.register((&Interaction{}).WithRequest(Request{Method: "POST", Path: String("/users"), Body: map[string]string{"name": "billy"}}))
+
+	var forwarded bool
+	handler := c.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = true
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"billy"}`)))
+	if !forwarded {
+		t.Fatal("expected requests for interactions with differing expectations to be left to the mock server")
+	}
+}
+
+func TestWriteEmptyBodies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-empty-bodies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "consumer-provider.json")
+	ioutil.WriteFile(file, []byte(`{"interactions":[
+		{"description":"a","request":{"method":"DELETE","path":"/users/1"},"response":{"status":204}},
+		{"description":"b","request":{"method":"GET","path":"/users/1"},"response":{"status":200}}
+	]}`), 0644)
+
+	if err = writeEmptyBodies(file, map[string][]string{"a": {"request", "response"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := ioutil.ReadFile(file)
+	var pact struct {
+		Interactions []struct {
+			Request  map[string]json.RawMessage `json:"request"`
+			Response map[string]json.RawMessage `json:"response"`
+		} `json:"interactions"`
+	}
+	json.Unmarshal(content, &pact)
+
+	if string(pact.Interactions[0].Request["body"]) != `""` || string(pact.Interactions[0].Response["body"]) != `""` {
+		t.Fatalf("expected empty bodies to be recorded, got %s", content)
+	}
+	if _, ok := pact.Interactions[1].Response["body"]; ok {
+		t.Fatalf("expected other interactions to be untouched, got %s", content)
+	}
+}
+
+func TestPact_VerifyBodyExpectations(t *testing.T) {
+	ms := setupMockServer(true, t)
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:                 &types.MockServer{Port: getPort(ms.URL)},
+		Consumer:               "My Consumer",
+		Provider:               "My Provider",
+		DefaultResponseHeaders: MapMatcher{"Content-Type": String("application/json")},
+	}
+	pact.AddInteraction().
+		UponReceiving("a request to delete a user").
+		WithRequest(Request{Method: "DELETE", Path: String("/users/1"), Body: NoBody}).
+		WillRespondWith(Response{Status: 204, Body: NoBody})
+
+	interaction := pact.Interactions[0]
+	if err := pact.Verify(func() error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if interaction.Request.Body != nil || interaction.Response.Body != nil {
+		t.Fatalf("expected body expectations to be left to the proxy, got %v and %v", interaction.Request.Body, interaction.Response.Body)
+	}
+	if _, ok := interaction.Response.Headers["Content-Type"]; ok {
+		t.Fatal("expected the default Content-Type not to be added to a response with NoBody")
+	}
+	if sides := pact.emptyBodies["a request to delete a user"]; len(sides) != 2 {
+		t.Fatalf("expected both sides to be recorded with an empty body, got %v", sides)
+	}
+}
@@ -0,0 +1,252 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// MatchBody compares an actual JSON document with an expected body built
+// with matchers (Like, EachLike, Term, StructMatcher etc.), in the same
+// manner as the mock server and provider verifier: objects may have
+// unexpected keys, Like and EachLike match their contents by type, and Term
+// by regular expression. It returns each difference found, or none if the
+// body matches.
+//
+// JSON and MessagePack body builders are not supported.
+func MatchBody(expected interface{}, actual []byte) ([]types.BodyMismatch, error) {
+	actualBody, err := decodeJSON(actual)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the actual body: %v", err)
+	}
+
+	m := &bodyMatcher{}
+	if err = m.match(expected, actualBody, NewRulePath(), false); err != nil {
+		return nil, err
+	}
+
+	return m.mismatches, nil
+}
+
+// bodyMatcher collects the mismatches found walking an expected body, in
+// the same manner as collectDescriptions
+type bodyMatcher struct {
+	mismatches []types.BodyMismatch
+}
+
+func (m *bodyMatcher) mismatch(path RulePath, expected, actual interface{}, rule string, format string, args ...interface{}) {
+	m.mismatches = append(m.mismatches, types.BodyMismatch{
+		Path:     path.String(),
+		Expected: expected,
+		Actual:   actual,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// match compares the actual value with the expected one, by type if within
+// a Like or EachLike
+func (m *bodyMatcher) match(expected interface{}, actual interface{}, path RulePath, byType bool) error {
+	switch e := expected.(type) {
+	case described:
+		return m.match(e.Matcher, actual, path, byType)
+	case generated:
+		return m.match(e.Matcher, actual, path, byType)
+	case like:
+		return m.match(e.Contents, actual, path, true)
+	case eachLike:
+		items, ok := actual.([]interface{})
+		if !ok {
+			m.mismatch(path, nil, actual, "type", "Expected an array but got %s", describeJSON(actual))
+			return nil
+		}
+		if len(items) < e.Min {
+			m.mismatch(path, nil, actual, "type", "Expected an array with at least %d elements but got %d", e.Min, len(items))
+		}
+		for i, item := range items {
+			if err := m.match(e.Contents, item, path.Index(i), true); err != nil {
+				return err
+			}
+		}
+		return nil
+	case term:
+		regex, _ := e.Data.Matcher.Regex.(string)
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression at %s: %v", path, err)
+		}
+		if s, ok := actual.(string); !ok || !re.MatchString(s) {
+			m.mismatch(path, regex, actual, "regex", "Expected a string matching /%s/ but got %s", regex, describeJSON(actual))
+		}
+		return nil
+	case StructMatcher:
+		return m.matchObject(map[string]interface{}(e), actual, path, byType)
+	case String:
+		return m.matchValue(string(e), actual, path, byType)
+	case S:
+		return m.matchValue(string(e), actual, path, byType)
+	case *JSONBodyBuilder, *MsgPackBodyBuilder:
+		return fmt.Errorf("%T bodies are not supported", expected)
+	case nil:
+		return m.matchValue(nil, actual, path, byType)
+	}
+
+	rv := reflect.ValueOf(expected)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		object := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			object[key.String()] = rv.MapIndex(key).Interface()
+		}
+		return m.matchObject(object, actual, path, byType)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return m.matchArray(items, actual, path, byType)
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return m.matchValue(nil, actual, path, byType)
+		}
+		return m.match(rv.Elem().Interface(), actual, path, byType)
+	}
+
+	// Anything else (e.g. a struct) is compared in its JSON form
+	content, err := json.Marshal(expected)
+	if err != nil {
+		return fmt.Errorf("unable to serialise the expected body at %s: %v", path, err)
+	}
+	value, err := decodeJSON(content)
+	if err != nil {
+		return fmt.Errorf("unable to serialise the expected body at %s: %v", path, err)
+	}
+	if _, ok := value.(map[string]interface{}); ok {
+		return m.match(value, actual, path, byType)
+	}
+	if _, ok := value.([]interface{}); ok {
+		return m.match(value, actual, path, byType)
+	}
+
+	return m.matchValue(value, actual, path, byType)
+}
+
+// matchObject requires each expected key, allowing unexpected keys
+func (m *bodyMatcher) matchObject(expected map[string]interface{}, actual interface{}, path RulePath, byType bool) error {
+	object, ok := actual.(map[string]interface{})
+	if !ok {
+		m.mismatch(path, nil, actual, "type", "Expected an object but got %s", describeJSON(actual))
+		return nil
+	}
+
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := object[key]
+		if !ok {
+			m.mismatch(path.Key(key), nil, nil, "", "Could not find key %q", key)
+			continue
+		}
+		if err := m.match(expected[key], value, path.Key(key), byType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchArray requires the same number of elements
+func (m *bodyMatcher) matchArray(expected []interface{}, actual interface{}, path RulePath, byType bool) error {
+	items, ok := actual.([]interface{})
+	if !ok {
+		m.mismatch(path, nil, actual, "type", "Expected an array but got %s", describeJSON(actual))
+		return nil
+	}
+	if len(items) != len(expected) {
+		m.mismatch(path, nil, actual, "", "Expected an array with %d elements but got %d", len(expected), len(items))
+	}
+
+	for i := 0; i < len(expected) && i < len(items); i++ {
+		if err := m.match(expected[i], items[i], path.Index(i), byType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchValue compares a plain value, by type or by equality
+func (m *bodyMatcher) matchValue(expected interface{}, actual interface{}, path RulePath, byType bool) error {
+	content, err := json.Marshal(expected)
+	if err != nil {
+		return fmt.Errorf("unable to serialise the expected body at %s: %v", path, err)
+	}
+	value, err := decodeJSON(content)
+	if err != nil {
+		return fmt.Errorf("unable to serialise the expected body at %s: %v", path, err)
+	}
+
+	switch {
+	case byType && jsonType(value) != jsonType(actual):
+		m.mismatch(path, value, actual, "type", "Expected %s but got %s", describeJSON(value), describeJSON(actual))
+	case !byType && !equalJSON(value, actual):
+		m.mismatch(path, value, actual, "equality", "Expected %s but got %s", jsonString(value), jsonString(actual))
+	}
+
+	return nil
+}
+
+// jsonType is the JSON type of a decoded value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// describeJSON describes a decoded value and its type e.g. `a string ("a")`
+func describeJSON(value interface{}) string {
+	switch t := jsonType(value); t {
+	case "null":
+		return "null"
+	case "array", "object":
+		return "an " + t
+	default:
+		return fmt.Sprintf("a %s (%s)", t, jsonString(value))
+	}
+}
+
+func jsonString(value interface{}) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(content)
+}
@@ -0,0 +1,124 @@
+package dsl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestMatchBody(t *testing.T) {
+	expected := StructMatcher{
+		"id":      Like(1).Describe("account id"),
+		"email":   Term("jane@example.com", `^\S+@\S+$`),
+		"status":  "active",
+		"tags":    EachLike("admin", 1),
+		"address": Like(map[string]interface{}{"city": "London", "lines": []string{"1 High St"}}),
+	}
+
+	mismatches, err := MatchBody(expected, []byte(`{
+		"id": 42,
+		"email": "joe@example.com",
+		"status": "active",
+		"tags": ["admin", "billing"],
+		"address": {"city": "Paris", "lines": ["2 Rue"], "country": "FR"},
+		"unexpected": true
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("expected the body to match, got %v", mismatches)
+	}
+}
+
+func TestMatchBody_Mismatches(t *testing.T) {
+	expected := map[string]interface{}{
+		"id":     Like(1),
+		"email":  Term("jane@example.com", `^\S+@\S+$`),
+		"status": "active",
+		"tags":   EachLike(Like("admin"), 2),
+		"items":  []interface{}{1, 2},
+		"name":   "jane",
+	}
+
+	mismatches, err := MatchBody(expected, []byte(`{
+		"id": "42",
+		"email": "not an email",
+		"status": "closed",
+		"tags": [1],
+		"items": [1]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []types.BodyMismatch{
+		{Path: "$.email", Expected: `^\S+@\S+$`, Actual: "not an email", Rule: "regex", Message: `Expected a string matching /^\S+@\S+$/ but got a string ("not an email")`},
+		{Path: "$.id", Expected: float64(1), Actual: "42", Rule: "type", Message: `Expected a number (1) but got a string ("42")`},
+		{Path: "$.items", Actual: []interface{}{float64(1)}, Message: "Expected an array with 2 elements but got 1"},
+		{Path: "$.name", Message: `Could not find key "name"`},
+		{Path: "$.status", Expected: "active", Actual: "closed", Rule: "equality", Message: `Expected "active" but got "closed"`},
+		{Path: "$.tags", Actual: []interface{}{float64(1)}, Rule: "type", Message: "Expected an array with at least 2 elements but got 1"},
+		{Path: "$.tags[0]", Expected: "admin", Actual: float64(1), Rule: "type", Message: `Expected a string ("admin") but got a number (1)`},
+	}
+	if len(mismatches) != len(want) {
+		t.Fatalf("want %v, got %v", want, mismatches)
+	}
+	for n := range want {
+		if mismatches[n].String() != want[n].String() || mismatches[n].Rule != want[n].Rule {
+			t.Fatalf("want %v, got %v", want[n], mismatches[n])
+		}
+	}
+}
+
+func TestMatchBody_Match(t *testing.T) {
+	type user struct {
+		ID    int    `json:"id"`
+		Email string `json:"email" pact:"example=jane@example.com,regex=^\\S+@\\S+$"`
+	}
+
+	mismatches, err := MatchBody(Match(user{}), []byte(`{"id": 7, "email": "joe@example.com"}`))
+	if err != nil || len(mismatches) != 0 {
+		t.Fatalf("expected the body to match, got %v and %v", mismatches, err)
+	}
+
+	mismatches, err = MatchBody(Match(user{}), []byte(`{"id": "7", "email": "joe"}`))
+	if err != nil || len(mismatches) != 2 {
+		t.Fatalf("expected two mismatches, got %v and %v", mismatches, err)
+	}
+}
+
+func TestMatchBody_LargeNumbers(t *testing.T) {
+	expected := map[string]interface{}{
+		"id":    int64(9007199254740993),
+		"total": json.Number("12345678901234567890.123456789"),
+		"price": 1.5,
+		"count": Like(int64(1)),
+	}
+
+	mismatches, err := MatchBody(expected, []byte(`{"id": 9007199254740993, "total": 12345678901234567890.123456789, "price": 1.50, "count": 9223372036854775807}`))
+	if err != nil || len(mismatches) != 0 {
+		t.Fatalf("expected the body to match, got %v and %v", mismatches, err)
+	}
+
+	mismatches, err = MatchBody(expected, []byte(`{"id": 9007199254740992, "total": 12345678901234567890.123456788, "price": 1.5, "count": 1}`))
+	if err != nil || len(mismatches) != 2 {
+		t.Fatalf("expected two mismatches, got %v and %v", mismatches, err)
+	}
+	if mismatches[0].Path != "$.id" || mismatches[0].Message != "Expected 9007199254740993 but got 9007199254740992" {
+		t.Fatalf("expected the mismatch to show the exact numbers, got %+v", mismatches[0])
+	}
+}
+
+func TestMatchBody_Errors(t *testing.T) {
+	if _, err := MatchBody(Like(1), []byte(`{`)); err == nil {
+		t.Fatal("expected an error for an invalid actual body")
+	}
+	if _, err := MatchBody(JSONBody([]byte(`{}`)), []byte(`{}`)); err == nil {
+		t.Fatal("expected an error for an unsupported body")
+	}
+	if _, err := MatchBody(Term("a", "("), []byte(`"a"`)); err == nil {
+		t.Fatal("expected an error for an invalid regular expression")
+	}
+}
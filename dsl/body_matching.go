@@ -0,0 +1,365 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// BodyMatching controls whether JSON bodies may contain keys the interaction
+// doesn't expect. By default, following the Pact convention, unexpected keys
+// are rejected in requests and allowed in responses.
+type BodyMatching string
+
+const (
+	// StrictBodyMatching rejects unexpected keys in both the request body
+	// (by the mock server) and the response body (by the verifier)
+	StrictBodyMatching BodyMatching = "strict"
+
+	// PostelBodyMatching allows unexpected keys in both the request body and
+	// the response body ("be liberal in what you accept")
+	PostelBodyMatching BodyMatching = "postel"
+)
+
+// WithBodyMatching sets whether unexpected keys in the JSON request and
+// response bodies of the interaction are allowed (PostelBodyMatching) or
+// rejected (StrictBodyMatching). It is written to the pact file, so that the
+// verifier checks provider responses in the same way.
+func (i *Interaction) WithBodyMatching(matching BodyMatching) *Interaction {
+	i.bodyMatching = matching
+
+	return i
+}
+
+// bodyMatchingErrors checks the body matching is known
+func (i *Interaction) bodyMatchingErrors() []FieldError {
+	switch i.bodyMatching {
+	case "", StrictBodyMatching, PostelBodyMatching:
+		return nil
+	}
+
+	return []FieldError{{Field: "bodyMatching", Message: fmt.Sprintf("%q is not one of %q or %q", i.bodyMatching, StrictBodyMatching, PostelBodyMatching)}}
+}
+
+// writeBodyMatching records the body matching of each interaction, keyed by
+// description, in the pact file
+func writeBodyMatching(file string, matching map[string]BodyMatching) error {
+	if len(matching) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		if m, ok := matching[description]; ok {
+			interaction["bodyMatching"] = string(m)
+		}
+	})
+}
+
+// unexpectedKeys returns the path of each key of the actual JSON value not
+// in the expected value. Array elements are compared with the expected
+// element at the same index, or the last if there are fewer.
+func unexpectedKeys(path string, actual interface{}, expected interface{}) []string {
+	var keys []string
+
+	switch a := actual.(type) {
+	case map[string]interface{}:
+		e, ok := expected.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for key, value := range a {
+			field := path + "." + key
+			if expectedValue, ok := e[key]; ok {
+				keys = append(keys, unexpectedKeys(field, value, expectedValue)...)
+			} else {
+				keys = append(keys, field)
+			}
+		}
+	case []interface{}:
+		e, ok := expected.([]interface{})
+		if !ok || len(e) == 0 {
+			return nil
+		}
+		for n, value := range a {
+			keys = append(keys, unexpectedKeys(fmt.Sprintf("%s[%d]", path, n), value, e[minInt(n, len(e)-1)])...)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// removeUnexpectedKeys removes the keys of the actual JSON value not in the
+// expected value, see unexpectedKeys
+func removeUnexpectedKeys(actual interface{}, expected interface{}) {
+	switch a := actual.(type) {
+	case map[string]interface{}:
+		e, ok := expected.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, value := range a {
+			if expectedValue, ok := e[key]; ok {
+				removeUnexpectedKeys(value, expectedValue)
+			} else {
+				delete(a, key)
+			}
+		}
+	case []interface{}:
+		e, ok := expected.([]interface{})
+		if !ok || len(e) == 0 {
+			return
+		}
+		for n, value := range a {
+			removeUnexpectedKeys(value, e[minInt(n, len(e)-1)])
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// postelRequests removes unexpected keys from the JSON request bodies of
+// interactions with PostelBodyMatching before they reach the mock server,
+// which would otherwise reject them
+type postelRequests struct {
+	mu sync.Mutex
+
+	// bodies are the expected request bodies, keyed by method and path.
+	// Requests for interactions with differing expectations are not changed.
+	bodies map[string]interface{}
+}
+
+func newPostelRequests() *postelRequests {
+	return &postelRequests{bodies: make(map[string]interface{})}
+}
+
+// register records the request body expected by the interaction
+func (p *postelRequests) register(i *Interaction) {
+	if i.bodyMatching != PostelBodyMatching {
+		return
+	}
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		log.Printf("[WARN] interaction '%s' allows unexpected keys but its path is not a plain string, they will be rejected\n", i.Description)
+		return
+	}
+
+	key := negotiationKey(i.Request.Method, path)
+	body := exampleOf(i.Request.Body)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.bodies[key]; ok && jsonString(existing) != jsonString(body) {
+		body = nil
+	}
+	p.bodies[key] = body
+}
+
+// reset forgets all registered bodies
+func (p *postelRequests) reset() {
+	p.mu.Lock()
+	p.bodies = make(map[string]interface{})
+	p.mu.Unlock()
+}
+
+func (p *postelRequests) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p.mu.Lock()
+		expected := p.bodies[negotiationKey(r.Method, r.URL.Path)]
+		p.mu.Unlock()
+
+		if expected == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body.Close()
+
+		if actual, err := decodeJSON(body); err == nil {
+			if keys := unexpectedKeys("$", actual, expected); len(keys) > 0 {
+				log.Printf("[DEBUG] ignoring unexpected keys in the body of %s %s: %s\n", r.Method, r.URL.Path, strings.Join(keys, ", "))
+				removeUnexpectedKeys(actual, expected)
+				if pruned, err := json.Marshal(actual); err == nil {
+					body = pruned
+					r.ContentLength = int64(len(body))
+					r.Header.Del("Content-Length")
+				}
+			}
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// strictInteraction is an interaction with StrictBodyMatching, and the
+// outcome of checking the provider's responses for it
+type strictInteraction struct {
+	consumer    string
+	description string
+	body        interface{}
+
+	// checked is set once a response was checked, and clean once a response
+	// had no unexpected keys
+	checked    bool
+	clean      bool
+	unexpected []string
+}
+
+// strictResponses rejects unexpected keys in the provider's JSON responses
+// for interactions with StrictBodyMatching, which the verifier allows
+type strictResponses struct {
+	mu sync.Mutex
+
+	// interactions are keyed by method and path. A response for several
+	// interactions passes each it has no unexpected keys for.
+	interactions map[string][]*strictInteraction
+}
+
+// newStrictResponses reads the interactions with StrictBodyMatching from
+// the pact files. It returns nil if there are none. Pacts that can't be read
+// are left for the verifier to report.
+func newStrictResponses(pactURLs []string, request types.VerifyRequest) (*strictResponses, error) {
+	s := &strictResponses{interactions: make(map[string][]*strictInteraction)}
+
+	for _, location := range pactURLs {
+		pact, err := readTriagePact(location, request)
+		if err != nil {
+			log.Printf("[WARN] unable to read pact %s to check its body matching: %v\n", location, err)
+			continue
+		}
+
+		for _, interaction := range pact.Interactions {
+			if interaction.BodyMatching != string(StrictBodyMatching) || len(interaction.Response.Body) == 0 {
+				continue
+			}
+
+			var body interface{}
+			if err = json.Unmarshal(interaction.Response.Body, &body); err != nil {
+				return nil, fmt.Errorf("unable to parse the response body of interaction '%s' in pact %s: %v", interaction.Description, location, err)
+			}
+			key := negotiationKey(interaction.Request.Method, interaction.Request.Path)
+			s.interactions[key] = append(s.interactions[key], &strictInteraction{
+				consumer:    pact.Consumer.Name,
+				description: interaction.Description,
+				body:        body,
+			})
+		}
+	}
+
+	if len(s.interactions) == 0 {
+		return nil, nil
+	}
+
+	return s, nil
+}
+
+func (s *strictResponses) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		interactions := s.interactions[negotiationKey(r.Method, r.URL.Path)]
+		s.mu.Unlock()
+
+		if len(interactions) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		var actual interface{}
+		if json.Unmarshal(recorder.body.Bytes(), &actual) == nil {
+			s.mu.Lock()
+			for _, interaction := range interactions {
+				interaction.checked = true
+				if keys := unexpectedKeys("$", actual, interaction.body); len(keys) > 0 {
+					interaction.unexpected = keys
+				} else {
+					interaction.clean = true
+				}
+			}
+			s.mu.Unlock()
+		}
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(recorder.status)
+		w.Write(recorder.body.Bytes()) // nolint:errcheck
+	})
+}
+
+// fail marks the body examples of interactions whose responses had
+// unexpected keys as failed, returning an error if there were any
+func (s *strictResponses) fail(res []types.ProviderVerifierResponse, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// consumer -> description -> interaction
+	byConsumer := make(map[string]map[string]*strictInteraction)
+	for _, interactions := range s.interactions {
+		for _, interaction := range interactions {
+			if byConsumer[interaction.consumer] == nil {
+				byConsumer[interaction.consumer] = make(map[string]*strictInteraction)
+			}
+			byConsumer[interaction.consumer][interaction.description] = interaction
+		}
+	}
+
+	failed := 0
+	for _, response := range res {
+		for n, example := range response.Examples {
+			if example.Status != "passed" || !strings.Contains(example.FullDescription, "has a matching body") {
+				continue
+			}
+
+			interactions := byConsumer[example.Pact.ConsumerName]
+			descriptions := make([]string, 0, len(interactions))
+			for description := range interactions {
+				descriptions = append(descriptions, description)
+			}
+			interaction, ok := interactions[exampleDescription(example.FullDescription, descriptions)]
+			if !ok || !interaction.checked || interaction.clean {
+				continue
+			}
+
+			message := fmt.Sprintf("unexpected keys in the response body, which is matched strictly: %s", strings.Join(interaction.unexpected, ", "))
+			response.Examples[n].Status = "failed"
+			response.Examples[n].Exception.Message = message
+			response.Examples[n].Mismatches = append(response.Examples[n].Mismatches, message)
+			failed++
+		}
+	}
+
+	if failed > 0 && err == nil {
+		err = fmt.Errorf("%d interactions have unexpected keys in their response body", failed)
+	}
+
+	return err
+}
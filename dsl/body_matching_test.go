@@ -0,0 +1,157 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestUnexpectedKeys(t *testing.T) {
+	var actual, expected interface{}
+	if err := json.Unmarshal([]byte(`{"id": 1, "name": "mary", "tags": [{"id": 1}, {"id": 2, "colour": "red"}], "address": {"city": "leeds", "zip": "LS1"}}`), &actual); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"id": 1, "tags": [{"id": 1}], "address": {"city": "leeds"}}`), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"$.address.zip", "$.name", "$.tags[1].colour"}
+	if got := unexpectedKeys("$", actual, expected); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	removeUnexpectedKeys(actual, expected)
+	if got := unexpectedKeys("$", actual, expected); len(got) != 0 {
+		t.Fatalf("want the unexpected keys removed, got %v", got)
+	}
+}
+
+func TestInteraction_ValidateBodyMatching(t *testing.T) {
+	interaction := (&Interaction{}).
+		UponReceiving("a request to create a user").
+		WithRequest(Request{Method: "POST", Path: String("/users")}).
+		WillRespondWith(Response{Status: 201}).
+		WithBodyMatching("lenient")
+
+	err := interaction.Validate()
+	if err == nil || !strings.Contains(err.Error(), `bodyMatching: "lenient" is not one of "strict" or "postel"`) {
+		t.Fatalf("expected an error for the unknown body matching, got %v", err)
+	}
+}
+
+func TestPact_VerifyPostelBodyMatching(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			fmt.Fprintln(w, "ok")
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"name":"mary"}` {
+			http.Error(w, "unexpected body "+string(body), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:   &types.MockServer{Port: getPort(ms.URL)},
+		Consumer: "My Consumer",
+		Provider: "My Provider",
+	}
+	pact.
+		AddInteraction().
+		UponReceiving("a request to create a user").
+		WithRequest(Request{Method: "POST", Path: String("/users"), Body: map[string]interface{}{"name": Like("mary")}}).
+		WillRespondWith(Response{Status: 201}).
+		WithBodyMatching(PostelBodyMatching)
+
+	err := pact.Verify(func() error {
+		res, err := http.Post(fmt.Sprintf("http://localhost:%d/users", pact.Server.Port), "application/json", strings.NewReader(`{"name": "mary", "nickname": "m"}`))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusCreated {
+			return fmt.Errorf("expected the unexpected key to be removed, got %d", res.StatusCode)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pact.bodyMatching["a request to create a user"] != PostelBodyMatching {
+		t.Fatalf("expected the body matching to be recorded for the pact file, got %v", pact.bodyMatching)
+	}
+}
+
+func TestStrictResponses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "web-users.json")
+	pact := `{"consumer": {"name": "web"}, "provider": {"name": "users"}, "interactions": [
+		{"description": "a request for user 1", "request": {"method": "GET", "path": "/users/1"}, "response": {"status": 200, "body": {"id": 1}}},
+		{"description": "a request for user 2", "request": {"method": "GET", "path": "/users/2"}, "response": {"status": 200, "body": {"id": 2}}}
+	]}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeBodyMatching(file, map[string]BodyMatching{"a request for user 1": StrictBodyMatching}); err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := newStrictResponses([]string{file, filepath.Join(dir, "missing.json")}, types.VerifyRequest{})
+	if err != nil || strict == nil {
+		t.Fatalf("expected the strict interaction to be read, got %v", err)
+	}
+
+	provider := strict.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "email": "mary@example.com"}`)
+	}))
+	for _, path := range []string{"/users/1", "/users/2"} {
+		recorder := httptest.NewRecorder()
+		provider.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Body.String() != `{"id": 1, "email": "mary@example.com"}` {
+			t.Fatalf("expected the response to be passed on, got %s", recorder.Body.String())
+		}
+	}
+
+	var res []types.ProviderVerifierResponse
+	err = json.Unmarshal([]byte(`[{"examples": [
+		{"status": "passed", "full_description": "Verifying a pact between web and users A request for user 1 with GET /users/1 returns a response which has status code 200", "pact": {"consumer_name": "web"}},
+		{"status": "passed", "full_description": "Verifying a pact between web and users A request for user 1 with GET /users/1 returns a response which has a matching body", "pact": {"consumer_name": "web"}},
+		{"status": "passed", "full_description": "Verifying a pact between web and users A request for user 2 with GET /users/2 returns a response which has a matching body", "pact": {"consumer_name": "web"}}
+	]}]`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = strict.fail(res, nil)
+	if err == nil || err.Error() != "1 interactions have unexpected keys in their response body" {
+		t.Fatalf("expected an error for the unexpected key, got %v", err)
+	}
+
+	var statuses []string
+	for _, example := range res[0].Examples {
+		statuses = append(statuses, example.Status)
+	}
+	if strings.Join(statuses, ",") != "passed,failed,passed" {
+		t.Fatalf("expected only the body of user 1 to fail, got %v", statuses)
+	}
+	if want := "unexpected keys in the response body, which is matched strictly: $.email"; res[0].Examples[1].Exception.Message != want {
+		t.Fatalf("want %q, got %q", want, res[0].Examples[1].Exception.Message)
+	}
+}
@@ -0,0 +1,314 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// BodyTransform converts a body between its form on the wire and its form in
+// the contract, e.g. to strip an envelope or decrypt a payload that shouldn't
+// be part of the contract. Transforms are registered by name (see
+// RegisterBodyTransform) on both the consumer and provider side, and named by
+// the interactions using them (see Interaction.WithRequestTransform).
+type BodyTransform interface {
+	// Unwrap converts a body from its wire form to its contract form
+	Unwrap(body []byte) ([]byte, error)
+
+	// Wrap converts a body from its contract form to its wire form
+	Wrap(body []byte) ([]byte, error)
+}
+
+// JSONEnvelope is a BodyTransform for JSON bodies wrapped in an envelope
+// object, e.g. {"data": {...}, "meta": {...}}, whose Field is the contract
+type JSONEnvelope struct {
+	// Field of the envelope holding the body
+	Field string
+
+	// Envelope holds the other fields of the envelope, added when wrapping
+	// a body. Optional.
+	Envelope map[string]interface{}
+}
+
+// Unwrap returns the envelope's Field
+func (e JSONEnvelope) Unwrap(body []byte) ([]byte, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("expected a JSON envelope: %v", err)
+	}
+
+	content, ok := envelope[e.Field]
+	if !ok {
+		return nil, fmt.Errorf("the envelope has no field %q", e.Field)
+	}
+
+	return content, nil
+}
+
+// Wrap returns the Envelope with the body as its Field
+func (e JSONEnvelope) Wrap(body []byte) ([]byte, error) {
+	envelope := make(map[string]interface{}, len(e.Envelope)+1)
+	for name, value := range e.Envelope {
+		envelope[name] = value
+	}
+	envelope[e.Field] = json.RawMessage(body)
+
+	return json.Marshal(envelope)
+}
+
+var (
+	bodyTransformsMu sync.RWMutex
+	bodyTransforms   = map[string]BodyTransform{}
+)
+
+// RegisterBodyTransform registers the transform under the name, replacing
+// any existing transform. The provider must register the same transforms as
+// the consumer.
+func RegisterBodyTransform(name string, transform BodyTransform) {
+	bodyTransformsMu.Lock()
+	defer bodyTransformsMu.Unlock()
+
+	bodyTransforms[name] = transform
+}
+
+// bodyTransform finds the transform registered under the name
+func bodyTransform(name string) (BodyTransform, error) {
+	bodyTransformsMu.RLock()
+	defer bodyTransformsMu.RUnlock()
+
+	transform, ok := bodyTransforms[name]
+	if !ok {
+		return nil, fmt.Errorf("no body transform registered as '%s', see RegisterBodyTransform", name)
+	}
+
+	return transform, nil
+}
+
+// WithRequestTransform names the body transform (see RegisterBodyTransform)
+// that unwraps the request body sent by the consumer before it is matched,
+// and wraps the request body sent to the provider. It is written to the pact
+// file, so that the verifier applies the same transform.
+func (i *Interaction) WithRequestTransform(name string) *Interaction {
+	i.requestTransform = name
+
+	return i
+}
+
+// WithResponseTransform names the body transform (see RegisterBodyTransform)
+// that wraps the response body returned to the consumer, and unwraps the
+// response body returned by the provider before it is matched. It is
+// written to the pact file, so that the verifier applies the same transform.
+func (i *Interaction) WithResponseTransform(name string) *Interaction {
+	i.responseTransform = name
+
+	return i
+}
+
+// bodyTransformErrors checks the transforms are registered
+func (i *Interaction) bodyTransformErrors() []FieldError {
+	var errs []FieldError
+	if i.requestTransform != "" {
+		if _, err := bodyTransform(i.requestTransform); err != nil {
+			errs = append(errs, FieldError{Field: "requestTransform", Message: err.Error()})
+		}
+	}
+	if i.responseTransform != "" {
+		if _, err := bodyTransform(i.responseTransform); err != nil {
+			errs = append(errs, FieldError{Field: "responseTransform", Message: err.Error()})
+		}
+	}
+
+	return errs
+}
+
+// bodyTransformNames are the transforms of the interaction, as written to
+// the pact file
+func (i *Interaction) bodyTransformNames() map[string]string {
+	names := make(map[string]string)
+	if i.requestTransform != "" {
+		names["request"] = i.requestTransform
+	}
+	if i.responseTransform != "" {
+		names["response"] = i.responseTransform
+	}
+
+	return names
+}
+
+// writeBodyTransforms records the body transforms of each interaction, keyed
+// by description, in the pact file
+func writeBodyTransforms(file string, transforms map[string]map[string]string) error {
+	if len(transforms) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		if names, ok := transforms[description]; ok {
+			interaction["bodyTransforms"] = names
+		}
+	})
+}
+
+// bodyTransformer applies the body transforms of interactions to the
+// requests and responses passing through it. On the consumer side request
+// bodies are unwrapped and response bodies wrapped, and on the provider side
+// the reverse.
+type bodyTransformer struct {
+	mu       sync.Mutex
+	provider bool
+
+	// transforms are the request and response transform names, keyed by
+	// method and path. Requests for interactions with differing transforms
+	// are not changed.
+	transforms map[string]map[string]string
+}
+
+func newBodyTransformer(provider bool) *bodyTransformer {
+	return &bodyTransformer{provider: provider, transforms: make(map[string]map[string]string)}
+}
+
+// register records the transforms of the interaction
+func (b *bodyTransformer) register(i *Interaction) {
+	names := i.bodyTransformNames()
+	if len(names) == 0 {
+		return
+	}
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		log.Printf("[WARN] interaction '%s' has body transforms but its path is not a plain string, its bodies will not be transformed\n", i.Description)
+		return
+	}
+
+	b.add(negotiationKey(i.Request.Method, path), names)
+}
+
+func (b *bodyTransformer) add(key string, names map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if existing, ok := b.transforms[key]; ok && (existing == nil || jsonString(existing) != jsonString(names)) {
+		log.Printf("[WARN] interactions for %s have differing body transforms, its bodies will not be transformed\n", key)
+		names = nil
+	}
+	b.transforms[key] = names
+}
+
+// reset forgets all registered transforms
+func (b *bodyTransformer) reset() {
+	b.mu.Lock()
+	b.transforms = make(map[string]map[string]string)
+	b.mu.Unlock()
+}
+
+func (b *bodyTransformer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		names := b.transforms[negotiationKey(r.Method, r.URL.Path)]
+		b.mu.Unlock()
+
+		if r.Header.Get("X-Pact-Mock-Service") != "" || names == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if name := names["request"]; name != "" && r.Body != nil {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body.Close()
+
+			body = b.apply(name, body, !b.provider)
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		}
+
+		name := names["response"]
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		body := recorder.body.Bytes()
+		if len(body) > 0 {
+			body = b.apply(name, body, b.provider)
+		}
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(recorder.status)
+		w.Write(body) // nolint:errcheck
+	})
+}
+
+// apply unwraps or wraps the body with the named transform. Bodies that
+// can't be transformed are passed through unchanged, to be reported as
+// mismatches.
+func (b *bodyTransformer) apply(name string, body []byte, unwrap bool) []byte {
+	transform, err := bodyTransform(name)
+	if err != nil {
+		log.Println("[WARN]", err)
+		return body
+	}
+
+	var transformed []byte
+	if unwrap {
+		transformed, err = transform.Unwrap(body)
+	} else {
+		transformed, err = transform.Wrap(body)
+	}
+	if err != nil {
+		log.Printf("[WARN] unable to transform body with '%s': %v\n", name, err)
+		return body
+	}
+
+	return transformed
+}
+
+// newProviderBodyTransforms reads the body transforms of interactions from
+// the pact files. It returns nil if there are none, or an error if a
+// transform isn't registered. Pacts that can't be read are left for the
+// verifier to report.
+func newProviderBodyTransforms(pactURLs []string, request types.VerifyRequest) (*bodyTransformer, error) {
+	b := newBodyTransformer(true)
+
+	for _, location := range pactURLs {
+		pact, err := readTriagePact(location, request)
+		if err != nil {
+			log.Printf("[WARN] unable to read pact %s to check for body transforms: %v\n", location, err)
+			continue
+		}
+
+		for _, interaction := range pact.Interactions {
+			if len(interaction.BodyTransforms) == 0 {
+				continue
+			}
+			for _, name := range interaction.BodyTransforms {
+				if _, err := bodyTransform(name); err != nil {
+					return nil, fmt.Errorf("interaction '%s' of pact %s: %v", interaction.Description, location, err)
+				}
+			}
+			b.add(negotiationKey(interaction.Request.Method, interaction.Request.Path), interaction.BodyTransforms)
+		}
+	}
+
+	if len(b.transforms) == 0 {
+		return nil, nil
+	}
+
+	return b, nil
+}
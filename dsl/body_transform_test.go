@@ -0,0 +1,168 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestJSONEnvelope(t *testing.T) {
+	envelope := JSONEnvelope{Field: "data", Envelope: map[string]interface{}{"version": 1}}
+
+	wrapped, err := envelope.Wrap([]byte(`{"id":1}`))
+	if err != nil || string(wrapped) != `{"data":{"id":1},"version":1}` {
+		t.Fatalf("unexpected wrapped body %s (%v)", wrapped, err)
+	}
+
+	unwrapped, err := envelope.Unwrap(wrapped)
+	if err != nil || string(unwrapped) != `{"id":1}` {
+		t.Fatalf("unexpected unwrapped body %s (%v)", unwrapped, err)
+	}
+
+	if _, err = envelope.Unwrap([]byte(`{"id":1}`)); err == nil || !strings.Contains(err.Error(), `no field "data"`) {
+		t.Fatalf("expected an error for a body without the field, got %v", err)
+	}
+	if _, err = envelope.Unwrap([]byte(`[1]`)); err == nil {
+		t.Fatal("expected an error for a body that isn't an object")
+	}
+}
+
+func TestInteraction_BodyTransformErrors(t *testing.T) {
+	RegisterBodyTransform("test-envelope", JSONEnvelope{Field: "data"})
+
+	interaction := (&Interaction{}).
+		UponReceiving("a request to create an order").
+		WithRequest(Request{Method: "POST", Path: String("/orders"), Body: map[string]interface{}{"id": 1}}).
+		WillRespondWith(Response{Status: 201}).
+		WithRequestTransform("test-envelope").
+		WithResponseTransform("unknown")
+
+	err := interaction.Validate()
+	if err == nil || !strings.Contains(err.Error(), "responseTransform") || strings.Contains(err.Error(), "requestTransform") {
+		t.Fatalf("expected an error for the unregistered transform only, got %v", err)
+	}
+}
+
+func TestPact_VerifyBodyTransforms(t *testing.T) {
+	RegisterBodyTransform("test-envelope", JSONEnvelope{Field: "data", Envelope: map[string]interface{}{"version": 1}})
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			fmt.Fprintln(w, "ok")
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"quantity":2}` {
+			http.Error(w, "unexpected body "+string(body), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1}`)
+	}))
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:   &types.MockServer{Port: getPort(ms.URL)},
+		Consumer: "My Consumer",
+		Provider: "My Provider",
+	}
+	pact.
+		AddInteraction().
+		UponReceiving("a request to create an order").
+		WithRequest(Request{Method: "POST", Path: String("/orders"), Body: map[string]interface{}{"quantity": Like(1)}}).
+		WillRespondWith(Response{Status: 201, Body: map[string]interface{}{"id": Like(1)}}).
+		WithRequestTransform("test-envelope").
+		WithResponseTransform("test-envelope")
+
+	err := pact.Verify(func() error {
+		res, err := http.Post(fmt.Sprintf("http://localhost:%d/orders", pact.Server.Port), "application/json", strings.NewReader(`{"data":{"quantity":2},"version":1}`))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != http.StatusCreated || string(body) != `{"data":{"id":1},"version":1}` {
+			return fmt.Errorf("expected the wrapped response, got %d %s", res.StatusCode, body)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pact.bodyTransforms["a request to create an order"]["response"] != "test-envelope" {
+		t.Fatalf("expected the transforms to be recorded for the pact file, got %v", pact.bodyTransforms)
+	}
+}
+
+func TestProviderBodyTransforms(t *testing.T) {
+	RegisterBodyTransform("test-envelope", JSONEnvelope{Field: "data"})
+
+	dir, err := ioutil.TempDir("", "transforms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "web-orders.json")
+	pact := `{"consumer": {"name": "web"}, "provider": {"name": "orders"}, "interactions": [
+		{"description": "a request to create an order", "request": {"method": "POST", "path": "/orders", "body": {"quantity": 2}},
+		 "response": {"status": 201, "body": {"id": 1}}},
+		{"description": "a request for order 1", "request": {"method": "GET", "path": "/orders/1"}, "response": {"status": 200, "body": {"id": 1}}}
+	]}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeBodyTransforms(file, map[string]map[string]string{"a request to create an order": {"request": "test-envelope", "response": "test-envelope"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := pactfile.Read(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written.Interactions[0].BodyTransforms["request"] != "test-envelope" || len(written.Interactions[1].BodyTransforms) != 0 {
+		t.Fatalf("expected the transforms to be written to the first interaction only, got %v", written.Interactions)
+	}
+
+	transformer, err := newProviderBodyTransforms([]string{file}, types.VerifyRequest{})
+	if err != nil || transformer == nil {
+		t.Fatalf("expected the transforms to be read, got %v", err)
+	}
+
+	provider := transformer.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if len(body) == 0 {
+			body = []byte("null")
+		}
+		fmt.Fprintf(w, `{"data":{"received":%s}}`, body)
+	}))
+
+	recorder := httptest.NewRecorder()
+	provider.ServeHTTP(recorder, httptest.NewRequest("POST", "/orders", strings.NewReader(`{"quantity":2}`)))
+	if want := `{"received":{"data":{"quantity":2}}}`; recorder.Body.String() != want {
+		t.Fatalf("want %s, got %s", want, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	provider.ServeHTTP(recorder, httptest.NewRequest("GET", "/orders/1", nil))
+	if want := `{"data":{"received":null}}`; recorder.Body.String() != want {
+		t.Fatalf("expected other interactions to be unchanged, want %s, got %s", want, recorder.Body.String())
+	}
+
+	if err = writeBodyTransforms(file, map[string]map[string]string{"a request for order 1": {"response": "unknown"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newProviderBodyTransforms([]string{file}, types.VerifyRequest{}); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("expected an error for the unregistered transform, got %v", err)
+	}
+}
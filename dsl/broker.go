@@ -0,0 +1,87 @@
+package dsl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// ErrBrokerUnavailable is returned by requests to a Pact Broker when the
+// broker client is compiled out with the pact_nobroker build tag, or disabled
+// by setting the PACT_DISABLE_BROKER environment variable
+var ErrBrokerUnavailable = errors.New("the Pact Broker client is unavailable: build without the pact_nobroker tag and unset PACT_DISABLE_BROKER")
+
+// BrokerAvailable reports whether the Pact Broker client is compiled in and
+// enabled. Consumer tests that never talk to a broker may build with the
+// pact_nobroker tag to leave it out.
+func BrokerAvailable() bool {
+	return brokerCompiled && os.Getenv("PACT_DISABLE_BROKER") == ""
+}
+
+// brokerGet fetches a JSON resource from a Pact Broker, authenticating with
+// the authenticator if given
+func brokerGet(u string, auth types.Authenticator) ([]byte, error) {
+	return brokerRequest(context.Background(), "GET", u, nil, auth)
+}
+
+// brokerPost posts a JSON document to a Pact Broker, returning the response,
+// authenticating as brokerGet does
+func brokerPost(u string, body []byte, auth types.Authenticator) ([]byte, error) {
+	return brokerRequest(context.Background(), "POST", u, body, auth)
+}
+
+// brokerRequest sends a request to a Pact Broker, cancelled with the context.
+// The response body is returned with the error for an unsuccessful status.
+func brokerRequest(ctx context.Context, method string, u string, body []byte, auth types.Authenticator) ([]byte, error) {
+	if !BrokerAvailable() {
+		return nil, ErrBrokerUnavailable
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/hal+json, application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if auth != nil {
+		if err = auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("unable to authenticate with the broker: %v", err)
+		}
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	content, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		if method == "GET" {
+			return content, fmt.Errorf("unexpected status %d fetching %s", res.StatusCode, u)
+		}
+		return content, fmt.Errorf("unexpected status %d from %s %s", res.StatusCode, method, u)
+	}
+
+	return content, nil
+}
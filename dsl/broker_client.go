@@ -0,0 +1,223 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// Broker is a client of a Pact Broker's API, authenticating with the
+// Authenticator if given, otherwise the Token, or the Username and Password
+type Broker struct {
+	URL           string
+	Token         string
+	Username      string
+	Password      string
+	Authenticator types.Authenticator
+}
+
+// auth returns the authenticator for the broker's requests, or nil
+func (b *Broker) auth() types.Authenticator {
+	switch {
+	case b.Authenticator != nil:
+		return b.Authenticator
+	case b.Token != "":
+		return types.BearerToken(b.Token)
+	case b.Username != "":
+		return types.BasicAuth{Username: b.Username, Password: b.Password}
+	}
+
+	return nil
+}
+
+// Pacticipants iterates over the broker's pacticipants
+func (b *Broker) Pacticipants(ctx context.Context) *BrokerIterator {
+	return b.iterate(ctx, "pacticipants", b.url("pacticipants"))
+}
+
+// Versions iterates over the versions of a pacticipant
+func (b *Broker) Versions(ctx context.Context, pacticipant string) *BrokerIterator {
+	return b.iterate(ctx, "versions", b.url("pacticipants", pacticipant, "versions"))
+}
+
+// Webhooks iterates over the broker's webhooks
+func (b *Broker) Webhooks(ctx context.Context) *BrokerIterator {
+	return b.iterate(ctx, "webhooks", b.url("webhooks"))
+}
+
+// Pacts iterates over the pacts the broker selects for the provider to
+// verify with the consumer version selectors (see PactsForVerification)
+func (b *Broker) Pacts(ctx context.Context, provider string, selectors ...types.ConsumerVersionSelector) *BrokerIterator {
+	it := &BrokerIterator{ctx: ctx, broker: b, key: "pacts", seen: map[string]bool{}}
+	it.first = func() (string, []byte, error) {
+		body, err := pactsForVerificationBody(types.VerifyRequest{ConsumerVersionSelectors: selectors})
+		if err != nil {
+			return "", nil, err
+		}
+		u := pactsForVerificationURL(b.URL, provider)
+		content, err := brokerRequest(ctx, "POST", u, body, b.auth())
+
+		return u, content, err
+	}
+
+	return it
+}
+
+func (b *Broker) url(segments ...string) string {
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.TrimSuffix(b.URL, "/") + "/" + strings.Join(segments, "/")
+}
+
+func (b *Broker) iterate(ctx context.Context, key string, u string) *BrokerIterator {
+	it := &BrokerIterator{ctx: ctx, broker: b, key: key, seen: map[string]bool{}}
+	it.first = func() (string, []byte, error) {
+		return it.get(u)
+	}
+
+	return it
+}
+
+// BrokerIterator steps through a collection of broker resources, following
+// the broker's 'next' links to fetch each page as it is needed:
+//
+//	it := broker.Pacticipants(ctx)
+//	for it.Next() {
+//		var pacticipant struct {
+//			Name string `json:"name"`
+//		}
+//		if err := it.Decode(&pacticipant); err != nil {
+//			return err
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type BrokerIterator struct {
+	ctx    context.Context
+	broker *Broker
+	key    string
+	first  func() (string, []byte, error)
+	next   string
+	seen   map[string]bool
+
+	page    []json.RawMessage
+	current json.RawMessage
+	err     error
+}
+
+// Next advances to the next resource, fetching the next page if required.
+// It returns false when the collection is exhausted, the context is done or
+// a request fails, see Err.
+func (it *BrokerIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil || (it.first == nil && it.next == "") {
+			it.current = nil
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			continue
+		}
+
+		var u string
+		var content []byte
+		var err error
+		if it.first != nil {
+			u, content, err = it.first()
+			it.first = nil
+		} else {
+			u, content, err = it.get(it.next)
+			it.next = ""
+		}
+		if err != nil {
+			it.err = fmt.Errorf("unable to fetch the %s from the broker: %v", it.key, err)
+			continue
+		}
+		it.err = it.parse(u, content)
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+
+	return true
+}
+
+// Decode unmarshals the current resource into v
+func (it *BrokerIterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return fmt.Errorf("no current %s: call Next first", it.key)
+	}
+
+	return json.Unmarshal(it.current, v)
+}
+
+// Raw is the JSON of the current resource
+func (it *BrokerIterator) Raw() json.RawMessage {
+	return it.current
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *BrokerIterator) Err() error {
+	return it.err
+}
+
+func (it *BrokerIterator) get(u string) (string, []byte, error) {
+	if it.seen[u] {
+		return u, nil, fmt.Errorf("the page %s was already fetched", u)
+	}
+	it.seen[u] = true
+
+	content, err := brokerRequest(it.ctx, "GET", u, nil, it.broker.auth())
+
+	return u, content, err
+}
+
+// parse reads a page of the collection, from its embedded resources or else
+// its 'pb:' links, and the link to the next page
+func (it *BrokerIterator) parse(u string, content []byte) error {
+	var page struct {
+		Embedded map[string]json.RawMessage `json:"_embedded"`
+		Links    map[string]json.RawMessage `json:"_links"`
+	}
+	if err := json.Unmarshal(content, &page); err != nil {
+		return fmt.Errorf("unable to fetch the %s from the broker: invalid response from %s: %v", it.key, u, err)
+	}
+
+	items, ok := page.Embedded[it.key]
+	if !ok {
+		items = page.Links["pb:"+it.key]
+	}
+	if items != nil {
+		if err := json.Unmarshal(items, &it.page); err != nil {
+			return fmt.Errorf("unable to fetch the %s from the broker: invalid response from %s: %v", it.key, u, err)
+		}
+	}
+
+	var next struct {
+		Href string `json:"href"`
+	}
+	if raw, ok := page.Links["next"]; ok {
+		if err := json.Unmarshal(raw, &next); err != nil {
+			return fmt.Errorf("unable to fetch the %s from the broker: invalid next link in %s: %v", it.key, u, err)
+		}
+	}
+	if next.Href != "" {
+		base, err := url.Parse(u)
+		if err != nil {
+			return err
+		}
+		ref, err := url.Parse(next.Href)
+		if err != nil {
+			return fmt.Errorf("unable to fetch the %s from the broker: invalid next link '%s' in %s", it.key, next.Href, u)
+		}
+		it.next = base.ResolveReference(ref).String()
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func setupPagingBroker() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pacticipants", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.WriteHeader(401)
+			return
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			fmt.Fprint(w, `{"_embedded":{"pacticipants":[{"name":"billy"},{"name":"bobby"}]},"_links":{"next":{"href":"/pacticipants?page=2"}}}`)
+		case "2":
+			fmt.Fprint(w, `{"_embedded":{"pacticipants":[]},"_links":{"next":{"href":"/pacticipants?page=3"}}}`)
+		case "3":
+			fmt.Fprint(w, `{"_embedded":{"pacticipants":[{"name":"jessica"}]}}`)
+		}
+	})
+	mux.HandleFunc("/pacticipants/billy/versions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_embedded":{"versions":[{"number":"1.0.0"}]},"_links":{"next":{"href":"/pacticipants/billy/versions"}}}`)
+	})
+	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_links":{"pb:webhooks":[{"title":"a webhook","href":"http://broker/webhooks/1"}]}}`)
+	})
+	mux.HandleFunc("/pacts/provider/bobby/for-verification", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(405)
+			return
+		}
+		fmt.Fprint(w, `{"_embedded":{"pacts":[{"_links":{"self":{"href":"http://broker/pacts/1"}}}]}}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestBroker_Iterators(t *testing.T) {
+	s := setupPagingBroker()
+	defer s.Close()
+	broker := &Broker{URL: s.URL, Token: "t0k3n"}
+	ctx := context.Background()
+
+	names := func(it *BrokerIterator, field string) []string {
+		var got []string
+		for it.Next() {
+			var resource map[string]interface{}
+			if err := it.Decode(&resource); err != nil {
+				t.Fatal(err)
+			}
+			if field == "href" {
+				resource = resource["_links"].(map[string]interface{})["self"].(map[string]interface{})
+			}
+			got = append(got, fmt.Sprint(resource[field]))
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return got
+	}
+
+	if got := names(broker.Pacticipants(ctx), "name"); !reflect.DeepEqual(got, []string{"billy", "bobby", "jessica"}) {
+		t.Fatalf("expected every page of pacticipants, got %v", got)
+	}
+	if got := names(broker.Webhooks(ctx), "title"); !reflect.DeepEqual(got, []string{"a webhook"}) {
+		t.Fatalf("expected the webhooks, got %v", got)
+	}
+	if got := names(broker.Pacts(ctx, "bobby", types.ConsumerVersionSelector{MainBranch: true}), "href"); !reflect.DeepEqual(got, []string{"http://broker/pacts/1"}) {
+		t.Fatalf("expected the pacts for verification, got %v", got)
+	}
+
+	authenticated := &Broker{URL: s.URL, Token: "ignored", Authenticator: types.AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer t0k3n")
+		return nil
+	})}
+	if got := names(authenticated.Pacticipants(ctx), "name"); len(got) != 3 {
+		t.Fatalf("expected the authenticator to be used, got %v", got)
+	}
+}
+
+func TestBroker_IteratorErrors(t *testing.T) {
+	s := setupPagingBroker()
+	defer s.Close()
+	failingAuthenticator := types.AuthenticatorFunc(func(*http.Request) error {
+		return fmt.Errorf("no token")
+	})
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := map[string]struct {
+		it    *BrokerIterator
+		items int
+		err   string
+	}{
+		"unauthorised":    {(&Broker{URL: s.URL}).Pacticipants(context.Background()), 0, "unexpected status 401"},
+		"cancelled":       {(&Broker{URL: s.URL, Token: "t0k3n"}).Pacticipants(cancelled), 0, "context canceled"},
+		"unauthenticated": {(&Broker{URL: s.URL, Authenticator: failingAuthenticator}).Pacticipants(context.Background()), 0, "unable to authenticate with the broker: no token"},
+		"repeated page":   {(&Broker{URL: s.URL}).Versions(context.Background(), "billy"), 1, "was already fetched"},
+		"invalid request": {(&Broker{URL: s.URL}).Pacts(context.Background(), "bobby", types.ConsumerVersionSelector{All: true, Latest: true}), 0, "invalid consumer version selector"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			items := 0
+			for c.it.Next() {
+				items++
+			}
+			if items != c.items || c.it.Err() == nil || !strings.Contains(c.it.Err().Error(), c.err) {
+				t.Fatalf("expected %d items and the error %q, got %d and %v", c.items, c.err, items, c.it.Err())
+			}
+			if c.it.Next() || c.it.Decode(&struct{}{}) == nil {
+				t.Fatal("expected the iterator to stay stopped")
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !pact_nobroker
+// +build !pact_nobroker
+
+package dsl
+
+// brokerCompiled is false when the broker client is compiled out with the
+// pact_nobroker build tag
+const brokerCompiled = true
@@ -1,10 +1,17 @@
 package dsl
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
 )
 
 // Pretend to be a Broker for fetching Pacts
@@ -39,45 +46,56 @@ func setupMockBroker(auth bool) *httptest.Server {
 
 	server := httptest.NewServer(mux)
 
-	// Find latest 'bobby' consumers (no tag)
-	// curl --user pactuser:pact -H "accept: application/hal+json" "http://pact.onegeek.com.au/pacts/provider/bobby/latest"
-	mux.HandleFunc("/pacts/provider/bobby/latest", authFunc(func(w http.ResponseWriter, req *http.Request) {
-		log.Println("[DEBUG] get pacts for provider 'bobby'")
-		fmt.Fprintf(w, `{"_links":{"self":{"href":"%s/pacts/provider/bobby/latest","title":"Latest pact versions for the provider bobby"},"provider":{"href":"%s/pacticipants/bobby","title":"bobby"},"pb:pacts":[{"href":"%s/pacts/provider/bobby/consumer/jessica/version/2.0.0","title":"Pact between jessica (v2.0.0) and bobby","name":"jessica"},{"href":"%s/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0","title":"Pact between billy (v1.0.0) and bobby","name":"billy"}],"pacts":[{"href":"%s/pacts/provider/bobby/consumer/jessica/version/2.0.0","title":"OLD Pact between jessica (v2.0.0) and bobby","name":"jessica"},{"href":"%s/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0","title":"OLD Pact between billy (v1.0.0) and bobby","name":"billy"}]}}`, server.URL, server.URL, server.URL, server.URL, server.URL, server.URL)
-		w.Header().Add("Content-Type", "application/hal+json")
-	}))
+	// Pacts for verification of 'bobby', for the consumer version selectors
+	// curl --user pactuser:pact -H "content-type: application/json" -d '{"consumerVersionSelectors":[{"tag":"prod","latest":true}]}' "http://pact.onegeek.com.au/pacts/provider/bobby/for-verification"
+	mux.Handle("/pacts/provider/bobby/for-verification", authFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			ConsumerVersionSelectors []types.ConsumerVersionSelector `json:"consumerVersionSelectors"`
+			IncludePendingStatus     bool                            `json:"includePendingStatus"`
+			IncludeWipPactsSince     string                          `json:"includeWipPactsSince"`
+		}
+		if req.Method != "POST" || json.NewDecoder(req.Body).Decode(&body) != nil {
+			w.WriteHeader(400)
+			return
+		}
 
-	// Find 'bobby' consumers for tag 'prod'
-	// curl --user pactuser:pact -H "accept: application/hal+json" "http://pact.onegeek.com.au/pacts/provider/bobby/latest/sit4"
-	mux.Handle("/pacts/provider/bobby/latest/prod", authFunc(func(w http.ResponseWriter, req *http.Request) {
-		log.Println("[DEBUG] get all pacts for provider 'bobby' where the tag 'prod' exists")
-		fmt.Fprintf(w, `{"_links":{"self":{"href":"%s/pacts/provider/bobby/latest/dev","title":"Latest pact versions for the provider bobby with tag 'dev'"},"provider":{"href":"%s/pacticipants/bobby","title":"bobby"},"pb:pacts":[{"href":"%s/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0","title":"Pact between billy (v1.0.0) and bobby","name":"billy"}],"pacts":[{"href":"%s/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0","title":"OLD Pact between billy (v1.0.0) and bobby","name":"billy"}]}}`, server.URL, server.URL, server.URL, server.URL)
-		w.Header().Add("Content-Type", "application/hal+json")
-	}))
+		pacts := []string{"bobby/consumer/jessica/version/2.0.0", "loginprovider/consumer/jmarie/version/1.0.0"}
+		if len(body.ConsumerVersionSelectors) > 0 {
+			switch body.ConsumerVersionSelectors[0].Tag {
+			case "prod":
+				pacts = pacts[1:]
+			case "dev":
+				pacts = []string{"loginprovider/consumer/jmarie/version/1.0.1"}
+			case "broken":
+				log.Println("[DEBUG] broken broker")
+				fmt.Fprintf(w, `broken response`)
+				return
+			}
+		}
+		// jmarie's 1.0.1 pact is a work in progress pact, if included
+		if body.IncludeWipPactsSince != "" {
+			pacts = append(pacts, "loginprovider/consumer/jmarie/version/1.0.1")
+		}
+		log.Println("[DEBUG] get pacts for verification of provider 'bobby'", body.ConsumerVersionSelectors)
 
-	// Broken response
-	mux.Handle("/pacts/provider/bobby/latest/broken", authFunc(func(w http.ResponseWriter, req *http.Request) {
-		log.Println("[DEBUG] broken broker")
-		fmt.Fprintf(w, `broken response`)
+		embedded := make([]string, 0, len(pacts))
+		for _, pact := range pacts {
+			// jessica's pact is pending, if pending pacts are included
+			pending := body.IncludePendingStatus && strings.Contains(pact, "jessica")
+			wip := body.IncludeWipPactsSince != "" && strings.HasSuffix(pact, "1.0.1")
+			embedded = append(embedded, fmt.Sprintf(`{"shortDescription":"latest","verificationProperties":{"pending":%[3]t,"wip":%[4]t,"notices":[{"when":"before_verification","text":"The pact at %[1]s/pacts/provider/%[2]s is being verified"}]},"_links":{"self":{"href":"%[1]s/pacts/provider/%[2]s","name":"Pact between a consumer and bobby"}}}`, server.URL, pact, pending || wip, wip))
+		}
 		w.Header().Add("Content-Type", "application/hal+json")
+		fmt.Fprintf(w, `{"_embedded":{"pacts":[%s]},"_links":{"self":{"href":"%s/pacts/provider/bobby/for-verification","title":"Pacts to be verified"}}}`, strings.Join(embedded, ","), server.URL)
 	}))
 
 	// 50x response
-	// curl --user pactuser:pact -H "accept: application/hal+json" "http://pact.onegeek.com.au/pacts/provider/bobby/latest/sit4"
-	mux.Handle("/pacts/provider/broken/latest/dev", authFunc(func(w http.ResponseWriter, req *http.Request) {
+	mux.Handle("/pacts/provider/broken/for-verification", authFunc(func(w http.ResponseWriter, req *http.Request) {
 		log.Println("[DEBUG] broker broker response")
 		w.WriteHeader(500)
 		w.Write([]byte("500 Server Error\n")) // nolint:errcheck
 	}))
 
-	// Find 'bobby' consumers for tag 'dev'
-	// curl --user pactuser:pact -H "accept: application/hal+json" "http://pact.onegeek.com.au/pacts/provider/bobby/latest/sit4"
-	mux.Handle("/pacts/provider/bobby/latest/dev", authFunc(func(w http.ResponseWriter, req *http.Request) {
-		log.Println("[DEBUG] get all pacts for provider 'bobby' where the tag 'dev' exists")
-		fmt.Fprintf(w, `{"_links":{"self":{"href":"%s/pacts/provider/bobby/latest/dev","title":"Latest pact versions for the provider bobby with tag 'dev'"},"provider":{"href":"%s/pacticipants/bobby","title":"bobby"},"pb:pacts":[{"href":"%s/pacts/provider/loginprovider/consumer/jmarie/version/1.0.1","title":"Pact between billy (v1.0.1) and bobby","name":"billy"}],"pacts":[{"href":"%s/pacts/provider/loginprovider/consumer/jmarie/version/1.0.1","title":"OLD Pact between billy (v1.0.1) and bobby","name":"billy"}]}}`, server.URL, server.URL, server.URL, server.URL)
-		w.Header().Add("Content-Type", "application/hal+json")
-	}))
-
 	// Actual Consumer Pact
 	// curl -v --user pactuser:pact -H "accept: application/json" http://pact.onegeek.com.au/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0
 	mux.Handle("/pacts/provider/loginprovider/consumer/jmarie/version/", authFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -88,3 +106,25 @@ func setupMockBroker(auth bool) *httptest.Server {
 
 	return server
 }
+
+func TestBrokerAvailable(t *testing.T) {
+	s := setupPagingBroker()
+	defer s.Close()
+
+	if !BrokerAvailable() {
+		t.Fatal("expected the broker client to be available")
+	}
+
+	os.Setenv("PACT_DISABLE_BROKER", "1")
+	defer os.Unsetenv("PACT_DISABLE_BROKER")
+
+	if BrokerAvailable() {
+		t.Fatal("expected PACT_DISABLE_BROKER to disable the broker client")
+	}
+	if _, err := (&Broker{URL: s.URL, Token: "t0k3n"}).Environments(context.Background()); err == nil || !strings.Contains(err.Error(), ErrBrokerUnavailable.Error()) {
+		t.Fatalf("expected ErrBrokerUnavailable, got %v", err)
+	}
+	if err := (&Publisher{}).Publish(types.PublishRequest{PactBroker: s.URL}); err != ErrBrokerUnavailable {
+		t.Fatalf("expected ErrBrokerUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Webhook is a Pact Broker webhook, sending a request when one of its events
+// occurs for the consumer and provider
+type Webhook struct {
+	// UUID identifies the webhook. Assigned by the broker.
+	UUID string
+
+	Description string
+
+	// Consumer and Provider restrict the webhook to their pacts. Empty
+	// matches every pacticipant.
+	Consumer string
+	Provider string
+
+	// Events trigger the webhook, e.g. ContractContentChanged or
+	// ProviderVerificationPublished
+	Events []WebhookEventName
+
+	// Disabled webhooks are not triggered
+	Disabled bool
+
+	Request WebhookRequest
+}
+
+// WebhookRequest is the request a webhook sends. Its URL, headers and body
+// may contain the broker's ${pactbroker.*} placeholders.
+type WebhookRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is a string, e.g. WebhookEventTemplate, or a value sent as JSON.
+	// Optional.
+	Body interface{} `json:"body,omitempty"`
+
+	// Username and Password authenticate the request with basic
+	// authentication. For a token, set an Authorization header instead.
+	// The broker doesn't return the password.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// WebhookExecution is the result of executing a webhook
+type WebhookExecution struct {
+	Success bool
+
+	// Status is the status of the response to the webhook's request, or 0
+	// if it wasn't answered
+	Status int
+
+	// Logs describe the request and response
+	Logs string
+}
+
+// webhookDocument is the broker's representation of a webhook
+type webhookDocument struct {
+	UUID        string              `json:"uuid,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Consumer    *webhookPacticipant `json:"consumer,omitempty"`
+	Provider    *webhookPacticipant `json:"provider,omitempty"`
+	Enabled     bool                `json:"enabled"`
+	Events      []webhookEvent      `json:"events"`
+	Request     WebhookRequest      `json:"request"`
+	Links       *struct {
+		Self struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"_links,omitempty"`
+}
+
+type webhookPacticipant struct {
+	Name string `json:"name"`
+}
+
+type webhookEvent struct {
+	Name WebhookEventName `json:"name"`
+}
+
+// ListWebhooks fetches each of the broker's webhooks
+func (b *Broker) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	it := b.Webhooks(ctx)
+	for it.Next() {
+		var link struct {
+			Href string `json:"href"`
+		}
+		if err := it.Decode(&link); err != nil {
+			return nil, err
+		}
+		webhook, err := b.Webhook(ctx, path.Base(link.Href))
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, it.Err()
+}
+
+// Webhook fetches the webhook with the UUID
+func (b *Broker) Webhook(ctx context.Context, uuid string) (Webhook, error) {
+	content, err := brokerRequest(ctx, "GET", b.url("webhooks", uuid), nil, b.auth())
+	if err != nil {
+		return Webhook{}, fmt.Errorf("unable to fetch the webhook %s: %v", uuid, err)
+	}
+
+	return parseWebhook(content)
+}
+
+// CreateWebhook creates the webhook, returning it with its UUID
+func (b *Broker) CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error) {
+	webhook.UUID = ""
+	created, err := b.saveWebhook(ctx, "POST", b.url("webhooks"), webhook)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("unable to create the webhook '%s': %v", webhook.Description, err)
+	}
+
+	return created, nil
+}
+
+// UpdateWebhook replaces the webhook with the same UUID
+func (b *Broker) UpdateWebhook(ctx context.Context, webhook Webhook) (Webhook, error) {
+	if webhook.UUID == "" {
+		return Webhook{}, fmt.Errorf("unable to update the webhook '%s': no UUID specified", webhook.Description)
+	}
+	updated, err := b.saveWebhook(ctx, "PUT", b.url("webhooks", webhook.UUID), webhook)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("unable to update the webhook '%s': %v", webhook.Description, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteWebhook deletes the webhook with the UUID
+func (b *Broker) DeleteWebhook(ctx context.Context, uuid string) error {
+	if uuid == "" {
+		return fmt.Errorf("unable to delete the webhook: no UUID specified")
+	}
+	if _, err := brokerRequest(ctx, "DELETE", b.url("webhooks", uuid), nil, b.auth()); err != nil {
+		return fmt.Errorf("unable to delete the webhook %s: %v", uuid, err)
+	}
+
+	return nil
+}
+
+// ExecuteWebhook sends the webhook's request now, to test it, with the
+// placeholders filled in from the latest pact it applies to. A request that
+// fails is reported by the execution rather than an error.
+func (b *Broker) ExecuteWebhook(ctx context.Context, uuid string) (WebhookExecution, error) {
+	content, err := brokerRequest(ctx, "POST", b.url("webhooks", uuid, "execute"), []byte("{}"), b.auth())
+
+	var result struct {
+		Success  *bool  `json:"success"`
+		Logs     string `json:"logs"`
+		Response struct {
+			Status int `json:"status"`
+		} `json:"response"`
+	}
+	if json.Unmarshal(content, &result) != nil || result.Success == nil {
+		if err == nil {
+			err = fmt.Errorf("invalid response %s", strings.TrimSpace(string(content)))
+		}
+		return WebhookExecution{}, fmt.Errorf("unable to execute the webhook %s: %v", uuid, err)
+	}
+
+	return WebhookExecution{Success: *result.Success, Status: result.Response.Status, Logs: result.Logs}, nil
+}
+
+// saveWebhook sends the webhook to the broker, returning the webhook the
+// broker saved
+func (b *Broker) saveWebhook(ctx context.Context, method string, u string, webhook Webhook) (Webhook, error) {
+	if len(webhook.Events) == 0 {
+		return Webhook{}, fmt.Errorf("no events specified")
+	}
+	document := webhookDocument{
+		UUID:        webhook.UUID,
+		Description: webhook.Description,
+		Enabled:     !webhook.Disabled,
+		Request:     webhook.Request,
+	}
+	if webhook.Consumer != "" {
+		document.Consumer = &webhookPacticipant{webhook.Consumer}
+	}
+	if webhook.Provider != "" {
+		document.Provider = &webhookPacticipant{webhook.Provider}
+	}
+	for _, event := range webhook.Events {
+		document.Events = append(document.Events, webhookEvent{event})
+	}
+
+	body, err := json.Marshal(document)
+	if err != nil {
+		return Webhook{}, err
+	}
+	content, err := brokerRequest(ctx, method, u, body, b.auth())
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	return parseWebhook(content)
+}
+
+// parseWebhook parses the broker's representation of a webhook, taking its
+// UUID from its self link if it isn't given
+func parseWebhook(content []byte) (Webhook, error) {
+	var document webhookDocument
+	if err := json.Unmarshal(content, &document); err != nil {
+		return Webhook{}, fmt.Errorf("invalid webhook %s: %v", strings.TrimSpace(string(content)), err)
+	}
+
+	webhook := Webhook{
+		UUID:        document.UUID,
+		Description: document.Description,
+		Disabled:    !document.Enabled,
+		Request:     document.Request,
+	}
+	if webhook.UUID == "" && document.Links != nil {
+		webhook.UUID = path.Base(document.Links.Self.Href)
+	}
+	if webhook.UUID == "" {
+		return Webhook{}, fmt.Errorf("invalid webhook %s: no UUID", strings.TrimSpace(string(content)))
+	}
+	if document.Consumer != nil {
+		webhook.Consumer = document.Consumer.Name
+	}
+	if document.Provider != nil {
+		webhook.Provider = document.Provider.Name
+	}
+	for _, event := range document.Events {
+		webhook.Events = append(webhook.Events, event.Name)
+	}
+
+	return webhook, nil
+}
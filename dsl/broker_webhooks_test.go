@@ -0,0 +1,160 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupWebhooksBroker() *httptest.Server {
+	webhooks := map[string]map[string]interface{}{}
+	var ids []string
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if segments[0] != "webhooks" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		save := func(id string) {
+			var document map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&document); err != nil || document["request"] == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			delete(document, "uuid")
+			delete(document["request"].(map[string]interface{}), "password")
+			document["_links"] = map[string]interface{}{"self": map[string]string{"href": fmt.Sprintf("http://%s/webhooks/%s", r.Host, id)}}
+			webhooks[id] = document
+			json.NewEncoder(w).Encode(document) // nolint:errcheck
+		}
+
+		switch {
+		case len(segments) == 1 && r.Method == "GET":
+			var links []map[string]string
+			for _, id := range ids {
+				if _, ok := webhooks[id]; ok {
+					links = append(links, map[string]string{"title": id, "href": fmt.Sprintf("http://%s/webhooks/%s", r.Host, id)})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"_links": map[string]interface{}{"pb:webhooks": links}}) // nolint:errcheck
+		case len(segments) == 1 && r.Method == "POST":
+			id := fmt.Sprintf("w%d", len(ids)+1)
+			ids = append(ids, id)
+			save(id)
+		case webhooks[segments[1]] == nil:
+			w.WriteHeader(http.StatusNotFound)
+		case len(segments) == 2 && r.Method == "GET":
+			json.NewEncoder(w).Encode(webhooks[segments[1]]) // nolint:errcheck
+		case len(segments) == 2 && r.Method == "PUT":
+			save(segments[1])
+		case len(segments) == 2 && r.Method == "DELETE":
+			delete(webhooks, segments[1])
+			w.WriteHeader(http.StatusNoContent)
+		case len(segments) == 3 && r.Method == "POST" && segments[2] == "execute":
+			url := webhooks[segments[1]]["request"].(map[string]interface{})["url"].(string)
+			if strings.Contains(url, "broken") {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"success":false,"logs":"connection refused","request":{}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"success":true,"logs":"POST %s\n200 OK","response":{"status":200}}`, url)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+func TestBroker_Webhooks(t *testing.T) {
+	s := setupWebhooksBroker()
+	defer s.Close()
+	broker := &Broker{URL: s.URL}
+	ctx := context.Background()
+
+	webhook := Webhook{
+		Description: "verify bobby",
+		Provider:    "bobby",
+		Events:      []WebhookEventName{ContractContentChanged},
+		Request: WebhookRequest{
+			Method:   "POST",
+			URL:      "https://ci.example.com/verify",
+			Headers:  map[string]string{"Content-Type": "application/json"},
+			Body:     WebhookEventTemplate,
+			Username: "ci",
+			Password: "secret",
+		},
+	}
+	created, err := broker.CreateWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	webhook.UUID = "w1"
+	webhook.Request.Password = ""
+	if !reflect.DeepEqual(created, webhook) {
+		t.Fatalf("want %+v, got %+v", webhook, created)
+	}
+
+	other, err := broker.CreateWebhook(ctx, Webhook{
+		Consumer: "billy",
+		Events:   []WebhookEventName{ProviderVerificationPublished},
+		Request:  WebhookRequest{Method: "GET", URL: "https://broken.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other.Disabled = true
+	if other, err = broker.UpdateWebhook(ctx, other); err != nil || !other.Disabled || other.Consumer != "billy" {
+		t.Fatalf("expected the webhook to be disabled, got %+v and %v", other, err)
+	}
+
+	webhooks, err := broker.ListWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []Webhook{webhook, other}; !reflect.DeepEqual(webhooks, want) {
+		t.Fatalf("want the webhooks %+v, got %+v", want, webhooks)
+	}
+
+	execution, err := broker.ExecuteWebhook(ctx, "w1")
+	if err != nil || !execution.Success || execution.Status != 200 || !strings.Contains(execution.Logs, "https://ci.example.com/verify") {
+		t.Fatalf("expected the webhook to succeed, got %+v and %v", execution, err)
+	}
+	execution, err = broker.ExecuteWebhook(ctx, "w2")
+	if err != nil || execution.Success || execution.Logs != "connection refused" {
+		t.Fatalf("expected the webhook to fail, got %+v and %v", execution, err)
+	}
+
+	if err = broker.DeleteWebhook(ctx, "w2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if webhooks, _ = broker.ListWebhooks(ctx); len(webhooks) != 1 || webhooks[0].UUID != "w1" {
+		t.Fatalf("expected the webhook to be deleted, got %+v", webhooks)
+	}
+}
+
+func TestBroker_WebhooksErrors(t *testing.T) {
+	s := setupWebhooksBroker()
+	defer s.Close()
+	broker := &Broker{URL: s.URL}
+	ctx := context.Background()
+
+	if _, err := broker.CreateWebhook(ctx, Webhook{Description: "nothing"}); err == nil || !strings.Contains(err.Error(), "unable to create the webhook 'nothing': no events specified") {
+		t.Fatalf("expected the events to be required, got %v", err)
+	}
+	if _, err := broker.UpdateWebhook(ctx, Webhook{Events: []WebhookEventName{ContractPublished}}); err == nil || !strings.Contains(err.Error(), "no UUID specified") {
+		t.Fatalf("expected the UUID to be required, got %v", err)
+	}
+	if _, err := broker.Webhook(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "unable to fetch the webhook missing") {
+		t.Fatalf("expected the unknown webhook to be reported, got %v", err)
+	}
+	if _, err := broker.ExecuteWebhook(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "unable to execute the webhook missing: unexpected status 404") {
+		t.Fatalf("expected the unknown webhook to fail to execute, got %v", err)
+	}
+	if err := broker.DeleteWebhook(ctx, "missing"); err == nil {
+		t.Fatal("expected the unknown webhook to fail to delete")
+	}
+}
@@ -0,0 +1,115 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CanIDeployResult is the broker's answer to whether a pacticipant version
+// can be deployed to an environment
+type CanIDeployResult struct {
+	// Deployable is set if every required verification was successful
+	Deployable bool
+
+	// Reason explains the broker's decision
+	Reason string
+
+	// Notices are the broker's messages about the decision
+	Notices []string
+
+	// Verifications are the required verifications, and Unresolved those
+	// that are missing or failed
+	Verifications []MatrixVerification
+	Unresolved    []MatrixVerification
+}
+
+// MatrixVerification is the verification of a consumer version's pact by a
+// provider version, from the broker's matrix
+type MatrixVerification struct {
+	Consumer        string
+	ConsumerVersion string
+	Provider        string
+	ProviderVersion string
+
+	// Verified is set if the provider version has verified the pact, and
+	// Success if it did so successfully
+	Verified bool
+	Success  bool
+}
+
+func (v MatrixVerification) String() string {
+	provider := v.Provider
+	if v.ProviderVersion != "" {
+		provider += " (" + v.ProviderVersion + ")"
+	}
+
+	switch {
+	case !v.Verified:
+		return fmt.Sprintf("the pact of %s (%s) has not been verified by %s", v.Consumer, v.ConsumerVersion, provider)
+	case !v.Success:
+		return fmt.Sprintf("the pact of %s (%s) failed verification by %s", v.Consumer, v.ConsumerVersion, provider)
+	default:
+		return fmt.Sprintf("the pact of %s (%s) was verified by %s", v.Consumer, v.ConsumerVersion, provider)
+	}
+}
+
+// CanIDeploy asks the broker's matrix whether the version of the pacticipant
+// can be deployed to the environment, as the can-i-deploy CLI does. Without
+// a version, the pacticipant's latest version is checked.
+func (b *Broker) CanIDeploy(ctx context.Context, pacticipant string, version string, environment string) (CanIDeployResult, error) {
+	if pacticipant == "" || environment == "" {
+		return CanIDeployResult{}, errors.New("a pacticipant and environment must be given to check if it can be deployed")
+	}
+
+	m, err := b.Matrix(ctx, MatrixQuery{
+		Selectors:   []MatrixSelector{{Pacticipant: pacticipant, Version: version, Latest: version == ""}},
+		LatestBy:    "cvp",
+		Environment: environment,
+	})
+	if err != nil {
+		return CanIDeployResult{}, err
+	}
+
+	result := CanIDeployResult{
+		Deployable: m.Summary.Deployable != nil && *m.Summary.Deployable,
+		Reason:     m.Summary.Reason,
+		Notices:    m.Notices,
+	}
+	for _, row := range m.Rows {
+		verification := MatrixVerification{
+			Consumer:        row.Consumer.Name,
+			ConsumerVersion: row.Consumer.Version,
+			Provider:        row.Provider.Name,
+			ProviderVersion: row.Provider.Version,
+			Verified:        row.VerificationResult != nil,
+			Success:         row.VerificationResult != nil && row.VerificationResult.Success,
+		}
+		result.Verifications = append(result.Verifications, verification)
+		if !verification.Success {
+			result.Unresolved = append(result.Unresolved, verification)
+		}
+	}
+
+	return result, nil
+}
+
+// String describes the result, listing the unresolved verifications
+func (r CanIDeployResult) String() string {
+	var b strings.Builder
+
+	if r.Deployable {
+		b.WriteString("deployable")
+	} else {
+		b.WriteString("not deployable")
+	}
+	if r.Reason != "" {
+		b.WriteString(": " + r.Reason)
+	}
+	for _, verification := range r.Unresolved {
+		b.WriteString("\n  " + verification.String())
+	}
+
+	return b.String()
+}
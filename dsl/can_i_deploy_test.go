@@ -0,0 +1,68 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBroker_CanIDeploy(t *testing.T) {
+	var query string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/matrix" {
+			w.WriteHeader(404)
+			return
+		}
+		query = r.URL.RawQuery
+		if r.URL.Query().Get("environment") == "production" {
+			fmt.Fprint(w, `{
+				"summary": {"deployable": false, "reason": "There are missing or failed verification results", "success": 1, "failed": 1, "unknown": 1},
+				"notices": [{"type": "warning", "text": "The verification by payments (2.0.0) failed"}],
+				"matrix": [
+					{"consumer": {"name": "billing", "version": {"number": "1.0.0"}}, "provider": {"name": "accounts", "version": {"number": "3.0.0"}}, "verificationResult": {"success": true}},
+					{"consumer": {"name": "billing", "version": {"number": "1.0.0"}}, "provider": {"name": "payments", "version": {"number": "2.0.0"}}, "verificationResult": {"success": false}},
+					{"consumer": {"name": "billing", "version": {"number": "1.0.0"}}, "provider": {"name": "ledger", "version": null}, "verificationResult": null}
+				]
+			}`)
+			return
+		}
+		fmt.Fprint(w, `{"summary": {"deployable": true, "reason": "All required verification results are published and successful"}, "matrix": []}`)
+	}))
+	defer s.Close()
+	broker := &Broker{URL: s.URL}
+
+	result, err := broker.CanIDeploy(context.Background(), "billing", "1.0.0", "production")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "environment=production&latestby=cvp&q%5B%5D%5Bpacticipant%5D=billing&q%5B%5D%5Bversion%5D=1.0.0" {
+		t.Fatalf("unexpected matrix query %s", query)
+	}
+	unresolved := []MatrixVerification{
+		{Consumer: "billing", ConsumerVersion: "1.0.0", Provider: "payments", ProviderVersion: "2.0.0", Verified: true},
+		{Consumer: "billing", ConsumerVersion: "1.0.0", Provider: "ledger"},
+	}
+	if result.Deployable || len(result.Verifications) != 3 || !reflect.DeepEqual(result.Unresolved, unresolved) || len(result.Notices) != 1 {
+		t.Fatalf("expected the failed and missing verifications to be unresolved, got %+v", result)
+	}
+	want := "not deployable: There are missing or failed verification results\n  the pact of billing (1.0.0) failed verification by payments (2.0.0)\n  the pact of billing (1.0.0) has not been verified by ledger"
+	if result.String() != want {
+		t.Fatalf("want %q, got %q", want, result.String())
+	}
+
+	result, err = broker.CanIDeploy(context.Background(), "billing", "", "test")
+	if err != nil || !result.Deployable || len(result.Unresolved) != 0 || !strings.Contains(query, "q%5B%5D%5Blatest%5D=true") {
+		t.Fatalf("expected the latest version to be deployable, got %+v, %v (%s)", result, err, query)
+	}
+
+	if _, err = broker.CanIDeploy(context.Background(), "billing", "1.0.0", ""); err == nil {
+		t.Fatal("expected an error without an environment")
+	}
+	if _, err = (&Broker{URL: s.URL + "/missing"}).CanIDeploy(context.Background(), "billing", "1.0.0", "test"); err == nil {
+		t.Fatal("expected an error from the broker")
+	}
+}
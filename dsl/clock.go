@@ -0,0 +1,67 @@
+package dsl
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Sources of time and randomness used when generating example values.
+// They can be overridden with SetClock and SetRandSource to make generated
+// examples, and therefore pact files, fully deterministic under test.
+var (
+	sourceMutex sync.Mutex
+	clock       = time.Now
+	random      = newDefaultRand()
+)
+
+func newDefaultRand() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// SetClock overrides the clock used when generating time based example values.
+// Passing nil restores the system clock.
+func SetClock(now func() time.Time) {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	if now == nil {
+		now = time.Now
+	}
+	clock = now
+}
+
+// SetRandSource overrides the source of randomness used when generating
+// example values (e.g. from a regular expression or a faker). Passing nil
+// restores a source seeded from the current time.
+func SetRandSource(src rand.Source) {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	if src == nil {
+		random = newDefaultRand()
+		return
+	}
+	random = rand.New(src)
+}
+
+// now returns the current time according to the configured clock
+func now() time.Time {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	return clock()
+}
+
+// randomIntn returns a non-negative pseudo-random number in [0,n) from the
+// configured source of randomness
+func randomIntn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	return random.Intn(n)
+}
@@ -0,0 +1,50 @@
+package dsl
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestClock_SetClock(t *testing.T) {
+	frozen := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return frozen })
+	defer SetClock(nil)
+
+	if got := now(); !got.Equal(frozen) {
+		t.Fatalf("want %v, got %v", frozen, got)
+	}
+
+	SetClock(nil)
+	if got := now(); got.Equal(frozen) {
+		t.Fatal("expected the system clock to be restored")
+	}
+}
+
+func TestClock_SetRandSource(t *testing.T) {
+	defer SetRandSource(nil)
+
+	generate := func() []int {
+		SetRandSource(rand.NewSource(42))
+		values := make([]int, 10)
+		for i := range values {
+			values[i] = randomIntn(1000)
+		}
+		return values
+	}
+
+	first := generate()
+	second := generate()
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same sequence for the same seed, got %v and %v", first, second)
+		}
+	}
+}
+
+func TestClock_RandomIntnNonPositive(t *testing.T) {
+	if got := randomIntn(0); got != 0 {
+		t.Fatalf("want 0, got %d", got)
+	}
+}
@@ -0,0 +1,80 @@
+package dsl
+
+// etagRegex matches strong and weak entity tags e.g. "abc" or W/"abc"
+const etagRegex = `^(W/)?"[^"]*"$`
+
+// ConditionalGet describes a conditional GET flow, in which a consumer caches
+// a resource along with its ETag, and later revalidates it with If-None-Match.
+type ConditionalGet struct {
+	// Description of the resource e.g. "user 1", used to describe the
+	// interactions
+	Description string
+
+	// State is the provider state of both interactions. Optional.
+	State string
+
+	// Path of the resource. Must be a plain string for the responses to be
+	// sequenced.
+	Path string
+
+	// Headers of the requests. Optional.
+	Headers MapMatcher
+
+	// ETag of the resource, including quotes e.g. `"33a64df5"`
+	ETag string
+
+	// Body of the 200 response
+	Body interface{}
+
+	// Headers of the 200 response, in addition to the ETag. Optional.
+	ResponseHeaders MapMatcher
+}
+
+// AddConditionalGet adds the interactions of a conditional GET flow: a 200
+// response emitting an ETag, followed by a 304 response to a request with a
+// matching If-None-Match header. The responses are sequenced, so the first
+// request for the resource receives the 200 and subsequent requests the 304.
+func (p *Pact) AddConditionalGet(c ConditionalGet) (fetch *Interaction, revalidate *Interaction) {
+	fetchHeaders := MapMatcher{}
+	for name, value := range c.ResponseHeaders {
+		fetchHeaders[name] = value
+	}
+	fetchHeaders["ETag"] = Term(c.ETag, etagRegex)
+
+	revalidateHeaders := MapMatcher{}
+	for name, value := range c.Headers {
+		revalidateHeaders[name] = value
+	}
+	revalidateHeaders["If-None-Match"] = Term(c.ETag, etagRegex)
+
+	fetch = p.AddInteraction().
+		Given(c.State).
+		UponReceiving("a request for " + c.Description).
+		WithRequest(Request{
+			Method:  "GET",
+			Path:    String(c.Path),
+			Headers: c.Headers,
+		}).
+		WillRespondWith(Response{
+			Status:  200,
+			Headers: fetchHeaders,
+			Body:    c.Body,
+		}).
+		Sequence(1)
+
+	revalidate = p.AddInteraction().
+		Given(c.State).
+		UponReceiving("a conditional request for " + c.Description).
+		WithRequest(Request{
+			Method:  "GET",
+			Path:    String(c.Path),
+			Headers: revalidateHeaders,
+		}).
+		WillRespondWith(Response{
+			Status:  304,
+			Headers: MapMatcher{"ETag": Term(c.ETag, etagRegex)},
+		}).
+		Sequence(2)
+
+	return fetch, revalidate
+}
@@ -0,0 +1,127 @@
+package dsl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ContentHandler encodes and decodes message content of a given content
+// type, e.g. protobuf, Avro or MsgPack
+type ContentHandler interface {
+	// Encode serialises the value
+	Encode(v interface{}) ([]byte, error)
+
+	// Decode deserialises the data into the value, a pointer
+	Decode(data []byte, v interface{}) error
+}
+
+// jsonContentHandler is the built in handler for JSON content. JSON content
+// is written to the pact as-is, so that it may contain matchers.
+type jsonContentHandler struct{}
+
+func (jsonContentHandler) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonContentHandler) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+var (
+	contentHandlersMu sync.RWMutex
+	contentHandlers   = map[string]ContentHandler{
+		"application/json":        jsonContentHandler{},
+		MsgPackContentType:        msgpackContentHandler{},
+		"application/x-msgpack":   msgpackContentHandler{},
+		"application/vnd.msgpack": msgpackContentHandler{},
+	}
+)
+
+// RegisterContentHandler registers the handler for message content of the
+// content type (e.g. "application/x-protobuf"), replacing any existing
+// handler. JSON and MessagePack are supported out of the box.
+func RegisterContentHandler(contentType string, handler ContentHandler) {
+	contentHandlersMu.Lock()
+	defer contentHandlersMu.Unlock()
+
+	contentHandlers[mediaType(contentType)] = handler
+}
+
+// contentHandler finds the handler for the content type. Structured syntax
+// suffixed JSON types (e.g. "application/vnd.orders+json") are handled as JSON.
+func contentHandler(contentType string) (ContentHandler, error) {
+	contentHandlersMu.RLock()
+	defer contentHandlersMu.RUnlock()
+
+	media := mediaType(contentType)
+	if handler, ok := contentHandlers[media]; ok {
+		return handler, nil
+	}
+	if strings.HasSuffix(media, "+json") {
+		return contentHandlers["application/json"], nil
+	}
+
+	return nil, fmt.Errorf("no content handler registered for content type '%s'", contentType)
+}
+
+// EncodeContent encodes the value as message content of the content type,
+// in the form it is written to a pact file: JSON content is unchanged, other
+// content is encoded with its registered handler and then base64 encoded.
+// Message providers may use it to produce content for verification.
+func EncodeContent(contentType string, v interface{}) (interface{}, error) {
+	handler, err := contentHandler(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := handler.(jsonContentHandler); ok {
+		return v, nil
+	}
+
+	data, err := handler.Encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode content as '%s': %v", contentType, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// decodeContent decodes reified (JSON) message content of the content type
+// into a new value of the same type as t
+func decodeContent(contentType string, reified []byte, t interface{}) (interface{}, error) {
+	handler, err := contentHandler(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	data := reified
+	if _, ok := handler.(jsonContentHandler); !ok {
+		var encoded string
+		if err = json.Unmarshal(reified, &encoded); err != nil {
+			return nil, fmt.Errorf("expected base64 encoded '%s' content: %v", contentType, err)
+		}
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("expected base64 encoded '%s' content: %v", contentType, err)
+		}
+	}
+
+	value := reflect.New(reflect.TypeOf(t))
+	if err = handler.Decode(data, value.Interface()); err != nil {
+		return nil, fmt.Errorf("unable to decode '%s' content: %v", contentType, err)
+	}
+
+	return value.Elem().Interface(), nil
+}
+
+// mediaType returns the content type without any parameters
+func mediaType(contentType string) string {
+	if media, _, err := mime.ParseMediaType(contentType); err == nil {
+		return media
+	}
+
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
@@ -0,0 +1,108 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+type point struct {
+	X, Y int
+}
+
+// csvContentHandler is a stand in for a binary format such as protobuf
+type csvContentHandler struct{}
+
+func (csvContentHandler) Encode(v interface{}) ([]byte, error) {
+	p, ok := v.(point)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+	return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+}
+
+func (csvContentHandler) Decode(data []byte, v interface{}) error {
+	p := v.(*point)
+	_, err := fmt.Sscanf(string(data), "%d,%d", &p.X, &p.Y)
+	return err
+}
+
+func TestContentHandler_Lookup(t *testing.T) {
+	RegisterContentHandler("text/csv", csvContentHandler{})
+
+	tests := map[string]interface{}{
+		"application/json; charset=utf-8": jsonContentHandler{},
+		"application/vnd.orders+json":     jsonContentHandler{},
+		"TEXT/CSV":                        csvContentHandler{},
+	}
+	for contentType, want := range tests {
+		handler, err := contentHandler(contentType)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", contentType, err)
+		}
+		if reflect.TypeOf(handler) != reflect.TypeOf(want) {
+			t.Fatalf("want %T for %s, got %T", want, contentType, handler)
+		}
+	}
+
+	if _, err := contentHandler("application/avro"); err == nil {
+		t.Fatal("expected an error for an unregistered content type")
+	}
+}
+
+func TestEncodeContent(t *testing.T) {
+	RegisterContentHandler("text/csv", csvContentHandler{})
+
+	body := StructMatcher{"id": Like(1)}
+	if encoded, _ := EncodeContent("application/json", body); !reflect.DeepEqual(encoded, body) {
+		t.Fatalf("expected JSON content to be unchanged, got %v", encoded)
+	}
+
+	encoded, err := EncodeContent("text/csv", point{1, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if encoded != "MSwy" {
+		t.Fatalf("expected base64 encoded content, got %v", encoded)
+	}
+}
+
+func TestMessage_WithContentType(t *testing.T) {
+	RegisterContentHandler("text/csv", csvContentHandler{})
+
+	c := newMockClient()
+	c.ReifyMessageResponse = &types.ReificationResponse{ResponseRaw: []byte(`"MSwy"`)}
+	pact := &Pact{pactClient: c}
+
+	message := pact.AddMessage().
+		ExpectsToReceive("a point").
+		WithContentType("text/csv", point{1, 2})
+
+	if message.Content != "MSwy" {
+		t.Fatalf("unexpected content %v", message.Content)
+	}
+	metadata, _ := json.Marshal(message.Metadata)
+	if string(metadata) != `{"contentType":"text/csv"}` {
+		t.Fatalf("expected the content type in the metadata, got %s", metadata)
+	}
+
+	var received interface{}
+	err := pact.VerifyMessageConsumerRaw(message, func(m Message) error {
+		received = m.Content
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received != (point{1, 2}) {
+		t.Fatalf("expected the decoded struct, got %#v", received)
+	}
+
+	invalid := pact.AddMessage().WithContentType("application/avro", point{})
+	if err := pact.VerifyMessageConsumerRaw(invalid, func(Message) error { return nil }); err == nil {
+		t.Fatal("expected an error for an unregistered content type")
+	}
+}
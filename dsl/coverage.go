@@ -0,0 +1,245 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Route is an endpoint exposed by a provider. The path may be a template
+// such as "/users/{id}" (OpenAPI, chi, gorilla), "/users/:id" (gin) or a
+// subtree pattern ending in "/" or "*" (http.ServeMux, gin).
+//
+// An empty Method matches any HTTP method.
+type Route struct {
+	Method string
+	Path   string
+}
+
+func (r Route) String() string {
+	method := r.Method
+	if method == "" {
+		method = "*"
+	}
+
+	return fmt.Sprintf("%s %s", method, r.Path)
+}
+
+// RouteCoverage lists the pact interactions that exercise a route
+type RouteCoverage struct {
+	Route Route
+
+	// Interactions in the form "<consumer>: <description>"
+	Interactions []string
+}
+
+// CoverageReport describes how well a provider's routes are covered by the
+// interactions in its pacts
+type CoverageReport struct {
+	// Covered are routes exercised by at least one interaction
+	Covered []RouteCoverage
+
+	// Uncovered are routes no consumer relies on, and which may be candidates
+	// for deprecation
+	Uncovered []Route
+
+	// Unmatched are interactions that match no known route, in the form
+	// "<consumer>: <description>"
+	Unmatched []string
+}
+
+// String renders a human readable summary of the report
+func (r CoverageReport) String() string {
+	var b strings.Builder
+	total := len(r.Covered) + len(r.Uncovered)
+	fmt.Fprintf(&b, "%d of %d routes covered by consumer pacts\n", len(r.Covered), total)
+
+	if len(r.Uncovered) > 0 {
+		b.WriteString("\nRoutes with no consumer coverage:\n")
+		for _, route := range r.Uncovered {
+			fmt.Fprintf(&b, "\t%s\n", route)
+		}
+	}
+
+	if len(r.Unmatched) > 0 {
+		b.WriteString("\nInteractions matching no known route:\n")
+		for _, interaction := range r.Unmatched {
+			fmt.Fprintf(&b, "\t%s\n", interaction)
+		}
+	}
+
+	return b.String()
+}
+
+// ContractCoverage compares a provider's routes against the interactions in
+// the given pact files (or directories of pact files), reporting endpoints
+// with no consumer coverage.
+func ContractCoverage(routes []Route, pactFiles ...string) (*CoverageReport, error) {
+	files, err := expandPactFiles(pactFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	covered := make([][]string, len(routes))
+	report := &CoverageReport{}
+
+	for _, file := range files {
+		pact, err := pactfile.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read pact file %s: %v", file, err)
+		}
+
+		for _, interaction := range pact.Interactions {
+			name := fmt.Sprintf("%s: %s", pact.Consumer.Name, interaction.Description)
+			matched := false
+
+			for i, route := range routes {
+				if route.matches(interaction.Request.Method, interaction.Request.Path) {
+					covered[i] = append(covered[i], name)
+					matched = true
+				}
+			}
+
+			if !matched {
+				report.Unmatched = append(report.Unmatched, name)
+			}
+		}
+	}
+
+	for i, route := range routes {
+		if len(covered[i]) == 0 {
+			report.Uncovered = append(report.Uncovered, route)
+			continue
+		}
+		sort.Strings(covered[i])
+		report.Covered = append(report.Covered, RouteCoverage{Route: route, Interactions: covered[i]})
+	}
+	sort.Strings(report.Unmatched)
+
+	return report, nil
+}
+
+// RoutesFromOpenAPI extracts the routes from an OpenAPI (or Swagger) document,
+// in either JSON or YAML format
+func RoutesFromOpenAPI(file string) ([]Route, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Paths map[string]map[string]interface{} `yaml:"paths"`
+	}
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse OpenAPI document %s: %v", file, err)
+	}
+
+	var routes []Route
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			switch strings.ToUpper(method) {
+			case "GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE":
+				routes = append(routes, Route{Method: strings.ToUpper(method), Path: path})
+			}
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+
+	return routes, nil
+}
+
+// RoutesFromPatterns creates method agnostic routes from a set of
+// http.ServeMux patterns
+func RoutesFromPatterns(patterns ...string) []Route {
+	routes := make([]Route, len(patterns))
+	for i, pattern := range patterns {
+		routes[i] = Route{Path: pattern}
+	}
+
+	return routes
+}
+
+// matches determines if a concrete request matches the route template
+func (r Route) matches(method, path string) bool {
+	if r.Method != "" && r.Method != "*" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+
+	template := splitPath(r.Path)
+	actual := splitPath(path)
+
+	for i, segment := range template {
+		if isWildcardSegment(segment) {
+			return true
+		}
+		if i >= len(actual) {
+			return false
+		}
+		if !isParamSegment(segment) && segment != actual[i] {
+			return false
+		}
+	}
+
+	if len(template) < len(actual) {
+		// http.ServeMux patterns ending in a slash match the entire subtree
+		return strings.HasSuffix(r.Path, "/") && r.Method == ""
+	}
+
+	return len(template) == len(actual)
+}
+
+func splitPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
+}
+
+func isParamSegment(segment string) bool {
+	return strings.HasPrefix(segment, ":") ||
+		(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"))
+}
+
+func isWildcardSegment(segment string) bool {
+	return segment == "*" || strings.HasPrefix(segment, "*") || strings.HasSuffix(segment, "...}")
+}
+
+// expandPactFiles replaces any directories with the pact files they contain
+func expandPactFiles(locations []string) ([]string, error) {
+	var files []string
+	for _, location := range locations {
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, location)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(location, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,33 @@
+/*
+Package ginroutes lists the routes registered with a gin engine, to compare
+against a provider's pacts with dsl.ContractCoverage without the dsl package
+depending on gin.
+
+	routes := ginroutes.Routes(engine)
+	report, _ := dsl.ContractCoverage(routes, "./pacts")
+*/
+package ginroutes
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pact-foundation/pact-go/dsl"
+)
+
+// Routes lists the routes registered with a gin engine
+func Routes(engine *gin.Engine) []dsl.Route {
+	var routes []dsl.Route
+	for _, route := range engine.Routes() {
+		routes = append(routes, dsl.Route{Method: route.Method, Path: route.Path})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+
+	return routes
+}
@@ -0,0 +1,30 @@
+package ginroutes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pact-foundation/pact-go/dsl"
+)
+
+func TestRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/accounts/:id", func(*gin.Context) {})
+	engine.DELETE("/accounts/:id", func(*gin.Context) {})
+	engine.POST("/accounts", func(*gin.Context) {})
+	engine.GET("/static/*filepath", func(*gin.Context) {})
+
+	routes := Routes(engine)
+
+	expected := []dsl.Route{
+		{Method: "POST", Path: "/accounts"},
+		{Method: "DELETE", Path: "/accounts/:id"},
+		{Method: "GET", Path: "/accounts/:id"},
+		{Method: "GET", Path: "/static/*filepath"},
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Fatalf("want %v, got %v", expected, routes)
+	}
+}
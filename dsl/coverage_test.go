@@ -0,0 +1,128 @@
+package dsl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoute_matches(t *testing.T) {
+	tests := []struct {
+		route  Route
+		method string
+		path   string
+		want   bool
+	}{
+		{route: Route{Method: "GET", Path: "/users"}, method: "GET", path: "/users", want: true},
+		{route: Route{Method: "GET", Path: "/users"}, method: "POST", path: "/users", want: false},
+		{route: Route{Method: "get", Path: "/users/{id}"}, method: "GET", path: "/users/10", want: true},
+		{route: Route{Method: "GET", Path: "/users/:id"}, method: "GET", path: "/users/10", want: true},
+		{route: Route{Method: "GET", Path: "/users/:id"}, method: "GET", path: "/users/10/posts", want: false},
+		{route: Route{Method: "GET", Path: "/static/*filepath"}, method: "GET", path: "/static/css/main.css", want: true},
+		{route: Route{Method: "GET", Path: "/files/{path...}"}, method: "GET", path: "/files/a/b", want: true},
+		{route: Route{Path: "/api/"}, method: "DELETE", path: "/api/users/1", want: true},
+		{route: Route{Path: "/api"}, method: "DELETE", path: "/api/users/1", want: false},
+		{route: Route{Path: "/"}, method: "GET", path: "/anything", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route.String()+" "+tt.path, func(t *testing.T) {
+			if got := tt.route.matches(tt.method, tt.path); got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestContractCoverage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pact := `{
+		"consumer": {"name": "billing"},
+		"provider": {"name": "accounts"},
+		"interactions": [
+			{"description": "get account", "request": {"method": "GET", "path": "/accounts/1"}, "response": {"status": 200}},
+			{"description": "legacy export", "request": {"method": "GET", "path": "/export"}, "response": {"status": 200}}
+		]
+	}`
+	ioutil.WriteFile(filepath.Join(dir, "billing-accounts.json"), []byte(pact), 0644)
+
+	routes := []Route{
+		{Method: "GET", Path: "/accounts/{id}"},
+		{Method: "DELETE", Path: "/accounts/{id}"},
+	}
+
+	report, err := ContractCoverage(routes, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedCovered := []RouteCoverage{{Route: routes[0], Interactions: []string{"billing: get account"}}}
+	if !reflect.DeepEqual(report.Covered, expectedCovered) {
+		t.Fatalf("want covered %v, got %v", expectedCovered, report.Covered)
+	}
+	if !reflect.DeepEqual(report.Uncovered, []Route{routes[1]}) {
+		t.Fatalf("unexpected uncovered routes %v", report.Uncovered)
+	}
+	if !reflect.DeepEqual(report.Unmatched, []string{"billing: legacy export"}) {
+		t.Fatalf("unexpected unmatched interactions %v", report.Unmatched)
+	}
+
+	summary := report.String()
+	if !strings.Contains(summary, "1 of 2 routes covered") || !strings.Contains(summary, "DELETE /accounts/{id}") {
+		t.Fatalf("unexpected summary %s", summary)
+	}
+}
+
+func TestContractCoverage_MissingFile(t *testing.T) {
+	if _, err := ContractCoverage(nil, "/does/not/exist.json"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestRoutesFromOpenAPI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	doc := `
+openapi: 3.0.0
+paths:
+  /accounts/{id}:
+    parameters:
+      - name: id
+        in: path
+    get:
+      summary: get an account
+    delete:
+      summary: delete an account
+  /accounts:
+    post:
+      summary: create an account
+`
+	file := filepath.Join(dir, "openapi.yaml")
+	ioutil.WriteFile(file, []byte(doc), 0644)
+
+	routes, err := RoutesFromOpenAPI(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Route{
+		{Method: "POST", Path: "/accounts"},
+		{Method: "DELETE", Path: "/accounts/{id}"},
+		{Method: "GET", Path: "/accounts/{id}"},
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Fatalf("want %v, got %v", expected, routes)
+	}
+}
@@ -0,0 +1,113 @@
+package dsl
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// startDependencies starts each dependency in order and waits for it to be
+// ready, returning a function that stops them in reverse order. If any fail
+// to start, those already started are stopped.
+func startDependencies(dependencies []types.Dependency) (func(), error) {
+	var started []types.Dependency
+	stop := func() {
+		for i := len(started) - 1; i >= 0; i-- {
+			d := started[i]
+			if d.Stop == nil {
+				continue
+			}
+			log.Println("[DEBUG] stopping dependency", d.Name)
+			if err := d.Stop(); err != nil {
+				log.Printf("[WARN] unable to stop dependency '%s': %v\n", d.Name, err)
+			}
+		}
+	}
+
+	for _, d := range dependencies {
+		if d.Start == nil {
+			stop()
+			return nil, fmt.Errorf("dependency '%s' has no Start hook", d.Name)
+		}
+
+		log.Println("[DEBUG] starting dependency", d.Name)
+		if err := d.Start(); err != nil {
+			stop()
+			return nil, fmt.Errorf("unable to start dependency '%s': %v", d.Name, err)
+		}
+		started = append(started, d)
+
+		if err := waitForDependency(d); err != nil {
+			stop()
+			return nil, err
+		}
+	}
+
+	return stop, nil
+}
+
+// waitForDependency polls the ready check of the dependency until it passes
+func waitForDependency(d types.Dependency) error {
+	if d.Ready == nil {
+		return nil
+	}
+
+	timeout := d.ReadyTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	interval := d.ReadyInterval
+	if interval == 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	// The wall clock is used, as the clock may be frozen by SetClock
+	deadline := time.After(timeout)
+	for {
+		err := d.Ready()
+		if err == nil {
+			log.Println("[DEBUG] dependency", d.Name, "is ready")
+			return nil
+		}
+
+		select {
+		case <-deadline:
+			return fmt.Errorf("timed out after %s waiting for dependency '%s' to be ready: %v", timeout, d.Name, err)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// HTTPReadyCheck returns a ready check that passes once the URL responds with
+// a non 5xx status
+func HTTPReadyCheck(url string) types.Hook {
+	return func() error {
+		client := &http.Client{Timeout: 5 * time.Second}
+		res, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+
+		if res.StatusCode >= 500 {
+			return fmt.Errorf("%s responded with status %d", url, res.StatusCode)
+		}
+		return nil
+	}
+}
+
+// TCPReadyCheck returns a ready check that passes once a connection can be
+// made to the address e.g. "localhost:5432"
+func TCPReadyCheck(address string) types.Hook {
+	return func() error {
+		conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
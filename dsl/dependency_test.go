@@ -0,0 +1,132 @@
+package dsl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestStartDependencies(t *testing.T) {
+	var calls []string
+	dependency := func(name string) types.Dependency {
+		return types.Dependency{
+			Name:  name,
+			Start: func() error { calls = append(calls, "start "+name); return nil },
+			Stop:  func() error { calls = append(calls, "stop "+name); return nil },
+		}
+	}
+
+	readyAfter := 2
+	db := dependency("db")
+	db.ReadyInterval = time.Millisecond
+	db.Ready = func() error {
+		readyAfter--
+		if readyAfter > 0 {
+			return errors.New("not ready")
+		}
+		calls = append(calls, "ready db")
+		return nil
+	}
+
+	stop, err := startDependencies([]types.Dependency{db, dependency("queue")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop()
+
+	expected := []string{"start db", "ready db", "start queue", "stop queue", "stop db"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("want %v, got %v", expected, calls)
+	}
+}
+
+func TestStartDependencies_Failures(t *testing.T) {
+	var stopped []string
+	started := types.Dependency{
+		Name:  "db",
+		Start: func() error { return nil },
+		Stop:  func() error { stopped = append(stopped, "db"); return nil },
+	}
+
+	tests := []struct {
+		name       string
+		dependency types.Dependency
+		want       string
+	}{
+		{
+			name:       "start fails",
+			dependency: types.Dependency{Name: "queue", Start: func() error { return errors.New("boom") }},
+			want:       "unable to start dependency 'queue': boom",
+		},
+		{
+			name: "never ready",
+			dependency: types.Dependency{
+				Name:          "queue",
+				Start:         func() error { return nil },
+				Ready:         func() error { return errors.New("connection refused") },
+				ReadyTimeout:  5 * time.Millisecond,
+				ReadyInterval: time.Millisecond,
+			},
+			want: "waiting for dependency 'queue' to be ready: connection refused",
+		},
+		{
+			name:       "no start hook",
+			dependency: types.Dependency{Name: "queue"},
+			want:       "dependency 'queue' has no Start hook",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stopped = nil
+			_, err := startDependencies([]types.Dependency{started, tt.dependency})
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if !reflect.DeepEqual(stopped, []string{"db"}) {
+				t.Fatalf("expected started dependencies to be stopped, got %v", stopped)
+			}
+		})
+	}
+}
+
+func TestWaitForDependency_FrozenClock(t *testing.T) {
+	SetClock(func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) })
+	defer SetClock(nil)
+
+	err := waitForDependency(types.Dependency{
+		Name:          "queue",
+		Ready:         func() error { return errors.New("connection refused") },
+		ReadyTimeout:  5 * time.Millisecond,
+		ReadyInterval: time.Millisecond,
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 5ms") {
+		t.Fatalf("expected the ready check to time out, got %v", err)
+	}
+}
+
+func TestReadyChecks(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	if err := HTTPReadyCheck(healthy.URL)(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := HTTPReadyCheck(unhealthy.URL)(); err == nil {
+		t.Fatal("expected an error for a 503 response")
+	}
+	if err := TCPReadyCheck(strings.TrimPrefix(healthy.URL, "http://"))(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
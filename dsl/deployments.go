@@ -0,0 +1,56 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// RecordDeployment records that the version of the pacticipant is deployed
+// to the environment, replacing the version previously deployed there, as
+// the record-deployment CLI does. The environment must exist in the broker.
+func (b *Broker) RecordDeployment(ctx context.Context, pacticipant string, version string, environment string) error {
+	return b.record(ctx, "pb:record-deployment", "deployment", pacticipant, version, environment)
+}
+
+// RecordRelease records that the version of the pacticipant is released to
+// the environment, alongside any versions released before it, as the
+// record-release CLI does. The environment must exist in the broker.
+func (b *Broker) RecordRelease(ctx context.Context, pacticipant string, version string, environment string) error {
+	return b.record(ctx, "pb:record-release", "release", pacticipant, version, environment)
+}
+
+// record follows the version's link for recording a deployment or release
+// to the environment, named by the link
+func (b *Broker) record(ctx context.Context, relation string, kind string, pacticipant string, version string, environment string) error {
+	u := b.url("pacticipants", pacticipant, "versions", version)
+	content, err := brokerRequest(ctx, "GET", u, nil, b.auth())
+	if err != nil {
+		return fmt.Errorf("unable to record the %s of %s version %s: %v", kind, pacticipant, version, err)
+	}
+
+	var resource struct {
+		Links map[string]json.RawMessage `json:"_links"`
+	}
+	var links []struct {
+		Name string `json:"name"`
+		Href string `json:"href"`
+	}
+	if err = json.Unmarshal(content, &resource); err == nil && resource.Links[relation] != nil {
+		err = json.Unmarshal(resource.Links[relation], &links)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to record the %s of %s version %s: invalid response from %s: %v", kind, pacticipant, version, u, err)
+	}
+
+	for _, link := range links {
+		if link.Name == environment {
+			if err = b.send(ctx, "POST", link.Href, struct{}{}); err != nil {
+				return fmt.Errorf("unable to record the %s of %s version %s to %s: %v", kind, pacticipant, version, environment, err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unable to record the %s of %s version %s: the broker has no environment '%s'", kind, pacticipant, version, environment)
+}
@@ -0,0 +1,51 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBroker_RecordDeploymentAndRelease(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/pacticipants/billy/versions/1.0.0":
+			fmt.Fprintf(w, `{"_links":{
+				"pb:record-deployment":[{"name":"production","href":"http://%[1]s/deployed/production"}],
+				"pb:record-release":[{"name":"production","href":"http://%[1]s/released/production"}]
+			}}`, r.Host)
+		case r.Method == "GET":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer s.Close()
+	broker := &Broker{URL: s.URL}
+
+	if err := broker.RecordDeployment(context.Background(), "billy", "1.0.0", "production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := broker.RecordRelease(context.Background(), "billy", "1.0.0", "production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"POST /deployed/production", "POST /released/production"}
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("want the requests %v, got %v", want, requests)
+	}
+
+	err := broker.RecordDeployment(context.Background(), "billy", "1.0.0", "staging")
+	if err == nil || !strings.Contains(err.Error(), "the broker has no environment 'staging'") {
+		t.Fatalf("expected the unknown environment to be reported, got %v", err)
+	}
+	err = broker.RecordRelease(context.Background(), "billy", "2.0.0", "production")
+	if err == nil || !strings.Contains(err.Error(), "unable to record the release of billy version 2.0.0") {
+		t.Fatalf("expected the unknown version to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// checkDeprecations warns about any interactions in the local pact files that
+// still rely on deprecated endpoints or fields, returning an error if any are
+// relied upon past their FailAfter date
+func checkDeprecations(deprecations []types.Deprecation, pactURLs []string) error {
+	if len(deprecations) == 0 {
+		return nil
+	}
+
+	fields := make([]*RulePath, len(deprecations))
+	for i, d := range deprecations {
+		if d.Path == "" {
+			return errors.New("deprecation is missing a Path")
+		}
+		if d.Field != "" {
+			path, err := ParseRulePath(d.Field)
+			if err != nil {
+				return fmt.Errorf("invalid deprecated field: %v", err)
+			}
+			fields[i] = &path
+		}
+	}
+
+	var failures []string
+	for _, location := range pactURLs {
+		if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+			log.Println("[DEBUG] skipping deprecation checks for remote pact", location)
+			continue
+		}
+
+		files, err := expandPactFiles([]string{location})
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			pact, err := pactfile.Read(file)
+			if err != nil {
+				return fmt.Errorf("unable to read pact file %s: %v", file, err)
+			}
+
+			for _, interaction := range pact.Interactions {
+				for i, d := range deprecations {
+					if !reliesOn(interaction, d, fields[i]) {
+						continue
+					}
+
+					message := deprecationMessage(pact.Consumer.Name, interaction.Description, d)
+					if !d.FailAfter.IsZero() && now().After(d.FailAfter) {
+						failures = append(failures, message)
+						continue
+					}
+					log.Println("[WARN]", message)
+				}
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("pacts rely on deprecations past their removal date:\n\t%s", strings.Join(failures, "\n\t"))
+	}
+
+	return nil
+}
+
+// reliesOn determines if the interaction uses the deprecated endpoint or field
+func reliesOn(interaction pactfile.Interaction, d types.Deprecation, field *RulePath) bool {
+	route := Route{Method: d.Method, Path: d.Path}
+	if !route.matches(interaction.Request.Method, interaction.Request.Path) {
+		return false
+	}
+
+	if field == nil {
+		return true
+	}
+
+	for _, body := range []json.RawMessage{interaction.Request.Body, interaction.Response.Body} {
+		if len(body) == 0 {
+			continue
+		}
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			continue
+		}
+		if field.existsIn(doc) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func deprecationMessage(consumer, description string, d types.Deprecation) string {
+	subject := Route{Method: d.Method, Path: d.Path}.String()
+	if d.Field != "" {
+		subject = fmt.Sprintf("field %s of %s", d.Field, subject)
+	}
+
+	message := fmt.Sprintf("consumer '%s' still relies on deprecated %s in interaction '%s'", consumer, subject, description)
+	if d.Reason != "" {
+		message = fmt.Sprintf("%s: %s", message, d.Reason)
+	}
+
+	return message
+}
+
+// existsIn determines if the path resolves to a value within the document,
+// wildcards match if any element matches
+func (p RulePath) existsIn(doc interface{}) bool {
+	if len(p.tokens) == 0 {
+		return true
+	}
+
+	rest := RulePath{tokens: p.tokens[1:]}
+	switch token := p.tokens[0]; token.kind {
+	case keyToken:
+		if obj, ok := doc.(map[string]interface{}); ok {
+			if value, ok := obj[token.key]; ok {
+				return rest.existsIn(value)
+			}
+		}
+	case indexToken:
+		if arr, ok := doc.([]interface{}); ok && token.index < len(arr) {
+			return rest.existsIn(arr[token.index])
+		}
+	case anyIndexToken:
+		if arr, ok := doc.([]interface{}); ok {
+			for _, value := range arr {
+				if rest.existsIn(value) {
+					return true
+				}
+			}
+		}
+	case anyKeyToken:
+		if obj, ok := doc.(map[string]interface{}); ok {
+			for _, value := range obj {
+				if rest.existsIn(value) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package dsl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func writeDeprecationPact(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "pact-deprecation")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pact := `{
+		"consumer": {"name": "billing"},
+		"provider": {"name": "accounts"},
+		"interactions": [
+			{
+				"description": "get account",
+				"request": {"method": "GET", "path": "/accounts/1"},
+				"response": {"status": 200, "body": {"id": 1, "items": [{"legacyCode": "a"}]}}
+			}
+		]
+	}`
+	ioutil.WriteFile(filepath.Join(dir, "billing-accounts.json"), []byte(pact), 0644)
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestCheckDeprecations(t *testing.T) {
+	dir, cleanup := writeDeprecationPact(t)
+	defer cleanup()
+
+	SetClock(func() time.Time { return time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC) })
+	defer SetClock(nil)
+
+	removal := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		deprecation types.Deprecation
+		wantErr     bool
+	}{
+		{name: "unused endpoint", deprecation: types.Deprecation{Method: "DELETE", Path: "/accounts/{id}", FailAfter: removal}},
+		{name: "used endpoint before removal", deprecation: types.Deprecation{Path: "/accounts/{id}"}},
+		{name: "used endpoint after removal", deprecation: types.Deprecation{Method: "GET", Path: "/accounts/{id}", FailAfter: removal}, wantErr: true},
+		{name: "used field after removal", deprecation: types.Deprecation{Path: "/accounts/{id}", Field: "$.items[*].legacyCode", FailAfter: removal}, wantErr: true},
+		{name: "unused field after removal", deprecation: types.Deprecation{Path: "/accounts/{id}", Field: "$.legacyId", FailAfter: removal}},
+		{name: "missing path", deprecation: types.Deprecation{Field: "$.id"}, wantErr: true},
+		{name: "invalid field", deprecation: types.Deprecation{Path: "/accounts", Field: "legacyId"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDeprecations([]types.Deprecation{tt.deprecation}, []string{dir, "http://broker/pacts/1"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckDeprecations_Message(t *testing.T) {
+	dir, cleanup := writeDeprecationPact(t)
+	defer cleanup()
+
+	err := checkDeprecations([]types.Deprecation{{
+		Path:      "/accounts/{id}",
+		Field:     "$.id",
+		Reason:    "use uuid instead",
+		FailAfter: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}, []string{dir})
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"billing", "field $.id of * /accounts/{id}", "get account", "use uuid instead"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to contain %q, got %v", want, err)
+		}
+	}
+}
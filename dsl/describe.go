@@ -0,0 +1,133 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// described wraps a Matcher with a human readable description of the field,
+// it is otherwise transparent to the mock service
+type described struct {
+	Matcher
+	Description string
+}
+
+func describe(m Matcher, description string) Matcher {
+	if d, ok := m.(described); ok {
+		m = d.Matcher
+	}
+
+	return described{Matcher: m, Description: description}
+}
+
+// Describe replaces the description of the field
+func (m described) Describe(description string) Matcher {
+	return describe(m.Matcher, description)
+}
+
+// MarshalJSON serialises the underlying matcher, descriptions are written
+// to the pact file separately
+func (m described) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Matcher)
+}
+
+// fieldDescriptions returns the descriptions attached to any matchers in the
+// request and response of an interaction, keyed by matching rule path
+func (i *Interaction) fieldDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+
+	collectDescriptions(i.Request.Body, NewRulePath().Key("request").Key("body"), descriptions)
+	collectDescriptions(i.Response.Body, NewRulePath().Key("response").Key("body"), descriptions)
+	for name, m := range i.Request.Headers {
+		collectDescriptions(m, NewRulePath().Key("request").Key("headers").Key(name), descriptions)
+	}
+	for name, m := range i.Request.Query {
+		collectDescriptions(m, NewRulePath().Key("request").Key("query").Key(name), descriptions)
+	}
+	for name, m := range i.Response.Headers {
+		collectDescriptions(m, NewRulePath().Key("response").Key("headers").Key(name), descriptions)
+	}
+
+	return descriptions
+}
+
+// collectDescriptions walks the body in the same manner as pactBodyBuilder,
+// recording descriptions of any described matchers found
+func collectDescriptions(value interface{}, path RulePath, descriptions map[string]string) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case described:
+		descriptions[path.String()] = v.Description
+		collectDescriptions(v.Matcher, path, descriptions)
+		return
+	case like:
+		collectDescriptions(v.Contents, path, descriptions)
+		return
+	case eachLike:
+		collectDescriptions(v.Contents, path.AnyIndex(), descriptions)
+		return
+	case StructMatcher:
+		for key, child := range v {
+			collectDescriptions(child, path.Key(key), descriptions)
+		}
+		return
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range rv.MapKeys() {
+			collectDescriptions(rv.MapIndex(key).Interface(), path.Key(key.String()), descriptions)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collectDescriptions(rv.Index(i).Interface(), path.Index(i), descriptions)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			collectDescriptions(rv.Elem().Interface(), path, descriptions)
+		}
+	}
+}
+
+// descriptionComments renders the descriptions as V4 style comments
+// e.g. "$.response.body.id: account id, assigned by billing"
+func descriptionComments(descriptions map[string]string) []string {
+	comments := make([]string, 0, len(descriptions))
+	for path, description := range descriptions {
+		comments = append(comments, fmt.Sprintf("%s: %s", path, description))
+	}
+	sort.Strings(comments)
+
+	return comments
+}
+
+// writeFieldDescriptions adds the field descriptions of each interaction to
+// the pact file as comments, so that provider failures can explain the
+// business meaning of a field
+func writeFieldDescriptions(file string, descriptions map[string]map[string]string) error {
+	if len(descriptions) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		fields, ok := descriptions[description]
+		if !ok || len(fields) == 0 {
+			return
+		}
+
+		comments, _ := interaction["comments"].(map[string]interface{})
+		if comments == nil {
+			comments = make(map[string]interface{})
+		}
+		comments["text"] = descriptionComments(fields)
+		interaction["comments"] = comments
+	})
+}
@@ -0,0 +1,114 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDescribe_TransparentToMockService(t *testing.T) {
+	plain, _ := json.Marshal(Like(42))
+	withDescription, _ := json.Marshal(Like(42).Describe("account id"))
+
+	if string(plain) != string(withDescription) {
+		t.Fatalf("want %s, got %s", plain, withDescription)
+	}
+
+	if Like(42).Describe("account id").GetValue() != 42 {
+		t.Fatal("expected the described matcher to return the underlying value")
+	}
+}
+
+func TestDescribe_Redescribe(t *testing.T) {
+	m := Like(42).Describe("first").Describe("second")
+
+	d, ok := m.(described)
+	if !ok {
+		t.Fatalf("expected a described matcher, got %T", m)
+	}
+	if d.Description != "second" {
+		t.Fatalf("want second, got %s", d.Description)
+	}
+	if _, nested := d.Matcher.(described); nested {
+		t.Fatal("expected descriptions not to be nested")
+	}
+}
+
+func TestInteraction_fieldDescriptions(t *testing.T) {
+	i := (&Interaction{}).
+		UponReceiving("a request for an account").
+		WithRequest(Request{
+			Headers: MapMatcher{"Authorization": Term("Bearer 1234", "Bearer .+").Describe("issued by the auth service")},
+		}).
+		WillRespondWith(Response{
+			Body: StructMatcher{
+				"id":   Like(42).Describe("account id, assigned by billing"),
+				"tags": EachLike(S("admin").Describe("role name"), 1),
+			},
+		})
+
+	expected := map[string]string{
+		"$.request.headers.Authorization": "issued by the auth service",
+		"$.response.body.id":              "account id, assigned by billing",
+		"$.response.body.tags[*]":         "role name",
+	}
+
+	if got := i.fieldDescriptions(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("want %v, got %v", expected, got)
+	}
+
+	_, rules := pactBodyBuilder(BodyPath(), i.Response.Body)
+	if _, ok := rules["$.body.id"]; !ok {
+		t.Fatalf("expected described matchers to produce rules, got %v", rules)
+	}
+}
+
+func TestPactFileName(t *testing.T) {
+	if got := pactFileName("Billing Consumer", "AccountProvider"); got != "billing_consumer-accountprovider.json" {
+		t.Fatalf("unexpected file name %s", got)
+	}
+}
+
+func TestWriteFieldDescriptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-describe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "consumer-provider.json")
+	pact := `{"interactions":[{"description":"a request","response":{"status":200,"body":{"id":42}}},{"description":"other"}]}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = writeFieldDescriptions(file, map[string]map[string]string{
+		"a request": {"$.response.body.id": "account id"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var written struct {
+		Interactions []struct {
+			Description string `json:"description"`
+			Comments    struct {
+				Text []string `json:"text"`
+			} `json:"comments"`
+		} `json:"interactions"`
+	}
+	content, _ := ioutil.ReadFile(file)
+	if err = json.Unmarshal(content, &written); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(written.Interactions[0].Comments.Text, []string{"$.response.body.id: account id"}) {
+		t.Fatalf("unexpected comments %v", written.Interactions[0].Comments.Text)
+	}
+	if len(written.Interactions[1].Comments.Text) != 0 {
+		t.Fatalf("expected no comments on other interactions, got %v", written.Interactions[1].Comments.Text)
+	}
+}
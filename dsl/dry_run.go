@@ -0,0 +1,72 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// VerifyProviderDryRun reads the pacts selected by the request, without
+// replaying them against the provider, and reports the provider states,
+// endpoints and content types they require. Pacts are read from PactURLs
+// and, if a BrokerURL is given, the pacts the broker selects for the
+// Provider (see PactsForVerification).
+func (p *Pact) VerifyProviderDryRun(request types.VerifyRequest) (pactfile.Requirements, error) {
+	if request.Provider == "" {
+		request.Provider = p.Provider
+	}
+
+	pacts, err := selectPacts(request)
+	if err != nil {
+		return pactfile.Requirements{}, err
+	}
+	if len(pacts) == 0 && request.FailIfNoPactsFound {
+		return pactfile.Requirements{}, errors.New("no pacts found to verify")
+	}
+
+	requirements := pactfile.RequiredBy(pacts...)
+	log.Printf("[INFO] pact verification dry run, %d pact(s) require:\n%s", len(pacts), requirements)
+
+	return requirements, nil
+}
+
+// selectPacts reads the pacts a verification request would verify
+func selectPacts(request types.VerifyRequest) ([]*pactfile.Pact, error) {
+	locations := append([]string{}, request.PactURLs...)
+
+	if request.BrokerURL != "" {
+		if request.Provider == "" {
+			return nil, errors.New("'Provider' must be supplied if 'BrokerURL' given")
+		}
+		brokerPacts, err := brokerPactURLs(request)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, brokerPacts...)
+	}
+
+	pacts := make([]*pactfile.Pact, 0, len(locations))
+	for _, location := range locations {
+		var pact *pactfile.Pact
+		var err error
+
+		if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+			var content []byte
+			if content, err = brokerGet(location, request.BrokerAuth()); err == nil {
+				pact, err = pactfile.Parse(content)
+			}
+		} else {
+			pact, err = pactfile.Read(location)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read pact %s: %v", location, err)
+		}
+		pacts = append(pacts, pact)
+	}
+
+	return pacts, nil
+}
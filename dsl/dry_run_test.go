@@ -0,0 +1,83 @@
+package dsl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPact_VerifyProviderDryRun(t *testing.T) {
+	s := setupMockBroker(true)
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "pact-go-dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	local := filepath.Join(dir, "web-bobby.json")
+	err = ioutil.WriteFile(local, []byte(`{
+		"consumer": {"name": "web"},
+		"provider": {"name": "bobby"},
+		"interactions": [{
+			"description": "a request for foobar",
+			"providerState": "foobar exists",
+			"request": {"method": "GET", "path": "/foobar"},
+			"response": {"status": 200, "headers": {"Content-Type": "text/plain"}}
+		}]
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pact := &Pact{Provider: "bobby"}
+	requirements, err := pact.VerifyProviderDryRun(types.VerifyRequest{
+		PactURLs:       []string{local},
+		BrokerURL:      s.URL,
+		Tags:           []string{"dev"},
+		BrokerUsername: "foo",
+		BrokerPassword: "bar",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := pactfile.Requirements{
+		States:       []string{"Some state", "Some state2", "foobar exists"},
+		ContentTypes: []string{"application/json", "text/plain"},
+		Endpoints: []pactfile.Endpoint{
+			{Method: "GET", Path: "/bazbat", Consumers: []string{"billy"}},
+			{Method: "GET", Path: "/foobar", Consumers: []string{"billy", "web"}},
+		},
+		Messages: []string{},
+	}
+	if !reflect.DeepEqual(requirements, expected) {
+		t.Fatalf("want %+v, got %+v", expected, requirements)
+	}
+}
+
+func TestPact_VerifyProviderDryRunErrors(t *testing.T) {
+	s := setupMockBroker(true)
+	defer s.Close()
+
+	requests := map[string]types.VerifyRequest{
+		"missing pact file":  {PactURLs: []string{"does-not-exist.json"}},
+		"unauthorised":       {BrokerURL: s.URL, Provider: "bobby"},
+		"broken broker":      {BrokerURL: s.URL, Provider: "bobby", Tags: []string{"broken"}, BrokerUsername: "foo", BrokerPassword: "bar"},
+		"no provider":        {BrokerURL: s.URL},
+		"no pacts to verify": {FailIfNoPactsFound: true},
+	}
+
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (&Pact{}).VerifyProviderDryRun(request); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
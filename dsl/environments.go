@@ -0,0 +1,108 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Environment is an environment that pacticipant versions are deployed or
+// released to, e.g. "production"
+type Environment struct {
+	// UUID identifies the environment. Assigned by the broker.
+	UUID string `json:"uuid,omitempty"`
+
+	// Name is the environment's name, used by the CLI tools and the
+	// deployedOrReleased consumer version selectors
+	Name string `json:"name"`
+
+	// DisplayName is the environment's name in the broker's UI. Optional.
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Production is whether the environment is a production environment
+	Production bool `json:"production"`
+
+	// Contacts are the people or teams responsible for the environment.
+	// Optional.
+	Contacts []EnvironmentContact `json:"contacts,omitempty"`
+}
+
+// EnvironmentContact is a contact for an environment
+type EnvironmentContact struct {
+	Name string `json:"name"`
+
+	// Details are free form, e.g. {"emailAddress": "ops@example.com"}
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Environments lists the broker's environments
+func (b *Broker) Environments(ctx context.Context) ([]Environment, error) {
+	var environments []Environment
+	it := b.iterate(ctx, "environments", b.url("environments"))
+	for it.Next() {
+		var e Environment
+		if err := it.Decode(&e); err != nil {
+			return nil, err
+		}
+		environments = append(environments, e)
+	}
+
+	return environments, it.Err()
+}
+
+// CreateEnvironment creates the environment, returning it with its UUID
+func (b *Broker) CreateEnvironment(ctx context.Context, environment Environment) (Environment, error) {
+	environment.UUID = ""
+	created, err := b.saveEnvironment(ctx, "POST", b.url("environments"), environment)
+	if err != nil {
+		return Environment{}, fmt.Errorf("unable to create the environment '%s': %v", environment.Name, err)
+	}
+
+	return created, nil
+}
+
+// UpdateEnvironment replaces the environment with the same UUID
+func (b *Broker) UpdateEnvironment(ctx context.Context, environment Environment) (Environment, error) {
+	if environment.UUID == "" {
+		return Environment{}, fmt.Errorf("unable to update the environment '%s': no UUID specified", environment.Name)
+	}
+	updated, err := b.saveEnvironment(ctx, "PUT", b.url("environments", environment.UUID), environment)
+	if err != nil {
+		return Environment{}, fmt.Errorf("unable to update the environment '%s': %v", environment.Name, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteEnvironment deletes the environment with the UUID
+func (b *Broker) DeleteEnvironment(ctx context.Context, uuid string) error {
+	if uuid == "" {
+		return fmt.Errorf("unable to delete the environment: no UUID specified")
+	}
+	if _, err := brokerRequest(ctx, "DELETE", b.url("environments", uuid), nil, b.auth()); err != nil {
+		return fmt.Errorf("unable to delete the environment %s: %v", uuid, err)
+	}
+
+	return nil
+}
+
+// saveEnvironment sends the environment to the broker, returning the
+// environment the broker saved
+func (b *Broker) saveEnvironment(ctx context.Context, method string, u string, environment Environment) (Environment, error) {
+	body, err := json.Marshal(environment)
+	if err != nil {
+		return Environment{}, err
+	}
+	content, err := brokerRequest(ctx, method, u, body, b.auth())
+	if err != nil {
+		return Environment{}, err
+	}
+
+	var saved Environment
+	if err = json.Unmarshal(content, &saved); err != nil || saved.UUID == "" {
+		return Environment{}, fmt.Errorf("invalid response %s", strings.TrimSpace(string(content)))
+	}
+
+	return saved, nil
+}
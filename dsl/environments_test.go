@@ -0,0 +1,107 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupEnvironmentsBroker() *httptest.Server {
+	environments := map[string]Environment{"s1": {UUID: "s1", Name: "staging"}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uuid := strings.TrimPrefix(r.URL.Path, "/environments/")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/environments":
+			var list []Environment
+			for _, id := range []string{"s1", "p1"} {
+				if e, ok := environments[id]; ok {
+					list = append(list, e)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"_embedded": map[string]interface{}{"environments": list}}) // nolint:errcheck
+		case r.Method == "POST" && r.URL.Path == "/environments", r.Method == "PUT" && environments[uuid].UUID != "":
+			var e Environment
+			if err := json.NewDecoder(r.Body).Decode(&e); err != nil || e.Name == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":{"name":["can't be blank"]}}`)
+				return
+			}
+			if e.UUID == "" {
+				e.UUID = "p1"
+			}
+			environments[e.UUID] = e
+			json.NewEncoder(w).Encode(e) // nolint:errcheck
+		case r.Method == "DELETE" && environments[uuid].UUID != "":
+			delete(environments, uuid)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBroker_Environments(t *testing.T) {
+	s := setupEnvironmentsBroker()
+	defer s.Close()
+	broker := &Broker{URL: s.URL}
+	ctx := context.Background()
+
+	production := Environment{
+		Name:        "production",
+		DisplayName: "Production",
+		Production:  true,
+		Contacts:    []EnvironmentContact{{Name: "Ops", Details: map[string]string{"emailAddress": "ops@example.com"}}},
+	}
+	created, err := broker.CreateEnvironment(ctx, production)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	production.UUID = "p1"
+	if !reflect.DeepEqual(created, production) {
+		t.Fatalf("want %v, got %v", production, created)
+	}
+
+	production.DisplayName = "Live"
+	if _, err = broker.UpdateEnvironment(ctx, production); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	environments, err := broker.Environments(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(environments) != 2 || environments[0].Name != "staging" || !reflect.DeepEqual(environments[1], production) {
+		t.Fatalf("expected staging and the updated production environment, got %v", environments)
+	}
+
+	if err = broker.DeleteEnvironment(ctx, "s1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if environments, _ = broker.Environments(ctx); len(environments) != 1 || environments[0].UUID != "p1" {
+		t.Fatalf("expected staging to be deleted, got %v", environments)
+	}
+}
+
+func TestBroker_EnvironmentsErrors(t *testing.T) {
+	s := setupEnvironmentsBroker()
+	defer s.Close()
+	broker := &Broker{URL: s.URL}
+	ctx := context.Background()
+
+	if _, err := broker.CreateEnvironment(ctx, Environment{}); err == nil || !strings.Contains(err.Error(), "unable to create the environment ''") {
+		t.Fatalf("expected the invalid environment to be rejected, got %v", err)
+	}
+	if _, err := broker.UpdateEnvironment(ctx, Environment{Name: "test"}); err == nil || !strings.Contains(err.Error(), "no UUID specified") {
+		t.Fatalf("expected the UUID to be required, got %v", err)
+	}
+	if _, err := broker.UpdateEnvironment(ctx, Environment{UUID: "x1", Name: "test"}); err == nil {
+		t.Fatal("expected the unknown environment to fail to update")
+	}
+	if err := broker.DeleteEnvironment(ctx, "x1"); err == nil || !strings.Contains(err.Error(), "unable to delete the environment x1") {
+		t.Fatalf("expected the unknown environment to fail to delete, got %v", err)
+	}
+}
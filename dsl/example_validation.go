@@ -0,0 +1,124 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// exampleErrors checks that the example response satisfies its own matching
+// rules, e.g. that the example of a Term matches its regular expression, so
+// that the mock server never returns a response the provider could not.
+// Each problem is reported with the path of the field.
+func (i *Interaction) exampleErrors() []FieldError {
+	var errs []FieldError
+
+	if _, ok := i.Response.Body.(BodyExpectation); !ok && i.Response.Body != nil {
+		errs = append(errs, selfMatchErrors("response.body", i.Response.Body)...)
+	}
+
+	names := make([]string, 0, len(i.Response.Headers))
+	for name := range i.Response.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if value := i.Response.Headers[name]; value != nil {
+			errs = append(errs, selfMatchErrors("response.headers."+name, value)...)
+		}
+	}
+
+	return errs
+}
+
+// selfMatchErrors matches the example of a body against the body itself
+func selfMatchErrors(field string, body interface{}) []FieldError {
+	switch body.(type) {
+	case *JSONBodyBuilder, *MsgPackBodyBuilder, []byte:
+		return nil
+	}
+
+	example, err := json.Marshal(exampleOf(body))
+	if err != nil {
+		return []FieldError{{Field: field, Message: fmt.Sprintf("unable to serialise the example: %v", err)}}
+	}
+
+	mismatches, err := MatchBody(body, example)
+	if err != nil {
+		return []FieldError{{Field: field, Message: err.Error()}}
+	}
+
+	errs := make([]FieldError, len(mismatches))
+	for n, mismatch := range mismatches {
+		errs[n] = FieldError{Field: field, Message: "the example does not satisfy its matching rule: " + mismatch.Message}
+		if mismatch.Path != "$" {
+			errs[n].Field = fmt.Sprintf("%s[%s]", field, mismatch.Path)
+		}
+	}
+
+	return errs
+}
+
+// exampleOf is the example value of a body built with matchers, as returned
+// by the mock server
+func exampleOf(body interface{}) interface{} {
+	switch b := body.(type) {
+	case described:
+		return exampleOf(b.Matcher)
+	case generated:
+		return exampleOf(b.Matcher)
+	case like:
+		return exampleOf(b.Contents)
+	case eachLike:
+		count := b.Min
+		if count < 1 {
+			count = 1
+		}
+		items := make([]interface{}, count)
+		for n := range items {
+			items[n] = exampleOf(b.Contents)
+		}
+		return items
+	case term:
+		return b.Data.Generate
+	case StructMatcher:
+		return exampleOf(map[string]interface{}(b))
+	case String:
+		return string(b)
+	case S:
+		return string(b)
+	case nil:
+		return nil
+	}
+
+	rv := reflect.ValueOf(body)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		object := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			object[key.String()] = exampleOf(rv.MapIndex(key).Interface())
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		items := make([]interface{}, rv.Len())
+		for n := range items {
+			items[n] = exampleOf(rv.Index(n).Interface())
+		}
+		return items
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return exampleOf(rv.Elem().Interface())
+	}
+
+	return body
+}
@@ -0,0 +1,79 @@
+package dsl
+
+import (
+	"testing"
+)
+
+func TestInteraction_ValidateExamples(t *testing.T) {
+	i := (&Interaction{validateExamples: true}).
+		UponReceiving("a request for a user").
+		WithRequest(Request{Method: "GET", Path: String("/users/1")}).
+		WillRespondWith(Response{
+			Status: 200,
+			Headers: MapMatcher{
+				"Location":     MockServerURL("users", Term("1", `\d+`)),
+				"X-Request-Id": Term("abc", `^\d+$`),
+			},
+			Body: StructMatcher{
+				"id":      Like(1),
+				"email":   Term("jane.example.com", `^\S+@\S+$`).Describe("contact email"),
+				"friends": EachLike(StructMatcher{"since": Term("2020-01-01", `^\d{4}-\d{2}$`)}, 2),
+				"status":  "active",
+			},
+		})
+
+	err := i.Validate()
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+
+	want := []FieldError{
+		{Field: "response.body[$.email]", Message: `the example does not satisfy its matching rule: Expected a string matching /^\S+@\S+$/ but got a string ("jane.example.com")`},
+		{Field: "response.body[$.friends[0].since]", Message: `the example does not satisfy its matching rule: Expected a string matching /^\d{4}-\d{2}$/ but got a string ("2020-01-01")`},
+		{Field: "response.body[$.friends[1].since]", Message: `the example does not satisfy its matching rule: Expected a string matching /^\d{4}-\d{2}$/ but got a string ("2020-01-01")`},
+		{Field: "response.headers.X-Request-Id", Message: `the example does not satisfy its matching rule: Expected a string matching /^\d+$/ but got a string ("abc")`},
+	}
+	if len(validationErr.Errors) != len(want) {
+		t.Fatalf("want %v, got %v", want, validationErr.Errors)
+	}
+	for n := range want {
+		if validationErr.Errors[n] != want[n] {
+			t.Fatalf("want %v, got %v", want[n], validationErr.Errors[n])
+		}
+	}
+
+	i.validateExamples = false
+	if err = i.Validate(); err != nil {
+		t.Fatalf("expected examples not to be checked unless enabled, got %v", err)
+	}
+}
+
+func TestInteraction_ValidateExamplesMatch(t *testing.T) {
+	type user struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags" pact:"min=2"`
+		Date string   `json:"date" pact:"regex=^\\d{4}-\\d{2}-\\d{2}$"`
+	}
+
+	i := (&Interaction{validateExamples: true}).
+		UponReceiving("a request for a user").
+		WithRequest(Request{Method: "GET", Path: String("/users/1")}).
+		WillRespondWith(Response{Status: 200, Body: Match(user{})})
+
+	if err := i.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPact_ValidateExamples(t *testing.T) {
+	pact := &Pact{ValidateExamples: true}
+	pact.Interactions = append(pact.Interactions, (&Interaction{}).
+		UponReceiving("a request for a user").
+		WithRequest(Request{Method: "GET", Path: String("/users/1")}).
+		WillRespondWith(Response{Status: 200, Body: map[string]interface{}{"id": Term("a", `\d+`)}}))
+
+	if err := pact.validateInteractions(); err == nil {
+		t.Fatal("expected the example to be checked")
+	}
+}
@@ -0,0 +1,290 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// requestExplainer writes, for each request to the mock server, how it
+// compares with each registered interaction, see Pact.Explain
+type requestExplainer struct {
+	mu           sync.Mutex
+	out          io.Writer
+	interactions []explainedInteraction
+}
+
+// explainedInteraction is the expected request of an interaction, as it was
+// registered with the mock server
+type explainedInteraction struct {
+	description  string
+	method       string
+	path         Matcher
+	query        MapMatcher
+	headers      MapMatcher
+	body         interface{}
+	expectation  BodyExpectation
+	bodyMatching BodyMatching
+}
+
+// fieldOutcome is the comparison of one field of a request
+type fieldOutcome struct {
+	field   string
+	path    string
+	passed  bool
+	message string
+}
+
+func newRequestExplainer(out io.Writer) *requestExplainer {
+	if out == nil {
+		out = os.Stderr
+	}
+
+	return &requestExplainer{out: out}
+}
+
+// register records the request expected by the interaction
+func (e *requestExplainer) register(i *Interaction) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.interactions = append(e.interactions, explainedInteraction{
+		description:  i.Description,
+		method:       i.Request.Method,
+		path:         i.Request.Path,
+		query:        i.Request.Query,
+		headers:      i.Request.Headers,
+		body:         i.Request.Body,
+		expectation:  i.requestBodyExpectation(),
+		bodyMatching: i.bodyMatching,
+	})
+}
+
+// reset forgets all registered interactions
+func (e *requestExplainer) reset() {
+	e.mu.Lock()
+	e.interactions = nil
+	e.mu.Unlock()
+}
+
+func (e *requestExplainer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := readRequestBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		e.mu.Lock()
+		interactions := append([]explainedInteraction(nil), e.interactions...)
+		e.mu.Unlock()
+
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "pact explain: %s %s\n", r.Method, r.URL.RequestURI())
+		if len(interactions) == 0 {
+			fmt.Fprintln(&b, "  no interactions are registered")
+		}
+		for _, interaction := range interactions {
+			outcomes := interaction.explain(r, body)
+			verdict := "matches"
+			for _, outcome := range outcomes {
+				if !outcome.passed {
+					verdict = "does not match"
+					break
+				}
+			}
+			fmt.Fprintf(&b, "  candidate '%s': %s\n", interaction.description, verdict)
+			for _, outcome := range outcomes {
+				mark := "ok  "
+				if !outcome.passed {
+					mark = "FAIL"
+				}
+				fmt.Fprintf(&b, "    %s %s %s\n", mark, outcome.field, outcome.message)
+			}
+		}
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		fmt.Fprintf(&b, "  the mock server responded %d\n", recorder.status)
+
+		e.mu.Lock()
+		e.out.Write(b.Bytes()) // nolint:errcheck
+		e.mu.Unlock()
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(recorder.status)
+		w.Write(recorder.body.Bytes()) // nolint:errcheck
+	})
+}
+
+// explain compares the request with the interaction's expected request,
+// field by field
+func (i explainedInteraction) explain(r *http.Request, body []byte) []fieldOutcome {
+	var outcomes []fieldOutcome
+
+	if strings.EqualFold(i.method, r.Method) {
+		outcomes = append(outcomes, fieldOutcome{field: "method", passed: true, message: r.Method})
+	} else {
+		outcomes = append(outcomes, fieldOutcome{field: "method", message: fmt.Sprintf("%s: expected %s", r.Method, strings.ToUpper(i.method))})
+	}
+
+	outcomes = append(outcomes, explainField("path", RequestPathPath(), i.path, r.URL.Path)...)
+
+	query := r.URL.Query()
+	for _, name := range sortedKeys(i.query) {
+		values, ok := query[name]
+		if !ok {
+			outcomes = append(outcomes, fieldOutcome{field: "query", message: fmt.Sprintf("%s: missing", QueryPath(name))})
+			continue
+		}
+		var actual interface{} = values[0]
+		if len(values) > 1 {
+			actual = values
+		}
+		outcomes = append(outcomes, explainField("query", QueryPath(name), i.query[name], actual)...)
+	}
+	var unexpected []string
+	for name := range query {
+		if _, ok := i.query[name]; !ok {
+			unexpected = append(unexpected, name)
+		}
+	}
+	sort.Strings(unexpected)
+	for _, name := range unexpected {
+		outcomes = append(outcomes, fieldOutcome{field: "query", message: fmt.Sprintf("%s: unexpected parameter", QueryPath(name))})
+	}
+
+	for _, name := range sortedKeys(i.headers) {
+		values, ok := r.Header[http.CanonicalHeaderKey(name)]
+		if !ok {
+			outcomes = append(outcomes, fieldOutcome{field: "header", message: fmt.Sprintf("%s: missing", HeaderPath(name))})
+			continue
+		}
+		outcomes = append(outcomes, explainField("header", HeaderPath(name), i.headers[name], strings.Join(values, ", "))...)
+	}
+
+	return append(outcomes, i.explainBody(body)...)
+}
+
+// explainBody compares the request body with the expected body
+func (i explainedInteraction) explainBody(body []byte) []fieldOutcome {
+	switch i.expectation {
+	case NoBody, EmptyBody:
+		if len(body) > 0 {
+			return []fieldOutcome{{field: "body", message: fmt.Sprintf("expected %s but got %d bytes", i.expectation, len(body))}}
+		}
+		return []fieldOutcome{{field: "body", passed: true, message: string(i.expectation)}}
+	}
+
+	switch i.body.(type) {
+	case nil, BodyExpectation:
+		return []fieldOutcome{{field: "body", passed: true, message: "not compared"}}
+	case *JSONBodyBuilder, *MsgPackBodyBuilder, []byte:
+		return []fieldOutcome{{field: "body", passed: true, message: "not explained for this kind of body"}}
+	}
+
+	actual, err := decodeJSON(body)
+	if err != nil {
+		return []fieldOutcome{{field: "body", message: "expected a JSON body"}}
+	}
+
+	// Unexpected keys are reported instead of their values
+	var unexpected []string
+	if i.bodyMatching != PostelBodyMatching {
+		unexpected = unexpectedKeys(BodyPath().String(), actual, exampleOf(i.body))
+	}
+
+	var outcomes []fieldOutcome
+	for _, outcome := range explainField("body", BodyPath(), i.body, actual) {
+		if !withinPaths(outcome.path, unexpected) {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+
+	// Missing keys and arrays of the wrong length aren't found walking the
+	// actual body
+	mismatches, err := MatchBody(i.body, body)
+	if err != nil {
+		return append(outcomes, fieldOutcome{field: "body", message: err.Error()})
+	}
+	for _, mismatch := range mismatches {
+		if mismatch.Rule != "" {
+			continue
+		}
+		path, err := ParseRulePath(mismatch.Path)
+		if err != nil {
+			continue
+		}
+		outcomes = append(outcomes, fieldOutcome{field: "body", message: fmt.Sprintf("%s: %s", path.relativeTo(BodyPath()), mismatch.Message)})
+	}
+
+	for _, key := range unexpected {
+		outcomes = append(outcomes, fieldOutcome{field: "body", path: key, message: key + ": unexpected key"})
+	}
+
+	return outcomes
+}
+
+// withinPaths is whether the path is one of the paths, or within one
+func withinPaths(path string, paths []string) bool {
+	for _, p := range paths {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// explainField explains the rule applied to each value of an actual field,
+// and whether it matches the expected value, which may contain matchers
+func explainField(field string, root RulePath, expected interface{}, actual interface{}) []fieldOutcome {
+	example, rules := pactBodyBuilder(root, expected)
+	expectedJSON, err := json.Marshal(example)
+	if err != nil {
+		return []fieldOutcome{{field: field, message: fmt.Sprintf("unable to serialise the expected value: %v", err)}}
+	}
+	actualJSON, err := json.Marshal(actual)
+	if err != nil {
+		return []fieldOutcome{{field: field, message: fmt.Sprintf("unable to serialise the actual value: %v", err)}}
+	}
+
+	explanations, err := ExplainRules(rules, root, expectedJSON, actualJSON)
+	if err != nil {
+		return []fieldOutcome{{field: field, message: err.Error()}}
+	}
+
+	var outcomes []fieldOutcome
+	for _, explanation := range explanations {
+		// Objects and arrays without a rule are explained by their contents
+		if explanation.Applied == nil && explanation.Passed && isContainer(explanation.Value) {
+			continue
+		}
+		outcomes = append(outcomes, fieldOutcome{field: field, path: explanation.Path, passed: explanation.Passed, message: explanation.String()})
+	}
+
+	return outcomes
+}
+
+func sortedKeys(m MapMatcher) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,125 @@
+package dsl
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestRequestExplainer_Middleware(t *testing.T) {
+	var out bytes.Buffer
+	explainer := newRequestExplainer(&out)
+	explainer.register((&Interaction{}).
+		UponReceiving("a request to create an order").
+		WithRequest(Request{
+			Method:  "POST",
+			Path:    Term("/orders", `^/orders$`),
+			Query:   MapMatcher{"dryRun": String("true")},
+			Headers: MapMatcher{"Authorization": Term("Bearer 1234", `^Bearer `)},
+			Body:    map[string]interface{}{"quantity": Like(1), "sku": String("ABC")},
+		}))
+	explainer.register((&Interaction{}).
+		UponReceiving("a request for an order").
+		WithRequest(Request{Method: "GET", Path: String("/orders/1")}))
+
+	handler := explainer.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "no interaction matched")
+	}))
+
+	req := httptest.NewRequest("POST", "/orders?dryRun=false&page=2", strings.NewReader(`{"quantity": "2", "note": "x"}`))
+	req.Header.Set("Authorization", "Bearer abcd")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusInternalServerError || recorder.Body.String() != "no interaction matched" {
+		t.Fatalf("expected the response to be passed through, got %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	explanation := out.String()
+	if strings.Contains(explanation, `$.body.note = "x"`) {
+		t.Fatalf("expected the unexpected key to be reported instead of its value, got\n%s", explanation)
+	}
+	for _, want := range []string{
+		"pact explain: POST /orders?dryRun=false&page=2",
+		"candidate 'a request to create an order': does not match",
+		`ok   path $.path = "/orders": {"match":"regex","regex":"^/orders$"} from $.path`,
+		`FAIL query $.query.dryRun = "false": no rule, matched by equality: expected "true"`,
+		"FAIL query $.query.page: unexpected parameter",
+		`ok   header $.headers.Authorization = "Bearer abcd": {"match":"regex","regex":"^Bearer "}`,
+		`FAIL body $.body.quantity = "2": {"match":"type"} from $.body.quantity (weight 8): expected a number (1) but got a string ("2")`,
+		`FAIL body $.body.sku: Could not find key "sku"`,
+		"FAIL body $.body.note: unexpected key",
+		"candidate 'a request for an order': does not match",
+		"FAIL method POST: expected GET",
+		`FAIL path $.path = "/orders": no rule, matched by equality: expected "/orders/1"`,
+		"the mock server responded 500",
+	} {
+		if !strings.Contains(explanation, want) {
+			t.Fatalf("expected the explanation to contain %q, got\n%s", want, explanation)
+		}
+	}
+
+	out.Reset()
+	admin := httptest.NewRequest("GET", "/interactions/verification", nil)
+	admin.Header.Set("X-Pact-Mock-Service", "true")
+	handler.ServeHTTP(httptest.NewRecorder(), admin)
+	if out.Len() != 0 {
+		t.Fatalf("expected mock service administration requests not to be explained, got %s", out.String())
+	}
+}
+
+func TestPact_VerifyExplain(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			fmt.Fprintln(w, "ok")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ms.Close()
+
+	var out bytes.Buffer
+	pact := &Pact{
+		Server:        &types.MockServer{Port: getPort(ms.URL)},
+		Consumer:      "My Consumer",
+		Provider:      "My Provider",
+		Explain:       true,
+		ExplainOutput: &out,
+	}
+	pact.
+		AddInteraction().
+		UponReceiving("a request for an order").
+		WithRequest(Request{Method: "GET", Path: String("/orders/1")}).
+		WillRespondWith(Response{Status: 200})
+
+	err := pact.Verify(func() error {
+		res, err := http.Get(fmt.Sprintf("http://localhost:%d/orders/1", pact.Server.Port))
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	explanation := out.String()
+	for _, want := range []string{
+		"pact explain: GET /orders/1",
+		"candidate 'a request for an order': matches",
+		"ok   method GET",
+		`ok   path $.path = "/orders/1": no rule, matched by equality`,
+		"ok   body not compared",
+		"the mock server responded 200",
+	} {
+		if !strings.Contains(explanation, want) {
+			t.Fatalf("expected the explanation to contain %q, got\n%s", want, explanation)
+		}
+	}
+}
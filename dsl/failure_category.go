@@ -0,0 +1,72 @@
+package dsl
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// unreachableRecorder records the requests that couldn't be sent to the
+// provider during verification
+type unreachableRecorder struct {
+	mu         sync.Mutex
+	targetPath string
+	requests   map[string]bool
+}
+
+func newUnreachableRecorder(targetPath string) *unreachableRecorder {
+	return &unreachableRecorder{targetPath: strings.TrimSuffix(targetPath, "/"), requests: make(map[string]bool)}
+}
+
+// record is called by the proxy with each request it couldn't send, whose
+// path includes the provider's base path
+func (u *unreachableRecorder) record(r *http.Request, err error) {
+	path := strings.TrimPrefix(r.URL.Path, u.targetPath)
+
+	u.mu.Lock()
+	u.requests[r.Method+" "+path] = true
+	u.mu.Unlock()
+}
+
+// includes determines if the verification example is for a request that
+// couldn't be sent, from its full description e.g. "... a request for
+// a user with GET /users/1 returns a response which ..."
+func (u *unreachableRecorder) includes(fullDescription string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for request := range u.requests {
+		for _, suffix := range []string{" ", "?"} {
+			if strings.Contains(fullDescription, "with "+request+suffix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// categoriseFailures sets the category of each failed example, reporting
+// those whose provider state couldn't be set up as "errored" to distinguish
+// them from mismatches
+func categoriseFailures(res []types.ProviderVerifierResponse, unreachable *unreachableRecorder) {
+	for _, response := range res {
+		for n, example := range response.Examples {
+			if example.Status != "failed" {
+				continue
+			}
+
+			category := types.ClassifyFailure(example.Exception.Class, example.Exception.Message)
+			if unreachable != nil && unreachable.includes(example.FullDescription) {
+				category = types.FailureProviderUnreachable
+			}
+
+			response.Examples[n].Category = category
+			if category == types.FailureStateSetup {
+				response.Examples[n].Status = "errored"
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestCategoriseFailures(t *testing.T) {
+	unreachable := newUnreachableRecorder("/api/")
+	unreachable.record(httptest.NewRequest("GET", "/api/users/1", nil), errors.New("connection refused"))
+
+	var res []types.ProviderVerifierResponse
+	err := json.Unmarshal([]byte(`[{"examples": [
+		{"status": "failed", "full_description": "Verifying a pact between web and users A request for a user with GET /users/1 returns a response which has status code 200",
+		 "exception": {"class": "RSpec::Expectations::ExpectationNotMetError", "message": "expected: 200\n     got: 502"}},
+		{"status": "failed", "full_description": "Verifying a pact between web and users A request for a user with GET /users/10 returns a response which has status code 200",
+		 "exception": {"class": "RSpec::Expectations::ExpectationNotMetError", "message": "expected: 200\n     got: 404"}},
+		{"status": "failed", "full_description": "Verifying a pact between web and users Given a user A request for a user with GET /users/2 returns a response which has status code 200",
+		 "exception": {"class": "Pact::ProviderVerifier::SetUpProviderStateError", "message": "Error setting up provider state 'a user' for consumer 'web' at http://localhost:1234/__setup. response status=500"}},
+		{"status": "passed", "full_description": "Verifying a pact between web and users A request for users with GET /users returns a response which has status code 200"}
+	]}]`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categoriseFailures(res, unreachable)
+
+	var got []string
+	for _, example := range res[0].Examples {
+		got = append(got, example.Status+":"+string(example.Category))
+	}
+	want := "failed:provider_unreachable,failed:mismatch,errored:state_setup,passed:"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("want %s, got %s", want, strings.Join(got, ","))
+	}
+}
@@ -0,0 +1,202 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Faker generates realistic example data for a given kind of value,
+// e.g. "email" or "first_name". It returns false if the kind is unknown.
+//
+// The built-in Faker supports the kinds listed in FakerKinds, a custom
+// implementation (e.g. backed by a third party fake-data library) may be
+// configured with SetFaker.
+type Faker interface {
+	Fake(kind string) (string, bool)
+}
+
+// FakerKinds are the kinds of data supported by the built-in Faker
+var FakerKinds = []string{
+	"name",
+	"first_name",
+	"last_name",
+	"email",
+	"username",
+	"phone",
+	"address",
+	"city",
+	"country",
+	"postcode",
+	"company",
+	"url",
+}
+
+var (
+	fakerMutex        sync.RWMutex
+	activeFaker       Faker = builtinFaker{}
+	fakeByFieldName   bool
+	fakerFieldAliases = map[string]string{
+		"name":           "name",
+		"fullname":       "name",
+		"firstname":      "first_name",
+		"givenname":      "first_name",
+		"lastname":       "last_name",
+		"surname":        "last_name",
+		"familyname":     "last_name",
+		"email":          "email",
+		"emailaddress":   "email",
+		"username":       "username",
+		"login":          "username",
+		"phone":          "phone",
+		"phonenumber":    "phone",
+		"mobile":         "phone",
+		"address":        "address",
+		"streetaddress":  "address",
+		"street":         "address",
+		"city":           "city",
+		"country":        "country",
+		"zip":            "postcode",
+		"zipcode":        "postcode",
+		"postcode":       "postcode",
+		"postalcode":     "postcode",
+		"company":        "company",
+		"companyname":    "company",
+		"organisation":   "company",
+		"organization":   "company",
+		"url":            "url",
+		"website":        "url",
+		"homepage":       "url",
+		"profileurl":     "url",
+		"websiteaddress": "url",
+	}
+)
+
+// SetFaker configures the Faker used to generate example data. Passing nil
+// restores the built-in Faker.
+func SetFaker(f Faker) {
+	fakerMutex.Lock()
+	defer fakerMutex.Unlock()
+
+	if f == nil {
+		f = builtinFaker{}
+	}
+	activeFaker = f
+}
+
+// UseFakeExamples enables (or disables) fake example data in Match() for
+// string fields without a pact tag, keyed by the JSON field name
+// (e.g. a field named "email" gets a realistic email address).
+func UseFakeExamples(enabled bool) {
+	fakerMutex.Lock()
+	defer fakerMutex.Unlock()
+
+	fakeByFieldName = enabled
+}
+
+// Fake specifies that the given kind of data (e.g. "email") should be matched
+// by type, using realistic fake data as the example. It panics if the kind
+// is unknown to the active Faker, as does a pact:"faker=..." tag.
+func Fake(kind string) Matcher {
+	value, err := fake(kind)
+	if err != nil {
+		panic(fmt.Sprintf("fake: %v", err))
+	}
+
+	return Like(value)
+}
+
+// fake generates an example of the given kind using the active Faker
+func fake(kind string) (string, error) {
+	fakerMutex.RLock()
+	f := activeFaker
+	fakerMutex.RUnlock()
+
+	if value, ok := f.Fake(kind); ok {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("unknown faker kind %q", kind)
+}
+
+// fakerKindForField returns the faker kind associated with a field name,
+// if fake examples are enabled and the name is recognised
+func fakerKindForField(name string) string {
+	fakerMutex.RLock()
+	defer fakerMutex.RUnlock()
+
+	if !fakeByFieldName {
+		return ""
+	}
+
+	normalised := strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+
+	return fakerFieldAliases[normalised]
+}
+
+// builtinFaker is a small, dependency free Faker using the configured
+// source of randomness (see SetRandSource)
+type builtinFaker struct{}
+
+var (
+	fakeFirstNames = []string{"Billy", "Mary", "Jean-Marie", "Aisha", "Kenji", "Olga", "Diego", "Priya"}
+	fakeLastNames  = []string{"Sampson", "Smith", "Nguyen", "Okafor", "Tanaka", "Ivanova", "Garcia", "Patel"}
+	fakeStreets    = []string{"Main Street", "High Street", "Station Road", "Elm Avenue", "Harbour Lane"}
+	fakeCities     = []string{"Melbourne", "London", "Lagos", "Osaka", "Berlin", "Toronto", "São Paulo"}
+	fakeCountries  = []string{"Australia", "United Kingdom", "Nigeria", "Japan", "Germany", "Canada", "Brazil"}
+	fakeCompanies  = []string{"Acme Corp", "Globex", "Initech", "Umbrella Ltd", "Hooli", "Stark Industries"}
+	fakeDomains    = []string{"example.com", "example.org", "example.net"}
+)
+
+func pick(values []string) string {
+	return values[randomIntn(len(values))]
+}
+
+func randomDigits(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(byte('0' + randomIntn(10)))
+	}
+	return b.String()
+}
+
+func slug(s string) string {
+	return strings.ToLower(strings.Replace(s, " ", "", -1))
+}
+
+// Fake implements the Faker interface
+func (builtinFaker) Fake(kind string) (string, bool) {
+	switch kind {
+	case "name":
+		return fmt.Sprintf("%s %s", pick(fakeFirstNames), pick(fakeLastNames)), true
+	case "first_name":
+		return pick(fakeFirstNames), true
+	case "last_name":
+		return pick(fakeLastNames), true
+	case "email":
+		return fmt.Sprintf("%s.%s@%s", slug(pick(fakeFirstNames)), slug(pick(fakeLastNames)), pick(fakeDomains)), true
+	case "username":
+		return fmt.Sprintf("%s%s", slug(pick(fakeFirstNames)), randomDigits(2)), true
+	case "phone":
+		return fmt.Sprintf("+61 4%s %s %s", randomDigits(2), randomDigits(3), randomDigits(3)), true
+	case "address":
+		return fmt.Sprintf("%d %s", 1+randomIntn(999), pick(fakeStreets)), true
+	case "city":
+		return pick(fakeCities), true
+	case "country":
+		return pick(fakeCountries), true
+	case "postcode":
+		return randomDigits(4), true
+	case "company":
+		return pick(fakeCompanies), true
+	case "url":
+		return fmt.Sprintf("https://www.%s/%s", pick(fakeDomains), slug(pick(fakeLastNames))), true
+	}
+
+	return "", false
+}
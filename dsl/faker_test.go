@@ -0,0 +1,122 @@
+package dsl
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type staticFaker map[string]string
+
+func (f staticFaker) Fake(kind string) (string, bool) {
+	v, ok := f[kind]
+	return v, ok
+}
+
+func TestFaker_BuiltinKinds(t *testing.T) {
+	SetRandSource(rand.NewSource(1))
+	defer SetRandSource(nil)
+
+	for _, kind := range FakerKinds {
+		value, err := fake(kind)
+		if err != nil {
+			t.Fatalf("unexpected error for kind %q: %v", kind, err)
+		}
+		if value == "" {
+			t.Fatalf("expected a value for kind %q", kind)
+		}
+	}
+
+	email, _ := fake("email")
+	if !regexp.MustCompile(`^[a-z\-]+\.[a-z]+@example\.(com|org|net)$`).MatchString(email) {
+		t.Fatalf("expected a valid email, got %q", email)
+	}
+
+	if _, err := fake("unknown"); err == nil {
+		t.Fatal("expected an error for an unknown kind")
+	}
+}
+
+func TestFaker_FakeMatcher(t *testing.T) {
+	SetFaker(staticFaker{"email": "billy@example.com"})
+	defer SetFaker(nil)
+
+	if got := Fake("email").GetValue(); got != "billy@example.com" {
+		t.Fatalf("want billy@example.com, got %v", got)
+	}
+}
+
+func TestFaker_MatchTag(t *testing.T) {
+	SetFaker(staticFaker{"email": "billy@example.com"})
+	defer SetFaker(nil)
+
+	type dto struct {
+		Contact string `json:"contact" pact:"faker=email"`
+		Email   string `json:"email"`
+	}
+
+	m := Match(dto{}).(StructMatcher)
+
+	if got := m["contact"].(Matcher).GetValue(); got != "billy@example.com" {
+		t.Fatalf("want billy@example.com, got %v", got)
+	}
+	if got := m["email"].(Matcher).GetValue(); got != "string" {
+		t.Fatalf("expected field name faking to be disabled by default, got %v", got)
+	}
+}
+
+func TestFaker_MatchByFieldName(t *testing.T) {
+	SetFaker(staticFaker{"email": "billy@example.com", "first_name": "Billy"})
+	UseFakeExamples(true)
+	defer SetFaker(nil)
+	defer UseFakeExamples(false)
+
+	type dto struct {
+		Email     string `json:"email_address"`
+		FirstName string `json:"firstName"`
+		Other     string `json:"other"`
+		Explicit  string `json:"explicit" pact:"example=foo"`
+	}
+
+	m := Match(dto{}).(StructMatcher)
+
+	expected := map[string]interface{}{
+		"email_address": "billy@example.com",
+		"firstName":     "Billy",
+		"other":         "string",
+		"explicit":      "foo",
+	}
+
+	for k, v := range expected {
+		if got := m[k].(Matcher).GetValue(); got != v {
+			t.Fatalf("field %q: want %v, got %v", k, v, got)
+		}
+	}
+}
+
+func TestFaker_MatchTagUnknownKind(t *testing.T) {
+	type dto struct {
+		Value string `json:"value" pact:"faker=nope"`
+	}
+
+	defer func() {
+		rec, _ := recover().(string)
+		if !strings.Contains(rec, `encountered invalid pact tag "faker=nope"`) {
+			t.Fatalf("expected an invalid pact tag panic for an unknown faker kind, got %q", rec)
+		}
+	}()
+
+	Match(dto{})
+}
+
+func TestFaker_FakeUnknownKind(t *testing.T) {
+	defer func() {
+		rec, _ := recover().(string)
+		if !strings.Contains(rec, `unknown faker kind "nope"`) {
+			t.Fatalf("expected a panic for an unknown faker kind, got %q", rec)
+		}
+	}()
+
+	Fake("nope")
+}
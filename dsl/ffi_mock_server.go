@@ -0,0 +1,90 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/ffi"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// FFIMockServer is a mock provider run by the Rust pact core (see package
+// ffi), for consumer tests that don't use the Ruby mock service. It is only
+// available when built with the pact_ffi tag; otherwise NewFFIMockServer
+// returns ffi.ErrUnavailable.
+//
+// Interactions are built with the matcher DSL, with the same restrictions as
+// the NativeMockServer, and the Rust core matches the requests against them.
+type FFIMockServer struct {
+	// URL is the base URL of the mock server
+	URL string
+
+	server *ffi.MockServer
+}
+
+// NewFFIMockServer starts a Rust mock server for the interactions between the
+// consumer and provider, listening on a local port given by its URL. Close it
+// when done.
+func NewFFIMockServer(consumer, provider string, interactions ...*Interaction) (*FFIMockServer, error) {
+	registered := make([]*nativeInteraction, len(interactions))
+	for n, interaction := range interactions {
+		if err := validateNative(interaction, "the FFI mock server"); err != nil {
+			return nil, err
+		}
+		registered[n] = &nativeInteraction{Interaction: interaction}
+	}
+
+	pact, err := nativePact(consumer, provider, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := ffi.StartMockServer(pact, "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	return &FFIMockServer{URL: server.URL(), server: server}, nil
+}
+
+// Verify returns a *MismatchError if a request matched no interaction, or an
+// interaction was not requested, and nil otherwise
+func (s *FFIMockServer) Verify() error {
+	if s.server.Matched() {
+		return nil
+	}
+
+	mismatches, err := s.server.Mismatches()
+	if err != nil {
+		return err
+	}
+
+	return ffiMismatchError(mismatches)
+}
+
+// ffiMismatchError describes the mismatches reported by the Rust mock server
+func ffiMismatchError(mismatches []types.InteractionMismatches) error {
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	lines := []string{"the mock server did not receive the expected requests:"}
+	for _, m := range mismatches {
+		for _, mismatch := range m.Mismatches {
+			lines = append(lines, fmt.Sprintf("  %s", mismatch))
+		}
+	}
+
+	return &MismatchError{Message: strings.Join(lines, "\n"), Mismatches: mismatches}
+}
+
+// WritePact writes the pact to dir, merging it with an existing pact between
+// the consumer and provider
+func (s *FFIMockServer) WritePact(dir string) error {
+	return s.server.WritePact(dir, false)
+}
+
+// Close stops the mock server
+func (s *FFIMockServer) Close() error {
+	return s.server.Close()
+}
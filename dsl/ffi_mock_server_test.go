@@ -0,0 +1,39 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/ffi"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestNewFFIMockServer(t *testing.T) {
+	interaction := (&Interaction{}).
+		UponReceiving("a MessagePack request").
+		WithRequest(Request{Method: "POST", Path: String("/packed"), Body: MsgPackBody(map[string]int{"id": 1})}).
+		WillRespondWith(Response{Status: 200})
+	if _, err := NewFFIMockServer("web", "orders", interaction); err == nil || !strings.Contains(err.Error(), "not supported by the FFI mock server") {
+		t.Fatalf("expected an error for a MessagePack body, got %v", err)
+	}
+
+	if ffi.Available() {
+		t.Skip("built with the pact_ffi tag")
+	}
+	if _, err := NewFFIMockServer("web", "orders", nativeOrderInteractions()...); err != ffi.ErrUnavailable {
+		t.Fatalf("expected ffi.ErrUnavailable, got %v", err)
+	}
+}
+
+func TestFFIMismatchError(t *testing.T) {
+	if err := ffiMismatchError(nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err := ffiMismatchError([]types.InteractionMismatches{{
+		Mismatches: []types.Mismatch{types.RequestMismatch{Method: "GET", Path: "/orders/1", Message: "expected request was not received"}},
+	}})
+	if mismatchErr, ok := err.(*MismatchError); !ok || !strings.Contains(err.Error(), "request GET /orders/1: expected request was not received") || len(mismatchErr.Mismatches) != 1 {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
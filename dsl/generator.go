@@ -0,0 +1,384 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exampleMockServerURL is the base of the example of a MockServerURL, which
+// is replaced by the address of the mock server when generated
+const exampleMockServerURL = "http://localhost:8080"
+
+// headerGenerator generates the value of a response header for each request
+// answered by the mock server
+type headerGenerator interface {
+	generate(r *http.Request) (string, error)
+
+	// pactGenerator is the generator as written to a version 3 pact file
+	pactGenerator() map[string]interface{}
+}
+
+// generated wraps a Matcher with a generator, which the mock server proxy
+// applies to response headers. It is otherwise transparent to the mock
+// service.
+type generated struct {
+	Matcher
+	generator headerGenerator
+}
+
+// Describe attaches a description of the field to the matcher, keeping the
+// generator
+func (m generated) Describe(description string) Matcher {
+	return describe(m, description)
+}
+
+// MarshalJSON serialises the underlying matcher, generators are written to
+// the pact file separately
+func (m generated) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Matcher)
+}
+
+// MockServerURL matches a URL ending with the given path segments, each a
+// string or a Term e.g. MockServerURL("orders", UUID()). When used as a
+// response header, e.g. the Location of a 201 response, the mock server
+// generates the URL from its own address with a new value for each Term
+// segment, so that the consumer can follow it.
+func MockServerURL(segments ...interface{}) Matcher {
+	g := mockServerURL{}
+	for _, segment := range segments {
+		switch s := unwrapMatcher(segment).(type) {
+		case string:
+			g.segments = append(g.segments, urlSegment{example: s, regex: regexp.QuoteMeta(s)})
+		case String:
+			g.segments = append(g.segments, urlSegment{example: string(s), regex: regexp.QuoteMeta(string(s))})
+		case S:
+			g.segments = append(g.segments, urlSegment{example: string(s), regex: regexp.QuoteMeta(string(s))})
+		case term:
+			regex, _ := s.Data.Matcher.Regex.(string)
+			g.segments = append(g.segments, urlSegment{example: fmt.Sprint(s.Data.Generate), regex: unanchored(regex), generate: true})
+		default:
+			log.Printf("[WARN] MockServerURL: segment %v is not a string or a Term, using its value\n", segment)
+			value := fmt.Sprint(segment)
+			if m, ok := segment.(Matcher); ok {
+				value = fmt.Sprint(m.GetValue())
+			}
+			g.segments = append(g.segments, urlSegment{example: value, regex: regexp.QuoteMeta(value)})
+		}
+	}
+
+	return generated{
+		Matcher:   Term(exampleMockServerURL+g.path(false), g.regex()),
+		generator: g,
+	}
+}
+
+// Generate generates a new value matching the Term for each response of
+// the mock server, e.g. Generate(UUID()) for a request id header. Other
+// matchers are returned unchanged.
+func Generate(m Matcher) Matcher {
+	t, ok := unwrapMatcher(m).(term)
+	if !ok {
+		log.Printf("[WARN] Generate: only a Term can be generated, not %T\n", m)
+		return m
+	}
+	regex, _ := t.Data.Matcher.Regex.(string)
+
+	return generated{Matcher: m, generator: regexGenerator{regex: regex}}
+}
+
+// urlSegment is a segment of the path of a MockServerURL
+type urlSegment struct {
+	example  string
+	regex    string
+	generate bool
+}
+
+// mockServerURL generates a URL of the mock server
+type mockServerURL struct {
+	segments []urlSegment
+}
+
+// path joins the segments, generating those from a Term if required
+func (g mockServerURL) path(generate bool) string {
+	var path strings.Builder
+	for _, segment := range g.segments {
+		value := segment.example
+		if generate && segment.generate {
+			if example, err := generateRegexExample(segment.regex); err == nil {
+				value = example
+			}
+		}
+		path.WriteString("/" + strings.TrimPrefix(value, "/"))
+	}
+
+	return path.String()
+}
+
+// regex matches any URL ending with the path, capturing the path
+func (g mockServerURL) regex() string {
+	var path strings.Builder
+	for _, segment := range g.segments {
+		path.WriteString(`\/` + strings.TrimPrefix(segment.regex, `\/`))
+	}
+
+	return fmt.Sprintf(`.*(%s)$`, path.String())
+}
+
+func (g mockServerURL) generate(r *http.Request) (string, error) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, g.path(true)), nil
+}
+
+func (g mockServerURL) pactGenerator() map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "MockServerURL",
+		"example": exampleMockServerURL + g.path(false),
+		"regex":   g.regex(),
+	}
+}
+
+// regexGenerator generates a value from a regular expression
+type regexGenerator struct {
+	regex string
+}
+
+func (g regexGenerator) generate(r *http.Request) (string, error) {
+	return generateRegexExample(g.regex)
+}
+
+func (g regexGenerator) pactGenerator() map[string]interface{} {
+	return map[string]interface{}{
+		"type":  "Regex",
+		"regex": g.regex,
+	}
+}
+
+// timeGenerator generates the current time, according to the clock (see
+// SetClock)
+type timeGenerator struct {
+	regex  string
+	format func(time.Time) string
+
+	// pactFormat is the format of a version 3 DateTime generator. Without
+	// one, a Regex generator is written.
+	pactFormat string
+}
+
+func (g timeGenerator) generate(r *http.Request) (string, error) {
+	return g.format(now()), nil
+}
+
+func (g timeGenerator) pactGenerator() map[string]interface{} {
+	if g.pactFormat == "" {
+		return regexGenerator{regex: g.regex}.pactGenerator()
+	}
+
+	return map[string]interface{}{
+		"type":   "DateTime",
+		"format": g.pactFormat,
+	}
+}
+
+// unwrapMatcher removes any description or generator from a matcher
+func unwrapMatcher(m interface{}) interface{} {
+	for {
+		switch w := m.(type) {
+		case described:
+			m = w.Matcher
+		case generated:
+			m = w.Matcher
+		default:
+			return m
+		}
+	}
+}
+
+// findGenerator returns the generator of a (possibly described) matcher
+func findGenerator(m interface{}) (headerGenerator, bool) {
+	for {
+		switch w := m.(type) {
+		case described:
+			m = w.Matcher
+		case generated:
+			return w.generator, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// unanchored removes the anchors from a regular expression, so that it can
+// be embedded in another
+func unanchored(regex string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(regex, "^"), "$")
+}
+
+// headerGenerators returns the generators of the interaction's response
+// headers, keyed by header name
+func (i *Interaction) headerGenerators() map[string]headerGenerator {
+	generators := make(map[string]headerGenerator)
+	for name, m := range i.Response.Headers {
+		if g, ok := findGenerator(m); ok {
+			generators[name] = g
+		}
+	}
+
+	return generators
+}
+
+// pactHeaderGenerators returns the generators of the interaction's response
+// headers, as written to a version 3 pact file
+func (i *Interaction) pactHeaderGenerators() map[string]interface{} {
+	generators := make(map[string]interface{})
+	for name, g := range i.headerGenerators() {
+		generators[name] = g.pactGenerator()
+	}
+
+	return generators
+}
+
+// writeHeaderGenerators adds the response header generators of each
+// interaction, keyed by description, to the pact file
+func writeHeaderGenerators(file string, generators map[string]map[string]interface{}) error {
+	if len(generators) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		headers, ok := generators[description]
+		response, _ := interaction["response"].(map[string]interface{})
+		if !ok || len(headers) == 0 || response == nil {
+			return
+		}
+
+		existing, _ := response["generators"].(map[string]interface{})
+		if existing == nil {
+			existing = make(map[string]interface{})
+		}
+		existing["header"] = headers
+		response["generators"] = existing
+	})
+}
+
+// responseGenerators generates the response headers of interactions with
+// header generators
+type responseGenerators struct {
+	mu sync.Mutex
+
+	// generators are keyed by method and path. Requests for interactions
+	// with differing generators are left unchanged.
+	generators map[string]map[string]headerGenerator
+}
+
+func newResponseGenerators() *responseGenerators {
+	return &responseGenerators{generators: make(map[string]map[string]headerGenerator)}
+}
+
+// register records the header generators of the interaction
+func (g *responseGenerators) register(i *Interaction) {
+	generators := i.headerGenerators()
+	if len(generators) == 0 {
+		return
+	}
+
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		log.Printf("[WARN] interaction '%s' has response header generators but its path is not a plain string, they will not be applied\n", i.Description)
+		return
+	}
+	key := negotiationKey(i.Request.Method, path)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.generators[key]; ok && !reflect.DeepEqual(existing, generators) {
+		log.Printf("[WARN] interactions for %s %s have differing response header generators, they will not be applied\n", i.Request.Method, path)
+		generators = nil
+	}
+	g.generators[key] = generators
+}
+
+// reset forgets all registered generators
+func (g *responseGenerators) reset() {
+	g.mu.Lock()
+	g.generators = make(map[string]map[string]headerGenerator)
+	g.mu.Unlock()
+}
+
+// middleware replaces the example value of each generated header in the
+// mock server's response
+func (g *responseGenerators) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		g.mu.Lock()
+		generators := g.generators[negotiationKey(r.Method, r.URL.Path)]
+		g.mu.Unlock()
+
+		if len(generators) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(&generatingResponseWriter{ResponseWriter: w, request: r, generators: generators}, r)
+	})
+}
+
+// generatingResponseWriter generates headers before they are written
+type generatingResponseWriter struct {
+	http.ResponseWriter
+	request    *http.Request
+	generators map[string]headerGenerator
+	generated  bool
+}
+
+func (w *generatingResponseWriter) WriteHeader(status int) {
+	w.generate()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *generatingResponseWriter) Write(body []byte) (int, error) {
+	w.generate()
+	return w.ResponseWriter.Write(body)
+}
+
+// generate replaces the headers present in the response, in name order
+func (w *generatingResponseWriter) generate() {
+	if w.generated {
+		return
+	}
+	w.generated = true
+
+	names := make([]string, 0, len(w.generators))
+	for name := range w.generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if w.Header().Get(name) == "" {
+			continue
+		}
+		value, err := w.generators[name].generate(w.request)
+		if err != nil {
+			log.Printf("[WARN] unable to generate response header %s: %v\n", name, err)
+			continue
+		}
+		w.Header().Set(name, value)
+	}
+}
@@ -0,0 +1,183 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMockServerURL(t *testing.T) {
+	m := MockServerURL("orders", UUID())
+
+	want := `.*(\/orders\/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`
+	g, ok := findGenerator(m)
+	if !ok {
+		t.Fatal("expected a generator")
+	}
+	generator := g.pactGenerator()
+	if generator["type"] != "MockServerURL" || generator["regex"] != want {
+		t.Fatalf("want a MockServerURL generator with regex %s, got %v", want, generator)
+	}
+	if m.GetValue() != "http://localhost:8080/orders/fc763eba-0905-41c5-a27f-3934ab26786c" {
+		t.Fatalf("unexpected example %v", m.GetValue())
+	}
+
+	// The matcher is a plain term to the mock service
+	content, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, _ := json.Marshal(Term(m.GetValue().(string), want))
+	if string(content) != string(expected) {
+		t.Fatalf("want %s, got %s", expected, content)
+	}
+
+	req := httptest.NewRequest("POST", "http://127.0.0.1:4321/orders", nil)
+	first, _ := g.generate(req)
+	second, _ := g.generate(req)
+	if !regexp.MustCompile(`^http://127\.0\.0\.1:4321` + want[2:]).MatchString(first) {
+		t.Fatalf("expected a URL of the mock server, got %s", first)
+	}
+	if first == second {
+		t.Fatalf("expected a new UUID for each response, got %s twice", first)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	m := Generate(UUID().Describe("request id"))
+	g, ok := findGenerator(m)
+	if !ok {
+		t.Fatal("expected a generator")
+	}
+
+	value, err := g.generate(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !regexp.MustCompile(uuid).MatchString(value) {
+		t.Fatalf("expected a UUID, got %s", value)
+	}
+
+	if Generate(Like(1)) != Like(1) {
+		t.Fatal("expected matchers other than a Term to be returned unchanged")
+	}
+}
+
+func TestTimeGenerators(t *testing.T) {
+	SetClock(func() time.Time { return time.Date(2021, 3, 4, 5, 6, 7, 8000000, time.FixedZone("", 3600)) })
+	defer SetClock(nil)
+
+	cases := []struct {
+		matcher Matcher
+		value   string
+		pact    map[string]interface{}
+	}{
+		{RFC1123Timestamp(), "Thu, 04 Mar 2021 04:06:07 GMT", map[string]interface{}{"type": "DateTime", "format": "EEE, dd MMM yyyy HH:mm:ss 'GMT'"}},
+		{EpochSecondsString(), "1614830767", map[string]interface{}{"type": "Regex", "regex": epochSecs}},
+		{EpochMillisString(), "1614830767008", map[string]interface{}{"type": "Regex", "regex": epochMillis}},
+	}
+	for _, c := range cases {
+		g, ok := findGenerator(c.matcher)
+		if !ok {
+			t.Fatalf("expected a generator for %v", c.matcher)
+		}
+		value, err := g.generate(httptest.NewRequest("GET", "/", nil))
+		if err != nil || value != c.value {
+			t.Fatalf("want the current time %s, got %s and %v", c.value, value, err)
+		}
+		if !reflect.DeepEqual(g.pactGenerator(), c.pact) {
+			t.Fatalf("want the generator %v, got %v", c.pact, g.pactGenerator())
+		}
+
+		// In a body, the matcher is its Term
+		example, rules := pactBodyBuilder(BodyPath(), map[string]interface{}{"at": c.matcher})
+		if example.(map[string]interface{})["at"] != c.matcher.GetValue() || rules["$.body.at"]["match"] != "regex" {
+			t.Fatalf("expected the example and a regex rule, got %v and %v", example, rules)
+		}
+	}
+}
+
+func TestResponseGenerators_middleware(t *testing.T) {
+	g := newResponseGenerators()
+	g.register((&Interaction{}).
+		UponReceiving("a request to create an order").
+		WithRequest(Request{Method: "POST", Path: String("/orders")}).
+		WillRespondWith(Response{Status: 201, Headers: MapMatcher{"Location": MockServerURL("orders", UUID())}}))
+
+	handler := g.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "http://localhost:8080/orders/fc763eba-0905-41c5-a27f-3934ab26786c")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("POST", "http://127.0.0.1:4321/orders", nil))
+	location := res.Header().Get("Location")
+	if !regexp.MustCompile(`^http://127\.0\.0\.1:4321/orders/` + uuid + `$`).MatchString(location) {
+		t.Fatalf("expected the Location to be generated, got %s", location)
+	}
+	if location == "http://127.0.0.1:4321/orders/fc763eba-0905-41c5-a27f-3934ab26786c" {
+		t.Fatal("expected a new UUID to be generated")
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("POST", "http://127.0.0.1:4321/users", nil))
+	if res.Header().Get("Location") != "http://localhost:8080/orders/fc763eba-0905-41c5-a27f-3934ab26786c" {
+		t.Fatalf("expected other responses to be untouched, got %s", res.Header().Get("Location"))
+	}
+
+	g.reset()
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("POST", "http://127.0.0.1:4321/orders", nil))
+	if res.Header().Get("Location") != "http://localhost:8080/orders/fc763eba-0905-41c5-a27f-3934ab26786c" {
+		t.Fatalf("expected no generation after reset, got %s", res.Header().Get("Location"))
+	}
+}
+
+func TestWriteHeaderGenerators(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-generators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "consumer-provider.json")
+	ioutil.WriteFile(file, []byte(`{"interactions":[
+		{"description":"a","request":{"method":"POST","path":"/orders"},"response":{"status":201,"headers":{"Location":"http://localhost:8080/orders/1"}}},
+		{"description":"b","request":{"method":"GET","path":"/orders/1"},"response":{"status":200}}
+	]}`), 0644)
+
+	i := (&Interaction{}).
+		UponReceiving("a").
+		WillRespondWith(Response{Status: 201, Headers: MapMatcher{"Location": MockServerURL("orders", Term("1", `\d+`))}})
+	if err = writeHeaderGenerators(file, map[string]map[string]interface{}{"a": i.pactHeaderGenerators()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := ioutil.ReadFile(file)
+	var pact struct {
+		Interactions []struct {
+			Response struct {
+				Generators map[string]map[string]map[string]string `json:"generators"`
+			} `json:"response"`
+		} `json:"interactions"`
+	}
+	json.Unmarshal(content, &pact)
+
+	want := map[string]string{"type": "MockServerURL", "example": "http://localhost:8080/orders/1", "regex": `.*(\/orders\/\d+)$`}
+	got := pact.Interactions[0].Response.Generators["header"]["Location"]
+	for key, value := range want {
+		if got[key] != value {
+			t.Fatalf("want %s %s, got %v", key, value, got)
+		}
+	}
+	if pact.Interactions[1].Response.Generators != nil {
+		t.Fatalf("expected other interactions to be untouched, got %s", content)
+	}
+}
@@ -0,0 +1,251 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// GitExchange exchanges pacts through a git repository, for organisations
+// that can't host a Pact Broker. Each consumer branch's latest pact with a
+// provider is committed to
+//
+//	pacts/<provider>/<consumer>/<consumer branch>.json
+//
+// and the history of each file records its earlier versions.
+type GitExchange struct {
+	// Repository is the URL or path of the repository to clone
+	Repository string
+
+	// Dir is the local clone of the repository, cloned if it doesn't exist.
+	// Defaults to a temporary directory.
+	Dir string
+
+	// Branch of the repository the pacts are committed to. Defaults to
+	// "main".
+	Branch string
+
+	// AuthorName and AuthorEmail identify the author of commits. Default to
+	// "pact-go" and "pact-go@localhost".
+	AuthorName  string
+	AuthorEmail string
+}
+
+// gitExchangePushAttempts is how many times a publication is retried when
+// another publication is pushed first
+const gitExchangePushAttempts = 3
+
+// Publish commits the pact files (or directories of them) given by the
+// request's PactURLs for its consumer Branch, and pushes them to the
+// repository. The ConsumerVersion is recorded in the commit message. Branch
+// and ConsumerVersion may be found by VersionDetection.
+func (g *GitExchange) Publish(request types.PublishRequest) error {
+	detectVersion(request.VersionDetection, &request.ConsumerVersion, &request.Branch)
+	if request.Branch == "" {
+		return fmt.Errorf("a consumer branch is required to publish pacts to git")
+	}
+
+	files, err := expandPactFiles(request.PactURLs)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no pact files found to publish in %s", strings.Join(request.PactURLs, ", "))
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = g.sync(); err != nil {
+			return err
+		}
+
+		var consumers []string
+		for _, file := range files {
+			consumer, err := g.copyPact(file, request.Branch)
+			if err != nil {
+				return err
+			}
+			consumers = appendDistinct(consumers, consumer)
+		}
+
+		if _, err = g.git("add", "-A", "pacts"); err != nil {
+			return err
+		}
+		if _, err = g.git("diff", "--cached", "--quiet"); err == nil {
+			log.Println("[INFO] pact git exchange: pacts are unchanged, skipping")
+			return nil
+		}
+
+		message := fmt.Sprintf("Publish pacts for %s %s (%s)", strings.Join(consumers, ", "), request.ConsumerVersion, request.Branch)
+		if _, err = g.git("-c", "user.name="+g.authorName(), "-c", "user.email="+g.authorEmail(), "commit", "-m", message); err != nil {
+			return err
+		}
+
+		if _, err = g.git("push", "origin", "HEAD:refs/heads/"+g.branch()); err == nil {
+			log.Println("[INFO] pact git exchange:", message)
+			return nil
+		}
+		if attempt == gitExchangePushAttempts {
+			return err
+		}
+		log.Printf("[DEBUG] pact git exchange: push rejected, retrying: %v", err)
+	}
+}
+
+// Fetch returns the local paths of the provider's pacts for the given
+// consumer branches, or for every branch if none are given, for use as the
+// PactURLs of a VerifyRequest
+func (g *GitExchange) Fetch(provider string, branches ...string) ([]string, error) {
+	if err := g.sync(); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, branch := range branches {
+		wanted[gitExchangeName(branch)+".json"] = true
+	}
+
+	consumers, err := ioutil.ReadDir(filepath.Join(g.Dir, "pacts", gitExchangeName(provider)))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, consumer := range consumers {
+		if !consumer.IsDir() {
+			continue
+		}
+		dir := filepath.Join(g.Dir, "pacts", gitExchangeName(provider), consumer.Name())
+		pacts, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, pact := range pacts {
+			if filepath.Ext(pact.Name()) == ".json" && (len(wanted) == 0 || wanted[pact.Name()]) {
+				files = append(files, filepath.Join(dir, pact.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// sync clones the repository if needed, and resets the clone to the latest
+// commit of the branch
+func (g *GitExchange) sync() error {
+	if g.Dir == "" {
+		dir, err := ioutil.TempDir("", "pact-git-exchange")
+		if err != nil {
+			return err
+		}
+		g.Dir = filepath.Join(dir, "repository")
+	}
+
+	if _, err := os.Stat(filepath.Join(g.Dir, ".git")); os.IsNotExist(err) {
+		log.Printf("[DEBUG] pact git exchange: cloning %s to %s", g.Repository, g.Dir)
+		out, err := exec.Command("git", "clone", "--quiet", g.Repository, g.Dir).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("unable to clone %s: %v\n%s", g.Repository, err, out)
+		}
+	}
+
+	if _, err := g.git("fetch", "--quiet", "origin"); err != nil {
+		return err
+	}
+
+	remote := "refs/remotes/origin/" + g.branch()
+	if _, err := g.git("rev-parse", "--verify", "--quiet", remote); err != nil {
+		// The branch doesn't exist yet, so start it
+		_, err = g.git("symbolic-ref", "HEAD", "refs/heads/"+g.branch())
+		return err
+	}
+	if _, err := g.git("checkout", "--quiet", "-B", g.branch(), remote); err != nil {
+		return err
+	}
+	_, err := g.git("reset", "--quiet", "--hard", remote)
+
+	return err
+}
+
+// copyPact copies the pact file into the clone, returning its consumer
+func (g *GitExchange) copyPact(file string, branch string) (string, error) {
+	pact, err := pactfile.Read(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read pact file %s: %v", file, err)
+	}
+	if pact.Consumer.Name == "" || pact.Provider.Name == "" {
+		return "", fmt.Errorf("pact file %s has no consumer or provider name", file)
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(g.Dir, "pacts", gitExchangeName(pact.Provider.Name), gitExchangeName(pact.Consumer.Name))
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return pact.Consumer.Name, ioutil.WriteFile(filepath.Join(dir, gitExchangeName(branch)+".json"), content, 0644)
+}
+
+func (g *GitExchange) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.Dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (g *GitExchange) branch() string {
+	if g.Branch == "" {
+		return "main"
+	}
+	return g.Branch
+}
+
+func (g *GitExchange) authorName() string {
+	if g.AuthorName == "" {
+		return "pact-go"
+	}
+	return g.AuthorName
+}
+
+func (g *GitExchange) authorEmail() string {
+	if g.AuthorEmail == "" {
+		return "pact-go@localhost"
+	}
+	return g.AuthorEmail
+}
+
+// gitExchangeName escapes a pacticipant or branch name for use as a file
+// name, so that branches such as "feature/foo" aren't nested
+func gitExchangeName(name string) string {
+	return url.PathEscape(name)
+}
+
+func appendDistinct(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
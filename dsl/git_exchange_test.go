@@ -0,0 +1,85 @@
+package dsl
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func writeExchangePact(t *testing.T, dir string, consumer string, provider string) string {
+	file := filepath.Join(dir, consumer+"-"+provider+".json")
+	content := `{"consumer":{"name":"` + consumer + `"},"provider":{"name":"` + provider + `"},"interactions":[]}`
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("unable to write pact: %v", err)
+	}
+
+	return file
+}
+
+func TestGitExchange(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "pact-git-exchange-test")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	remote := filepath.Join(dir, "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("unable to create repository: %v\n%s", err, out)
+	}
+	pacts := filepath.Join(dir, "pacts")
+	if err = os.Mkdir(pacts, 0755); err != nil {
+		t.Fatalf("unable to create pact dir: %v", err)
+	}
+	writeExchangePact(t, pacts, "billy", "bobby")
+	writeExchangePact(t, pacts, "jessica", "bobby")
+
+	consumer := &GitExchange{Repository: remote, Dir: filepath.Join(dir, "consumer")}
+	for _, branch := range []string{"main", "feature/login"} {
+		err = consumer.Publish(types.PublishRequest{PactURLs: []string{pacts}, ConsumerVersion: "1.0.0", Branch: branch})
+		if err != nil {
+			t.Fatalf("unable to publish pacts: %v", err)
+		}
+	}
+
+	// Republishing unchanged pacts doesn't commit
+	if err = consumer.Publish(types.PublishRequest{PactURLs: []string{pacts}, ConsumerVersion: "1.0.1", Branch: "main"}); err != nil {
+		t.Fatalf("unable to republish pacts: %v", err)
+	}
+	history, _ := consumer.git("log", "--format=%s", "main")
+	if commits := strings.Split(history, "\n"); len(commits) != 2 || commits[0] != "Publish pacts for billy, jessica 1.0.0 (feature/login)" {
+		t.Fatalf("unexpected commits %q", commits)
+	}
+
+	provider := &GitExchange{Repository: remote, Dir: filepath.Join(dir, "provider")}
+	files, err := provider.Fetch("bobby", "feature/login")
+	if err != nil {
+		t.Fatalf("unable to fetch pacts: %v", err)
+	}
+	if len(files) != 2 || filepath.Base(files[0]) != "feature%2Flogin.json" || filepath.Base(filepath.Dir(files[1])) != "jessica" {
+		t.Fatalf("unexpected pacts %v", files)
+	}
+
+	if files, err = provider.Fetch("bobby"); err != nil || len(files) != 4 {
+		t.Fatalf("want all 4 pacts, got %v: %v", files, err)
+	}
+	if files, err = provider.Fetch("nobody"); err != nil || len(files) != 0 {
+		t.Fatalf("want no pacts, got %v: %v", files, err)
+	}
+}
+
+func TestGitExchange_PublishRequiresBranch(t *testing.T) {
+	exchange := &GitExchange{Repository: "unused"}
+	if err := exchange.Publish(types.PublishRequest{PactURLs: []string{"unused"}}); err == nil {
+		t.Fatalf("expected an error publishing without a branch")
+	}
+}
@@ -0,0 +1,378 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GatewayRule is the REST mapping of a gRPC method served through
+// grpc-gateway, as given by its google.api.http annotation
+type GatewayRule struct {
+	// Method is the HTTP method e.g. "GET"
+	Method string
+
+	// Path is the path template e.g. "/v1/users/{user_id}" or
+	// "/v1/{name=users/*}"
+	Path string
+
+	// Body is the request field sent as the body, "*" for every field not
+	// bound by the path, or empty if fields not bound by the path are sent
+	// as query parameters
+	Body string
+
+	// ResponseBody is the response field sent as the body, or empty for the
+	// whole response message
+	ResponseBody string
+}
+
+// GatewayCall describes a call to a gRPC method through its REST mapping.
+// Messages are given with their protobuf field names, and may contain
+// matchers.
+type GatewayCall struct {
+	Rule GatewayRule
+
+	// State is the provider state of the interaction. Optional.
+	State string
+
+	// Request is the request message
+	Request map[string]interface{}
+
+	// Headers of the request. Optional.
+	Headers MapMatcher
+
+	// Status of the response. Defaults to 200
+	Status int
+
+	// Response is the response message
+	Response map[string]interface{}
+
+	// UseProtoNames keeps the protobuf field names in JSON, as with the
+	// gateway's UseProtoNames marshalling option, rather than converting
+	// them to lowerCamelCase
+	UseProtoNames bool
+}
+
+var (
+	protoCommentRegex = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+	protoServiceRegex = regexp.MustCompile(`\bservice\s+(\w+)\s*\{`)
+	protoRPCRegex     = regexp.MustCompile(`\brpc\s+(\w+)\s*\([^)]*\)\s*returns\s*\([^)]*\)\s*([{;])`)
+	protoHTTPRegex    = regexp.MustCompile(`option\s*\(\s*google\.api\.http\s*\)\s*=\s*\{`)
+	protoFieldRegex   = regexp.MustCompile(`\b(get|put|post|delete|patch|body|response_body|additional_bindings)\s*:?\s*("([^"]*)"|\{)`)
+	pathVariableRegex = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+)
+
+// ParseGatewayRules reads the google.api.http annotations of the services in
+// a .proto file, keyed by "<service>.<method>" e.g. "UserService.GetUser".
+// Only the primary binding of each method is read.
+func ParseGatewayRules(file string) (map[string]GatewayRule, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	source := protoCommentRegex.ReplaceAllString(string(content), "")
+
+	rules := make(map[string]GatewayRule)
+	for _, service := range protoServiceRegex.FindAllStringSubmatchIndex(source, -1) {
+		name := source[service[2]:service[3]]
+		block, err := protoBlock(source, service[1]-1)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse service %s in %s: %v", name, file, err)
+		}
+
+		for _, rpc := range protoRPCRegex.FindAllStringSubmatchIndex(block, -1) {
+			method := block[rpc[2]:rpc[3]]
+			if block[rpc[4]:rpc[5]] == ";" {
+				continue
+			}
+			options, err := protoBlock(block, rpc[4])
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse method %s.%s in %s: %v", name, method, file, err)
+			}
+
+			rule, ok, err := parseGatewayRule(options)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse method %s.%s in %s: %v", name, method, file, err)
+			}
+			if ok {
+				rules[name+"."+method] = rule
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// parseGatewayRule reads the google.api.http option in an rpc's options
+func parseGatewayRule(options string) (GatewayRule, bool, error) {
+	option := protoHTTPRegex.FindStringIndex(options)
+	if option == nil {
+		return GatewayRule{}, false, nil
+	}
+	http, err := protoBlock(options, option[1]-1)
+	if err != nil {
+		return GatewayRule{}, false, err
+	}
+
+	var rule GatewayRule
+	for len(http) > 0 {
+		field := protoFieldRegex.FindStringSubmatchIndex(http)
+		if field == nil {
+			break
+		}
+		key := http[field[2]:field[3]]
+		if http[field[4]:field[5]] == "{" {
+			// Skip additional bindings
+			nested, err := protoBlock(http, field[5]-1)
+			if err != nil {
+				return GatewayRule{}, false, err
+			}
+			http = http[field[5]+len(nested)+1:]
+			continue
+		}
+
+		value := http[field[6]:field[7]]
+		switch key {
+		case "body":
+			rule.Body = value
+		case "response_body":
+			rule.ResponseBody = value
+		default:
+			rule.Method = strings.ToUpper(key)
+			rule.Path = value
+		}
+		http = http[field[1]:]
+	}
+
+	if rule.Method == "" {
+		return GatewayRule{}, false, fmt.Errorf("google.api.http option has no method")
+	}
+
+	return rule, true, nil
+}
+
+// protoBlock returns the content of the braces opening at the index
+func protoBlock(source string, open int) (string, error) {
+	depth := 0
+	for i := open; i < len(source); i++ {
+		switch source[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return source[open+1 : i], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("unbalanced braces")
+}
+
+// AddGatewayInteraction adds the interaction of a gRPC method called through
+// grpc-gateway. The request's path parameters are bound from the request
+// message, and its other fields are sent in the body or as query parameters
+// according to the rule. Field names are converted to JSON names, as the
+// gateway does.
+func (p *Pact) AddGatewayInteraction(description string, call GatewayCall) (*Interaction, error) {
+	request, err := call.request()
+	if err != nil {
+		return nil, fmt.Errorf("unable to map gRPC request for '%s': %v", description, err)
+	}
+
+	status := call.Status
+	if status == 0 {
+		status = 200
+	}
+
+	var body interface{} = call.Response
+	if call.Rule.ResponseBody != "" {
+		body = call.Response[call.Rule.ResponseBody]
+	}
+
+	return p.AddInteraction().
+		Given(call.State).
+		UponReceiving(description).
+		WithRequest(request).
+		WillRespondWith(Response{
+			Status:  status,
+			Headers: MapMatcher{"Content-Type": Term("application/json", `^application\/json`)},
+			Body:    call.jsonNames(body),
+		}), nil
+}
+
+// request maps the request message to an HTTP request
+func (c GatewayCall) request() (Request, error) {
+	remaining := copyMessage(c.Request)
+
+	var missing []string
+	path := pathVariableRegex.ReplaceAllStringFunc(c.Rule.Path, func(variable string) string {
+		match := pathVariableRegex.FindStringSubmatch(variable)
+		value, ok := takeField(remaining, strings.Split(match[1], "."))
+		if !ok {
+			missing = append(missing, match[1])
+			return variable
+		}
+		if m, ok := value.(Matcher); ok {
+			value = m.GetValue()
+		}
+
+		// Variables with a pattern such as {name=users/*} bind segments
+		if match[2] != "" {
+			return fmt.Sprint(value)
+		}
+		return url.PathEscape(fmt.Sprint(value))
+	})
+	if len(missing) > 0 {
+		return Request{}, fmt.Errorf("the request has no value for path parameters: %s", strings.Join(missing, ", "))
+	}
+
+	request := Request{Method: c.Rule.Method, Path: String(path), Headers: c.Headers}
+	switch c.Rule.Body {
+	case "*":
+		request.Body = c.jsonNames(remaining)
+		return request, nil
+	case "":
+	default:
+		body, _ := takeField(remaining, []string{c.Rule.Body})
+		request.Body = c.jsonNames(body)
+	}
+
+	query, err := c.query("", remaining)
+	if err != nil {
+		return Request{}, err
+	}
+	if len(query) > 0 {
+		request.Query = query
+	}
+
+	return request, nil
+}
+
+// query flattens the fields into query parameters e.g. "filter.name"
+func (c GatewayCall) query(prefix string, fields map[string]interface{}) (MapMatcher, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	query := MapMatcher{}
+	for _, name := range names {
+		key := prefix + c.jsonName(name)
+		switch value := fields[name].(type) {
+		case nil:
+		case map[string]interface{}:
+			nested, err := c.query(key+".", value)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range nested {
+				query[k] = v
+			}
+		case []interface{}:
+			return nil, fmt.Errorf("repeated field %s can't be sent as a query parameter", key)
+		case Matcher:
+			query[key] = value
+		default:
+			query[key] = String(fmt.Sprint(value))
+		}
+	}
+
+	return query, nil
+}
+
+// jsonNames converts the field names in a message to their JSON names,
+// including within matchers
+func (c GatewayCall) jsonNames(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for name, field := range v {
+			converted[c.jsonName(name)] = c.jsonNames(field)
+		}
+		return converted
+	case StructMatcher:
+		converted := make(StructMatcher, len(v))
+		for name, field := range v {
+			converted[c.jsonName(name)] = c.jsonNames(field)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = c.jsonNames(item)
+		}
+		return converted
+	case like:
+		return like{Contents: c.jsonNames(v.Contents)}
+	case eachLike:
+		return eachLike{Contents: c.jsonNames(v.Contents), Min: v.Min}
+	}
+
+	return value
+}
+
+// jsonName converts a protobuf field name to its JSON name e.g. "user_id" to
+// "userId", unless UseProtoNames is set
+func (c GatewayCall) jsonName(name string) string {
+	if c.UseProtoNames {
+		return name
+	}
+
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// copyMessage copies the nested messages, so that fields can be removed
+func copyMessage(message map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(message))
+	for name, value := range message {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = copyMessage(nested)
+		}
+		copied[name] = value
+	}
+
+	return copied
+}
+
+// takeField removes the (possibly nested) field from the message, returning
+// its value
+func takeField(message map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := message[path[0]]
+	if !ok {
+		return nil, false
+	}
+	if len(path) == 1 {
+		delete(message, path[0])
+		return value, true
+	}
+
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok = takeField(nested, path[1:])
+	if ok && len(nested) == 0 {
+		delete(message, path[0])
+	}
+
+	return value, ok
+}
@@ -0,0 +1,173 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+const gatewayProto = `
+syntax = "proto3";
+
+import "google/api/annotations.proto";
+
+// UserService manages users { not a block }
+service UserService {
+  rpc GetUser(GetUserRequest) returns (User) {
+    option (google.api.http) = {
+      get: "/v1/users/{user_id}"
+    };
+  }
+
+  /* UpdateUser updates a user */
+  rpc UpdateUser(UpdateUserRequest) returns (User) {
+    option (google.api.http) = {
+      patch: "/v1/{user.name=users/*}"
+      body: "user"
+      additional_bindings {
+        put: "/v2/{user.name=users/*}"
+        body: "*"
+      }
+    };
+  }
+
+  rpc ListUsers(ListUsersRequest) returns (ListUsersResponse) {
+    option (google.api.http) = { get: "/v1/users" response_body: "users" };
+  }
+
+  rpc Ping(PingRequest) returns (PingResponse);
+  rpc Internal(PingRequest) returns (PingResponse) {}
+}
+`
+
+func TestParseGatewayRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-grpc-gateway")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "users.proto")
+	if err = ioutil.WriteFile(file, []byte(gatewayProto), 0644); err != nil {
+		t.Fatalf("unable to write proto: %v", err)
+	}
+
+	rules, err := ParseGatewayRules(file)
+	if err != nil {
+		t.Fatalf("unable to parse rules: %v", err)
+	}
+
+	want := map[string]GatewayRule{
+		"UserService.GetUser":    {Method: "GET", Path: "/v1/users/{user_id}"},
+		"UserService.UpdateUser": {Method: "PATCH", Path: "/v1/{user.name=users/*}", Body: "user"},
+		"UserService.ListUsers":  {Method: "GET", Path: "/v1/users", ResponseBody: "users"},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("want %+v, got %+v", want, rules)
+	}
+}
+
+func TestGatewayCall_Request(t *testing.T) {
+	tests := []struct {
+		name  string
+		call  GatewayCall
+		path  string
+		query string
+		body  string
+	}{
+		{
+			name: "query",
+			call: GatewayCall{
+				Rule:    GatewayRule{Method: "GET", Path: "/v1/users/{user_id}"},
+				Request: map[string]interface{}{"user_id": "a b", "read_mask": "display_name", "filter": map[string]interface{}{"org_id": 1}},
+			},
+			path:  "/v1/users/a%20b",
+			query: `{"filter.orgId":"1","readMask":"display_name"}`,
+			body:  `null`,
+		},
+		{
+			name: "field body",
+			call: GatewayCall{
+				Rule: GatewayRule{Method: "PATCH", Path: "/v1/{user.name=users/*}", Body: "user"},
+				Request: map[string]interface{}{
+					"user":        map[string]interface{}{"name": "users/10", "display_name": Like("Billy")},
+					"update_mask": "display_name",
+				},
+			},
+			path:  "/v1/users/10",
+			query: `{"updateMask":"display_name"}`,
+			body:  `{"displayName":{"json_class":"Pact::SomethingLike","contents":"Billy"}}`,
+		},
+		{
+			name: "whole body",
+			call: GatewayCall{
+				Rule:          GatewayRule{Method: "POST", Path: "/v1/users", Body: "*"},
+				Request:       map[string]interface{}{"display_name": "Billy", "email_addresses": []interface{}{"billy@example.com"}},
+				UseProtoNames: true,
+			},
+			path:  "/v1/users",
+			query: `null`,
+			body:  `{"display_name":"Billy","email_addresses":["billy@example.com"]}`,
+		},
+	}
+
+	for _, test := range tests {
+		request, err := test.call.request()
+		if err != nil {
+			t.Fatalf("%s: unable to map request: %v", test.name, err)
+		}
+		if path := fmt.Sprint(request.Path.GetValue()); path != test.path {
+			t.Fatalf("%s: want path %s, got %s", test.name, test.path, path)
+		}
+		if query, _ := json.Marshal(request.Query); string(query) != test.query {
+			t.Fatalf("%s: want query %s, got %s", test.name, test.query, query)
+		}
+		if body, _ := json.Marshal(request.Body); string(body) != test.body {
+			t.Fatalf("%s: want body %s, got %s", test.name, test.body, body)
+		}
+	}
+
+	// The caller's message isn't modified
+	call := tests[1].call
+	if _, ok := call.Request["user"].(map[string]interface{})["name"]; !ok {
+		t.Fatalf("request message was modified: %v", call.Request)
+	}
+}
+
+func TestGatewayCall_RequestErrors(t *testing.T) {
+	calls := []GatewayCall{
+		{Rule: GatewayRule{Method: "GET", Path: "/v1/users/{user_id}"}},
+		{Rule: GatewayRule{Method: "GET", Path: "/v1/users"}, Request: map[string]interface{}{"ids": []interface{}{1, 2}}},
+	}
+
+	for _, call := range calls {
+		if _, err := call.request(); err == nil {
+			t.Fatalf("expected an error mapping %+v", call)
+		}
+	}
+}
+
+func TestAddGatewayInteraction(t *testing.T) {
+	pact := &Pact{Host: "localhost", Server: &types.MockServer{Port: 1234}, DisableToolValidityCheck: true}
+	interaction, err := pact.AddGatewayInteraction("a request to list users", GatewayCall{
+		Rule:     GatewayRule{Method: "GET", Path: "/v1/users", ResponseBody: "users"},
+		Response: map[string]interface{}{"users": EachLike(map[string]interface{}{"user_id": "10"}, 1), "next_page_token": ""},
+	})
+	if err != nil {
+		t.Fatalf("unable to add interaction: %v", err)
+	}
+
+	body, _ := json.Marshal(interaction.Response.Body)
+	if want := `{"json_class":"Pact::ArrayLike","contents":{"userId":"10"},"min":1}`; string(body) != want {
+		t.Fatalf("want body %s, got %s", want, body)
+	}
+	if interaction.Response.Status != 200 || interaction.Description != "a request to list users" {
+		t.Fatalf("unexpected interaction %+v", interaction)
+	}
+}
@@ -0,0 +1,253 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// harRecorder records the traffic handled by the mock server, to write as a
+// HAR (HTTP Archive) file for replaying in browser devtools
+type harRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+
+	// files counts the HAR files written under each name, so repeated
+	// names don't overwrite each other
+	files map[string]int
+}
+
+func newHARRecorder() *harRecorder {
+	return &harRecorder{files: make(map[string]int)}
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func (h *harRecorder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			body, _ = ioutil.ReadAll(r.Body)
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		started := time.Now()
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		elapsed := float64(time.Since(started)) / float64(time.Millisecond)
+
+		h.record(harEntry{
+			StartedDateTime: started.UTC().Format(time.RFC3339Nano),
+			Time:            elapsed,
+			Request:         harRequestOf(r, body),
+			Response:        harResponseOf(recorder),
+			Timings:         harTimings{Wait: elapsed},
+		})
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(recorder.status)
+		w.Write(recorder.body.Bytes()) // nolint:errcheck
+	})
+}
+
+func (h *harRecorder) record(entry harEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, entry)
+}
+
+// take returns the recorded entries, clearing them
+func (h *harRecorder) take() []harEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := h.entries
+	h.entries = nil
+
+	return entries
+}
+
+var harFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// write writes the recorded entries to a HAR file in the directory, named
+// after the test (e.g. its first interaction), and clears them
+func (h *harRecorder) write(dir string, name string) (string, error) {
+	entries := h.take()
+	if entries == nil {
+		entries = []harEntry{}
+	}
+
+	name = strings.Trim(strings.ToLower(harFileNameRegex.ReplaceAllString(name, "-")), "-")
+	if name == "" {
+		name = "mock-server"
+	}
+	h.mu.Lock()
+	h.files[name]++
+	if n := h.files[name]; n > 1 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+	h.mu.Unlock()
+
+	var har harLog
+	har.Log.Version = "1.2"
+	har.Log.Creator = harCreator{Name: "pact-go"}
+	har.Log.Entries = entries
+
+	content, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, name+".har")
+	log.Println("[DEBUG] writing mock server traffic to", file)
+
+	return file, ioutil.WriteFile(file, content, 0644)
+}
+
+func harRequestOf(r *http.Request, body []byte) harRequest {
+	url := *r.URL
+	url.Scheme = "http"
+	url.Host = r.Host
+
+	request := harRequest{
+		Method:      r.Method,
+		URL:         url.String(),
+		HTTPVersion: r.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(r.Header),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	for name, values := range r.URL.Query() {
+		for _, value := range values {
+			request.QueryString = append(request.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+	for _, cookie := range r.Cookies() {
+		request.Cookies = append(request.Cookies, harNameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+	if len(body) > 0 {
+		request.PostData = &harPostData{MimeType: r.Header.Get("Content-Type"), Text: string(body)}
+	}
+
+	return request
+}
+
+func harResponseOf(recorder *bufferedResponse) harResponse {
+	body := recorder.body.Bytes()
+
+	return harResponse{
+		Status:      recorder.status,
+		StatusText:  http.StatusText(recorder.status),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(recorder.header),
+		Content:     harContent{Size: len(body), MimeType: recorder.header.Get("Content-Type"), Text: string(body)},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	return headers
+}
@@ -0,0 +1,89 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPact_VerifyWritesHAR(t *testing.T) {
+	dir, err := ioutil.TempDir("", "har")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			fmt.Fprintln(w, "ok")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 1}`)
+	}))
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:   &types.MockServer{Port: getPort(ms.URL)},
+		Consumer: "My Consumer",
+		Provider: "My Provider",
+		HARDir:   dir,
+	}
+
+	for i := 0; i < 2; i++ {
+		pact.
+			AddInteraction().
+			UponReceiving("A request to create user 1").
+			WithRequest(Request{Method: "POST", Path: String("/users")}).
+			WillRespondWith(Response{Status: 201})
+
+		err = pact.Verify(func() error {
+			res, err := http.Post(fmt.Sprintf("http://localhost:%d/users?notify=true", pact.Server.Port), "application/json", strings.NewReader(`{"name": "mary"}`))
+			if err != nil {
+				return err
+			}
+			return res.Body.Close()
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, name := range []string{"a-request-to-create-user-1.har", "a-request-to-create-user-1-2.har"} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", name, err)
+		}
+
+		var har harLog
+		if err = json.Unmarshal(content, &har); err != nil {
+			t.Fatal(err)
+		}
+		if har.Log.Version != "1.2" || len(har.Log.Entries) != 1 {
+			t.Fatalf("expected a single entry, got %s", content)
+		}
+
+		entry := har.Log.Entries[0]
+		if entry.Request.Method != "POST" || !strings.HasSuffix(entry.Request.URL, "/users?notify=true") {
+			t.Fatalf("unexpected request %+v", entry.Request)
+		}
+		if entry.Request.PostData == nil || entry.Request.PostData.Text != `{"name": "mary"}` {
+			t.Fatalf("expected the request body to be recorded, got %+v", entry.Request.PostData)
+		}
+		if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0] != (harNameValue{Name: "notify", Value: "true"}) {
+			t.Fatalf("unexpected query %+v", entry.Request.QueryString)
+		}
+		if entry.Response.Status != 201 || entry.Response.Content.Text != `{"id": 1}` || entry.Response.Content.MimeType != "application/json" {
+			t.Fatalf("unexpected response %+v", entry.Response)
+		}
+	}
+}
@@ -18,15 +18,78 @@ type Interaction struct {
 
 	// Provider state to be written into the Pact file
 	State string `json:"providerState,omitempty"`
+
+	// States are the provider states of the interaction, when it has
+	// several or they have parameters, see GivenWithParams. They are written
+	// to version 3 pacts as "providerStates".
+	States []State `json:"-"`
+
+	// Step of a response sequence, and the number of requests it answers,
+	// see Sequence and Repeat
+	sequence int
+	repeat   int
+
+	// Arbitrary metadata e.g. the owning team, see WithMetadata
+	metadata map[string]string
+
+	// Pact specification version the interaction is written with, see
+	// Validate
+	specificationVersion int
+
+	// Whether the request and response bodies must be specified, see
+	// Pact.ExplicitBodies
+	explicitBodies bool
+
+	// Whether the example response must satisfy its own matching rules, see
+	// Pact.ValidateExamples
+	validateExamples bool
+
+	// Whether unexpected keys are allowed in the bodies, see
+	// WithBodyMatching
+	bodyMatching BodyMatching
+
+	// Body paths at which numbers and numeric strings are equivalent, see
+	// WithNumbersAsStrings
+	numbersAsStrings []string
+
+	// Names of the transforms of the request and response bodies, see
+	// WithRequestTransform and WithResponseTransform
+	requestTransform  string
+	responseTransform string
 }
 
 // Given specifies a provider state. Optional.
 func (i *Interaction) Given(state string) *Interaction {
+	if len(i.States) > 0 {
+		return i.GivenWithParams(state, nil)
+	}
 	i.State = state
 
 	return i
 }
 
+// GivenWithParams adds a provider state with parameters for the provider to
+// set it up with e.g. GivenWithParams("user exists", map[string]interface{}{"id": 10}).
+// An interaction may have several states. Requires pact specification
+// version 3.
+func (i *Interaction) GivenWithParams(state string, params map[string]interface{}) *Interaction {
+	if len(i.States) == 0 && i.State != "" {
+		i.States = []State{{Name: i.State}}
+	}
+	if i.State == "" {
+		i.State = state
+	}
+	i.States = append(i.States, State{Name: state, Params: params})
+
+	return i
+}
+
+// hasStateParams determines if the provider states can only be written as
+// "providerStates", as there are several or they have parameters
+func (i *Interaction) hasStateParams() bool {
+	return len(i.States) > 1 || (len(i.States) == 1 && len(i.States[0].Params) > 0)
+}
+
 // UponReceiving specifies the name of the test case. This becomes the name of
 // the consumer/provider pair in the Pact file. Mandatory.
 func (i *Interaction) UponReceiving(description string) *Interaction {
@@ -45,7 +108,7 @@ func (i *Interaction) WithRequest(request Request) *Interaction {
 	// as per original allowed implementation, e.g.
 	// { "foo": "bar", "baz": like("bat") }
 	if isJSONFormattedObject(request.Body) {
-		log.Println("[WARN] request body appears to be a JSON formatted object, " +
+		log.Println("[WARN] request body appears to be a JSON formatted object or array, " +
 			"no structural matching will occur. Support for structured strings has been" +
 			"deprecated as of 0.13.0")
 	}
@@ -61,8 +124,17 @@ func (i *Interaction) WillRespondWith(response Response) *Interaction {
 	return i
 }
 
+// resolveJSONBodyRules merges the rules of raw JSON bodies with the other
+// matching rules of the request and response. It runs when the interaction
+// is verified, so that rules added to a body after WithRequest or
+// WillRespondWith are included.
+func (i *Interaction) resolveJSONBodyRules() {
+	i.Request.MatchingRules = mergeJSONBodyRules(i.Request.Body, i.Request.MatchingRules)
+	i.Response.MatchingRules = mergeJSONBodyRules(i.Response.Body, i.Response.MatchingRules)
+}
+
 // Checks to see if someone has tried to submit a JSON string
-// for an object, which is no longer supported
+// for an object or array, which is no longer supported
 func isJSONFormattedObject(stringOrObject interface{}) bool {
 	switch content := stringOrObject.(type) {
 	case []byte:
@@ -74,8 +146,9 @@ func isJSONFormattedObject(stringOrObject interface{}) bool {
 			return false
 		}
 
-		// Check if a map type
-		if _, ok := obj.(map[string]interface{}); ok {
+		// Check if a map or array type
+		switch obj.(type) {
+		case map[string]interface{}, []interface{}:
 			return true
 		}
 	}
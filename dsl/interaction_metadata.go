@@ -0,0 +1,255 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// WithMetadata attaches metadata to the interaction, such as the owning
+// team, a ticket or its criticality. It is written to the pact file and can
+// be used to filter verification (see VerifyRequest.MetadataFilter) and to
+// route failures to their owners (see GroupFailuresByMetadata).
+func (i *Interaction) WithMetadata(key string, value string) *Interaction {
+	if i.metadata == nil {
+		i.metadata = make(map[string]string)
+	}
+	i.metadata[key] = value
+
+	return i
+}
+
+// writeInteractionMetadata adds the metadata of each interaction, keyed by
+// description, to the pact file
+func writeInteractionMetadata(file string, metadata map[string]map[string]string) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		if values, ok := metadata[description]; ok && len(values) > 0 {
+			interaction["metadata"] = values
+		}
+	})
+}
+
+// tagsKey is the metadata key the tags of an interaction are recorded under,
+// as a sorted, comma separated list
+const tagsKey = "tags"
+
+// WithTags tags the interaction e.g. "smoke" or "slow", so that provider
+// verification can be limited to a subset of interactions (see
+// VerifyRequest.InteractionTags and SkipInteractionTags). The tags are
+// written to the pact file as the "tags" metadata of the interaction.
+func (i *Interaction) WithTags(tags ...string) *Interaction {
+	if i.metadata == nil {
+		i.metadata = make(map[string]string)
+	}
+	i.metadata[tagsKey] = strings.Join(mergeTags(splitTags(i.metadata[tagsKey]), tags), ",")
+
+	return i
+}
+
+// splitTags parses tags recorded in metadata
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// mergeTags returns the sorted, distinct tags of both lists
+func mergeTags(existing []string, tags []string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, tag := range append(existing, tags...) {
+		for _, t := range splitTags(tag) {
+			if !seen[t] {
+				seen[t] = true
+				merged = append(merged, t)
+			}
+		}
+	}
+	sort.Strings(merged)
+
+	return merged
+}
+
+// hasAnyTag determines if the metadata has any of the tags
+func hasAnyTag(metadata map[string]string, tags []string) bool {
+	for _, tag := range splitTags(metadata[tagsKey]) {
+		for _, t := range tags {
+			if tag == t {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// metadataFilter returns a filter keeping the interactions whose metadata
+// contains all of the filter's key/value pairs, has any of the tags (if
+// given), and none of the skipped tags
+func metadataFilter(filter map[string]string, tags []string, skipTags []string) func(metadata map[string]string) bool {
+	return func(metadata map[string]string) bool {
+		if !matchesMetadata(metadata, filter) {
+			return false
+		}
+		if len(tags) > 0 && !hasAnyTag(metadata, tags) {
+			return false
+		}
+
+		return !hasAnyTag(metadata, skipTags)
+	}
+}
+
+// matchesMetadata determines if the metadata contains all of the filter's
+// key/value pairs
+func matchesMetadata(metadata map[string]string, filter map[string]string) bool {
+	for key, value := range filter {
+		if metadata[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterPactsByMetadata writes copies of the local pact files, containing
+// only the interactions whose metadata is kept by the filter, to a temporary
+// directory. The returned cleanup function removes them.
+func filterPactsByMetadata(pactURLs []string, keep func(metadata map[string]string) bool) ([]string, func(), error) {
+	dir, err := ioutil.TempDir("", "pact-go-filtered")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) } // nolint:errcheck
+
+	filtered := make([]string, 0, len(pactURLs))
+	for n, location := range pactURLs {
+		if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+			cleanup()
+			return nil, nil, fmt.Errorf("'MetadataFilter' and 'InteractionTags' are only supported for local pact files, not %s", location)
+		}
+
+		content, err := ioutil.ReadFile(location)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+
+		file := filepath.Join(dir, fmt.Sprintf("%d-%s", n, filepath.Base(location)))
+		if err = ioutil.WriteFile(file, content, 0644); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+
+		if err = filterPactFile(file, keep); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		filtered = append(filtered, file)
+	}
+
+	return filtered, cleanup, nil
+}
+
+// filterPactFile removes the interactions whose metadata is not kept by the
+// filter from the pact file
+func filterPactFile(file string, keep func(metadata map[string]string) bool) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var pact map[string]json.RawMessage
+	if err = json.Unmarshal(content, &pact); err != nil {
+		return fmt.Errorf("unable to parse pact file %s: %v", file, err)
+	}
+
+	var interactions []json.RawMessage
+	if err = json.Unmarshal(pact["interactions"], &interactions); err != nil && len(pact["interactions"]) > 0 {
+		return fmt.Errorf("unable to parse pact file %s: %v", file, err)
+	}
+
+	kept := make([]json.RawMessage, 0, len(interactions))
+	for _, raw := range interactions {
+		var interaction pactfile.Interaction
+		if err = json.Unmarshal(raw, &interaction); err != nil {
+			return fmt.Errorf("unable to parse pact file %s: %v", file, err)
+		}
+		if keep(interaction.Metadata) {
+			kept = append(kept, raw)
+		}
+	}
+
+	if pact["interactions"], err = json.Marshal(kept); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, out, 0644)
+}
+
+// GroupFailuresByMetadata groups the failed interactions of a verification
+// by the value of a metadata key of the interaction in the (local) pact
+// files, e.g. by owning team. Failures of interactions without the key are
+// grouped under "".
+func GroupFailuresByMetadata(res []types.ProviderVerifierResponse, key string, pactURLs ...string) (map[string][]string, error) {
+	if key == "" {
+		return nil, errors.New("a metadata key is required")
+	}
+
+	// consumer -> description -> value
+	values := make(map[string]map[string]string)
+	for _, location := range pactURLs {
+		pact, err := pactfile.Read(location)
+		if err != nil {
+			return nil, err
+		}
+
+		descriptions, ok := values[pact.Consumer.Name]
+		if !ok {
+			descriptions = make(map[string]string)
+			values[pact.Consumer.Name] = descriptions
+		}
+		for _, interaction := range pact.Interactions {
+			descriptions[interaction.Description] = interaction.Metadata[key]
+		}
+	}
+
+	groups := make(map[string][]string)
+	for _, response := range res {
+		for _, example := range response.Examples {
+			if example.Status != "failed" && example.Status != "errored" {
+				continue
+			}
+
+			descriptions := make([]string, 0, len(values[example.Pact.ConsumerName]))
+			for description := range values[example.Pact.ConsumerName] {
+				descriptions = append(descriptions, description)
+			}
+			value := values[example.Pact.ConsumerName][exampleDescription(example.FullDescription, descriptions)]
+			groups[value] = append(groups[value], example.FullDescription)
+		}
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,151 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+const metadataPact = `{
+  "consumer": {"name": "web"},
+  "provider": {"name": "orders"},
+  "interactions": [
+    {"description": "a request for an order", "request": {"method": "GET", "path": "/orders/1"}, "response": {"status": 200}},
+    {"description": "a request for an order's invoice", "request": {"method": "GET", "path": "/orders/1/invoice"}, "response": {"status": 200}},
+    {"description": "a request for stock", "request": {"method": "GET", "path": "/stock"}, "response": {"status": 200}}
+  ]
+}`
+
+func writeMetadataPact(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "pact-go-metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "web-orders.json")
+	if err = ioutil.WriteFile(file, []byte(metadataPact), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = writeInteractionMetadata(file, map[string]map[string]string{
+		"a request for an order":           {"team": "orders", "criticality": "high"},
+		"a request for an order's invoice": {"team": "billing"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file, func() { os.RemoveAll(dir) }
+}
+
+func TestInteraction_WithMetadata(t *testing.T) {
+	i := (&Interaction{}).WithMetadata("team", "orders").WithMetadata("ticket", "ORD-1")
+
+	if !reflect.DeepEqual(i.metadata, map[string]string{"team": "orders", "ticket": "ORD-1"}) {
+		t.Fatalf("unexpected metadata %v", i.metadata)
+	}
+
+	file, cleanup := writeMetadataPact(t)
+	defer cleanup()
+
+	pact, err := pactfile.Read(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pact.Interactions[0].Metadata["criticality"] != "high" || pact.Interactions[2].Metadata != nil {
+		t.Fatalf("unexpected metadata in the pact file %+v", pact.Interactions)
+	}
+}
+
+func TestFilterPactsByMetadata(t *testing.T) {
+	file, cleanup := writeMetadataPact(t)
+	defer cleanup()
+
+	filtered, cleanupFiltered, err := filterPactsByMetadata([]string{file}, metadataFilter(map[string]string{"team": "orders"}, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pact, err := pactfile.Read(filtered[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pact.Interactions) != 1 || pact.Interactions[0].Description != "a request for an order" {
+		t.Fatalf("expected only the orders team's interaction, got %+v", pact.Interactions)
+	}
+
+	cleanupFiltered()
+	if _, err = os.Stat(filtered[0]); !os.IsNotExist(err) {
+		t.Fatal("expected the filtered pacts to be removed")
+	}
+
+	if _, _, err = filterPactsByMetadata([]string{"http://broker/pacts/1"}, metadataFilter(map[string]string{"team": "orders"}, nil, nil)); err == nil {
+		t.Fatal("expected an error for a remote pact")
+	}
+}
+
+func TestGroupFailuresByMetadata(t *testing.T) {
+	file, cleanup := writeMetadataPact(t)
+	defer cleanup()
+
+	var res []types.ProviderVerifierResponse
+	err := json.Unmarshal([]byte(`[{"examples": [
+		{"status": "failed", "full_description": "Verifying a pact between web and orders A request for an order's invoice with GET /orders/1/invoice returns a response which has status code 200", "pact": {"consumer_name": "web"}},
+		{"status": "failed", "full_description": "Verifying a pact between web and orders A request for stock with GET /stock returns a response which has status code 200", "pact": {"consumer_name": "web"}},
+		{"status": "failed", "full_description": "Verifying a pact between web and orders a request for an order with GET /orders/1 returns a response which has status code 200", "pact": {"consumer_name": "web"}},
+		{"status": "passed", "full_description": "Verifying a pact between web and orders a request for an order with GET /orders/1 returns a response which has a matching body", "pact": {"consumer_name": "web"}}
+	]}]`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := GroupFailuresByMetadata(res, "team", file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(groups["orders"]) != 1 || len(groups["billing"]) != 1 || len(groups[""]) != 1 {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+}
+
+func TestInteraction_WithTags(t *testing.T) {
+	i := (&Interaction{}).WithTags("smoke", "v2-api").WithTags("slow, smoke")
+
+	if i.metadata["tags"] != "slow,smoke,v2-api" {
+		t.Fatalf("expected sorted, distinct tags, got %q", i.metadata["tags"])
+	}
+}
+
+func TestMetadataFilter(t *testing.T) {
+	smoke := map[string]string{"team": "orders", "tags": "smoke,v2-api"}
+	slow := map[string]string{"team": "orders", "tags": "slow"}
+	untagged := map[string]string{"team": "billing"}
+
+	tests := []struct {
+		name           string
+		filter         map[string]string
+		tags, skipTags []string
+		wantSmoke      bool
+		wantSlow       bool
+		wantUntagged   bool
+	}{
+		{name: "no filter", wantSmoke: true, wantSlow: true, wantUntagged: true},
+		{name: "tags", tags: []string{"smoke"}, wantSmoke: true},
+		{name: "any tag", tags: []string{"smoke", "slow"}, wantSmoke: true, wantSlow: true},
+		{name: "skip tags", skipTags: []string{"slow"}, wantSmoke: true, wantUntagged: true},
+		{name: "metadata and tags", filter: map[string]string{"team": "billing"}, tags: []string{"smoke"}},
+	}
+	for _, test := range tests {
+		keep := metadataFilter(test.filter, test.tags, test.skipTags)
+		if keep(smoke) != test.wantSmoke || keep(slow) != test.wantSlow || keep(untagged) != test.wantUntagged {
+			t.Fatalf("%s: want %v, %v and %v, got %v, %v and %v", test.name,
+				test.wantSmoke, test.wantSlow, test.wantUntagged, keep(smoke), keep(slow), keep(untagged))
+		}
+	}
+}
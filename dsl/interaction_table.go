@@ -0,0 +1,142 @@
+package dsl
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// InteractionDefinition declares an interaction as a struct literal, so that
+// many similar interactions can be described in a table, see
+// AddInteractions.
+type InteractionDefinition struct {
+	// Description of the interaction, see UponReceiving. Required.
+	Description string
+
+	// State is the provider state of the interaction, see Given. Optional.
+	State string
+
+	// States are further provider states, with parameters, see
+	// GivenWithParams. Optional.
+	States []State
+
+	Request  Request
+	Response Response
+
+	// Metadata of the interaction, see WithMetadata. Optional.
+	Metadata map[string]string
+
+	// Tags of the interaction, see WithTags. Optional.
+	Tags []string
+}
+
+// interaction builds the interaction the definition declares
+func (d InteractionDefinition) interaction() *Interaction {
+	i := (&Interaction{}).
+		UponReceiving(d.Description).
+		WithRequest(d.Request).
+		WillRespondWith(d.Response)
+	if d.State != "" {
+		i.Given(d.State)
+	}
+	for _, state := range d.States {
+		i.GivenWithParams(state.Name, state.Params)
+	}
+	for _, key := range sortedStringKeys(d.Metadata) {
+		i.WithMetadata(key, d.Metadata[key])
+	}
+	if len(d.Tags) > 0 {
+		i.WithTags(d.Tags...)
+	}
+
+	return i
+}
+
+// interactionKey identifies an interaction in a pact by its description and
+// provider states, which must be unique
+func interactionKey(i *Interaction) string {
+	states := []string{i.State}
+	for _, state := range i.States {
+		states = append(states, state.Name)
+	}
+
+	return i.Description + "\x00" + strings.Join(states, "\x00")
+}
+
+// sameInteraction determines if two interactions with the same key declare
+// the same request and response
+func sameInteraction(a, b *Interaction) bool {
+	return reflect.DeepEqual(a.Request, b.Request) &&
+		reflect.DeepEqual(a.Response, b.Response) &&
+		reflect.DeepEqual(a.States, b.States) &&
+		reflect.DeepEqual(a.metadata, b.metadata)
+}
+
+// AddInteractions adds the interactions defined in the table, for
+// data-driven contract suites in which many similar endpoints are described
+// together. Every definition is validated first, and none are added if any
+// is invalid, all problems being reported together. Definitions that repeat
+// an interaction, with the same description and provider states, are added
+// once; an error is returned if their requests or responses differ. The
+// interaction of each definition is returned, in the order of the table.
+func (p *Pact) AddInteractions(definitions []InteractionDefinition) ([]*Interaction, error) {
+	existing := make(map[string]*Interaction, len(p.Interactions)+len(definitions))
+	for _, interaction := range p.Interactions {
+		existing[interactionKey(interaction)] = interaction
+	}
+
+	interactions := make([]*Interaction, len(definitions))
+	var added []*Interaction
+	var errs []string
+	for n, definition := range definitions {
+		interaction := definition.interaction()
+		interaction.specificationVersion = p.SpecificationVersion
+		interaction.explicitBodies = p.ExplicitBodies
+		interaction.validateExamples = p.ValidateExamples
+		if err := interaction.Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("definition %d: %v", n, err))
+			continue
+		}
+
+		key := interactionKey(interaction)
+		if previous, ok := existing[key]; ok {
+			if !sameInteraction(previous, interaction) {
+				errs = append(errs, fmt.Sprintf("definition %d: interaction '%s' is already defined with a different request, response or metadata", n, interaction.Description))
+				continue
+			}
+			log.Printf("[DEBUG] pact add interactions: interaction '%s' is already defined, skipping\n", interaction.Description)
+			interactions[n] = previous
+			continue
+		}
+
+		existing[key] = interaction
+		interactions[n] = interaction
+		added = append(added, interaction)
+	}
+
+	if len(errs) == 1 {
+		return nil, fmt.Errorf("%s", errs[0])
+	}
+	if len(errs) > 1 {
+		return nil, fmt.Errorf("%d interaction definitions are invalid:\n%s", len(errs), strings.Join(errs, "\n"))
+	}
+
+	p.Setup(true)
+	log.Printf("[DEBUG] pact add interactions: %d added, %d repeated\n", len(added), len(definitions)-len(added))
+	p.Interactions = append(p.Interactions, added...)
+
+	return interactions, nil
+}
+
+// sortedStringKeys returns the keys of the map in order
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
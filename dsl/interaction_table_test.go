@@ -0,0 +1,83 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPact_AddInteractions(t *testing.T) {
+	pact := &Pact{pactClient: newMockClient(), Server: &types.MockServer{}, DisableToolValidityCheck: true}
+	pact.AddInteraction().
+		UponReceiving("a request for user 1").
+		WithRequest(Request{Method: "GET", Path: String("/users/1")}).
+		WillRespondWith(Response{Status: 200})
+
+	var table []InteractionDefinition
+	for _, resource := range []string{"users", "orders", "invoices"} {
+		table = append(table, InteractionDefinition{
+			Description: "a request to list " + resource,
+			State:       resource + " exist",
+			Request:     Request{Method: "GET", Path: String("/" + resource)},
+			Response:    Response{Status: 200, Body: EachLike(map[string]interface{}{"id": Like(1)}, 1)},
+			Tags:        []string{"smoke"},
+		})
+	}
+	table = append(table, table[1], InteractionDefinition{
+		Description: "a request for user 1",
+		Request:     Request{Method: "GET", Path: String("/users/1")},
+		Response:    Response{Status: 200},
+	})
+
+	interactions, err := pact.AddInteractions(table)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pact.Interactions) != 4 || len(interactions) != 5 {
+		t.Fatalf("expected the repeated definitions to be added once, got %d interactions", len(pact.Interactions))
+	}
+	if interactions[3] != interactions[1] || interactions[4] != pact.Interactions[0] {
+		t.Fatal("expected the repeated definitions to return the interactions already added")
+	}
+	if interactions[2].State != "invoices exist" || interactions[2].metadata[tagsKey] != "smoke" {
+		t.Fatalf("unexpected interaction %+v", interactions[2])
+	}
+}
+
+func TestPact_AddInteractionsInvalid(t *testing.T) {
+	pact := &Pact{pactClient: newMockClient(), Server: &types.MockServer{}, DisableToolValidityCheck: true}
+
+	_, err := pact.AddInteractions([]InteractionDefinition{
+		{
+			Description: "a request to list users",
+			Request:     Request{Method: "GET", Path: String("/users")},
+			Response:    Response{Status: 200},
+		},
+		{
+			Description: "a request to list users",
+			Request:     Request{Method: "GET", Path: String("/users")},
+			Response:    Response{Status: 404},
+		},
+		{
+			Request:  Request{Method: "GET", Path: String("/orders")},
+			Response: Response{Status: 200},
+		},
+	})
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"2 interaction definitions are invalid",
+		"definition 1: interaction 'a request to list users' is already defined with a different request, response or metadata",
+		"definition 2: interaction '' is invalid",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+	if len(pact.Interactions) != 0 {
+		t.Fatalf("expected no interactions to be added, got %d", len(pact.Interactions))
+	}
+}
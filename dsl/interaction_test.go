@@ -113,9 +113,12 @@ func TestInteraction_WillRespondWith(t *testing.T) {
 
 func TestInteraction_isStringLikeObject(t *testing.T) {
 	testCases := map[string]bool{
-		"somestring":    false,
-		"":              false,
-		`{"foo":"bar"}`: true,
+		"somestring":      false,
+		"":                false,
+		`{"foo":"bar"}`:   true,
+		`[{"foo":"bar"}]`: true,
+		`42`:              false,
+		`"foo"`:           false,
 	}
 
 	for testCase, want := range testCases {
@@ -124,3 +127,42 @@ func TestInteraction_isStringLikeObject(t *testing.T) {
 		}
 	}
 }
+
+func TestInteraction_RootLevelBodies(t *testing.T) {
+	testCases := map[string]struct {
+		body interface{}
+		want string
+	}{
+		"array":       {body: EachLike(Like(1), 1), want: `{"json_class":"Pact::ArrayLike","contents":{"json_class":"Pact::SomethingLike","contents":1},"min":1}`},
+		"plain array": {body: []int{1, 2}, want: `[1,2]`},
+		"string":      {body: "foo", want: `"foo"`},
+		"number":      {body: 42, want: `42`},
+		"boolean":     {body: true, want: `true`},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			i := (&Interaction{}).
+				UponReceiving("a request").
+				WithRequest(Request{Body: tc.body}).
+				WillRespondWith(Response{Body: tc.body})
+
+			b, err := json.Marshal(i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var decoded struct {
+				Request  struct{ Body json.RawMessage } `json:"request"`
+				Response struct{ Body json.RawMessage } `json:"response"`
+			}
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(decoded.Request.Body) != tc.want || string(decoded.Response.Body) != tc.want {
+				t.Fatalf("want body %s, got request %s and response %s", tc.want, decoded.Request.Body, decoded.Response.Body)
+			}
+		})
+	}
+}
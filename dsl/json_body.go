@@ -0,0 +1,87 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// JSONBodyBuilder is a raw JSON body with a selective set of matching rules.
+// It is useful for teams that already have canonical example payloads, and
+// only want to relax matching on a few fields, bypassing the matcher DSL.
+//
+// It can be used as the Body of a Request or Response. Its rules are merged
+// with those of the request or response when the interaction is verified.
+type JSONBodyBuilder struct {
+	raw   json.RawMessage
+	rules MatchingRules
+	err   error
+}
+
+// JSONBody creates a body from a raw JSON document. Matching rules may then
+// be attached with WithRule.
+func JSONBody(raw json.RawMessage) *JSONBodyBuilder {
+	b := &JSONBodyBuilder{
+		raw:   raw,
+		rules: MatchingRules{},
+	}
+
+	if !json.Valid(raw) {
+		b.err = errors.New("invalid JSON body: the document could not be parsed")
+	}
+
+	return b
+}
+
+// WithRule attaches a matching rule to the body, at the given path relative
+// to the root of the document e.g. "$.items[*].id"
+func (b *JSONBodyBuilder) WithRule(path string, rule Rule) *JSONBodyBuilder {
+	parsed, err := ParseRulePath(path)
+	if err != nil {
+		if b.err == nil {
+			b.err = fmt.Errorf("invalid JSON body rule: %v", err)
+		}
+		return b
+	}
+
+	b.rules.Add(parsed.relativeTo(BodyPath()), rule)
+
+	return b
+}
+
+// MarshalJSON writes the raw document verbatim
+func (b *JSONBodyBuilder) MarshalJSON() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return b.raw, nil
+}
+
+// mergeJSONBodyRules merges the rules of a raw JSON body (if the body is one)
+// with any other matching rules
+func mergeJSONBodyRules(body interface{}, rules MatchingRules) MatchingRules {
+	b, ok := body.(*JSONBodyBuilder)
+	if !ok || len(b.rules) == 0 {
+		return rules
+	}
+
+	merged := MatchingRules{}
+	for path, rule := range b.rules {
+		merged[path] = rule
+	}
+	for path, rule := range rules {
+		merged[path] = rule
+	}
+
+	return merged
+}
+
+// jsonBodyError returns any error recorded while building a raw JSON body
+func jsonBodyError(body interface{}) error {
+	if b, ok := body.(*JSONBodyBuilder); ok {
+		return b.err
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package dsl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONBody_WithRule(t *testing.T) {
+	body := JSONBody(json.RawMessage(`{"id": 1, "items": [{"name": "a"}]}`)).
+		WithRule("$.id", TypeRule())
+
+	i := (&Interaction{}).
+		UponReceiving("a raw request").
+		WithRequest(Request{
+			Method:        "POST",
+			Path:          String("/raw"),
+			Body:          body,
+			MatchingRules: MatchingRules{"$.query.page": TypeRule()},
+		}).
+		WillRespondWith(Response{Status: 200, Body: body})
+
+	// Rules added after the body is given are included when it is verified
+	body.WithRule("$.items[*].name", RegexRule("[a-z]+"))
+	i.resolveJSONBodyRules()
+
+	expectedRules := MatchingRules{
+		"$.body.id":            TypeRule(),
+		"$.body.items[*].name": RegexRule("[a-z]+"),
+	}
+
+	if !reflect.DeepEqual(i.Response.MatchingRules, expectedRules) {
+		t.Fatalf("want rules %v, got %v", expectedRules, i.Response.MatchingRules)
+	}
+
+	if _, ok := i.Request.MatchingRules["$.query.page"]; !ok || len(i.Request.MatchingRules) != 3 {
+		t.Fatalf("expected body rules to be merged with the request rules, got %v", i.Request.MatchingRules)
+	}
+
+	if err := i.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestJSONBody_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(Response{Status: 200, Body: JSONBody(json.RawMessage(`{"id":1}`))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(b) != `{"status":200,"body":{"id":1}}` {
+		t.Fatalf("expected the raw body to be written verbatim, got %s", b)
+	}
+}
+
+func TestJSONBody_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body *JSONBodyBuilder
+	}{
+		{name: "invalid JSON", body: JSONBody(json.RawMessage(`{"id":`))},
+		{name: "invalid path", body: JSONBody(json.RawMessage(`{}`)).WithRule("$.a b", TypeRule())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := (&Interaction{}).
+				UponReceiving("a raw request").
+				WillRespondWith(Response{Body: tt.body})
+
+			if err := i.Validate(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
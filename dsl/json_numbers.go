@@ -0,0 +1,79 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// JSON documents are decoded with decodeJSON, keeping numbers as written
+// (json.Number) rather than as float64, so that integers above 2^53 (e.g.
+// int64 IDs) and high precision decimals are compared exactly.
+
+// jsonNumber returns the exact value of a decoded number, which may be a
+// json.Number or a Go number
+func jsonNumber(value interface{}) (*big.Rat, bool) {
+	var text string
+	switch n := value.(type) {
+	case json.Number:
+		text = n.String()
+	case float64:
+		r := new(big.Rat).SetFloat64(n)
+		return r, r != nil
+	case float32:
+		r := new(big.Rat).SetFloat64(float64(n))
+		return r, r != nil
+	default:
+		if !isNumber(value) {
+			return nil, false
+		}
+		text = fmt.Sprint(value)
+	}
+
+	r, ok := new(big.Rat).SetString(text)
+	return r, ok
+}
+
+// isInteger reports whether the value is a number without a fractional part
+func isInteger(value interface{}) bool {
+	n, ok := jsonNumber(value)
+	return ok && n.IsInt()
+}
+
+// equalJSON compares decoded JSON values, comparing numbers by value rather
+// than by their representation e.g. 1.50 equals 1.5
+func equalJSON(expected, actual interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for key, value := range e {
+			other, ok := a[key]
+			if !ok || !equalJSON(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !equalJSON(e[i], a[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if x, ok := jsonNumber(expected); ok {
+		y, ok := jsonNumber(actual)
+		return ok && x.Cmp(y) == 0
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
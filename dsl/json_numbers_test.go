@@ -0,0 +1,46 @@
+package dsl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEqualJSON(t *testing.T) {
+	cases := []struct {
+		expected, actual interface{}
+		equal            bool
+	}{
+		{json.Number("9007199254740993"), json.Number("9007199254740993"), true},
+		{json.Number("9007199254740993"), json.Number("9007199254740992"), false},
+		{int64(9007199254740993), json.Number("9007199254740993"), true},
+		{json.Number("1.50"), 1.5, true},
+		{json.Number("1e3"), 1000, true},
+		{json.Number("0.1"), json.Number("0.10000000000000001"), false},
+		{json.Number("1"), "1", false},
+		{map[string]interface{}{"a": []interface{}{json.Number("2")}}, map[string]interface{}{"a": []interface{}{2.0}}, true},
+		{map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1, "b": 2}, false},
+		{"a", "a", true},
+	}
+
+	for _, c := range cases {
+		if equalJSON(c.expected, c.actual) != c.equal {
+			t.Fatalf("expected equalJSON(%v, %v) to be %v", c.expected, c.actual, c.equal)
+		}
+	}
+}
+
+func TestIsInteger(t *testing.T) {
+	for value, want := range map[interface{}]bool{
+		json.Number("9223372036854775808"): true,
+		json.Number("1.0"):                 true,
+		json.Number("1.5"):                 false,
+		json.Number("1e2"):                 true,
+		42:                                 true,
+		2.5:                                false,
+		"1":                                false,
+	} {
+		if isInteger(value) != want {
+			t.Fatalf("expected isInteger(%v) to be %v", value, want)
+		}
+	}
+}
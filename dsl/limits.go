@@ -0,0 +1,106 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// MockServerLimits protect the test process from runaway requests, e.g. a
+// consumer uploading an unbounded stream. Requests exceeding a limit are
+// rejected before they reach the mock server, and reported as a
+// types.RequestMismatch by Verify.
+type MockServerLimits struct {
+	// MaxBodySize is the largest request body accepted, in bytes. Optional.
+	MaxBodySize int64
+
+	// MaxHeaders is the most request header values accepted. Optional.
+	MaxHeaders int
+
+	// Timeout is the longest time taken to receive a request's headers and
+	// body, set as the read timeout of the server. Optional.
+	Timeout time.Duration
+}
+
+// read reads the body of the request within the limits. If a limit is
+// exceeded it returns the status to respond with, and a mismatch describing
+// the limit.
+func (l MockServerLimits) read(r *http.Request) ([]byte, int, *types.RequestMismatch) {
+	exceeded := func(status int, format string, args ...interface{}) ([]byte, int, *types.RequestMismatch) {
+		return nil, status, &types.RequestMismatch{Method: r.Method, Path: r.URL.Path, Message: fmt.Sprintf(format, args...)}
+	}
+
+	headers := 0
+	for _, values := range r.Header {
+		headers += len(values)
+	}
+	if l.MaxHeaders > 0 && headers > l.MaxHeaders {
+		return exceeded(http.StatusRequestHeaderFieldsTooLarge, "the request has %d headers, more than the mock server's limit of %d", headers, l.MaxHeaders)
+	}
+
+	max := l.MaxBodySize
+	if max > 0 && r.ContentLength > max {
+		return exceeded(http.StatusRequestEntityTooLarge, "the request body of %d bytes exceeds the mock server's limit of %d bytes", r.ContentLength, max)
+	}
+	if r.Body == nil {
+		return nil, 0, nil
+	}
+
+	var reader io.Reader = r.Body
+	if max > 0 {
+		reader = io.LimitReader(r.Body, max+1)
+	}
+
+	// The server's read timeout ends the read of a stalled body
+	body, err := ioutil.ReadAll(reader)
+	if err, ok := err.(net.Error); ok && err.Timeout() {
+		return exceeded(http.StatusRequestTimeout, "the request was not received within the mock server's timeout of %s", l.Timeout)
+	}
+	if err != nil {
+		return exceeded(http.StatusBadRequest, "unable to read the request body: %v", err)
+	}
+	if max > 0 && int64(len(body)) > max {
+		return exceeded(http.StatusRequestEntityTooLarge, "the request body exceeds the mock server's limit of %d bytes", max)
+	}
+
+	return body, 0, nil
+}
+
+// middleware rejects requests exceeding the limits, recording the mismatch
+func (l MockServerLimits) middleware(record func(types.InteractionMismatches)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Pact-Mock-Service") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, status, mismatch := l.read(r)
+			if mismatch != nil {
+				log.Println("[WARN]", mismatch)
+				record(types.InteractionMismatches{Mismatches: []types.Mismatch{*mismatch}})
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Connection", "close")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(map[string]string{"message": mismatch.String()}) // nolint:errcheck
+				return
+			}
+
+			if r.Body != nil {
+				r.Body.Close()
+				r.Body = ioutil.NopCloser(bytes.NewReader(body))
+				r.ContentLength = int64(len(body))
+				r.Header.Del("Content-Length")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
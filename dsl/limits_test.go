@@ -0,0 +1,134 @@
+package dsl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestMockServerLimits_Middleware(t *testing.T) {
+	var recorded []types.InteractionMismatches
+	var received string
+	limits := MockServerLimits{MaxBodySize: 10, MaxHeaders: 3, Timeout: 50 * time.Millisecond}
+	handler := limits.middleware(func(m types.InteractionMismatches) {
+		recorded = append(recorded, m)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+	}))
+
+	chunked := httptest.NewRequest("POST", "/uploads", io.MultiReader(strings.NewReader("0123456789"), strings.NewReader("abc")))
+	chunked.ContentLength = -1
+	headers := httptest.NewRequest("GET", "/uploads", nil)
+	for _, name := range []string{"A", "B", "C", "D"} {
+		headers.Header.Set(name, "1")
+	}
+
+	cases := []struct {
+		request *http.Request
+		status  int
+		message string
+	}{
+		{httptest.NewRequest("POST", "/uploads", strings.NewReader("small")), http.StatusOK, ""},
+		{httptest.NewRequest("POST", "/uploads", strings.NewReader("0123456789abc")), http.StatusRequestEntityTooLarge, "the request body of 13 bytes exceeds the mock server's limit of 10 bytes"},
+		{chunked, http.StatusRequestEntityTooLarge, "the request body exceeds the mock server's limit of 10 bytes"},
+		{headers, http.StatusRequestHeaderFieldsTooLarge, "the request has 4 headers, more than the mock server's limit of 3"},
+	}
+
+	for _, c := range cases {
+		recorded = nil
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, c.request)
+
+		if recorder.Code != c.status {
+			t.Fatalf("expected status %d, got %d", c.status, recorder.Code)
+		}
+		if c.message == "" {
+			if received != "small" || len(recorded) != 0 {
+				t.Fatalf("expected the request to be passed on, got %q and %v", received, recorded)
+			}
+			continue
+		}
+		want := "request " + c.request.Method + " /uploads: " + c.message
+		if len(recorded) != 1 || recorded[0].Mismatches[0].String() != want || !strings.Contains(recorder.Body.String(), c.message) {
+			t.Fatalf("expected the mismatch %q, got %v and %s", want, recorded, recorder.Body.String())
+		}
+	}
+}
+
+func TestMockServerLimits_NoLimits(t *testing.T) {
+	req := httptest.NewRequest("POST", "/uploads", strings.NewReader(strings.Repeat("x", 20<<20)))
+	if body, _, mismatch := (MockServerLimits{}).read(req); mismatch != nil || len(body) != 20<<20 {
+		t.Fatalf("expected any body to be accepted, got %v", mismatch)
+	}
+}
+
+func TestMockServerLimits_Timeout(t *testing.T) {
+	var recorded []types.InteractionMismatches
+	limits := MockServerLimits{Timeout: 50 * time.Millisecond}
+	server := httptest.NewUnstartedServer(limits.middleware(func(m types.InteractionMismatches) {
+		recorded = append(recorded, m)
+	})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+	server.Config.ReadTimeout = limits.Timeout
+	server.Start()
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "POST /uploads HTTP/1.1\r\nHost: localhost\r\nContent-Length: 10\r\n\r\n01234")
+
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestTimeout {
+		t.Fatalf("expected the stalled request to time out, got %d", res.StatusCode)
+	}
+	if len(recorded) != 1 || recorded[0].Mismatches[0].String() != "request POST /uploads: the request was not received within the mock server's timeout of 50ms" {
+		t.Fatalf("unexpected mismatches %v", recorded)
+	}
+}
+
+func TestNativeMockServer_Limits(t *testing.T) {
+	server := NewNativeMockServer()
+	defer server.Close()
+	server.Limits = MockServerLimits{MaxBodySize: 4}
+
+	res, err := http.Post(server.URL+"/uploads", "text/plain", strings.NewReader("too large"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected the request to be rejected, got %d", res.StatusCode)
+	}
+
+	err = server.Verify()
+	if err == nil || !strings.Contains(err.Error(), "request POST /uploads: the request body of 9 bytes exceeds the mock server's limit of 4 bytes") {
+		t.Fatalf("expected the limit to be reported, got %v", err)
+	}
+}
+
+func TestNativeMockServer_LimitsTimeout(t *testing.T) {
+	server := NewUnstartedNativeMockServer()
+	server.Limits.Timeout = time.Second
+	server.Start() // nolint:errcheck
+	defer server.Close()
+
+	if server.Config.ReadTimeout != time.Second {
+		t.Fatalf("expected the timeout to be the server's read timeout, got %s", server.Config.ReadTimeout)
+	}
+}
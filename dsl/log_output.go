@@ -0,0 +1,71 @@
+package dsl
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	logHandlerMutex sync.Mutex
+
+	// logHandler receives each line logged by the package, after level
+	// filtering. Output is written to stderr if nil.
+	logHandler func(level string, message string)
+)
+
+// logLevelRegex finds the level of a line logged by the package e.g.
+// "2020/01/02 03:04:05 [DEBUG] pact setup logging"
+var logLevelRegex = regexp.MustCompile(`\[(TRACE|DEBUG|INFO|WARN|ERROR)\]\s?`)
+
+// captureTestLogs routes the package's log output into the test's log until
+// the returned function is called, unless it is already routed elsewhere.
+// It is replaced when built with Go 1.22 or later, see LogToTest.
+var captureTestLogs = func(t testing.TB) func() {
+	return func() {}
+}
+
+// setLogHandler replaces the handler of the package's log output, returning
+// the previous handler
+func setLogHandler(handler func(level string, message string)) func(level string, message string) {
+	logHandlerMutex.Lock()
+	defer logHandlerMutex.Unlock()
+
+	previous := logHandler
+	logHandler = handler
+
+	return previous
+}
+
+// logOutput is the destination of the package's log output, after level
+// filtering
+type logOutput struct{}
+
+func (logOutput) Write(p []byte) (int, error) {
+	logHandlerMutex.Lock()
+	handler := logHandler
+	logHandlerMutex.Unlock()
+
+	if handler == nil {
+		return os.Stderr.Write(p)
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		handler(parseLogLine(line))
+	}
+
+	return len(p), nil
+}
+
+// parseLogLine splits a logged line into its level (INFO if it has none) and
+// message, without the timestamp
+func parseLogLine(line string) (string, string) {
+	loc := logLevelRegex.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return "INFO", strings.TrimSpace(line)
+	}
+
+	return line[loc[2]:loc[3]], strings.TrimSpace(line[loc[1]:])
+}
@@ -0,0 +1,38 @@
+package dsl
+
+import (
+	"log"
+	"testing"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		line, level, message string
+	}{
+		{"2020/01/02 03:04:05 [DEBUG] pact setup logging", "DEBUG", "pact setup logging"},
+		{"[WARN] unable to record mismatches", "WARN", "unable to record mismatches"},
+		{"2020/01/02 03:04:05 starting verification", "INFO", "2020/01/02 03:04:05 starting verification"},
+	}
+	for _, test := range tests {
+		level, message := parseLogLine(test.line)
+		if level != test.level || message != test.message {
+			t.Fatalf("%q: want %s %q, got %s %q", test.line, test.level, test.message, level, message)
+		}
+	}
+}
+
+func TestLogOutput(t *testing.T) {
+	var levels, messages []string
+	previous := setLogHandler(func(level string, message string) {
+		levels = append(levels, level)
+		messages = append(messages, message)
+	})
+	defer setLogHandler(previous)
+
+	logger := log.New(logOutput{}, "", log.LstdFlags)
+	logger.Println("[ERROR] first\n[TRACE] second")
+
+	if len(messages) != 2 || levels[0] != "ERROR" || messages[0] != "first" || levels[1] != "TRACE" || messages[1] != "second" {
+		t.Fatalf("unexpected levels %v and messages %v", levels, messages)
+	}
+}
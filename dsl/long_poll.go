@@ -0,0 +1,85 @@
+package dsl
+
+import "fmt"
+
+// LongPoll describes a polled endpoint, e.g. a job status, which answers with
+// 204 No Content until the provider reaches a given state and then with 200.
+type LongPoll struct {
+	// Description of the resource e.g. "job 1", used to describe the
+	// interactions
+	Description string
+
+	// Method of the request. Defaults to GET
+	Method string
+
+	// Path of the resource. Must be a plain string for the responses to be
+	// sequenced.
+	Path string
+
+	// Headers of the requests. Optional.
+	Headers MapMatcher
+
+	// Retries is the number of 204 responses received before the 200.
+	// Defaults to 1.
+	Retries int
+
+	// PendingState is the provider state whilst the resource is incomplete.
+	// Optional.
+	PendingState string
+
+	// CompleteState is the provider state once the resource is complete
+	// e.g. "job 1 has finished"
+	CompleteState string
+
+	// Body of the 200 response
+	Body interface{}
+
+	// Headers of the 200 response. Optional.
+	ResponseHeaders MapMatcher
+}
+
+// AddLongPoll adds the interactions of a polled endpoint: a 204 response,
+// answering the first Retries requests, followed by a 200 response. They are
+// serialised as distinct interactions, with their own provider states.
+func (p *Pact) AddLongPoll(l LongPoll) (pending *Interaction, complete *Interaction) {
+	method := l.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	retries := l.Retries
+	if retries < 1 {
+		retries = 1
+	}
+
+	pending = p.AddInteraction().
+		Given(l.PendingState).
+		UponReceiving(fmt.Sprintf("a poll for %s before %s", l.Description, l.CompleteState)).
+		WithRequest(Request{
+			Method:  method,
+			Path:    String(l.Path),
+			Headers: l.Headers,
+		}).
+		WillRespondWith(Response{
+			Status: 204,
+		}).
+		Sequence(1).
+		Repeat(retries)
+
+	complete = p.AddInteraction().
+		Given(l.CompleteState).
+		UponReceiving(fmt.Sprintf("a poll for %s once %s", l.Description, l.CompleteState)).
+		WithRequest(Request{
+			Method:  method,
+			Path:    String(l.Path),
+			Headers: l.Headers,
+		}).
+		WillRespondWith(Response{
+			Status:  200,
+			Headers: l.ResponseHeaders,
+			Body:    l.Body,
+		}).
+		Sequence(2)
+
+	return pending, complete
+}
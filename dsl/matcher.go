@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -18,8 +20,45 @@ const (
 	timestamp   = `^([\+-]?\d{4}(?!\d{2}\b))((-?)((0[1-9]|1[0-2])(\3([12]\d|0[1-9]|3[01]))?|W([0-4]\d|5[0-2])(-?[1-7])?|(00[1-9]|0[1-9]\d|[12]\d{2}|3([0-5]\d|6[1-6])))([T\s]((([01]\d|2[0-3])((:?)[0-5]\d)?|24\:?00)([\.,]\d+(?!:))?)?(\17[0-5]\d([\.,]\d+)?)?([zZ]|([\+-])([01]\d|2[0-3]):?([0-5]\d)?)?)?)?$`
 	date        = `^([\+-]?\d{4}(?!\d{2}\b))((-?)((0[1-9]|1[0-2])(\3([12]\d|0[1-9]|3[01]))?|W([0-4]\d|5[0-2])(-?[1-7])?|(00[1-9]|0[1-9]\d|[12]\d{2}|3([0-5]\d|6[1-6])))?)`
 	timeRegex   = `^(T\d\d:\d\d(:\d\d)?(\.\d+)?(([+-]\d\d:\d\d)|Z)?)?$`
+	ipv4Octet   = `(25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])`
+	ipv4Address = ipv4Octet + `(\.` + ipv4Octet + `){3}`
+	hextet      = `[0-9a-fA-F]{1,4}`
+	dateTime    = `\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T([01]\d|2[0-3]):[0-5]\d:[0-5]\d(\.\d+)?`
+	utcOffset   = `[+-]([01]\d|2[0-3]):[0-5]\d`
+	rfc1123     = `^(Mon|Tue|Wed|Thu|Fri|Sat|Sun), (0[1-9]|[12]\d|3[01]) (Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) \d{4} ([01]\d|2[0-3]):[0-5]\d:[0-5]\d ([A-Z]{3,4}|[+-]\d{4})$`
+	epochSecs   = `^\d{9,10}$`
+	epochMillis = `^\d{12,13}$`
 )
 
+var ipv6Address = ipv6Pattern()
+
+// ipv6Pattern returns a regex for IPv6 addresses in their full, compressed
+// and IPv4 mapped forms, e.g. "2001:db8::1" and "::ffff:192.0.2.128"
+func ipv6Pattern() string {
+	alternatives := []string{
+		fmt.Sprintf("(%s:){7}%s", hextet, hextet),
+		fmt.Sprintf("(%s:){6}%s", hextet, ipv4Address),
+	}
+	// "::" replaces at least one group, so with n groups before it there
+	// are at most 7-n after it, or 5-n before a trailing IPv4 address
+	for n := 0; n <= 7; n++ {
+		compressed := "::"
+		if n > 0 {
+			compressed = fmt.Sprintf("(%s:){%d}:", hextet, n)
+		}
+		if n < 7 {
+			alternatives = append(alternatives, fmt.Sprintf("%s(%s(:%s){0,%d})?", compressed, hextet, hextet, 6-n))
+		} else {
+			alternatives = append(alternatives, compressed)
+		}
+		if n <= 5 {
+			alternatives = append(alternatives, fmt.Sprintf("%s(%s:){0,%d}%s", compressed, hextet, 5-n, ipv4Address))
+		}
+	}
+
+	return "(" + strings.Join(alternatives, "|") + ")"
+}
+
 var timeExample = time.Date(2000, 2, 1, 12, 30, 0, 0, time.UTC)
 
 var fullRegex = regexp.MustCompile(`regex=(.*)$`)
@@ -37,6 +76,11 @@ func (m eachLike) GetValue() interface{} {
 func (m eachLike) isMatcher() {
 }
 
+// Describe attaches a description of the field to the matcher
+func (m eachLike) Describe(description string) Matcher {
+	return describe(m, description)
+}
+
 func (m eachLike) MarshalJSON() ([]byte, error) {
 	type marshaler eachLike
 
@@ -57,6 +101,11 @@ func (m like) GetValue() interface{} {
 func (m like) isMatcher() {
 }
 
+// Describe attaches a description of the field to the matcher
+func (m like) Describe(description string) Matcher {
+	return describe(m, description)
+}
+
 func (m like) MarshalJSON() ([]byte, error) {
 	type marshaler like
 
@@ -77,6 +126,11 @@ func (m term) GetValue() interface{} {
 func (m term) isMatcher() {
 }
 
+// Describe attaches a description of the field to the matcher
+func (m term) Describe(description string) Matcher {
+	return describe(m, description)
+}
+
 func (m term) MarshalJSON() ([]byte, error) {
 	type marshaler term
 
@@ -116,7 +170,26 @@ func Like(content interface{}) Matcher {
 
 // Term specifies that the matching should generate a value
 // and also match using a regular expression.
+// If generate is empty, an example is synthesised from the regular expression.
+// If none can be, e.g. for lookarounds Go doesn't support, a warning is logged
+// and the example is left empty, see TermE.
 func Term(generate string, matcher string) Matcher {
+	m, err := TermE(generate, matcher)
+	if err != nil {
+		log.Printf("[WARN] term: %v, the example will be empty\n", err)
+	}
+
+	return m
+}
+
+// TermE is Term, returning an error if generate is empty and no example can
+// be synthesised from the regular expression
+func TermE(generate string, matcher string) (Matcher, error) {
+	var err error
+	if generate == "" {
+		generate, err = generateRegexExample(matcher)
+	}
+
 	return term{
 		Data: termData{
 			Generate: generate,
@@ -126,7 +199,7 @@ func Term(generate string, matcher string) Matcher {
 				Regex: matcher,
 			},
 		},
-	}
+	}, err
 }
 
 // HexValue defines a matcher that accepts hexadecimal values.
@@ -150,9 +223,21 @@ func IPAddress() Matcher {
 // IPv4Address matches valid IPv4 addresses.
 var IPv4Address = IPAddress
 
-// IPv6Address defines a matcher that accepts IP addresses.
+// IPv6Address defines a matcher that accepts IPv6 addresses, including the
+// compressed and IPv4 mapped forms.
 func IPv6Address() Matcher {
-	return Regex("::ffff:192.0.2.128", ipAddress)
+	return Regex("::ffff:192.0.2.128", "^"+ipv6Address+"$")
+}
+
+// IPAddressAny defines a matcher that accepts IPv4 or IPv6 addresses.
+func IPAddressAny() Matcher {
+	return Regex("127.0.0.1", "^("+ipv4Address+"|"+ipv6Address+")$")
+}
+
+// CIDR defines a matcher that accepts IPv4 or IPv6 CIDR blocks, e.g.
+// "192.168.0.0/24" or "2001:db8::/32".
+func CIDR() Matcher {
+	return Regex("192.168.0.0/24", "^("+ipv4Address+`/(3[0-2]|[12]?[0-9])|`+ipv6Address+`/(12[0-8]|1[01][0-9]|[1-9]?[0-9]))$`)
 }
 
 // Decimal defines a matcher that accepts any decimal value.
@@ -166,6 +251,62 @@ func Timestamp() Matcher {
 	return Regex(timeExample.Format(time.RFC3339), timestamp)
 }
 
+// TimestampUTC matches RFC3339 timestamps in UTC, i.e. ending in "Z", e.g.
+// "2000-02-01T12:30:00Z".
+func TimestampUTC() Matcher {
+	return Regex(timeExample.Format(time.RFC3339), "^"+dateTime+"Z$")
+}
+
+// TimestampWithOffset matches RFC3339 timestamps with an explicit numeric
+// offset, e.g. "2000-02-01T13:30:00+01:00". Timestamps in UTC must then be
+// written with "+00:00" rather than "Z".
+func TimestampWithOffset() Matcher {
+	return Regex(timeExample.In(time.FixedZone("", 60*60)).Format(time.RFC3339), "^"+dateTime+utcOffset+"$")
+}
+
+// RFC1123Timestamp matches RFC1123 timestamps as used by HTTP headers, e.g.
+// "Tue, 01 Feb 2000 12:30:00 GMT", with a zone name or numeric offset. As a
+// response header, e.g. Last-Modified, the mock server generates the current
+// time.
+func RFC1123Timestamp() Matcher {
+	return generated{
+		Matcher:   Regex(timeExample.Format(http.TimeFormat), rfc1123),
+		generator: timeGenerator{regex: rfc1123, format: func(t time.Time) string { return t.UTC().Format(http.TimeFormat) }, pactFormat: "EEE, dd MMM yyyy HH:mm:ss 'GMT'"},
+	}
+}
+
+// EpochSeconds matches Unix timestamps in seconds, sent as integers e.g.
+// 949408200
+func EpochSeconds() Matcher {
+	return Like(timeExample.Unix())
+}
+
+// EpochMillis matches Unix timestamps in milliseconds, sent as integers
+// e.g. 949408200000
+func EpochMillis() Matcher {
+	return Like(timeExample.UnixNano() / int64(time.Millisecond))
+}
+
+// EpochSecondsString matches Unix timestamps in seconds, sent as strings
+// of 9 or 10 digits (from 1973 to 2286). As a response header the mock
+// server generates the current time.
+func EpochSecondsString() Matcher {
+	return generated{
+		Matcher:   Regex(strconv.FormatInt(timeExample.Unix(), 10), epochSecs),
+		generator: timeGenerator{regex: epochSecs, format: func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }},
+	}
+}
+
+// EpochMillisString matches Unix timestamps in milliseconds, sent as strings
+// of 12 or 13 digits (from 1973 to 2286). As a response header the mock
+// server generates the current time.
+func EpochMillisString() Matcher {
+	return generated{
+		Matcher:   Regex(strconv.FormatInt(timeExample.UnixNano()/int64(time.Millisecond), 10), epochMillis),
+		generator: timeGenerator{regex: epochMillis, format: func(t time.Time) string { return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10) }},
+	}
+}
+
 // Date matches a pattern corresponding to the ISO_DATE_FORMAT, which
 // is "yyyy-MM-dd". The current date is used as the eaxmple.
 func Date() Matcher {
@@ -183,6 +324,13 @@ func UUID() Matcher {
 	return Regex("fc763eba-0905-41c5-a27f-3934ab26786c", uuid)
 }
 
+// UnicodeString defines a matcher that accepts any string. Its example mixes
+// accented, non-Latin and emoji characters, to exercise the handling of
+// multibyte text.
+func UnicodeString() Matcher {
+	return Like(unicodeExample)
+}
+
 // Regex is a more appropriately named alias for the "Term" matcher
 var Regex = Term
 
@@ -198,6 +346,10 @@ type Matcher interface {
 	// GetValue returns the raw generated value for the matcher
 	// without any of the matching detail context
 	GetValue() interface{}
+
+	// Describe attaches a human readable description of the field's business
+	// meaning, which is written to the pact file alongside the interaction
+	Describe(description string) Matcher
 }
 
 // S is the string primitive wrapper (alias) for the Matcher type,
@@ -208,6 +360,11 @@ type S string
 
 func (s S) isMatcher() {}
 
+// Describe attaches a description of the field to the matcher
+func (s S) Describe(description string) Matcher {
+	return describe(s, description)
+}
+
 // GetValue returns the raw generated value for the matcher
 // without any of the matching detail context
 func (s S) GetValue() interface{} {
@@ -220,6 +377,11 @@ type String string
 
 func (s String) isMatcher() {}
 
+// Describe attaches a description of the field to the matcher
+func (s String) Describe(description string) Matcher {
+	return describe(s, description)
+}
+
 // GetValue returns the raw generated value for the matcher
 // without any of the matching detail context
 func (s String) GetValue() interface{} {
@@ -232,6 +394,11 @@ type StructMatcher map[string]interface{}
 
 func (m StructMatcher) isMatcher() {}
 
+// Describe attaches a description of the field to the matcher
+func (m StructMatcher) Describe(description string) Matcher {
+	return describe(m, description)
+}
+
 // GetValue returns the raw generated value for the matcher
 // without any of the matching detail context
 func (m StructMatcher) GetValue() interface{} {
@@ -242,6 +409,34 @@ func (m StructMatcher) GetValue() interface{} {
 // to also contain complex matchers
 type MapMatcher map[string]Matcher
 
+// mergeHeaders returns the headers with any defaults not already present
+// added, comparing header names case insensitively
+func mergeHeaders(defaults MapMatcher, headers MapMatcher) MapMatcher {
+	if len(defaults) == 0 {
+		return headers
+	}
+
+	merged := MapMatcher{}
+	for name, value := range headers {
+		merged[name] = value
+	}
+
+	for name, value := range defaults {
+		found := false
+		for existing := range headers {
+			if strings.EqualFold(name, existing) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged[name] = value
+		}
+	}
+
+	return merged
+}
+
 // UnmarshalJSON is a custom JSON parser for MapMatcher
 // It treats the matchers as strings
 func (m *MapMatcher) UnmarshalJSON(bytes []byte) (err error) {
@@ -284,6 +479,12 @@ func objectToString(obj interface{}) string {
 // Supported Tag Formats
 // Minimum Slice Size: `pact:"min=2"`
 // String RegEx:       `pact:"example=2000-01-01,regex=^\\d{4}-\\d{2}-\\d{2}$"`
+//
+// Fake Data:         `pact:"faker=email"`
+//
+// If the example is omitted from a regex tag (e.g. `pact:"regex=^\\d+$"`),
+// one is generated from the regular expression. See UseFakeExamples to
+// generate fake data for string fields based on their name.
 func Match(src interface{}) Matcher {
 	return match(reflect.TypeOf(src), getDefaults())
 }
@@ -305,7 +506,17 @@ func match(srcType reflect.Type, params params) Matcher {
 			if fieldName == "" {
 				continue
 			}
-			result[fieldName] = match(field.Type, pluckParams(field.Type, field.Tag.Get("pact")))
+			fieldParams := pluckParams(field.Type, field.Tag.Get("pact"))
+			if field.Tag.Get("pact") == "" && isStringType(field.Type) {
+				if kind := fakerKindForField(fieldName); kind != "" {
+					// Fake examples by field name are best effort, a custom
+					// Faker may not support the kind
+					if example, err := fake(kind); err == nil {
+						fieldParams.str.example = example
+					}
+				}
+			}
+			result[fieldName] = match(field.Type, fieldParams)
 		}
 		return result
 	case reflect.String:
@@ -397,6 +608,7 @@ func getDefaults() params {
 // Supported Tag Formats
 // Minimum Slice Size: `pact:"min=2"`
 // String RegEx:       `pact:"example=2000-01-01,regex=^\\d{4}-\\d{2}-\\d{2}$"`
+// Fake Data:         `pact:"faker=email"`
 func pluckParams(srcType reflect.Type, pactTag string) params {
 	params := getDefaults()
 	if pactTag == "" {
@@ -423,7 +635,25 @@ func pluckParams(srcType reflect.Type, pactTag string) params {
 			triggerInvalidPactTagPanic(pactTag, err)
 		}
 	case reflect.String:
-		if fullRegex.Match([]byte(pactTag)) {
+		if strings.HasPrefix(pactTag, "faker=") {
+			kind := strings.TrimPrefix(pactTag, "faker=")
+
+			if len(kind) == 0 {
+				triggerInvalidPactTagPanic(pactTag, fmt.Errorf("invalid format: faker must not be empty"))
+			}
+
+			example, err := fake(kind)
+			if err != nil {
+				triggerInvalidPactTagPanic(pactTag, err)
+			}
+			params.str.example = example
+		} else if strings.HasPrefix(pactTag, "regex=") {
+			params.str.regEx = strings.TrimPrefix(pactTag, "regex=")
+
+			if len(params.str.regEx) == 0 {
+				triggerInvalidPactTagPanic(pactTag, fmt.Errorf("invalid format: regex must not be empty"))
+			}
+		} else if fullRegex.Match([]byte(pactTag)) {
 			components := strings.Split(pactTag, ",regex=")
 
 			if len(components[1]) == 0 {
@@ -449,6 +679,15 @@ func pluckParams(srcType reflect.Type, pactTag string) params {
 	return params
 }
 
+// isStringType determines if the type is a string, or a pointer to one
+func isStringType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.String
+}
+
 func triggerInvalidPactTagPanic(tag string, err error) {
 	panic(fmt.Sprintf("match: encountered invalid pact tag %q . . . parsing failed with error: %v", tag, err))
 }
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -489,6 +491,149 @@ func TestMatcher_SugarMatchers(t *testing.T) {
 	}
 }
 
+func TestMatcher_TimestampOffsetMatchers(t *testing.T) {
+	cases := []struct {
+		name    string
+		matcher Matcher
+		example string
+		valid   []string
+		invalid []string
+	}{
+		{
+			"TimestampUTC",
+			TimestampUTC(),
+			"2000-02-01T12:30:00Z",
+			[]string{"2021-12-31T23:59:59Z", "2021-01-01T00:00:00.123456Z"},
+			[]string{"2021-01-01T00:00:00+00:00", "2021-01-01T00:00:00", "2021-01-01T00:00:00z", "2021-13-01T00:00:00Z", "2021-01-01 00:00:00Z"},
+		},
+		{
+			"TimestampWithOffset",
+			TimestampWithOffset(),
+			"2000-02-01T13:30:00+01:00",
+			[]string{"2021-01-01T00:00:00+00:00", "2021-06-30T08:15:00.5-07:00", "2021-01-01T00:00:00+05:45"},
+			[]string{"2021-01-01T00:00:00Z", "2021-01-01T00:00:00", "2021-01-01T00:00:00+0100", "2021-01-01T00:00:00+24:00"},
+		},
+	}
+
+	for _, c := range cases {
+		pattern := regexp.MustCompile(c.matcher.(term).Data.Matcher.Regex.(string))
+		if example := c.matcher.GetValue().(string); example != c.example || !pattern.MatchString(example) {
+			t.Fatalf("%s: want the matching example '%s', got '%s'", c.name, c.example, example)
+		}
+		for _, timestamp := range c.valid {
+			if !pattern.MatchString(timestamp) {
+				t.Errorf("%s: expected '%s' to match", c.name, timestamp)
+			}
+		}
+		for _, timestamp := range c.invalid {
+			if pattern.MatchString(timestamp) {
+				t.Errorf("%s: expected '%s' not to match", c.name, timestamp)
+			}
+		}
+	}
+}
+
+func TestMatcher_TimeFormatMatchers(t *testing.T) {
+	cases := []struct {
+		name    string
+		matcher Matcher
+		example string
+		valid   []string
+		invalid []string
+	}{
+		{
+			"RFC1123Timestamp",
+			RFC1123Timestamp(),
+			"Tue, 01 Feb 2000 12:30:00 GMT",
+			[]string{"Sun, 06 Nov 1994 08:49:37 GMT", "Mon, 02 Jan 2006 15:04:05 MST", "Mon, 02 Jan 2006 15:04:05 -0700"},
+			[]string{"Sunday, 06-Nov-94 08:49:37 GMT", "Sun Nov  6 08:49:37 1994", "Sun, 6 Nov 1994 08:49:37 GMT", "2000-02-01T12:30:00Z"},
+		},
+		{
+			"EpochSecondsString",
+			EpochSecondsString(),
+			"949408200",
+			[]string{"1614830767", "100000000"},
+			[]string{"1614830767008", "12345", "-1614830767", "1614830767.5"},
+		},
+		{
+			"EpochMillisString",
+			EpochMillisString(),
+			"949408200000",
+			[]string{"1614830767008", "100000000000"},
+			[]string{"1614830767", "16148307670080", "1614830767.008"},
+		},
+	}
+
+	for _, c := range cases {
+		pattern := regexp.MustCompile(unwrapMatcher(c.matcher).(term).Data.Matcher.Regex.(string))
+		if example := c.matcher.GetValue().(string); example != c.example || !pattern.MatchString(example) {
+			t.Fatalf("%s: want the matching example '%s', got '%s'", c.name, c.example, example)
+		}
+		for _, value := range c.valid {
+			if !pattern.MatchString(value) {
+				t.Errorf("%s: expected '%s' to match", c.name, value)
+			}
+		}
+		for _, value := range c.invalid {
+			if pattern.MatchString(value) {
+				t.Errorf("%s: expected '%s' not to match", c.name, value)
+			}
+		}
+	}
+
+	if EpochSeconds().GetValue() != int64(949408200) || EpochMillis().GetValue() != int64(949408200000) {
+		t.Fatalf("expected integer examples, got %v and %v", EpochSeconds().GetValue(), EpochMillis().GetValue())
+	}
+}
+
+func TestMatcher_IPAddressMatchers(t *testing.T) {
+	cases := []struct {
+		name    string
+		matcher Matcher
+		valid   []string
+		invalid []string
+	}{
+		{
+			"IPv6Address",
+			IPv6Address(),
+			[]string{"2001:0db8:85a3:0000:0000:8a2e:0370:7334", "2001:db8::1", "::1", "::", "fe80::", "1::8", "1:2:3:4:5:6:7::", "::ffff:192.0.2.128", "::192.0.2.128", "64:ff9b::192.0.2.33", "0:0:0:0:0:ffff:192.0.2.128"},
+			[]string{"127.0.0.1", "2001:db8::1::2", "1:2:3:4:5:6:7:8:9", "2001:db8:::1", "12345::1", "::ffff:192.0.2.256", "1:2:3:4:5:6::1.2.3.4", "g::1", ""},
+		},
+		{
+			"IPAddressAny",
+			IPAddressAny(),
+			[]string{"127.0.0.1", "255.255.255.255", "2001:db8::1", "::ffff:10.0.0.1"},
+			[]string{"256.0.0.1", "1.2.3", "1.2.3.4.5", "localhost", "2001:db8::1/64"},
+		},
+		{
+			"CIDR",
+			CIDR(),
+			[]string{"192.168.0.0/24", "0.0.0.0/0", "10.0.0.1/32", "2001:db8::/32", "::/0", "::ffff:10.0.0.0/128"},
+			[]string{"192.168.0.0", "192.168.0.0/33", "2001:db8::/129", "2001:db8::/", "10.0.0.0/024a"},
+		},
+	}
+
+	for _, c := range cases {
+		pattern := regexp.MustCompile(c.matcher.(term).Data.Matcher.Regex.(string))
+		if example := c.matcher.GetValue().(string); !pattern.MatchString(example) {
+			t.Fatalf("%s: expected the example '%s' to match", c.name, example)
+		}
+		for _, address := range c.valid {
+			if !pattern.MatchString(address) {
+				t.Errorf("%s: expected '%s' to match", c.name, address)
+			}
+			if ip := strings.Split(address, "/")[0]; net.ParseIP(ip) == nil {
+				t.Errorf("%s: '%s' is not a valid address", c.name, ip)
+			}
+		}
+		for _, address := range c.invalid {
+			if pattern.MatchString(address) {
+				t.Errorf("%s: expected '%s' not to match", c.name, address)
+			}
+		}
+	}
+}
+
 func ExampleLike_string() {
 	match := Like("myspecialvalue")
 	fmt.Println(formatJSON(match))
@@ -966,11 +1111,26 @@ func Test_pluckParams(t *testing.T) {
 			wantPanic: true,
 		},
 		{
-			name: "invalid string tag - no example",
+			name: "expected use - regex with no example",
 			args: args{
 				srcType: reflect.TypeOf(""),
 				pactTag: "regex=[A-Za-z0-9]",
 			},
+			want: params{
+				slice: sliceParams{
+					min: getDefaults().slice.min,
+				},
+				str: stringParams{
+					regEx: "[A-Za-z0-9]",
+				},
+			},
+		},
+		{
+			name: "invalid string tag - empty regex with no example",
+			args: args{
+				srcType: reflect.TypeOf(""),
+				pactTag: "regex=",
+			},
 			wantPanic: true,
 		},
 		{
@@ -1024,3 +1184,36 @@ func Test_pluckParams(t *testing.T) {
 		})
 	}
 }
+
+func TestMatcher_mergeHeaders(t *testing.T) {
+	defaults := MapMatcher{
+		"Authorization": Term("Bearer 1234", "^Bearer .+$"),
+		"Content-Type":  String("application/json"),
+	}
+	headers := MapMatcher{
+		"content-type": String("text/csv"),
+		"X-Request-Id": Like("1"),
+	}
+
+	merged := mergeHeaders(defaults, headers)
+
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 headers, got %v", merged)
+	}
+	if merged["content-type"] != String("text/csv") {
+		t.Fatalf("expected interaction headers to take precedence, got %v", merged)
+	}
+	if _, ok := merged["Content-Type"]; ok {
+		t.Fatalf("expected header names to be compared case insensitively, got %v", merged)
+	}
+	if merged["Authorization"] == nil {
+		t.Fatalf("expected the default header to be added, got %v", merged)
+	}
+	if len(headers) != 2 {
+		t.Fatalf("expected the interaction headers not to be modified, got %v", headers)
+	}
+
+	if mergeHeaders(nil, headers)["X-Request-Id"] == nil {
+		t.Fatal("expected headers to be returned without defaults")
+	}
+}
@@ -0,0 +1,91 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Rule is a single matching rule, as written to a pact file
+// e.g. {"match": "type", "min": 1}
+type Rule map[string]interface{}
+
+// TypeRule matches values by type rather than by value
+func TypeRule() Rule {
+	return Rule{"match": "type"}
+}
+
+// MinTypeRule matches arrays by type, requiring at least min elements
+func MinTypeRule(min int) Rule {
+	return Rule{"match": "type", "min": min}
+}
+
+// MaxTypeRule matches arrays by type, allowing at most max elements
+func MaxTypeRule(max int) Rule {
+	return Rule{"match": "type", "max": max}
+}
+
+// RegexRule matches string values against the given regular expression
+func RegexRule(regex string) Rule {
+	return Rule{"match": "regex", "regex": regex}
+}
+
+// MatchingRules maps matching rule path expressions (see RulePath) to the
+// rule applied to the values at that path. They may be added to a Request or
+// Response alongside (or instead of) matchers in the body.
+//
+// Paths are relative to the request or response e.g. "$.body.id",
+// "$.headers.Authorization" or "$.query.page".
+type MatchingRules map[string]Rule
+
+// Add adds a rule at the given path
+func (m MatchingRules) Add(path RulePath, rule Rule) MatchingRules {
+	m[path.String()] = rule
+	return m
+}
+
+// validate checks that every path is well formed and refers to one of
+// the given top level categories (e.g. "body", "headers")
+func (m MatchingRules) validate(categories ...string) error {
+	paths := make([]string, 0, len(m))
+	for path := range m {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		parsed, err := ParseRulePath(path)
+		if err != nil {
+			return err
+		}
+
+		if !parsed.hasCategory(categories...) {
+			return fmt.Errorf("invalid matching rule path %q: must start with one of %v", path, prefixPaths(categories))
+		}
+	}
+
+	return nil
+}
+
+// hasCategory checks if the first element of the path is one of the given keys
+func (p RulePath) hasCategory(categories ...string) bool {
+	if len(p.tokens) == 0 || p.tokens[0].kind != keyToken {
+		return false
+	}
+
+	for _, c := range categories {
+		if p.tokens[0].key == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+func prefixPaths(categories []string) []string {
+	prefixed := make([]string, len(categories))
+	for i, c := range categories {
+		prefixed[i] = NewRulePath().Key(c).String()
+	}
+
+	return prefixed
+}
@@ -0,0 +1,267 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MatrixQuery selects the rows of the broker's matrix: the pacts between
+// the selected pacticipant versions, and their verification results
+type MatrixQuery struct {
+	// Selectors select the pacticipant versions. At least one is required.
+	Selectors []MatrixSelector
+
+	// LatestBy limits the rows to the latest verification of each consumer
+	// version ("cvp"), or of each consumer and provider version ("cvpv").
+	// Optional.
+	LatestBy string
+
+	// Environment asks whether the selected versions can be deployed to the
+	// environment, with the versions deployed there. Optional.
+	Environment string
+
+	// Limit is the most rows returned. Optional.
+	Limit int
+}
+
+// MatrixSelector selects versions of a pacticipant
+type MatrixSelector struct {
+	Pacticipant string
+
+	// Version selects a specific version. Optional.
+	Version string
+
+	// Latest selects the latest version, or with Tag or Branch the latest
+	// version with it
+	Latest bool
+
+	// Tag and Branch select the versions with the tag or on the branch, and
+	// MainBranch those on the pacticipant's main branch. Optional.
+	Tag        string
+	Branch     string
+	MainBranch bool
+
+	// Environment selects the versions deployed or released to the
+	// environment. Optional.
+	Environment string
+}
+
+// Matrix is the broker's matrix of pacts and verification results
+type Matrix struct {
+	Summary MatrixSummary
+
+	// Notices are the broker's messages about the query
+	Notices []string
+
+	Rows []MatrixRow
+}
+
+// MatrixSummary summarises the verification results of the rows, and
+// whether the selected versions can be deployed
+type MatrixSummary struct {
+	// Deployable is unset if the broker couldn't decide, e.g. for a query
+	// without an environment
+	Deployable *bool
+	Reason     string
+
+	Success int
+	Failed  int
+	Unknown int
+}
+
+// MatrixRow is the pact between a consumer version and provider version,
+// and its verification result
+type MatrixRow struct {
+	Consumer MatrixVersion
+	Provider MatrixVersion
+
+	// PactURL and PactCreatedAt describe the pact
+	PactURL       string
+	PactCreatedAt time.Time
+
+	// VerificationResult is nil if the provider version hasn't verified the
+	// pact, or the provider has no version in the row
+	VerificationResult *MatrixVerificationResult
+}
+
+// MatrixVersion is a pacticipant version in the matrix. Version is empty if
+// no version of the provider has verified the pact.
+type MatrixVersion struct {
+	Name    string
+	Version string
+	Branch  string
+	Tags    []string
+
+	// Environments are those the version is deployed or released to
+	Environments []string
+}
+
+// MatrixVerificationResult is a provider version's verification of a pact
+type MatrixVerificationResult struct {
+	Success    bool
+	VerifiedAt time.Time
+	URL        string
+}
+
+// matrixVersion is a pacticipant version in the broker's matrix response
+type matrixVersion struct {
+	Name    string `json:"name"`
+	Version *struct {
+		Number string `json:"number"`
+		Branch string `json:"branch"`
+		Tags   []struct {
+			Name string `json:"name"`
+		} `json:"tags"`
+		Environments []struct {
+			Name string `json:"name"`
+		} `json:"environments"`
+	} `json:"version"`
+}
+
+type matrixLinks struct {
+	Self struct {
+		Href string `json:"href"`
+	} `json:"self"`
+}
+
+// matrixResponse is the broker's matrix response
+type matrixResponse struct {
+	Summary struct {
+		Deployable *bool  `json:"deployable"`
+		Reason     string `json:"reason"`
+		Success    int    `json:"success"`
+		Failed     int    `json:"failed"`
+		Unknown    int    `json:"unknown"`
+	} `json:"summary"`
+	Notices []struct {
+		Text string `json:"text"`
+	} `json:"notices"`
+	Matrix []struct {
+		Consumer matrixVersion `json:"consumer"`
+		Provider matrixVersion `json:"provider"`
+		Pact     struct {
+			CreatedAt time.Time   `json:"createdAt"`
+			Links     matrixLinks `json:"_links"`
+		} `json:"pact"`
+		VerificationResult *struct {
+			Success    bool        `json:"success"`
+			VerifiedAt time.Time   `json:"verifiedAt"`
+			Links      matrixLinks `json:"_links"`
+		} `json:"verificationResult"`
+	} `json:"matrix"`
+}
+
+// Matrix queries the broker's matrix
+func (b *Broker) Matrix(ctx context.Context, query MatrixQuery) (Matrix, error) {
+	encoded, err := query.encode()
+	if err != nil {
+		return Matrix{}, err
+	}
+	content, err := brokerRequest(ctx, "GET", b.url("matrix")+"?"+encoded, nil, b.auth())
+	if err != nil {
+		return Matrix{}, fmt.Errorf("unable to query the broker's matrix: %v", err)
+	}
+
+	var response matrixResponse
+	if err = json.Unmarshal(content, &response); err != nil {
+		return Matrix{}, fmt.Errorf("unable to query the broker's matrix: invalid response: %v", err)
+	}
+
+	m := Matrix{Summary: MatrixSummary{
+		Deployable: response.Summary.Deployable,
+		Reason:     response.Summary.Reason,
+		Success:    response.Summary.Success,
+		Failed:     response.Summary.Failed,
+		Unknown:    response.Summary.Unknown,
+	}}
+	for _, notice := range response.Notices {
+		m.Notices = append(m.Notices, notice.Text)
+	}
+	for _, row := range response.Matrix {
+		r := MatrixRow{
+			Consumer:      row.Consumer.version(),
+			Provider:      row.Provider.version(),
+			PactURL:       row.Pact.Links.Self.Href,
+			PactCreatedAt: row.Pact.CreatedAt,
+		}
+		if v := row.VerificationResult; v != nil {
+			r.VerificationResult = &MatrixVerificationResult{Success: v.Success, VerifiedAt: v.VerifiedAt, URL: v.Links.Self.Href}
+		}
+		m.Rows = append(m.Rows, r)
+	}
+
+	return m, nil
+}
+
+func (v matrixVersion) version() MatrixVersion {
+	version := MatrixVersion{Name: v.Name}
+	if v.Version == nil {
+		return version
+	}
+
+	version.Version = v.Version.Number
+	version.Branch = v.Version.Branch
+	for _, tag := range v.Version.Tags {
+		version.Tags = append(version.Tags, tag.Name)
+	}
+	for _, environment := range v.Version.Environments {
+		version.Environments = append(version.Environments, environment.Name)
+	}
+
+	return version
+}
+
+// encode encodes the query. The parameters of each selector are kept
+// together, as the broker groups the q[] parameters by their order.
+func (q MatrixQuery) encode() (string, error) {
+	if len(q.Selectors) == 0 {
+		return "", errors.New("at least one selector must be given to query the broker's matrix")
+	}
+
+	var params []string
+	add := func(key string, value string) {
+		params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+	}
+	if q.Environment != "" {
+		add("environment", q.Environment)
+	}
+	if q.LatestBy != "" {
+		add("latestby", q.LatestBy)
+	}
+	if q.Limit > 0 {
+		add("limit", strconv.Itoa(q.Limit))
+	}
+
+	for _, s := range q.Selectors {
+		if s.Pacticipant == "" {
+			return "", errors.New("a matrix selector must have a pacticipant")
+		}
+		add("q[][pacticipant]", s.Pacticipant)
+		if s.Version != "" {
+			add("q[][version]", s.Version)
+		}
+		if s.Latest {
+			add("q[][latest]", "true")
+		}
+		if s.Branch != "" {
+			add("q[][branch]", s.Branch)
+		}
+		if s.MainBranch {
+			add("q[][mainBranch]", "true")
+		}
+		if s.Tag != "" {
+			add("q[][tag]", s.Tag)
+		}
+		if s.Environment != "" {
+			add("q[][environment]", s.Environment)
+		}
+	}
+
+	return strings.Join(params, "&"), nil
+}
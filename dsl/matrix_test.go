@@ -0,0 +1,99 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBroker_Matrix(t *testing.T) {
+	var query string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, _ = url.QueryUnescape(r.URL.RawQuery)
+		fmt.Fprint(w, `{
+			"summary": {"deployable": null, "reason": "", "success": 1, "failed": 0, "unknown": 1},
+			"notices": [{"type": "info", "text": "No environment given"}],
+			"matrix": [
+				{
+					"consumer": {"name": "billing", "version": {"number": "1.0.0", "branch": "main", "tags": [{"name": "prod"}], "environments": [{"uuid": "p1", "name": "production"}]}},
+					"provider": {"name": "accounts", "version": {"number": "3.0.0", "branch": "main", "tags": [], "environments": []}},
+					"pact": {"createdAt": "2021-03-04T05:06:07+00:00", "_links": {"self": {"href": "http://broker/pacts/1"}}},
+					"verificationResult": {"success": true, "verifiedAt": "2021-03-05T05:06:07+00:00", "_links": {"self": {"href": "http://broker/verifications/1"}}}
+				},
+				{
+					"consumer": {"name": "billing", "version": {"number": "1.1.0"}},
+					"provider": {"name": "accounts", "version": null},
+					"pact": {"createdAt": "2021-03-06T05:06:07+00:00", "_links": {"self": {"href": "http://broker/pacts/2"}}},
+					"verificationResult": null
+				}
+			]
+		}`)
+	}))
+	defer s.Close()
+
+	m, err := (&Broker{URL: s.URL}).Matrix(context.Background(), MatrixQuery{
+		Selectors: []MatrixSelector{
+			{Pacticipant: "billing", Branch: "main", Latest: true},
+			{Pacticipant: "accounts", Environment: "production"},
+		},
+		LatestBy: "cvpv",
+		Limit:    100,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantQuery := "latestby=cvpv&limit=100&q[][pacticipant]=billing&q[][latest]=true&q[][branch]=main&q[][pacticipant]=accounts&q[][environment]=production"
+	if query != wantQuery {
+		t.Fatalf("want the query %s, got %s", wantQuery, query)
+	}
+
+	want := Matrix{
+		Summary: MatrixSummary{Success: 1, Unknown: 1},
+		Notices: []string{"No environment given"},
+		Rows: []MatrixRow{
+			{
+				Consumer:      MatrixVersion{Name: "billing", Version: "1.0.0", Branch: "main", Tags: []string{"prod"}, Environments: []string{"production"}},
+				Provider:      MatrixVersion{Name: "accounts", Version: "3.0.0", Branch: "main"},
+				PactURL:       "http://broker/pacts/1",
+				PactCreatedAt: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+				VerificationResult: &MatrixVerificationResult{
+					Success:    true,
+					VerifiedAt: time.Date(2021, 3, 5, 5, 6, 7, 0, time.UTC),
+					URL:        "http://broker/verifications/1",
+				},
+			},
+			{
+				Consumer:      MatrixVersion{Name: "billing", Version: "1.1.0"},
+				Provider:      MatrixVersion{Name: "accounts"},
+				PactURL:       "http://broker/pacts/2",
+				PactCreatedAt: time.Date(2021, 3, 6, 5, 6, 7, 0, time.UTC),
+			},
+		},
+	}
+	// Compare the times in UTC, rather than the broker's +00:00 offset
+	for i, row := range m.Rows {
+		m.Rows[i].PactCreatedAt = row.PactCreatedAt.UTC()
+		if row.VerificationResult != nil {
+			row.VerificationResult.VerifiedAt = row.VerificationResult.VerifiedAt.UTC()
+		}
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("want %+v, got %+v", want, m)
+	}
+}
+
+func TestMatrixQuery_Errors(t *testing.T) {
+	if _, err := (&Broker{URL: "http://broker"}).Matrix(context.Background(), MatrixQuery{}); err == nil || !strings.Contains(err.Error(), "at least one selector") {
+		t.Fatalf("expected a selector to be required, got %v", err)
+	}
+	if _, err := (MatrixQuery{Selectors: []MatrixSelector{{Version: "1.0.0"}}}).encode(); err == nil || !strings.Contains(err.Error(), "must have a pacticipant") {
+		t.Fatalf("expected a pacticipant to be required, got %v", err)
+	}
+}
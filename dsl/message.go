@@ -19,6 +19,14 @@ type MessageHandler func(Message) (interface{}, error)
 // MessageHandlers is a list of handlers ordered by description
 type MessageHandlers map[string]MessageHandler
 
+// MessageProducer is a provider function that produces the contents and
+// metadata of a message, for native verification (see
+// VerifyMessageProviderNative)
+type MessageProducer func(Message) (contents interface{}, metadata map[string]interface{}, err error)
+
+// MessageProducers is a list of producers ordered by description
+type MessageProducers map[string]MessageProducer
+
 // MessageConsumer receives a message and must be able to parse
 // the content
 type MessageConsumer func(Message) error
@@ -47,6 +55,12 @@ type Message struct {
 	Type interface{}
 
 	Args []string `json:"-"`
+
+	// contentType of the message, if given with WithContentType
+	contentType string
+
+	// err records a problem building the message, reported on verification
+	err error
 }
 
 // State specifies how the system should be configured when
@@ -87,6 +101,33 @@ func (p *Message) WithContent(content interface{}) *Message {
 	return p
 }
 
+// WithContentType specifies the content of the message and its content type,
+// which is added to the message metadata. Content is encoded with the handler
+// registered for the content type (see RegisterContentHandler), so Go structs
+// may be given for formats such as protobuf. JSON content may contain matchers.
+func (p *Message) WithContentType(contentType string, content interface{}) *Message {
+	encoded, err := EncodeContent(contentType, content)
+	if err != nil {
+		p.err = err
+		return p
+	}
+
+	p.Content = encoded
+	p.contentType = contentType
+	if p.Metadata == nil {
+		p.Metadata = MapMatcher{}
+	}
+	p.Metadata["contentType"] = String(contentType)
+
+	// Non-JSON content can't contain matchers, so is decoded back into its
+	// own type by default
+	if _, ok := encoded.(string); ok && p.Type == nil {
+		p.Type = content
+	}
+
+	return p
+}
+
 // AsType specifies that the content sent through to the
 // consumer handler should be sent as the given type
 func (p *Message) AsType(t interface{}) *Message {
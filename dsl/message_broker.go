@@ -0,0 +1,62 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// MessageBroker is an adapter to a real message broker, such as a test
+// Kafka or NATS topic. When given to a message verification, each message
+// produced is published to, and consumed back from, the broker before it is
+// verified, so that serialisation round trips are validated as well as the
+// content of the message.
+type MessageBroker interface {
+	// Publish sends the serialised message to the broker
+	Publish(description string, content []byte) error
+
+	// Consume receives the message published for the description
+	Consume(description string) ([]byte, error)
+}
+
+// brokerMessageHandlers wraps the message handlers so that the messages they
+// produce are round tripped through the broker
+func brokerMessageHandlers(handlers MessageHandlers, broker MessageBroker) MessageHandlers {
+	wrapped := make(MessageHandlers, len(handlers))
+
+	for description, handler := range handlers {
+		description, handler := description, handler
+		wrapped[description] = func(m Message) (interface{}, error) {
+			res, err := handler(m)
+			if err != nil {
+				return nil, err
+			}
+
+			content, err := json.Marshal(res)
+			if err != nil {
+				return nil, fmt.Errorf("unable to serialise message '%s': %v", description, err)
+			}
+
+			if err = broker.Publish(description, content); err != nil {
+				log.Printf("[ERROR] unable to publish message '%s' to the broker: %v", description, err)
+				return nil, err
+			}
+
+			received, err := broker.Consume(description)
+			if err != nil {
+				log.Printf("[ERROR] unable to consume message '%s' from the broker: %v", description, err)
+				return nil, err
+			}
+
+			if !json.Valid(received) {
+				err = fmt.Errorf("message '%s' consumed from the broker is not valid JSON: %s", description, received)
+				log.Println("[ERROR]", err)
+				return nil, err
+			}
+
+			return json.RawMessage(received), nil
+		}
+	}
+
+	return wrapped
+}
@@ -0,0 +1,88 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeMessageBroker struct {
+	topic      map[string][]byte
+	transform  func([]byte) []byte
+	publishErr error
+}
+
+func (b *fakeMessageBroker) Publish(description string, content []byte) error {
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	if b.transform != nil {
+		content = b.transform(content)
+	}
+	b.topic[description] = content
+	return nil
+}
+
+func (b *fakeMessageBroker) Consume(description string) ([]byte, error) {
+	content, ok := b.topic[description]
+	if !ok {
+		return nil, errors.New("no message")
+	}
+	return content, nil
+}
+
+func TestBrokerMessageHandlers(t *testing.T) {
+	type order struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+	}
+	handlers := MessageHandlers{
+		"an order created event": func(m Message) (interface{}, error) {
+			return order{ID: 1, Status: "created"}, nil
+		},
+	}
+
+	broker := &fakeMessageBroker{topic: map[string][]byte{}}
+	res, err := brokerMessageHandlers(handlers, broker)["an order created event"](Message{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(broker.topic["an order created event"]) != `{"id":1,"status":"created"}` {
+		t.Fatalf("expected the message to be published, got %s", broker.topic["an order created event"])
+	}
+
+	content, _ := json.Marshal(map[string]interface{}{"contents": res})
+	if string(content) != `{"contents":{"id":1,"status":"created"}}` {
+		t.Fatalf("expected the consumed message to be verified, got %s", content)
+	}
+}
+
+func TestBrokerMessageHandlers_Errors(t *testing.T) {
+	handlers := MessageHandlers{
+		"an event": func(m Message) (interface{}, error) {
+			return map[string]string{"id": "1"}, nil
+		},
+	}
+
+	tests := map[string]*fakeMessageBroker{
+		"publish fails": {topic: map[string][]byte{}, publishErr: errors.New("broker unavailable")},
+		"corrupted":     {topic: map[string][]byte{}, transform: func(b []byte) []byte { return b[1:] }},
+	}
+
+	for name, broker := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := brokerMessageHandlers(handlers, broker)["an event"](Message{})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	_, err := brokerMessageHandlers(MessageHandlers{
+		"an event": func(m Message) (interface{}, error) { return nil, errors.New("boom") },
+	}, &fakeMessageBroker{topic: map[string][]byte{}})["an event"](Message{})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+}
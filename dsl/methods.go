@@ -0,0 +1,66 @@
+package dsl
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MergePatchContentType is the content type of a JSON merge patch
+	// (RFC 7396), a partial document replacing the fields it contains
+	MergePatchContentType = "application/merge-patch+json"
+
+	// JSONPatchContentType is the content type of a JSON patch (RFC 6902),
+	// a list of operations to apply to a document
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// HeadRequest is a HEAD request for the path. Neither the request nor the
+// response of a HEAD interaction have a body, though the response may have
+// the headers (e.g. Content-Type and Content-Length) of the GET response.
+func HeadRequest(path Matcher) Request {
+	return Request{Method: http.MethodHead, Path: path, Body: NoBody}
+}
+
+// OptionsRequest is an OPTIONS request for the path, e.g. to discover the
+// methods it allows (see AllowHeader)
+func OptionsRequest(path Matcher) Request {
+	return Request{Method: http.MethodOptions, Path: path, Body: NoBody}
+}
+
+// PatchRequest is a PATCH request for the path, with a body of the content
+// type e.g. MergePatchContentType or JSONPatchContentType
+func PatchRequest(path Matcher, contentType string, body interface{}) Request {
+	return Request{
+		Method:  http.MethodPatch,
+		Path:    path,
+		Headers: MapMatcher{"Content-Type": String(contentType)},
+		Body:    body,
+	}
+}
+
+// AllowHeader matches an Allow header listing exactly the methods, in any
+// order, e.g. AllowHeader("GET", "HEAD", "OPTIONS") matches
+// "OPTIONS, GET, HEAD". The methods, separated by ", ", are the example.
+func AllowHeader(methods ...string) Matcher {
+	quoted := make([]string, len(methods))
+	for n, method := range methods {
+		quoted[n] = regexp.QuoteMeta(strings.ToUpper(method))
+	}
+	method := "(" + strings.Join(quoted, "|") + ")"
+
+	regex := fmt.Sprintf(`^\s*%s\s*$`, method)
+	if len(methods) > 1 {
+		regex = fmt.Sprintf(`^\s*%s(\s*,\s*%s){%d}\s*$`, method, method, len(methods)-1)
+	}
+
+	return Term(strings.ToUpper(strings.Join(methods, ", ")), regex)
+}
+
+// isHead determines if the interaction is for a HEAD request, whose response
+// has no body
+func (i *Interaction) isHead() bool {
+	return strings.EqualFold(i.Request.Method, http.MethodHead)
+}
@@ -0,0 +1,70 @@
+package dsl
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAllowHeader(t *testing.T) {
+	matcher := AllowHeader("get", "HEAD", "OPTIONS").(term)
+	if matcher.Data.Generate != "GET, HEAD, OPTIONS" {
+		t.Fatalf("unexpected example %v", matcher.Data.Generate)
+	}
+
+	regex := regexp.MustCompile(matcher.Data.Matcher.Regex.(string))
+	for value, want := range map[string]bool{
+		"GET, HEAD, OPTIONS":  true,
+		"OPTIONS,GET , HEAD":  true,
+		"GET, HEAD":           false,
+		"GET, HEAD, DELETE":   false,
+		"GET, HEAD, OPTIONS,": false,
+	} {
+		if got := regex.MatchString(value); got != want {
+			t.Fatalf("%q: want match %v, got %v", value, want, got)
+		}
+	}
+
+	single := AllowHeader("GET").(term)
+	if !regexp.MustCompile(single.Data.Matcher.Regex.(string)).MatchString(" GET ") {
+		t.Fatalf("expected a single method to match, got %v", single.Data.Matcher.Regex)
+	}
+}
+
+func TestInteraction_ValidateHead(t *testing.T) {
+	interaction := &Interaction{explicitBodies: true}
+	interaction.
+		UponReceiving("a HEAD request for user 1").
+		WithRequest(HeadRequest(String("/users/1"))).
+		WillRespondWith(Response{Status: 200, Headers: MapMatcher{"Content-Type": String("application/json")}})
+
+	if err := interaction.Validate(); err != nil {
+		t.Fatalf("expected a HEAD response to need no body, got %v", err)
+	}
+
+	interaction.Response.Body = NoBody
+	if err := interaction.Validate(); err != nil {
+		t.Fatalf("expected a HEAD response with NoBody to allow a Content-Type, got %v", err)
+	}
+	if sides := interaction.emptyBodySides(); strings.Join(sides, ",") != "request" {
+		t.Fatalf("expected the HEAD response to be recorded without a body, got %v", sides)
+	}
+
+	interaction.Response.Body = map[string]interface{}{"id": 1}
+	err := interaction.Validate()
+	if err == nil || !strings.Contains(err.Error(), "a HEAD response should not have a body") {
+		t.Fatalf("expected an error for the HEAD response body, got %v", err)
+	}
+}
+
+func TestPatchRequest(t *testing.T) {
+	request := PatchRequest(String("/users/1"), MergePatchContentType, map[string]interface{}{"name": "mary"})
+	if request.Method != "PATCH" || request.Headers["Content-Type"] != String("application/merge-patch+json") {
+		t.Fatalf("unexpected request %+v", request)
+	}
+
+	options := OptionsRequest(String("/users"))
+	if options.Method != "OPTIONS" || options.Body != NoBody {
+		t.Fatalf("unexpected request %+v", options)
+	}
+}
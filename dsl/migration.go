@@ -0,0 +1,155 @@
+package dsl
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// modulePath is the import path of pact-go, whose own frames are skipped when
+// finding the caller of a deprecated API
+const modulePath = "github.com/pact-foundation/pact-go/"
+
+// DeprecatedCall is the use of a deprecated API from a file, recorded so
+// that a migration report can list what is left to migrate
+type DeprecatedCall struct {
+	// API is the deprecated function or field e.g.
+	// "types.VerifyRequest.Verbose", and Replacement what to use instead
+	API         string `json:"api"`
+	Replacement string `json:"replacement"`
+
+	// File and Line are the first call from outside pact-go, typically a
+	// test file
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	// Calls counts the calls from the file
+	Calls int `json:"calls"`
+}
+
+var (
+	deprecatedMutex sync.Mutex
+	deprecatedCalls = map[string]*DeprecatedCall{}
+)
+
+// deprecated records a use of a deprecated API, which keeps working. The
+// first use from each file is logged as a warning.
+func deprecated(api string, replacement string) {
+	file, line := deprecatedCaller()
+	key := api + "\x00" + file
+
+	deprecatedMutex.Lock()
+	defer deprecatedMutex.Unlock()
+
+	if call, ok := deprecatedCalls[key]; ok {
+		call.Calls++
+		return
+	}
+	deprecatedCalls[key] = &DeprecatedCall{API: api, Replacement: replacement, File: file, Line: line, Calls: 1}
+	log.Printf("[WARN] %s:%d: %s is deprecated, use %s instead\n", filepath.Base(file), line, api, replacement)
+}
+
+// deprecatedCaller finds the first caller outside of pact-go, treating the
+// examples and test files as callers
+func deprecatedCaller() (string, int) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, modulePath) && !strings.HasPrefix(frame.Function, modulePath+"examples/")
+		if !internal || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return "unknown", 0
+		}
+	}
+}
+
+// DeprecatedCalls lists the uses of deprecated APIs recorded so far, by API
+// then file
+func DeprecatedCalls() []DeprecatedCall {
+	deprecatedMutex.Lock()
+	defer deprecatedMutex.Unlock()
+
+	calls := make([]DeprecatedCall, 0, len(deprecatedCalls))
+	for _, call := range deprecatedCalls {
+		calls = append(calls, *call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].API != calls[j].API {
+			return calls[i].API < calls[j].API
+		}
+		return calls[i].File < calls[j].File
+	})
+
+	return calls
+}
+
+// ResetDeprecatedCalls forgets the recorded uses of deprecated APIs
+func ResetDeprecatedCalls() {
+	deprecatedMutex.Lock()
+	deprecatedCalls = map[string]*DeprecatedCall{}
+	deprecatedMutex.Unlock()
+}
+
+// WriteMigrationReport writes the uses of deprecated APIs recorded so far,
+// grouped by API, e.g. from TestMain after the tests have run:
+//
+//	func TestMain(m *testing.M) {
+//		code := m.Run()
+//		dsl.WriteMigrationReport(os.Stderr)
+//		os.Exit(code)
+//	}
+func WriteMigrationReport(w io.Writer) error {
+	calls := DeprecatedCalls()
+	if len(calls) == 0 {
+		_, err := fmt.Fprintln(w, "No deprecated pact-go APIs were used")
+		return err
+	}
+
+	var b strings.Builder
+	for i, call := range calls {
+		if i == 0 || calls[i-1].API != call.API {
+			fmt.Fprintf(&b, "%s: use %s instead\n", call.API, call.Replacement)
+		}
+		fmt.Fprintf(&b, "\t%s:%d (%d calls)\n", call.File, call.Line, call.Calls)
+	}
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// checkDeprecatedVerifyRequest records the deprecated fields the request
+// sets
+func checkDeprecatedVerifyRequest(request types.VerifyRequest) {
+	if request.Verbose {
+		deprecated("types.VerifyRequest.Verbose", "PactLogLevel")
+	}
+	if request.ProviderStatesSetupURL != "" {
+		deprecated("types.VerifyRequest.ProviderStatesSetupURL", "StateHandlers")
+	}
+	checkDeprecatedSelectors(request.ConsumerVersionSelectors)
+}
+
+// checkDeprecatedSelectors records the deprecated fields of the selectors
+func checkDeprecatedSelectors(selectors []types.ConsumerVersionSelector) {
+	for _, selector := range selectors {
+		if selector.Pacticipant != "" {
+			deprecated("types.ConsumerVersionSelector.Pacticipant", "Consumer")
+		}
+		if selector.All {
+			deprecated("types.ConsumerVersionSelector.All", "a Tag or Branch without Latest")
+		}
+		if selector.Version != "" {
+			deprecated("types.ConsumerVersionSelector.Version", "Branch, MainBranch or DeployedOrReleased")
+		}
+	}
+}
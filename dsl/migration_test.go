@@ -0,0 +1,49 @@
+package dsl
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestDeprecatedCalls(t *testing.T) {
+	ResetDeprecatedCalls()
+	defer ResetDeprecatedCalls()
+
+	request := types.VerifyRequest{
+		Verbose:                  true,
+		ConsumerVersionSelectors: []types.ConsumerVersionSelector{{Pacticipant: "billy", All: true}},
+	}
+	for i := 0; i < 2; i++ {
+		checkDeprecatedVerifyRequest(request)
+	}
+
+	calls := DeprecatedCalls()
+	apis := []string{"types.ConsumerVersionSelector.All", "types.ConsumerVersionSelector.Pacticipant", "types.VerifyRequest.Verbose"}
+	if len(calls) != len(apis) {
+		t.Fatalf("expected a call to each deprecated API, got %+v", calls)
+	}
+	for i, call := range calls {
+		if call.API != apis[i] || filepath.Base(call.File) != "migration_test.go" || call.Line == 0 || call.Calls != 2 {
+			t.Fatalf("expected two calls to %s from this test, got %+v", apis[i], call)
+		}
+	}
+
+	var report bytes.Buffer
+	if err := WriteMigrationReport(&report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(report.String(), "types.VerifyRequest.Verbose: use PactLogLevel instead\n\t") || !strings.Contains(report.String(), "migration_test.go:") {
+		t.Fatalf("expected the report to list each API with its callers, got:\n%s", report.String())
+	}
+
+	ResetDeprecatedCalls()
+	report.Reset()
+	WriteMigrationReport(&report) // nolint:errcheck
+	if len(DeprecatedCalls()) != 0 || report.String() != "No deprecated pact-go APIs were used\n" {
+		t.Fatalf("expected no calls after a reset, got %s", report.String())
+	}
+}
@@ -0,0 +1,177 @@
+package dsl
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// MismatchError is returned by Verify when the mock server did not receive
+// the expected requests. Mismatches lists the requests that were not
+// received, not expected, or matched no interaction. If
+// Pact.RecordMismatches is set, the latter are replaced by the differences
+// between each incorrect request and the interactions it was compared with.
+type MismatchError struct {
+	// Message is the mock server's verification report
+	Message string
+
+	Mismatches []types.InteractionMismatches
+}
+
+func (e *MismatchError) Error() string {
+	return e.Message
+}
+
+// mismatchRecorder records the differences reported by the mock server for
+// requests that matched no interaction
+type mismatchRecorder struct {
+	mu         sync.Mutex
+	mismatches []types.InteractionMismatches
+}
+
+func newMismatchRecorder() *mismatchRecorder {
+	return &mismatchRecorder{}
+}
+
+func (m *mismatchRecorder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		body := recorder.body.Bytes()
+		if recorder.status == http.StatusInternalServerError && bytes.Contains(body, []byte(`"interaction_diffs"`)) {
+			mismatches, err := types.ParseInteractionDiffs(body)
+			if err != nil {
+				log.Println("[WARN] unable to record mismatches:", err)
+			} else {
+				m.mu.Lock()
+				m.mismatches = append(m.mismatches, mismatches...)
+				m.mu.Unlock()
+			}
+		}
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(recorder.status)
+		w.Write(body) // nolint:errcheck
+	})
+}
+
+// record records mismatches found by the proxy itself
+func (m *mismatchRecorder) record(mismatches types.InteractionMismatches) {
+	m.mu.Lock()
+	m.mismatches = append(m.mismatches, mismatches)
+	m.mu.Unlock()
+}
+
+// take returns the recorded mismatches, clearing them
+func (m *mismatchRecorder) take() []types.InteractionMismatches {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mismatches := m.mismatches
+	m.mismatches = nil
+
+	return mismatches
+}
+
+// Sections of the mock service's verification report, see
+// parseVerificationReport
+const (
+	missingRequests    = "Missing requests:"
+	unexpectedRequests = "Unexpected requests:"
+	incorrectRequests  = "Incorrect requests:"
+)
+
+// verificationReportMessages describe the requests listed in each section of
+// the mock service's verification report
+var verificationReportMessages = map[string]string{
+	missingRequests:    "expected request was not received",
+	unexpectedRequests: "no interaction expected this method and path",
+	incorrectRequests:  "request matched no interaction",
+}
+
+var reportedRequestRegex = regexp.MustCompile(`^(\S+) (\S+)(?: \((.*)\))?$`)
+
+// parseVerificationReport parses the requests listed in each section of the
+// mock service's verification report e.g.
+//
+//	Incorrect requests:
+//		GET /users/1 (request body did not match)
+func parseVerificationReport(report string) map[string][]types.RequestMismatch {
+	requests := make(map[string][]types.RequestMismatch)
+
+	section := ""
+	for _, line := range strings.Split(report, "\n") {
+		line = strings.TrimSpace(line)
+		if _, ok := verificationReportMessages[line]; ok {
+			section = line
+			continue
+		}
+
+		m := reportedRequestRegex.FindStringSubmatch(line)
+		if section == "" || m == nil {
+			section = ""
+			continue
+		}
+
+		message := verificationReportMessages[section]
+		if m[3] != "" {
+			message = m[3]
+		}
+		requests[section] = append(requests[section], types.RequestMismatch{Method: m[1], Path: m[2], Message: message})
+	}
+
+	return requests
+}
+
+// verificationMismatches returns the mismatches in the mock service's
+// verification report, with the descriptions of the interactions that were
+// not requested. The differences recorded for incorrect requests, if
+// RecordMismatches is set, replace the report's summary of them.
+func (p *Pact) verificationMismatches(report string) []types.InteractionMismatches {
+	requests := parseVerificationReport(report)
+	recorded := p.mismatches.take()
+
+	var mismatches []types.InteractionMismatches
+	described := make(map[*Interaction]bool)
+	for _, request := range requests[missingRequests] {
+		description := ""
+		for _, interaction := range p.Interactions {
+			path, _ := exampleOf(interaction.Request.Path).(string)
+			if !described[interaction] && strings.EqualFold(interaction.Request.Method, request.Method) && path == strings.SplitN(request.Path, "?", 2)[0] {
+				described[interaction] = true
+				description = interaction.Description
+				break
+			}
+		}
+		mismatches = append(mismatches, types.InteractionMismatches{Description: description, Mismatches: []types.Mismatch{request}})
+	}
+	for _, request := range requests[unexpectedRequests] {
+		mismatches = append(mismatches, types.InteractionMismatches{Mismatches: []types.Mismatch{request}})
+	}
+
+	if len(recorded) > 0 {
+		return append(mismatches, recorded...)
+	}
+	for _, request := range requests[incorrectRequests] {
+		mismatches = append(mismatches, types.InteractionMismatches{Mismatches: []types.Mismatch{request}})
+	}
+
+	return mismatches
+}
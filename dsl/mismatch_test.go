@@ -0,0 +1,108 @@
+package dsl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPact_VerifyMismatches(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/interactions/verification":
+			http.Error(w, "Actual interactions do not match expected interactions for mock MockService.\n\nIncorrect requests:\n\tGET /users/1 (request body did not match)", http.StatusInternalServerError)
+		case r.Header.Get("X-Pact-Mock-Service") != "":
+			fmt.Fprintln(w, "ok")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "No interaction found for GET /users/1", "interaction_diffs": [{
+				"description": "a request for user 1",
+				"headers": {"Accept": {"EXPECTED": "application/json", "ACTUAL": "text/plain"}}
+			}]}`)
+		}
+	}))
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:           &types.MockServer{Port: getPort(ms.URL)},
+		Consumer:         "My Consumer",
+		Provider:         "My Provider",
+		RecordMismatches: true,
+	}
+	pact.
+		AddInteraction().
+		UponReceiving("a request for user 1").
+		WithRequest(Request{Method: "GET", Path: String("/users/1")}).
+		WillRespondWith(Response{Status: 200})
+
+	err := pact.Verify(func() error {
+		res, err := http.Get(fmt.Sprintf("http://localhost:%d/users/1", pact.Server.Port))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusInternalServerError {
+			return fmt.Errorf("expected the mock server's response, got %d", res.StatusCode)
+		}
+		return nil
+	})
+
+	mismatchErr, ok := err.(*MismatchError)
+	if !ok {
+		t.Fatalf("expected a *MismatchError, got %v", err)
+	}
+	if len(mismatchErr.Mismatches) != 1 || mismatchErr.Mismatches[0].Description != "a request for user 1" {
+		t.Fatalf("unexpected mismatches %+v", mismatchErr.Mismatches)
+	}
+	want := types.HeaderMismatch{Key: "Accept", Expected: "application/json", Actual: "text/plain", Rule: "equality"}
+	if mismatches := mismatchErr.Mismatches[0].Mismatches; len(mismatches) != 1 || mismatches[0] != want {
+		t.Fatalf("want %v, got %v", want, mismatches)
+	}
+	if pact.mismatches.take() != nil {
+		t.Fatal("expected the recorded mismatches to be cleared")
+	}
+}
+
+func TestPact_VerifyReportedMismatches(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/interactions/verification" {
+			http.Error(w, "Actual interactions do not match expected interactions for mock MockService.\n\nMissing requests:\n\tGET /users/2\n\nUnexpected requests:\n\tDELETE /users\n\nIncorrect requests:\n\tGET /users/1 (request body did not match)\n\nSee pact.log for details.", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}))
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:   &types.MockServer{Port: getPort(ms.URL)},
+		Consumer: "My Consumer",
+		Provider: "My Provider",
+	}
+	for _, id := range []string{"1", "2"} {
+		pact.
+			AddInteraction().
+			UponReceiving("a request for user " + id).
+			WithRequest(Request{Method: "GET", Path: String("/users/" + id)}).
+			WillRespondWith(Response{Status: 200})
+	}
+
+	err := pact.Verify(func() error { return nil })
+
+	mismatchErr, ok := err.(*MismatchError)
+	if !ok {
+		t.Fatalf("expected a *MismatchError, got %v", err)
+	}
+	want := []types.InteractionMismatches{
+		{Description: "a request for user 2", Mismatches: []types.Mismatch{types.RequestMismatch{Method: "GET", Path: "/users/2", Message: "expected request was not received"}}},
+		{Mismatches: []types.Mismatch{types.RequestMismatch{Method: "DELETE", Path: "/users", Message: "no interaction expected this method and path"}}},
+		{Mismatches: []types.Mismatch{types.RequestMismatch{Method: "GET", Path: "/users/1", Message: "request body did not match"}}},
+	}
+	if !reflect.DeepEqual(mismatchErr.Mismatches, want) {
+		t.Fatalf("want %+v, got %+v", want, mismatchErr.Mismatches)
+	}
+}
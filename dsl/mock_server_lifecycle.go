@@ -0,0 +1,117 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+)
+
+// MockServerEvents are callbacks on the lifecycle of a mock server, e.g. for
+// a test harness or IDE integration to follow the server. Each is optional.
+type MockServerEvents struct {
+	// Started is called when the server is listening, with its address e.g.
+	// "127.0.0.1:50123"
+	Started func(addr string)
+
+	// Stopped is called when the server has shut down
+	Stopped func()
+
+	// Matched is called when a request matches an interaction, after the
+	// response is written
+	Matched func(interaction *Interaction)
+}
+
+// NewUnstartedNativeMockServer returns a mock server that is not yet
+// listening, so that its Limits and Events may be set before calling Start.
+func NewUnstartedNativeMockServer() *NativeMockServer {
+	s := &NativeMockServer{}
+	s.Server = httptest.NewUnstartedServer(s)
+
+	return s
+}
+
+// Start starts the server. A stopped server is started on the same address,
+// keeping its interactions and the requests it received.
+func (s *NativeMockServer) Start() error {
+	s.lifecycle.Lock()
+	if s.running {
+		s.lifecycle.Unlock()
+		return fmt.Errorf("the native mock server is already running on %s", s.URL)
+	}
+
+	if s.stopped {
+		listener, err := net.Listen("tcp", s.Listener.Addr().String())
+		if err != nil {
+			s.lifecycle.Unlock()
+			return fmt.Errorf("unable to restart the native mock server: %v", err)
+		}
+		s.Server = &httptest.Server{Listener: listener, Config: &http.Server{Handler: s}}
+	}
+	s.Config.ReadTimeout = s.Limits.Timeout
+	s.Server.Start()
+	s.running = true
+	addr := s.Listener.Addr().String()
+	s.lifecycle.Unlock()
+
+	log.Println("[DEBUG] native mock server: started on", addr)
+	if s.Events.Started != nil {
+		s.Events.Started(addr)
+	}
+
+	return nil
+}
+
+// Stop shuts the server down gracefully, waiting for the requests in flight
+// to be answered until the context is done, when their connections are
+// closed and the context's error returned. Stopping a stopped server does
+// nothing.
+func (s *NativeMockServer) Stop(ctx context.Context) error {
+	s.lifecycle.Lock()
+	if !s.running {
+		s.lifecycle.Unlock()
+		return nil
+	}
+	s.running = false
+	s.stopped = true
+	server := s.Server
+	s.lifecycle.Unlock()
+
+	err := server.Config.Shutdown(ctx)
+	if err != nil {
+		server.CloseClientConnections()
+	}
+	server.Close()
+
+	log.Println("[DEBUG] native mock server: stopped")
+	if s.Events.Stopped != nil {
+		s.Events.Stopped()
+	}
+
+	return err
+}
+
+// Restart stops the server gracefully (see Stop) and starts it again on the
+// same address
+func (s *NativeMockServer) Restart(ctx context.Context) error {
+	if err := s.Stop(ctx); err != nil {
+		return err
+	}
+
+	return s.Start()
+}
+
+// Close stops the server, waiting for the requests in flight to be answered
+func (s *NativeMockServer) Close() {
+	s.lifecycle.Lock()
+	unstarted := !s.running && !s.stopped
+	s.lifecycle.Unlock()
+	if unstarted {
+		s.Server.Close()
+		return
+	}
+
+	s.Stop(context.Background()) // nolint:errcheck
+}
@@ -0,0 +1,110 @@
+package dsl
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNativeMockServer_Lifecycle(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	event := func(e string) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}
+
+	server := NewUnstartedNativeMockServer()
+	defer server.Close()
+	server.Events = MockServerEvents{
+		Started: func(addr string) { event("started " + addr) },
+		Stopped: func() { event("stopped") },
+		Matched: func(i *Interaction) { event("matched " + i.Description) },
+	}
+	if err := server.AddInteraction(nativeOrderInteractions()[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := server.Start(); err == nil {
+		t.Fatal("expected an error starting a running server")
+	}
+	addr := server.Listener.Addr().String()
+	url := server.URL
+
+	get := func() {
+		req, _ := http.NewRequest("GET", url+"/orders/1?expand=items", nil)
+		req.Header.Set("Accept", "application/json")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != 200 {
+			t.Fatalf("expected the interaction to match, got %d", res.StatusCode)
+		}
+	}
+	get()
+
+	if err := server.Restart(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if server.URL != url {
+		t.Fatalf("expected the server to restart on %s, got %s", url, server.URL)
+	}
+	get()
+
+	if err := server.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := server.Stop(context.Background()); err != nil {
+		t.Fatalf("expected stopping a stopped server to do nothing, got %v", err)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Fatal("expected the stopped server to refuse connections")
+	}
+
+	want := []string{
+		"started " + addr, "matched a request for order 1", "stopped",
+		"started " + addr, "matched a request for order 1", "stopped",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != len(want) {
+		t.Fatalf("want events %v, got %v", want, events)
+	}
+	for n := range want {
+		if events[n] != want[n] {
+			t.Fatalf("want events %v, got %v", want, events)
+		}
+	}
+}
+
+func TestNativeMockServer_StopTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := NewUnstartedNativeMockServer()
+	defer server.Close()
+	server.Events.Matched = func(*Interaction) { <-release }
+	server.AddInteraction(nativeOrderInteractions()[1]) // nolint:errcheck
+	server.Start()                                      // nolint:errcheck
+
+	go http.Post(server.URL+"/orders", "application/json", strings.NewReader(`{"sku": "ABC", "quantity": 1}`)) // nolint:errcheck
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- server.Stop(ctx) }()
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	if err := <-done; err != context.DeadlineExceeded {
+		t.Fatalf("expected the shutdown to time out, got %v", err)
+	}
+}
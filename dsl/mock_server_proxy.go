@@ -0,0 +1,139 @@
+package dsl
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+
+	"github.com/pact-foundation/pact-go/utils"
+)
+
+// startMockServerProxy starts a proxy in front of the mock server, which
+// selects between interactions the mock server can't distinguish itself
+// (content negotiation and sequenced responses), rejects requests with a body
+// for interactions expecting none, removes unexpected keys from requests for
+// interactions allowing them, converts numbers sent as strings (and vice
+// versa), generates response headers, converts MessagePack bodies to and
+// from their recorded form, applies body transforms, matches and sends
+// trailers, enforces Limits,
+// normalises the Unicode text of requests if UnicodeNormalization is set,
+// records mismatches and,
+// if HARDir is set, traffic, and explains how requests compare with the
+// interactions if Explain is set. All mock server traffic is then routed
+// through it. It is only started if needsMockServerProxy.
+func (p *Pact) startMockServerProxy() error {
+	target, err := url.Parse(fmt.Sprintf("http://%s:%d", p.Host, p.Server.Port))
+	if err != nil {
+		return err
+	}
+
+	port, err := utils.GetFreePort()
+	if err != nil {
+		return err
+	}
+
+	p.mismatches = newMismatchRecorder()
+	p.bodies = newBodyChecker()
+	p.postel = newPostelRequests()
+	p.numbers = newNumericStrings()
+	var handler http.Handler = p.mismatches.middleware(p.postel.middleware(p.numbers.requestMiddleware(p.bodies.middleware(httputil.NewSingleHostReverseProxy(target)))))
+	if p.Explain {
+		p.explainer = newRequestExplainer(p.ExplainOutput)
+		handler = p.explainer.middleware(handler)
+	}
+	p.transformer = newBodyTransformer(false)
+	handler = p.transformer.middleware(handler)
+	if p.UnicodeNormalization != nil {
+		handler = unicodeNormaliser(p.UnicodeNormalization).requestMiddleware(handler)
+	}
+	if p.ContentNegotiation {
+		p.negotiator = newContentNegotiator()
+		handler = p.negotiator.middleware(handler)
+	}
+	p.sequencer = newRequestSequencer()
+	handler = p.sequencer.middleware(handler)
+	p.generators = newResponseGenerators()
+	handler = p.generators.middleware(handler)
+	handler = msgpackMiddleware(msgpackToBase64, base64ToMsgPack)(handler)
+	if p.HARDir != "" {
+		p.har = newHARRecorder()
+		handler = p.har.middleware(handler)
+	}
+	// Trailers are sent after the body, so must be added outside the
+	// middleware buffering responses
+	p.trailerChecker = newConsumerTrailers(p.mismatches.record)
+	handler = p.trailerChecker.middleware(handler)
+	handler = p.Limits.middleware(p.mismatches.record)(handler)
+
+	log.Println("[DEBUG] starting mock server proxy on port", port)
+	p.proxyServer = &http.Server{Addr: fmt.Sprintf("%s:%d", p.Host, port), Handler: handler, ReadTimeout: p.Limits.Timeout}
+	go p.proxyServer.ListenAndServe() // nolint:errcheck
+
+	err = waitForPort(port, p.Network, p.Host, p.ClientTimeout,
+		fmt.Sprintf(`Timed out waiting for mock server proxy on port %d - check for errors`, port))
+	if err != nil {
+		return err
+	}
+	p.Server.Port = port
+
+	return nil
+}
+
+// needsMockServerProxy determines if a feature in use requires the proxy in
+// front of the mock server, which otherwise isn't started
+func (p *Pact) needsMockServerProxy() bool {
+	return len(p.mockServerProxyFeatures()) > 0
+}
+
+// mockServerProxyFeatures names the features in use that require the proxy
+// in front of the mock server
+func (p *Pact) mockServerProxyFeatures() []string {
+	var features []string
+	for feature, used := range map[string]bool{
+		"RecordMismatches":                     p.RecordMismatches,
+		"ContentNegotiation":                   p.ContentNegotiation,
+		"Explain":                              p.Explain,
+		"HARDir":                               p.HARDir != "",
+		"UnicodeNormalization":                 p.UnicodeNormalization != nil,
+		"Limits":                               p.Limits != (MockServerLimits{}),
+		"sequenced interactions":               hasSequencedInteractions(p.Interactions),
+		"MessagePack bodies":                   hasMsgPackInteractions(p.Interactions),
+		"generators in DefaultResponseHeaders": hasHeaderGenerators(p.DefaultResponseHeaders),
+	} {
+		if used {
+			features = append(features, feature)
+		}
+	}
+	for _, i := range p.Interactions {
+		body := i.requestBodyExpectation()
+		for feature, used := range map[string]bool{
+			"a NoBody or EmptyBody request": body == NoBody || body == EmptyBody,
+			"PostelBodyMatching":            i.bodyMatching == PostelBodyMatching,
+			"WithNumbersAsStrings":          len(i.numbersAsStrings) > 0,
+			"generated headers":             len(i.headerGenerators()) > 0,
+			"body transforms":               len(i.bodyTransformNames()) > 0,
+			"trailers":                      i.trailerSides() != nil,
+		} {
+			if used {
+				features = append(features, fmt.Sprintf("%s in interaction '%s'", feature, i.Description))
+			}
+		}
+	}
+	sort.Strings(features)
+
+	return features
+}
+
+// hasHeaderGenerators determines if any of the headers is generated
+func hasHeaderGenerators(headers MapMatcher) bool {
+	for _, m := range headers {
+		if _, ok := findGenerator(m); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package dsl
+
+import "testing"
+
+func TestPact_needsMockServerProxy(t *testing.T) {
+	plain := func() *Interaction {
+		return (&Interaction{}).
+			UponReceiving("a request for an order").
+			WithRequest(Request{Method: "GET", Path: String("/orders/1")}).
+			WillRespondWith(Response{Status: 200})
+	}
+
+	for name, tc := range map[string]struct {
+		pact *Pact
+		want bool
+	}{
+		"plain":               {&Pact{Interactions: []*Interaction{plain()}}, false},
+		"record mismatches":   {&Pact{RecordMismatches: true}, true},
+		"explain":             {&Pact{Explain: true}, true},
+		"content negotiation": {&Pact{ContentNegotiation: true}, true},
+		"limits":              {&Pact{Limits: MockServerLimits{MaxHeaders: 10}}, true},
+		"sequenced":           {&Pact{Interactions: []*Interaction{plain().Sequence(1)}}, true},
+		"no body":             {&Pact{Interactions: []*Interaction{plain().WithRequest(Request{Method: "GET", Path: String("/"), Body: NoBody})}}, true},
+		"msgpack":             {&Pact{Interactions: []*Interaction{plain().WillRespondWith(Response{Status: 200, Body: MsgPackBody(map[string]int{"id": 1})})}}, true},
+		"header generators":   {&Pact{Interactions: []*Interaction{plain().WillRespondWith(Response{Status: 200, Headers: MapMatcher{"X-Request-Id": Generate(UUID())}})}}, true},
+		"default generators":  {&Pact{DefaultResponseHeaders: MapMatcher{"X-Request-Id": Generate(UUID())}, Interactions: []*Interaction{plain()}}, true},
+	} {
+		if got := tc.pact.needsMockServerProxy(); got != tc.want {
+			t.Errorf("%s: want %v, got %v", name, tc.want, got)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 )
 
 // MockService is the HTTP interface to setup the Pact Mock Service
@@ -88,6 +89,39 @@ func (m *MockService) Verify() error {
 	return m.call("GET", url, nil)
 }
 
+// UnexpectedRequests returns any requests received by the Mock Service that
+// did not match an interaction, or that matched one incorrectly. Unlike Verify,
+// interactions that have not (yet) been called are ignored.
+func (m *MockService) UnexpectedRequests() ([]string, error) {
+	log.Println("[DEBUG] mock service unexpected requests")
+	err := m.Verify()
+	if err == nil {
+		return nil, nil
+	}
+
+	if !strings.Contains(err.Error(), "Actual interactions do not match expected interactions") {
+		return nil, err
+	}
+
+	var requests []string
+	inSection := false
+	for _, line := range strings.Split(err.Error(), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			inSection = false
+		case strings.HasPrefix(line, "Unexpected requests:"), strings.HasPrefix(line, "Incorrect requests:"):
+			inSection = true
+		case strings.HasSuffix(line, ":"):
+			inSection = false
+		case inSection:
+			requests = append(requests, line)
+		}
+	}
+
+	return requests, nil
+}
+
 // WritePact writes the pact file to disk.
 func (m *MockService) WritePact() error {
 	log.Println("[DEBUG] mock service write pact")
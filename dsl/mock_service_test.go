@@ -7,6 +7,7 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/pact-foundation/pact-go/utils"
@@ -175,3 +176,47 @@ func TestMockService_callInvalidObject(t *testing.T) {
 		t.Fatalf("Expected error but got none")
 	}
 }
+
+func TestMockService_UnexpectedRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "verified", status: 200, body: "Interactions matched"},
+		{
+			name:   "missing only",
+			status: 500,
+			body:   "Actual interactions do not match expected interactions for mock MockService.\n\nMissing requests:\n\tGET /foobar\n\n",
+		},
+		{
+			name:   "unexpected and incorrect",
+			status: 500,
+			body:   "Actual interactions do not match expected interactions for mock MockService.\n\nMissing requests:\n\tGET /foobar\n\nUnexpected requests:\n\tGET /baz\n\tPOST /qux\n\nIncorrect requests:\n\tGET /foobar (request body did not match)\n\nSee pact.log for details.\n",
+			want:   []string{"GET /baz", "POST /qux", "GET /foobar (request body did not match)"},
+		},
+		{name: "server error", status: 500, body: "something went wrong", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer ms.Close()
+
+			mockService := &MockService{BaseURL: ms.URL}
+			got, err := mockService.UnexpectedRequests()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/pact-foundation/pact-go/msgpack"
+)
+
+// MsgPackContentType is the content type of MessagePack bodies
+const MsgPackContentType = "application/msgpack"
+
+// msgpackContentHandler is the built in handler for MessagePack messages
+type msgpackContentHandler struct{}
+
+func (msgpackContentHandler) Encode(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackContentHandler) Decode(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// isMsgPack determines if the content type is one of the MessagePack types
+func isMsgPack(contentType string) bool {
+	handler, err := contentHandler(contentType)
+	if err != nil {
+		return false
+	}
+	_, ok := handler.(msgpackContentHandler)
+
+	return ok
+}
+
+// MsgPackBodyBuilder is a MessagePack encoded Request or Response body
+type MsgPackBodyBuilder struct {
+	encoded string
+	err     error
+}
+
+// MsgPackBody encodes the value as a MessagePack body for a Request or
+// Response. The body is recorded in the pact base64 encoded, in a canonical
+// form, so that requests and responses are matched on their decoded
+// structure. The Content-Type defaults to MsgPackContentType.
+func MsgPackBody(v interface{}) *MsgPackBodyBuilder {
+	data, err := msgpack.Marshal(v)
+	if err != nil {
+		return &MsgPackBodyBuilder{err: fmt.Errorf("invalid MessagePack body: %v", err)}
+	}
+
+	return &MsgPackBodyBuilder{encoded: base64.StdEncoding.EncodeToString(data)}
+}
+
+// MarshalJSON writes the base64 encoded body
+func (b *MsgPackBodyBuilder) MarshalJSON() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return json.Marshal(b.encoded)
+}
+
+// msgpackBodyError returns any error recorded while encoding a MessagePack
+// body
+func msgpackBodyError(body interface{}) error {
+	if b, ok := body.(*MsgPackBodyBuilder); ok {
+		return b.err
+	}
+
+	return nil
+}
+
+// msgpackHeaders defaults the Content-Type of MessagePack bodies
+func msgpackHeaders(body interface{}, headers MapMatcher) MapMatcher {
+	if _, ok := body.(*MsgPackBodyBuilder); !ok {
+		return headers
+	}
+
+	return mergeHeaders(MapMatcher{"Content-Type": String(MsgPackContentType)}, headers)
+}
+
+// hasMsgPackInteractions determines if any interaction has a MessagePack body
+func hasMsgPackInteractions(interactions []*Interaction) bool {
+	for _, i := range interactions {
+		if _, ok := i.Request.Body.(*MsgPackBodyBuilder); ok {
+			return true
+		}
+		if _, ok := i.Response.Body.(*MsgPackBodyBuilder); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// msgpackToBase64 converts a MessagePack body to the canonical, base64
+// encoded, form it is recorded in
+func msgpackToBase64(body []byte) ([]byte, error) {
+	canonical, err := msgpack.Canonical(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(canonical)), nil
+}
+
+// base64ToMsgPack converts a recorded body back to MessagePack
+func base64ToMsgPack(body []byte) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(string(bytes.TrimSpace(body)))
+}
+
+// msgpackMiddleware converts the bodies of MessagePack requests and
+// responses between their recorded (base64) and wire forms, as neither the
+// mock server nor verifier understand MessagePack. Bodies that can't be
+// converted are passed through unchanged, to be reported as mismatches.
+func msgpackMiddleware(convertRequest func([]byte) ([]byte, error), convertResponse func([]byte) ([]byte, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Pact-Mock-Service") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if isMsgPack(r.Header.Get("Content-Type")) && r.Body != nil {
+				body, err := ioutil.ReadAll(r.Body)
+				r.Body.Close()
+				if err == nil {
+					if converted, convertErr := convertRequest(body); convertErr == nil {
+						body = converted
+					} else {
+						log.Println("[WARN] unable to convert MessagePack request body:", convertErr)
+					}
+				}
+				r.Body = ioutil.NopCloser(bytes.NewReader(body))
+				r.ContentLength = int64(len(body))
+				r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			}
+
+			recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			for name, values := range recorder.header {
+				w.Header()[name] = values
+			}
+
+			body := recorder.body.Bytes()
+			if isMsgPack(recorder.header.Get("Content-Type")) && len(body) > 0 {
+				if converted, err := convertResponse(body); err == nil {
+					body = converted
+					w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				} else {
+					log.Println("[WARN] unable to convert MessagePack response body:", err)
+				}
+			}
+			w.WriteHeader(recorder.status)
+			w.Write(body) // nolint:errcheck
+		})
+	}
+}
+
+// bufferedResponse records a response so that it may be modified before
+// being written
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
@@ -0,0 +1,121 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/msgpack"
+)
+
+func TestMsgPackBody(t *testing.T) {
+	i := (&Interaction{}).
+		UponReceiving("a request for an order").
+		WithRequest(Request{Method: "GET", Path: String("/orders/1")}).
+		WillRespondWith(Response{Status: 200, Body: MsgPackBody(map[string]interface{}{"compact": true, "schema": 0})})
+
+	body, err := json.Marshal(i.Response.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != `"gqdjb21wYWN0w6ZzY2hlbWEA"` {
+		t.Fatalf("expected a base64 encoded body, got %s", body)
+	}
+
+	headers, _ := json.Marshal(msgpackHeaders(i.Response.Body, i.Response.Headers))
+	if string(headers) != `{"Content-Type":"application/msgpack"}` {
+		t.Fatalf("expected a default Content-Type, got %s", headers)
+	}
+	if !hasMsgPackInteractions([]*Interaction{i}) {
+		t.Fatal("expected the interaction to have a MessagePack body")
+	}
+
+	i.Request.Body = MsgPackBody(make(chan int))
+	if err := i.Validate(); err == nil {
+		t.Fatal("expected an error for a body that can't be encoded")
+	}
+}
+
+func TestMsgPackMiddleware(t *testing.T) {
+	order := map[string]interface{}{"id": 1, "status": "created"}
+	wire, _ := msgpack.Marshal(order)
+	recorded, _ := msgpackToBase64(wire)
+
+	var received []byte
+	mockServer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", MsgPackContentType)
+		w.Write(recorded) // nolint:errcheck
+	})
+	handler := msgpackMiddleware(msgpackToBase64, base64ToMsgPack)(mockServer)
+
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewReader(wire))
+	req.Header.Set("Content-Type", "application/x-msgpack")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if !bytes.Equal(received, recorded) {
+		t.Fatalf("expected the mock server to receive the recorded form %s, got %s", recorded, received)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), wire) {
+		t.Fatalf("expected the client to receive MessagePack %x, got %x", wire, rr.Body.Bytes())
+	}
+
+	// The verification proxy converts the other way
+	provider := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", MsgPackContentType)
+		w.Write(wire) // nolint:errcheck
+	})
+	handler = msgpackMiddleware(base64ToMsgPack, msgpackToBase64)(provider)
+
+	req, _ = http.NewRequest("POST", "/orders", bytes.NewReader(recorded))
+	req.Header.Set("Content-Type", MsgPackContentType)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if !bytes.Equal(received, wire) || !bytes.Equal(rr.Body.Bytes(), recorded) {
+		t.Fatalf("unexpected conversion, provider received %x and verifier %s", received, rr.Body.Bytes())
+	}
+}
+
+func TestMsgPackMiddleware_Passthrough(t *testing.T) {
+	handler := msgpackMiddleware(msgpackToBase64, base64ToMsgPack)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body) // nolint:errcheck
+	}))
+
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewReader([]byte(`{"id":1}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated || rr.Body.String() != `{"id":1}` {
+		t.Fatalf("expected other content types to be untouched, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestMessage_WithContentTypeMsgPack(t *testing.T) {
+	type event struct {
+		ID int `json:"id"`
+	}
+
+	content, err := EncodeContent(MsgPackContentType, event{ID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reified, _ := json.Marshal(content)
+	decoded, err := decodeContent(MsgPackContentType, reified, event{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != (event{ID: 1}) {
+		t.Fatalf("unexpected decoded content %#v", decoded)
+	}
+}
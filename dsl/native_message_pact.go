@@ -0,0 +1,203 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+// NativeMessagePact builds a version 3 message pact in-process, for
+// consumers of queues and topics (e.g. Kafka or SQS) that have no HTTP
+// surface and don't use the Ruby pact-message tool.
+//
+// Messages are built with the Message DSL: their contents and metadata may
+// contain matchers. VerifyMessage sends the example contents to the
+// consumer's handler, and the messages it accepts are written to the pact by
+// WritePact, replacing any earlier message with the same description.
+//
+// Request/reply messages are built with AddSynchronousMessage, and verified
+// with VerifySynchronousMessage. Pacts with synchronous messages are written
+// as version 4 pacts, as earlier versions can't represent them.
+//
+// Raw contents (ContentRaw) are not supported.
+type NativeMessagePact struct {
+	Consumer string
+	Provider string
+
+	// PactDir is the directory the pact file is written to. Defaults to
+	// "pacts" in the working directory.
+	PactDir string
+
+	mu          sync.Mutex
+	messages    []*Message
+	synchronous []*SynchronousMessage
+}
+
+// NewNativeMessagePact returns a message pact between the consumer and
+// provider
+func NewNativeMessagePact(consumer, provider string) *NativeMessagePact {
+	return &NativeMessagePact{Consumer: consumer, Provider: provider}
+}
+
+// AddMessage returns a new message to build. It is added to the pact once
+// verified by VerifyMessage.
+func (p *NativeMessagePact) AddMessage() *Message {
+	return &Message{}
+}
+
+// VerifyMessageRaw sends the example of the message to the handler, adding
+// the message to the pact if the handler accepts it. Unless the message's
+// Type is set (see AsType) the handler is given the example as decoded JSON.
+func (p *NativeMessagePact) VerifyMessageRaw(message *Message, handler MessageConsumer) error {
+	log.Println("[DEBUG] native message pact: verify message", message.Description)
+	if message.err != nil {
+		return message.err
+	}
+	if message.Description == "" {
+		return fmt.Errorf("the message has no description, see ExpectsToReceive")
+	}
+	if message.ContentRaw != nil {
+		return fmt.Errorf("message '%s': raw contents are not supported by the native message pact, use matchers", message.Description)
+	}
+
+	serialised := serialiseMessage(message)
+	reified, err := json.Marshal(serialised["contents"])
+	if err != nil {
+		return fmt.Errorf("unable to convert message '%s' to a valid JSON representation: %v", message.Description, err)
+	}
+
+	if message.Type == nil {
+		var content interface{}
+		if err = json.Unmarshal(reified, &content); err != nil {
+			return err
+		}
+		message.Type = content
+	}
+
+	if err = yieldMessage(message, reified, handler); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for n, existing := range p.messages {
+		if existing.Description == message.Description {
+			p.messages[n] = message
+			return nil
+		}
+	}
+	p.messages = append(p.messages, message)
+
+	return nil
+}
+
+// VerifyMessage is a test convenience function for VerifyMessageRaw,
+// accepting an instance of `*testing.T`
+func (p *NativeMessagePact) VerifyMessage(t *testing.T, message *Message, handler MessageConsumer) error {
+	err := p.VerifyMessageRaw(message, handler)
+	if err != nil {
+		t.Errorf("VerifyMessage failed: %v", err)
+	}
+
+	return err
+}
+
+// Pact returns the verified messages as a version 3 pact, or version 4 if
+// there are synchronous messages
+func (p *NativeMessagePact) Pact() (*pactfile.Pact, error) {
+	content, err := p.serialise()
+	if err != nil {
+		return nil, err
+	}
+
+	return pactfile.Parse(content)
+}
+
+// WritePact writes the verified messages to the pact file in PactDir,
+// overwriting it
+func (p *NativeMessagePact) WritePact() error {
+	content, err := p.serialise()
+	if err != nil {
+		return err
+	}
+
+	dir := p.PactDir
+	if dir == "" {
+		wd, _ := os.Getwd()
+		dir = filepath.Join(wd, "pacts")
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create the pact directory: %v", err)
+	}
+
+	file := filepath.Join(dir, pactFileName(p.Consumer, p.Provider))
+	log.Println("[DEBUG] native message pact: writing", file)
+
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+// serialise writes the verified messages as a version 3 pact document, or
+// version 4 if there are synchronous messages
+func (p *NativeMessagePact) serialise() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	messages := make([]interface{}, len(p.messages))
+	for n, m := range p.messages {
+		messages[n] = serialiseMessage(m)
+	}
+	doc := map[string]interface{}{
+		"consumer": map[string]string{"name": p.Consumer},
+		"provider": map[string]string{"name": p.Provider},
+		"messages": messages,
+	}
+
+	version := 3
+	if len(p.synchronous) > 0 {
+		version = 4
+		interactions := make([]interface{}, len(p.synchronous))
+		for n, m := range p.synchronous {
+			interactions[n] = serialiseSynchronousMessage(m)
+		}
+		doc["interactions"] = interactions
+	}
+
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return pactfile.ConvertSpecification(content, version)
+}
+
+// serialiseMessage writes the message with the examples of its contents and
+// metadata, and the matching rules of their matchers
+func serialiseMessage(m *Message) map[string]interface{} {
+	rules := MatchingRules{}
+	message := map[string]interface{}{
+		"description": m.Description,
+		"contents":    serialiseMatcher(BodyPath(), m.Content, rules),
+	}
+	if len(m.States) > 0 {
+		message["providerStates"] = m.States
+	}
+	if len(m.Metadata) > 0 {
+		metadata := make(map[string]interface{}, len(m.Metadata))
+		for name, value := range m.Metadata {
+			metadata[name] = serialiseMatcher(MetadataPath(name), value, rules)
+		}
+		message["metaData"] = metadata
+	}
+	if len(rules) > 0 {
+		message["matchingRules"] = rules
+	}
+
+	return message
+}
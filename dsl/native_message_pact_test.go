@@ -0,0 +1,112 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+type orderCreated struct {
+	ID    int      `json:"id"`
+	Items []string `json:"items"`
+}
+
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	json.Compact(&buf, raw) // nolint:errcheck
+	return buf.String()
+}
+
+func TestNativeMessagePact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-messages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pact := NewNativeMessagePact("order-consumer", "order-service")
+	pact.PactDir = dir
+
+	message := pact.AddMessage().
+		Given("order 1 exists").
+		ExpectsToReceive("an order created event").
+		WithMetadata(MapMatcher{"topic": Term("orders.created", `^orders\.`)}).
+		WithContent(StructMatcher{"id": Like(1), "items": EachLike("ABC", 2)}).
+		AsType(&orderCreated{})
+
+	var received *orderCreated
+	err = pact.VerifyMessageRaw(message, func(m Message) error {
+		received = m.Content.(*orderCreated)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received == nil || received.ID != 1 || len(received.Items) != 2 {
+		t.Fatalf("expected the handler to receive the example order, got %+v", received)
+	}
+
+	rejected := pact.AddMessage().ExpectsToReceive("an order deleted event").WithContent(StructMatcher{"id": Like(1)})
+	if err = pact.VerifyMessageRaw(rejected, func(Message) error { return fmt.Errorf("unknown event") }); err == nil || err.Error() != "unknown event" {
+		t.Fatalf("expected the handler's error, got %v", err)
+	}
+
+	if err = pact.WritePact(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := pactfile.Read(filepath.Join(dir, "order-consumer-order-service.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written.Messages) != 1 || written.SpecificationVersion() != "3.0.0" {
+		t.Fatalf("expected only the accepted message in a version 3 pact, got %d messages in %s", len(written.Messages), written.SpecificationVersion())
+	}
+
+	var rules map[string]map[string]struct {
+		Matchers []map[string]interface{} `json:"matchers"`
+	}
+	if err = json.Unmarshal(written.Messages[0].MatchingRules, &rules); err != nil {
+		t.Fatal(err)
+	}
+	if rules["body"]["$.items"].Matchers[0]["min"] != float64(2) || rules["metadata"]["topic"].Matchers[0]["regex"] != `^orders\.` {
+		t.Fatalf("expected the body and metadata matching rules, got %s", written.Messages[0].MatchingRules)
+	}
+	if got := compactJSON(written.Messages[0].Metadata); got != `{"topic":"orders.created"}` {
+		t.Fatalf("expected the example metadata, got %s", got)
+	}
+}
+
+func TestNativeMessagePact_Errors(t *testing.T) {
+	pact := NewNativeMessagePact("order-consumer", "order-service")
+	accept := func(Message) error { return nil }
+
+	if err := pact.VerifyMessageRaw(pact.AddMessage(), accept); err == nil {
+		t.Fatal("expected a message without a description to be rejected")
+	}
+	raw := pact.AddMessage().ExpectsToReceive("a raw event")
+	raw.ContentRaw = `{"id":1}`
+	if err := pact.VerifyMessageRaw(raw, accept); err == nil {
+		t.Fatal("expected raw contents to be rejected")
+	}
+
+	first := pact.AddMessage().ExpectsToReceive("an event").WithContent(StructMatcher{"id": Like(1)})
+	second := pact.AddMessage().ExpectsToReceive("an event").WithContent(StructMatcher{"id": Like("1")})
+	for _, m := range []*Message{first, second} {
+		if err := pact.VerifyMessageRaw(m, accept); err != nil {
+			t.Fatal(err)
+		}
+	}
+	written, err := pact.Pact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written.Messages) != 1 || compactJSON(written.Messages[0].Contents) != `{"id":"1"}` {
+		t.Fatalf("expected the message to be replaced, got %+v", written.Messages)
+	}
+}
@@ -0,0 +1,589 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// VerifyMessageProviderNative accepts an instance of `*testing.T`, running
+// native message verification (see VerifyMessageProviderNativeRaw) with
+// granular test reporting.
+func (p *Pact) VerifyMessageProviderNative(t *testing.T, request VerifyMessageRequest) ([]types.ProviderVerifierResponse, error) {
+	res, err := p.VerifyMessageProviderNativeRaw(request)
+
+	runTestCases(t, res)
+
+	return res, err
+}
+
+// VerifyMessageProviderNativeRaw verifies a message provider in-process,
+// without the Ruby verifier. After its provider states are set up by the
+// StateHandlers, each message in the pacts is produced by the
+// MessageProducers, or else the MessageHandlers, for its description, and
+// its contents are matched against the example and matching rules of the
+// pact. The metadata of messages produced by MessageProducers is matched
+// likewise.
+//
+// Synchronous (request/reply) messages of version 4 pacts are verified in the
+// same way: the producer is given the example request as its Content, and
+// the contents (and metadata) it produces are matched with the reply.
+//
+// Pacts are read from PactURLs and, if a BrokerURL is given, the pacts the
+// broker selects for the Provider. With PublishVerificationResults, the
+// results of the pacts fetched from the broker are published to it, along
+// with the ProviderTags and ProviderBranch. Results are reported in the form
+// of the verifier's output.
+func (p *Pact) VerifyMessageProviderNativeRaw(request VerifyMessageRequest) ([]types.ProviderVerifierResponse, error) {
+	res, err := p.verifyMessageProviderNative(request)
+
+	return res, writeVerificationSummary(request.SummaryFile, res, err)
+}
+
+func (p *Pact) verifyMessageProviderNative(request VerifyMessageRequest) ([]types.ProviderVerifierResponse, error) {
+	detectVersion(request.VersionDetection, &request.ProviderVersion, &request.ProviderBranch)
+	checkDeprecatedSelectors(request.ConsumerVersionSelectors)
+	verifyRequest := request.verifyRequest(p.Provider)
+
+	if request.PublishVerificationResults && request.ProviderVersion == "" {
+		return nil, errors.New("'ProviderVersion' must be supplied if 'PublishVerificationResults' is set")
+	}
+
+	pacts := make([]PactForVerification, 0, len(request.PactURLs))
+	for _, location := range request.PactURLs {
+		pacts = append(pacts, PactForVerification{URL: location})
+	}
+	if request.BrokerURL != "" {
+		selected, err := pactsForVerification(verifyRequest)
+		if err != nil {
+			return nil, err
+		}
+		pacts = append(pacts, selected...)
+	}
+	if len(pacts) == 0 {
+		return nil, errors.New("One of 'PactURLs' or 'BrokerURL' must be specified")
+	}
+
+	producers, withMetadata := request.messageProducers()
+	res := make([]types.ProviderVerifierResponse, 0, len(pacts))
+	failed := 0
+	for _, pact := range pacts {
+		content, err := readPactContent(pact.URL, verifyRequest)
+		if err != nil {
+			return res, fmt.Errorf("unable to read pact %s: %v", pact.URL, err)
+		}
+
+		verifier := messageVerifier{
+			provider:      p.Provider,
+			producers:     producers,
+			withMetadata:  withMetadata,
+			stateHandlers: request.StateHandlers,
+		}
+		response, failures, err := verifier.verifyPact(pact, content)
+		if err != nil {
+			return res, fmt.Errorf("unable to verify pact %s: %v", pact.URL, err)
+		}
+		res = append(res, response)
+		if !pact.Pending && !pact.WIP {
+			failed += failures
+		}
+
+		if request.PublishVerificationResults {
+			if err = publishMessageVerification(content, failures == 0, request, verifyRequest); err != nil {
+				return res, fmt.Errorf("unable to publish the verification results of pact %s: %v", pact.URL, err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return res, fmt.Errorf("%d messages failed verification", failed)
+	}
+
+	return res, nil
+}
+
+// messageProducers are the producers of the messages: the MessageProducers,
+// and the MessageHandlers for other descriptions, which produce no metadata.
+// The descriptions whose metadata is produced, and so verified, are
+// returned. Messages are round tripped through the MessageBroker, if given.
+func (v *VerifyMessageRequest) messageProducers() (MessageProducers, map[string]bool) {
+	producers := make(MessageProducers, len(v.MessageHandlers)+len(v.MessageProducers))
+	withMetadata := make(map[string]bool, len(v.MessageProducers))
+
+	handlers := v.MessageHandlers
+	if v.MessageBroker != nil {
+		handlers = brokerMessageHandlers(handlers, v.MessageBroker)
+	}
+	for description, handler := range handlers {
+		handler := handler
+		producers[description] = func(m Message) (interface{}, map[string]interface{}, error) {
+			contents, err := handler(m)
+			return contents, nil, err
+		}
+	}
+
+	for description, producer := range v.MessageProducers {
+		description, producer := description, producer
+		withMetadata[description] = true
+		if v.MessageBroker == nil {
+			producers[description] = producer
+			continue
+		}
+		producers[description] = func(m Message) (interface{}, map[string]interface{}, error) {
+			var metadata map[string]interface{}
+			handler := brokerMessageHandlers(MessageHandlers{description: func(m Message) (interface{}, error) {
+				contents, produced, err := producer(m)
+				metadata = produced
+				return contents, err
+			}}, v.MessageBroker)[description]
+			contents, err := handler(m)
+			return contents, metadata, err
+		}
+	}
+
+	return producers, withMetadata
+}
+
+// messageVerifier verifies the messages of a pact
+type messageVerifier struct {
+	provider      string
+	producers     MessageProducers
+	withMetadata  map[string]bool
+	stateHandlers StateHandlers
+}
+
+// verifiedMessage is the outcome of verifying a message, in the form of an
+// example of the verifier's output
+type verifiedMessage struct {
+	Description     string   `json:"description"`
+	FullDescription string   `json:"full_description"`
+	Status          string   `json:"status"`
+	Mismatches      []string `json:"mismatches,omitempty"`
+	Pact            struct {
+		ConsumerName string `json:"consumer_name"`
+		ProviderName string `json:"provider_name"`
+		URL          string `json:"url"`
+		Pending      bool   `json:"pending,omitempty"`
+		WIP          bool   `json:"wip,omitempty"`
+	} `json:"pact"`
+	Exception struct {
+		Message string `json:"message"`
+	} `json:"exception"`
+}
+
+// verifyPact verifies each message of the pact, returning the results and
+// the number of messages that failed
+func (m messageVerifier) verifyPact(pact PactForVerification, content []byte) (types.ProviderVerifierResponse, int, error) {
+	var res types.ProviderVerifierResponse
+
+	synchronous, content, err := synchronousMessages(content)
+	if err != nil {
+		return res, 0, err
+	}
+	converted, err := pactfile.ConvertSpecification(content, 3)
+	if err != nil {
+		return res, 0, err
+	}
+	parsed, err := pactfile.Parse(converted)
+	if err != nil {
+		return res, 0, err
+	}
+	if len(parsed.Messages) == 0 && len(synchronous) == 0 {
+		log.Printf("[WARN] pact %s has no messages to verify\n", pact.URL)
+	}
+
+	examples := make([]verifiedMessage, 0, len(parsed.Messages)+len(synchronous))
+	failed, pending := 0, 0
+	verify := func(description string, providerStates json.RawMessage, verifyMessage func([]State) []string) error {
+		var states []State
+		if len(providerStates) > 0 {
+			if err := json.Unmarshal(providerStates, &states); err != nil {
+				return fmt.Errorf("message '%s' has invalid provider states: %v", description, err)
+			}
+		}
+
+		given := ""
+		if len(states) > 0 {
+			names := make([]string, len(states))
+			for n, state := range states {
+				names[n] = state.Name
+			}
+			given = " Given " + strings.Join(names, " and ")
+		}
+
+		example := verifiedMessage{
+			Description:     "has matching content",
+			FullDescription: fmt.Sprintf("Verifying a pact between %s and %s%s %s has matching content", parsed.Consumer.Name, m.provider, given, description),
+			Status:          "passed",
+		}
+		example.Pact.ConsumerName = parsed.Consumer.Name
+		example.Pact.ProviderName = m.provider
+		example.Pact.URL = pact.URL
+		example.Pact.Pending = pact.Pending
+		example.Pact.WIP = pact.WIP
+
+		if mismatches := verifyMessage(states); len(mismatches) > 0 {
+			example.Status = "failed"
+			if pact.Pending || pact.WIP {
+				example.Status = "pending"
+				pending++
+			}
+			example.Mismatches = mismatches
+			example.Exception.Message = strings.Join(mismatches, "\n")
+			failed++
+		}
+		examples = append(examples, example)
+
+		return nil
+	}
+
+	for _, message := range parsed.Messages {
+		message := message
+		if err = verify(message.Description, message.ProviderStates, func(states []State) []string {
+			return m.verifyMessage(message, states)
+		}); err != nil {
+			return res, 0, err
+		}
+	}
+	for _, message := range synchronous {
+		message := message
+		if err = verify(message.Description, message.ProviderStates, func(states []State) []string {
+			return m.verifySynchronousMessage(message, states)
+		}); err != nil {
+			return res, 0, err
+		}
+	}
+
+	document, err := json.Marshal(map[string]interface{}{
+		"examples": examples,
+		"summary": map[string]interface{}{
+			"example_count": len(examples),
+			"failure_count": failed - pending,
+			"pending_count": pending,
+		},
+		"summary_line": fmt.Sprintf("%d examples, %d failures", len(examples), failed-pending),
+	})
+	if err != nil {
+		return res, 0, err
+	}
+	if err = json.Unmarshal(document, &res); err != nil {
+		return res, 0, err
+	}
+
+	return res, failed, nil
+}
+
+// verifyMessage sets up the provider states of the message, produces it and
+// matches it with the pact, returning the differences
+func (m messageVerifier) verifyMessage(message pactfile.Message, states []State) []string {
+	if failure := m.setupStates(states); failure != "" {
+		return []string{failure}
+	}
+
+	producer, ok := m.producers[message.Description]
+	if !ok {
+		return []string{fmt.Sprintf("no message producer or handler for message '%s'", message.Description)}
+	}
+	contents, metadata, err := producer(Message{Description: message.Description, States: states})
+	if err != nil {
+		return []string{fmt.Sprintf("the producer of message '%s' failed: %v", message.Description, err)}
+	}
+
+	mismatches, err := messageMismatches(message, contents, metadata, m.withMetadata[message.Description])
+	if err != nil {
+		return []string{fmt.Sprintf("unable to match message '%s': %v", message.Description, err)}
+	}
+
+	return mismatches
+}
+
+// verifySynchronousMessage sets up the provider states of the message,
+// produces the reply to its example request and matches it with the first
+// reply of the pact, returning the differences
+func (m messageVerifier) verifySynchronousMessage(message pactfile.Interaction, states []State) []string {
+	if failure := m.setupStates(states); failure != "" {
+		return []string{failure}
+	}
+
+	producer, ok := m.producers[message.Description]
+	if !ok {
+		return []string{fmt.Sprintf("no message producer or handler for message '%s'", message.Description)}
+	}
+	var request interface{}
+	if message.MessageRequest != nil && len(message.MessageRequest.Contents) > 0 {
+		if err := json.Unmarshal(v4Contents(message.MessageRequest.Contents), &request); err != nil {
+			return []string{fmt.Sprintf("message '%s' has an invalid request: %v", message.Description, err)}
+		}
+	}
+	contents, metadata, err := producer(Message{Description: message.Description, States: states, Content: request})
+	if err != nil {
+		return []string{fmt.Sprintf("the producer of message '%s' failed: %v", message.Description, err)}
+	}
+	if len(message.MessageResponses) == 0 {
+		return nil
+	}
+
+	mismatches, err := messageMismatches(messageContents(message.Description, message.MessageResponses[0]), contents, metadata, m.withMetadata[message.Description])
+	if err != nil {
+		return []string{fmt.Sprintf("unable to match message '%s': %v", message.Description, err)}
+	}
+
+	return mismatches
+}
+
+// setupStates runs the state handlers of the provider states, returning the
+// failure of the first that fails
+func (m messageVerifier) setupStates(states []State) string {
+	for _, state := range states {
+		handler, ok := m.stateHandlers[state.Name]
+		if !ok {
+			log.Printf("[WARN] state handler not found for state: %v", state.Name)
+			continue
+		}
+		if err := handler(state); err != nil {
+			return fmt.Sprintf("state handler for '%s' failed: %v", state.Name, err)
+		}
+	}
+
+	return ""
+}
+
+// synchronousMessages separates the synchronous messages of a pact, which
+// only version 4 pacts have, from the rest of the pact, which may then be
+// converted to an earlier version
+func synchronousMessages(content []byte) ([]pactfile.Interaction, []byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid pact file: %v", err)
+	}
+	var interactions []json.RawMessage
+	if raw, ok := doc["interactions"]; ok {
+		if err := json.Unmarshal(raw, &interactions); err != nil {
+			return nil, nil, fmt.Errorf("invalid pact file: %v", err)
+		}
+	}
+
+	var rest []json.RawMessage
+	for _, raw := range interactions {
+		var interaction struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(raw, &interaction) == nil && strings.HasPrefix(interaction.Type, "Synchronous/Messages") {
+			continue
+		}
+		rest = append(rest, raw)
+	}
+	if len(rest) == len(interactions) {
+		return nil, content, nil
+	}
+
+	converted, err := pactfile.ConvertSpecification(content, 4)
+	if err != nil {
+		return nil, nil, err
+	}
+	parsed, err := pactfile.Parse(converted)
+	if err != nil {
+		return nil, nil, err
+	}
+	var synchronous []pactfile.Interaction
+	for _, interaction := range parsed.Interactions {
+		if interaction.IsSynchronousMessage() {
+			synchronous = append(synchronous, interaction)
+		}
+	}
+
+	if doc["interactions"], err = json.Marshal(rest); err != nil {
+		return nil, nil, err
+	}
+	content, err = json.Marshal(doc)
+
+	return synchronous, content, err
+}
+
+// messageMismatches matches the produced contents, and metadata if
+// produced, with the examples and matching rules of the message. Contents
+// differences are written in the verifier's form e.g.
+// `* expected "Mary" at $.name`.
+func messageMismatches(message pactfile.Message, contents interface{}, metadata map[string]interface{}, withMetadata bool) ([]string, error) {
+	rules, err := messageRules(message.MatchingRules)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []string
+	if len(message.Contents) > 0 && string(message.Contents) != "null" {
+		actual, err := json.Marshal(contents)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialise the contents: %v", err)
+		}
+		explanations, err := ExplainRules(rules, BodyPath(), message.Contents, actual)
+		if err != nil {
+			return nil, err
+		}
+		for _, explanation := range explanations {
+			if !explanation.Passed {
+				mismatches = append(mismatches, fmt.Sprintf("* %s at $%s", explanation.Message, strings.TrimPrefix(explanation.Path, "$.body")))
+			}
+		}
+
+		expectedDoc, _ := decodeJSON(message.Contents)
+		actualDoc, _ := decodeJSON(actual)
+		mismatches = append(mismatches, missingKeys(expectedDoc, actualDoc, NewRulePath())...)
+	}
+
+	if !withMetadata || len(message.Metadata) == 0 {
+		return mismatches, nil
+	}
+
+	var expected map[string]json.RawMessage
+	if err = json.Unmarshal(message.Metadata, &expected); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %v", err)
+	}
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := metadata[name]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("metadata %s: expected %s but it was missing", name, expected[name]))
+			continue
+		}
+		actual, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialise metadata %s: %v", name, err)
+		}
+		explanations, err := ExplainRules(rules, MetadataPath(name), expected[name], actual)
+		if err != nil {
+			return nil, err
+		}
+		for _, explanation := range explanations {
+			if !explanation.Passed {
+				mismatches = append(mismatches, fmt.Sprintf("metadata %s: %s", name, explanation.Message))
+			}
+		}
+	}
+
+	return mismatches, nil
+}
+
+// messageRules flattens the version 3 matching rules of a message, keyed by
+// category, to rules with paths relative to the message e.g. "$.body.id"
+// and "$.metadata.contentType"
+func messageRules(raw json.RawMessage) (MatchingRules, error) {
+	rules := MatchingRules{}
+	if len(raw) == 0 {
+		return rules, nil
+	}
+
+	var nested map[string]map[string]Rule
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		return nil, fmt.Errorf("invalid matching rules: %v", err)
+	}
+	for path, rule := range nested["body"] {
+		rules["$.body"+strings.TrimPrefix(path, "$")] = rule
+	}
+	for name, rule := range nested["metadata"] {
+		rules[MetadataPath(name).String()] = rule
+	}
+
+	return rules, nil
+}
+
+// missingKeys reports the keys of the expected objects that are missing
+// from the actual document. Elements of arrays are compared with the first
+// expected element.
+func missingKeys(expected interface{}, actual interface{}, path RulePath) []string {
+	var missing []string
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		object, ok := actual.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		keys := make([]string, 0, len(e))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := object[key]
+			if !ok {
+				missing = append(missing, fmt.Sprintf("* Could not find key %q at %s", key, path.Key(key)))
+				continue
+			}
+			missing = append(missing, missingKeys(e[key], value, path.Key(key))...)
+		}
+	case []interface{}:
+		items, ok := actual.([]interface{})
+		if !ok || len(e) == 0 {
+			return nil
+		}
+		for n, item := range items {
+			element := e[0]
+			if n < len(e) {
+				element = e[n]
+			}
+			missing = append(missing, missingKeys(element, item, path.Index(n))...)
+		}
+	}
+
+	return missing
+}
+
+// publishMessageVerification publishes the result of verifying a pact
+// fetched from the broker, after tagging the provider version and recording
+// its branch
+func publishMessageVerification(content []byte, success bool, request VerifyMessageRequest, verifyRequest types.VerifyRequest) error {
+	var links struct {
+		Links map[string]struct {
+			Href string `json:"href"`
+		} `json:"_links"`
+	}
+	if err := json.Unmarshal(content, &links); err != nil {
+		return err
+	}
+	publish := links.Links["pb:publish-verification-results"].Href
+	if publish == "" {
+		log.Println("[WARN] the pact has no link to publish verification results, it was not fetched from a broker")
+		return nil
+	}
+
+	if request.BrokerURL != "" {
+		version := strings.TrimSuffix(request.BrokerURL, "/") + "/pacticipants/" + url.PathEscape(verifyRequest.Provider) + "/versions/" + url.PathEscape(request.ProviderVersion)
+		for _, tag := range request.ProviderTags {
+			if _, err := brokerRequest(context.Background(), "PUT", version+"/tags/"+url.PathEscape(tag), []byte("{}"), verifyRequest.BrokerAuth()); err != nil {
+				return fmt.Errorf("unable to tag the provider version with %s: %v", tag, err)
+			}
+		}
+		if request.ProviderBranch != "" {
+			branch := strings.TrimSuffix(request.BrokerURL, "/") + "/pacticipants/" + url.PathEscape(verifyRequest.Provider) + "/branches/" + url.PathEscape(request.ProviderBranch) + "/versions/" + url.PathEscape(request.ProviderVersion)
+			if _, err := brokerRequest(context.Background(), "PUT", branch, []byte("{}"), verifyRequest.BrokerAuth()); err != nil {
+				return fmt.Errorf("unable to record the provider branch %s: %v", request.ProviderBranch, err)
+			}
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"success":                    success,
+		"providerApplicationVersion": request.ProviderVersion,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = brokerPost(publish, body, verifyRequest.BrokerAuth())
+
+	return err
+}
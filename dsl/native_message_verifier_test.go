@@ -0,0 +1,189 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeOrderMessagePact writes a message pact for an order created event to
+// the directory, returning its file
+func writeOrderMessagePact(t *testing.T, dir string) string {
+	pact := NewNativeMessagePact("order-consumer", "order-service")
+	pact.PactDir = dir
+
+	message := pact.AddMessage().
+		Given("order 1 exists").
+		ExpectsToReceive("an order created event").
+		WithMetadata(MapMatcher{"topic": Term("orders.created", `^orders\.`)}).
+		WithContent(StructMatcher{"id": Like(1), "items": EachLike("ABC", 2)})
+	if err := pact.VerifyMessageRaw(message, func(Message) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := pact.WritePact(); err != nil {
+		t.Fatal(err)
+	}
+
+	return filepath.Join(dir, "order-consumer-order-service.json")
+}
+
+func TestPact_VerifyMessageProviderNative(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := writeOrderMessagePact(t, dir)
+
+	var states []string
+	request := VerifyMessageRequest{
+		PactURLs: []string{file},
+		StateHandlers: StateHandlers{
+			"order 1 exists": func(s State) error {
+				states = append(states, s.Name)
+				return nil
+			},
+		},
+		MessageProducers: MessageProducers{
+			"an order created event": func(m Message) (interface{}, map[string]interface{}, error) {
+				return orderCreated{ID: 42, Items: []string{"X", "Y", "Z"}}, map[string]interface{}{"topic": "orders.v2"}, nil
+			},
+		},
+	}
+
+	pact := &Pact{Provider: "order-service"}
+	res, err := pact.VerifyMessageProviderNativeRaw(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(states) != 1 || len(res) != 1 || len(res[0].Examples) != 1 || res[0].Examples[0].Status != "passed" {
+		t.Fatalf("expected the message to pass after its state was set up, got %+v and states %v", res, states)
+	}
+	if want := "Verifying a pact between order-consumer and order-service Given order 1 exists an order created event has matching content"; res[0].Examples[0].FullDescription != want {
+		t.Fatalf("want %q, got %q", want, res[0].Examples[0].FullDescription)
+	}
+
+	request.MessageProducers = MessageProducers{
+		"an order created event": func(m Message) (interface{}, map[string]interface{}, error) {
+			return map[string]interface{}{"id": "42"}, map[string]interface{}{"topic": "invoices"}, nil
+		},
+	}
+	res, err = pact.VerifyMessageProviderNativeRaw(request)
+	if err == nil || err.Error() != "1 messages failed verification" {
+		t.Fatalf("expected the message to fail, got %v", err)
+	}
+	message := res[0].Examples[0].Exception.Message
+	for _, want := range []string{
+		`* expected a number (1) but got a string ("42") at $.id`,
+		`* Could not find key "items" at $.items`,
+		`metadata topic: expected a value matching "^orders\\."`,
+	} {
+		if !strings.Contains(message, want) {
+			t.Fatalf("expected %q in %q", want, message)
+		}
+	}
+	if mismatches := res[0].Mismatches(); len(mismatches) != 1 || len(mismatches[0].Mismatches) != 2 {
+		t.Fatalf("expected the contents differences to be parsed, got %+v", mismatches)
+	}
+
+	// Handlers produce no metadata, so it isn't verified
+	request.MessageProducers = nil
+	request.MessageHandlers = MessageHandlers{
+		"an order created event": func(m Message) (interface{}, error) {
+			return orderCreated{ID: 7, Items: []string{"A", "B"}}, nil
+		},
+	}
+	if _, err = pact.VerifyMessageProviderNativeRaw(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	request.MessageHandlers["an order created event"] = func(m Message) (interface{}, error) {
+		return orderCreated{ID: 7, Items: []string{"A"}}, nil
+	}
+	if res, err = pact.VerifyMessageProviderNativeRaw(request); err == nil || !strings.Contains(res[0].Examples[0].Exception.Message, "at $.items") {
+		t.Fatalf("expected too few items to fail, got %v", err)
+	}
+
+	request.MessageHandlers = nil
+	res, err = pact.VerifyMessageProviderNativeRaw(request)
+	if err == nil || res[0].Examples[0].Exception.Message != "no message producer or handler for message 'an order created event'" {
+		t.Fatalf("expected the message without a producer to fail, got %v", err)
+	}
+}
+
+func TestPact_VerifyMessageProviderNativePublish(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	content, err := ioutil.ReadFile(writeOrderMessagePact(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var requests []string
+	var published map[string]interface{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/pacts/provider/order-service/for-verification":
+			fmt.Fprintf(w, `{"_embedded": {"pacts": [{"_links": {"self": {"href": "%s/pacts/1"}}}]}}`, server.URL)
+		case "/pacts/1":
+			var pact map[string]interface{}
+			json.Unmarshal(content, &pact) // nolint:errcheck
+			pact["_links"] = map[string]interface{}{"pb:publish-verification-results": map[string]string{"href": server.URL + "/pacts/1/verification-results"}}
+			json.NewEncoder(w).Encode(pact) // nolint:errcheck
+		case "/pacts/1/verification-results":
+			json.Unmarshal(body, &published) // nolint:errcheck
+			w.Write([]byte("{}"))            // nolint:errcheck
+		default:
+			w.Write([]byte("{}")) // nolint:errcheck
+		}
+	}))
+	defer server.Close()
+
+	pact := &Pact{Provider: "order-service"}
+	_, err = pact.VerifyMessageProviderNativeRaw(VerifyMessageRequest{
+		BrokerURL:                  server.URL,
+		PublishVerificationResults: true,
+		ProviderVersion:            "1.0.0",
+		ProviderTags:               []string{"prod"},
+		ProviderBranch:             "main",
+		MessageHandlers: MessageHandlers{
+			"an order created event": func(m Message) (interface{}, error) {
+				return orderCreated{ID: 1, Items: []string{"A", "B"}}, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"POST /pacts/provider/order-service/for-verification",
+		"GET /pacts/1",
+		"PUT /pacticipants/order-service/versions/1.0.0/tags/prod",
+		"PUT /pacticipants/order-service/branches/main/versions/1.0.0",
+		"POST /pacts/1/verification-results",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("want requests %v, got %v", want, requests)
+	}
+	if published["success"] != true || published["providerApplicationVersion"] != "1.0.0" {
+		t.Fatalf("unexpected verification results %v", published)
+	}
+}
@@ -0,0 +1,600 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// NativeMockServer is an in-process mock provider, for consumer tests that
+// don't use the Ruby mock service. Interactions built with the matcher DSL
+// are registered with AddInteraction, and each request the server receives
+// is matched against them in the order they were added: the method, path,
+// query, headers and body must satisfy the examples and matching rules the
+// interaction is written to the pact file with, those of its matchers and
+// any given explicitly, and the example response of the first matching
+// interaction is returned. Requests matching no interaction are answered
+// with a 500 and recorded.
+//
+// Verify reports the requests that matched no interaction, and the
+// interactions that weren't requested, as typed mismatches. Pact returns the
+// interactions as a version 3 pact, to write with pactfile.Pact.Write.
+//
+// Bodies may be built with matchers or JSONBody, or be plain strings;
+// MessagePack bodies are not supported.
+type NativeMockServer struct {
+	*httptest.Server
+
+	// Limits on the size of requests and the time taken to receive them. Set
+	// before sending requests, and the Timeout before starting the server,
+	// see NewUnstartedNativeMockServer.
+	Limits MockServerLimits
+
+	// Events are called as the server starts, stops and matches requests.
+	// Set before starting the server, see NewUnstartedNativeMockServer.
+	Events MockServerEvents
+
+	mu           sync.Mutex
+	interactions []*nativeInteraction
+	unexpected   []types.InteractionMismatches
+
+	lifecycle sync.Mutex
+	running   bool
+	stopped   bool
+}
+
+// nativeInteraction is a registered interaction and the number of requests
+// it has matched
+type nativeInteraction struct {
+	*Interaction
+	received int
+}
+
+// NewNativeMockServer starts a mock server, listening on a local port given
+// by its URL. Close it when done.
+func NewNativeMockServer() *NativeMockServer {
+	s := NewUnstartedNativeMockServer()
+	s.Start() // nolint:errcheck
+
+	return s
+}
+
+// AddInteraction registers an interaction, returning an error if it is
+// invalid or not supported by the native mock server
+func (s *NativeMockServer) AddInteraction(interaction *Interaction) error {
+	if err := validateNative(interaction, "the native mock server"); err != nil {
+		return err
+	}
+	s.add(interaction)
+
+	return nil
+}
+
+func (s *NativeMockServer) add(interaction *Interaction) {
+	s.mu.Lock()
+	s.interactions = append(s.interactions, &nativeInteraction{Interaction: interaction})
+	s.mu.Unlock()
+}
+
+// validateNative returns an error if the interaction is invalid, or can't be
+// serialised by the server
+func validateNative(interaction *Interaction, server string) error {
+	if err := interaction.Validate(); err != nil {
+		return err
+	}
+
+	return nativeSupportError(interaction, server)
+}
+
+// nativeSupportError returns an error if the interaction can't be
+// serialised by the server
+func nativeSupportError(interaction *Interaction, server string) error {
+	for _, body := range []interface{}{interaction.Request.Body, interaction.Response.Body} {
+		if _, ok := body.(*MsgPackBodyBuilder); ok {
+			return fmt.Errorf("interaction '%s': %T bodies are not supported by %s", interaction.Description, body, server)
+		}
+	}
+
+	return nil
+}
+
+// Reset forgets the registered interactions and the requests received
+func (s *NativeMockServer) Reset() {
+	s.mu.Lock()
+	s.interactions = nil
+	s.unexpected = nil
+	s.mu.Unlock()
+}
+
+// Verify returns a *MismatchError if a request matched no interaction, or
+// an interaction was not requested, and nil otherwise
+func (s *NativeMockServer) Verify() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mismatches := append([]types.InteractionMismatches{}, s.unexpected...)
+	for _, i := range s.interactions {
+		if i.received == 0 {
+			path, _ := exampleOf(i.Request.Path).(string)
+			mismatches = append(mismatches, types.InteractionMismatches{
+				Description: i.Description,
+				Mismatches:  []types.Mismatch{types.RequestMismatch{Method: strings.ToUpper(i.Request.Method), Path: path, Message: "expected request was not received"}},
+			})
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	lines := []string{"the mock server did not receive the expected requests:"}
+	for _, line := range describeMismatches(mismatches) {
+		lines = append(lines, "  "+line)
+	}
+
+	return &MismatchError{Message: strings.Join(lines, "\n"), Mismatches: mismatches}
+}
+
+// UnexpectedRequests returns the mismatches of the requests received that
+// matched no interaction. Unlike Verify, interactions that have not (yet)
+// been requested are ignored.
+func (s *NativeMockServer) UnexpectedRequests() []types.InteractionMismatches {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]types.InteractionMismatches{}, s.unexpected...)
+}
+
+// describeMismatches describes each mismatch on a line, prefixed with the
+// description of its interaction if known
+func describeMismatches(mismatches []types.InteractionMismatches) []string {
+	var lines []string
+	for _, m := range mismatches {
+		for _, mismatch := range m.Mismatches {
+			if m.Description == "" {
+				lines = append(lines, mismatch.String())
+			} else {
+				lines = append(lines, fmt.Sprintf("'%s': %s", m.Description, mismatch))
+			}
+		}
+	}
+
+	return lines
+}
+
+// ServeHTTP answers the request with the response of the first matching
+// interaction
+func (s *NativeMockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	matched := s.serve(w, r)
+	if matched != nil && s.Events.Matched != nil {
+		s.Events.Matched(matched)
+	}
+}
+
+// serve answers the request, returning the interaction it matched if any
+func (s *NativeMockServer) serve(w http.ResponseWriter, r *http.Request) *Interaction {
+	body, status, exceeded := s.Limits.read(r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if exceeded != nil {
+		s.unexpected = append(s.unexpected, types.InteractionMismatches{Mismatches: []types.Mismatch{*exceeded}})
+		http.Error(w, exceeded.String(), status)
+		return nil
+	}
+
+	var diffs []types.InteractionMismatches
+	for _, i := range s.interactions {
+		if !strings.EqualFold(i.Request.Method, r.Method) {
+			continue
+		}
+		expected, err := i.expectedRequest()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil
+		}
+		if pathDiffs, err := matchRules(expected.rules, RequestPathPath(), expected.path, r.URL.Path); err != nil || len(pathDiffs) > 0 {
+			continue
+		}
+
+		mismatches, err := expected.mismatches(r, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil
+		}
+		if len(mismatches) == 0 {
+			i.received++
+			i.respond(w)
+			return i.Interaction
+		}
+		diffs = append(diffs, types.InteractionMismatches{Description: i.Description, Mismatches: mismatches})
+	}
+
+	if len(diffs) == 0 {
+		diffs = []types.InteractionMismatches{{
+			Mismatches: []types.Mismatch{types.RequestMismatch{Method: r.Method, Path: r.URL.Path, Message: "no interaction expected this method and path"}},
+		}}
+	}
+	s.unexpected = append(s.unexpected, diffs...)
+	log.Printf("[WARN] native mock server: no interaction matched %s %s\n", r.Method, r.URL.RequestURI())
+
+	var messages []string
+	for _, diff := range diffs {
+		for _, mismatch := range diff.Mismatches {
+			messages = append(messages, mismatch.String())
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]interface{}{ // nolint:errcheck
+		"message":    fmt.Sprintf("No interaction found for %s %s", r.Method, r.URL.RequestURI()),
+		"mismatches": messages,
+	})
+
+	return nil
+}
+
+// matchString matches an actual string, e.g. a path or header, with an
+// expected matcher
+func matchString(expected interface{}, actual string) []types.BodyMismatch {
+	m := &bodyMatcher{}
+	if err := m.match(expected, actual, NewRulePath(), false); err != nil {
+		return []types.BodyMismatch{{Message: err.Error()}}
+	}
+
+	return m.mismatches
+}
+
+// expectedRequest is the example of an interaction's request, as written
+// to the pact file, with the matching rules of its matchers and any given
+// explicitly
+type expectedRequest struct {
+	*nativeInteraction
+	path    interface{}
+	query   map[string][]string
+	headers map[string]interface{}
+	body    interface{}
+	rules   MatchingRules
+}
+
+// expectedRequest serialises the request of the interaction
+func (i *nativeInteraction) expectedRequest() (*expectedRequest, error) {
+	rules := MatchingRules{}
+	expected := &expectedRequest{
+		nativeInteraction: i,
+		path:              serialiseMatcher(RequestPathPath(), i.Request.Path, rules),
+		query:             make(map[string][]string, len(i.Request.Query)),
+		headers:           serialiseHeaders(i.Request.Headers, rules),
+		rules:             rules,
+	}
+	for key, value := range i.Request.Query {
+		example := serialiseMatcher(QueryPath(key), value, rules)
+		values, ok := example.([]interface{})
+		if !ok {
+			values = []interface{}{example}
+		}
+		for _, v := range values {
+			expected.query[key] = append(expected.query[key], fmt.Sprint(v))
+		}
+	}
+	for path, rule := range mergeJSONBodyRules(i.Request.Body, i.Request.MatchingRules) {
+		rules[path] = rule
+	}
+
+	// Compare the body in its JSON form
+	if body := serialiseBody(i.Request.Body, rules); body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialise the body of interaction '%s': %v", i.Description, err)
+		}
+		if expected.body, err = decodeJSON(content); err != nil {
+			return nil, fmt.Errorf("unable to serialise the body of interaction '%s': %v", i.Description, err)
+		}
+	}
+
+	return expected, nil
+}
+
+// mismatches compares the query, headers, trailers and body of the request
+// with the interaction
+func (e *expectedRequest) mismatches(r *http.Request, body []byte) ([]types.Mismatch, error) {
+	var mismatches []types.Mismatch
+
+	query := r.URL.Query()
+	for _, key := range sortedQueryKeys(e.query) {
+		matched, err := e.queryMatches(key, query[key])
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			mismatches = append(mismatches, types.QueryMismatch{Key: key, Expected: strings.Join(e.query[key], ","), Actual: strings.Join(query[key], ",")})
+		}
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := e.query[key]; !ok {
+			mismatches = append(mismatches, types.QueryMismatch{Key: key, Actual: strings.Join(query[key], ",")})
+		}
+	}
+
+	for _, name := range sortedKeys(e.Request.Headers) {
+		expected := fmt.Sprint(e.headers[name])
+		values, ok := r.Header[http.CanonicalHeaderKey(name)]
+		actual := strings.Join(values, ", ")
+		if !ok {
+			mismatches = append(mismatches, types.HeaderMismatch{Key: name, Expected: expected})
+			continue
+		}
+		diffs, err := matchRules(e.rules, HeaderPath(name), expected, actual)
+		if err != nil {
+			return nil, err
+		}
+		if len(diffs) > 0 {
+			mismatches = append(mismatches, types.HeaderMismatch{Key: name, Expected: expected, Actual: actual, Rule: diffs[0].Rule})
+		}
+	}
+
+	mismatches = append(mismatches, trailerMismatches(e.Request.Trailers, r.Trailer)...)
+
+	bodyMismatches, err := e.bodyMismatches(body)
+	if err != nil {
+		return nil, err
+	}
+	for _, mismatch := range bodyMismatches {
+		mismatches = append(mismatches, mismatch)
+	}
+
+	return mismatches, nil
+}
+
+func (e *expectedRequest) bodyMismatches(body []byte) ([]types.BodyMismatch, error) {
+	switch expected := e.Request.Body.(type) {
+	case nil:
+		return nil, nil
+	case BodyExpectation:
+		if expected != AnyBody && len(body) > 0 {
+			return []types.BodyMismatch{{Path: "$", Actual: string(body), Message: "Expected " + string(expected)}}, nil
+		}
+		return nil, nil
+	case []byte:
+		return matchString(string(expected), string(body)), nil
+	}
+
+	if text, ok := plainString(e.Request.Body); ok {
+		return matchString(text, string(body)), nil
+	}
+	if len(body) == 0 {
+		return []types.BodyMismatch{{Path: "$", Message: "Expected a JSON body but got none"}}, nil
+	}
+	actual, err := decodeJSON(body)
+	if err != nil {
+		return []types.BodyMismatch{{Path: "$", Actual: string(body), Message: "Expected a JSON body"}}, nil
+	}
+
+	return matchRules(e.rules, BodyPath(), e.body, actual)
+}
+
+// queryMatches determines if the values of a query parameter match the
+// examples. A rule for the parameter applies to each value, which otherwise
+// must equal the examples in turn.
+func (e *expectedRequest) queryMatches(key string, values []string) (bool, error) {
+	expected := e.query[key]
+	_, ruled, err := e.rules.Resolve(QueryPath(key))
+	if err != nil {
+		return false, err
+	}
+	if len(values) == 0 || len(expected) == 0 || (!ruled && len(values) != len(expected)) {
+		return false, nil
+	}
+
+	for n, value := range values {
+		example := expected[len(expected)-1]
+		if n < len(expected) {
+			example = expected[n]
+		}
+		diffs, err := matchRules(e.rules, QueryPath(key), example, value)
+		if err != nil || len(diffs) > 0 {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// sortedQueryKeys returns the keys of the query in order
+func sortedQueryKeys(query map[string][]string) []string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// respond writes the example response of the interaction
+func (i *nativeInteraction) respond(w http.ResponseWriter) {
+	for name, value := range i.Response.Headers {
+		w.Header().Set(name, fmt.Sprint(exampleOf(value)))
+	}
+
+	var body []byte
+	switch b := i.Response.Body.(type) {
+	case nil, BodyExpectation:
+	case []byte:
+		body = b
+	case *JSONBodyBuilder:
+		body = b.raw
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+	default:
+		if text, ok := plainString(b); ok {
+			body = []byte(text)
+			break
+		}
+		body = []byte(jsonString(exampleOf(b)))
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+	}
+
+	writeWithTrailers(w, http.Header{}, i.Response.Status, body, trailerExamples(i.Response.Trailers))
+}
+
+// Pact returns the registered interactions as a version 3 pact between the
+// consumer and provider
+func (s *NativeMockServer) Pact(consumer, provider string) (*pactfile.Pact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, err := nativePact(consumer, provider, s.interactions)
+	if err != nil {
+		return nil, err
+	}
+
+	return pactfile.Parse(content)
+}
+
+// nativePact writes the interactions as a version 3 pact document
+func nativePact(consumer, provider string, registered []*nativeInteraction) ([]byte, error) {
+	content, err := nativePactDocument(consumer, provider, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	return pactfile.ConvertSpecification(content, 3)
+}
+
+// nativePactDocument writes the interactions as a version 2 pact document
+func nativePactDocument(consumer, provider string, registered []*nativeInteraction) ([]byte, error) {
+	interactions := make([]interface{}, len(registered))
+	for n, i := range registered {
+		interactions[n] = i.serialise()
+	}
+	return json.Marshal(map[string]interface{}{
+		"consumer":     map[string]string{"name": consumer},
+		"provider":     map[string]string{"name": provider},
+		"interactions": interactions,
+		"metadata":     map[string]interface{}{"pactSpecification": map[string]string{"version": "2.0.0"}},
+	})
+}
+
+// serialise writes the interaction in the version 2 pact form, with its
+// examples and the matching rules of its matchers
+func (i *nativeInteraction) serialise() map[string]interface{} {
+	requestRules := MatchingRules{}
+	request := map[string]interface{}{
+		"method": strings.ToUpper(i.Request.Method),
+		"path":   serialiseMatcher(RequestPathPath(), i.Request.Path, requestRules),
+	}
+	if len(i.Request.Query) > 0 {
+		query := url.Values{}
+		for key, value := range i.Request.Query {
+			example := serialiseMatcher(QueryPath(key), value, requestRules)
+			if values, ok := example.([]interface{}); ok {
+				for _, v := range values {
+					query.Add(key, fmt.Sprint(v))
+				}
+				continue
+			}
+			query.Add(key, fmt.Sprint(example))
+		}
+		request["query"] = query.Encode()
+	}
+	if len(i.Request.Headers) > 0 {
+		request["headers"] = serialiseHeaders(i.Request.Headers, requestRules)
+	}
+	if body := serialiseBody(i.Request.Body, requestRules); body != nil {
+		request["body"] = body
+	}
+	if len(i.Request.Trailers) > 0 {
+		request["trailers"] = serialiseTrailers(i.Request.Trailers, requestRules)
+	}
+	for path, rule := range mergeJSONBodyRules(i.Request.Body, i.Request.MatchingRules) {
+		requestRules[path] = rule
+	}
+	if len(requestRules) > 0 {
+		request["matchingRules"] = requestRules
+	}
+
+	responseRules := MatchingRules{}
+	response := map[string]interface{}{"status": i.Response.Status}
+	if len(i.Response.Headers) > 0 {
+		response["headers"] = serialiseHeaders(i.Response.Headers, responseRules)
+	}
+	if body := serialiseBody(i.Response.Body, responseRules); body != nil {
+		response["body"] = body
+	}
+	if len(i.Response.Trailers) > 0 {
+		response["trailers"] = serialiseTrailers(i.Response.Trailers, responseRules)
+	}
+	for path, rule := range mergeJSONBodyRules(i.Response.Body, i.Response.MatchingRules) {
+		responseRules[path] = rule
+	}
+	if len(responseRules) > 0 {
+		response["matchingRules"] = responseRules
+	}
+
+	interaction := map[string]interface{}{
+		"description": i.Description,
+		"request":     request,
+		"response":    response,
+	}
+	if len(i.States) > 0 {
+		interaction["providerStates"] = i.States
+	} else if i.State != "" {
+		interaction["providerState"] = i.State
+	}
+
+	return interaction
+}
+
+// serialiseMatcher returns the example of a matcher, adding its rules
+func serialiseMatcher(root RulePath, matcher interface{}, rules MatchingRules) interface{} {
+	example, matcherRules := pactBodyBuilder(root, matcher)
+	for path, rule := range matcherRules {
+		rules[path] = rule
+	}
+
+	return example
+}
+
+func serialiseHeaders(headers MapMatcher, rules MatchingRules) map[string]interface{} {
+	serialised := make(map[string]interface{}, len(headers))
+	for name, value := range headers {
+		serialised[name] = serialiseMatcher(HeaderPath(name), value, rules)
+	}
+
+	return serialised
+}
+
+func serialiseBody(body interface{}, rules MatchingRules) interface{} {
+	switch b := body.(type) {
+	case nil:
+		return nil
+	case BodyExpectation:
+		if b == AnyBody {
+			return nil
+		}
+		return ""
+	case *JSONBodyBuilder:
+		// The rules of a raw JSON body are merged with the explicit rules
+		return json.RawMessage(b.raw)
+	}
+
+	return serialiseMatcher(BodyPath(), body, rules)
+}
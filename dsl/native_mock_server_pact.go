@@ -0,0 +1,166 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// startNativeMockServer starts the in-process mock server on the port, in
+// place of the Ruby mock service
+func (p *Pact) startNativeMockServer(port int) {
+	log.Println("[DEBUG] starting native mock server on port:", port)
+	p.Server = &types.MockServer{Port: port}
+
+	listener, err := net.Listen(p.Network, fmt.Sprintf("%s:%d", p.Host, port))
+	if err != nil {
+		log.Println("[ERROR] unable to start native mock server:", err)
+		p.Server.Error = err
+		return
+	}
+
+	s := NewUnstartedNativeMockServer()
+	s.Listener.Close()
+	s.Listener = listener
+	s.Limits = p.Limits
+	if err = s.Start(); err != nil {
+		log.Println("[ERROR] unable to start native mock server:", err)
+		p.Server.Error = err
+		return
+	}
+	p.nativeServer = s
+}
+
+// verifyNative runs the test against the native mock server, keeping the
+// interactions to write to the pact file if it passes
+func (p *Pact) verifyNative(integrationTest func() error) error {
+	defer func() {
+		log.Println("[DEBUG] clearing interactions")
+		p.Interactions = make([]*Interaction, 0)
+		p.nativeServer.Reset()
+	}()
+
+	// The native mock server enforces the Limits itself, but the other
+	// features needing the proxy in front of the Ruby mock service would be
+	// silently ignored
+	var unsupported []string
+	for _, feature := range p.mockServerProxyFeatures() {
+		if feature != "Limits" {
+			unsupported = append(unsupported, feature)
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("not supported with UseNativeMockServer: %s", strings.Join(unsupported, ", "))
+	}
+
+	for _, interaction := range p.Interactions {
+		if err := p.resolvePlaceholders(interaction); err != nil {
+			return err
+		}
+		interaction.Request.Headers = mergeHeaders(withoutContentType(interaction.Request.Body, p.DefaultRequestHeaders), interaction.Request.Headers)
+		interaction.Response.Headers = mergeHeaders(withoutContentType(interaction.Response.Body, p.DefaultResponseHeaders), interaction.Response.Headers)
+
+		// The interactions have already been validated by Verify
+		if err := nativeSupportError(interaction, "the native mock server"); err != nil {
+			return err
+		}
+		p.nativeServer.add(interaction)
+
+		p.recordInteractionDetails(interaction)
+	}
+
+	if err := integrationTest(); err != nil {
+		return err
+	}
+	if err := p.nativeServer.Verify(); err != nil {
+		return err
+	}
+
+	for _, interaction := range p.Interactions {
+		p.addNativeInteraction(interaction)
+	}
+	p.recordVerified(p.Interactions)
+
+	return nil
+}
+
+// addNativeInteraction keeps a verified interaction to write to the pact
+// file, replacing an earlier one with the same description and state
+func (p *Pact) addNativeInteraction(interaction *Interaction) {
+	for n, existing := range p.nativeInteractions {
+		if existing.Description == interaction.Description && existing.State == interaction.State && jsonString(existing.States) == jsonString(interaction.States) {
+			p.nativeInteractions[n] = &nativeInteraction{Interaction: interaction}
+			return
+		}
+	}
+	p.nativeInteractions = append(p.nativeInteractions, &nativeInteraction{Interaction: interaction})
+}
+
+// writeNativePact writes the interactions verified with the native mock
+// server to the pact file, merging them with the existing file if the
+// PactFileWriteMode is "merge"
+func (p *Pact) writeNativePact() error {
+	version := p.SpecificationVersion
+	if version > 3 {
+		version = 3
+	}
+	content, err := nativePactDocument(p.Consumer, p.Provider, p.nativeInteractions)
+	if err == nil {
+		content, err = pactfile.ConvertSpecification(content, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(p.PactDir, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(p.PactDir, pactFileName(p.Consumer, p.Provider))
+	if _, err = os.Stat(file); err == nil && p.PactFileWriteMode == "merge" {
+		// Only pacts of the same version are merged
+		if err = convertPactFile(file, version); err != nil {
+			return err
+		}
+		if err = mergeNativePact(file, content); err != nil {
+			return err
+		}
+	} else if err = ioutil.WriteFile(file, content, 0644); err != nil {
+		return err
+	}
+
+	if err = p.completePactFile(file); err != nil {
+		return err
+	}
+
+	return writeSpecificationVersions(file, p.PactDir, p.AdditionalSpecificationVersions)
+}
+
+// mergeNativePact merges the pact content into the existing pact file
+func mergeNativePact(file string, content []byte) error {
+	written, err := ioutil.TempFile("", "native-pact")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(written.Name())
+	if _, err = written.Write(content); err != nil {
+		written.Close()
+		return err
+	}
+	if err = written.Close(); err != nil {
+		return err
+	}
+
+	merged, err := pactfile.Merge(file, written.Name())
+	if err != nil {
+		return err
+	}
+
+	return merged.Write(file)
+}
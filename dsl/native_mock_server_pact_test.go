@@ -0,0 +1,122 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+func TestPact_UseNativeMockServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-mock-server-pact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pact := &Pact{
+		Consumer:             "web",
+		Provider:             "orders",
+		PactDir:              dir,
+		SpecificationVersion: 3,
+		UseNativeMockServer:  true,
+	}
+	defer pact.Teardown()
+
+	for _, interaction := range nativeOrderInteractions() {
+		pact.AddInteraction().
+			Given(interaction.State).
+			UponReceiving(interaction.Description).
+			WithRequest(interaction.Request).
+			WillRespondWith(interaction.Response)
+
+		err = pact.Verify(func() error {
+			req, _ := http.NewRequest(strings.ToUpper(interaction.Request.Method), fmt.Sprintf("http://localhost:%d/orders", pact.Server.Port), strings.NewReader(`{"sku":"XYZ","quantity":2}`))
+			if interaction.Request.Method == "GET" {
+				req, _ = http.NewRequest("GET", fmt.Sprintf("http://localhost:%d/orders/7?expand=items", pact.Server.Port), nil)
+				req.Header.Set("Accept", "application/json")
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			return res.Body.Close()
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	pact.AddInteraction().
+		UponReceiving("a request for order 2").
+		WithRequest(Request{Method: "GET", Path: String("/orders/2")}).
+		WillRespondWith(Response{Status: 200})
+	err = pact.Verify(func() error { return nil })
+	if _, ok := err.(*MismatchError); !ok || !strings.Contains(err.Error(), "'a request for order 2': request GET /orders/2: expected request was not received") {
+		t.Fatalf("expected the missing request to be reported, got %v", err)
+	}
+
+	if err = pact.WritePact(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := pactfile.Read(filepath.Join(dir, "web-orders.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written.SpecificationVersion() != "3.0.0" || len(written.Interactions) != 2 {
+		t.Fatalf("expected the verified interactions in a version 3 pact, got %+v", written)
+	}
+	if query := compactJSON(written.Interactions[0].Request.Query); query != `{"expand":["items"]}` {
+		t.Fatalf("unexpected query %s", query)
+	}
+}
+
+func TestPact_UseNativeMockServerUnsupportedFeatures(t *testing.T) {
+	pact := &Pact{
+		Consumer:            "web",
+		Provider:            "orders",
+		UseNativeMockServer: true,
+		ContentNegotiation:  true,
+		Limits:              MockServerLimits{MaxHeaders: 10},
+	}
+	defer pact.Teardown()
+
+	pact.AddInteraction().
+		UponReceiving("a request for order 1").
+		WithRequest(Request{Method: "GET", Path: String("/orders/1")}).
+		WillRespondWith(Response{Status: 200}).
+		Sequence(1)
+
+	err := pact.Verify(func() error { return nil })
+	if err == nil || err.Error() != "not supported with UseNativeMockServer: ContentNegotiation, sequenced interactions" {
+		t.Fatalf("expected the features needing the proxy to be rejected, got %v", err)
+	}
+}
+
+func TestPact_UseNativeMockServerUnexpectedRequests(t *testing.T) {
+	pact := &Pact{
+		Consumer:            "web",
+		Provider:            "orders",
+		UseNativeMockServer: true,
+	}
+	defer pact.Teardown()
+	pact.Setup(true)
+
+	pact.AssertNoUnexpectedRequests(t)
+
+	res, err := http.Get(fmt.Sprintf("http://localhost:%d/orders/1", pact.Server.Port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	unexpected := describeMismatches(pact.nativeServer.UnexpectedRequests())
+	if len(unexpected) != 1 || unexpected[0] != "request GET /orders/1: no interaction expected this method and path" {
+		t.Fatalf("expected the unexpected request to be recorded, got %v", unexpected)
+	}
+}
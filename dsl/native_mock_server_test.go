@@ -0,0 +1,250 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func nativeOrderInteractions() []*Interaction {
+	return []*Interaction{
+		(&Interaction{}).
+			Given("order 1 exists").
+			UponReceiving("a request for order 1").
+			WithRequest(Request{
+				Method:  "GET",
+				Path:    Term("/orders/1", `^/orders/\d+$`),
+				Query:   MapMatcher{"expand": String("items")},
+				Headers: MapMatcher{"Accept": String("application/json")},
+			}).
+			WillRespondWith(Response{
+				Status:  200,
+				Headers: MapMatcher{"Content-Type": String("application/json")},
+				Body:    StructMatcher{"id": Like(1), "items": EachLike(StructMatcher{"sku": Like("ABC")}, 1)},
+			}),
+		(&Interaction{}).
+			UponReceiving("a request to create an order").
+			WithRequest(Request{
+				Method: "POST",
+				Path:   String("/orders"),
+				Body:   StructMatcher{"sku": Like("ABC"), "quantity": Like(1)},
+			}).
+			WillRespondWith(Response{Status: 201}),
+	}
+}
+
+func TestNativeMockServer(t *testing.T) {
+	server := NewNativeMockServer()
+	defer server.Close()
+
+	for _, interaction := range nativeOrderInteractions() {
+		if err := server.AddInteraction(interaction); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+"/orders/42?expand=items", nil)
+	req.Header.Set("Accept", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || res.Header.Get("Content-Type") != "application/json" || string(body) != `{"id":1,"items":[{"sku":"ABC"}]}` {
+		t.Fatalf("unexpected response %d %v %s", res.StatusCode, res.Header, body)
+	}
+
+	res, err = http.Post(server.URL+"/orders", "application/json", strings.NewReader(`{"sku": "XYZ", "quantity": 3, "note": "gift"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 201 {
+		t.Fatalf("expected the order to be created, got %d", res.StatusCode)
+	}
+
+	if err = server.Verify(); err != nil {
+		t.Fatalf("expected verification to pass, got %v", err)
+	}
+}
+
+func TestNativeMockServer_Mismatches(t *testing.T) {
+	server := NewNativeMockServer()
+	defer server.Close()
+
+	for _, interaction := range nativeOrderInteractions() {
+		if err := server.AddInteraction(interaction); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	res, err := http.Post(server.URL+"/orders?dry=true", "application/json", strings.NewReader(`{"sku": 7}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected a mismatched request to fail, got %d", res.StatusCode)
+	}
+
+	res, err = http.Get(server.URL + "/customers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	err = server.Verify()
+	mismatchErr, ok := err.(*MismatchError)
+	if !ok {
+		t.Fatalf("expected a *MismatchError, got %v", err)
+	}
+
+	var got []string
+	for _, m := range mismatchErr.Mismatches {
+		for _, mismatch := range m.Mismatches {
+			got = append(got, fmt.Sprintf("%s|%s|%s", m.Description, mismatch.Type(), mismatch))
+		}
+	}
+	want := []string{
+		"a request to create an order|query|query dry: expected '' but got 'true'",
+		"a request to create an order|body|body $.quantity: Could not find key \"quantity\"",
+		"a request to create an order|body|body $.sku: Expected a string (\"ABC\") but got a number (7)",
+		"|request|request GET /customers: no interaction expected this method and path",
+		"a request for order 1|request|request GET /orders/1: expected request was not received",
+		"a request to create an order|request|request POST /orders: expected request was not received",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if _, ok := mismatchErr.Mismatches[0].Mismatches[1].(types.BodyMismatch); !ok {
+		t.Fatalf("expected a typed body mismatch, got %T", mismatchErr.Mismatches[0].Mismatches[1])
+	}
+
+	server.Reset()
+	if err = server.Verify(); err != nil {
+		t.Fatalf("expected no mismatches after a reset, got %v", err)
+	}
+}
+
+func TestNativeMockServer_AddInteraction(t *testing.T) {
+	server := NewNativeMockServer()
+	defer server.Close()
+
+	interaction := (&Interaction{}).
+		UponReceiving("a MessagePack request").
+		WithRequest(Request{Method: "POST", Path: String("/packed"), Body: MsgPackBody(map[string]int{"id": 1})}).
+		WillRespondWith(Response{Status: 200})
+	if err := server.AddInteraction(interaction); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("expected an error for a MessagePack body, got %v", err)
+	}
+	if err := server.AddInteraction(&Interaction{}); err == nil {
+		t.Fatal("expected an error for an invalid interaction")
+	}
+}
+
+func TestNativeMockServer_MatchingRules(t *testing.T) {
+	server := NewNativeMockServer()
+	defer server.Close()
+
+	err := server.AddInteraction((&Interaction{}).
+		UponReceiving("a request to create a user").
+		WithRequest(Request{
+			Method:        "POST",
+			Path:          String("/users/1"),
+			Headers:       MapMatcher{"X-Request-Id": String("abc")},
+			Body:          JSONBody([]byte(`{"name": "Mary", "tags": ["admin"]}`)).WithRule("$.name", TypeRule()),
+			MatchingRules: MatchingRules{}.Add(RequestPathPath(), RegexRule(`^/users/\d+$`)).Add(HeaderPath("X-Request-Id"), RegexRule(`^[a-z]+$`)).Add(BodyPath().Key("tags"), MinTypeRule(1)),
+		}).
+		WillRespondWith(Response{Status: 201, Body: JSONBody([]byte(`{"id": 7}`))}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	send := func(path, requestID, body string) *http.Response {
+		req, _ := http.NewRequest("POST", server.URL+path, strings.NewReader(body))
+		req.Header.Set("X-Request-Id", requestID)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := send("/users/42", "xyz", `{"name": "Joe", "tags": ["admin", "staff"]}`)
+	content, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated || compactJSON(content) != `{"id":7}` {
+		t.Fatalf("expected the request to match the rules, got %d %s", res.StatusCode, content)
+	}
+	if err = server.Verify(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res = send("/users/42", "XYZ", `{"name": 7, "tags": []}`)
+	res.Body.Close()
+	err = server.Verify()
+	mismatchErr, ok := err.(*MismatchError)
+	if !ok {
+		t.Fatalf("expected a *MismatchError, got %v", err)
+	}
+	var got []string
+	for _, mismatch := range mismatchErr.Mismatches[0].Mismatches {
+		got = append(got, mismatch.String())
+	}
+	want := []string{
+		"header X-Request-Id: expected 'abc' but got 'XYZ'",
+		"body $.name: Expected a string (\"Mary\") but got a number (7)",
+		"body $.tags: Expected at least 1 elements but got 0",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestNativeMockServer_Pact(t *testing.T) {
+	server := NewNativeMockServer()
+	defer server.Close()
+
+	for _, interaction := range nativeOrderInteractions() {
+		if err := server.AddInteraction(interaction); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	pact, err := server.Pact("web", "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pact.SpecificationVersion() != "3.0.0" || len(pact.Interactions) != 2 {
+		t.Fatalf("expected a version 3 pact with both interactions, got %+v", pact)
+	}
+
+	compact := func(raw json.RawMessage) string {
+		var buf bytes.Buffer
+		json.Compact(&buf, raw) // nolint:errcheck
+		return buf.String()
+	}
+
+	order := pact.Interactions[0]
+	if order.Request.Path != "/orders/1" || compact(order.Request.Query) != `{"expand":["items"]}` || order.States()[0] != "order 1 exists" {
+		t.Fatalf("unexpected request %+v", order.Request)
+	}
+	if want := `{"path":{"combine":"AND","matchers":[{"match":"regex","regex":"^/orders/\\d+$"}]}}`; compact(order.Request.MatchingRules) != want {
+		t.Fatalf("want request rules %s, got %s", want, compact(order.Request.MatchingRules))
+	}
+	for _, want := range []string{`"$.id":{"combine":"AND","matchers":[{"match":"type"}]}`, `"$.items":{"combine":"AND","matchers":[{"match":"type","min":1}]}`} {
+		if !strings.Contains(compact(order.Response.MatchingRules), want) {
+			t.Fatalf("expected the response rules to contain %s, got %s", want, compact(order.Response.MatchingRules))
+		}
+	}
+	if compact(order.Response.Body) != `{"id":1,"items":[{"sku":"ABC"}]}` {
+		t.Fatalf("unexpected response body %s", compact(order.Response.Body))
+	}
+}
@@ -0,0 +1,161 @@
+package dsl
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// contentNegotiator selects between interactions for the same request that
+// are distinguished only by their Accept header (e.g. JSON vs CSV). Requests
+// to the mock server have their Accept header replaced with the negotiated
+// media type, so that exactly one interaction matches.
+type contentNegotiator struct {
+	mu sync.RWMutex
+
+	// offers are the media types accepted by interactions, keyed by request
+	// method and path
+	offers map[string][]string
+}
+
+func newContentNegotiator() *contentNegotiator {
+	return &contentNegotiator{offers: make(map[string][]string)}
+}
+
+// register records the media type of the interaction's Accept header, if the
+// interaction has a plain path and Accept header
+func (n *contentNegotiator) register(i *Interaction) {
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		return
+	}
+
+	for name, value := range i.Request.Headers {
+		if !strings.EqualFold(name, "Accept") {
+			continue
+		}
+		if accept, ok := plainString(value); ok {
+			key := negotiationKey(i.Request.Method, path)
+
+			n.mu.Lock()
+			n.offers[key] = append(n.offers[key], accept)
+			n.mu.Unlock()
+		}
+	}
+}
+
+// reset forgets all registered interactions
+func (n *contentNegotiator) reset() {
+	n.mu.Lock()
+	n.offers = make(map[string][]string)
+	n.mu.Unlock()
+}
+
+// middleware rewrites the Accept header of requests for negotiable interactions
+func (n *contentNegotiator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") == "" {
+			n.mu.RLock()
+			offers := n.offers[negotiationKey(r.Method, r.URL.Path)]
+			n.mu.RUnlock()
+
+			if len(offers) > 0 {
+				if accept := negotiateContentType(r.Header.Get("Accept"), offers); accept != "" {
+					log.Println("[DEBUG] content negotiation: selected", accept, "for", r.Method, r.URL.Path)
+					r.Header.Set("Accept", accept)
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// negotiateContentType selects the offered media type most preferred by the
+// Accept header, honouring q-values and wildcards. Ties are resolved in the
+// order of the offers. Returns an empty string if none are acceptable.
+func negotiateContentType(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return ""
+	}
+
+	best := ""
+	bestQ := 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best = offer
+			bestQ = q
+		}
+	}
+
+	return best
+}
+
+// acceptQuality returns the q-value the Accept header gives the media type,
+// using the most specific matching range
+func acceptQuality(accept, offer string) float64 {
+	offerType, _, err := mime.ParseMediaType(offer)
+	if err != nil {
+		return 0
+	}
+
+	q := 0.0
+	specificity := -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		s := rangeSpecificity(mediaRange, offerType)
+		if s <= specificity {
+			continue
+		}
+
+		specificity = s
+		q = 1
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+
+	return q
+}
+
+// rangeSpecificity returns how specifically the media range matches the type,
+// or -1 if it does not match
+func rangeSpecificity(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+
+	return -1
+}
+
+func negotiationKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// plainString returns the value of a matcher that is matched verbatim
+func plainString(m interface{}) (string, bool) {
+	switch v := m.(type) {
+	case S:
+		return string(v), true
+	case String:
+		return string(v), true
+	case string:
+		return v, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,89 @@
+package dsl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "text/csv"}
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "application/json", want: "application/json"},
+		{accept: "text/csv", want: "text/csv"},
+		{accept: "text/csv;q=0.9, application/json", want: "application/json"},
+		{accept: "application/json;q=0.5, text/csv;q=0.8", want: "text/csv"},
+		{accept: "text/*, application/json;q=0.1", want: "text/csv"},
+		{accept: "*/*", want: "application/json"},
+		{accept: "*/*;q=0.1, text/csv;q=0", want: "application/json"},
+		{accept: "application/xml", want: ""},
+		{accept: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := negotiateContentType(tt.accept, offers); got != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestContentNegotiator_middleware(t *testing.T) {
+	n := newContentNegotiator()
+	for _, accept := range []string{"application/json", "text/csv"} {
+		n.register((&Interaction{}).
+			UponReceiving("a request for " + accept).
+			WithRequest(Request{
+				Method:  "GET",
+				Path:    String("/report"),
+				Headers: MapMatcher{"Accept": String(accept)},
+			}))
+	}
+
+	var received string
+	handler := n.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Accept")
+	}))
+
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+		admin  bool
+		want   string
+	}{
+		{name: "negotiated", path: "/report", accept: "text/csv;q=0.9, application/json", want: "application/json"},
+		{name: "unregistered path", path: "/other", accept: "text/csv;q=0.9, application/json", want: "text/csv;q=0.9, application/json"},
+		{name: "not acceptable", path: "/report", accept: "application/xml", want: "application/xml"},
+		{name: "admin request", path: "/report", accept: "text/csv;q=0.9, application/json", admin: true, want: "text/csv;q=0.9, application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			if tt.admin {
+				req.Header.Set("X-Pact-Mock-Service", "true")
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if received != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, received)
+			}
+		})
+	}
+
+	n.reset()
+	req := httptest.NewRequest("GET", "/report", nil)
+	req.Header.Set("Accept", "text/csv;q=0.9, application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if received != "text/csv;q=0.9, application/json" {
+		t.Fatalf("expected no negotiation after reset, got %q", received)
+	}
+}
@@ -0,0 +1,392 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// numericStringRegex matches strings containing a number, as sent by
+// providers that quote all numbers
+const numericStringRegex = `^-?\d+(\.\d+)?([eE][+-]?\d+)?$`
+
+var numericString = regexp.MustCompile(numericStringRegex)
+
+// NumberOrNumericStringRule matches numbers, and strings containing a
+// number e.g. "42" or "-1.5", treating them as the same type
+func NumberOrNumericStringRule() Rule {
+	return Rule{
+		"combine":  "OR",
+		"matchers": []interface{}{Rule{"match": "number"}, RegexRule(numericStringRegex)},
+	}
+}
+
+// WithNumbersAsStrings treats numbers and numeric strings (e.g. 42 and "42")
+// as equivalent at the given paths of the request and response bodies,
+// relative to the root of the body e.g. "$.id" or "$.items[*].price".
+// Each path is matched with NumberOrNumericStringRule in the pact.
+func (i *Interaction) WithNumbersAsStrings(paths ...string) *Interaction {
+	i.numbersAsStrings = append(i.numbersAsStrings, paths...)
+
+	return i
+}
+
+// numbersAsStringsErrors checks the paths are well formed
+func (i *Interaction) numbersAsStringsErrors() []FieldError {
+	var errs []FieldError
+	for _, path := range i.numbersAsStrings {
+		if _, err := ParseRulePath(path); err != nil {
+			errs = append(errs, FieldError{Field: "numbersAsStrings", Message: err.Error()})
+		}
+	}
+
+	return errs
+}
+
+// applyNumbersAsStrings adds the rules for the numbers as strings paths to
+// the request (if it has a body) and response
+func (i *Interaction) applyNumbersAsStrings() {
+	for _, path := range i.numbersAsStrings {
+		parsed, err := ParseRulePath(path)
+		if err != nil {
+			continue
+		}
+		rulePath := parsed.relativeTo(BodyPath())
+
+		if i.Request.Body != nil {
+			if i.Request.MatchingRules == nil {
+				i.Request.MatchingRules = MatchingRules{}
+			}
+			i.Request.MatchingRules.Add(rulePath, NumberOrNumericStringRule())
+		}
+		if i.Response.MatchingRules == nil {
+			i.Response.MatchingRules = MatchingRules{}
+		}
+		i.Response.MatchingRules.Add(rulePath, NumberOrNumericStringRule())
+	}
+}
+
+// coerceNumbers converts the numbers and numeric strings at the path of the
+// actual JSON document to the type of the example at the same path, so that
+// they match whichever way they are sent
+func coerceNumbers(actual interface{}, example interface{}, path []pathToken) interface{} {
+	if len(path) == 0 {
+		return coerceNumber(actual, example)
+	}
+
+	rest := path[1:]
+	switch token := path[0]; token.kind {
+	case keyToken:
+		a, aok := actual.(map[string]interface{})
+		e, eok := example.(map[string]interface{})
+		if aok && eok {
+			if value, ok := a[token.key]; ok {
+				a[token.key] = coerceNumbers(value, e[token.key], rest)
+			}
+		}
+	case anyKeyToken:
+		a, aok := actual.(map[string]interface{})
+		e, eok := example.(map[string]interface{})
+		if aok && eok {
+			for key, value := range a {
+				if expected, ok := e[key]; ok {
+					a[key] = coerceNumbers(value, expected, rest)
+				}
+			}
+		}
+	case indexToken, anyIndexToken:
+		a, aok := actual.([]interface{})
+		e, eok := example.([]interface{})
+		if !aok || !eok || len(e) == 0 {
+			break
+		}
+		for n, value := range a {
+			if token.kind == indexToken && n != token.index {
+				continue
+			}
+			a[n] = coerceNumbers(value, e[minInt(n, len(e)-1)], rest)
+		}
+	}
+
+	return actual
+}
+
+func coerceNumber(actual interface{}, example interface{}) interface{} {
+	if isNumber(example) {
+		if s, ok := actual.(string); ok && numericString.MatchString(s) {
+			return json.Number(s)
+		}
+		return actual
+	}
+
+	if _, ok := example.(string); ok && isNumber(actual) {
+		switch n := actual.(type) {
+		case json.Number:
+			return n.String()
+		case float64:
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return fmt.Sprint(actual)
+	}
+
+	return actual
+}
+
+func isNumber(value interface{}) bool {
+	if _, ok := value.(json.Number); ok {
+		return true
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// decodeJSON decodes a JSON document, keeping numbers as written
+func decodeJSON(content []byte) (interface{}, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	err := decoder.Decode(&doc)
+
+	return doc, err
+}
+
+// numericBody is the example body of an interaction, and the paths at which
+// numbers and numeric strings are equivalent
+type numericBody struct {
+	example interface{}
+	paths   []RulePath
+}
+
+// coerce converts the numbers at the paths of the body to the type of the
+// example, returning the body unchanged if it isn't JSON
+func (b numericBody) coerce(body []byte) []byte {
+	actual, err := decodeJSON(body)
+	if err != nil {
+		return body
+	}
+
+	for _, path := range b.paths {
+		actual = coerceNumbers(actual, b.example, path.tokens)
+	}
+	coerced, err := json.Marshal(actual)
+	if err != nil {
+		return body
+	}
+
+	return coerced
+}
+
+// numericStrings converts the numbers and numeric strings in bodies to the
+// type of the example, for interactions treating them as equivalent. It is
+// used in front of the mock server for requests, and in front of the
+// provider during verification for responses.
+type numericStrings struct {
+	mu sync.Mutex
+
+	// bodies are keyed by method and path. Requests for interactions with
+	// differing bodies are not changed.
+	bodies map[string]*numericBody
+}
+
+func newNumericStrings() *numericStrings {
+	return &numericStrings{bodies: make(map[string]*numericBody)}
+}
+
+// register records the request body of an interaction with numbers as
+// strings paths
+func (n *numericStrings) register(i *Interaction) {
+	if len(i.numbersAsStrings) == 0 || i.Request.Body == nil {
+		return
+	}
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		log.Printf("[WARN] interaction '%s' has numbers as strings but its path is not a plain string, its request will not be converted\n", i.Description)
+		return
+	}
+
+	body := &numericBody{example: exampleOf(i.Request.Body)}
+	for _, p := range i.numbersAsStrings {
+		if parsed, err := ParseRulePath(p); err == nil {
+			body.paths = append(body.paths, parsed)
+		}
+	}
+
+	n.add(negotiationKey(i.Request.Method, path), body)
+}
+
+func (n *numericStrings) add(key string, body *numericBody) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if existing, ok := n.bodies[key]; ok && (existing == nil || jsonString(existing.example) != jsonString(body.example)) {
+		body = nil
+	}
+	n.bodies[key] = body
+}
+
+func (n *numericStrings) lookup(r *http.Request) *numericBody {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.bodies[negotiationKey(r.Method, r.URL.Path)]
+}
+
+// reset forgets all registered bodies
+func (n *numericStrings) reset() {
+	n.mu.Lock()
+	n.bodies = make(map[string]*numericBody)
+	n.mu.Unlock()
+}
+
+// requestMiddleware converts the numbers in request bodies
+func (n *numericStrings) requestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := n.lookup(r)
+		if r.Header.Get("X-Pact-Mock-Service") != "" || r.Body == nil || body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body.Close()
+
+		content = body.coerce(content)
+		r.ContentLength = int64(len(content))
+		r.Header.Del("Content-Length")
+		r.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// responseMiddleware converts the numbers in response bodies
+func (n *numericStrings) responseMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := n.lookup(r)
+		if body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		content := body.coerce(recorder.body.Bytes())
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(recorder.status)
+		w.Write(content) // nolint:errcheck
+	})
+}
+
+// newNumericResponses reads the responses of interactions with numbers as
+// strings (see NumberOrNumericStringRule) from the pact files. It returns
+// nil if there are none. Pacts that can't be read are left for the verifier
+// to report.
+func newNumericResponses(pactURLs []string, request types.VerifyRequest) *numericStrings {
+	n := newNumericStrings()
+
+	for _, location := range pactURLs {
+		pact, err := readTriagePact(location, request)
+		if err != nil {
+			log.Printf("[WARN] unable to read pact %s to check for numbers as strings: %v\n", location, err)
+			continue
+		}
+
+		for _, interaction := range pact.Interactions {
+			paths := numericStringPaths(interaction.Response.MatchingRules)
+			if len(paths) == 0 || len(interaction.Response.Body) == 0 {
+				continue
+			}
+
+			example, err := decodeJSON(interaction.Response.Body)
+			if err != nil {
+				log.Printf("[WARN] unable to parse the response body of interaction '%s': %v\n", interaction.Description, err)
+				continue
+			}
+			n.add(negotiationKey(interaction.Request.Method, interaction.Request.Path), &numericBody{example: example, paths: paths})
+		}
+	}
+
+	if len(n.bodies) == 0 {
+		return nil
+	}
+
+	return n
+}
+
+// numericStringPaths finds the body paths, relative to the root of the
+// body, matched with NumberOrNumericStringRule. Both version 2 rules (e.g.
+// "$.body.id") and version 3 rules (keyed by "body", then "$.id") are read.
+func numericStringPaths(raw json.RawMessage) []RulePath {
+	var rules map[string]interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &rules) != nil {
+		return nil
+	}
+
+	var paths []RulePath
+	add := func(path string, rule interface{}) {
+		if !hasNumericStringRule(rule) {
+			return
+		}
+		if parsed, err := ParseRulePath(path); err == nil {
+			paths = append(paths, parsed)
+		}
+	}
+
+	for path, rule := range rules {
+		if strings.HasPrefix(path, "$.body.") || strings.HasPrefix(path, "$.body[") {
+			add("$"+strings.TrimPrefix(path, "$.body"), rule)
+		}
+	}
+	if body, ok := rules["body"].(map[string]interface{}); ok {
+		for path, rule := range body {
+			add(path, rule)
+		}
+	}
+
+	return paths
+}
+
+// hasNumericStringRule determines if the rule, or any rule it combines,
+// matches numeric strings
+func hasNumericStringRule(rule interface{}) bool {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		if r["regex"] == numericStringRegex {
+			return true
+		}
+		return hasNumericStringRule(r["matchers"])
+	case []interface{}:
+		for _, matcher := range r {
+			if hasNumericStringRule(matcher) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
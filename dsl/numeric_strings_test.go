@@ -0,0 +1,151 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestNumericBody_Coerce(t *testing.T) {
+	body := numericBody{example: map[string]interface{}{"id": 1, "items": []interface{}{map[string]interface{}{"price": "2.50"}}}}
+	for _, path := range []string{"$.id", "$.items[*].price"} {
+		parsed, _ := ParseRulePath(path)
+		body.paths = append(body.paths, parsed)
+	}
+
+	got := string(body.coerce([]byte(`{"id": "42", "name": "7", "items": [{"price": 3}, {"price": 1e2}, {"price": "free"}]}`)))
+	want := `{"id":42,"items":[{"price":"3"},{"price":"1e2"},{"price":"free"}],"name":"7"}`
+	if got != want {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+
+	if got := string(body.coerce([]byte("not json"))); got != "not json" {
+		t.Fatalf("expected a body that isn't JSON to be unchanged, got %s", got)
+	}
+}
+
+func TestInteraction_ApplyNumbersAsStrings(t *testing.T) {
+	interaction := (&Interaction{}).
+		UponReceiving("a request for an order").
+		WithRequest(Request{Method: "GET", Path: String("/orders/1")}).
+		WillRespondWith(Response{Status: 200, Body: map[string]interface{}{"total": Like(10)}}).
+		WithNumbersAsStrings("$.total")
+
+	if err := interaction.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	interaction.applyNumbersAsStrings()
+
+	if interaction.Request.MatchingRules != nil {
+		t.Fatalf("expected no rules for a request without a body, got %v", interaction.Request.MatchingRules)
+	}
+	rule := interaction.Response.MatchingRules["$.body.total"]
+	if jsonString(rule) != jsonString(NumberOrNumericStringRule()) {
+		t.Fatalf("expected the numbers as strings rule, got %v", interaction.Response.MatchingRules)
+	}
+
+	interaction.WithNumbersAsStrings("$.items[")
+	if err := interaction.Validate(); err == nil || !strings.Contains(err.Error(), "numbersAsStrings") {
+		t.Fatalf("expected an error for the invalid path, got %v", err)
+	}
+}
+
+func TestNumericStringPaths(t *testing.T) {
+	rule := jsonString(NumberOrNumericStringRule())
+
+	v2 := numericStringPaths(json.RawMessage(`{"$.body.id": ` + rule + `, "$.body.name": {"match": "type"}, "$.headers.X-Count": ` + rule + `}`))
+	if len(v2) != 1 || v2[0].String() != "$.id" {
+		t.Fatalf("unexpected version 2 paths %v", v2)
+	}
+
+	v3 := numericStringPaths(json.RawMessage(`{"body": {"$.items[*].price": {"combine": "AND", "matchers": [` + rule + `]}}}`))
+	if len(v3) != 1 || v3[0].String() != "$.items[*].price" {
+		t.Fatalf("unexpected version 3 paths %v", v3)
+	}
+}
+
+func TestPact_VerifyNumbersAsStrings(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			fmt.Fprintln(w, "ok")
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"quantity":2}` {
+			http.Error(w, "unexpected body "+string(body), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:   &types.MockServer{Port: getPort(ms.URL)},
+		Consumer: "My Consumer",
+		Provider: "My Provider",
+	}
+	pact.
+		AddInteraction().
+		UponReceiving("a request to create an order").
+		WithRequest(Request{Method: "POST", Path: String("/orders"), Body: map[string]interface{}{"quantity": Like(1)}}).
+		WillRespondWith(Response{Status: 201}).
+		WithNumbersAsStrings("$.quantity")
+
+	err := pact.Verify(func() error {
+		res, err := http.Post(fmt.Sprintf("http://localhost:%d/orders", pact.Server.Port), "application/json", strings.NewReader(`{"quantity": "2"}`))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusCreated {
+			return fmt.Errorf("expected the quoted number to be converted, got %d", res.StatusCode)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNumericResponses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "numeric")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "web-orders.json")
+	pact := `{"consumer": {"name": "web"}, "provider": {"name": "orders"}, "interactions": [
+		{"description": "a request for order 1", "request": {"method": "GET", "path": "/orders/1"},
+		 "response": {"status": 200, "body": {"total": 10}, "matchingRules": {"$.body.total": ` + jsonString(NumberOrNumericStringRule()) + `}}},
+		{"description": "a request for order 2", "request": {"method": "GET", "path": "/orders/2"}, "response": {"status": 200, "body": {"total": 10}}}
+	]}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	numbers := newNumericResponses([]string{file}, types.VerifyRequest{})
+	if numbers == nil {
+		t.Fatal("expected the interaction with numbers as strings to be read")
+	}
+
+	provider := numbers.responseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"total": "12.5"}`)
+	}))
+	for path, want := range map[string]string{"/orders/1": `{"total":12.5}`, "/orders/2": `{"total": "12.5"}`} {
+		recorder := httptest.NewRecorder()
+		provider.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Body.String() != want || recorder.Header().Get("Content-Type") != "application/json" {
+			t.Fatalf("%s: want %s, got %s", path, want, recorder.Body.String())
+		}
+	}
+}
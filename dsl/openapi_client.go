@@ -0,0 +1,171 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// OpenAPIOperation is an operation of an OpenAPI (or Swagger) document
+type OpenAPIOperation struct {
+	ID      string
+	Method  string
+	Summary string
+
+	// Path is the operation's path template, including the document's base
+	// path e.g. "/v1/users/{id}"
+	Path string
+}
+
+// OpenAPIClient connects clients generated from an OpenAPI document, such as
+// by oapi-codegen or go-swagger, to the mock server, and describes
+// interactions by the operationId of the request the client will send.
+type OpenAPIClient struct {
+	pact *Pact
+
+	// BasePath is the path that operation paths are relative to, from the
+	// document's first server (OpenAPI 3) or basePath (Swagger 2)
+	BasePath string
+
+	// Operations by operationId
+	Operations map[string]OpenAPIOperation
+}
+
+// OpenAPIClient reads the operations of an OpenAPI (or Swagger) document, in
+// either JSON or YAML format
+func (p *Pact) OpenAPIClient(file string) (*OpenAPIClient, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		BasePath string `yaml:"basePath"`
+		Servers  []struct {
+			URL string `yaml:"url"`
+		} `yaml:"servers"`
+		Paths map[string]map[string]interface{} `yaml:"paths"`
+	}
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse OpenAPI document %s: %v", file, err)
+	}
+
+	client := &OpenAPIClient{pact: p, BasePath: doc.BasePath, Operations: make(map[string]OpenAPIOperation)}
+	if len(doc.Servers) > 0 {
+		server, err := url.Parse(doc.Servers[0].URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server URL in OpenAPI document %s: %v", file, err)
+		}
+		client.BasePath = server.Path
+	}
+	client.BasePath = strings.TrimSuffix(client.BasePath, "/")
+
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			switch strings.ToUpper(method) {
+			case "GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE":
+			default:
+				continue
+			}
+
+			fields, _ := operation.(map[interface{}]interface{})
+			id, _ := fields["operationId"].(string)
+			if id == "" {
+				continue
+			}
+			if existing, ok := client.Operations[id]; ok {
+				return nil, fmt.Errorf("operationId '%s' is used by both %s %s and %s %s", id, existing.Method, existing.Path, strings.ToUpper(method), client.BasePath+path)
+			}
+			summary, _ := fields["summary"].(string)
+			client.Operations[id] = OpenAPIOperation{
+				ID:      id,
+				Method:  strings.ToUpper(method),
+				Summary: summary,
+				Path:    client.BasePath + path,
+			}
+		}
+	}
+
+	return client, nil
+}
+
+// BaseURL is the server URL to give an oapi-codegen client e.g.
+// NewClient(client.BaseURL()), starting the mock server if needed
+func (c *OpenAPIClient) BaseURL() string {
+	return "http://" + c.Host() + c.BasePath
+}
+
+// Host is the host to give a go-swagger client, along with the BasePath e.g.
+// DefaultTransportConfig().WithHost(client.Host()).WithSchemes([]string{"http"}).
+// The mock server is started if needed.
+func (c *OpenAPIClient) Host() string {
+	if c.pact.Server == nil {
+		c.pact.Setup(true)
+	}
+
+	return fmt.Sprintf("%s:%d", c.pact.Host, c.pact.Server.Port)
+}
+
+// Request returns the request of the operation, with the path parameters
+// substituted
+func (c *OpenAPIClient) Request(operationID string, params map[string]string) (Request, error) {
+	operation, ok := c.Operations[operationID]
+	if !ok {
+		return Request{}, fmt.Errorf("unknown operationId '%s'", operationID)
+	}
+
+	path := operation.Path
+	for name, value := range params {
+		placeholder := "{" + name + "}"
+		if !strings.Contains(path, placeholder) {
+			return Request{}, fmt.Errorf("operation '%s' has no path parameter '%s'", operationID, name)
+		}
+		path = strings.Replace(path, placeholder, url.PathEscape(value), -1)
+	}
+
+	if missing := pathParams(path); len(missing) > 0 {
+		return Request{}, fmt.Errorf("operation '%s' is missing path parameters: %s", operationID, strings.Join(missing, ", "))
+	}
+
+	return Request{Method: operation.Method, Path: String(path)}, nil
+}
+
+// AddOperation adds an interaction for the operation, described by its
+// summary (or else its operationId) and recording the operationId in the
+// interaction's metadata. Its request may be completed with e.g. a body
+// through the interaction's Request field.
+func (c *OpenAPIClient) AddOperation(operationID string, params map[string]string) (*Interaction, error) {
+	request, err := c.Request(operationID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	description := c.Operations[operationID].Summary
+	if description == "" {
+		description = operationID
+	}
+
+	return c.pact.AddInteraction().
+		UponReceiving(description).
+		WithRequest(request).
+		WithMetadata("operationId", operationID), nil
+}
+
+// pathParams returns the names of the parameters left in a path template
+func pathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if start := strings.Index(segment, "{"); start >= 0 {
+			if end := strings.Index(segment[start:], "}"); end > 0 {
+				params = append(params, segment[start+1:start+end])
+			}
+		}
+	}
+	sort.Strings(params)
+
+	return params
+}
@@ -0,0 +1,115 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+const openAPIClientDocument = `
+openapi: 3.0.0
+servers:
+  - url: https://api.example.com/v1/
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+    get:
+      operationId: getUser
+      summary: a request for a user
+    delete:
+      operationId: deleteUser
+  /users:
+    post:
+      operationId: createUser
+`
+
+func writeOpenAPIClientDocument(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "pact-openapi-client")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	file := filepath.Join(dir, "openapi.yaml")
+	if err = ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("unable to write document: %v", err)
+	}
+
+	return file, func() { os.RemoveAll(dir) }
+}
+
+func TestOpenAPIClient(t *testing.T) {
+	file, cleanup := writeOpenAPIClientDocument(t, openAPIClientDocument)
+	defer cleanup()
+
+	pact := &Pact{Host: "localhost", Server: &types.MockServer{Port: 1234}, DisableToolValidityCheck: true}
+	client, err := pact.OpenAPIClient(file)
+	if err != nil {
+		t.Fatalf("unable to read document: %v", err)
+	}
+
+	if url := client.BaseURL(); url != "http://localhost:1234/v1" {
+		t.Fatalf("want base URL http://localhost:1234/v1, got %s", url)
+	}
+	if len(client.Operations) != 3 {
+		t.Fatalf("want 3 operations, got %v", client.Operations)
+	}
+
+	interaction, err := client.AddOperation("getUser", map[string]string{"id": "10"})
+	if err != nil {
+		t.Fatalf("unable to add operation: %v", err)
+	}
+	if interaction.Description != "a request for a user" || interaction.Request.Method != "GET" || fmt.Sprint(interaction.Request.Path.GetValue()) != "/v1/users/10" {
+		t.Fatalf("unexpected interaction %+v", interaction)
+	}
+	if interaction.metadata["operationId"] != "getUser" {
+		t.Fatalf("want operationId metadata, got %v", interaction.metadata)
+	}
+
+	interaction, err = client.AddOperation("deleteUser", map[string]string{"id": "a b"})
+	if err != nil || interaction.Description != "deleteUser" || fmt.Sprint(interaction.Request.Path.GetValue()) != "/v1/users/a%20b" {
+		t.Fatalf("unexpected interaction %+v: %v", interaction, err)
+	}
+}
+
+func TestOpenAPIClient_RequestErrors(t *testing.T) {
+	file, cleanup := writeOpenAPIClientDocument(t, openAPIClientDocument)
+	defer cleanup()
+
+	client, err := (&Pact{}).OpenAPIClient(file)
+	if err != nil {
+		t.Fatalf("unable to read document: %v", err)
+	}
+
+	tests := map[string]map[string]string{
+		"unknown": nil,
+		"getUser": {"name": "jmarie"},
+	}
+	for id, params := range tests {
+		if _, err := client.Request(id, params); err == nil {
+			t.Fatalf("%s: expected an error", id)
+		}
+	}
+	if _, err := client.Request("getUser", nil); err == nil {
+		t.Fatalf("expected an error for a missing path parameter")
+	}
+}
+
+func TestOpenAPIClient_SwaggerBasePath(t *testing.T) {
+	file, cleanup := writeOpenAPIClientDocument(t, `{"swagger":"2.0","basePath":"/api","paths":{"/users":{"get":{"operationId":"listUsers"}}}}`)
+	defer cleanup()
+
+	client, err := (&Pact{}).OpenAPIClient(file)
+	if err != nil {
+		t.Fatalf("unable to read document: %v", err)
+	}
+
+	request, err := client.Request("listUsers", nil)
+	if err != nil || fmt.Sprint(request.Path.GetValue()) != "/api/users" {
+		t.Fatalf("unexpected request %+v: %v", request, err)
+	}
+}
@@ -5,9 +5,11 @@ collaboration test cases, and Provider contract test verification.
 package dsl
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
@@ -21,6 +23,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/logutils"
+	"github.com/pact-foundation/pact-go/ffi"
 	"github.com/pact-foundation/pact-go/install"
 	"github.com/pact-foundation/pact-go/proxy"
 	"github.com/pact-foundation/pact-go/types"
@@ -69,10 +72,17 @@ type Pact struct {
 	// See https://github.com/pact-foundation/pact-ruby/blob/master/documentation/configuration.md#pactfile_write_mode
 	PactFileWriteMode string
 
-	// Specify which version of the Pact Specification should be used (1 or 2).
-	// Defaults to 2.
+	// Specify which version of the Pact Specification should be used (1, 2, 3
+	// or 4). Defaults to 2. Version 4 pacts are written by the mock service as
+	// version 3, and converted by WritePact.
 	SpecificationVersion int
 
+	// AdditionalSpecificationVersions lists other specification versions (2, 3
+	// or 4) to also write the pact as, each to a "v<version>" subdirectory of
+	// PactDir e.g. `pacts/v3/consumer-provider.json`. This allows providers
+	// verified with different verifiers to share the same consumer tests.
+	AdditionalSpecificationVersions []int
+
 	// Host is the address of the Mock and Verification Service runs on
 	// Examples include 'localhost', '127.0.0.1', '[::1]'
 	// Defaults to 'localhost'
@@ -97,8 +107,171 @@ type Pact struct {
 	// Defaults to 10s
 	ClientTimeout time.Duration
 
+	// DefaultRequestHeaders are merged into the request headers of every
+	// interaction e.g. an Authorization header. Headers specified on an
+	// interaction take precedence.
+	DefaultRequestHeaders MapMatcher
+
+	// DefaultResponseHeaders are merged into the response headers of every
+	// interaction e.g. a Content-Type header. Headers specified on an
+	// interaction take precedence.
+	DefaultResponseHeaders MapMatcher
+
+	// UseNativeMockServer runs the consumer tests against the in-process
+	// NativeMockServer instead of the Ruby mock service, which needn't then
+	// be installed. The interactions are limited to what NativeMockServer
+	// supports, and Verify returns an error if features needing the proxy in
+	// front of the Ruby mock service, such as ContentNegotiation or
+	// RecordMismatches, are used. Limits are enforced by the native mock
+	// server itself.
+	UseNativeMockServer bool
+
+	// ContentNegotiation routes requests to the mock server through a proxy
+	// which negotiates the Accept header (including q-values) against the
+	// registered interactions. This allows interactions for the same path to
+	// be distinguished by their Accept header, e.g. JSON vs CSV.
+	ContentNegotiation bool
+
+	// RecordMismatches routes requests to the mock server through a proxy
+	// which records the differences between the requests matching no
+	// interaction and the interactions, returned by Verify in a
+	// MismatchError in place of a summary of each request.
+	RecordMismatches bool
+
+	// ExplicitBodies requires the request and response body of every
+	// interaction to be specified, using NoBody, EmptyBody or AnyBody when
+	// there is no body to match, so that a missing body is not silently
+	// ignored. GET and HEAD requests without a body expect NoBody.
+	ExplicitBodies bool
+
+	// RecordGeneration records when the pact file was written, and the git
+	// commit and Go module version of the consumer, in its metadata. See
+	// VerifyRequest.MaxPactAge.
+	RecordGeneration bool
+
+	// StrictValidation fails Verify if any interaction has a problem found
+	// by Interaction.Validate. By default, problems the mock server
+	// tolerates, such as a GET request with a body, are logged as warnings.
+	StrictValidation bool
+
+	// ValidateExamples checks that the example response of every interaction
+	// satisfies its own matching rules, e.g. that the example of a Term
+	// matches its regular expression, so that the mock server never returns
+	// a response the provider could not. Failures are reported by Verify with
+	// the path of each field.
+	ValidateExamples bool
+
+	// Redaction scrubs secrets, such as Authorization headers or API keys,
+	// from the interactions written to the pact file by WritePact
+	Redaction Redaction
+
+	// Resolvers substitute ${NAME} placeholders in the path, query, headers
+	// and provider states of interactions (and the provider states and
+	// metadata of messages) when they are verified, trying each resolver in
+	// turn, e.g. []Resolver{EnvResolver}. An unresolved placeholder fails
+	// the test. Resolved values are written to the pact file, see Redaction.
+	Resolvers []Resolver
+
+	// HARDir is written with a HAR (HTTP Archive) file of the traffic the
+	// mock server handled during each Verify, named after its first
+	// interaction, to replay in browser devtools or share with the provider
+	// team. Optional.
+	HARDir string
+
+	// Explain writes, for each request the mock server receives, each
+	// registered interaction it was compared with, the outcome of comparing
+	// each field and the matching rule applied, to ExplainOutput, to show why
+	// a request did or didn't match. Optional.
+	Explain bool
+
+	// ExplainOutput receives the output of Explain. Defaults to stderr.
+	ExplainOutput io.Writer
+
+	// Limits on the size of requests and the time taken to receive them,
+	// enforced in front of the mock server. Optional.
+	Limits MockServerLimits
+
+	// UnicodeNormalization normalises the text of interactions, and of the
+	// requests the mock server receives, to a single Unicode normalisation
+	// form, so that text differing only in its form (e.g. "é" as a single
+	// code point, or as "e" and a combining accent) matches. Use e.g.
+	// norm.NFC.String from golang.org/x/text/unicode/norm. Optional.
+	UnicodeNormalization func(string) string
+
+	// Selects between interactions differing only by Accept header
+	negotiator *contentNegotiator
+
+	// Selects between sequenced interactions for the same request
+	sequencer *requestSequencer
+
+	// The proxy in front of the mock server, if started
+	proxyServer *http.Server
+
+	// The in-process mock server, if UseNativeMockServer is set, and the
+	// interactions verified with it to write to the pact file
+	nativeServer       *NativeMockServer
+	nativeInteractions []*nativeInteraction
+
+	// Records the mismatches of requests that matched no interaction
+	mismatches *mismatchRecorder
+
+	// Records the traffic of the mock server, see HARDir
+	har *harRecorder
+
+	// Rejects requests with a body for interactions expecting none
+	bodies *bodyChecker
+
+	// Generates the response headers of interactions with header generators
+	generators *responseGenerators
+
+	// Removes unexpected keys from requests for interactions allowing them
+	postel *postelRequests
+
+	// Converts numbers in requests for interactions with numbers as strings
+	numbers *numericStrings
+
+	// Explains how each request compares with the interactions, see Explain
+	explainer *requestExplainer
+
+	// Applies the body transforms of interactions
+	transformer *bodyTransformer
+
+	// Matches and sends the trailers of interactions
+	trailerChecker *consumerTrailers
+
 	// Check if CLI tools are up to date
 	toolValidityCheck bool
+
+	// Descriptions of described matchers, keyed by interaction description
+	fieldDescriptions map[string]map[string]string
+
+	// Metadata of interactions, keyed by interaction description
+	interactionMetadata map[string]map[string]string
+
+	// Provider states of interactions with several states, or states with
+	// parameters, by interaction description
+	providerStates map[string][]State
+
+	// Sides of interactions recorded with an empty body, keyed by
+	// interaction description
+	emptyBodies map[string][]string
+
+	// Response header generators of interactions, keyed by interaction
+	// description
+	headerGenerators map[string]map[string]interface{}
+
+	// Body matching of interactions, keyed by interaction description
+	bodyMatching map[string]BodyMatching
+
+	// Body transforms of interactions, keyed by interaction description
+	bodyTransforms map[string]map[string]string
+
+	// Trailers of interactions, keyed by interaction description
+	trailers map[string]*interactionTrailers
+
+	// Interactions verified so far, in the order first verified, see
+	// Document
+	verified []*Interaction
 }
 
 // AddMessage creates a new asynchronous consumer expectation
@@ -116,7 +289,11 @@ func (p *Pact) AddMessage() *Message {
 func (p *Pact) AddInteraction() *Interaction {
 	p.Setup(true)
 	log.Println("[DEBUG] pact add interaction")
-	i := &Interaction{}
+	i := &Interaction{
+		specificationVersion: p.SpecificationVersion,
+		explicitBodies:       p.ExplicitBodies,
+		validateExamples:     p.ValidateExamples,
+	}
 	p.Interactions = append(p.Interactions, i)
 	return i
 }
@@ -133,7 +310,7 @@ func (p *Pact) Setup(startMockServer bool) *Pact {
 		p.Network = "tcp"
 	}
 
-	if !p.toolValidityCheck && !(p.DisableToolValidityCheck || os.Getenv("PACT_DISABLE_TOOL_VALIDITY_CHECK") != "") {
+	if !p.toolValidityCheck && !(p.DisableToolValidityCheck || p.UseNativeMockServer || os.Getenv("PACT_DISABLE_TOOL_VALIDITY_CHECK") != "") {
 		checkCliCompatibility()
 		p.toolValidityCheck = true
 	}
@@ -180,11 +357,17 @@ func (p *Pact) Setup(startMockServer bool) *Pact {
 		log.Println("[ERROR] unable to find free port, mockserver will fail to start")
 	}
 
-	if p.Server == nil && startMockServer {
+	if p.Server == nil && startMockServer && p.UseNativeMockServer {
+		p.startNativeMockServer(port)
+	} else if p.Server == nil && startMockServer {
 		log.Println("[DEBUG] starting mock service on port:", port)
+		version := p.SpecificationVersion
+		if version > 3 {
+			version = 3
+		}
 		args := []string{
 			"--pact-specification-version",
-			fmt.Sprintf("%d", p.SpecificationVersion),
+			fmt.Sprintf("%d", version),
 			"--pact-dir",
 			filepath.FromSlash(p.PactDir),
 			"--log",
@@ -212,17 +395,29 @@ func (p *Pact) setupLogging() {
 		p.logFilter = &logutils.LevelFilter{
 			Levels:   []logutils.LogLevel{"TRACE", "DEBUG", "INFO", "WARN", "ERROR"},
 			MinLevel: logutils.LogLevel(p.LogLevel),
-			Writer:   os.Stderr,
+			Writer:   logOutput{},
 		}
 		log.SetOutput(p.logFilter)
 	}
 	log.Println("[DEBUG] pact setup logging")
 }
 
-// Teardown stops the Pact Mock Server. This usually is called on completion
-// of each test suite.
+// Teardown stops the Pact Mock Server, and the proxy in front of it. This
+// usually is called on completion of each test suite.
 func (p *Pact) Teardown() *Pact {
 	log.Println("[DEBUG] teardown")
+	if p.nativeServer != nil {
+		p.nativeServer.Close()
+		p.nativeServer = nil
+		p.Server = nil
+		return p
+	}
+	if p.proxyServer != nil {
+		if err := p.proxyServer.Shutdown(context.Background()); err != nil {
+			log.Println("error:", err)
+		}
+		p.proxyServer = nil
+	}
 	if p.Server != nil {
 		server, err := p.pactClient.StopServer(p.Server)
 
@@ -237,6 +432,14 @@ func (p *Pact) Teardown() *Pact {
 // Verify runs the current test case against a Mock Service.
 // Will cleanup interactions between tests within a suite.
 func (p *Pact) Verify(integrationTest func() error) error {
+	for _, interaction := range p.Interactions {
+		interaction.resolveJSONBodyRules()
+	}
+	if err := p.validateInteractions(); err != nil {
+		p.Interactions = make([]*Interaction, 0)
+		return err
+	}
+
 	p.Setup(true)
 	log.Println("[DEBUG] pact verify")
 	var err error
@@ -246,25 +449,147 @@ func (p *Pact) Verify(integrationTest func() error) error {
 		return errors.New("there are no interactions to be verified")
 	}
 
+	if p.nativeServer != nil {
+		return p.verifyNative(integrationTest)
+	}
+
 	mockServer := &MockService{
 		BaseURL:  fmt.Sprintf("http://%s:%d", p.Host, p.Server.Port),
 		Consumer: p.Consumer,
 		Provider: p.Provider,
 	}
+	harName := p.Interactions[0].Description
 
 	// Cleanup all interactions
 	defer func(mockServer *MockService) {
+		if p.har != nil {
+			if _, harErr := p.har.write(p.HARDir, harName); harErr != nil {
+				log.Println("[WARN] unable to write mock server traffic:", harErr)
+			}
+		}
+
 		log.Println("[DEBUG] clearing interactions")
 
 		p.Interactions = make([]*Interaction, 0)
+		if p.negotiator != nil {
+			p.negotiator.reset()
+		}
+		if p.sequencer != nil {
+			p.sequencer.reset()
+		}
+		if p.mismatches != nil {
+			p.mismatches.take()
+		}
+		if p.bodies != nil {
+			p.bodies.reset()
+		}
+		if p.generators != nil {
+			p.generators.reset()
+		}
+		if p.postel != nil {
+			p.postel.reset()
+		}
+		if p.numbers != nil {
+			p.numbers.reset()
+		}
+		if p.explainer != nil {
+			p.explainer.reset()
+		}
+		if p.transformer != nil {
+			p.transformer.reset()
+		}
+		if p.trailerChecker != nil {
+			p.trailerChecker.reset()
+		}
 		err = mockServer.DeleteInteractions()
 	}(mockServer)
 
+	if p.proxyServer == nil && p.needsMockServerProxy() {
+		if err = p.startMockServerProxy(); err != nil {
+			return err
+		}
+	}
+
 	for _, interaction := range p.Interactions {
+		if err = p.resolvePlaceholders(interaction); err != nil {
+			return err
+		}
+		interaction.applySequence()
+		interaction.applyNumbersAsStrings()
+		if p.UnicodeNormalization != nil {
+			unicodeNormaliser(p.UnicodeNormalization).normaliseInteraction(interaction)
+		}
+		interaction.Request.Headers = mergeHeaders(withoutContentType(interaction.Request.Body, p.DefaultRequestHeaders), interaction.Request.Headers)
+		interaction.Response.Headers = mergeHeaders(withoutContentType(interaction.Response.Body, p.DefaultResponseHeaders), interaction.Response.Headers)
+		interaction.Request.Headers = msgpackHeaders(interaction.Request.Body, interaction.Request.Headers)
+		interaction.Response.Headers = msgpackHeaders(interaction.Response.Body, interaction.Response.Headers)
+
+		if p.bodies != nil {
+			p.bodies.register(interaction)
+		}
+		if p.generators != nil {
+			p.generators.register(interaction)
+		}
+		if p.postel != nil {
+			p.postel.register(interaction)
+		}
+		if p.numbers != nil {
+			p.numbers.register(interaction)
+		}
+		if p.explainer != nil {
+			p.explainer.register(interaction)
+		}
+		if p.transformer != nil {
+			p.transformer.register(interaction)
+		}
+		if p.trailerChecker != nil {
+			p.trailerChecker.register(interaction)
+		}
+		if t := interaction.trailerSides(); t != nil {
+			if p.trailers == nil {
+				p.trailers = make(map[string]*interactionTrailers)
+			}
+			p.trailers[interaction.Description] = t
+		}
+		if names := interaction.bodyTransformNames(); len(names) > 0 {
+			if p.bodyTransforms == nil {
+				p.bodyTransforms = make(map[string]map[string]string)
+			}
+			p.bodyTransforms[interaction.Description] = names
+		}
+		if interaction.bodyMatching != "" {
+			if p.bodyMatching == nil {
+				p.bodyMatching = make(map[string]BodyMatching)
+			}
+			p.bodyMatching[interaction.Description] = interaction.bodyMatching
+		}
+		if generators := interaction.pactHeaderGenerators(); len(generators) > 0 {
+			if p.headerGenerators == nil {
+				p.headerGenerators = make(map[string]map[string]interface{})
+			}
+			p.headerGenerators[interaction.Description] = generators
+		}
+		if sides := interaction.emptyBodySides(); len(sides) > 0 {
+			if p.emptyBodies == nil {
+				p.emptyBodies = make(map[string][]string)
+			}
+			p.emptyBodies[interaction.Description] = sides
+		}
+		interaction.clearBodyExpectations()
+
 		err = mockServer.AddInteraction(interaction)
 		if err != nil {
 			return err
 		}
+
+		if p.negotiator != nil {
+			p.negotiator.register(interaction)
+		}
+		if p.sequencer != nil {
+			p.sequencer.register(interaction)
+		}
+
+		p.recordInteractionDetails(interaction)
 	}
 
 	// Run the integration test
@@ -276,29 +601,164 @@ func (p *Pact) Verify(integrationTest func() error) error {
 	// Run Verification Process
 	err = mockServer.Verify()
 	if err != nil {
-		return err
+		return &MismatchError{Message: err.Error(), Mismatches: p.verificationMismatches(err.Error())}
 	}
+	p.recordVerified(p.Interactions)
 
 	return err
 }
 
+// recordInteractionDetails records what the mock server doesn't write to the
+// pact file for the interaction, see completePactFile
+func (p *Pact) recordInteractionDetails(interaction *Interaction) {
+	if descriptions := interaction.fieldDescriptions(); len(descriptions) > 0 {
+		if p.fieldDescriptions == nil {
+			p.fieldDescriptions = make(map[string]map[string]string)
+		}
+		p.fieldDescriptions[interaction.Description] = descriptions
+	}
+
+	if len(interaction.metadata) > 0 {
+		if p.interactionMetadata == nil {
+			p.interactionMetadata = make(map[string]map[string]string)
+		}
+		p.interactionMetadata[interaction.Description] = interaction.metadata
+	}
+
+	if interaction.hasStateParams() {
+		if p.providerStates == nil {
+			p.providerStates = make(map[string][]State)
+		}
+		p.providerStates[interaction.Description] = interaction.States
+	}
+}
+
+// AssertNoUnexpectedRequests fails the test if the mock server has received any
+// requests that did not match an interaction. It may be called at any point
+// during a test, unlike Verify which also requires all interactions to have
+// been called.
+func (p *Pact) AssertNoUnexpectedRequests(t *testing.T) {
+	t.Helper()
+
+	var unexpected []string
+	if p.nativeServer != nil {
+		unexpected = describeMismatches(p.nativeServer.UnexpectedRequests())
+	} else {
+		mockServer := &MockService{
+			BaseURL:  fmt.Sprintf("http://%s:%d", p.Host, p.Server.Port),
+			Consumer: p.Consumer,
+			Provider: p.Provider,
+		}
+
+		var err error
+		if unexpected, err = mockServer.UnexpectedRequests(); err != nil {
+			t.Fatalf("unable to check the mock server for unexpected requests: %v", err)
+		}
+	}
+
+	if len(unexpected) > 0 {
+		t.Errorf("mock server received unexpected requests:\n\t%s", strings.Join(unexpected, "\n\t"))
+	}
+}
+
 // WritePact should be called writes when all tests have been performed for a
 // given Consumer <-> Provider pair. It will write out the Pact to the
 // configured file.
 func (p *Pact) WritePact() error {
 	p.Setup(true)
 	log.Println("[DEBUG] pact write Pact file")
+	if p.nativeServer != nil {
+		return p.writeNativePact()
+	}
+
 	mockServer := MockService{
 		BaseURL:           fmt.Sprintf("http://%s:%d", p.Host, p.Server.Port),
 		Consumer:          p.Consumer,
 		Provider:          p.Provider,
 		PactFileWriteMode: p.PactFileWriteMode,
 	}
+	file := filepath.Join(p.PactDir, pactFileName(p.Consumer, p.Provider))
+
+	// The mock service merges with version 3 pacts at most
+	if p.SpecificationVersion >= 4 && p.PactFileWriteMode == "merge" {
+		if err := convertPactFile(file, 3); err != nil {
+			return err
+		}
+	}
+
 	err := mockServer.WritePact()
 	if err != nil {
 		return err
 	}
 
+	if err = p.completePactFile(file); err != nil {
+		return err
+	}
+
+	return writeSpecificationVersions(file, p.PactDir, p.AdditionalSpecificationVersions)
+}
+
+// completePactFile adds what the mock service doesn't write to the pact file
+// it wrote, such as metadata, generators and trailers, and converts it to
+// the SpecificationVersion
+func (p *Pact) completePactFile(file string) error {
+	// Redact first, so that secrets are scrubbed even if a later step fails
+	err := redactPactFile(file, p.Redaction)
+	if err != nil {
+		return err
+	}
+
+	if p.sequencer != nil {
+		if err = removeSequenceHeaders(file); err != nil {
+			return err
+		}
+	}
+
+	if err = writeFieldDescriptions(file, p.fieldDescriptions); err != nil {
+		return err
+	}
+
+	if err = writeInteractionMetadata(file, p.interactionMetadata); err != nil {
+		return err
+	}
+
+	if err = writeProviderStates(file, p.providerStates); err != nil {
+		return err
+	}
+
+	if err = writeEmptyBodies(file, p.emptyBodies); err != nil {
+		return err
+	}
+
+	if err = writeBodyMatching(file, p.bodyMatching); err != nil {
+		return err
+	}
+
+	if err = writeBodyTransforms(file, p.bodyTransforms); err != nil {
+		return err
+	}
+
+	if err = writeTrailers(file, p.trailers, p.SpecificationVersion >= 3); err != nil {
+		return err
+	}
+
+	// Generators are only part of version 3 (and later) pacts
+	if p.SpecificationVersion >= 3 {
+		if err = writeHeaderGenerators(file, p.headerGenerators); err != nil {
+			return err
+		}
+	}
+
+	if p.RecordGeneration {
+		if err = writeGeneration(file); err != nil {
+			return err
+		}
+	}
+
+	if p.SpecificationVersion >= 4 {
+		return convertPactFile(file, 4)
+	}
+
 	return nil
 }
 
@@ -307,8 +767,22 @@ func (p *Pact) WritePact() error {
 //
 // Order of events: BeforeEach, stateHandlers, requestFilter(pre <execute provider> post), AfterEach
 func (p *Pact) VerifyProviderRaw(request types.VerifyRequest) ([]types.ProviderVerifierResponse, error) {
+	var recorder *exchangeRecorder
+	if request.TriageDir != "" {
+		recorder = &exchangeRecorder{}
+	}
+
+	res, err := p.verifyProviderRaw(request, recorder)
+	err = writeTriageBundle(request, res, err, recorder)
+
+	return res, writeVerificationSummary(request.SummaryFile, res, err)
+}
+
+func (p *Pact) verifyProviderRaw(request types.VerifyRequest, recorder *exchangeRecorder) ([]types.ProviderVerifierResponse, error) {
 	p.Setup(false)
 	res := make([]types.ProviderVerifierResponse, 0)
+	detectVersion(request.VersionDetection, &request.ProviderVersion, &request.ProviderBranch)
+	checkDeprecatedVerifyRequest(request)
 
 	u, err := url.Parse(request.ProviderBaseURL)
 
@@ -316,6 +790,43 @@ func (p *Pact) VerifyProviderRaw(request types.VerifyRequest) ([]types.ProviderV
 		return res, err
 	}
 
+	if err = checkDeprecations(request.Deprecations, request.PactURLs); err != nil {
+		return res, err
+	}
+
+	if _, err = checkPactAges(request.PactURLs, request.MaxPactAge); err != nil {
+		return res, err
+	}
+
+	if len(request.MetadataFilter) > 0 || len(request.InteractionTags) > 0 || len(request.SkipInteractionTags) > 0 {
+		if request.BrokerURL != "" {
+			return res, errors.New("'MetadataFilter' and 'InteractionTags' are only supported for local pact files, not with 'BrokerURL'")
+		}
+		keep := metadataFilter(request.MetadataFilter, request.InteractionTags, request.SkipInteractionTags)
+		filtered, cleanup, err := filterPactsByMetadata(request.PactURLs, keep)
+		if err != nil {
+			return res, err
+		}
+		defer cleanup()
+		request.PactURLs = filtered
+	}
+
+	var pacts []PactForVerification
+	if request.VerifyPactsIndividually {
+		if request.Provider == "" {
+			request.Provider = p.Provider
+		}
+		if pacts, err = individualPacts(request); err != nil {
+			return res, err
+		}
+	}
+
+	stopDependencies, err := startDependencies(request.Dependencies)
+	if err != nil {
+		return res, err
+	}
+	defer stopDependencies()
+
 	m := []proxy.Middleware{}
 
 	if request.BeforeEach != nil {
@@ -326,22 +837,97 @@ func (p *Pact) VerifyProviderRaw(request types.VerifyRequest) ([]types.ProviderV
 		m = append(m, AfterEachMiddleware(request.AfterEach))
 	}
 
-	if len(request.StateHandlers) > 0 {
-		m = append(m, stateHandlerMiddleware(request.StateHandlers))
+	if len(request.StateHandlers) > 0 || len(request.StateHandlersWithParams) > 0 {
+		m = append(m, stateHandlerMiddlewareWithParams(
+			retryingStateHandlers(request.StateHandlers, request.StateSetupRetries, request.StateSetupRetryDelay),
+			retryingStateHandlersWithParams(request.StateHandlersWithParams, request.StateSetupRetries, request.StateSetupRetryDelay)))
+	}
+
+	// Retry the provider's own state setup URL by routing it through the proxy
+	retrySetupURL := request.ProviderStatesSetupURL != "" && request.StateSetupRetries > 0
+	if retrySetupURL {
+		m = append(m, stateSetupURLMiddleware(request.ProviderStatesSetupURL, request.StateSetupRetries, request.StateSetupRetryDelay))
+	}
+
+	// Convert recorded MessagePack bodies to and from their wire form, ahead
+	// of any filter so that it sees the bytes sent to the provider
+	m = append(m, msgpackMiddleware(base64ToMsgPack, msgpackToBase64))
+
+	if recorder != nil {
+		m = append(m, recorder.middleware)
+	}
+
+	// Pacts from a broker are only known if verified individually
+	knownPactURLs := request.PactURLs
+	if request.VerifyPactsIndividually {
+		knownPactURLs = nil
+		for _, pact := range pacts {
+			knownPactURLs = append(knownPactURLs, pact.URL)
+		}
+	}
+	strict, err := newStrictResponses(knownPactURLs, request)
+	if err != nil {
+		return res, err
+	}
+	if strict != nil {
+		m = append(m, strict.middleware)
+	}
+	if numbers := newNumericResponses(knownPactURLs, request); numbers != nil {
+		m = append(m, numbers.responseMiddleware)
+	}
+
+	// Unwrap responses before they are checked, and wrap requests after
+	transformer, err := newProviderBodyTransforms(knownPactURLs, request)
+	if err != nil {
+		return res, err
+	}
+	if transformer != nil {
+		m = append(m, transformer.middleware)
+	}
+	if request.UnicodeNormalization != nil {
+		m = append(m, unicodeNormaliser(request.UnicodeNormalization).responseMiddleware)
+	}
+
+	// Fingerprint responses ahead of the state handlers, to see each state
+	drift, err := newSchemaDrift(knownPactURLs, request)
+	if err != nil {
+		return res, err
+	}
+	if drift != nil {
+		m = append([]proxy.Middleware{drift.middleware}, m...)
 	}
 
 	if request.RequestFilter != nil {
 		m = append(m, request.RequestFilter)
 	}
 
+	// Send and check trailers closest to the provider, as the verifier
+	// doesn't support them
+	trailers, err := newProviderTrailers(knownPactURLs, request)
+	if err != nil {
+		return res, err
+	}
+	if trailers != nil {
+		m = append(m, trailers.middleware)
+	}
+
+	tlsConfig, err := providerTLSConfig(request)
+	if err != nil {
+		return res, err
+	}
+
 	// Configure HTTP Verification Proxy
+	unreachable := newUnreachableRecorder(u.Path)
 	opts := proxy.Options{
 		TargetAddress:             fmt.Sprintf("%s:%s", u.Hostname(), u.Port()),
 		TargetScheme:              u.Scheme,
 		TargetPath:                u.Path,
+		TargetHost:                request.ProviderHost,
 		Middleware:                m,
 		InternalRequestPathPrefix: providerStatesSetupPath,
-		CustomTLSConfig:           request.CustomTLSConfig,
+		CustomTLSConfig:           tlsConfig,
+		Transport:                 request.Transport,
+		OnTargetError:             unreachable.record,
 	}
 
 	// Starts the message wrapper API with hooks back to the state handlers
@@ -353,7 +939,7 @@ func (p *Pact) VerifyProviderRaw(request types.VerifyRequest) ([]types.ProviderV
 	// Backwards compatibility, setup old provider states URL if given
 	// Otherwise point to proxy
 	setupURL := request.ProviderStatesSetupURL
-	if request.ProviderStatesSetupURL == "" && len(request.StateHandlers) > 0 {
+	if (request.ProviderStatesSetupURL == "" && (len(request.StateHandlers) > 0 || len(request.StateHandlersWithParams) > 0)) || retrySetupURL {
 		setupURL = fmt.Sprintf("http://localhost:%d%s", port, providerStatesSetupPath)
 	}
 
@@ -366,6 +952,7 @@ func (p *Pact) VerifyProviderRaw(request types.VerifyRequest) ([]types.ProviderV
 		BrokerUsername:             request.BrokerUsername,
 		BrokerPassword:             request.BrokerPassword,
 		BrokerToken:                request.BrokerToken,
+		BrokerAuthenticator:        request.BrokerAuthenticator,
 		PublishVerificationResults: request.PublishVerificationResults,
 		ProviderVersion:            request.ProviderVersion,
 		Provider:                   request.Provider,
@@ -396,13 +983,35 @@ func (p *Pact) VerifyProviderRaw(request types.VerifyRequest) ([]types.ProviderV
 
 	log.Println("[DEBUG] pact provider verification")
 
-	return p.pactClient.VerifyProvider(verificationRequest)
+	if request.UseFFI {
+		err = ffi.Verify(verificationRequest)
+	} else if request.VerifyPactsIndividually {
+		res, err = p.verifyPactsIndividually(verificationRequest, pacts)
+	} else {
+		res, err = p.pactClient.VerifyProvider(verificationRequest)
+		markPendingPacts(res)
+	}
+	if strict != nil {
+		err = strict.fail(res, err)
+	}
+	if trailers != nil {
+		err = trailers.fail(res, err)
+	}
+	if drift != nil {
+		err = drift.report(res, err, request.FailOnSchemaDrift)
+	}
+	categoriseFailures(res, unreachable)
+
+	return res, err
 }
 
 // VerifyProvider accepts an instance of `*testing.T`
 // running the provider verification with granular test reporting and
-// automatic failure reporting for nice, simple tests.
+// automatic failure reporting for nice, simple tests. When built with Go 1.22
+// or later, logs are written to the test's log (see LogToTest).
 func (p *Pact) VerifyProvider(t *testing.T, request types.VerifyRequest) ([]types.ProviderVerifierResponse, error) {
+	defer captureTestLogs(t)()
+
 	res, err := p.VerifyProviderRaw(request)
 
 	if len(res) == 0 {
@@ -486,6 +1095,13 @@ func AfterEachMiddleware(AfterEach types.Hook) proxy.Middleware {
 // any state handlers associated with the provider.
 // It will not execute further middleware if it is the designted "state" request
 func stateHandlerMiddleware(stateHandlers types.StateHandlers) proxy.Middleware {
+	return stateHandlerMiddlewareWithParams(stateHandlers, nil)
+}
+
+// stateHandlerMiddlewareWithParams is stateHandlerMiddleware, also calling
+// the handlers of states with parameters with the parameters given by the
+// verifier
+func stateHandlerMiddlewareWithParams(stateHandlers types.StateHandlers, withParams types.StateHandlersWithParams) proxy.Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path == providerStatesSetupPath {
@@ -499,6 +1115,15 @@ func stateHandlerMiddleware(stateHandlers types.StateHandlers) proxy.Middleware
 
 				// Setup any provider state
 				for _, state := range s.States {
+					if handler, ok := withParams[state]; ok {
+						if err := handler(s.Params); err != nil {
+							log.Printf("[ERROR] state handler for '%v' errored: %v", state, err)
+							w.WriteHeader(http.StatusInternalServerError)
+							return
+						}
+						continue
+					}
+
 					sf, stateFound := stateHandlers[state]
 
 					if !stateFound {
@@ -630,7 +1255,9 @@ func runTestCases(t *testing.T, res []types.ProviderVerifierResponse) {
 			}
 			for _, example := range test.Examples {
 				testCase := example.Description
-				if example.Status == "pending" {
+				if example.Pact.WIP {
+					testCase = fmt.Sprintf("WIP %s", example.Description)
+				} else if example.Status == "pending" {
 					testCase = fmt.Sprintf("Pending %s", example.Description)
 				}
 
@@ -640,6 +1267,8 @@ func runTestCases(t *testing.T, res []types.ProviderVerifierResponse) {
 					if example.Status != "passed" {
 						if example.Status == "pending" {
 							st.Skip(example.Exception.Message)
+						} else if example.Category != "" {
+							st.Errorf("%s\n%s: %s\n", example.FullDescription, example.Category, example.Exception.Message)
 						} else {
 							st.Errorf("%s\n%s\n", example.FullDescription, example.Exception.Message)
 						}
@@ -661,8 +1290,16 @@ func runTestCases(t *testing.T, res []types.ProviderVerifierResponse) {
 // It is the initiator of an interaction, and expects something on the other end
 // of the interaction to respond - just in this case, not immediately.
 func (p *Pact) VerifyMessageProviderRaw(request VerifyMessageRequest) ([]types.ProviderVerifierResponse, error) {
+	res, err := p.verifyMessageProviderRaw(request)
+
+	return res, writeVerificationSummary(request.SummaryFile, res, err)
+}
+
+func (p *Pact) verifyMessageProviderRaw(request VerifyMessageRequest) ([]types.ProviderVerifierResponse, error) {
 	p.Setup(false)
 	response := make([]types.ProviderVerifierResponse, 0)
+	detectVersion(request.VersionDetection, &request.ProviderVersion, &request.ProviderBranch)
+	checkDeprecatedSelectors(request.ConsumerVersionSelectors)
 
 	// Starts the message wrapper API with hooks back to the message handlers
 	// This maps the 'description' field of a message pact, to a function handler
@@ -676,22 +1313,15 @@ func (p *Pact) VerifyMessageProviderRaw(request VerifyMessageRequest) ([]types.P
 	}
 
 	// Construct verifier request
-	verificationRequest := types.VerifyRequest{
-		ProviderBaseURL:            fmt.Sprintf("http://localhost:%d", port),
-		PactURLs:                   request.PactURLs,
-		BrokerURL:                  request.BrokerURL,
-		Tags:                       request.Tags,
-		ConsumerVersionSelectors:   request.ConsumerVersionSelectors,
-		BrokerUsername:             request.BrokerUsername,
-		BrokerPassword:             request.BrokerPassword,
-		BrokerToken:                request.BrokerToken,
-		PublishVerificationResults: request.PublishVerificationResults,
-		ProviderVersion:            request.ProviderVersion,
-		ProviderTags:               request.ProviderTags,
-		Provider:                   p.Provider,
+	verificationRequest := request.verifyRequest(p.Provider)
+	verificationRequest.ProviderBaseURL = fmt.Sprintf("http://localhost:%d", port)
+
+	messageHandlers := request.MessageHandlers
+	if request.MessageBroker != nil {
+		messageHandlers = brokerMessageHandlers(messageHandlers, request.MessageBroker)
 	}
 
-	mux.HandleFunc("/", messageVerificationHandler(request.MessageHandlers, request.StateHandlers))
+	mux.HandleFunc("/", messageVerificationHandler(messageHandlers, request.StateHandlers))
 
 	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
@@ -728,6 +1358,12 @@ func (p *Pact) VerifyMessageProviderRaw(request VerifyMessageRequest) ([]types.P
 // request was provided.
 func (p *Pact) VerifyMessageConsumerRaw(message *Message, handler MessageConsumer) error {
 	log.Println("[DEBUG] verify message")
+	if message.err != nil {
+		return message.err
+	}
+	if err := p.resolveMessagePlaceholders(message); err != nil {
+		return err
+	}
 	p.Setup(false)
 
 	// Reify the message back to its "example/generated" form
@@ -739,10 +1375,44 @@ func (p *Pact) VerifyMessageConsumerRaw(message *Message, handler MessageConsume
 		return fmt.Errorf("unable to convert consumer test to a valid JSON representation: %v", err)
 	}
 
+	if err = yieldMessage(message, reified.ResponseRaw, handler); err != nil {
+		return err
+	}
+
+	// If no errors, update Message Pact
+	err = p.pactClient.UpdateMessagePact(types.PactMessageRequest{
+		Message:  message,
+		Consumer: p.Consumer,
+		Provider: p.Provider,
+		PactDir:  p.PactDir,
+	})
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(p.PactDir, pactFileName(p.Consumer, p.Provider))
+	if p.RecordGeneration {
+		if err = writeGeneration(file); err != nil {
+			return err
+		}
+	}
+
+	return writeSpecificationVersions(file, p.PactDir, p.AdditionalSpecificationVersions)
+}
+
+// yieldMessage sends the message to the handler, with its reified content
+// decoded into the message's Type
+func yieldMessage(message *Message, reified []byte, handler MessageConsumer) error {
+	var err error
 	t := reflect.TypeOf(message.Type)
-	if t != nil && t.Name() != "interface" {
+	if t != nil && message.contentType != "" {
+		log.Println("[DEBUG] decoding", message.contentType, "content to type", t.Name())
+		if message.Type, err = decodeContent(message.contentType, reified, message.Type); err != nil {
+			return err
+		}
+	} else if t != nil && t.Name() != "interface" {
 		log.Println("[DEBUG] narrowing type to", t.Name())
-		err = json.Unmarshal(reified.ResponseRaw, &message.Type)
+		err = json.Unmarshal(reified, &message.Type)
 
 		if err != nil {
 			return fmt.Errorf("unable to narrow type to %v: %v", t.Name(), err)
@@ -758,18 +1428,7 @@ func (p *Pact) VerifyMessageConsumerRaw(message *Message, handler MessageConsume
 			Metadata:    message.Metadata,
 		}
 
-	err = handler(generatedMessage)
-	if err != nil {
-		return err
-	}
-
-	// If no errors, update Message Pact
-	return p.pactClient.UpdateMessagePact(types.PactMessageRequest{
-		Message:  message,
-		Consumer: p.Consumer,
-		Provider: p.Provider,
-		PactDir:  p.PactDir,
-	})
+	return handler(generatedMessage)
 }
 
 // VerifyMessageConsumer is a test convience function for VerifyMessageConsumerRaw,
@@ -0,0 +1,82 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+// Document returns the pact as WritePact would write it, with the
+// interactions verified so far, their matching rules and generators, so
+// that tests can make assertions about the contract before it is written or
+// published e.g. that no interaction matches on an exact UUID. Interactions
+// merged from an existing pact file (see PactFileWriteMode) are not
+// included.
+func (p *Pact) Document() (*pactfile.Pact, error) {
+	version := 2
+	if p.SpecificationVersion >= 3 {
+		version = 3
+	}
+
+	interactions := make([]interface{}, len(p.verified))
+	for n, i := range p.verified {
+		interaction := (&nativeInteraction{Interaction: i}).serialise()
+		// Several states, or states with parameters, are added to version 2
+		// pacts as for the mock service's pacts
+		if version == 2 && len(i.States) > 0 {
+			delete(interaction, "providerStates")
+			interaction["providerState"] = i.State
+		}
+		interactions[n] = interaction
+	}
+	content, err := json.Marshal(map[string]interface{}{
+		"consumer":     map[string]string{"name": p.Consumer},
+		"provider":     map[string]string{"name": p.Provider},
+		"interactions": interactions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content, err = pactfile.ConvertSpecification(content, version); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "pact-document")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, pactFileName(p.Consumer, p.Provider))
+	if err = ioutil.WriteFile(file, content, 0644); err != nil {
+		return nil, err
+	}
+	if err = p.completePactFile(file); err != nil {
+		return nil, err
+	}
+
+	return pactfile.Read(file)
+}
+
+// recordVerified records the interactions of a successful verification,
+// replacing earlier interactions with the same description and provider
+// states, as the mock service does
+func (p *Pact) recordVerified(interactions []*Interaction) {
+	for _, interaction := range interactions {
+		key := interactionKey(interaction)
+		replaced := false
+		for n, verified := range p.verified {
+			if interactionKey(verified) == key {
+				p.verified[n] = interaction
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			p.verified = append(p.verified, interaction)
+		}
+	}
+}
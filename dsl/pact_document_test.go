@@ -0,0 +1,57 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPact_Document(t *testing.T) {
+	ms := setupMockServer(true, t)
+	defer ms.Close()
+
+	pact := &Pact{
+		Server:               &types.MockServer{Port: getPort(ms.URL)},
+		Consumer:             "My Consumer",
+		Provider:             "My Provider",
+		SpecificationVersion: 3,
+	}
+
+	if document, err := pact.Document(); err != nil || len(document.Interactions) != 0 {
+		t.Fatalf("expected an empty pact before verification, got %v", err)
+	}
+
+	for _, status := range []int{200, 201} {
+		pact.AddInteraction().
+			Given("user 1 exists").
+			UponReceiving("a request for user 1").
+			WithRequest(Request{Method: "GET", Path: Term("/users/5b1c4e2a-9a3f-4b8e-8f5e-1c2d3e4f5a6b", `^/users/[0-9a-f-]{36}$`)}).
+			WillRespondWith(Response{Status: status, Body: StructMatcher{"id": Like(1)}}).
+			WithMetadata("team", "accounts")
+		if err := pact.Verify(func() error { return nil }); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	document, err := pact.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(document.Interactions) != 1 || document.Interactions[0].Response.Status != 201 {
+		t.Fatalf("expected the interaction verified again to replace the earlier one, got %+v", document.Interactions)
+	}
+	interaction := document.Interactions[0]
+	if interaction.ProviderState != "" || !strings.Contains(string(interaction.ProviderStates), "user 1 exists") {
+		t.Fatalf("expected version 3 provider states, got %s", interaction.ProviderStates)
+	}
+	if rules := compactJSON(interaction.Request.MatchingRules); !strings.Contains(rules, `"path":{"combine":"AND","matchers":[{"match":"regex","regex":"^/users/[0-9a-f-]{36}$"}]}`) {
+		t.Fatalf("expected the path to be matched by regex, got %s", rules)
+	}
+	if rules := compactJSON(interaction.Response.MatchingRules); !strings.Contains(rules, `"body":{"$.id":{"combine":"AND","matchers":[{"match":"type"}]}}`) {
+		t.Fatalf("expected the body to be matched by type, got %s", rules)
+	}
+	if interaction.Metadata["team"] != "accounts" {
+		t.Fatalf("expected the interaction metadata, got %v", interaction.Metadata)
+	}
+}
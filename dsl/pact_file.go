@@ -0,0 +1,123 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+// pactFileName returns the name of the pact file written by the mock service
+// for the given consumer/provider pair
+func pactFileName(consumer, provider string) string {
+	filenamify := func(name string) string {
+		return strings.Join(strings.Fields(strings.ToLower(name)), "_")
+	}
+
+	return fmt.Sprintf("%s-%s.json", filenamify(consumer), filenamify(provider))
+}
+
+// rewritePactFile applies the rewrite function to each interaction in the
+// pact file written by the mock service
+func rewritePactFile(file string, rewrite func(interaction map[string]interface{})) error {
+	return rewritePact(file, func(pact map[string]interface{}) {
+		interactions, _ := pact["interactions"].([]interface{})
+		for _, raw := range interactions {
+			if interaction, ok := raw.(map[string]interface{}); ok {
+				rewrite(interaction)
+			}
+		}
+	})
+}
+
+// rewritePact applies the rewrite function to the pact file written by the
+// mock service
+func rewritePact(file string, rewrite func(pact map[string]interface{})) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Println("[WARN] unable to find pact file", file, "to update")
+			return nil
+		}
+		return err
+	}
+
+	var pact map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(content)))
+	decoder.UseNumber()
+	if err = decoder.Decode(&pact); err != nil {
+		return fmt.Errorf("unable to parse pact file %s: %v", file, err)
+	}
+
+	rewrite(pact)
+
+	out, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Clean(file), out, 0644)
+}
+
+// writeSpecificationVersions converts the pact file written by the mock
+// service to each of the specification versions, writing them to a
+// "v<version>" subdirectory of the pact directory
+func writeSpecificationVersions(file string, pactDir string, versions []int) error {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Println("[WARN] unable to find pact file", file, "to convert")
+			return nil
+		}
+		return err
+	}
+
+	for _, version := range versions {
+		converted, err := pactfile.ConvertSpecification(content, version)
+		if err != nil {
+			return fmt.Errorf("unable to write pact file %s as specification version %d: %v", file, version, err)
+		}
+
+		dir := filepath.Join(pactDir, fmt.Sprintf("v%d", version))
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		out := filepath.Join(dir, filepath.Base(file))
+		log.Println("[DEBUG] writing specification version", version, "pact file", out)
+		if err = ioutil.WriteFile(out, converted, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// convertPactFile converts the pact file written by the mock service to the
+// specification version in place
+func convertPactFile(file string, version int) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	converted, err := pactfile.ConvertSpecification(content, version)
+	if err != nil {
+		return fmt.Errorf("unable to write pact file %s as specification version %d: %v", file, version, err)
+	}
+
+	log.Println("[DEBUG] converting pact file", file, "to specification version", version)
+	return ioutil.WriteFile(filepath.Clean(file), converted, 0644)
+}
@@ -0,0 +1,77 @@
+package dsl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSpecificationVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-go-versions")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "billing-accounts.json")
+	pact := `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "interactions": [{"description": "a request", "request": {"method": "GET", "path": "/"}, "response": {"status": 200}}],
+  "metadata": {"pactSpecification": {"version": "2.0.0"}}
+}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatalf("unable to write pact: %v", err)
+	}
+
+	if err = writeSpecificationVersions(file, dir, []int{3, 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for version, want := range map[string]string{"v3": `"3.0.0"`, "v4": `"Synchronous/HTTP"`} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, version, "billing-accounts.json"))
+		if err != nil {
+			t.Fatalf("expected a %s pact file: %v", version, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("expected the %s pact file to contain %s, got %s", version, want, content)
+		}
+	}
+
+	if err = writeSpecificationVersions(file, dir, []int{7}); err == nil {
+		t.Fatal("expected an unsupported version error")
+	}
+}
+
+func TestConvertPactFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-go-convert")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "billing-accounts.json")
+	pact := `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "interactions": [{"description": "a request", "request": {"method": "GET", "path": "/"}, "response": {"status": 200}}],
+  "metadata": {"pactSpecification": {"version": "3.0.0"}}
+}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatalf("unable to write pact: %v", err)
+	}
+
+	if err = convertPactFile(file, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := ioutil.ReadFile(file)
+	if !strings.Contains(string(content), `"Synchronous/HTTP"`) || !strings.Contains(string(content), `"4.0"`) {
+		t.Fatalf("expected a version 4 pact, got %s", content)
+	}
+
+	if err = convertPactFile(filepath.Join(dir, "missing.json"), 4); err != nil {
+		t.Fatalf("expected a missing pact file to be ignored, got %v", err)
+	}
+}
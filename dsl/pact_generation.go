@@ -0,0 +1,116 @@
+package dsl
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+// gitSHAVariables are the environment variables CI systems set to the
+// commit being built, checked before asking git
+var gitSHAVariables = []string{"GIT_COMMIT", "GITHUB_SHA", "CI_COMMIT_SHA", "CIRCLE_SHA1", "TRAVIS_COMMIT", "BUILDKITE_COMMIT"}
+
+// writeGeneration records when the pact file was generated, and from which
+// version of the consumer, in its metadata
+func writeGeneration(file string) error {
+	generation := currentGeneration()
+
+	return rewritePact(file, func(pact map[string]interface{}) {
+		metadata, _ := pact["metadata"].(map[string]interface{})
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+			pact["metadata"] = metadata
+		}
+		metadata[pactfile.GenerationKey] = generation
+	})
+}
+
+// currentGeneration describes the pact being generated now, by the current
+// commit and Go module
+func currentGeneration() pactfile.Generation {
+	generation := pactfile.Generation{
+		Timestamp: now().UTC().Truncate(time.Second),
+		GitSHA:    gitSHA(),
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		generation.Module = info.Main.Path
+		if info.Main.Version != "(devel)" {
+			generation.ModuleVersion = info.Main.Version
+		}
+	}
+
+	return generation
+}
+
+// gitSHA returns the commit being built, or "" if unknown
+func gitSHA() string {
+	if sha := firstVariable(gitSHAVariables); sha != "" {
+		return sha
+	}
+
+	return gitOutput("rev-parse", "HEAD")
+}
+
+// checkPactAges warns about local pact files generated longer than maxAge
+// ago, as their consumer may have been abandoned. The warnings are also
+// returned.
+func checkPactAges(pactURLs []string, maxAge time.Duration) ([]string, error) {
+	if maxAge <= 0 {
+		return nil, nil
+	}
+
+	var warnings []string
+	for _, location := range pactURLs {
+		if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+			log.Println("[DEBUG] skipping age check for remote pact", location)
+			continue
+		}
+
+		files, err := expandPactFiles([]string{location})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			pact, err := pactfile.Read(file)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read pact file %s: %v", file, err)
+			}
+
+			generation, ok := pact.Generation()
+			if !ok {
+				log.Println("[DEBUG] pact file", file, "does not record when it was generated")
+				continue
+			}
+
+			age := now().Sub(generation.Timestamp)
+			if age <= maxAge {
+				continue
+			}
+
+			warning := fmt.Sprintf("pact between %s and %s was generated %s ago", pact.Consumer.Name, pact.Provider.Name, formatAge(age))
+			if generation.GitSHA != "" {
+				warning += fmt.Sprintf(" from commit %s", generation.GitSHA)
+			}
+			warning += fmt.Sprintf(", longer than the maximum of %s: is the consumer still maintained?", formatAge(maxAge))
+			log.Println("[WARN]", warning)
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return warnings, nil
+}
+
+// formatAge formats a duration in days when longer than two days
+func formatAge(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	}
+
+	return d.Round(time.Second).String()
+}
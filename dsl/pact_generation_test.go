@@ -0,0 +1,97 @@
+package dsl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+func TestWriteGeneration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-generation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	frozen := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	SetClock(func() time.Time { return frozen })
+	defer SetClock(nil)
+
+	os.Setenv("GIT_COMMIT", "0a1b2c3")
+	defer os.Unsetenv("GIT_COMMIT")
+
+	file := filepath.Join(dir, "billing-accounts.json")
+	ioutil.WriteFile(file, []byte(`{
+		"consumer": {"name": "billing"},
+		"provider": {"name": "accounts"},
+		"interactions": [],
+		"metadata": {"pactSpecification": {"version": "2.0.0"}}
+	}`), 0644)
+
+	if err = writeGeneration(file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pact, err := pactfile.Read(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	generation, ok := pact.Generation()
+	if !ok {
+		t.Fatal("expected the generation to be recorded")
+	}
+	if !generation.Timestamp.Equal(frozen.Truncate(time.Second)) || generation.GitSHA != "0a1b2c3" {
+		t.Fatalf("unexpected generation %+v", generation)
+	}
+	if pact.SpecificationVersion() != "2.0.0" {
+		t.Fatal("expected the existing metadata to be kept")
+	}
+}
+
+func TestCheckPactAges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-ages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	SetClock(func() time.Time { return time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC) })
+	defer SetClock(nil)
+
+	write := func(name string, metadata string) string {
+		file := filepath.Join(dir, name)
+		ioutil.WriteFile(file, []byte(`{
+			"consumer": {"name": "`+strings.TrimSuffix(name, ".json")+`"},
+			"provider": {"name": "accounts"},
+			"interactions": [],
+			"metadata": `+metadata+`
+		}`), 0644)
+		return file
+	}
+	old := write("billing.json", `{"generated": {"timestamp": "2020-01-01T00:00:00Z", "gitSha": "0a1b2c3"}}`)
+	recent := write("invoicing.json", `{"generated": {"timestamp": "2020-02-28T00:00:00Z"}}`)
+	unknown := write("payroll.json", `{}`)
+
+	warnings, err := checkPactAges([]string{old, recent, unknown, "https://broker/pacts/1"}, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "pact between billing and accounts was generated 60 days ago from commit 0a1b2c3, longer than the maximum of 30 days: is the consumer still maintained?"
+	if len(warnings) != 1 || warnings[0] != want {
+		t.Fatalf("want [%s], got %v", want, warnings)
+	}
+
+	if warnings, _ = checkPactAges([]string{old}, 0); len(warnings) != 0 {
+		t.Fatalf("expected no checks without a maximum age, got %v", warnings)
+	}
+
+	if _, err = checkPactAges([]string{filepath.Join(dir, "missing.json")}, time.Hour); err == nil {
+		t.Fatal("expected an error for a missing pact file")
+	}
+}
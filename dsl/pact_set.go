@@ -0,0 +1,192 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// PactSet manages the pacts between a consumer and each of its providers,
+// within a single test suite. Each provider has its own Pact, mock server and
+// pact file, created from the shared configuration on first use.
+type PactSet struct {
+	config Pact
+
+	mu    sync.Mutex
+	pacts map[string]*Pact
+	order []string
+}
+
+// ProviderError is returned by PactSet.Verify when the pact of a provider
+// fails verification
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("provider '%s': %v", e.Provider, e.Err)
+}
+
+// NewPactSet creates a set of pacts sharing the given configuration e.g.
+// Consumer, PactDir, LogDir and DefaultRequestHeaders. The Provider (and
+// Server) of the configuration are ignored.
+func NewPactSet(config Pact) *PactSet {
+	return &PactSet{
+		config: config,
+		pacts:  make(map[string]*Pact),
+	}
+}
+
+// Provider returns the Pact for the given provider, creating it on first
+// use. Its configuration may be changed before any interactions are added.
+func (s *PactSet) Provider(provider string) *Pact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.pacts[provider]; ok {
+		return p
+	}
+
+	c := s.config
+	p := &Pact{
+		pactClient:                      c.pactClient,
+		Consumer:                        c.Consumer,
+		Provider:                        provider,
+		LogLevel:                        c.LogLevel,
+		LogDir:                          c.LogDir,
+		PactDir:                         c.PactDir,
+		PactFileWriteMode:               c.PactFileWriteMode,
+		SpecificationVersion:            c.SpecificationVersion,
+		AdditionalSpecificationVersions: c.AdditionalSpecificationVersions,
+		Host:                            c.Host,
+		Network:                         c.Network,
+		AllowedMockServerPorts:          c.AllowedMockServerPorts,
+		DisableToolValidityCheck:        c.DisableToolValidityCheck,
+		ClientTimeout:                   c.ClientTimeout,
+		DefaultRequestHeaders:           c.DefaultRequestHeaders,
+		DefaultResponseHeaders:          c.DefaultResponseHeaders,
+		UseNativeMockServer:             c.UseNativeMockServer,
+		ContentNegotiation:              c.ContentNegotiation,
+		RecordMismatches:                c.RecordMismatches,
+		ExplicitBodies:                  c.ExplicitBodies,
+		RecordGeneration:                c.RecordGeneration,
+		StrictValidation:                c.StrictValidation,
+		ValidateExamples:                c.ValidateExamples,
+		Redaction:                       c.Redaction,
+		Resolvers:                       c.Resolvers,
+		HARDir:                          c.HARDir,
+		Explain:                         c.Explain,
+		ExplainOutput:                   c.ExplainOutput,
+		Limits:                          c.Limits,
+		UnicodeNormalization:            c.UnicodeNormalization,
+	}
+	s.pacts[provider] = p
+	s.order = append(s.order, provider)
+
+	return p
+}
+
+// Pacts returns the pact of each provider, in the order they were created
+func (s *PactSet) Pacts() []*Pact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pacts := make([]*Pact, len(s.order))
+	for i, provider := range s.order {
+		pacts[i] = s.pacts[provider]
+	}
+
+	return pacts
+}
+
+// Verify runs the integration test once against the interactions of every
+// provider that has any, then verifies each mock server received the
+// expected requests. An error from the test is returned unchanged, otherwise
+// each provider failing verification is reported as a ProviderError.
+// Interactions are cleared afterwards, as with Pact.Verify.
+func (s *PactSet) Verify(integrationTest func() error) error {
+	var pacts []*Pact
+	for _, p := range s.Pacts() {
+		if len(p.Interactions) > 0 {
+			pacts = append(pacts, p)
+		}
+	}
+	if len(pacts) == 0 {
+		return errors.New("there are no interactions to be verified")
+	}
+
+	// Each pact's Verify registers its interactions and runs the next, so
+	// that the test runs once all mock servers are ready. Only the test's
+	// own error is passed back out, so that every pact is verified.
+	var failures []error
+	test := integrationTest
+	for i := len(pacts) - 1; i >= 0; i-- {
+		p, next := pacts[i], test
+		test = func() error {
+			var testErr error
+			err := p.Verify(func() error {
+				testErr = next()
+				return testErr
+			})
+			if testErr != nil {
+				return testErr
+			}
+			if err != nil {
+				failures = append(failures, &ProviderError{Provider: p.Provider, Err: err})
+			}
+			return nil
+		}
+	}
+	err := test()
+
+	// Pacts not reached due to an earlier failure still have interactions
+	for _, p := range pacts {
+		p.Interactions = make([]*Interaction, 0)
+	}
+
+	switch {
+	case err != nil:
+		return err
+	case len(failures) == 1:
+		return failures[0]
+	case len(failures) > 1:
+		messages := make([]string, len(failures))
+		for i, failure := range failures {
+			messages[i] = failure.Error()
+		}
+		return fmt.Errorf("%d providers failed verification:\n%s", len(failures), strings.Join(messages, "\n"))
+	}
+
+	return nil
+}
+
+// WritePacts writes the pact file of each provider with interactions
+// verified during the suite
+func (s *PactSet) WritePacts() error {
+	var errs []string
+	for _, p := range s.Pacts() {
+		if p.Server == nil {
+			continue
+		}
+		if err := p.WritePact(); err != nil {
+			errs = append(errs, fmt.Sprintf("provider '%s': %v", p.Provider, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to write pact files:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+
+	return nil
+}
+
+// Teardown stops the mock server of every provider
+func (s *PactSet) Teardown() {
+	log.Println("[DEBUG] pact set teardown")
+	for _, p := range s.Pacts() {
+		p.Teardown()
+	}
+}
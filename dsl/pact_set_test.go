@@ -0,0 +1,95 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPactSet_Provider(t *testing.T) {
+	set := NewPactSet(Pact{
+		Consumer:              "web",
+		PactDir:               "/tmp/pacts",
+		DefaultRequestHeaders: MapMatcher{"Authorization": String("Bearer 1234")},
+	})
+
+	orders := set.Provider("orders")
+	users := set.Provider("users")
+
+	if orders == users || set.Provider("orders") != orders {
+		t.Fatal("expected a single, distinct, pact per provider")
+	}
+	if orders.Consumer != "web" || orders.Provider != "orders" || orders.PactDir != "/tmp/pacts" || orders.DefaultRequestHeaders["Authorization"] == nil {
+		t.Fatalf("expected the shared configuration to be applied, got %+v", orders)
+	}
+	if pacts := set.Pacts(); len(pacts) != 2 || pacts[0] != orders || pacts[1] != users {
+		t.Fatalf("unexpected pacts %v", pacts)
+	}
+}
+
+func TestPactSet_Verify(t *testing.T) {
+	verified := map[string]bool{}
+	server := func(provider string, verifies bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/interactions/verification" {
+				verified[provider] = true
+				if !verifies {
+					http.Error(w, "Missing requests", http.StatusInternalServerError)
+					return
+				}
+			}
+			fmt.Fprintln(w, "ok")
+		}))
+	}
+	ordersServer, usersServer := server("orders", true), server("users", false)
+	defer ordersServer.Close()
+	defer usersServer.Close()
+
+	set := NewPactSet(Pact{Consumer: "web", DisableToolValidityCheck: true})
+	set.Provider("orders").Server = &types.MockServer{Port: getPort(ordersServer.URL)}
+	set.Provider("users").Server = &types.MockServer{Port: getPort(usersServer.URL)}
+	set.Provider("stock")
+
+	for _, provider := range []string{"orders", "users"} {
+		set.Provider(provider).
+			AddInteraction().
+			UponReceiving("a request").
+			WithRequest(Request{Method: "GET", Path: String("/")}).
+			WillRespondWith(Response{Status: 200})
+	}
+
+	runs := 0
+	err := set.Verify(func() error {
+		runs++
+		return nil
+	})
+
+	providerErr, ok := err.(*ProviderError)
+	if !ok || providerErr.Provider != "users" {
+		t.Fatalf("expected an error for the users provider, got %v", err)
+	}
+	if runs != 1 || !verified["orders"] || !verified["users"] {
+		t.Fatalf("expected the test to run once and both pacts to be verified, got %d runs and %v", runs, verified)
+	}
+	for _, p := range set.Pacts() {
+		if len(p.Interactions) != 0 {
+			t.Fatalf("expected the interactions of %s to be cleared", p.Provider)
+		}
+	}
+
+	if err = set.Verify(func() error { return nil }); err == nil {
+		t.Fatal("expected an error with no interactions")
+	}
+
+	set.Provider("orders").AddInteraction().UponReceiving("a request").
+		WithRequest(Request{Method: "GET", Path: String("/")}).
+		WillRespondWith(Response{Status: 200})
+	testErr := errors.New("test failed")
+	if err = set.Verify(func() error { return testErr }); err != testErr {
+		t.Fatalf("expected the test's error to be returned unchanged, got %v", err)
+	}
+}
@@ -2,8 +2,10 @@ package dsl
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -95,6 +97,52 @@ func TestPact_VerifyMockServerFail(t *testing.T) {
 	}
 }
 
+func TestPact_VerifyDefaultHeaders(t *testing.T) {
+	ms := setupMockServer(true, t)
+	defer ms.Close()
+
+	pact := &Pact{
+		Server: &types.MockServer{
+			Port: getPort(ms.URL),
+		},
+		Consumer:               "My Consumer",
+		Provider:               "My Provider",
+		DefaultRequestHeaders:  MapMatcher{"Authorization": Term("Bearer 1234", "^Bearer .+$")},
+		DefaultResponseHeaders: MapMatcher{"Content-Type": String("application/json")},
+	}
+
+	i := pact.
+		AddInteraction().
+		UponReceiving("Some name for the test").
+		WithRequest(Request{}).
+		WillRespondWith(Response{Headers: MapMatcher{"Content-Type": String("text/csv")}})
+
+	if err := pact.Verify(func() error { return nil }); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if i.Request.Headers["Authorization"] == nil {
+		t.Fatalf("expected the default request header to be added, got %v", i.Request.Headers)
+	}
+	if i.Response.Headers["Content-Type"] != String("text/csv") {
+		t.Fatalf("expected the interaction's header to take precedence, got %v", i.Response.Headers)
+	}
+}
+
+func TestPact_AssertNoUnexpectedRequests(t *testing.T) {
+	ms := setupMockServer(true, t)
+	defer ms.Close()
+
+	pact := &Pact{
+		Server: &types.MockServer{
+			Port: getPort(ms.URL),
+		},
+		Host: "localhost",
+	}
+
+	pact.AssertNoUnexpectedRequests(t)
+}
+
 func TestPact_WritePact(t *testing.T) {
 	ms := setupMockServer(true, t)
 	defer ms.Close()
@@ -244,6 +292,42 @@ func TestPact_Teardown(t *testing.T) {
 	}
 }
 
+func TestPact_TeardownProxy(t *testing.T) {
+	ms := setupMockServer(true, t)
+	defer ms.Close()
+	c, _ := createMockClient(true)
+
+	pact := &Pact{
+		Server:           &types.MockServer{Port: getPort(ms.URL)},
+		Consumer:         "My Consumer",
+		Provider:         "My Provider",
+		RecordMismatches: true,
+		pactClient:       c,
+	}
+	pact.
+		AddInteraction().
+		UponReceiving("a request for an order").
+		WithRequest(Request{Method: "GET", Path: String("/orders/1")}).
+		WillRespondWith(Response{Status: 200})
+
+	err := pact.Verify(func() error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pact.proxyServer == nil || pact.proxyServer.Addr != fmt.Sprintf("localhost:%d", pact.Server.Port) {
+		t.Fatalf("expected the proxy to be bound to the host, got %+v", pact.proxyServer)
+	}
+
+	pact.Teardown()
+	if pact.proxyServer != nil {
+		t.Fatal("expected the proxy to be shut down")
+	}
+	if conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", pact.Server.Port)); err == nil {
+		conn.Close()
+		t.Fatal("expected the proxy to stop listening")
+	}
+}
+
 func TestPact_TeardownFail(t *testing.T) {
 	c := &mockClient{}
 
@@ -921,6 +1005,25 @@ func TestPact_MessageVerification(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("provider test publishes the provider branch", func(t *testing.T) {
+		os.Setenv("PACT_TEST_MESSAGE_BRANCH", "main")
+		defer os.Unsetenv("PACT_TEST_MESSAGE_BRANCH")
+		c := &recordingVerifierClient{mockClient: newMockClient()}
+		pact := &Pact{LogLevel: "DEBUG", pactClient: c}
+
+		_, err := pact.VerifyMessageProviderRaw(VerifyMessageRequest{
+			PactURLs:                   []string{"foo.json"},
+			PublishVerificationResults: true,
+			ProviderVersion:            "1.0.0",
+			VersionDetection:           &types.VersionDetection{BranchVariables: []string{"PACT_TEST_MESSAGE_BRANCH"}},
+		})
+
+		assert.NoError(t, err)
+		if len(c.requests) != 1 || c.requests[0].ProviderBranch != "main" || c.requests[0].ProviderVersion != "1.0.0" {
+			t.Fatalf("expected the detected provider branch to be published, got %+v", c.requests)
+		}
+	})
+
 	t.Run("message verification handler", func(t *testing.T) {
 		var called = 0
 
@@ -0,0 +1,167 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// PactForVerification is a pact the broker selected for the provider to
+// verify
+type PactForVerification struct {
+	// URL of the pact
+	URL string
+
+	// Pending pacts do not fail the build when verification fails
+	Pending bool
+
+	// WIP is set for work in progress pacts, included by
+	// IncludeWIPPactsSince
+	WIP bool
+
+	// Notices explain why the broker selected the pact
+	Notices []string
+}
+
+// pactsForVerificationRequest is the body of the broker's 'pacts for
+// verification' request
+type pactsForVerificationRequest struct {
+	ConsumerVersionSelectors []types.ConsumerVersionSelector `json:"consumerVersionSelectors"`
+	ProviderVersionTags      []string                        `json:"providerVersionTags,omitempty"`
+	ProviderVersionBranch    string                          `json:"providerVersionBranch,omitempty"`
+	IncludePendingStatus     bool                            `json:"includePendingStatus,omitempty"`
+	IncludeWipPactsSince     string                          `json:"includeWipPactsSince,omitempty"`
+}
+
+// pactsForVerificationResponse is the broker's list of pacts to verify
+type pactsForVerificationResponse struct {
+	Embedded struct {
+		Pacts []struct {
+			VerificationProperties struct {
+				Pending bool `json:"pending"`
+				WIP     bool `json:"wip"`
+				Notices []struct {
+					Text string `json:"text"`
+				} `json:"notices"`
+			} `json:"verificationProperties"`
+			Links struct {
+				Self struct {
+					Href string `json:"href"`
+				} `json:"self"`
+			} `json:"_links"`
+		} `json:"pacts"`
+	} `json:"_embedded"`
+}
+
+// PactsForVerification asks the broker at BrokerURL which pacts the Provider
+// should verify, using the broker's 'pacts for verification' API. Pacts are
+// selected by the ConsumerVersionSelectors, or else the latest pact for each
+// of the Tags. With neither, the broker's default selection is used.
+func (p *Pact) PactsForVerification(request types.VerifyRequest) ([]PactForVerification, error) {
+	if request.Provider == "" {
+		request.Provider = p.Provider
+	}
+
+	return pactsForVerification(request)
+}
+
+// pactsForVerification fetches the pacts the broker selects for the request
+func pactsForVerification(request types.VerifyRequest) ([]PactForVerification, error) {
+	if request.BrokerURL == "" {
+		return nil, errors.New("'BrokerURL' must be supplied to fetch the pacts for verification")
+	}
+	if request.Provider == "" {
+		return nil, errors.New("'Provider' must be supplied if 'BrokerURL' given")
+	}
+
+	payload, err := pactsForVerificationBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	u := pactsForVerificationURL(request.BrokerURL, request.Provider)
+	content, err := brokerPost(u, payload, request.BrokerAuth())
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch pacts from the broker: %v", err)
+	}
+
+	var res pactsForVerificationResponse
+	if err = json.Unmarshal(content, &res); err != nil {
+		return nil, fmt.Errorf("unable to fetch pacts from the broker: invalid response from %s: %v", u, err)
+	}
+
+	pacts := make([]PactForVerification, 0, len(res.Embedded.Pacts))
+	for _, pact := range res.Embedded.Pacts {
+		if pact.Links.Self.Href == "" {
+			return nil, fmt.Errorf("unable to fetch pacts from the broker: a pact in the response from %s has no URL", u)
+		}
+
+		notices := make([]string, 0, len(pact.VerificationProperties.Notices))
+		for _, notice := range pact.VerificationProperties.Notices {
+			notices = append(notices, notice.Text)
+		}
+		pacts = append(pacts, PactForVerification{
+			URL:     pact.Links.Self.Href,
+			Pending: pact.VerificationProperties.Pending,
+			WIP:     pact.VerificationProperties.WIP,
+			Notices: notices,
+		})
+	}
+
+	return pacts, nil
+}
+
+// pactsForVerificationBody is the body of the 'pacts for verification'
+// request: the ConsumerVersionSelectors, or else the latest pact for each of
+// the Tags
+func pactsForVerificationBody(request types.VerifyRequest) ([]byte, error) {
+	selectors := make([]types.ConsumerVersionSelector, 0, len(request.ConsumerVersionSelectors))
+	for _, selector := range request.ConsumerVersionSelectors {
+		if err := selector.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid consumer version selector specified: %v", err)
+		}
+		selectors = append(selectors, selector)
+	}
+	if len(selectors) == 0 {
+		for _, tag := range request.Tags {
+			selectors = append(selectors, types.ConsumerVersionSelector{Tag: tag, Latest: true})
+		}
+	}
+
+	body := pactsForVerificationRequest{
+		ConsumerVersionSelectors: selectors,
+		ProviderVersionTags:      request.ProviderTags,
+		ProviderVersionBranch:    request.ProviderBranch,
+		IncludePendingStatus:     request.EnablePending,
+	}
+	if request.IncludeWIPPactsSince != nil {
+		body.IncludeWipPactsSince = request.IncludeWIPPactsSince.Format(time.RFC3339)
+	}
+
+	return json.Marshal(body)
+}
+
+func pactsForVerificationURL(brokerURL string, provider string) string {
+	return fmt.Sprintf("%s/pacts/provider/%s/for-verification", strings.TrimSuffix(brokerURL, "/"), url.PathEscape(provider))
+}
+
+// brokerPactURLs returns the URLs of the pacts the broker selects for the
+// request
+func brokerPactURLs(request types.VerifyRequest) ([]string, error) {
+	pacts, err := pactsForVerification(request)
+	if err != nil {
+		return nil, err
+	}
+
+	pactURLs := make([]string, 0, len(pacts))
+	for _, pact := range pacts {
+		pactURLs = append(pactURLs, pact.URL)
+	}
+
+	return pactURLs, nil
+}
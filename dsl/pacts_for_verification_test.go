@@ -0,0 +1,112 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPact_PactsForVerification(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/pacts/provider/bobby/for-verification" || r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.WriteHeader(404)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received) // nolint:errcheck
+		fmt.Fprint(w, `{"_embedded":{"pacts":[
+			{"verificationProperties":{"pending":true,"notices":[{"text":"pending"},{"text":"from main"}]},"_links":{"self":{"href":"http://broker/pacts/1"}}},
+			{"verificationProperties":{"wip":true},"_links":{"self":{"href":"http://broker/pacts/2"}}}
+		]}}`)
+	}))
+	defer server.Close()
+
+	since := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	pacts, err := (&Pact{Provider: "bobby"}).PactsForVerification(types.VerifyRequest{
+		BrokerURL:   server.URL + "/",
+		BrokerToken: "t0k3n",
+		ConsumerVersionSelectors: []types.ConsumerVersionSelector{
+			{MainBranch: true},
+			{Deployed: true, Environment: "production"},
+		},
+		Tags:                 []string{"ignored"},
+		ProviderTags:         []string{"main"},
+		ProviderBranch:       "main",
+		EnablePending:        true,
+		IncludeWIPPactsSince: &since,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []PactForVerification{
+		{URL: "http://broker/pacts/1", Pending: true, Notices: []string{"pending", "from main"}},
+		{URL: "http://broker/pacts/2", WIP: true, Notices: []string{}},
+	}
+	if !reflect.DeepEqual(pacts, expected) {
+		t.Fatalf("want %+v, got %+v", expected, pacts)
+	}
+
+	body, _ := json.Marshal(received)
+	want := `{"consumerVersionSelectors":[{"mainBranch":true},{"deployed":true,"environment":"production"}],"includePendingStatus":true,"includeWipPactsSince":"2020-01-02T00:00:00Z","providerVersionBranch":"main","providerVersionTags":["main"]}`
+	if string(body) != want {
+		t.Fatalf("want the request %s, got %s", want, body)
+	}
+}
+
+func TestPact_PactsForVerificationTags(t *testing.T) {
+	s := setupMockBroker(true)
+	defer s.Close()
+
+	pact := &Pact{Provider: "bobby"}
+	cases := map[string][]string{
+		"":     {s.URL + "/pacts/provider/bobby/consumer/jessica/version/2.0.0", s.URL + "/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0"},
+		"prod": {s.URL + "/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0"},
+	}
+	for tag, want := range cases {
+		request := types.VerifyRequest{BrokerURL: s.URL, BrokerUsername: "foo", BrokerPassword: "bar"}
+		if tag != "" {
+			request.Tags = []string{tag}
+		}
+		pacts, err := pact.PactsForVerification(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var got []string
+		for _, p := range pacts {
+			got = append(got, p.URL)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("tag %q: want %v, got %v", tag, want, got)
+		}
+	}
+}
+
+func TestPact_PactsForVerificationErrors(t *testing.T) {
+	s := setupMockBroker(true)
+	defer s.Close()
+
+	requests := map[string]types.VerifyRequest{
+		"no broker":        {Provider: "bobby"},
+		"no provider":      {BrokerURL: s.URL},
+		"unauthorised":     {BrokerURL: s.URL, Provider: "bobby"},
+		"broken broker":    {BrokerURL: s.URL, Provider: "bobby", Tags: []string{"broken"}, BrokerUsername: "foo", BrokerPassword: "bar"},
+		"server error":     {BrokerURL: s.URL, Provider: "broken", BrokerUsername: "foo", BrokerPassword: "bar"},
+		"invalid selector": {BrokerURL: s.URL, Provider: "bobby", ConsumerVersionSelectors: []types.ConsumerVersionSelector{{All: true, Latest: true}}},
+	}
+
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (&Pact{}).PactsForVerification(request); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package dsl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pagination helps to contract a paginated collection, generating the page and
+// limit query parameters, navigation links and RFC 5988 Link headers. URLs
+// are matched by regular expression so that the provider may use its own host.
+type Pagination struct {
+	// BaseURL of the example links e.g. the mock server's address.
+	// Defaults to "http://localhost"
+	BaseURL string
+
+	// Path of the collection e.g. "/users"
+	Path string
+
+	// Page is the current (1-indexed) page
+	Page int
+
+	// Limit is the number of items per page
+	Limit int
+
+	// Last is the final page of the collection, if known. If zero, a "next"
+	// link is always expected.
+	Last int
+
+	// PageParam is the name of the page query parameter. Defaults to "page"
+	PageParam string
+
+	// LimitParam is the name of the limit query parameter. Defaults to "limit"
+	LimitParam string
+}
+
+// Pagination creates a Pagination bound to the mock server host, for the given
+// page of a collection
+func (p *Pact) Pagination(path string, page, limit int) Pagination {
+	pagination := Pagination{Path: path, Page: page, Limit: limit}
+	if p.Server != nil {
+		pagination.BaseURL = fmt.Sprintf("http://%s:%d", p.Host, p.Server.Port)
+	}
+
+	return pagination
+}
+
+// Query returns the page and limit query parameters of the request
+func (p Pagination) Query() MapMatcher {
+	return MapMatcher{
+		p.pageParam():  Term(strconv.Itoa(p.Page), `^\d+$`),
+		p.limitParam(): Term(strconv.Itoa(p.Limit), `^\d+$`),
+	}
+}
+
+// Links returns the "self", "next" and "prev" links of the page, in the
+// HAL style e.g. {"next": {"href": "http://localhost/users?page=2&limit=20"}}
+func (p Pagination) Links() StructMatcher {
+	links := StructMatcher{}
+	for rel, page := range p.relations() {
+		links[rel] = StructMatcher{
+			"href": Term(p.pageURL(page), "^"+p.pageURLRegex()+"$"),
+		}
+	}
+
+	return links
+}
+
+// LinkHeader returns a matcher for an RFC 5988 Link header containing the
+// "next" and "prev" links of the page,
+// e.g. <http://localhost/users?page=2&limit=20>; rel="next"
+func (p Pagination) LinkHeader() Matcher {
+	relations := p.relations()
+
+	var values, patterns []string
+	for _, rel := range []string{"next", "prev"} {
+		page, ok := relations[rel]
+		if !ok {
+			continue
+		}
+		values = append(values, fmt.Sprintf(`<%s>; rel="%s"`, p.pageURL(page), rel))
+		patterns = append(patterns, fmt.Sprintf(`<%s>; rel="%s"`, p.pageURLRegex(), rel))
+	}
+
+	return Term(strings.Join(values, ", "), "^"+strings.Join(patterns, `,\s*`)+"$")
+}
+
+// relations returns the page numbers of each of the link relations
+func (p Pagination) relations() map[string]int {
+	relations := map[string]int{"self": p.Page}
+
+	if p.Last == 0 || p.Page < p.Last {
+		relations["next"] = p.Page + 1
+	}
+	if p.Page > 1 {
+		relations["prev"] = p.Page - 1
+	}
+
+	return relations
+}
+
+func (p Pagination) pageURL(page int) string {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost"
+	}
+
+	return fmt.Sprintf("%s%s?%s=%d&%s=%d", strings.TrimSuffix(baseURL, "/"), p.Path, p.pageParam(), page, p.limitParam(), p.Limit)
+}
+
+func (p Pagination) pageURLRegex() string {
+	return fmt.Sprintf(`https?://[^/]+%s\?%s=\d+&%s=\d+`, regexp.QuoteMeta(p.Path), regexp.QuoteMeta(p.pageParam()), regexp.QuoteMeta(p.limitParam()))
+}
+
+func (p Pagination) pageParam() string {
+	if p.PageParam == "" {
+		return "page"
+	}
+	return p.PageParam
+}
+
+func (p Pagination) limitParam() string {
+	if p.LimitParam == "" {
+		return "limit"
+	}
+	return p.LimitParam
+}
@@ -0,0 +1,78 @@
+package dsl
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPagination_Links(t *testing.T) {
+	p := Pagination{BaseURL: "http://localhost:1234", Path: "/users", Page: 2, Limit: 20, Last: 3}
+
+	example, _ := pactBodyBuilder(BodyPath(), p.Links())
+	expected := map[string]interface{}{
+		"self": map[string]interface{}{"href": "http://localhost:1234/users?page=2&limit=20"},
+		"next": map[string]interface{}{"href": "http://localhost:1234/users?page=3&limit=20"},
+		"prev": map[string]interface{}{"href": "http://localhost:1234/users?page=1&limit=20"},
+	}
+
+	if !reflect.DeepEqual(example, expected) {
+		t.Fatalf("want %v, got %v", expected, example)
+	}
+
+	regex := p.Links()["next"].(StructMatcher)["href"].(term).Data.Matcher.Regex.(string)
+	if !regexp.MustCompile(regex).MatchString("https://api.example.com/users?page=3&limit=20") {
+		t.Fatalf("expected links on other hosts to match %s", regex)
+	}
+}
+
+func TestPagination_Boundaries(t *testing.T) {
+	first := Pagination{Path: "/users", Page: 1, Limit: 10}
+	if _, ok := first.Links()["prev"]; ok {
+		t.Fatal("expected no prev link on the first page")
+	}
+	if _, ok := first.Links()["next"]; !ok {
+		t.Fatal("expected a next link when the last page is unknown")
+	}
+
+	last := Pagination{Path: "/users", Page: 3, Limit: 10, Last: 3}
+	if _, ok := last.Links()["next"]; ok {
+		t.Fatal("expected no next link on the last page")
+	}
+}
+
+func TestPagination_LinkHeader(t *testing.T) {
+	p := Pagination{Path: "/users", Page: 2, Limit: 20, PageParam: "p", LimitParam: "per_page"}
+	header := p.LinkHeader().(term)
+
+	want := `<http://localhost/users?p=3&per_page=20>; rel="next", <http://localhost/users?p=1&per_page=20>; rel="prev"`
+	if header.Data.Generate != want {
+		t.Fatalf("want %s, got %s", want, header.Data.Generate)
+	}
+
+	regex := regexp.MustCompile(header.Data.Matcher.Regex.(string))
+	if !regex.MatchString(want) {
+		t.Fatalf("expected the example to match %s", regex)
+	}
+	if !regex.MatchString(`<https://api/users?p=9&per_page=50>; rel="next",<https://api/users?p=7&per_page=50>; rel="prev"`) {
+		t.Fatalf("expected other pages to match %s", regex)
+	}
+}
+
+func TestPagination_Query(t *testing.T) {
+	q := Pagination{Page: 2, Limit: 20}.Query()
+
+	if q["page"].GetValue() != "2" || q["limit"].GetValue() != "20" {
+		t.Fatalf("unexpected query %v", q)
+	}
+}
+
+func TestPact_Pagination(t *testing.T) {
+	pact := &Pact{Host: "127.0.0.1", Server: &types.MockServer{Port: 1234}}
+
+	if got := pact.Pagination("/users", 1, 10).BaseURL; got != "http://127.0.0.1:1234" {
+		t.Fatalf("expected links bound to the mock server, got %s", got)
+	}
+}
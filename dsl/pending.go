@@ -0,0 +1,45 @@
+package dsl
+
+import (
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// markPending marks the examples of a pending (or work in progress) pact as
+// pending, reporting whether any had failed
+func markPending(responses []types.ProviderVerifierResponse, wip bool) bool {
+	failed := false
+	for i := range responses {
+		for j := range responses[i].Examples {
+			example := &responses[i].Examples[j]
+			example.Pact.Pending = true
+			example.Pact.WIP = wip
+			if example.Status == "failed" || example.Status == "errored" {
+				example.Status = "pending"
+				failed = true
+			}
+		}
+	}
+
+	return failed
+}
+
+// markPendingPacts marks the examples of the pacts the verifier reported as
+// pending, so that each example shows whether its pact is pending. Work in
+// progress pacts are those the broker describes as "WIP".
+func markPendingPacts(responses []types.ProviderVerifierResponse) {
+	for i := range responses {
+		pending := map[string]bool{}
+		for _, example := range responses[i].Examples {
+			if example.Status == "pending" {
+				pending[example.Pact.URL] = true
+			}
+		}
+		for j := range responses[i].Examples {
+			example := &responses[i].Examples[j]
+			example.Pact.WIP = strings.HasPrefix(example.Pact.ShortDescription, "WIP")
+			example.Pact.Pending = pending[example.Pact.URL] || example.Pact.WIP
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package dsl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestMarkPendingPacts(t *testing.T) {
+	var res types.ProviderVerifierResponse
+	err := json.Unmarshal([]byte(`{"examples":[
+		{"status":"passed","pact":{"url":"http://broker/pacts/1"}},
+		{"status":"pending","pact":{"url":"http://broker/pacts/1"}},
+		{"status":"failed","pact":{"url":"http://broker/pacts/2"}},
+		{"status":"passed","pact":{"url":"http://broker/pacts/3","short_description":"WIP"}}
+	]}`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markPendingPacts([]types.ProviderVerifierResponse{res})
+
+	for i, want := range []bool{true, true, false, true} {
+		if res.Examples[i].Pact.Pending != want {
+			t.Fatalf("expected example %d pending to be %t", i, want)
+		}
+	}
+	if res.Examples[2].Pact.WIP || !res.Examples[3].Pact.WIP {
+		t.Fatal("expected the pact the broker described as WIP to be work in progress")
+	}
+}
@@ -0,0 +1,34 @@
+package dsl
+
+import (
+	"net/http"
+)
+
+// problemContentType matches the RFC 7807 media type, with optional parameters
+const problemContentType = `^application/problem\+json(;.*)?$`
+
+// RFC7807Problem creates a response for an RFC 7807 "problem details" error,
+// standardising how error contracts are expressed. The type and status must
+// match exactly, whilst the title, detail and instance are matched by type.
+//
+// The body is a StructMatcher, which may be extended with additional members.
+func RFC7807Problem(status int, typeURI string) Response {
+	title := http.StatusText(status)
+	if title == "" {
+		title = "Error"
+	}
+
+	return Response{
+		Status: status,
+		Headers: MapMatcher{
+			"Content-Type": Term("application/problem+json", problemContentType),
+		},
+		Body: StructMatcher{
+			"type":     typeURI,
+			"status":   status,
+			"title":    Like(title),
+			"detail":   Like("A human readable explanation of the problem"),
+			"instance": Like("/problems/1"),
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package dsl
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRFC7807Problem(t *testing.T) {
+	res := RFC7807Problem(404, "https://example.com/problems/not-found")
+
+	if res.Status != 404 {
+		t.Fatalf("want status 404, got %d", res.Status)
+	}
+
+	contentType := res.Headers["Content-Type"].(term).Data.Matcher.Regex.(string)
+	if !regexp.MustCompile(contentType).MatchString("application/problem+json; charset=utf-8") {
+		t.Fatalf("expected the content type to allow parameters, got %s", contentType)
+	}
+
+	example, rules := pactBodyBuilder(BodyPath(), res.Body)
+	body := example.(map[string]interface{})
+	if body["type"] != "https://example.com/problems/not-found" || body["status"] != 404 || body["title"] != "Not Found" {
+		t.Fatalf("unexpected body %v", body)
+	}
+
+	expectedRules := MatchingRules{
+		"$.body.title":    TypeRule(),
+		"$.body.detail":   TypeRule(),
+		"$.body.instance": TypeRule(),
+	}
+	if !reflect.DeepEqual(rules, expectedRules) {
+		t.Fatalf("want rules %v, got %v", expectedRules, rules)
+	}
+}
+
+func TestRFC7807Problem_UnknownStatus(t *testing.T) {
+	body := RFC7807Problem(599, "about:blank").Body.(StructMatcher)
+
+	if body["title"].(Matcher).GetValue() != "Error" {
+		t.Fatalf("expected a default title, got %v", body["title"])
+	}
+}
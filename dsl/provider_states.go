@@ -0,0 +1,18 @@
+package dsl
+
+// writeProviderStates writes the provider states of interactions with
+// several states, or states with parameters, to the pact file as
+// "providerStates", which the mock service doesn't write
+func writeProviderStates(file string, states map[string][]State) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		if s, ok := states[description]; ok && len(s) > 0 {
+			delete(interaction, "providerState")
+			interaction["providerStates"] = s
+		}
+	})
+}
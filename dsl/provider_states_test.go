@@ -0,0 +1,129 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestInteraction_GivenWithParams(t *testing.T) {
+	i := (&Interaction{}).
+		Given("a user exists").
+		GivenWithParams("an order exists", map[string]interface{}{"id": 10}).
+		Given("stock is available")
+
+	want := []State{
+		{Name: "a user exists"},
+		{Name: "an order exists", Params: map[string]interface{}{"id": 10}},
+		{Name: "stock is available"},
+	}
+	if i.State != "a user exists" || !reflect.DeepEqual(i.States, want) {
+		t.Fatalf("want states %v, got %q and %v", want, i.State, i.States)
+	}
+
+	single := (&Interaction{}).GivenWithParams("an order exists", nil)
+	if single.State != "an order exists" || single.hasStateParams() {
+		t.Fatalf("expected a single state without parameters, got %+v", single.States)
+	}
+}
+
+func TestInteraction_GivenWithParamsVersion(t *testing.T) {
+	i := (&Interaction{}).
+		GivenWithParams("an order exists", map[string]interface{}{"id": 10}).
+		UponReceiving("a request for an order").
+		WithRequest(Request{Method: "GET", Path: String("/orders/10")}).
+		WillRespondWith(Response{Status: 200})
+
+	i.specificationVersion = 2
+	if err := i.Validate(); err == nil || !strings.Contains(err.Error(), "require pact specification version 3") {
+		t.Fatalf("expected a specification version error, got %v", err)
+	}
+
+	i.specificationVersion = 3
+	if err := i.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWriteProviderStates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-go-states")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "web-orders.json")
+	pact := `{
+  "consumer": {"name": "web"},
+  "provider": {"name": "orders"},
+  "interactions": [
+    {"description": "a request for an order", "providerState": "an order exists", "request": {"method": "GET", "path": "/orders/10"}, "response": {"status": 200}},
+    {"description": "a request for stock", "providerState": "stock is available", "request": {"method": "GET", "path": "/stock"}, "response": {"status": 200}}
+  ],
+  "metadata": {"pactSpecification": {"version": "3.0.0"}}
+}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = writeProviderStates(file, map[string][]State{
+		"a request for an order": {{Name: "a user exists"}, {Name: "an order exists", Params: map[string]interface{}{"id": 10}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	written, err := pactfile.Read(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order, stock := written.Interactions[0], written.Interactions[1]
+	var states bytes.Buffer
+	json.Compact(&states, order.ProviderStates) // nolint:errcheck
+	if order.ProviderState != "" || states.String() != `[{"name":"a user exists"},{"name":"an order exists","params":{"id":10}}]` {
+		t.Fatalf("unexpected provider states %q and %s", order.ProviderState, states.String())
+	}
+	if stock.ProviderState != "stock is available" || len(stock.ProviderStates) > 0 {
+		t.Fatalf("expected other interactions to be unchanged, got %+v", stock)
+	}
+}
+
+func TestPact_StateHandlerMiddlewareWithParams(t *testing.T) {
+	var received map[string]interface{}
+	var plain bool
+	withParams := types.StateHandlersWithParams{
+		"an order exists": func(params map[string]interface{}) error {
+			received = params
+			return nil
+		},
+	}
+	handlers := types.StateHandlers{
+		"an order exists": func() error {
+			plain = true
+			return nil
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/__setup", strings.NewReader(`{
+		"consumer": "web",
+		"state": "an order exists",
+		"states": ["an order exists"],
+		"params": {"id": 10}
+		}`))
+	rr := httptest.NewRecorder()
+	stateHandlerMiddlewareWithParams(handlers, withParams)(dummyHandler("X-Dummy-Handler")).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || received["id"] != float64(10) || plain {
+		t.Fatalf("expected the handler with parameters to be called, got %d %v %v", rr.Code, received, plain)
+	}
+}
@@ -0,0 +1,200 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile("^" + uuid + "$")
+
+// ProviderStubs records the responses of a provider's handlers, from the
+// provider's own handler tests, as interactions that consumers can use as
+// stubs before they write pacts of their own. This supports teams that
+// start provider-first: the provider publishes examples of its API, with
+// matchers inferred from the recorded values, and consumers replace them
+// with consumer-driven pacts as they adopt the API.
+type ProviderStubs struct {
+	// Consumer names the stubs' consumer in the pact e.g. "stubs"
+	Consumer string
+
+	// Provider is the name of the provider
+	Provider string
+
+	mu           sync.Mutex
+	interactions []*nativeInteraction
+}
+
+// NewProviderStubs returns a recorder of the provider's stubs
+func NewProviderStubs(consumer, provider string) *ProviderStubs {
+	return &ProviderStubs{Consumer: consumer, Provider: provider}
+}
+
+// Record serves the request with the handler, as a handler test would with
+// httptest, and records the request and response as an interaction with the
+// description and optional provider states. The response is returned for
+// the test's own assertions.
+//
+// JSON bodies are recorded with inferred matchers: values match by type,
+// arrays by the type of their first element, and UUIDs, RFC 3339 timestamps
+// and dates by format. Other bodies must match exactly.
+func (s *ProviderStubs) Record(description string, handler http.Handler, r *http.Request, states ...string) *httptest.ResponseRecorder {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+
+	interaction := &Interaction{
+		Description: description,
+		Request: Request{
+			Method: r.Method,
+			Path:   String(r.URL.Path),
+			Body:   inferBody(r.Header.Get("Content-Type"), body),
+		},
+		Response: Response{
+			Status: recorder.Code,
+			Body:   inferBody(recorder.Header().Get("Content-Type"), recorder.Body.Bytes()),
+		},
+	}
+	for _, state := range states {
+		interaction.Given(state)
+	}
+	if query := r.URL.Query(); len(query) > 0 {
+		interaction.Request.Query = MapMatcher{}
+		for key := range query {
+			interaction.Request.Query[key] = String(query.Get(key))
+		}
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" && len(body) > 0 {
+		interaction.Request.Headers = MapMatcher{"Content-Type": String(contentType)}
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "" {
+		interaction.Response.Headers = MapMatcher{"Content-Type": String(contentType)}
+	}
+
+	s.mu.Lock()
+	s.interactions = append(s.interactions, &nativeInteraction{Interaction: interaction})
+	s.mu.Unlock()
+
+	return recorder
+}
+
+// Interactions returns the recorded interactions
+func (s *ProviderStubs) Interactions() []*Interaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interactions := make([]*Interaction, len(s.interactions))
+	for n, i := range s.interactions {
+		interactions[n] = i.Interaction
+	}
+
+	return interactions
+}
+
+// WritePact writes the recorded interactions to a version 3 pact in the
+// directory, returning its path. The pact's metadata marks it as published
+// by the provider, so that the stubs can be told apart from consumer driven
+// pacts. Publish it as any other pact for consumers to use as stubs.
+func (s *ProviderStubs) WritePact(dir string) (string, error) {
+	s.mu.Lock()
+	content, err := nativePact(s.Consumer, s.Provider, s.interactions)
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	var pact map[string]interface{}
+	if err = json.Unmarshal(content, &pact); err != nil {
+		return "", err
+	}
+	metadata, _ := pact["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		pact["metadata"] = metadata
+	}
+	metadata["providerPublished"] = true
+	if content, err = json.MarshalIndent(pact, "", "  "); err != nil {
+		return "", err
+	}
+
+	file := filepath.Join(dir, pactFileName(s.Consumer, s.Provider))
+	if err = ioutil.WriteFile(file, content, 0644); err != nil {
+		return "", fmt.Errorf("unable to write the provider stubs: %v", err)
+	}
+
+	return file, nil
+}
+
+// inferBody returns the body with inferred matchers if it is JSON, otherwise
+// as a string
+func inferBody(contentType string, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if decoder.Decode(&value) == nil {
+			return inferMatcher(value)
+		}
+	}
+
+	return string(body)
+}
+
+// inferMatcher converts a decoded JSON value to matchers of its type, or
+// format for strings
+func inferMatcher(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			object[key] = inferMatcher(child)
+		}
+		return object
+	case []interface{}:
+		if len(v) == 0 {
+			return v
+		}
+		return EachLike(inferMatcher(v[0]), 1)
+	case string:
+		if uuidPattern.MatchString(v) {
+			return Term(v, uuid)
+		}
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			return Term(v, `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?([zZ]|[+-]\d{2}:\d{2})$`)
+		}
+		if _, err := time.Parse("2006-01-02", v); err == nil {
+			return Term(v, `^\d{4}-\d{2}-\d{2}$`)
+		}
+		return Like(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return Like(n)
+		}
+		n, _ := v.Float64()
+		return Like(n)
+	case nil:
+		return nil
+	}
+
+	return Like(value)
+}
@@ -0,0 +1,112 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+func TestProviderStubs(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"fc763eba-0905-41c5-a27f-3934ab26786c","request":%s}`, body)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, `{"name":"jmarie","age":42,"score":1.5,"active":true,"created":"2020-01-02T03:04:05Z","birthday":"1990-02-03","tags":[{"name":"admin"}],"none":[],"manager":null}`)
+	})
+
+	stubs := NewProviderStubs("stubs", "users")
+	res := stubs.Record("a request for user 1", handler, httptest.NewRequest("GET", "/users/1?fields=all", nil), "user 1 exists")
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected the handler's response, got %d", res.Code)
+	}
+	post := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"jmarie"}`))
+	post.Header.Set("Content-Type", "application/json")
+	stubs.Record("a request to create a user", handler, post)
+
+	if interactions := stubs.Interactions(); len(interactions) != 2 || interactions[0].State != "user 1 exists" {
+		t.Fatalf("expected the interactions to be recorded, got %+v", interactions)
+	}
+
+	dir, err := ioutil.TempDir("", "pact-go-stubs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file, err := stubs.WritePact(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file != filepath.Join(dir, "stubs-users.json") {
+		t.Fatalf("unexpected pact file %s", file)
+	}
+
+	pact, err := pactfile.Read(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pact.Metadata["providerPublished"] != true || pact.SpecificationVersion() != "3.0.0" {
+		t.Fatalf("expected a version 3 pact published by the provider, got %v", pact.Metadata)
+	}
+
+	get := pact.Interactions[0]
+	var query map[string][]string
+	json.Unmarshal(get.Request.Query, &query) // nolint:errcheck
+	if get.Request.Path != "/users/1" || query["fields"][0] != "all" || get.ProviderState != "" || !strings.Contains(string(get.ProviderStates), "user 1 exists") {
+		t.Fatalf("unexpected request %+v", get)
+	}
+
+	var rules map[string]map[string]struct {
+		Matchers []map[string]interface{} `json:"matchers"`
+	}
+	if err = json.Unmarshal(get.Response.MatchingRules, &rules); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"$.name":         "type",
+		"$.age":          "type",
+		"$.score":        "type",
+		"$.active":       "type",
+		"$.created":      "regex",
+		"$.birthday":     "regex",
+		"$.tags":         "type",
+		"$.tags[*].name": "type",
+	}
+	for path, match := range want {
+		if matchers := rules["body"][path].Matchers; len(matchers) != 1 || matchers[0]["match"] != match {
+			t.Fatalf("expected a %s matcher at %s, got %v", match, path, rules["body"])
+		}
+	}
+	if _, ok := rules["body"]["$.none"]; ok {
+		t.Fatalf("expected no matcher for the empty array, got %v", rules["body"])
+	}
+
+	created := pact.Interactions[1]
+	if created.Response.Status != 201 || !strings.Contains(string(created.Request.MatchingRules), `"$.name"`) || !strings.Contains(string(created.Response.MatchingRules), uuid) {
+		t.Fatalf("expected the request and response to be recorded with matchers, got %+v", created)
+	}
+}
+
+func TestInferBody_Text(t *testing.T) {
+	if body := inferBody("text/plain", []byte("hello")); body != "hello" {
+		t.Fatalf("expected a text body to match exactly, got %v", body)
+	}
+	if body := inferBody("application/json", []byte("{broken")); body != "{broken" {
+		t.Fatalf("expected invalid JSON to match exactly, got %v", body)
+	}
+	if body := inferBody("application/json", nil); body != nil {
+		t.Fatalf("expected no body, got %v", body)
+	}
+}
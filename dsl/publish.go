@@ -1,10 +1,15 @@
 package dsl
 
 import (
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/logutils"
+	"github.com/pact-foundation/pact-go/pactfile"
 	"github.com/pact-foundation/pact-go/types"
 )
 
@@ -36,21 +41,177 @@ type Publisher struct {
 
 // Publish sends the Pacts to a broker, optionally tagging them
 func (p *Publisher) Publish(request types.PublishRequest) error {
+	_, err := p.PublishWithResult(request)
+	return err
+}
+
+// PublishWithResult sends the Pacts to a broker, optionally tagging them,
+// and returns a summary of the publication. If the request has a SummaryFile
+// the summary is also written to it.
+func (p *Publisher) PublishWithResult(request types.PublishRequest) (types.PublishResult, error) {
+	result, err := p.publish(request)
+	if err != nil {
+		result = types.PublishResult{Status: types.StatusFailed, Error: err.Error()}
+	}
+
+	if request.SummaryFile != "" {
+		if writeErr := result.WriteFile(request.SummaryFile); writeErr != nil && err == nil {
+			err = fmt.Errorf("unable to write publish summary: %v", writeErr)
+		}
+	}
+
+	return result, err
+}
+
+func (p *Publisher) publish(request types.PublishRequest) (types.PublishResult, error) {
 	p.setupLogging()
 	log.Println("[DEBUG] pact publisher: publish pact")
 
+	if !BrokerAvailable() {
+		return types.PublishResult{}, ErrBrokerUnavailable
+	}
+
 	if p.pactClient == nil {
 		c := NewClient()
 		p.pactClient = c
 	}
 
+	detectVersion(request.VersionDetection, &request.ConsumerVersion, &request.Branch)
+	if request.Verbose {
+		deprecated("types.PublishRequest.Verbose", "Publisher.LogLevel")
+	}
+	if request.ConsumerVersion == "" && request.VersionDetection != nil {
+		return types.PublishResult{}, fmt.Errorf("unable to detect the consumer version from CI environment variables or git: set ConsumerVersion, or VersionDetection.VersionVariables to the variable holding it")
+	}
+
+	if !request.SkipConsistencyChecks {
+		pactURLs, err := checkPublishConsistency(request)
+		if err != nil {
+			return types.PublishResult{}, err
+		}
+
+		if len(pactURLs) == 0 {
+			log.Println("[INFO] pact publisher: all pacts are unchanged since they were last published, skipping")
+			return types.PublishResult{Status: types.StatusPublishSkipped, Published: []string{}}, nil
+		}
+		request.PactURLs = pactURLs
+	}
+
 	err := request.Validate()
 
 	if err != nil {
-		return err
+		return types.PublishResult{}, err
+	}
+
+	if err = p.pactClient.PublishPacts(request); err != nil {
+		return types.PublishResult{}, err
+	}
+
+	return types.PublishResult{Status: types.StatusPublished, Published: request.PactURLs}, nil
+}
+
+// checkPublishConsistency ensures there is only one pact file for each
+// consumer/provider pair, that they are for the configured consumer and that
+// they don't differ from the pacts already published for the consumer
+// version, returning the pact files that have changed since they were last
+// published. Locations that aren't local files are left for the CLI to handle, and JSON
+// files in directories that aren't pacts are ignored.
+func checkPublishConsistency(request types.PublishRequest) ([]string, error) {
+	var pactURLs []string
+	pairs := make(map[string]string)
+
+	for _, location := range request.PactURLs {
+		info, err := os.Stat(location)
+		if err != nil {
+			log.Println("[DEBUG] pact publisher: unable to check consistency of", location)
+			pactURLs = append(pactURLs, location)
+			continue
+		}
+
+		files := []string{location}
+		if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(location, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range files {
+			pact, err := pactfile.Read(file)
+			if info.IsDir() && (err != nil || pact.Consumer.Name == "" || pact.Provider.Name == "") {
+				log.Println("[DEBUG] pact publisher: ignoring", file, "which is not a pact file")
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to read pact file %s: %v", file, err)
+			}
+
+			if request.Consumer != "" && pact.Consumer.Name != request.Consumer {
+				return nil, fmt.Errorf("pact file %s is for consumer '%s', expected '%s'", file, pact.Consumer.Name, request.Consumer)
+			}
+
+			pair := fmt.Sprintf("'%s' and '%s'", pact.Consumer.Name, pact.Provider.Name)
+			if existing, ok := pairs[pair]; ok {
+				return nil, fmt.Errorf("found multiple pact files between %s: %s and %s", pair, existing, file)
+			}
+			pairs[pair] = file
+
+			if request.PactBroker != "" && isPublished(request, pact) {
+				log.Println("[INFO] pact publisher: skipping unchanged pact", file)
+				continue
+			}
+			if request.PactBroker != "" && request.ConsumerVersion != "" {
+				if err = checkVersionUnchanged(request, pact, file); err != nil {
+					return nil, err
+				}
+			}
+			pactURLs = append(pactURLs, file)
+		}
+	}
+
+	return pactURLs, nil
+}
+
+// isPublished determines if the latest pact on the broker for the pair is
+// equivalent to the given pact
+func isPublished(request types.PublishRequest, pact *pactfile.Pact) bool {
+	u := fmt.Sprintf("%s/pacts/provider/%s/consumer/%s/latest", strings.TrimSuffix(request.PactBroker, "/"),
+		url.PathEscape(pact.Provider.Name), url.PathEscape(pact.Consumer.Name))
+
+	content, err := brokerGet(u, request.BrokerAuth())
+	if err != nil {
+		log.Println("[DEBUG] pact publisher: no published pact found:", err)
+		return false
+	}
+
+	published, err := pactfile.Parse(content)
+	if err != nil {
+		return false
+	}
+
+	return pact.Equivalent(published)
+}
+
+// checkVersionUnchanged returns an error if a different pact has already
+// been published for the consumer version, which the broker rejects with a
+// 409 Conflict: a version's pacts may not change
+func checkVersionUnchanged(request types.PublishRequest, pact *pactfile.Pact, file string) error {
+	u := fmt.Sprintf("%s/pacts/provider/%s/consumer/%s/version/%s", strings.TrimSuffix(request.PactBroker, "/"),
+		url.PathEscape(pact.Provider.Name), url.PathEscape(pact.Consumer.Name), url.PathEscape(request.ConsumerVersion))
+
+	content, err := brokerGet(u, request.BrokerAuth())
+	if err != nil {
+		log.Println("[DEBUG] pact publisher: no pact published for the version:", err)
+		return nil
+	}
+
+	published, err := pactfile.Parse(content)
+	if err != nil || pact.Equivalent(published) {
+		return nil
 	}
 
-	return p.pactClient.PublishPacts(request)
+	return fmt.Errorf("pact file %s differs from the pact between '%s' and '%s' already published for consumer version '%s', "+
+		"and the broker doesn't allow a version's pacts to change: publish with a new ConsumerVersion, e.g. by committing the changes, "+
+		"or by setting VersionDetection.MarkDirty or IncludeBranch", file, pact.Consumer.Name, pact.Provider.Name, request.ConsumerVersion)
 }
 
 // Configure logging
@@ -62,7 +223,7 @@ func (p *Publisher) setupLogging() {
 		p.logFilter = &logutils.LevelFilter{
 			Levels:   []logutils.LogLevel{"TRACE", "DEBUG", "INFO", "WARN", "ERROR"},
 			MinLevel: logutils.LogLevel(p.LogLevel),
-			Writer:   os.Stderr,
+			Writer:   logOutput{},
 		}
 		log.SetOutput(p.logFilter)
 	}
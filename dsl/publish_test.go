@@ -3,7 +3,13 @@ package dsl
 import (
 	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -77,3 +83,167 @@ func TestPublish_PublishFailIntegration(t *testing.T) {
 		t.Fatal("want error, got none")
 	}
 }
+
+func setupPublishedPacts(t *testing.T) (string, *httptest.Server, func()) {
+	dir, err := ioutil.TempDir("", "pact-publish")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unchanged := `{"consumer":{"name":"billing"},"provider":{"name":"accounts"},"interactions":[{"description":"a","request":{"method":"GET","path":"/"},"response":{"status":200}}]}`
+	changed := `{"consumer":{"name":"billing"},"provider":{"name":"payments"},"interactions":[{"description":"b","request":{"method":"GET","path":"/"},"response":{"status":200}}]}`
+	ioutil.WriteFile(filepath.Join(dir, "billing-accounts.json"), []byte(unchanged), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "billing-payments.json"), []byte(changed), 0644)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pacts/provider/accounts/consumer/billing/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"consumer":{"name":"billing"},"provider":{"name":"accounts"},"interactions":[{"description":"a","request":{"method":"GET","path":"/"},"response":{"status":200}}],"_links":{}}`)
+	})
+	mux.HandleFunc("/pacts/provider/payments/consumer/billing/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"consumer":{"name":"billing"},"provider":{"name":"payments"},"interactions":[]}`)
+	})
+	mux.HandleFunc("/pacts/provider/payments/consumer/billing/version/1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"consumer":{"name":"billing"},"provider":{"name":"payments"},"interactions":[]}`)
+	})
+	server := httptest.NewServer(mux)
+
+	return dir, server, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPublish_checkPublishConsistency(t *testing.T) {
+	dir, server, cleanup := setupPublishedPacts(t)
+	defer cleanup()
+
+	// JSON files that aren't pacts are ignored
+	ioutil.WriteFile(filepath.Join(dir, "summary.json"), []byte(`{"status": "published"}`), 0644)
+
+	pactURLs, err := checkPublishConsistency(types.PublishRequest{
+		PactURLs:   []string{dir, "/does/not/exist.json"},
+		PactBroker: server.URL,
+		Consumer:   "billing",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{filepath.Join(dir, "billing-payments.json"), "/does/not/exist.json"}
+	sort.Strings(pactURLs)
+	sort.Strings(expected)
+	if !reflect.DeepEqual(pactURLs, expected) {
+		t.Fatalf("want %v, got %v", expected, pactURLs)
+	}
+}
+
+func TestPublish_checkPublishConsistencyErrors(t *testing.T) {
+	dir, server, cleanup := setupPublishedPacts(t)
+	defer cleanup()
+
+	_, err := checkPublishConsistency(types.PublishRequest{PactURLs: []string{dir}, PactBroker: server.URL, Consumer: "shipping"})
+	if err == nil || !strings.Contains(err.Error(), "expected 'shipping'") {
+		t.Fatalf("expected a consumer name error, got %v", err)
+	}
+
+	duplicate := filepath.Join(dir, "copy")
+	os.Mkdir(duplicate, 0755)
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "billing-accounts.json"))
+	ioutil.WriteFile(filepath.Join(duplicate, "billing-accounts.json"), content, 0644)
+
+	_, err = checkPublishConsistency(types.PublishRequest{PactURLs: []string{dir, duplicate}, PactBroker: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "multiple pact files") {
+		t.Fatalf("expected a duplicate pact error, got %v", err)
+	}
+}
+
+func TestPublish_SkipsUnchangedPacts(t *testing.T) {
+	dir, server, cleanup := setupPublishedPacts(t)
+	defer cleanup()
+
+	p := Publisher{
+		pactClient: newMockClient(),
+	}
+	request := types.PublishRequest{
+		PactURLs:        []string{filepath.Join(dir, "billing-accounts.json")},
+		PactBroker:      server.URL,
+		ConsumerVersion: "1.0.0",
+	}
+
+	result, err := p.PublishWithResult(request)
+	if err != nil || result.Status != types.StatusPublishSkipped {
+		t.Fatalf("expected an unchanged pact not to be published by default, got %+v, %v", result, err)
+	}
+
+	request.SkipConsistencyChecks = true
+	result, err = p.PublishWithResult(request)
+	if err != nil || result.Status != types.StatusPublished {
+		t.Fatalf("expected the pact to be published when skipping consistency checks, got %+v, %v", result, err)
+	}
+}
+
+func TestPublish_PublishWithResult(t *testing.T) {
+	dir, server, cleanup := setupPublishedPacts(t)
+	defer cleanup()
+
+	c := newMockClient()
+	p := Publisher{
+		pactClient: c,
+	}
+	summary := filepath.Join(dir, "summary.json")
+
+	result, err := p.PublishWithResult(types.PublishRequest{
+		PactURLs:        []string{filepath.Join(dir, "billing-accounts.json")},
+		PactBroker:      server.URL,
+		ConsumerVersion: "1.0.0",
+		SummaryFile:     summary,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != types.StatusPublishSkipped {
+		t.Fatalf("want status %s, got %s", types.StatusPublishSkipped, result.Status)
+	}
+
+	content, err := ioutil.ReadFile(summary)
+	if err != nil {
+		t.Fatalf("expected a summary file to be written: %v", err)
+	}
+	if !strings.Contains(string(content), `"status": "publish_skipped"`) {
+		t.Fatalf("unexpected summary %s", content)
+	}
+
+	result, _ = p.PublishWithResult(types.PublishRequest{
+		PactURLs:              []string{filepath.Join(dir, "billing-accounts.json")},
+		PactBroker:            server.URL,
+		ConsumerVersion:       "1.0.0",
+		SkipConsistencyChecks: true,
+	})
+	if result.Status != types.StatusPublished || len(result.Published) != 1 {
+		t.Fatalf("expected the pact to be published, got %+v", result)
+	}
+}
+
+func TestPublish_VersionConflict(t *testing.T) {
+	dir, server, cleanup := setupPublishedPacts(t)
+	defer cleanup()
+
+	p := Publisher{
+		pactClient: newMockClient(),
+	}
+	request := types.PublishRequest{
+		PactURLs:        []string{filepath.Join(dir, "billing-payments.json")},
+		PactBroker:      server.URL,
+		ConsumerVersion: "1.0.0",
+	}
+
+	err := p.Publish(request)
+	if err == nil || !strings.Contains(err.Error(), "already published for consumer version '1.0.0'") {
+		t.Fatalf("expected a version conflict, got %v", err)
+	}
+
+	request.ConsumerVersion = "1.0.1"
+	if err = p.Publish(request); err != nil {
+		t.Fatalf("expected a new version to be published, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package dsl
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// redactedValue replaces redacted values unless Redaction.Replacement is set
+const redactedValue = "[REDACTED]"
+
+// Redaction scrubs secrets, such as API keys, tokens or passwords, from the
+// interactions written to the pact file, so that they are never committed
+// or published to a Pact Broker.
+type Redaction struct {
+	// Headers are the names of request and response headers to redact, case
+	// insensitive e.g. "Authorization"
+	Headers []string
+
+	// Query are the names of request query parameters to redact
+	// e.g. "api_key"
+	Query []string
+
+	// BodyPaths are the paths of request and response body fields to redact
+	// e.g. "$.password" or "$.users[*].token"
+	BodyPaths []string
+
+	// Replacement replaces each redacted value, defaults to "[REDACTED]"
+	Replacement string
+
+	// Hook is called with each interaction, as written to the pact file,
+	// after the configured fields have been redacted. It may modify the
+	// interaction to scrub or replace anything else.
+	Hook func(interaction map[string]interface{})
+}
+
+func (r Redaction) enabled() bool {
+	return len(r.Headers) > 0 || len(r.Query) > 0 || len(r.BodyPaths) > 0 || r.Hook != nil
+}
+
+func (r Redaction) replacement() string {
+	if r.Replacement == "" {
+		return redactedValue
+	}
+
+	return r.Replacement
+}
+
+// redactPactFile redacts each interaction of the pact file. The file is
+// removed if it can't be redacted, rather than leaving secrets behind.
+func redactPactFile(file string, redaction Redaction) error {
+	if !redaction.enabled() {
+		return nil
+	}
+
+	paths := make([]RulePath, len(redaction.BodyPaths))
+	for n, bodyPath := range redaction.BodyPaths {
+		path, err := ParseRulePath(bodyPath)
+		if err != nil {
+			os.Remove(file)
+			return fmt.Errorf("unable to redact pact file %s: %v", file, err)
+		}
+		paths[n] = path
+	}
+
+	err := rewritePactFile(file, func(interaction map[string]interface{}) {
+		redaction.redact(interaction, paths)
+	})
+	if err != nil {
+		os.Remove(file)
+		return fmt.Errorf("unable to redact pact file %s, it has been removed: %v", file, err)
+	}
+
+	return nil
+}
+
+// redact scrubs the configured fields of an interaction in the pact file
+func (r Redaction) redact(interaction map[string]interface{}, paths []RulePath) {
+	for _, side := range []string{"request", "response"} {
+		part, ok := interaction[side].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if headers, ok := part["headers"].(map[string]interface{}); ok {
+			for name := range headers {
+				if containsFold(r.Headers, name) {
+					headers[name] = r.replacement()
+				}
+			}
+		}
+
+		if side == "request" {
+			part["query"] = r.redactQuery(part["query"])
+		}
+
+		if body, ok := part["body"]; ok {
+			for _, path := range paths {
+				body = path.replaceIn(body, r.replacement())
+			}
+			part["body"] = body
+		}
+	}
+
+	if r.Hook != nil {
+		r.Hook(interaction)
+	}
+}
+
+// redactQuery redacts query parameters, written as a string (version 2
+// pacts) or a map of values (version 3)
+func (r Redaction) redactQuery(query interface{}) interface{} {
+	switch q := query.(type) {
+	case string:
+		pairs := strings.Split(q, "&")
+		for n, pair := range pairs {
+			name := strings.SplitN(pair, "=", 2)[0]
+			if unescaped, err := url.QueryUnescape(name); err == nil {
+				name = unescaped
+			}
+			if containsString(r.Query, name) {
+				pairs[n] = url.QueryEscape(name) + "=" + url.QueryEscape(r.replacement())
+			}
+		}
+		return strings.Join(pairs, "&")
+	case map[string]interface{}:
+		for name, values := range q {
+			if !containsString(r.Query, name) {
+				continue
+			}
+			if list, ok := values.([]interface{}); ok {
+				for n := range list {
+					list[n] = r.replacement()
+				}
+				continue
+			}
+			q[name] = r.replacement()
+		}
+	}
+
+	return query
+}
+
+// replaceIn replaces each value the path resolves to within the document,
+// wildcards replacing every element
+func (p RulePath) replaceIn(doc interface{}, replacement string) interface{} {
+	if len(p.tokens) == 0 {
+		return replacement
+	}
+
+	rest := RulePath{tokens: p.tokens[1:]}
+	switch token := p.tokens[0]; token.kind {
+	case keyToken:
+		if obj, ok := doc.(map[string]interface{}); ok {
+			if value, ok := obj[token.key]; ok {
+				obj[token.key] = rest.replaceIn(value, replacement)
+			}
+		}
+	case indexToken:
+		if arr, ok := doc.([]interface{}); ok && token.index < len(arr) {
+			arr[token.index] = rest.replaceIn(arr[token.index], replacement)
+		}
+	case anyIndexToken:
+		if arr, ok := doc.([]interface{}); ok {
+			for n, value := range arr {
+				arr[n] = rest.replaceIn(value, replacement)
+			}
+		}
+	case anyKeyToken:
+		if obj, ok := doc.(map[string]interface{}); ok {
+			for key, value := range obj {
+				obj[key] = rest.replaceIn(value, replacement)
+			}
+		}
+	}
+
+	return doc
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRedactPactFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-redaction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "billing-accounts.json")
+	ioutil.WriteFile(file, []byte(`{
+		"consumer": {"name": "billing"},
+		"provider": {"name": "accounts"},
+		"interactions": [{
+			"description": "a login",
+			"request": {
+				"method": "POST",
+				"path": "/login",
+				"query": "api_key=s3cret&page=1",
+				"headers": {"authorization": "Bearer s3cret", "Accept": "application/json"},
+				"body": {"user": "jane", "password": "s3cret", "devices": [{"token": "a"}, {"token": "b"}]}
+			},
+			"response": {
+				"status": 200,
+				"headers": {"Set-Cookie": "session=s3cret"},
+				"body": {"session": {"token": "s3cret"}}
+			}
+		}, {
+			"description": "a search",
+			"request": {"method": "GET", "path": "/search", "query": {"api_key": ["s3cret"], "q": ["pact"]}},
+			"response": {"status": 200, "body": "s3cret"}
+		}]
+	}`), 0644)
+
+	redaction := Redaction{
+		Headers:   []string{"Authorization", "set-cookie"},
+		Query:     []string{"api_key"},
+		BodyPaths: []string{"$.password", "$.devices[*].token", "$.session.token"},
+		Hook: func(interaction map[string]interface{}) {
+			if interaction["description"] == "a search" {
+				interaction["response"].(map[string]interface{})["body"] = "hooked"
+			}
+		},
+	}
+	if err = redactPactFile(file, redaction); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := ioutil.ReadFile(file)
+	var pact struct {
+		Interactions []struct {
+			Request  map[string]interface{} `json:"request"`
+			Response map[string]interface{} `json:"response"`
+		} `json:"interactions"`
+	}
+	if err = json.Unmarshal(content, &pact); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	login, search := pact.Interactions[0], pact.Interactions[1]
+	want := map[string]interface{}{
+		"method":  "POST",
+		"path":    "/login",
+		"query":   "api_key=%5BREDACTED%5D&page=1",
+		"headers": map[string]interface{}{"authorization": "[REDACTED]", "Accept": "application/json"},
+		"body": map[string]interface{}{
+			"user":     "jane",
+			"password": "[REDACTED]",
+			"devices":  []interface{}{map[string]interface{}{"token": "[REDACTED]"}, map[string]interface{}{"token": "[REDACTED]"}},
+		},
+	}
+	if !reflect.DeepEqual(login.Request, want) {
+		t.Fatalf("want %v, got %v", want, login.Request)
+	}
+	if login.Response["headers"].(map[string]interface{})["Set-Cookie"] != "[REDACTED]" ||
+		login.Response["body"].(map[string]interface{})["session"].(map[string]interface{})["token"] != "[REDACTED]" {
+		t.Fatalf("expected the response to be redacted, got %v", login.Response)
+	}
+
+	query := search.Request["query"].(map[string]interface{})
+	if !reflect.DeepEqual(query["api_key"], []interface{}{"[REDACTED]"}) || !reflect.DeepEqual(query["q"], []interface{}{"pact"}) {
+		t.Fatalf("expected the api_key parameter to be redacted, got %v", query)
+	}
+	if search.Response["body"] != "hooked" {
+		t.Fatalf("expected the hook to be called, got %v", search.Response["body"])
+	}
+}
+
+func TestRedactPactFile_InvalidPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-redaction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "billing-accounts.json")
+	ioutil.WriteFile(file, []byte(`{"interactions": []}`), 0644)
+
+	if err = redactPactFile(file, Redaction{BodyPaths: []string{"password"}}); err == nil {
+		t.Fatal("expected an error for an invalid body path")
+	}
+	if _, err = os.Stat(file); !os.IsNotExist(err) {
+		t.Fatal("expected the pact file to be removed rather than left unredacted")
+	}
+}
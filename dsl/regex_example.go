@@ -0,0 +1,152 @@
+package dsl
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxRegexRepeat is the upper bound of repetitions generated for unbounded
+// quantifiers such as '*' and '+'
+const maxRegexRepeat = 3
+
+// maxRegexAttempts is the number of times an example is generated before
+// giving up, e.g. when word boundaries can't be satisfied.
+const maxRegexAttempts = 10
+
+// printable ASCII range, preferred when picking characters from a class
+const (
+	printableMin = ' '
+	printableMax = '~'
+)
+
+// anyCharacters are used to satisfy the '.' operator
+var anyCharacters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+// generateRegexExample synthesises a string that matches the given regular
+// expression. It uses the configured source of randomness (see SetRandSource)
+// so the result is deterministic if the source is.
+func generateRegexExample(pattern string) (string, error) {
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate example for regex %q: %v", pattern, err)
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate example for regex %q: %v", pattern, err)
+	}
+	re = re.Simplify()
+
+	for i := 0; i < maxRegexAttempts; i++ {
+		var b strings.Builder
+		writeRegexExample(&b, re)
+
+		if example := b.String(); matcher.MatchString(example) {
+			return example, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to generate example for regex %q: no valid example found after %d attempts", pattern, maxRegexAttempts)
+}
+
+// writeRegexExample recursively walks the parsed expression, writing
+// a matching value into the builder
+func writeRegexExample(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(pickFromCharClass(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteRune(anyCharacters[randomIntn(len(anyCharacters))])
+	case syntax.OpCapture:
+		writeRegexExample(b, re.Sub[0])
+	case syntax.OpStar:
+		writeRegexRepeat(b, re.Sub[0], 0, maxRegexRepeat)
+	case syntax.OpPlus:
+		writeRegexRepeat(b, re.Sub[0], 1, 1+maxRegexRepeat)
+	case syntax.OpQuest:
+		writeRegexRepeat(b, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 {
+			max = re.Min + maxRegexRepeat
+		}
+		writeRegexRepeat(b, re.Sub[0], re.Min, max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeRegexExample(b, sub)
+		}
+	case syntax.OpAlternate:
+		writeRegexExample(b, re.Sub[randomIntn(len(re.Sub))])
+	default:
+		// Zero-width assertions (anchors, word boundaries) and empty matches
+		// don't contribute any characters
+	}
+}
+
+func writeRegexRepeat(b *strings.Builder, re *syntax.Regexp, min, max int) {
+	count := min + randomIntn(max-min+1)
+	for i := 0; i < count; i++ {
+		writeRegexExample(b, re)
+	}
+}
+
+// pickFromCharClass selects a character from a class, given as pairs of
+// inclusive rune ranges. Printable ASCII characters are preferred.
+func pickFromCharClass(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < printableMin {
+			lo = printableMin
+		}
+		if hi > printableMax {
+			hi = printableMax
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+
+	if len(printable) > 0 {
+		ranges = printable
+	}
+
+	if len(ranges) < 2 {
+		return 0
+	}
+
+	pair := randomIntn(len(ranges)/2) * 2
+	lo, hi := ranges[pair], ranges[pair+1]
+
+	// Outside of ASCII, avoid surrogates (which can't be encoded as UTF-8)
+	// and unprintable characters, falling back to the first valid one
+	for i := 0; i < maxRegexAttempts; i++ {
+		if r := lo + rune(randomIntn(int(hi-lo)+1)); printableRune(r) {
+			return r
+		}
+	}
+	for r := lo; r <= hi; r++ {
+		if printableRune(r) {
+			return r
+		}
+	}
+	for r := lo; r <= hi; r++ {
+		if utf8.ValidRune(r) {
+			return r
+		}
+	}
+
+	return lo
+}
+
+func printableRune(r rune) bool {
+	return utf8.ValidRune(r) && unicode.IsPrint(r)
+}
@@ -0,0 +1,126 @@
+package dsl
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRegexExample_Generate(t *testing.T) {
+	SetRandSource(rand.NewSource(1))
+	defer SetRandSource(nil)
+
+	patterns := []string{
+		`\w+`,
+		`^\d{4}-\d{2}-\d{2}$`,
+		hexadecimal,
+		ipAddress,
+		uuid,
+		`^(GET|POST|PUT)$`,
+		`[^a-z]{3,}`,
+		`^user-[a-z]+@example\.(com|org)$`,
+		`a?b*c+`,
+		`\bfoo\b`,
+	}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			example, err := generateRegexExample(pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !regexp.MustCompile(pattern).MatchString(example) {
+				t.Fatalf("generated example %q does not match %q", example, pattern)
+			}
+		})
+	}
+}
+
+func TestRegexExample_Multibyte(t *testing.T) {
+	SetRandSource(rand.NewSource(1))
+	defer SetRandSource(nil)
+
+	patterns := []string{
+		`^[à-ÿ]{3}$`,
+		`^東京-\p{Han}{2}$`,
+		`^[\x{1F600}-\x{1F64F}]+$`,
+		`^[\x{D7FF}-\x{E000}]$`,
+		`^\p{Cyrillic}+ \p{Greek}+$`,
+	}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			example, err := generateRegexExample(pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !utf8.ValidString(example) || !regexp.MustCompile(pattern).MatchString(example) {
+				t.Fatalf("generated example %q does not match %q", example, pattern)
+			}
+		})
+	}
+}
+
+func TestRegexExample_Deterministic(t *testing.T) {
+	defer SetRandSource(nil)
+
+	SetRandSource(rand.NewSource(7))
+	first, _ := generateRegexExample(`[a-z]{10}`)
+
+	SetRandSource(rand.NewSource(7))
+	second, _ := generateRegexExample(`[a-z]{10}`)
+
+	if first != second {
+		t.Fatalf("expected the same example for the same seed, got %q and %q", first, second)
+	}
+}
+
+func TestRegexExample_InvalidRegex(t *testing.T) {
+	if _, err := generateRegexExample(`(?!foo)`); err == nil {
+		t.Fatal("expected an error for an unsupported regex")
+	}
+}
+
+func TestRegexExample_TermUnsupportedRegex(t *testing.T) {
+	if _, err := TermE("", `(?!foo)`); err == nil {
+		t.Fatal("expected an error for a regex no example can be generated for")
+	}
+
+	var m Matcher
+	res := captureOutput(func() {
+		(&Pact{LogLevel: "WARN"}).setupLogging()
+		m = Term("", `(?!foo)`)
+	})
+	if m.GetValue() != "" || !strings.Contains(res, "[WARN] term:") {
+		t.Fatalf("expected an empty example and a warning, got %v and %q", m.GetValue(), res)
+	}
+}
+
+func TestRegexExample_TermWithoutExample(t *testing.T) {
+	pattern := `^\d{3}-\d{4}$`
+	value, ok := Term("", pattern).GetValue().(string)
+
+	if !ok || !regexp.MustCompile(pattern).MatchString(value) {
+		t.Fatalf("expected a generated example matching %q, got %v", pattern, value)
+	}
+}
+
+func TestRegexExample_MatchTagWithoutExample(t *testing.T) {
+	type dto struct {
+		Code string `json:"code" pact:"regex=^[A-Z]{3}$"`
+	}
+
+	m, ok := Match(dto{}).(StructMatcher)
+	if !ok {
+		t.Fatalf("expected a StructMatcher, got %T", m)
+	}
+
+	value, _ := m["code"].(Matcher).GetValue().(string)
+	if !regexp.MustCompile(`^[A-Z]{3}$`).MatchString(value) {
+		t.Fatalf("expected a generated example, got %q", value)
+	}
+}
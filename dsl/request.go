@@ -2,9 +2,11 @@ package dsl
 
 // Request is the default implementation of the Request interface.
 type Request struct {
-	Method  string      `json:"method"`
-	Path    Matcher     `json:"path"`
-	Query   MapMatcher  `json:"query,omitempty"`
-	Headers MapMatcher  `json:"headers,omitempty"`
-	Body    interface{} `json:"body,omitempty"`
+	Method        string        `json:"method"`
+	Path          Matcher       `json:"path"`
+	Query         MapMatcher    `json:"query,omitempty"`
+	Headers       MapMatcher    `json:"headers,omitempty"`
+	Trailers      MapMatcher    `json:"-"` // headers sent after the body, e.g. a checksum
+	Body          interface{}   `json:"body,omitempty"`
+	MatchingRules MatchingRules `json:"matchingRules,omitempty"`
 }
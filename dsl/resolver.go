@@ -0,0 +1,186 @@
+package dsl
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Resolver supplies the value of a ${NAME} placeholder in an interaction,
+// e.g. from the environment or a secret store. It returns false if the name
+// is unknown.
+//
+// Placeholders let the same consumer tests run across environments, see
+// Pact.Resolvers.
+type Resolver interface {
+	Resolve(name string) (string, bool)
+}
+
+// ResolverFunc adapts a function to a Resolver
+type ResolverFunc func(name string) (string, bool)
+
+// Resolve calls the function
+func (f ResolverFunc) Resolve(name string) (string, bool) {
+	return f(name)
+}
+
+// EnvResolver resolves placeholders from environment variables
+var EnvResolver Resolver = ResolverFunc(os.LookupEnv)
+
+// MapResolver resolves placeholders from a fixed set of values
+type MapResolver map[string]string
+
+// Resolve looks up the name
+func (m MapResolver) Resolve(name string) (string, bool) {
+	value, ok := m[name]
+	return value, ok
+}
+
+var placeholderRegex = regexp.MustCompile(`\$\{([^${}]+)\}`)
+
+// placeholders substitutes the placeholders in an interaction, recording
+// any that none of its resolvers know
+type placeholders struct {
+	resolvers  []Resolver
+	unresolved map[string]bool
+}
+
+func newPlaceholders(resolvers []Resolver) *placeholders {
+	return &placeholders{resolvers: resolvers, unresolved: make(map[string]bool)}
+}
+
+// err reports the unresolved placeholders of the interaction
+func (p *placeholders) err(description string) error {
+	if len(p.unresolved) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(p.unresolved))
+	for name := range p.unresolved {
+		names = append(names, "${"+name+"}")
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("unresolved placeholders in interaction '%s': %s", description, strings.Join(names, ", "))
+}
+
+func (p *placeholders) resolve(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(s, func(placeholder string) string {
+		name := strings.TrimSpace(placeholder[2 : len(placeholder)-1])
+		for _, resolver := range p.resolvers {
+			if value, ok := resolver.Resolve(name); ok {
+				return value
+			}
+		}
+		p.unresolved[name] = true
+
+		return placeholder
+	})
+}
+
+// matcher substitutes the placeholders in string values, and in the
+// examples of string matchers
+func (p *placeholders) matcher(m Matcher) Matcher {
+	switch t := m.(type) {
+	case S:
+		return S(p.resolve(string(t)))
+	case String:
+		return String(p.resolve(string(t)))
+	case like:
+		if contents, ok := t.Contents.(string); ok {
+			t.Contents = p.resolve(contents)
+		}
+		return t
+	case term:
+		if generate, ok := t.Data.Generate.(string); ok {
+			t.Data.Generate = p.resolve(generate)
+		}
+		return t
+	case described:
+		t.Matcher = p.matcher(t.Matcher)
+		return t
+	}
+
+	return m
+}
+
+func (p *placeholders) mapMatcher(m MapMatcher) MapMatcher {
+	if m == nil {
+		return nil
+	}
+
+	resolved := make(MapMatcher, len(m))
+	for name, value := range m {
+		resolved[name] = p.matcher(value)
+	}
+
+	return resolved
+}
+
+// value substitutes the placeholders in a provider state parameter
+func (p *placeholders) value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return p.resolve(t)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			resolved[key] = p.value(value)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(t))
+		for i, value := range t {
+			resolved[i] = p.value(value)
+		}
+		return resolved
+	}
+
+	return v
+}
+
+// resolvePlaceholders substitutes the placeholders in the path, query,
+// headers and provider state of the interaction
+func (p *Pact) resolvePlaceholders(i *Interaction) error {
+	if len(p.Resolvers) == 0 {
+		return nil
+	}
+	r := newPlaceholders(p.Resolvers)
+
+	i.State = r.resolve(i.State)
+	if i.Request.Path != nil {
+		i.Request.Path = r.matcher(i.Request.Path)
+	}
+	i.Request.Query = r.mapMatcher(i.Request.Query)
+	i.Request.Headers = r.mapMatcher(i.Request.Headers)
+	i.Response.Headers = r.mapMatcher(i.Response.Headers)
+
+	return r.err(i.Description)
+}
+
+// resolveMessagePlaceholders substitutes the placeholders in the provider
+// states and metadata of the message
+func (p *Pact) resolveMessagePlaceholders(m *Message) error {
+	if len(p.Resolvers) == 0 {
+		return nil
+	}
+	r := newPlaceholders(p.Resolvers)
+
+	states := make([]State, len(m.States))
+	for i, state := range m.States {
+		states[i] = State{Name: r.resolve(state.Name)}
+		if state.Params != nil {
+			states[i].Params = r.value(state.Params).(map[string]interface{})
+		}
+	}
+	m.States = states
+	m.Metadata = r.mapMatcher(m.Metadata)
+
+	return r.err(m.Description)
+}
@@ -0,0 +1,92 @@
+package dsl
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestPact_ResolvePlaceholders(t *testing.T) {
+	os.Setenv("PACT_TEST_TENANT", "acme")
+	defer os.Unsetenv("PACT_TEST_TENANT")
+
+	pact := &Pact{Resolvers: []Resolver{MapResolver{"API_KEY": "secret"}, EnvResolver}}
+	interaction := (&Interaction{}).
+		Given("tenant ${PACT_TEST_TENANT} exists").
+		UponReceiving("a request for the tenant").
+		WithRequest(Request{
+			Method:  "GET",
+			Path:    Term("/tenants/${PACT_TEST_TENANT}", `/tenants/\w+`),
+			Query:   MapMatcher{"key": String("${API_KEY}")},
+			Headers: MapMatcher{"X-Tenant": S("${ PACT_TEST_TENANT }").Describe("the tenant")},
+		}).
+		WillRespondWith(Response{Status: 200})
+
+	if err := pact.resolvePlaceholders(interaction); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := json.Marshal(interaction)
+	var got struct {
+		State   string `json:"providerState"`
+		Request struct {
+			Path struct {
+				Data struct {
+					Generate string `json:"generate"`
+				} `json:"data"`
+			} `json:"path"`
+			Query   map[string]string `json:"query"`
+			Headers map[string]string `json:"headers"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.State != "tenant acme exists" {
+		t.Fatalf("want the state resolved, got %q", got.State)
+	}
+	if got.Request.Path.Data.Generate != "/tenants/acme" {
+		t.Fatalf("want the path resolved, got %q", got.Request.Path.Data.Generate)
+	}
+	if got.Request.Query["key"] != "secret" || got.Request.Headers["X-Tenant"] != "acme" {
+		t.Fatalf("want the query and headers resolved, got %v and %v", got.Request.Query, got.Request.Headers)
+	}
+}
+
+func TestPact_ResolvePlaceholdersUnresolved(t *testing.T) {
+	pact := &Pact{Resolvers: []Resolver{MapResolver{}}}
+	interaction := (&Interaction{}).
+		UponReceiving("a request for the tenant").
+		WithRequest(Request{Method: "GET", Path: String("/tenants/${TENANT}"), Headers: MapMatcher{"Authorization": String("Bearer ${TOKEN}")}})
+
+	err := pact.resolvePlaceholders(interaction)
+	want := "unresolved placeholders in interaction 'a request for the tenant': ${TENANT}, ${TOKEN}"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+
+	pact.Resolvers = nil
+	if err := pact.resolvePlaceholders(interaction); err != nil {
+		t.Fatalf("want placeholders left alone without resolvers, got %v", err)
+	}
+}
+
+func TestPact_ResolveMessagePlaceholders(t *testing.T) {
+	pact := &Pact{Resolvers: []Resolver{MapResolver{"REGION": "eu-west-1"}}}
+	message := &Message{Description: "an order"}
+	message.States = []State{{Name: "an order in ${REGION}", Params: map[string]interface{}{"region": "${REGION}", "ids": []interface{}{"${REGION}-1", 2}}}}
+	message.Metadata = MapMatcher{"queue": String("orders-${REGION}")}
+
+	if err := pact.resolveMessagePlaceholders(message); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state := message.States[0]
+	if state.Name != "an order in eu-west-1" || state.Params["region"] != "eu-west-1" || state.Params["ids"].([]interface{})[0] != "eu-west-1-1" {
+		t.Fatalf("want the state resolved, got %+v", state)
+	}
+	if message.Metadata["queue"] != String("orders-eu-west-1") {
+		t.Fatalf("want the metadata resolved, got %v", message.Metadata)
+	}
+}
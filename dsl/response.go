@@ -2,7 +2,9 @@ package dsl
 
 // Response is the default implementation of the Response interface.
 type Response struct {
-	Status  int         `json:"status"`
-	Headers MapMatcher  `json:"headers,omitempty"`
-	Body    interface{} `json:"body,omitempty"`
+	Status        int           `json:"status"`
+	Headers       MapMatcher    `json:"headers,omitempty"`
+	Trailers      MapMatcher    `json:"-"` // headers sent after the body, e.g. a checksum
+	Body          interface{}   `json:"body,omitempty"`
+	MatchingRules MatchingRules `json:"matchingRules,omitempty"`
 }
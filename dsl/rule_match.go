@@ -0,0 +1,124 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// ruleMatcher compares actual values with the examples and matching rules of
+// an interaction, as written to the pact file, in the manner of the pact
+// specification: the most specific rule matching a value's path is applied
+// (see MatchingRules.Candidates), values without one are matched by
+// equality, objects may have unexpected keys, and arrays without a rule must
+// have as many elements as the example. Mismatches are reported with paths
+// relative to the root e.g. "$.id" for "$.body.id".
+type ruleMatcher struct {
+	rules      MatchingRules
+	root       RulePath
+	mismatches []types.BodyMismatch
+}
+
+// matchRules compares the actual value at the root with the expected
+// example, both decoded from JSON
+func matchRules(rules MatchingRules, root RulePath, expected interface{}, actual interface{}) ([]types.BodyMismatch, error) {
+	m := &ruleMatcher{rules: rules, root: root}
+	if err := m.match(expected, actual, root); err != nil {
+		return nil, err
+	}
+
+	return m.mismatches, nil
+}
+
+func (m *ruleMatcher) mismatch(path RulePath, expected, actual interface{}, rule string, format string, args ...interface{}) {
+	m.mismatches = append(m.mismatches, types.BodyMismatch{
+		Path:     "$" + strings.TrimPrefix(path.String(), m.root.String()),
+		Expected: expected,
+		Actual:   actual,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (m *ruleMatcher) match(expected interface{}, actual interface{}, path RulePath) error {
+	applied, ok, err := m.rules.Resolve(path)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if message := ruleError(applied.Rule, expected, true, actual); message != "" {
+			m.mismatch(path, expected, actual, ruleName(applied.Rule), "%s", strings.ToUpper(message[:1])+message[1:])
+			return nil
+		}
+	}
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		object, isObject := actual.(map[string]interface{})
+		if !isObject {
+			m.mismatch(path, nil, actual, "type", "Expected an object but got %s", describeJSON(actual))
+			return nil
+		}
+
+		keys := make([]string, 0, len(e))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, found := object[key]
+			if !found {
+				m.mismatch(path.Key(key), nil, nil, "", "Could not find key %q", key)
+				continue
+			}
+			if err := m.match(e[key], value, path.Key(key)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items, isArray := actual.([]interface{})
+		if !isArray {
+			m.mismatch(path, nil, actual, "type", "Expected an array but got %s", describeJSON(actual))
+			return nil
+		}
+		if !ok && len(items) != len(e) {
+			m.mismatch(path, nil, actual, "", "Expected an array with %d elements but got %d", len(e), len(items))
+		}
+
+		// Elements beyond the example are compared with its first element,
+		// if a rule allows them
+		for n, item := range items {
+			if n >= len(e) && (!ok || len(e) == 0) {
+				break
+			}
+			element := e[0]
+			if n < len(e) {
+				element = e[n]
+			}
+			if err := m.match(element, item, path.Index(n)); err != nil {
+				return err
+			}
+		}
+	default:
+		if !ok && !equalJSON(expected, actual) {
+			m.mismatch(path, expected, actual, "equality", "Expected %s but got %s", jsonString(expected), jsonString(actual))
+		}
+	}
+
+	return nil
+}
+
+// ruleName is the kind of a rule e.g. "type" or "regex", if it's a single
+// rule
+func ruleName(rule Rule) string {
+	if match, ok := rule["match"].(string); ok {
+		return match
+	}
+	if rule["regex"] != nil {
+		return "regex"
+	}
+
+	return ""
+}
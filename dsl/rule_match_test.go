@@ -0,0 +1,65 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchRules(t *testing.T) {
+	rules := MatchingRules{}.
+		Add(BodyPath().Key("id"), TypeRule()).
+		Add(BodyPath().Key("items"), MinTypeRule(1)).
+		Add(BodyPath().Key("items").AnyIndex().Key("sku"), RegexRule(`^[A-Z]+$`))
+	expected, err := decodeJSON([]byte(`{"id": 1, "name": "Mary", "items": [{"sku": "ABC", "quantity": 1}], "flags": ["a", "b"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		actual string
+		want   []string
+	}{
+		{
+			name:   "matching",
+			actual: `{"id": 7, "name": "Mary", "items": [{"sku": "XYZ", "quantity": 5}, {"sku": "DEF", "quantity": 2}], "flags": ["a", "b"], "extra": true}`,
+		},
+		{
+			name:   "mismatching",
+			actual: `{"id": "7", "name": "Joe", "items": [{"sku": "xyz", "quantity": 1}, {"quantity": 1}], "flags": ["a"]}`,
+			want: []string{
+				`body $.flags: Expected an array with 2 elements but got 1`,
+				`body $.id: Expected a number (1) but got a string ("7")`,
+				`body $.items[0].sku: Expected a value matching "^[A-Z]+$"`,
+				`body $.items[1].sku: Could not find key "sku"`,
+				`body $.name: Expected "Mary" but got "Joe"`,
+			},
+		},
+		{
+			name:   "too few elements",
+			actual: `{"id": 7, "name": "Mary", "items": [], "flags": ["a", "b"]}`,
+			want:   []string{`body $.items: Expected at least 1 elements but got 0`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := decodeJSON([]byte(tt.actual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mismatches, err := matchRules(rules, BodyPath(), expected, actual)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, mismatch := range mismatches {
+				got = append(got, mismatch.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("want:\n%s\ngot:\n%s", strings.Join(tt.want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
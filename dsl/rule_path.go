@@ -0,0 +1,250 @@
+package dsl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identifierRegex matches keys that may be written in dot notation,
+// anything else is escaped using bracket notation e.g. $.body['a.b']
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+type pathTokenType int
+
+const (
+	keyToken pathTokenType = iota
+	indexToken
+	anyIndexToken
+	anyKeyToken
+)
+
+type pathToken struct {
+	kind  pathTokenType
+	key   string
+	index int
+}
+
+// RulePath is a matching rule path expression, used to key matching rules
+// in a pact file e.g. $.body.items[*].id
+//
+// Keys that can't be represented in dot notation (e.g. containing '.', '@'
+// or spaces) are automatically escaped using bracket notation.
+type RulePath struct {
+	tokens []pathToken
+}
+
+// NewRulePath creates a path pointing at the root element "$"
+func NewRulePath() RulePath {
+	return RulePath{}
+}
+
+// BodyPath creates a path pointing at the body of a request or response,
+// "$.body"
+func BodyPath() RulePath {
+	return NewRulePath().Key("body")
+}
+
+// HeaderPath creates a path pointing at a request or response header,
+// e.g. "$.headers.Content-Type"
+func HeaderPath(name string) RulePath {
+	return NewRulePath().Key("headers").Key(name)
+}
+
+// QueryPath creates a path pointing at a query string parameter,
+// e.g. "$.query.page"
+func QueryPath(name string) RulePath {
+	return NewRulePath().Key("query").Key(name)
+}
+
+// TrailerPath creates a path pointing at a request or response trailer,
+// e.g. "$.trailers.X-Checksum"
+func TrailerPath(name string) RulePath {
+	return NewRulePath().Key("trailers").Key(name)
+}
+
+// MetadataPath creates a path pointing at a message metadata value,
+// e.g. "$.metadata.contentType"
+func MetadataPath(name string) RulePath {
+	return NewRulePath().Key("metadata").Key(name)
+}
+
+// RequestPathPath creates a path pointing at the path of a request, "$.path"
+func RequestPathPath() RulePath {
+	return NewRulePath().Key("path")
+}
+
+// Key appends an object key to the path
+func (p RulePath) Key(name string) RulePath {
+	return p.append(pathToken{kind: keyToken, key: name})
+}
+
+// Index appends an array index to the path
+func (p RulePath) Index(index int) RulePath {
+	return p.append(pathToken{kind: indexToken, index: index})
+}
+
+// AnyIndex appends an array wildcard "[*]" to the path
+func (p RulePath) AnyIndex() RulePath {
+	return p.append(pathToken{kind: anyIndexToken})
+}
+
+// AnyKey appends an object wildcard ".*" to the path
+func (p RulePath) AnyKey() RulePath {
+	return p.append(pathToken{kind: anyKeyToken})
+}
+
+// append returns a copy of the path with the token added, so that
+// paths may be safely used as prefixes for other paths
+func (p RulePath) append(token pathToken) RulePath {
+	tokens := make([]pathToken, len(p.tokens), len(p.tokens)+1)
+	copy(tokens, p.tokens)
+
+	return RulePath{tokens: append(tokens, token)}
+}
+
+// String renders the path expression e.g. "$.body.items[*].id"
+func (p RulePath) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+
+	for _, token := range p.tokens {
+		switch token.kind {
+		case keyToken:
+			if identifierRegex.MatchString(token.key) {
+				b.WriteString(".")
+				b.WriteString(token.key)
+			} else {
+				b.WriteString("['")
+				b.WriteString(escapePathKey(token.key))
+				b.WriteString("']")
+			}
+		case indexToken:
+			b.WriteString("[")
+			b.WriteString(strconv.Itoa(token.index))
+			b.WriteString("]")
+		case anyIndexToken:
+			b.WriteString("[*]")
+		case anyKeyToken:
+			b.WriteString(".*")
+		}
+	}
+
+	return b.String()
+}
+
+// MarshalText renders the path, so it may be used as a JSON key or value
+func (p RulePath) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func escapePathKey(key string) string {
+	key = strings.Replace(key, `\`, `\\`, -1)
+	return strings.Replace(key, `'`, `\'`, -1)
+}
+
+// ParseRulePath parses and validates a matching rule path expression,
+// returning an error describing the first problem found.
+func ParseRulePath(path string) (RulePath, error) {
+	result := NewRulePath()
+
+	if !strings.HasPrefix(path, "$") {
+		return result, fmt.Errorf("invalid matching rule path %q: must start with '$'", path)
+	}
+
+	pos := 1
+	for pos < len(path) {
+		switch path[pos] {
+		case '.':
+			pos++
+			if pos < len(path) && path[pos] == '*' {
+				result = result.AnyKey()
+				pos++
+				continue
+			}
+
+			end := pos
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			key := path[pos:end]
+
+			if !identifierRegex.MatchString(key) {
+				return result, fmt.Errorf("invalid matching rule path %q: invalid key %q at position %d, use bracket notation e.g. ['%s'] for keys containing special characters", path, key, pos, escapePathKey(key))
+			}
+			result = result.Key(key)
+			pos = end
+		case '[':
+			token, next, err := parseBracket(path, pos)
+			if err != nil {
+				return result, err
+			}
+			result = result.append(token)
+			pos = next
+		default:
+			return result, fmt.Errorf("invalid matching rule path %q: unexpected character %q at position %d", path, path[pos], pos)
+		}
+	}
+
+	return result, nil
+}
+
+// parseBracket parses a bracketed expression starting at pos,
+// returning the token and the position following the closing bracket
+func parseBracket(path string, pos int) (pathToken, int, error) {
+	pos++
+	if pos >= len(path) {
+		return pathToken{}, pos, fmt.Errorf("invalid matching rule path %q: unterminated '['", path)
+	}
+
+	switch {
+	case path[pos] == '*':
+		if pos+1 >= len(path) || path[pos+1] != ']' {
+			return pathToken{}, pos, fmt.Errorf("invalid matching rule path %q: expected ']' at position %d", path, pos+1)
+		}
+		return pathToken{kind: anyIndexToken}, pos + 2, nil
+	case path[pos] == '\'':
+		var key strings.Builder
+		pos++
+		for pos < len(path) {
+			c := path[pos]
+			if c == '\\' && pos+1 < len(path) {
+				key.WriteByte(path[pos+1])
+				pos += 2
+				continue
+			}
+			if c == '\'' {
+				if pos+1 >= len(path) || path[pos+1] != ']' {
+					return pathToken{}, pos, fmt.Errorf("invalid matching rule path %q: expected ']' at position %d", path, pos+1)
+				}
+				if key.Len() == 0 {
+					return pathToken{}, pos, fmt.Errorf("invalid matching rule path %q: empty key at position %d", path, pos)
+				}
+				return pathToken{kind: keyToken, key: key.String()}, pos + 2, nil
+			}
+			key.WriteByte(c)
+			pos++
+		}
+		return pathToken{}, pos, fmt.Errorf("invalid matching rule path %q: unterminated quoted key", path)
+	default:
+		end := strings.IndexByte(path[pos:], ']')
+		if end < 0 {
+			return pathToken{}, pos, fmt.Errorf("invalid matching rule path %q: unterminated '['", path)
+		}
+		index, err := strconv.Atoi(path[pos : pos+end])
+		if err != nil || index < 0 {
+			return pathToken{}, pos, fmt.Errorf("invalid matching rule path %q: invalid array index %q at position %d", path, path[pos:pos+end], pos)
+		}
+		return pathToken{kind: indexToken, index: index}, pos + end + 1, nil
+	}
+}
+
+// relativeTo re-roots the path beneath the given root e.g. "$.id" relative
+// to "$.body" becomes "$.body.id"
+func (p RulePath) relativeTo(root RulePath) RulePath {
+	tokens := make([]pathToken, 0, len(root.tokens)+len(p.tokens))
+	tokens = append(tokens, root.tokens...)
+
+	return RulePath{tokens: append(tokens, p.tokens...)}
+}
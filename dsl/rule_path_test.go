@@ -0,0 +1,157 @@
+package dsl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRulePath_String(t *testing.T) {
+	tests := []struct {
+		name string
+		path RulePath
+		want string
+	}{
+		{name: "root", path: NewRulePath(), want: "$"},
+		{name: "body", path: BodyPath(), want: "$.body"},
+		{name: "nested wildcard", path: BodyPath().Key("items").AnyIndex().Key("id"), want: "$.body.items[*].id"},
+		{name: "index", path: BodyPath().Key("items").Index(2), want: "$.body.items[2]"},
+		{name: "any key", path: BodyPath().Key("animals").AnyKey(), want: "$.body.animals.*"},
+		{name: "key with dot", path: BodyPath().Key("a.b"), want: "$.body['a.b']"},
+		{name: "key with at", path: BodyPath().Key("@context"), want: "$.body['@context']"},
+		{name: "key with space", path: BodyPath().Key("first name"), want: "$.body['first name']"},
+		{name: "key with quote", path: BodyPath().Key("it's"), want: `$.body['it\'s']`},
+		{name: "header", path: HeaderPath("Content-Type"), want: "$.headers.Content-Type"},
+		{name: "query", path: QueryPath("page"), want: "$.query.page"},
+		{name: "path", path: RequestPathPath(), want: "$.path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.path.String(); got != tt.want {
+				t.Fatalf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRulePath_Immutable(t *testing.T) {
+	items := BodyPath().Key("items")
+	first := items.Index(0)
+	second := items.Index(1)
+
+	if first.String() != "$.body.items[0]" || second.String() != "$.body.items[1]" {
+		t.Fatalf("expected paths sharing a prefix to be independent, got %s and %s", first, second)
+	}
+}
+
+func TestRulePath_MarshalText(t *testing.T) {
+	b, _ := json.Marshal(map[string]interface{}{"path": BodyPath().Key("a.b")})
+
+	if string(b) != `{"path":"$.body['a.b']"}` {
+		t.Fatalf("unexpected JSON %s", b)
+	}
+}
+
+func TestRulePath_ParseRoundTrip(t *testing.T) {
+	paths := []string{
+		"$",
+		"$.body",
+		"$.body.items[*].id",
+		"$.body.items[2].name",
+		"$.body.animals.*",
+		"$.body['a.b'].c",
+		"$.body['@context']",
+		`$.body['it\'s']`,
+		"$.headers.Content-Type",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			parsed, err := ParseRulePath(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed.String() != path {
+				t.Fatalf("want %s, got %s", path, parsed.String())
+			}
+		})
+	}
+}
+
+func TestRulePath_ParseNormalisesBrackets(t *testing.T) {
+	parsed, err := ParseRulePath("$['body']['id']")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if parsed.String() != "$.body.id" {
+		t.Fatalf("want $.body.id, got %s", parsed.String())
+	}
+}
+
+func TestRulePath_ParseInvalid(t *testing.T) {
+	paths := []string{
+		"",
+		"body.id",
+		"$.body.a b",
+		"$.body.@context",
+		"$.body..id",
+		"$.body[",
+		"$.body[*",
+		"$.body[abc]",
+		"$.body[-1]",
+		"$.body['unterminated",
+		"$.body['']",
+		"$body",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			if _, err := ParseRulePath(path); err == nil {
+				t.Fatalf("expected an error for %q", path)
+			}
+		})
+	}
+}
+
+func TestMatchingRules_Validate(t *testing.T) {
+	valid := MatchingRules{}.
+		Add(BodyPath().Key("items"), MinTypeRule(1)).
+		Add(BodyPath().Key("items").AnyIndex().Key("id"), RegexRule(`\d+`)).
+		Add(HeaderPath("Authorization"), RegexRule("Bearer .+"))
+
+	if err := valid.validate("body", "headers"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := (MatchingRules{"$.body.a b": TypeRule()}).validate("body"); err == nil {
+		t.Fatal("expected an error for an invalid path")
+	}
+
+	if err := (MatchingRules{"$.query.page": TypeRule()}).validate("body", "headers"); err == nil {
+		t.Fatal("expected an error for a path outside the allowed categories")
+	}
+}
+
+func TestInteraction_ValidateMatchingRules(t *testing.T) {
+	i := (&Interaction{}).
+		UponReceiving("a request").
+		WithRequest(Request{
+			Method:        "GET",
+			Path:          String("/users"),
+			MatchingRules: MatchingRules{"$.query.page": TypeRule()},
+		}).
+		WillRespondWith(Response{
+			Status:        200,
+			MatchingRules: MatchingRules{"$.body.id": TypeRule()},
+		})
+
+	if err := i.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	i.Response.MatchingRules = MatchingRules{"$.query.page": TypeRule()}
+	if err := i.Validate(); err == nil {
+		t.Fatal("expected responses to reject query matching rules")
+	}
+}
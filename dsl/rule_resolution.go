@@ -0,0 +1,332 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RuleMatch is a matching rule that applies to a value, and how specifically
+// its path matches the value's path
+type RuleMatch struct {
+	// Path is the rule's path expression e.g. "$.body.items[*].id"
+	Path string
+
+	Rule Rule
+
+	// Weight is how specifically the path matches, see PathWeight. The rule
+	// with the greatest weight is applied.
+	Weight int
+}
+
+// PathWeight calculates how specifically a rule path matches the path of a
+// value, as defined by the pact specification: each element of the rule path
+// scores 2 if it names the value's key or index exactly, or 1 if it is a
+// wildcard ("*" or "[*]"), and the weight is the product of the scores. The
+// weight is 0 if the rule path doesn't match, or is longer than the value's
+// path. A rule path shorter than the value's path matches its descendants,
+// so that e.g. a type rule on an array applies to its elements.
+func PathWeight(rulePath RulePath, valuePath RulePath) int {
+	if len(rulePath.tokens) > len(valuePath.tokens) {
+		return 0
+	}
+
+	// The root "$" always matches
+	weight := 2
+	for n, token := range rulePath.tokens {
+		value := valuePath.tokens[n]
+		switch {
+		case token.kind == keyToken && value.kind == keyToken && token.key == value.key,
+			token.kind == indexToken && value.kind == indexToken && token.index == value.index:
+			weight *= 2
+		case token.kind == anyKeyToken && (value.kind == keyToken || value.kind == indexToken),
+			token.kind == anyIndexToken && value.kind == indexToken:
+			weight *= 1
+		default:
+			return 0
+		}
+	}
+
+	return weight
+}
+
+// Candidates returns the rules whose paths match the value's path, the rule
+// applied first, followed by the rules it overrides. Rules are ordered by
+// weight, then by the length of their path, so that of two equally weighted
+// rules the one nearer the value wins.
+func (m MatchingRules) Candidates(path RulePath) ([]RuleMatch, error) {
+	var candidates []RuleMatch
+	lengths := make(map[string]int)
+	for expression, rule := range m {
+		rulePath, err := ParseRulePath(expression)
+		if err != nil {
+			return nil, err
+		}
+		if weight := PathWeight(rulePath, path); weight > 0 {
+			candidates = append(candidates, RuleMatch{Path: expression, Rule: rule, Weight: weight})
+			lengths[expression] = len(rulePath.tokens)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case a.Weight != b.Weight:
+			return a.Weight > b.Weight
+		case lengths[a.Path] != lengths[b.Path]:
+			return lengths[a.Path] > lengths[b.Path]
+		}
+		return a.Path < b.Path
+	})
+
+	return candidates, nil
+}
+
+// Resolve returns the rule applied to the value at the path, being the most
+// specific rule whose path matches it (see Candidates), if any
+func (m MatchingRules) Resolve(path RulePath) (RuleMatch, bool, error) {
+	candidates, err := m.Candidates(path)
+	if err != nil || len(candidates) == 0 {
+		return RuleMatch{}, false, err
+	}
+
+	return candidates[0], true, nil
+}
+
+// RuleExplanation explains how a value in an actual document was matched
+type RuleExplanation struct {
+	// Path of the value e.g. "$.body.items[0].id"
+	Path string
+
+	// Value is the actual value
+	Value interface{}
+
+	// Applied is the rule applied to the value, if any. Values without a
+	// rule are matched by equality with the expected value.
+	Applied *RuleMatch
+
+	// Overridden are the other rules whose paths match the value, but which
+	// are less specific than the applied rule
+	Overridden []RuleMatch
+
+	// Passed is whether the value satisfies the applied rule, and Message
+	// explains why not
+	Passed  bool
+	Message string
+}
+
+func (e RuleExplanation) String() string {
+	value, _ := json.Marshal(e.Value)
+	if e.Applied == nil {
+		explanation := fmt.Sprintf("%s = %s: no rule, matched by equality", e.Path, value)
+		if !e.Passed {
+			explanation += ": " + e.Message
+		}
+		return explanation
+	}
+
+	rule, _ := json.Marshal(e.Applied.Rule)
+	explanation := fmt.Sprintf("%s = %s: %s from %s (weight %d)", e.Path, value, rule, e.Applied.Path, e.Applied.Weight)
+	for _, overridden := range e.Overridden {
+		explanation += fmt.Sprintf(", overriding %s (weight %d)", overridden.Path, overridden.Weight)
+	}
+	if !e.Passed {
+		explanation += ": " + e.Message
+	}
+
+	return explanation
+}
+
+// ExplainRules walks an actual JSON document, e.g. a response body, and
+// explains the rule applied to each value and whether the value satisfies
+// it. The root is the path of the document within the request or response,
+// usually BodyPath(). The expected example document is used for type and
+// equality rules, and may be nil.
+//
+// It is intended for diagnosing surprising passes and failures, and
+// evaluates the rules in the manner of the pact specification rather than
+// replacing the verifier.
+func ExplainRules(rules MatchingRules, root RulePath, expected []byte, actual []byte) ([]RuleExplanation, error) {
+	var expectedDoc interface{}
+	actualDoc, err := decodeJSON(actual)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse actual document: %v", err)
+	}
+	if expected != nil {
+		if expectedDoc, err = decodeJSON(expected); err != nil {
+			return nil, fmt.Errorf("unable to parse expected document: %v", err)
+		}
+	}
+
+	var explanations []RuleExplanation
+	err = explainValue(rules, root, expectedDoc, expected != nil, actualDoc, &explanations)
+
+	return explanations, err
+}
+
+func explainValue(rules MatchingRules, path RulePath, expected interface{}, hasExpected bool, actual interface{}, explanations *[]RuleExplanation) error {
+	candidates, err := rules.Candidates(path)
+	if err != nil {
+		return err
+	}
+
+	explanation := RuleExplanation{Path: path.String(), Value: actual, Passed: true}
+	if len(candidates) > 0 {
+		explanation.Applied = &candidates[0]
+		explanation.Overridden = candidates[1:]
+		explanation.Message = ruleError(candidates[0].Rule, expected, hasExpected, actual)
+	} else if hasExpected && !isContainer(actual) && !equalJSON(expected, actual) {
+		explanation.Message = fmt.Sprintf("expected %s", jsonString(expected))
+	}
+	explanation.Passed = explanation.Message == ""
+	*explanations = append(*explanations, explanation)
+
+	switch v := actual.(type) {
+	case map[string]interface{}:
+		expectedObject, _ := expected.(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, ok := expectedObject[key]
+			if err := explainValue(rules, path.Key(key), child, hasExpected && ok, v[key], explanations); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		expectedArray, _ := expected.([]interface{})
+		for n, item := range v {
+			// Elements beyond the example are compared with its first element
+			var child interface{}
+			ok := false
+			switch {
+			case n < len(expectedArray):
+				child, ok = expectedArray[n], true
+			case len(expectedArray) > 0:
+				child, ok = expectedArray[0], true
+			}
+			if err := explainValue(rules, path.Index(n), child, hasExpected && ok, item, explanations); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ruleError evaluates a rule (or a combination of rules) against the actual
+// value, returning why it isn't satisfied
+func ruleError(rule Rule, expected interface{}, hasExpected bool, actual interface{}) string {
+	matchers, ok := rule["matchers"].([]interface{})
+	if !ok {
+		return singleRuleError(rule, expected, hasExpected, actual)
+	}
+
+	combine, _ := rule["combine"].(string)
+	var errs []string
+	for _, raw := range matchers {
+		entry, ok := raw.(Rule)
+		if !ok {
+			entry, _ = raw.(map[string]interface{})
+		}
+		err := singleRuleError(entry, expected, hasExpected, actual)
+		if err == "" && strings.EqualFold(combine, "OR") {
+			return ""
+		}
+		if err != "" {
+			errs = append(errs, err)
+		}
+	}
+
+	separator := " and "
+	if strings.EqualFold(combine, "OR") {
+		separator = " or "
+	}
+
+	return strings.Join(errs, separator)
+}
+
+func singleRuleError(rule Rule, expected interface{}, hasExpected bool, actual interface{}) string {
+	match, _ := rule["match"].(string)
+	if match == "" && rule["regex"] != nil {
+		match = "regex"
+	}
+
+	switch match {
+	case "", "type":
+		if hasExpected && jsonType(expected) != jsonType(actual) {
+			return fmt.Sprintf("expected %s but got %s", describeJSON(expected), describeJSON(actual))
+		}
+		return arrayLengthError(rule, actual)
+	case "regex":
+		pattern, _ := rule["regex"].(string)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Sprintf("invalid regular expression %q: %v", pattern, err)
+		}
+		if isContainer(actual) || actual == nil || !re.MatchString(fmt.Sprint(actual)) {
+			return fmt.Sprintf("expected a value matching %q", pattern)
+		}
+	case "equality":
+		if hasExpected && !equalJSON(expected, actual) {
+			return fmt.Sprintf("expected %s", jsonString(expected))
+		}
+	case "include":
+		value, _ := rule["value"].(string)
+		if s, ok := actual.(string); !ok || !strings.Contains(s, value) {
+			return fmt.Sprintf("expected a string including %q", value)
+		}
+	case "integer":
+		if !isInteger(actual) {
+			return "expected an integer"
+		}
+	case "decimal", "number":
+		if jsonType(actual) != "number" {
+			return fmt.Sprintf("expected a %s", match)
+		}
+	case "boolean":
+		if _, ok := actual.(bool); !ok {
+			return "expected a boolean"
+		}
+	case "null":
+		if actual != nil {
+			return "expected null"
+		}
+	default:
+		// Other rules (e.g. dates) are left to the verifier
+		return ""
+	}
+
+	return ""
+}
+
+// arrayLengthError checks the min and max of a type rule on an array
+func arrayLengthError(rule Rule, actual interface{}) string {
+	items, ok := actual.([]interface{})
+	if !ok {
+		return ""
+	}
+
+	if min, ok := rule["min"].(float64); ok && len(items) < int(min) {
+		return fmt.Sprintf("expected at least %d elements but got %d", int(min), len(items))
+	}
+	if min, ok := rule["min"].(int); ok && len(items) < min {
+		return fmt.Sprintf("expected at least %d elements but got %d", min, len(items))
+	}
+	if max, ok := rule["max"].(float64); ok && len(items) > int(max) {
+		return fmt.Sprintf("expected at most %d elements but got %d", int(max), len(items))
+	}
+	if max, ok := rule["max"].(int); ok && len(items) > max {
+		return fmt.Sprintf("expected at most %d elements but got %d", max, len(items))
+	}
+
+	return ""
+}
+
+func isContainer(value interface{}) bool {
+	return jsonType(value) == "object" || jsonType(value) == "array"
+}
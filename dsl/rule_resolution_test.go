@@ -0,0 +1,114 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathWeight(t *testing.T) {
+	value := BodyPath().Key("items").Index(0).Key("id")
+	tests := map[string]int{
+		"$.body.items[0].id":       32,
+		"$.body.items[*].id":       16,
+		"$.body.*[*].id":           8,
+		"$.body.items":             8,
+		"$.body.items[1].id":       0,
+		"$.body.id":                0,
+		"$.body.items[0].id.value": 0,
+	}
+
+	for expression, want := range tests {
+		path, err := ParseRulePath(expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if weight := PathWeight(path, value); weight != want {
+			t.Fatalf("%s: want weight %d, got %d", expression, want, weight)
+		}
+	}
+}
+
+func TestMatchingRules_Resolve(t *testing.T) {
+	rules := MatchingRules{
+		"$.body.items":       MinTypeRule(1),
+		"$.body.items[*].id": RegexRule(`^\d+$`),
+		"$.body.*[*].id":     TypeRule(),
+	}
+
+	match, ok, err := rules.Resolve(BodyPath().Key("items").Index(0).Key("id"))
+	if err != nil || !ok || match.Path != "$.body.items[*].id" || match.Weight != 16 {
+		t.Fatalf("expected the regex rule to be applied, got %+v, %v, %v", match, ok, err)
+	}
+
+	candidates, err := rules.Candidates(BodyPath().Key("items").Index(0).Key("id"))
+	if err != nil || len(candidates) != 3 {
+		t.Fatalf("want 3 candidates, got %v: %v", candidates, err)
+	}
+	// Equally weighted rules are ordered nearest first
+	if candidates[1].Path != "$.body.*[*].id" || candidates[2].Path != "$.body.items" {
+		t.Fatalf("unexpected candidate order %v", candidates)
+	}
+
+	if _, ok, _ = rules.Resolve(BodyPath().Key("name")); ok {
+		t.Fatalf("expected no rule for $.body.name")
+	}
+	if _, _, err = (MatchingRules{"$.body[": TypeRule()}).Resolve(BodyPath()); err == nil {
+		t.Fatalf("expected an error for an invalid rule path")
+	}
+}
+
+func TestExplainRules(t *testing.T) {
+	rules := MatchingRules{
+		"$.body.items":       MinTypeRule(2),
+		"$.body.items[*].id": RegexRule(`^\d+$`),
+		"$.body.count":       {"combine": "OR", "matchers": []interface{}{Rule{"match": "number"}, RegexRule(`^\d+$`)}},
+	}
+	expected := []byte(`{"items": [{"id": "1", "name": "a"}], "count": 1, "status": "ok"}`)
+	actual := []byte(`{"items": [{"id": "x", "name": 2}], "count": "3", "status": "ok"}`)
+
+	explanations, err := ExplainRules(rules, BodyPath(), expected, actual)
+	if err != nil {
+		t.Fatalf("unable to explain rules: %v", err)
+	}
+
+	var lines []string
+	for _, explanation := range explanations {
+		lines = append(lines, explanation.String())
+	}
+	want := []string{
+		`$.body = {"count":"3","items":[{"id":"x","name":2}],"status":"ok"}: no rule, matched by equality`,
+		`$.body.count = "3": {"combine":"OR","matchers":[{"match":"number"},{"match":"regex","regex":"^\\d+$"}]} from $.body.count (weight 8)`,
+		`$.body.items = [{"id":"x","name":2}]: {"match":"type","min":2} from $.body.items (weight 8): expected at least 2 elements but got 1`,
+		`$.body.items[0] = {"id":"x","name":2}: {"match":"type","min":2} from $.body.items (weight 8)`,
+		`$.body.items[0].id = "x": {"match":"regex","regex":"^\\d+$"} from $.body.items[*].id (weight 16), overriding $.body.items (weight 8): expected a value matching "^\\d+$"`,
+		`$.body.items[0].name = 2: {"match":"type","min":2} from $.body.items (weight 8): expected a string ("a") but got a number (2)`,
+		`$.body.status = "ok": no rule, matched by equality`,
+	}
+	if got := strings.Join(lines, "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), got)
+	}
+}
+
+func TestExplainRules_LargeNumbers(t *testing.T) {
+	rules := MatchingRules{
+		"$.body.id":    {"match": "integer"},
+		"$.body.total": {"match": "decimal"},
+	}
+	expected := []byte(`{"id": 9007199254740993, "total": 0.1, "ref": 9007199254740993}`)
+	actual := []byte(`{"id": 9007199254740993, "total": 12345678901234567890.123456789, "ref": 9007199254740992}`)
+
+	explanations, err := ExplainRules(rules, BodyPath(), expected, actual)
+	if err != nil {
+		t.Fatalf("unable to explain rules: %v", err)
+	}
+
+	var failed []string
+	for _, explanation := range explanations {
+		if !explanation.Passed {
+			failed = append(failed, explanation.String())
+		}
+	}
+	if want := `$.body.ref = 9007199254740992: no rule, matched by equality: expected 9007199254740993`; strings.Join(failed, "\n") != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, strings.Join(failed, "\n"))
+	}
+}
@@ -0,0 +1,397 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// schemaDrift fingerprints the shape of the provider's JSON responses to each
+// interaction, and compares them with the fingerprints of the previous
+// verification, to report fields that have been removed or changed type
+// even though the interactions still pass
+type schemaDrift struct {
+	mu   sync.Mutex
+	file string
+
+	// interactions are keyed by method and path
+	interactions map[string][]driftInteraction
+
+	// state is the provider state most recently set up, if state changes
+	// pass through the proxy
+	state string
+
+	previous map[string][]string
+	current  map[string][]string
+}
+
+type driftInteraction struct {
+	consumer    string
+	description string
+	states      []string
+}
+
+// name identifies the interaction in the fingerprint file
+func (i driftInteraction) name() string {
+	return fmt.Sprintf("%s: %s", i.consumer, i.description)
+}
+
+// shapeChange is a difference between the shapes of two responses
+type shapeChange struct {
+	interaction driftInteraction
+	removed     []string
+	changed     []string
+	added       []string
+}
+
+// drifted is whether fields were removed or changed type
+func (c shapeChange) drifted() bool {
+	return len(c.removed) > 0 || len(c.changed) > 0
+}
+
+func (c shapeChange) String() string {
+	var changes []string
+	if len(c.removed) > 0 {
+		changes = append(changes, "removed "+strings.Join(c.removed, ", "))
+	}
+	if len(c.changed) > 0 {
+		changes = append(changes, "changed "+strings.Join(c.changed, ", "))
+	}
+	if len(c.added) > 0 {
+		changes = append(changes, "added "+strings.Join(c.added, ", "))
+	}
+
+	return strings.Join(changes, "; ")
+}
+
+// newSchemaDrift reads the interactions of the pacts and the fingerprints of
+// the previous verification. It returns nil if no fingerprint file is
+// configured. Pacts that can't be read are left for the verifier to report.
+func newSchemaDrift(pactURLs []string, request types.VerifyRequest) (*schemaDrift, error) {
+	if request.SchemaDriftFile == "" {
+		return nil, nil
+	}
+
+	s := &schemaDrift{
+		file:         request.SchemaDriftFile,
+		interactions: make(map[string][]driftInteraction),
+		previous:     make(map[string][]string),
+		current:      make(map[string][]string),
+	}
+
+	content, err := ioutil.ReadFile(request.SchemaDriftFile)
+	switch {
+	case os.IsNotExist(err):
+		log.Println("[INFO] no previous response fingerprints, recording them to", request.SchemaDriftFile)
+	case err != nil:
+		return nil, fmt.Errorf("unable to read response fingerprints: %v", err)
+	default:
+		if err = json.Unmarshal(content, &s.previous); err != nil {
+			return nil, fmt.Errorf("unable to parse response fingerprints %s: %v", request.SchemaDriftFile, err)
+		}
+	}
+
+	for _, location := range pactURLs {
+		pact, err := readTriagePact(location, request)
+		if err != nil {
+			log.Printf("[WARN] unable to read pact %s to fingerprint its responses: %v\n", location, err)
+			continue
+		}
+
+		for _, interaction := range pact.Interactions {
+			key := negotiationKey(interaction.Request.Method, interaction.Request.Path)
+			s.interactions[key] = append(s.interactions[key], driftInteraction{
+				consumer:    pact.Consumer.Name,
+				description: interaction.Description,
+				states:      interaction.States(),
+			})
+		}
+	}
+
+	return s, nil
+}
+
+// middleware fingerprints each JSON response. It is placed before the state
+// handlers, so that it sees the provider state of each request.
+func (s *schemaDrift) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == providerStatesSetupPath {
+			s.setState(r)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s.mu.Lock()
+		interaction, ok := s.interaction(r.Method, r.URL.Path)
+		s.mu.Unlock()
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		var actual interface{}
+		if json.Unmarshal(recorder.body.Bytes(), &actual) == nil {
+			s.mu.Lock()
+			s.current[interaction.name()] = responseShape(actual)
+			s.mu.Unlock()
+		}
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(recorder.status)
+		w.Write(recorder.body.Bytes()) // nolint:errcheck
+	})
+}
+
+// setState records the provider state being set up, or clears it on
+// teardown
+func (s *schemaDrift) setState(r *http.Request) {
+	body, err := readRequestBody(r)
+	if err != nil {
+		return
+	}
+
+	var change struct {
+		State  string `json:"state"`
+		Action string `json:"action"`
+	}
+	if json.Unmarshal(body, &change) != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if change.Action == "teardown" {
+		s.state = ""
+	} else {
+		s.state = change.State
+	}
+}
+
+// interaction finds the interaction a request is for, by its method, path
+// and provider state. Requests that may be for several interactions are
+// skipped.
+func (s *schemaDrift) interaction(method, path string) (driftInteraction, bool) {
+	candidates := s.interactions[negotiationKey(method, path)]
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	var matched []driftInteraction
+	for _, candidate := range candidates {
+		if (s.state == "" && len(candidate.states) == 0) || (len(candidate.states) > 0 && candidate.states[0] == s.state) {
+			matched = append(matched, candidate)
+		}
+	}
+	if len(matched) != 1 {
+		if len(candidates) > 0 {
+			log.Printf("[DEBUG] unable to tell which interaction %s %s is for, skipping its fingerprint", method, path)
+		}
+		return driftInteraction{}, false
+	}
+
+	return matched[0], true
+}
+
+// report logs the responses whose shape has changed since the previous
+// verification and saves the new fingerprints. If fail is set, the body
+// examples of interactions whose fields were removed or changed type are
+// marked as failed.
+func (s *schemaDrift) report(res []types.ProviderVerifierResponse, err error, fail bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := make(map[string]shapeChange)
+	for _, interactions := range s.interactions {
+		for _, interaction := range interactions {
+			current, ok := s.current[interaction.name()]
+			previous, existed := s.previous[interaction.name()]
+			if !ok || !existed {
+				continue
+			}
+			change := compareShapes(previous, current)
+			if len(change.removed)+len(change.changed)+len(change.added) == 0 {
+				continue
+			}
+			change.interaction = interaction
+			changes[interaction.name()] = change
+
+			level := "[INFO]"
+			if change.drifted() {
+				level = "[WARN]"
+			}
+			log.Printf("%s the response to '%s' has changed shape since the last verification: %s\n", level, interaction.name(), change)
+		}
+	}
+
+	if len(s.current) > 0 {
+		for name, shape := range s.current {
+			s.previous[name] = shape
+		}
+		content, marshalErr := json.MarshalIndent(s.previous, "", "  ")
+		if marshalErr == nil {
+			marshalErr = ioutil.WriteFile(s.file, content, 0644)
+		}
+		if marshalErr != nil {
+			log.Println("[WARN] unable to save response fingerprints:", marshalErr)
+		}
+	}
+
+	if !fail {
+		return err
+	}
+
+	// consumer -> descriptions of changed interactions
+	descriptions := make(map[string][]string)
+	for _, change := range changes {
+		consumer := change.interaction.consumer
+		descriptions[consumer] = append(descriptions[consumer], change.interaction.description)
+	}
+
+	failed := 0
+	for _, response := range res {
+		for n, example := range response.Examples {
+			if example.Status != "passed" || !strings.Contains(example.FullDescription, "has a matching body") {
+				continue
+			}
+			consumer := example.Pact.ConsumerName
+			name := fmt.Sprintf("%s: %s", consumer, exampleDescription(example.FullDescription, descriptions[consumer]))
+			change, ok := changes[name]
+			if !ok || !change.drifted() {
+				continue
+			}
+
+			message := fmt.Sprintf("the response has changed shape since the last verification: %s", change)
+			response.Examples[n].Status = "failed"
+			response.Examples[n].Exception.Message = message
+			response.Examples[n].Mismatches = append(response.Examples[n].Mismatches, message)
+			failed++
+		}
+	}
+
+	if failed > 0 && err == nil {
+		err = fmt.Errorf("%d interactions' responses have changed shape since the last verification", failed)
+	}
+
+	return err
+}
+
+// responseShape fingerprints the JSON value as the sorted paths of its
+// fields and their types e.g. "$.items[*].id:number". The elements of arrays
+// are merged.
+func responseShape(value interface{}) []string {
+	shape := make(map[string]bool)
+	addShape(shape, "$", value)
+
+	paths := make([]string, 0, len(shape))
+	for path := range shape {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+func addShape(shape map[string]bool, path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		shape[path+":object"] = true
+		for key, field := range v {
+			addShape(shape, path+"."+key, field)
+		}
+	case []interface{}:
+		shape[path+":array"] = true
+		for _, item := range v {
+			addShape(shape, path+"[*]", item)
+		}
+	case string:
+		shape[path+":string"] = true
+	case float64:
+		shape[path+":number"] = true
+	case bool:
+		shape[path+":boolean"] = true
+	case nil:
+		shape[path+":null"] = true
+	}
+}
+
+// compareShapes finds the fields removed from, added to, or whose type has
+// changed in the current shape
+func compareShapes(previous []string, current []string) shapeChange {
+	fieldTypes := func(shape []string) map[string][]string {
+		fields := make(map[string][]string)
+		for _, entry := range shape {
+			i := strings.LastIndex(entry, ":")
+			fields[entry[:i]] = append(fields[entry[:i]], entry[i+1:])
+		}
+		return fields
+	}
+	before, after := fieldTypes(previous), fieldTypes(current)
+
+	var change shapeChange
+	for path, was := range before {
+		now, ok := after[path]
+		switch {
+		case !ok:
+			if !removedParent(path, before, after) {
+				change.removed = append(change.removed, path)
+			}
+		case strings.Join(was, "|") != strings.Join(now, "|"):
+			change.changed = append(change.changed, fmt.Sprintf("%s from %s to %s", path, strings.Join(was, "|"), strings.Join(now, "|")))
+		}
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok && !removedParent(path, after, before) {
+			change.added = append(change.added, path)
+		}
+	}
+	sort.Strings(change.removed)
+	sort.Strings(change.changed)
+	sort.Strings(change.added)
+
+	return change
+}
+
+// removedParent is whether the field's object or array is itself missing
+// from the other shape, and so is reported instead of the field
+func removedParent(path string, shape map[string][]string, other map[string][]string) bool {
+	for parent := path; ; {
+		i := strings.LastIndexAny(parent, ".[")
+		if i <= 0 {
+			return false
+		}
+		parent = parent[:i]
+		if _, ok := shape[parent]; ok {
+			if _, ok := other[parent]; !ok {
+				return true
+			}
+		}
+	}
+}
+
+// readRequestBody reads the request body, replacing it so it may be read
+// again
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, err
+}
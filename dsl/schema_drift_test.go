@@ -0,0 +1,115 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestResponseShape(t *testing.T) {
+	var body interface{}
+	if err := json.Unmarshal([]byte(`{"id": 1, "tags": [{"name": "a"}, {"name": null}], "active": true}`), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"$.active:boolean", "$.id:number", "$.tags:array", "$.tags[*].name:null", "$.tags[*].name:string", "$.tags[*]:object", "$:object"}
+	if shape := responseShape(body); !reflect.DeepEqual(shape, want) {
+		t.Fatalf("want %v, got %v", want, shape)
+	}
+}
+
+func TestCompareShapes(t *testing.T) {
+	previous := []string{"$.address.city:string", "$.address:object", "$.email:string", "$.id:number", "$:object"}
+	current := []string{"$.id:string", "$.nickname:string", "$:object"}
+
+	change := compareShapes(previous, current)
+	if want := "removed $.address, $.email; changed $.id from number to string; added $.nickname"; change.String() != want {
+		t.Fatalf("want %q, got %q", want, change.String())
+	}
+	if !change.drifted() {
+		t.Fatalf("expected removed fields to be drift")
+	}
+	if compareShapes(current, append(current, "$.extra:number")).drifted() {
+		t.Fatalf("expected added fields not to be drift")
+	}
+}
+
+func TestSchemaDrift(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drift")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "web-users.json")
+	pact := `{"consumer": {"name": "web"}, "provider": {"name": "users"}, "interactions": [
+		{"description": "a request for user 1", "providerState": "user 1 exists", "request": {"method": "GET", "path": "/users/1"}, "response": {"status": 200, "body": {"id": 1}}},
+		{"description": "a request for a missing user 1", "request": {"method": "GET", "path": "/users/1"}, "response": {"status": 404}}
+	]}`
+	if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	request := types.VerifyRequest{SchemaDriftFile: filepath.Join(dir, "fingerprints.json")}
+	body := `{"id": 1, "email": "mary@example.com"}`
+	verify := func() ([]types.ProviderVerifierResponse, error) {
+		drift, err := newSchemaDrift([]string{file}, request)
+		if err != nil || drift == nil {
+			t.Fatalf("expected the interactions to be read, got %v", err)
+		}
+
+		provider := drift.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		}))
+		provider.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", providerStatesSetupPath, strings.NewReader(`{"consumer": "web", "state": "user 1 exists"}`)))
+		recorder := httptest.NewRecorder()
+		provider.ServeHTTP(recorder, httptest.NewRequest("GET", "/users/1", nil))
+		if recorder.Body.String() != body {
+			t.Fatalf("expected the response to be passed on, got %s", recorder.Body.String())
+		}
+		provider.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", providerStatesSetupPath, strings.NewReader(`{"consumer": "web", "state": "user 1 exists", "action": "teardown"}`)))
+
+		var res []types.ProviderVerifierResponse
+		err = json.Unmarshal([]byte(`[{"examples": [
+			{"status": "passed", "full_description": "Verifying a pact between web and users Given user 1 exists A request for user 1 with GET /users/1 returns a response which has a matching body", "pact": {"consumer_name": "web"}}
+		]}]`), &res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res, drift.report(res, nil, true)
+	}
+
+	// The first verification records the fingerprints
+	if _, err = verify(); err != nil {
+		t.Fatalf("expected the first verification to pass, got %v", err)
+	}
+	content, err := ioutil.ReadFile(request.SchemaDriftFile)
+	if err != nil || !strings.Contains(string(content), `"web: a request for user 1"`) || strings.Contains(string(content), "missing") {
+		t.Fatalf("expected a fingerprint of only user 1, got %s: %v", content, err)
+	}
+
+	// The email field is removed
+	body = `{"id": 1}`
+	res, err := verify()
+	if err == nil || err.Error() != "1 interactions' responses have changed shape since the last verification" {
+		t.Fatalf("expected an error for the removed field, got %v", err)
+	}
+	if want := "the response has changed shape since the last verification: removed $.email"; res[0].Examples[0].Exception.Message != want {
+		t.Fatalf("want %q, got %q", want, res[0].Examples[0].Exception.Message)
+	}
+
+	// The new fingerprint is the baseline for the next verification
+	if _, err = verify(); err != nil {
+		t.Fatalf("expected an unchanged response to pass, got %v", err)
+	}
+}
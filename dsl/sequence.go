@@ -0,0 +1,157 @@
+package dsl
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sequenceHeader is added to requests by the mock server proxy to select the
+// current step of a sequence. It is removed from the pact file when written.
+const sequenceHeader = "X-Pact-Go-Sequence"
+
+// Sequence marks the interaction as the nth (1-indexed) response to a repeated
+// request. Requests with the same method and path are answered by each step of
+// the sequence in turn, with the final step answering any further requests.
+//
+// The request path must be a plain string, rather than a matcher.
+func (i *Interaction) Sequence(step int) *Interaction {
+	i.sequence = step
+
+	return i
+}
+
+// Repeat makes a step of a sequence answer the given number of requests
+// before moving on to the next step. Defaults to 1.
+func (i *Interaction) Repeat(times int) *Interaction {
+	i.repeat = times
+
+	return i
+}
+
+// applySequence adds the step of the sequence to the expected request
+func (i *Interaction) applySequence() {
+	if i.sequence <= 0 {
+		return
+	}
+
+	if i.Request.Headers == nil {
+		i.Request.Headers = MapMatcher{}
+	}
+	i.Request.Headers[sequenceHeader] = String(strconv.Itoa(i.sequence))
+}
+
+func hasSequencedInteractions(interactions []*Interaction) bool {
+	for _, i := range interactions {
+		if i.sequence > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestSequencer counts repeated requests, so that sequenced interactions
+// can be answered in order
+type requestSequencer struct {
+	mu sync.Mutex
+
+	// steps are the number of requests answered by each step of a sequence,
+	// keyed by method and path
+	steps map[string][]int
+
+	// counts is the number of requests received for each sequence
+	counts map[string]int
+}
+
+func newRequestSequencer() *requestSequencer {
+	return &requestSequencer{
+		steps:  make(map[string][]int),
+		counts: make(map[string]int),
+	}
+}
+
+// register records the step of a sequenced interaction
+func (s *requestSequencer) register(i *Interaction) {
+	if i.sequence <= 0 {
+		return
+	}
+
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		log.Printf("[WARN] interaction '%s' is sequenced but its path is not a plain string, it will not be matched\n", i.Description)
+		return
+	}
+
+	key := negotiationKey(i.Request.Method, path)
+
+	repeat := i.repeat
+	if repeat < 1 {
+		repeat = 1
+	}
+
+	s.mu.Lock()
+	for len(s.steps[key]) < i.sequence {
+		s.steps[key] = append(s.steps[key], 1)
+	}
+	s.steps[key][i.sequence-1] = repeat
+	s.mu.Unlock()
+}
+
+// step returns the step of the sequence answering the nth request
+func step(repeats []int, n int) int {
+	for i, repeat := range repeats {
+		if n <= repeat {
+			return i + 1
+		}
+		n -= repeat
+	}
+
+	return len(repeats)
+}
+
+// reset forgets all registered sequences
+func (s *requestSequencer) reset() {
+	s.mu.Lock()
+	s.steps = make(map[string][]int)
+	s.counts = make(map[string]int)
+	s.mu.Unlock()
+}
+
+// middleware tags requests for sequenced interactions with the current step
+func (s *requestSequencer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") == "" {
+			key := negotiationKey(r.Method, r.URL.Path)
+
+			s.mu.Lock()
+			if steps := s.steps[key]; len(steps) > 0 {
+				s.counts[key]++
+				r.Header.Set(sequenceHeader, strconv.Itoa(step(steps, s.counts[key])))
+			}
+			s.mu.Unlock()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// removeSequenceHeaders removes the sequence header from the requests in the
+// pact file, as it is not part of the contract
+func removeSequenceHeaders(file string) error {
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		request, _ := interaction["request"].(map[string]interface{})
+		headers, _ := request["headers"].(map[string]interface{})
+
+		for name := range headers {
+			if strings.EqualFold(name, sequenceHeader) {
+				delete(headers, name)
+			}
+		}
+		if headers != nil && len(headers) == 0 {
+			delete(request, "headers")
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestRequestSequencer_middleware(t *testing.T) {
+	s := newRequestSequencer()
+	for step := 1; step <= 2; step++ {
+		s.register((&Interaction{}).
+			WithRequest(Request{Method: "GET", Path: String("/jobs/1")}).
+			Sequence(step))
+	}
+
+	var received string
+	handler := s.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(sequenceHeader)
+	}))
+
+	for _, want := range []string{"1", "2", "2"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/jobs/1", nil))
+		if received != want {
+			t.Fatalf("want step %s, got %q", want, received)
+		}
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/jobs/2", nil))
+	if received != "" {
+		t.Fatalf("expected unsequenced requests to be untouched, got %q", received)
+	}
+
+	s.reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/jobs/1", nil))
+	if received != "" {
+		t.Fatalf("expected no sequencing after reset, got %q", received)
+	}
+}
+
+func TestInteraction_applySequence(t *testing.T) {
+	i := (&Interaction{}).WithRequest(Request{Method: "GET", Path: String("/")}).Sequence(2)
+	i.applySequence()
+
+	if i.Request.Headers[sequenceHeader] != String("2") {
+		t.Fatalf("expected the sequence header to be expected, got %v", i.Request.Headers)
+	}
+
+	plain := (&Interaction{}).WithRequest(Request{Method: "GET", Path: String("/")})
+	plain.applySequence()
+	if plain.Request.Headers != nil {
+		t.Fatalf("expected unsequenced interactions to be untouched, got %v", plain.Request.Headers)
+	}
+}
+
+func TestRemoveSequenceHeaders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-sequence")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "consumer-provider.json")
+	ioutil.WriteFile(file, []byte(`{"interactions":[
+		{"description":"a","request":{"method":"GET","path":"/","headers":{"X-Pact-Go-Sequence":"1"}}},
+		{"description":"b","request":{"method":"GET","path":"/","headers":{"X-Pact-Go-Sequence":"2","If-None-Match":"\"1\""}}}
+	]}`), 0644)
+
+	if err = removeSequenceHeaders(file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := ioutil.ReadFile(file)
+	if strings.Contains(string(content), sequenceHeader) {
+		t.Fatalf("expected the sequence header to be removed, got %s", content)
+	}
+
+	var pact struct {
+		Interactions []struct {
+			Request map[string]json.RawMessage `json:"request"`
+		} `json:"interactions"`
+	}
+	json.Unmarshal(content, &pact)
+	if _, ok := pact.Interactions[0].Request["headers"]; ok {
+		t.Fatal("expected empty headers to be removed")
+	}
+	if _, ok := pact.Interactions[1].Request["headers"]; !ok {
+		t.Fatal("expected other headers to be kept")
+	}
+}
+
+func TestPact_AddConditionalGet(t *testing.T) {
+	pact := &Pact{pactClient: newMockClient(), Server: &types.MockServer{}, DisableToolValidityCheck: true}
+
+	fetch, revalidate := pact.AddConditionalGet(ConditionalGet{
+		Description: "user 1",
+		Path:        "/users/1",
+		ETag:        `"33a64df5"`,
+		Body:        StructMatcher{"id": Like(1)},
+	})
+
+	if fetch.Response.Status != 200 || fetch.Response.Headers["ETag"].GetValue() != `"33a64df5"` || fetch.sequence != 1 {
+		t.Fatalf("unexpected fetch interaction %+v", fetch)
+	}
+	if revalidate.Response.Status != 304 || revalidate.Request.Headers["If-None-Match"].GetValue() != `"33a64df5"` || revalidate.sequence != 2 {
+		t.Fatalf("unexpected revalidate interaction %+v", revalidate)
+	}
+	if !hasSequencedInteractions(pact.Interactions) {
+		t.Fatal("expected the interactions to be sequenced")
+	}
+}
+
+func TestRequestSequencer_Repeat(t *testing.T) {
+	s := newRequestSequencer()
+	s.register((&Interaction{}).WithRequest(Request{Method: "GET", Path: String("/jobs/1")}).Sequence(2))
+	s.register((&Interaction{}).WithRequest(Request{Method: "GET", Path: String("/jobs/1")}).Sequence(1).Repeat(3))
+
+	var received []string
+	handler := s.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(sequenceHeader))
+	}))
+
+	for i := 0; i < 5; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/jobs/1", nil))
+	}
+
+	if strings.Join(received, ",") != "1,1,1,2,2" {
+		t.Fatalf("unexpected steps %v", received)
+	}
+}
+
+func TestPact_AddLongPoll(t *testing.T) {
+	pact := &Pact{pactClient: newMockClient(), Server: &types.MockServer{}, DisableToolValidityCheck: true}
+
+	pending, complete := pact.AddLongPoll(LongPoll{
+		Description:   "job 1",
+		Path:          "/jobs/1",
+		Retries:       3,
+		PendingState:  "job 1 is running",
+		CompleteState: "job 1 has finished",
+		Body:          StructMatcher{"status": "done"},
+	})
+
+	if pending.Response.Status != 204 || pending.State != "job 1 is running" || pending.sequence != 1 || pending.repeat != 3 {
+		t.Fatalf("unexpected pending interaction %+v", pending)
+	}
+	if complete.Response.Status != 200 || complete.State != "job 1 has finished" || complete.sequence != 2 {
+		t.Fatalf("unexpected complete interaction %+v", complete)
+	}
+	if pending.Request.Method != "GET" || pending.Description == complete.Description {
+		t.Fatalf("expected distinct GET interactions, got %q and %q", pending.Description, complete.Description)
+	}
+}
@@ -0,0 +1,27 @@
+package dsl
+
+import "regexp"
+
+// SigV4Headers matches the headers of a request signed with AWS Signature
+// Version 4, so that a consumer's signed requests may be recorded without
+// pinning the (time-bound) signature. Pair it with proxy.SigV4 to re-sign
+// the requests during provider verification.
+func SigV4Headers() MapMatcher {
+	return MapMatcher{
+		"Authorization": Term(
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+			`^AWS4-HMAC-SHA256 Credential=\S+/\d{8}/[^/]+/[^/]+/aws4_request, SignedHeaders=[a-z0-9;\-]+, Signature=[0-9a-f]{64}$`,
+		),
+		"X-Amz-Date": Term("20150830T123600Z", `^\d{8}T\d{6}Z$`),
+	}
+}
+
+// HMACSignature matches a hex encoded HMAC-SHA256 signature with the given
+// prefix (e.g. "sha256="). Pair it with proxy.HMAC to re-sign the requests
+// during provider verification.
+func HMACSignature(prefix string) Matcher {
+	return Term(
+		prefix+"b82fcb791acec57859b989b430a826488ce2e479fdf92326bd0a2e8375a42ba4",
+		"^"+regexp.QuoteMeta(prefix)+"[0-9a-f]{64}$",
+	)
+}
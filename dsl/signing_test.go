@@ -0,0 +1,21 @@
+package dsl
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSigningMatchers(t *testing.T) {
+	matchers := map[string]Matcher{
+		"Authorization": SigV4Headers()["Authorization"],
+		"X-Amz-Date":    SigV4Headers()["X-Amz-Date"],
+		"HMAC":          HMACSignature("sha256="),
+	}
+
+	for name, m := range matchers {
+		data := m.(term).Data
+		if !regexp.MustCompile(data.Matcher.Regex.(string)).MatchString(data.Generate.(string)) {
+			t.Fatalf("expected the %s example to match its regex", name)
+		}
+	}
+}
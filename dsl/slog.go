@@ -0,0 +1,128 @@
+//go:build go1.22
+// +build go1.22
+
+package dsl
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// levelTrace is the slog level of TRACE logs, below slog.LevelDebug
+const levelTrace = slog.LevelDebug - 4
+
+// slogLevels map the package's log levels to slog levels
+var slogLevels = map[string]slog.Level{
+	"TRACE": levelTrace,
+	"DEBUG": slog.LevelDebug,
+	"INFO":  slog.LevelInfo,
+	"WARN":  slog.LevelWarn,
+	"ERROR": slog.LevelError,
+}
+
+func init() {
+	captureTestLogs = func(t testing.TB) func() {
+		logHandlerMutex.Lock()
+		routed := logHandler != nil
+		logHandlerMutex.Unlock()
+		if routed {
+			return func() {}
+		}
+
+		return LogToTest(t)
+	}
+}
+
+// SetLogger routes the package's log output (after filtering by LogLevel)
+// through the logger, instead of stderr. Passing nil restores stderr.
+func SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		setLogHandler(nil)
+		return
+	}
+
+	setLogHandler(slogHandler(logger))
+}
+
+func slogHandler(logger *slog.Logger) func(level string, message string) {
+	return func(level string, message string) {
+		logger.Log(context.Background(), slogLevels[level], message)
+	}
+}
+
+// NewTestLogHandler returns a slog handler writing each record to the test's
+// log, so that it is interleaved with the test's own output and only shown
+// if the test fails (or with -v). By default all levels are written, leaving
+// filtering to LogLevel.
+func NewTestLogHandler(t testing.TB, opts *slog.HandlerOptions) slog.Handler {
+	return newTestLogHandler(&testLogWriter{t: t}, opts)
+}
+
+func newTestLogHandler(w *testLogWriter, opts *slog.HandlerOptions) slog.Handler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{Level: levelTrace}
+	}
+	options := *opts
+	replace := options.ReplaceAttr
+	options.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		// The test log is already in order, so times are noise
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		if replace != nil {
+			return replace(groups, a)
+		}
+		return a
+	}
+
+	return slog.NewTextHandler(w, &options)
+}
+
+// LogToTest routes the package's log output into the test's log until the
+// returned function is called, or the test completes. VerifyProvider does so
+// automatically, unless SetLogger has been called.
+func LogToTest(t testing.TB) func() {
+	writer := &testLogWriter{t: t}
+	previous := setLogHandler(slogHandler(slog.New(newTestLogHandler(writer, nil))))
+
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			writer.close()
+			setLogHandler(previous)
+		})
+	}
+	t.Cleanup(restore)
+
+	return restore
+}
+
+// testLogWriter writes each line to the test's log until closed, after which
+// (e.g. from a background goroutine outliving the test) it writes to stderr
+type testLogWriter struct {
+	mu     sync.Mutex
+	t      testing.TB
+	closed bool
+}
+
+func (w *testLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.Stderr.Write(p)
+	}
+	w.t.Log(strings.TrimRight(string(p), "\n"))
+
+	return len(p), nil
+}
+
+func (w *testLogWriter) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+}
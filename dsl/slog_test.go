@@ -0,0 +1,83 @@
+//go:build go1.22
+// +build go1.22
+
+package dsl
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// recordingT records the logs and cleanups of a test
+type recordingT struct {
+	testing.TB
+	logs     []string
+	cleanups []func()
+}
+
+func (t *recordingT) Log(args ...interface{}) {
+	for _, arg := range args {
+		t.logs = append(t.logs, arg.(string))
+	}
+}
+
+func (t *recordingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	logOutput{}.Write([]byte("2020/01/02 03:04:05 [WARN] pact file is old\n[TRACE] ignored\n"))
+
+	if !strings.Contains(buf.String(), `level=WARN msg="pact file is old"`) {
+		t.Fatalf("expected the log to be routed through slog, got %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "ignored") {
+		t.Fatalf("expected the logger's level to apply, got %s", buf.String())
+	}
+}
+
+func TestLogToTest(t *testing.T) {
+	rt := &recordingT{}
+	restore := LogToTest(rt)
+
+	logOutput{}.Write([]byte("[DEBUG] pact setup logging\n"))
+	if len(rt.logs) != 1 || rt.logs[0] != `level=DEBUG msg="pact setup logging"` {
+		t.Fatalf("expected the log to be written to the test, got %v", rt.logs)
+	}
+
+	if len(rt.cleanups) != 1 {
+		t.Fatal("expected the output to be restored when the test completes")
+	}
+	restore()
+	rt.cleanups[0]()
+
+	if setLogHandler(nil) != nil {
+		t.Fatal("expected the previous output to be restored")
+	}
+}
+
+func TestCaptureTestLogs(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer SetLogger(nil)
+
+	rt := &recordingT{}
+	captureTestLogs(rt)()
+	if len(rt.cleanups) != 0 {
+		t.Fatal("expected logs routed by SetLogger to be left alone")
+	}
+
+	SetLogger(nil)
+	restore := captureTestLogs(rt)
+	defer restore()
+	logOutput{}.Write([]byte("[INFO] verifying\n"))
+	if len(rt.logs) != 1 {
+		t.Fatalf("expected the log to be written to the test, got %v", rt.logs)
+	}
+}
@@ -0,0 +1,132 @@
+package dsl
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pact-foundation/pact-go/proxy"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// defaultStateSetupRetryDelay is the wait between provider state setup
+// attempts unless VerifyRequest.StateSetupRetryDelay is set
+const defaultStateSetupRetryDelay = time.Second
+
+// retryStateSetup calls setup until it succeeds, at most retries times more
+// after the first failure, returning the last error
+func retryStateSetup(state string, retries int, delay time.Duration, setup func() error) error {
+	if delay == 0 {
+		delay = defaultStateSetupRetryDelay
+	}
+
+	err := setup()
+	for attempt := 1; err != nil && attempt <= retries; attempt++ {
+		log.Printf("[WARN] provider state setup for '%s' failed, retrying (%d of %d) in %s: %v", state, attempt, retries, delay, err)
+		time.Sleep(delay)
+		err = setup()
+	}
+
+	return err
+}
+
+// retryingStateHandlers wraps each state handler to retry failures
+func retryingStateHandlers(handlers types.StateHandlers, retries int, delay time.Duration) types.StateHandlers {
+	if retries <= 0 {
+		return handlers
+	}
+
+	retrying := make(types.StateHandlers, len(handlers))
+	for state, handler := range handlers {
+		state, handler := state, handler
+		retrying[state] = func() error {
+			return retryStateSetup(state, retries, delay, func() error { return handler() })
+		}
+	}
+
+	return retrying
+}
+
+// retryingStateHandlersWithParams wraps each state handler with parameters to
+// retry failures
+func retryingStateHandlersWithParams(handlers types.StateHandlersWithParams, retries int, delay time.Duration) types.StateHandlersWithParams {
+	if retries <= 0 {
+		return handlers
+	}
+
+	retrying := make(types.StateHandlersWithParams, len(handlers))
+	for state, handler := range handlers {
+		state, handler := state, handler
+		retrying[state] = func(params map[string]interface{}) error {
+			return retryStateSetup(state, retries, delay, func() error { return handler(params) })
+		}
+	}
+
+	return retrying
+}
+
+// stateSetupURLMiddleware forwards provider state setup requests to the
+// provider's own setup URL, retrying failed requests and 5xx responses
+func stateSetupURLMiddleware(setupURL string, retries int, delay time.Duration) proxy.Middleware {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != providerStatesSetupPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			var status int
+			var header http.Header
+			var content []byte
+			err = retryStateSetup(setupURL, retries, delay, func() error {
+				req, err := http.NewRequest(r.Method, setupURL, bytes.NewReader(body))
+				if err != nil {
+					return err
+				}
+				req.Header = cloneHeader(r.Header)
+
+				res, err := client.Do(req)
+				if err != nil {
+					return err
+				}
+				defer res.Body.Close()
+
+				status, header = res.StatusCode, res.Header
+				if content, err = ioutil.ReadAll(res.Body); err != nil {
+					return err
+				}
+				if status >= 500 {
+					return fmt.Errorf("status %d: %s", status, strings.TrimSpace(string(content)))
+				}
+
+				return nil
+			})
+
+			if status == 0 {
+				log.Printf("[ERROR] provider state setup failed: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(err.Error())) // nolint:errcheck
+				return
+			}
+
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(status)
+			w.Write(content) // nolint:errcheck
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package dsl
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestRetryingStateHandlers(t *testing.T) {
+	calls, failures := 0, 2
+	handlers := retryingStateHandlers(types.StateHandlers{
+		"user 1 exists": func() error {
+			calls++
+			if calls <= failures {
+				return errors.New("relation \"users\" does not exist")
+			}
+			return nil
+		},
+	}, 2, time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", providerStatesSetupPath, strings.NewReader(`{"states": ["user 1 exists"]}`))
+	stateHandlerMiddleware(handlers)(dummyHandler("X-Dummy-Handler")).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || calls != 3 {
+		t.Fatalf("expected the state to be set up on the third attempt, got status %d after %d calls", rr.Code, calls)
+	}
+
+	calls, failures = 0, 3
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", providerStatesSetupPath, strings.NewReader(`{"states": ["user 1 exists"]}`))
+	stateHandlerMiddleware(handlers)(dummyHandler("X-Dummy-Handler")).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError || calls != 3 {
+		t.Fatalf("expected the state setup to fail after 3 attempts, got status %d after %d calls", rr.Code, calls)
+	}
+}
+
+func TestStateSetupURLMiddleware(t *testing.T) {
+	calls, failures := 0, 1
+	setup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(body), "user 1 exists") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if calls <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id": 1}`)) // nolint:errcheck
+	}))
+	defer setup.Close()
+
+	mw := stateSetupURLMiddleware(setup.URL, 1, time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", providerStatesSetupPath, strings.NewReader(`{"state": "user 1 exists"}`))
+	mw(dummyHandler("X-Dummy-Handler")).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || rr.Body.String() != `{"id": 1}` || calls != 2 {
+		t.Fatalf("expected the state setup to be retried, got status %d after %d calls", rr.Code, calls)
+	}
+
+	calls, failures = 0, 2
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", providerStatesSetupPath, strings.NewReader(`{"state": "user 1 exists"}`))
+	mw(dummyHandler("X-Dummy-Handler")).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable || calls != 2 {
+		t.Fatalf("expected the last response after the retries, got status %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	mw(dummyHandler("X-Dummy-Handler")).ServeHTTP(rr, httptest.NewRequest("GET", "/users/1", nil))
+	if rr.Header().Get("X-Dummy-Handler") != "true" {
+		t.Fatal("expected other requests to be passed through")
+	}
+}
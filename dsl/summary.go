@@ -0,0 +1,28 @@
+package dsl
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// writeVerificationSummary writes the summary of a verification to the file,
+// if given, returning the verification error
+func writeVerificationSummary(file string, res []types.ProviderVerifierResponse, err error) error {
+	if file == "" {
+		return err
+	}
+
+	result := types.NewVerificationResult(res, err)
+	log.Println("[DEBUG] writing verification summary to", file, "status:", result.Status)
+
+	if writeErr := result.WriteFile(file); writeErr != nil {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("unable to write verification summary: %v", writeErr)
+	}
+
+	return err
+}
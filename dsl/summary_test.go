@@ -0,0 +1,42 @@
+package dsl
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestWriteVerificationSummary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-go-summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "summary.json")
+
+	verifyErr := errors.New("verification failed")
+	if err := writeVerificationSummary(file, nil, verifyErr); err != verifyErr {
+		t.Fatalf("expected the verification error to be returned, got %v", err)
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("expected a summary file to be written: %v", err)
+	}
+	if !strings.Contains(string(content), `"status": "failed"`) {
+		t.Fatalf("unexpected summary %s", content)
+	}
+
+	if err := writeVerificationSummary("", []types.ProviderVerifierResponse{}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := writeVerificationSummary(filepath.Join(dir, "missing", "summary.json"), nil, nil); err == nil {
+		t.Fatal("expected an error when the summary can't be written")
+	}
+}
@@ -0,0 +1,221 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+// SynchronousMessageConsumer is a consumer of request/reply messages. It is
+// given the example reply, e.g. to stub its transport with, and must send
+// its request and be able to handle the reply, returning the contents of the
+// request it sent.
+type SynchronousMessageConsumer func(reply Message) (request interface{}, err error)
+
+// SynchronousMessage is a request/reply message over a transport other than
+// HTTP e.g. a gRPC unary call, or RPC over a queue. It is written to version
+// 4 pacts as a "Synchronous/Messages" interaction.
+type SynchronousMessage struct {
+	// Description to be written into the Pact file
+	Description string
+
+	// Provider states to be written into the Pact file
+	States []State
+
+	// Request the consumer sends
+	Request MessageContents
+
+	// Response is the reply the consumer expects
+	Response MessageContents
+}
+
+// MessageContents are the contents and metadata of the request or reply of a
+// synchronous message, either of which may contain matchers
+type MessageContents struct {
+	Content  interface{}
+	Metadata MapMatcher
+}
+
+// Given specifies a provider state. Optional.
+func (m *SynchronousMessage) Given(state string) *SynchronousMessage {
+	m.States = []State{State{Name: state}}
+
+	return m
+}
+
+// UponReceiving specifies the name of the message, which the provider
+// receives as the request
+func (m *SynchronousMessage) UponReceiving(description string) *SynchronousMessage {
+	m.Description = description
+
+	return m
+}
+
+// WithRequest specifies the request the consumer sends
+func (m *SynchronousMessage) WithRequest(request MessageContents) *SynchronousMessage {
+	m.Request = request
+
+	return m
+}
+
+// WillRespondWith specifies the reply the provider sends to the request
+func (m *SynchronousMessage) WillRespondWith(response MessageContents) *SynchronousMessage {
+	m.Response = response
+
+	return m
+}
+
+// AddSynchronousMessage returns a new synchronous message to build. It is
+// added to the pact once verified by VerifySynchronousMessage.
+func (p *NativeMessagePact) AddSynchronousMessage() *SynchronousMessage {
+	return &SynchronousMessage{}
+}
+
+// VerifySynchronousMessageRaw sends the example reply of the message to the
+// handler as decoded JSON, and matches the request the handler sent with the
+// expected request, adding the message to the pact if it matches. The
+// metadata of the request isn't matched.
+func (p *NativeMessagePact) VerifySynchronousMessageRaw(message *SynchronousMessage, handler SynchronousMessageConsumer) error {
+	log.Println("[DEBUG] native message pact: verify synchronous message", message.Description)
+	if message.Description == "" {
+		return fmt.Errorf("the synchronous message has no description, see UponReceiving")
+	}
+
+	expected, err := p.synchronousMessagePact(message)
+	if err != nil {
+		return fmt.Errorf("unable to convert synchronous message '%s' to a valid JSON representation: %v", message.Description, err)
+	}
+
+	var reply interface{}
+	if len(expected.MessageResponses) > 0 {
+		if err = json.Unmarshal(v4Contents(expected.MessageResponses[0].Contents), &reply); err != nil {
+			return err
+		}
+	}
+	request, err := handler(Message{
+		Content:     reply,
+		States:      message.States,
+		Description: message.Description,
+		Metadata:    message.Response.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	mismatches, err := messageMismatches(messageContents(message.Description, *expected.MessageRequest), request, nil, false)
+	if err != nil {
+		return fmt.Errorf("unable to match the request of synchronous message '%s': %v", message.Description, err)
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("the request of synchronous message '%s' doesn't match the pact:\n%s", message.Description, strings.Join(mismatches, "\n"))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for n, existing := range p.synchronous {
+		if existing.Description == message.Description {
+			p.synchronous[n] = message
+			return nil
+		}
+	}
+	p.synchronous = append(p.synchronous, message)
+
+	return nil
+}
+
+// VerifySynchronousMessage is a test convenience function for
+// VerifySynchronousMessageRaw, accepting an instance of `*testing.T`
+func (p *NativeMessagePact) VerifySynchronousMessage(t *testing.T, message *SynchronousMessage, handler SynchronousMessageConsumer) error {
+	err := p.VerifySynchronousMessageRaw(message, handler)
+	if err != nil {
+		t.Errorf("VerifySynchronousMessage failed: %v", err)
+	}
+
+	return err
+}
+
+// synchronousMessagePact returns the message as it is written to the pact,
+// so that the request is matched as the provider verifier matches replies
+func (p *NativeMessagePact) synchronousMessagePact(message *SynchronousMessage) (*pactfile.Interaction, error) {
+	content, err := json.Marshal(map[string]interface{}{
+		"consumer":     map[string]string{"name": p.Consumer},
+		"provider":     map[string]string{"name": p.Provider},
+		"interactions": []interface{}{serialiseSynchronousMessage(message)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content, err = pactfile.ConvertSpecification(content, 4); err != nil {
+		return nil, err
+	}
+	parsed, err := pactfile.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed.Interactions[0], nil
+}
+
+// serialiseSynchronousMessage writes the message with the examples of its
+// request and reply, and the matching rules of their matchers
+func serialiseSynchronousMessage(m *SynchronousMessage) map[string]interface{} {
+	message := map[string]interface{}{
+		"type":        "Synchronous/Messages",
+		"description": m.Description,
+		"request":     serialiseMessageContents(m.Request),
+		"response":    []interface{}{serialiseMessageContents(m.Response)},
+	}
+	if len(m.States) > 0 {
+		message["providerStates"] = m.States
+	}
+
+	return message
+}
+
+func serialiseMessageContents(c MessageContents) map[string]interface{} {
+	rules := MatchingRules{}
+	contents := map[string]interface{}{
+		"contents": serialiseMatcher(BodyPath(), c.Content, rules),
+	}
+	if len(c.Metadata) > 0 {
+		metadata := make(map[string]interface{}, len(c.Metadata))
+		for name, value := range c.Metadata {
+			metadata[name] = serialiseMatcher(MetadataPath(name), value, rules)
+		}
+		contents["metadata"] = metadata
+	}
+	if len(rules) > 0 {
+		contents["matchingRules"] = rules
+	}
+
+	return contents
+}
+
+// messageContents returns the request or reply of a synchronous message in
+// the form of a message, to be matched likewise
+func messageContents(description string, contents pactfile.MessageContents) pactfile.Message {
+	return pactfile.Message{
+		Description:   description,
+		Contents:      v4Contents(contents.Contents),
+		Metadata:      contents.Metadata,
+		MatchingRules: contents.MatchingRules,
+	}
+}
+
+// v4Contents unwraps contents written in the version 4 form, with their
+// content type and encoding
+func v4Contents(raw json.RawMessage) json.RawMessage {
+	var wrapped struct {
+		Content     json.RawMessage `json:"content"`
+		ContentType string          `json:"contentType"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err != nil || wrapped.ContentType == "" {
+		return raw
+	}
+
+	return wrapped.Content
+}
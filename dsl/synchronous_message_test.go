@@ -0,0 +1,146 @@
+package dsl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+)
+
+// priceRequest builds a synchronous message pricing an order
+func priceRequest(pact *NativeMessagePact) *SynchronousMessage {
+	return pact.AddSynchronousMessage().
+		Given("order 1 exists").
+		UponReceiving("a request to price an order").
+		WithRequest(MessageContents{
+			Content:  StructMatcher{"id": Like(1)},
+			Metadata: MapMatcher{"contentType": String("application/json")},
+		}).
+		WillRespondWith(MessageContents{
+			Content:  StructMatcher{"id": Like(1), "total": Like(10.5)},
+			Metadata: MapMatcher{"status": Term("OK", `^(OK|CACHED)$`)},
+		})
+}
+
+func TestNativeMessagePact_VerifySynchronousMessage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-message-pact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pact := NewNativeMessagePact("order-consumer", "pricing-service")
+	pact.PactDir = dir
+
+	var reply Message
+	err = pact.VerifySynchronousMessageRaw(priceRequest(pact), func(m Message) (interface{}, error) {
+		reply = m
+		return map[string]interface{}{"id": 7}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, ok := reply.Content.(map[string]interface{}); !ok || content["total"] != 10.5 || reply.States[0].Name != "order 1 exists" {
+		t.Fatalf("expected the handler to be given the example reply, got %+v", reply)
+	}
+
+	err = pact.VerifySynchronousMessageRaw(priceRequest(pact), func(m Message) (interface{}, error) {
+		return map[string]interface{}{"id": "7"}, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), `the request of synchronous message 'a request to price an order' doesn't match the pact:
+* expected a number (1) but got a string ("7") at $.id`) {
+		t.Fatalf("expected the request to fail to match, got %v", err)
+	}
+
+	if err = pact.WritePact(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := pactfile.Read(filepath.Join(dir, "order-consumer-pricing-service.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written.SpecificationVersion() != "4.0" || len(written.Interactions) != 1 {
+		t.Fatalf("expected a version 4 pact with the synchronous message, got %+v", written)
+	}
+	message := written.Interactions[0]
+	if !message.IsSynchronousMessage() || message.MessageRequest == nil || len(message.MessageResponses) != 1 {
+		t.Fatalf("unexpected synchronous message %+v", message)
+	}
+	if rules := compactJSON(message.MessageResponses[0].MatchingRules); !strings.Contains(rules, `"metadata":{"status":{"combine":"AND","matchers":[{"match":"regex","regex":"^(OK|CACHED)$"}]}}`) {
+		t.Fatalf("expected the reply metadata to be matched by regex, got %s", rules)
+	}
+}
+
+func TestPact_VerifyMessageProviderNativeSynchronous(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	consumer := NewNativeMessagePact("order-consumer", "pricing-service")
+	consumer.PactDir = dir
+	err = consumer.VerifySynchronousMessageRaw(priceRequest(consumer), func(m Message) (interface{}, error) {
+		return map[string]interface{}{"id": 1}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := consumer.AddMessage().
+		ExpectsToReceive("an order priced event").
+		WithContent(StructMatcher{"id": Like(1)})
+	if err = consumer.VerifyMessageRaw(message, func(Message) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err = consumer.WritePact(); err != nil {
+		t.Fatal(err)
+	}
+
+	var requested interface{}
+	request := VerifyMessageRequest{
+		PactURLs: []string{filepath.Join(dir, "order-consumer-pricing-service.json")},
+		MessageProducers: MessageProducers{
+			"a request to price an order": func(m Message) (interface{}, map[string]interface{}, error) {
+				requested = m.Content
+				return map[string]interface{}{"id": 1, "total": 99.0}, map[string]interface{}{"status": "CACHED"}, nil
+			},
+		},
+		MessageHandlers: MessageHandlers{
+			"an order priced event": func(m Message) (interface{}, error) {
+				return map[string]interface{}{"id": 1}, nil
+			},
+		},
+	}
+
+	pact := &Pact{Provider: "pricing-service"}
+	res, err := pact.VerifyMessageProviderNativeRaw(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 1 || len(res[0].Examples) != 2 {
+		t.Fatalf("expected both messages to be verified, got %+v", res)
+	}
+	if content, ok := requested.(map[string]interface{}); !ok || content["id"] != float64(1) {
+		t.Fatalf("expected the producer to be given the example request, got %v", requested)
+	}
+
+	request.MessageProducers["a request to price an order"] = func(m Message) (interface{}, map[string]interface{}, error) {
+		return map[string]interface{}{"id": 1}, map[string]interface{}{"status": "FAILED"}, nil
+	}
+	res, err = pact.VerifyMessageProviderNativeRaw(request)
+	if err == nil || err.Error() != "1 messages failed verification" {
+		t.Fatalf("expected the reply to fail, got %v", err)
+	}
+	failure := res[0].Examples[1].Exception.Message
+	for _, want := range []string{
+		`* Could not find key "total" at $.total`,
+		`metadata status: expected a value matching "^(OK|CACHED)$"`,
+	} {
+		if !strings.Contains(failure, want) {
+			t.Fatalf("expected %q in %q", want, failure)
+		}
+	}
+}
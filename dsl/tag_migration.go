@@ -0,0 +1,210 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// TagMigration describes how a Broker's version tags map to the branches
+// and environments that replace them
+type TagMigration struct {
+	// Pacticipants to migrate. Defaults to all of the broker's pacticipants.
+	Pacticipants []string
+
+	// Environments maps tags to the environments they represent, e.g.
+	// "prod" to "production". Missing environments are created. The latest
+	// version with each tag is recorded as deployed to the environment, or
+	// with Released, every version with the tag as released to it.
+	Environments map[string]string
+
+	// ProductionEnvironments lists the created environments that are
+	// production environments
+	ProductionEnvironments []string
+
+	// Released records versions as released to, rather than deployed to,
+	// their environments
+	Released bool
+
+	// Branches maps tags to the names of their branches, e.g. "master" to
+	// "main". Other tags not in Environments are branches of the same name.
+	Branches map[string]string
+
+	// Ignore lists tags that are neither branches nor environments
+	Ignore []string
+
+	// DryRun reports the changes without making them
+	DryRun bool
+}
+
+// TagMigrationChange is a branch or environment record made for a tag
+type TagMigrationChange struct {
+	Pacticipant string
+	Version     string
+	Tag         string
+
+	// Branch the version was added to, or the Environment it was recorded
+	// as deployed (or Released) to
+	Branch      string
+	Environment string
+	Released    bool
+}
+
+func (c TagMigrationChange) String() string {
+	switch {
+	case c.Branch != "":
+		return fmt.Sprintf("%s version %s: tag '%s' -> branch '%s'", c.Pacticipant, c.Version, c.Tag, c.Branch)
+	case c.Released:
+		return fmt.Sprintf("%s version %s: tag '%s' -> released to '%s'", c.Pacticipant, c.Version, c.Tag, c.Environment)
+	default:
+		return fmt.Sprintf("%s version %s: tag '%s' -> deployed to '%s'", c.Pacticipant, c.Version, c.Tag, c.Environment)
+	}
+}
+
+// MigrateTags reads the tags of the pacticipants' versions, and records each
+// as a branch version or a deployment (or release) to an environment, so
+// that the broker may be used with branches and environments instead of
+// tags. Branch versions are only added, so a migration may be run again.
+// The changes are returned even if the migration stops with an error.
+func (b *Broker) MigrateTags(ctx context.Context, migration TagMigration) ([]TagMigrationChange, error) {
+	pacticipants := migration.Pacticipants
+	if len(pacticipants) == 0 {
+		it := b.Pacticipants(ctx)
+		for it.Next() {
+			var pacticipant struct {
+				Name string `json:"name"`
+			}
+			if err := it.Decode(&pacticipant); err != nil {
+				return nil, err
+			}
+			pacticipants = append(pacticipants, pacticipant.Name)
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []TagMigrationChange
+	environments := map[string]bool{}
+	for _, pacticipant := range pacticipants {
+		planned, err := b.planTagMigration(ctx, migration, pacticipant)
+		if err != nil {
+			return changes, err
+		}
+
+		for _, change := range planned {
+			if !migration.DryRun {
+				if err = b.applyTagMigration(ctx, migration, environments, change); err != nil {
+					return changes, fmt.Errorf("unable to migrate %s: %v", change, err)
+				}
+			}
+			log.Println("[INFO] migrated", change)
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+// planTagMigration lists the changes for the pacticipant's version tags.
+// Versions are listed by the broker newest first.
+func (b *Broker) planTagMigration(ctx context.Context, migration TagMigration, pacticipant string) ([]TagMigrationChange, error) {
+	ignored := map[string]bool{}
+	for _, tag := range migration.Ignore {
+		ignored[tag] = true
+	}
+	deployed := map[string]bool{}
+
+	var changes []TagMigrationChange
+	it := b.Versions(ctx, pacticipant)
+	for it.Next() {
+		var version struct {
+			Number   string `json:"number"`
+			Embedded struct {
+				Tags []struct {
+					Name string `json:"name"`
+				} `json:"tags"`
+			} `json:"_embedded"`
+		}
+		if err := it.Decode(&version); err != nil {
+			return nil, err
+		}
+
+		for _, tag := range version.Embedded.Tags {
+			change := TagMigrationChange{Pacticipant: pacticipant, Version: version.Number, Tag: tag.Name}
+			if environment, ok := migration.Environments[tag.Name]; ok {
+				if !migration.Released && deployed[tag.Name] {
+					continue
+				}
+				deployed[tag.Name] = true
+				change.Environment = environment
+				change.Released = migration.Released
+			} else if ignored[tag.Name] {
+				continue
+			} else if branch, ok := migration.Branches[tag.Name]; ok {
+				change.Branch = branch
+			} else {
+				change.Branch = tag.Name
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, it.Err()
+}
+
+// applyTagMigration makes the change, creating its environment unless it is
+// already in environments
+func (b *Broker) applyTagMigration(ctx context.Context, migration TagMigration, environments map[string]bool, change TagMigrationChange) error {
+	if change.Branch != "" {
+		return b.send(ctx, "PUT", b.url("pacticipants", change.Pacticipant, "branches", change.Branch, "versions", change.Version), struct{}{})
+	}
+
+	if !environments[change.Environment] {
+		if err := b.environment(ctx, migration, change.Environment); err != nil {
+			return err
+		}
+		environments[change.Environment] = true
+	}
+	if change.Released {
+		return b.RecordRelease(ctx, change.Pacticipant, change.Version, change.Environment)
+	}
+
+	return b.RecordDeployment(ctx, change.Pacticipant, change.Version, change.Environment)
+}
+
+// environment creates the named environment if it doesn't exist
+func (b *Broker) environment(ctx context.Context, migration TagMigration, name string) error {
+	environments, err := b.Environments(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range environments {
+		if e.Name == name {
+			return nil
+		}
+	}
+
+	created := Environment{Name: name}
+	for _, production := range migration.ProductionEnvironments {
+		created.Production = created.Production || production == name
+	}
+	if _, err = b.CreateEnvironment(ctx, created); err != nil {
+		return err
+	}
+	log.Printf("[INFO] created the environment '%s'", name)
+
+	return nil
+}
+
+// send sends a JSON document to the broker
+func (b *Broker) send(ctx context.Context, method string, u string, document interface{}) error {
+	body, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = brokerRequest(ctx, method, u, body, b.auth())
+
+	return err
+}
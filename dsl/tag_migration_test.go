@@ -0,0 +1,101 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func setupMigrationBroker(requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/pacticipants":
+			fmt.Fprint(w, `{"_embedded":{"pacticipants":[{"name":"billy"}]}}`)
+		case r.Method == "GET" && r.URL.Path == "/pacticipants/billy/versions":
+			fmt.Fprint(w, `{"_embedded":{"versions":[
+				{"number":"3.0.0","_embedded":{"tags":[{"name":"prod"},{"name":"master"},{"name":"feat-x"}]}},
+				{"number":"2.0.0","_embedded":{"tags":[{"name":"prod"},{"name":"master"}]}}
+			]}}`)
+		case r.Method == "GET" && r.URL.Path == "/environments":
+			fmt.Fprint(w, `{"_embedded":{"environments":[{"uuid":"s1","name":"staging"}]}}`)
+		case r.Method == "GET" && r.URL.Path == "/pacticipants/billy/versions/3.0.0":
+			fmt.Fprintf(w, `{"_links":{
+				"pb:record-deployment":[{"name":"staging","href":"http://%[1]s/pacticipants/billy/versions/3.0.0/deployed-versions/environment/s1"},{"name":"production","href":"http://%[1]s/pacticipants/billy/versions/3.0.0/deployed-versions/environment/p1"}],
+				"pb:record-release":[{"name":"production","href":"http://%[1]s/pacticipants/billy/versions/3.0.0/released-versions/environment/p1"}]
+			}}`, r.Host)
+		default:
+			body, _ := ioutil.ReadAll(r.Body)
+			*requests = append(*requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+			w.WriteHeader(http.StatusCreated)
+			if r.URL.Path == "/environments" {
+				fmt.Fprint(w, `{"uuid":"p1","name":"production"}`)
+			}
+		}
+	}))
+}
+
+func TestBroker_MigrateTags(t *testing.T) {
+	var requests []string
+	s := setupMigrationBroker(&requests)
+	defer s.Close()
+
+	migration := TagMigration{
+		Environments:           map[string]string{"prod": "production", "test": "staging"},
+		ProductionEnvironments: []string{"production"},
+		Branches:               map[string]string{"master": "main"},
+		Ignore:                 []string{"feat-x"},
+	}
+	changes, err := (&Broker{URL: s.URL}).MigrateTags(context.Background(), migration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"billy version 3.0.0: tag 'prod' -> deployed to 'production'",
+		"billy version 3.0.0: tag 'master' -> branch 'main'",
+		"billy version 2.0.0: tag 'master' -> branch 'main'",
+	}
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want the changes %v, got %v", want, got)
+	}
+
+	wantRequests := []string{
+		`POST /environments {"name":"production","production":true}`,
+		`POST /pacticipants/billy/versions/3.0.0/deployed-versions/environment/p1 {}`,
+		`PUT /pacticipants/billy/branches/main/versions/3.0.0 {}`,
+		`PUT /pacticipants/billy/branches/main/versions/2.0.0 {}`,
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Fatalf("want the requests %v, got %v", wantRequests, requests)
+	}
+}
+
+func TestBroker_MigrateTagsReleasedDryRun(t *testing.T) {
+	var requests []string
+	s := setupMigrationBroker(&requests)
+	defer s.Close()
+
+	changes, err := (&Broker{URL: s.URL}).MigrateTags(context.Background(), TagMigration{
+		Pacticipants: []string{"billy"},
+		Environments: map[string]string{"prod": "production"},
+		Released:     true,
+		DryRun:       true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 5 || !changes[0].Released || changes[2].Branch != "feat-x" || changes[3].Version != "2.0.0" || !changes[3].Released {
+		t.Fatalf("expected every prod version to be released, and the other tags to be branches, got %v", changes)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("expected no changes to be made, got %v", requests)
+	}
+}
@@ -0,0 +1,46 @@
+package dsl
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// providerTLSConfig builds the TLS configuration used to communicate with
+// the Provider API, adding any client certificate for mutual TLS to the
+// custom TLS configuration.
+func providerTLSConfig(request types.VerifyRequest) (*tls.Config, error) {
+	if (request.ClientCertFile == "") != (request.ClientKeyFile == "") {
+		return nil, errors.New("both 'ClientCertFile' and 'ClientKeyFile' must be supplied if one given")
+	}
+	if request.ClientCertFile != "" && request.ClientCertificate != nil {
+		return nil, errors.New("only one of 'ClientCertFile' and 'ClientCertificate' may be given")
+	}
+	if request.ClientCertFile == "" && request.ClientCertificate == nil {
+		return request.CustomTLSConfig, nil
+	}
+
+	config := &tls.Config{}
+	if request.CustomTLSConfig != nil {
+		config = request.CustomTLSConfig.Clone()
+	}
+
+	if request.ClientCertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(request.ClientCertFile, request.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if request.ClientCertificate != nil {
+		source := request.ClientCertificate
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return source()
+		}
+	}
+
+	return config, nil
+}
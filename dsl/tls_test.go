@@ -0,0 +1,125 @@
+package dsl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func generateClientCertificate(t *testing.T) (certPEM []byte, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "consumer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestProviderTLSConfig_MutualTLS(t *testing.T) {
+	certPEM, keyPEM := generateClientCertificate(t)
+
+	var clients []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clients = append(clients, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "pact-go-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	ioutil.WriteFile(certFile, certPEM, 0600) // nolint:errcheck
+	ioutil.WriteFile(keyFile, keyPEM, 0600)   // nolint:errcheck
+
+	requests := map[string]types.VerifyRequest{
+		"files": {ClientCertFile: certFile, ClientKeyFile: keyFile},
+		"in memory": {ClientCertificate: func() (*tls.Certificate, error) {
+			certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+			return &certificate, err
+		}},
+	}
+
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			request.CustomTLSConfig = &tls.Config{InsecureSkipVerify: true}
+			config, err := providerTLSConfig(request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !config.InsecureSkipVerify {
+				t.Fatal("expected the custom TLS config to be preserved")
+			}
+
+			clients = nil
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+			res, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res.Body.Close()
+
+			if len(clients) != 1 || clients[0] != "consumer" {
+				t.Fatalf("expected the client certificate to be presented, got %v", clients)
+			}
+		})
+	}
+}
+
+func TestProviderTLSConfig_Invalid(t *testing.T) {
+	source := func() (*tls.Certificate, error) { return nil, nil }
+	requests := map[string]types.VerifyRequest{
+		"missing key":  {ClientCertFile: "client.crt"},
+		"missing cert": {ClientKeyFile: "client.key"},
+		"both sources": {ClientCertFile: "client.crt", ClientKeyFile: "client.key", ClientCertificate: source},
+		"bad files":    {ClientCertFile: "does-not-exist.crt", ClientKeyFile: "does-not-exist.key"},
+	}
+
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := providerTLSConfig(request); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	custom := &tls.Config{}
+	if config, _ := providerTLSConfig(types.VerifyRequest{CustomTLSConfig: custom}); config != custom {
+		t.Fatal("expected the custom TLS config to be used as-is without a client certificate")
+	}
+}
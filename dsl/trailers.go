@@ -0,0 +1,481 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// trailerMismatches compares the actual trailers with the expected ones
+func trailerMismatches(expected MapMatcher, actual http.Header) []types.Mismatch {
+	var mismatches []types.Mismatch
+	for _, name := range sortedKeys(expected) {
+		values, ok := actual[http.CanonicalHeaderKey(name)]
+		value := strings.Join(values, ", ")
+		if !ok {
+			mismatches = append(mismatches, types.TrailerMismatch{Key: name, Expected: fmt.Sprint(exampleOf(expected[name]))})
+			continue
+		}
+		if diffs := matchString(expected[name], value); len(diffs) > 0 {
+			mismatches = append(mismatches, types.TrailerMismatch{Key: name, Expected: fmt.Sprint(exampleOf(expected[name])), Actual: value, Rule: diffs[0].Rule})
+		}
+	}
+
+	return mismatches
+}
+
+// trailerExamples are the example values of the trailers
+func trailerExamples(trailers MapMatcher) http.Header {
+	examples := make(http.Header, len(trailers))
+	for name, value := range trailers {
+		examples.Set(name, fmt.Sprint(exampleOf(value)))
+	}
+
+	return examples
+}
+
+// writeWithTrailers writes a response, announcing the trailers in the
+// Trailer header and sending them after the body
+func writeWithTrailers(w http.ResponseWriter, header http.Header, status int, body []byte, trailers http.Header) {
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	for name := range trailers {
+		w.Header().Add("Trailer", name)
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	w.Write(body) // nolint:errcheck
+
+	for name, values := range trailers {
+		w.Header()[name] = values
+	}
+}
+
+// readTrailers reads the body of the request, after which its trailers are
+// known, replacing the body with the content read
+func readTrailers(r *http.Request) error {
+	if r.Body == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	return nil
+}
+
+// splitTrailers removes the trailers from the headers of a proxied response,
+// as announced by the Trailer header or prefixed with http.TrailerPrefix
+func splitTrailers(header http.Header) http.Header {
+	trailers := http.Header{}
+	for _, announced := range header["Trailer"] {
+		for _, name := range strings.Split(announced, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if values, ok := header[name]; ok {
+				trailers[name] = values
+				delete(header, name)
+			}
+		}
+	}
+	delete(header, "Trailer")
+
+	for name, values := range header {
+		if strings.HasPrefix(name, http.TrailerPrefix) {
+			trailers[http.CanonicalHeaderKey(strings.TrimPrefix(name, http.TrailerPrefix))] = values
+			delete(header, name)
+		}
+	}
+
+	return trailers
+}
+
+// serialiseTrailers writes the examples of the trailers, adding their rules
+func serialiseTrailers(trailers MapMatcher, rules MatchingRules) map[string]interface{} {
+	serialised := make(map[string]interface{}, len(trailers))
+	for name, value := range trailers {
+		serialised[name] = serialiseMatcher(TrailerPath(name), value, rules)
+	}
+
+	return serialised
+}
+
+// interactionTrailers are the request and response trailers of an
+// interaction
+type interactionTrailers struct {
+	description string
+	request     MapMatcher
+	response    MapMatcher
+}
+
+// trailerSides returns the trailers of the interaction, or nil if it has
+// none
+func (i *Interaction) trailerSides() *interactionTrailers {
+	if len(i.Request.Trailers) == 0 && len(i.Response.Trailers) == 0 {
+		return nil
+	}
+
+	return &interactionTrailers{description: i.Description, request: i.Request.Trailers, response: i.Response.Trailers}
+}
+
+// writeTrailers records the trailers of each interaction, keyed by
+// description, in the pact file. Their matching rules are written in the
+// form of the pact's other rules: flat for version 2 pacts, otherwise
+// nested by category.
+func writeTrailers(file string, trailers map[string]*interactionTrailers, nested bool) error {
+	if len(trailers) == 0 {
+		return nil
+	}
+
+	return rewritePactFile(file, func(interaction map[string]interface{}) {
+		description, _ := interaction["description"].(string)
+		t, ok := trailers[description]
+		if !ok {
+			return
+		}
+
+		for part, expected := range map[string]MapMatcher{"request": t.request, "response": t.response} {
+			message, _ := interaction[part].(map[string]interface{})
+			if message == nil || len(expected) == 0 {
+				continue
+			}
+
+			rules := MatchingRules{}
+			message["trailers"] = serialiseTrailers(expected, rules)
+			if len(rules) == 0 {
+				continue
+			}
+
+			existing, _ := message["matchingRules"].(map[string]interface{})
+			if existing == nil {
+				existing = make(map[string]interface{})
+			}
+			for name := range expected {
+				rule, ok := rules[TrailerPath(name).String()]
+				if !ok {
+					continue
+				}
+				if !nested {
+					existing[TrailerPath(name).String()] = rule
+					continue
+				}
+				category, _ := existing["trailer"].(map[string]interface{})
+				if category == nil {
+					category = make(map[string]interface{})
+					existing["trailer"] = category
+				}
+				category[name] = map[string]interface{}{"matchers": []interface{}{rule}, "combine": "AND"}
+			}
+			message["matchingRules"] = existing
+		}
+	})
+}
+
+// consumerTrailers checks the trailers of requests sent to the mock server,
+// and adds the example trailers to its responses, for interactions with
+// trailers. The Ruby mock service doesn't support trailers.
+type consumerTrailers struct {
+	mu sync.Mutex
+
+	// trailers are keyed by method and path
+	trailers map[string]*interactionTrailers
+	record   func(types.InteractionMismatches)
+}
+
+func newConsumerTrailers(record func(types.InteractionMismatches)) *consumerTrailers {
+	return &consumerTrailers{trailers: make(map[string]*interactionTrailers), record: record}
+}
+
+// register records the trailers of the interaction
+func (c *consumerTrailers) register(i *Interaction) {
+	t := i.trailerSides()
+	if t == nil {
+		return
+	}
+	path, ok := plainString(i.Request.Path)
+	if !ok {
+		log.Printf("[WARN] interaction '%s' has trailers but its path is not a plain string, its trailers will not be matched\n", i.Description)
+		return
+	}
+
+	c.mu.Lock()
+	c.trailers[negotiationKey(i.Request.Method, path)] = t
+	c.mu.Unlock()
+}
+
+// reset forgets all registered trailers
+func (c *consumerTrailers) reset() {
+	c.mu.Lock()
+	c.trailers = make(map[string]*interactionTrailers)
+	c.mu.Unlock()
+}
+
+func (c *consumerTrailers) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		t := c.trailers[negotiationKey(r.Method, r.URL.Path)]
+		c.mu.Unlock()
+
+		if r.Header.Get("X-Pact-Mock-Service") != "" || t == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := readTrailers(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if mismatches := trailerMismatches(t.request, r.Trailer); len(mismatches) > 0 {
+			log.Printf("[WARN] the trailers of %s %s don't match interaction '%s'\n", r.Method, r.URL.Path, t.description)
+			c.record(types.InteractionMismatches{Description: t.description, Mismatches: mismatches})
+			messages := make([]string, len(mismatches))
+			for n, mismatch := range mismatches {
+				messages[n] = mismatch.String()
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "Unexpected trailers", "mismatches": messages}) // nolint:errcheck
+			return
+		}
+		r.Trailer = nil
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		writeWithTrailers(w, recorder.header, recorder.status, recorder.body.Bytes(), trailerExamples(t.response))
+	})
+}
+
+// trailerInteraction is an interaction with trailers, and the outcome of
+// checking the provider's responses for it
+type trailerInteraction struct {
+	consumer    string
+	description string
+	request     http.Header
+	response    MapMatcher
+
+	// checked is set once a response was checked, and clean once a
+	// response's trailers matched
+	checked    bool
+	clean      bool
+	mismatches []types.Mismatch
+}
+
+// providerTrailers sends the example request trailers to the provider, and
+// checks the trailers of its responses, for interactions with trailers,
+// which the verifier doesn't support
+type providerTrailers struct {
+	mu sync.Mutex
+
+	// interactions are keyed by method and path
+	interactions map[string][]*trailerInteraction
+}
+
+// newProviderTrailers reads the interactions with trailers from the pact
+// files. It returns nil if there are none. Pacts that can't be read are left
+// for the verifier to report.
+func newProviderTrailers(pactURLs []string, request types.VerifyRequest) (*providerTrailers, error) {
+	t := &providerTrailers{interactions: make(map[string][]*trailerInteraction)}
+
+	for _, location := range pactURLs {
+		pact, err := readTriagePact(location, request)
+		if err != nil {
+			log.Printf("[WARN] unable to read pact %s to check for trailers: %v\n", location, err)
+			continue
+		}
+
+		for _, interaction := range pact.Interactions {
+			if len(interaction.Request.Trailers) == 0 && len(interaction.Response.Trailers) == 0 {
+				continue
+			}
+
+			requestTrailers, err := pactTrailers(interaction.Request.Trailers, interaction.Request.MatchingRules)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the request trailers of interaction '%s' in pact %s: %v", interaction.Description, location, err)
+			}
+			responseTrailers, err := pactTrailers(interaction.Response.Trailers, interaction.Response.MatchingRules)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the response trailers of interaction '%s' in pact %s: %v", interaction.Description, location, err)
+			}
+
+			key := negotiationKey(interaction.Request.Method, interaction.Request.Path)
+			t.interactions[key] = append(t.interactions[key], &trailerInteraction{
+				consumer:    pact.Consumer.Name,
+				description: interaction.Description,
+				request:     trailerExamples(requestTrailers),
+				response:    responseTrailers,
+			})
+		}
+	}
+
+	if len(t.interactions) == 0 {
+		return nil, nil
+	}
+
+	return t, nil
+}
+
+// pactTrailers reads the trailers of a request or response in a pact file
+// as matchers, from their examples and matching rules
+func pactTrailers(raw json.RawMessage, rawRules json.RawMessage) (MapMatcher, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var examples map[string]string
+	if err := json.Unmarshal(raw, &examples); err != nil {
+		return nil, err
+	}
+
+	// Convert the rules to the version 3 form, keyed by category
+	var converted struct {
+		Interactions []struct {
+			Request struct {
+				MatchingRules struct {
+					Trailer map[string]struct {
+						Matchers []map[string]interface{} `json:"matchers"`
+					} `json:"trailer"`
+				} `json:"matchingRules"`
+			} `json:"request"`
+		} `json:"interactions"`
+	}
+	if len(rawRules) > 0 {
+		doc, err := json.Marshal(map[string]interface{}{
+			"interactions": []interface{}{map[string]interface{}{"request": map[string]json.RawMessage{"matchingRules": rawRules}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		content, err := pactfile.ConvertSpecification(doc, 3)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(content, &converted); err != nil {
+			return nil, err
+		}
+	}
+
+	trailers := make(MapMatcher, len(examples))
+	for name, example := range examples {
+		trailers[name] = String(example)
+		if len(converted.Interactions) == 0 {
+			continue
+		}
+		for _, matcher := range converted.Interactions[0].Request.MatchingRules.Trailer[name].Matchers {
+			switch matcher["match"] {
+			case "regex":
+				regex, _ := matcher["regex"].(string)
+				trailers[name] = Term(example, regex)
+			case "type":
+				trailers[name] = Like(example)
+			}
+		}
+	}
+
+	return trailers, nil
+}
+
+func (t *providerTrailers) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.mu.Lock()
+		interactions := t.interactions[negotiationKey(r.Method, r.URL.Path)]
+		t.mu.Unlock()
+
+		if len(interactions) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The request trailers of the first interaction are sent, with a
+		// chunked body as trailers require
+		if len(interactions[0].request) > 0 {
+			r.Trailer = interactions[0].request
+			r.ContentLength = -1
+			r.Header.Del("Content-Length")
+			if r.Body == nil || r.Body == http.NoBody {
+				r.Body = ioutil.NopCloser(bytes.NewReader(nil))
+			}
+		}
+
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		trailers := splitTrailers(recorder.header)
+
+		t.mu.Lock()
+		for _, interaction := range interactions {
+			interaction.checked = true
+			if mismatches := trailerMismatches(interaction.response, trailers); len(mismatches) > 0 {
+				interaction.mismatches = mismatches
+			} else {
+				interaction.clean = true
+			}
+		}
+		t.mu.Unlock()
+
+		writeWithTrailers(w, recorder.header, recorder.status, recorder.body.Bytes(), trailers)
+	})
+}
+
+// fail marks the status examples of interactions whose responses had
+// mismatched trailers as failed, returning an error if there were any
+func (t *providerTrailers) fail(res []types.ProviderVerifierResponse, err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// consumer -> description -> interaction
+	byConsumer := make(map[string]map[string]*trailerInteraction)
+	for _, interactions := range t.interactions {
+		for _, interaction := range interactions {
+			if byConsumer[interaction.consumer] == nil {
+				byConsumer[interaction.consumer] = make(map[string]*trailerInteraction)
+			}
+			byConsumer[interaction.consumer][interaction.description] = interaction
+		}
+	}
+
+	failed := 0
+	for _, response := range res {
+		for n, example := range response.Examples {
+			if example.Status != "passed" || !strings.Contains(example.FullDescription, "has status code") {
+				continue
+			}
+
+			interactions := byConsumer[example.Pact.ConsumerName]
+			descriptions := make([]string, 0, len(interactions))
+			for description := range interactions {
+				descriptions = append(descriptions, description)
+			}
+			interaction, ok := interactions[exampleDescription(example.FullDescription, descriptions)]
+			if !ok || !interaction.checked || interaction.clean {
+				continue
+			}
+
+			messages := make([]string, len(interaction.mismatches))
+			for i, mismatch := range interaction.mismatches {
+				messages[i] = mismatch.String()
+			}
+			response.Examples[n].Status = "failed"
+			response.Examples[n].Exception.Message = "the response trailers don't match: " + strings.Join(messages, "; ")
+			response.Examples[n].Mismatches = append(response.Examples[n].Mismatches, messages...)
+			failed++
+		}
+	}
+
+	if failed > 0 && err == nil {
+		err = fmt.Errorf("%d interactions have mismatched response trailers", failed)
+	}
+
+	return err
+}
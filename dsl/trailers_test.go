@@ -0,0 +1,182 @@
+package dsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func uploadInteraction() *Interaction {
+	return (&Interaction{}).
+		UponReceiving("a streamed upload").
+		WithRequest(Request{
+			Method:   "POST",
+			Path:     String("/uploads"),
+			Trailers: MapMatcher{"X-Checksum": Term("5d41402a", `^[a-f0-9]{8}$`)},
+		}).
+		WillRespondWith(Response{
+			Status:   200,
+			Trailers: MapMatcher{"Grpc-Status": String("0")},
+		})
+}
+
+// postWithTrailer sends a chunked body followed by the trailer
+func postWithTrailer(t *testing.T, u string, checksum string) *http.Response {
+	req, _ := http.NewRequest("POST", u, ioutil.NopCloser(strings.NewReader("hello")))
+	req.ContentLength = -1
+	req.Trailer = http.Header{"X-Checksum": []string{checksum}}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(res.Body) // nolint:errcheck
+	res.Body.Close()
+
+	return res
+}
+
+func TestNativeMockServer_Trailers(t *testing.T) {
+	server := NewNativeMockServer()
+	defer server.Close()
+	if err := server.AddInteraction(uploadInteraction()); err != nil {
+		t.Fatal(err)
+	}
+
+	res := postWithTrailer(t, server.URL+"/uploads", "5d41402a")
+	if res.StatusCode != 200 || res.Trailer.Get("Grpc-Status") != "0" {
+		t.Fatalf("expected the response trailer, got %d and %v", res.StatusCode, res.Trailer)
+	}
+	if err := server.Verify(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res = postWithTrailer(t, server.URL+"/uploads", "not hex"); res.StatusCode != 500 {
+		t.Fatalf("expected the mismatched trailer to be rejected, got %d", res.StatusCode)
+	}
+	err := server.Verify()
+	if err == nil || !strings.Contains(err.Error(), "trailer X-Checksum: expected '5d41402a' but got 'not hex'") {
+		t.Fatalf("expected the trailer mismatch, got %v", err)
+	}
+
+	pact, err := server.Pact("web", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := pact.Interactions[0].Request
+	if compactJSON(request.Trailers) != `{"X-Checksum":"5d41402a"}` || !strings.Contains(compactJSON(request.MatchingRules), `"trailer":{"X-Checksum":{"combine":"AND","matchers":[{"match":"regex","regex":"^[a-f0-9]{8}$"}]}}`) {
+		t.Fatalf("expected the request trailer and its rule, got %s and %s", request.Trailers, request.MatchingRules)
+	}
+}
+
+func TestWriteTrailers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trailers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	interaction := uploadInteraction()
+	trailers := map[string]*interactionTrailers{interaction.Description: interaction.trailerSides()}
+	pact := `{"consumer": {"name": "web"}, "provider": {"name": "uploads"}, "interactions": [
+		{"description": "a streamed upload", "request": {"method": "POST", "path": "/uploads"}, "response": {"status": 200}}
+	]}`
+
+	for _, nested := range []bool{false, true} {
+		file := filepath.Join(dir, "web-uploads.json")
+		if err = ioutil.WriteFile(file, []byte(pact), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err = writeTrailers(file, trailers, nested); err != nil {
+			t.Fatal(err)
+		}
+
+		written, err := pactfile.Read(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"$.trailers.X-Checksum":{"match":"regex","regex":"^[a-f0-9]{8}$"}}`
+		if nested {
+			want = `{"trailer":{"X-Checksum":{"combine":"AND","matchers":[{"match":"regex","regex":"^[a-f0-9]{8}$"}]}}}`
+		}
+		if got := compactJSON(written.Interactions[0].Request.MatchingRules); got != want {
+			t.Fatalf("want %s, got %s", want, got)
+		}
+		if got := compactJSON(written.Interactions[0].Response.Trailers); got != `{"Grpc-Status":"0"}` {
+			t.Fatalf("expected the response trailer example, got %s", got)
+		}
+
+		matchers, err := pactTrailers(written.Interactions[0].Request.Trailers, written.Interactions[0].Request.MatchingRules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trailerMismatches(matchers, http.Header{"X-Checksum": []string{"0badf00d"}})) != 0 || len(trailerMismatches(matchers, http.Header{"X-Checksum": []string{"nope"}})) != 1 {
+			t.Fatalf("expected the trailer to be matched by its regex, got %v", matchers)
+		}
+	}
+}
+
+func TestProviderTrailers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trailers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := NewNativeMockServer()
+	server.AddInteraction(uploadInteraction()) // nolint:errcheck
+	pact, err := server.Pact("web", "uploads")
+	server.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "web-uploads.json")
+	if err = pact.Write(file); err != nil {
+		t.Fatal(err)
+	}
+
+	trailers, err := newProviderTrailers([]string{file}, types.VerifyRequest{})
+	if err != nil || trailers == nil {
+		t.Fatalf("expected the interaction with trailers to be read, got %v", err)
+	}
+
+	var received string
+	provider := trailers.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body) // nolint:errcheck
+		received = r.Trailer.Get("X-Checksum")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte("ok")) // nolint:errcheck
+		w.Header().Set("Grpc-Status", "13")
+	}))
+	recorder := httptest.NewRecorder()
+	provider.ServeHTTP(recorder, httptest.NewRequest("POST", "/uploads", strings.NewReader("hello")))
+	if received != "5d41402a" {
+		t.Fatalf("expected the request trailer to be sent to the provider, got %q", received)
+	}
+	if recorder.Result().Trailer.Get("Grpc-Status") != "13" {
+		t.Fatalf("expected the provider's trailer to be passed on, got %v", recorder.Result().Trailer)
+	}
+
+	var res []types.ProviderVerifierResponse
+	err = json.Unmarshal([]byte(`[{"examples": [
+		{"status": "passed", "full_description": "Verifying a pact between web and uploads A streamed upload with POST /uploads returns a response which has status code 200", "pact": {"consumer_name": "web"}}
+	]}]`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = trailers.fail(res, nil)
+	if err == nil || err.Error() != "1 interactions have mismatched response trailers" {
+		t.Fatalf("expected an error for the mismatched trailer, got %v", err)
+	}
+	if want := "the response trailers don't match: trailer Grpc-Status: expected '0' but got '13'"; res[0].Examples[0].Status != "failed" || res[0].Examples[0].Exception.Message != want {
+		t.Fatalf("want %q, got %s %q", want, res[0].Examples[0].Status, res[0].Examples[0].Exception.Message)
+	}
+}
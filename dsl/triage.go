@@ -0,0 +1,362 @@
+package dsl
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// triageFailure is a failed interaction in a triage bundle
+type triageFailure struct {
+	Consumer string `json:"consumer"`
+	PactURL  string `json:"pactUrl"`
+
+	// Description is the description of the interaction, if found in the
+	// pact, and Example the full description of the failed test
+	Description string `json:"description,omitempty"`
+	Example     string `json:"example"`
+
+	Category   types.FailureCategory `json:"category,omitempty"`
+	Mismatches []string              `json:"mismatches,omitempty"`
+	Exception  string                `json:"exception,omitempty"`
+
+	// Exchanges are the requests sent to the provider for the interaction,
+	// and its responses
+	Exchanges []exchange `json:"exchanges"`
+}
+
+// exchange is a request sent to the provider during verification, and its
+// response
+type exchange struct {
+	Request struct {
+		Method  string      `json:"method"`
+		Path    string      `json:"path"`
+		Query   string      `json:"query,omitempty"`
+		Headers http.Header `json:"headers,omitempty"`
+		Body    string      `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Status  int         `json:"status"`
+		Headers http.Header `json:"headers,omitempty"`
+		Body    string      `json:"body,omitempty"`
+	} `json:"response"`
+}
+
+// exchangeRecorder records the requests sent to the provider during
+// verification, and its responses
+type exchangeRecorder struct {
+	mu        sync.Mutex
+	exchanges []exchange
+}
+
+// middleware records each request other than provider state changes. It is
+// placed before the RequestFilter, so that credentials added by the filter
+// aren't recorded.
+func (e *exchangeRecorder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == providerStatesSetupPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var x exchange
+		x.Request.Method = r.Method
+		x.Request.Path = r.URL.Path
+		x.Request.Query = r.URL.RawQuery
+		x.Request.Headers = cloneHeader(r.Header)
+		if r.Body != nil {
+			body, _ := ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			x.Request.Body = string(body)
+		}
+
+		rec := &recordingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		x.Response.Status = rec.status
+		x.Response.Headers = cloneHeader(w.Header())
+		x.Response.Body = rec.body.String()
+
+		e.mu.Lock()
+		e.exchanges = append(e.exchanges, x)
+		e.mu.Unlock()
+	})
+}
+
+// matching returns the exchanges for the method and path
+func (e *exchangeRecorder) matching(method, path string) []exchange {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	matched := []exchange{}
+	for _, x := range e.exchanges {
+		if strings.EqualFold(x.Request.Method, method) && x.Request.Path == path {
+			matched = append(matched, x)
+		}
+	}
+
+	return matched
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for name, values := range header {
+		clone[name] = append([]string(nil), values...)
+	}
+
+	return clone
+}
+
+// recordingResponseWriter copies the status and body written to it
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingResponseWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// writeTriageBundle writes a triage bundle to the request's TriageDir if the
+// verification failed, returning the verification error
+func writeTriageBundle(request types.VerifyRequest, res []types.ProviderVerifierResponse, err error, recorder *exchangeRecorder) error {
+	if request.TriageDir == "" {
+		return err
+	}
+
+	result := types.NewVerificationResult(res, err)
+	if result.Status != types.StatusFailed {
+		return err
+	}
+
+	log.Println("[INFO] verification failed, writing triage bundle to", request.TriageDir)
+	files, bundleErr := triageFiles(request, res, result, recorder)
+	if bundleErr == nil {
+		bundleErr = writeBundle(request.TriageDir, files)
+	}
+	if bundleErr != nil {
+		if err != nil {
+			log.Println("[ERROR] unable to write triage bundle:", bundleErr)
+			return err
+		}
+		return fmt.Errorf("unable to write triage bundle: %v", bundleErr)
+	}
+
+	return err
+}
+
+// triageFiles builds the contents of a triage bundle: a summary of the
+// verification, each failure with the requests sent to the provider, the
+// failing interactions of each pact and the provider's logs
+func triageFiles(request types.VerifyRequest, res []types.ProviderVerifierResponse, result types.VerificationResult, recorder *exchangeRecorder) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if recorder == nil {
+		recorder = &exchangeRecorder{}
+	}
+
+	var err error
+	if files["summary.json"], err = json.MarshalIndent(result, "", "  "); err != nil {
+		return nil, err
+	}
+
+	failures := []triageFailure{}
+	failing := make(map[string][]string)
+	pacts := make(map[string]*pactfile.Pact)
+	for _, response := range res {
+		for _, example := range response.Examples {
+			if example.Status != "failed" && example.Status != "errored" {
+				continue
+			}
+
+			failure := triageFailure{
+				Consumer:   example.Pact.ConsumerName,
+				PactURL:    example.Pact.URL,
+				Example:    example.FullDescription,
+				Category:   example.Category,
+				Mismatches: example.Mismatches,
+				Exception:  example.Exception.Message,
+				Exchanges:  []exchange{},
+			}
+
+			pact, ok := pacts[example.Pact.URL]
+			if !ok {
+				if pact, err = readTriagePact(example.Pact.URL, request); err != nil {
+					log.Println("[WARN] unable to read pact", example.Pact.URL, "for triage:", err)
+				}
+				pacts[example.Pact.URL] = pact
+			}
+			if pact != nil {
+				descriptions := make([]string, len(pact.Interactions))
+				for n, interaction := range pact.Interactions {
+					descriptions[n] = interaction.Description
+				}
+				failure.Description = exampleDescription(example.FullDescription, descriptions)
+				for _, interaction := range pact.Interactions {
+					if interaction.Description == failure.Description {
+						failure.Exchanges = recorder.matching(interaction.Request.Method, interaction.Request.Path)
+						break
+					}
+				}
+			}
+			if failure.Description != "" && !containsString(failing[example.Pact.URL], failure.Description) {
+				failing[example.Pact.URL] = append(failing[example.Pact.URL], failure.Description)
+			}
+
+			failures = append(failures, failure)
+		}
+	}
+
+	if files["failures.json"], err = json.MarshalIndent(failures, "", "  "); err != nil {
+		return nil, err
+	}
+
+	for pactURL, descriptions := range failing {
+		content, err := readPactContent(pactURL, request)
+		if err != nil {
+			return nil, err
+		}
+		pact := pacts[pactURL]
+		name := filepath.Join("pacts", pactFileName(pact.Consumer.Name, pact.Provider.Name))
+		if files[name], err = failingInteractions(content, descriptions); err != nil {
+			return nil, fmt.Errorf("unable to parse pact %s: %v", pactURL, err)
+		}
+	}
+
+	if request.ProviderLogs != nil {
+		logs, err := request.ProviderLogs()
+		if err != nil {
+			logs = append(logs, []byte(fmt.Sprintf("\nunable to capture provider logs: %v\n", err))...)
+		}
+		files["provider.log"] = logs
+	}
+
+	return files, nil
+}
+
+// exampleDescription finds the interaction of a verification example: the
+// one with the longest description contained in its full description (which
+// may be capitalised)
+func exampleDescription(fullDescription string, descriptions []string) string {
+	var match string
+	fullDescription = strings.ToLower(fullDescription)
+	for _, description := range descriptions {
+		if strings.Contains(fullDescription, strings.ToLower(description)) && len(description) > len(match) {
+			match = description
+		}
+	}
+
+	return match
+}
+
+// readPactContent reads a local pact file, or fetches one from a broker
+func readPactContent(location string, request types.VerifyRequest) ([]byte, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return brokerGet(location, request.BrokerAuth())
+	}
+
+	return ioutil.ReadFile(location)
+}
+
+func readTriagePact(location string, request types.VerifyRequest) (*pactfile.Pact, error) {
+	content, err := readPactContent(location, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return pactfile.Parse(content)
+}
+
+// failingInteractions removes all but the interactions with the given
+// descriptions from the pact, so that just the failures can be reproduced
+func failingInteractions(content []byte, descriptions []string) ([]byte, error) {
+	var pact map[string]json.RawMessage
+	if err := json.Unmarshal(content, &pact); err != nil {
+		return nil, err
+	}
+
+	var interactions []json.RawMessage
+	if err := json.Unmarshal(pact["interactions"], &interactions); err != nil && len(pact["interactions"]) > 0 {
+		return nil, err
+	}
+
+	kept := make([]json.RawMessage, 0, len(descriptions))
+	for _, raw := range interactions {
+		var interaction pactfile.Interaction
+		if err := json.Unmarshal(raw, &interaction); err != nil {
+			return nil, err
+		}
+		if containsString(descriptions, interaction.Description) {
+			kept = append(kept, raw)
+		}
+	}
+
+	var err error
+	if pact["interactions"], err = json.Marshal(kept); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(pact, "", "  ")
+}
+
+// writeBundle writes the files to a zip archive if the path ends in ".zip",
+// otherwise to the directory
+func writeBundle(path string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !strings.HasSuffix(strings.ToLower(path), ".zip") {
+		for _, name := range names {
+			file := filepath.Join(path, name)
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(file, files[name], 0644); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := archive.Create(filepath.ToSlash(name))
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,172 @@
+package dsl
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestExchangeRecorder(t *testing.T) {
+	recorder := &exchangeRecorder{}
+	handler := recorder.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append([]byte(`{"echo":`), append(body, '}')...)) // nolint:errcheck
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/users?active=true", strings.NewReader(`"jane"`)))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", providerStatesSetupPath, strings.NewReader(`{}`)))
+
+	exchanges := recorder.matching("post", "/users")
+	if len(exchanges) != 1 || len(recorder.exchanges) != 1 {
+		t.Fatalf("expected one exchange to be recorded, got %+v", recorder.exchanges)
+	}
+	x := exchanges[0]
+	if x.Request.Query != "active=true" || x.Request.Body != `"jane"` {
+		t.Fatalf("unexpected request %+v", x.Request)
+	}
+	if x.Response.Status != http.StatusCreated || x.Response.Body != `{"echo":"jane"}` || x.Response.Headers.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %+v", x.Response)
+	}
+}
+
+// triageFixture writes a pact with two interactions, returning its file and
+// a verification failing the first of them
+func triageFixture(t *testing.T, dir string) (string, []types.ProviderVerifierResponse) {
+	file := filepath.Join(dir, "billing-accounts.json")
+	ioutil.WriteFile(file, []byte(`{
+		"consumer": {"name": "billing"},
+		"provider": {"name": "accounts"},
+		"interactions": [
+			{"description": "a request for an account", "request": {"method": "GET", "path": "/accounts/1"}, "response": {"status": 200}},
+			{"description": "a request for all accounts", "request": {"method": "GET", "path": "/accounts"}, "response": {"status": 200}}
+		],
+		"metadata": {"pactSpecification": {"version": "2.0.0"}}
+	}`), 0644)
+
+	var res []types.ProviderVerifierResponse
+	err := json.Unmarshal([]byte(`[{"examples": [
+		{"full_description": "Verifying a pact between billing and accounts A request for an account with GET /accounts/1 returns a response which has status code 200", "status": "failed",
+		 "exception": {"message": "expected: 200\n     got: 404"}, "pact": {"consumer_name": "billing", "url": "`+file+`"}},
+		{"full_description": "Verifying a pact between billing and accounts A request for all accounts with GET /accounts returns a response which has status code 200", "status": "passed",
+		 "pact": {"consumer_name": "billing", "url": "`+file+`"}}
+	]}]`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file, res
+}
+
+func TestWriteTriageBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-triage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, res := triageFixture(t, dir)
+	recorder := &exchangeRecorder{}
+	handler := recorder.middleware(http.NotFoundHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/accounts/1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/accounts", nil))
+
+	bundle := filepath.Join(dir, "triage")
+	request := types.VerifyRequest{
+		TriageDir:    bundle,
+		ProviderLogs: func() ([]byte, error) { return []byte("GET /accounts/1 404\n"), nil },
+	}
+	verifyErr := errors.New("1 example failed")
+	if err = writeTriageBundle(request, res, verifyErr, recorder); err != verifyErr {
+		t.Fatalf("expected the verification error to be returned, got %v", err)
+	}
+
+	var failures []triageFailure
+	content, _ := ioutil.ReadFile(filepath.Join(bundle, "failures.json"))
+	if err = json.Unmarshal(content, &failures); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(failures) != 1 || failures[0].Description != "a request for an account" || failures[0].Consumer != "billing" {
+		t.Fatalf("unexpected failures %+v", failures)
+	}
+	if len(failures[0].Exchanges) != 1 || failures[0].Exchanges[0].Response.Status != http.StatusNotFound {
+		t.Fatalf("expected the request for the failed interaction, got %+v", failures[0].Exchanges)
+	}
+
+	var pact struct {
+		Interactions []struct {
+			Description string `json:"description"`
+		} `json:"interactions"`
+	}
+	content, _ = ioutil.ReadFile(filepath.Join(bundle, "pacts", "billing-accounts.json"))
+	if err = json.Unmarshal(content, &pact); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pact.Interactions) != 1 || pact.Interactions[0].Description != "a request for an account" {
+		t.Fatalf("expected only the failing interaction, got %+v", pact.Interactions)
+	}
+
+	if logs, _ := ioutil.ReadFile(filepath.Join(bundle, "provider.log")); string(logs) != "GET /accounts/1 404\n" {
+		t.Fatalf("expected the provider logs, got %q", logs)
+	}
+	var summary types.VerificationResult
+	content, _ = ioutil.ReadFile(filepath.Join(bundle, "summary.json"))
+	if err = json.Unmarshal(content, &summary); err != nil || summary.Status != types.StatusFailed || summary.Failures != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+}
+
+func TestWriteTriageBundle_Zip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-triage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, res := triageFixture(t, dir)
+	bundle := filepath.Join(dir, "triage.zip")
+	if err = writeTriageBundle(types.VerifyRequest{TriageDir: bundle}, res, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	archive, err := zip.OpenReader(bundle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer archive.Close()
+
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "failures.json,pacts/billing-accounts.json,summary.json" {
+		t.Fatalf("unexpected bundle contents %v", names)
+	}
+}
+
+func TestWriteTriageBundle_Passed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact-triage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bundle := filepath.Join(dir, "triage")
+	res := []types.ProviderVerifierResponse{{}}
+	if err = writeTriageBundle(types.VerifyRequest{TriageDir: bundle}, res, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = os.Stat(bundle); !os.IsNotExist(err) {
+		t.Fatal("expected no bundle for a passing verification")
+	}
+}
@@ -0,0 +1,232 @@
+package dsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"unicode/utf8"
+)
+
+// unicodeExample is the example of UnicodeString: accented Latin (which has
+// both composed and decomposed forms), Cyrillic, CJK and an emoji outside of
+// the Basic Multilingual Plane
+const unicodeExample = "Zoë Ångström – Москва 東京 🚀"
+
+// unicodeNormaliser normalises the text of requests or responses to a single
+// Unicode normalisation form, with a function such as norm.NFC.String from
+// golang.org/x/text/unicode/norm, so that text differing only in its form
+// (e.g. "é" as a single code point, or as "e" and a combining accent) matches.
+// It is used in front of the mock server for requests, and in front of the
+// provider during verification for responses.
+type unicodeNormaliser func(string) string
+
+// normaliseMatcher returns a copy of an expected value of an interaction,
+// e.g. its body, with its strings normalised: the examples and regexes of
+// matchers, plain strings and the keys of objects. Values of other types
+// are returned unchanged.
+func (n unicodeNormaliser) normaliseMatcher(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return n(v)
+	case String:
+		return String(n(string(v)))
+	case S:
+		return S(n(string(v)))
+	case described:
+		v.Matcher = n.normaliseMatcher(v.Matcher).(Matcher)
+		return v
+	case generated:
+		v.Matcher = n.normaliseMatcher(v.Matcher).(Matcher)
+		return v
+	case like:
+		v.Contents = n.normaliseMatcher(v.Contents)
+		return v
+	case eachLike:
+		v.Contents = n.normaliseMatcher(v.Contents)
+		return v
+	case term:
+		v.Data.Generate = n.normaliseMatcher(v.Data.Generate)
+		v.Data.Matcher.Regex = n.normaliseMatcher(v.Data.Matcher.Regex)
+		return v
+	case StructMatcher:
+		normalised := make(StructMatcher, len(v))
+		for key, value := range v {
+			normalised[n(key)] = n.normaliseMatcher(value)
+		}
+		return normalised
+	case MapMatcher:
+		return n.normaliseMapMatcher(v)
+	case map[string]interface{}:
+		normalised := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			normalised[n(key)] = n.normaliseMatcher(value)
+		}
+		return normalised
+	case []interface{}:
+		normalised := make([]interface{}, len(v))
+		for i, value := range v {
+			normalised[i] = n.normaliseMatcher(value)
+		}
+		return normalised
+	}
+
+	return value
+}
+
+func (n unicodeNormaliser) normaliseMapMatcher(m MapMatcher) MapMatcher {
+	if m == nil {
+		return nil
+	}
+
+	normalised := make(MapMatcher, len(m))
+	for key, value := range m {
+		if value == nil {
+			normalised[n(key)] = nil
+			continue
+		}
+		normalised[n(key)] = n.normaliseMatcher(value).(Matcher)
+	}
+
+	return normalised
+}
+
+// normaliseInteraction normalises the expected request and response of the
+// interaction, so that the pact is written in the same form as the requests
+// it is compared with
+func (n unicodeNormaliser) normaliseInteraction(i *Interaction) {
+	if i.Request.Path != nil {
+		i.Request.Path = n.normaliseMatcher(i.Request.Path).(Matcher)
+	}
+	i.Request.Query = n.normaliseMapMatcher(i.Request.Query)
+	i.Request.Headers = n.normaliseMapMatcher(i.Request.Headers)
+	i.Request.Body = n.normaliseMatcher(i.Request.Body)
+	i.Response.Headers = n.normaliseMapMatcher(i.Response.Headers)
+	i.Response.Body = n.normaliseMatcher(i.Response.Body)
+}
+
+// normaliseBody normalises the strings of a JSON body, or the whole of any
+// other UTF-8 text. The body is returned unchanged if it is already
+// normalised, or isn't text.
+func (n unicodeNormaliser) normaliseBody(body []byte) []byte {
+	if !utf8.Valid(body) {
+		return body
+	}
+
+	doc, err := decodeJSON(body)
+	if err != nil {
+		if normalised := n(string(body)); normalised != string(body) {
+			return []byte(normalised)
+		}
+		return body
+	}
+
+	changed := false
+	doc = n.normaliseJSON(doc, &changed)
+	if !changed {
+		return body
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(doc); err != nil {
+		return body
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func (n unicodeNormaliser) normaliseJSON(value interface{}, changed *bool) interface{} {
+	switch v := value.(type) {
+	case string:
+		normalised := n(v)
+		if normalised != v {
+			*changed = true
+		}
+		return normalised
+	case map[string]interface{}:
+		normalised := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			normalised[n.normaliseJSON(key, changed).(string)] = n.normaliseJSON(value, changed)
+		}
+		return normalised
+	case []interface{}:
+		for i, value := range v {
+			v[i] = n.normaliseJSON(value, changed)
+		}
+	}
+
+	return value
+}
+
+func (n unicodeNormaliser) normaliseHeader(header http.Header) {
+	for _, values := range header {
+		for i, value := range values {
+			values[i] = n(value)
+		}
+	}
+}
+
+// requestMiddleware normalises the path, query, headers and body of requests
+func (n unicodeNormaliser) requestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if path := n(r.URL.Path); path != r.URL.Path {
+			r.URL.Path = path
+			r.URL.RawPath = ""
+		}
+		if r.URL.RawQuery != "" {
+			query := r.URL.Query()
+			normalised := make(url.Values, len(query))
+			for key, values := range query {
+				for _, value := range values {
+					normalised.Add(n(key), n(value))
+				}
+			}
+			if normalised.Encode() != query.Encode() {
+				r.URL.RawQuery = normalised.Encode()
+			}
+		}
+		r.RequestURI = r.URL.RequestURI()
+		n.normaliseHeader(r.Header)
+
+		if r.Body != nil {
+			content, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body.Close()
+
+			content = n.normaliseBody(content)
+			r.ContentLength = int64(len(content))
+			r.Header.Del("Content-Length")
+			r.Body = ioutil.NopCloser(bytes.NewReader(content))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// responseMiddleware normalises the headers and body of responses
+func (n unicodeNormaliser) responseMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		n.normaliseHeader(recorder.header)
+		content := n.normaliseBody(recorder.body.Bytes())
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(recorder.status)
+		w.Write(content) // nolint:errcheck
+	})
+}
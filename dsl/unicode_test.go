@@ -0,0 +1,116 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// composeAccents is a stand-in for norm.NFC.String, composing the decomposed
+// characters used in the tests
+var composeAccents = unicodeNormaliser(strings.NewReplacer("e\u0301", "é", "A\u030a", "Å").Replace)
+
+func TestMatcher_UnicodeString(t *testing.T) {
+	example, ok := UnicodeString().GetValue().(string)
+	if !ok || len(example) == utf8.RuneCountInString(example) {
+		t.Fatalf("expected a multibyte string example, got %v", UnicodeString().GetValue())
+	}
+	if !utf8.ValidString(example) {
+		t.Fatalf("expected the example to be valid UTF-8, got %q", example)
+	}
+	if jsonString(UnicodeString()) != jsonString(Like(example)) {
+		t.Fatalf("expected a type matcher, got %s", jsonString(UnicodeString()))
+	}
+}
+
+func TestUnicodeNormaliser_Interaction(t *testing.T) {
+	interaction := (&Interaction{}).
+		UponReceiving("a request for a cafe").
+		WithRequest(Request{
+			Method:  "GET",
+			Path:    String("/cafe\u0301s"),
+			Query:   MapMatcher{"name": Term("Cafe\u0301", "^Cafe\u0301$")},
+			Headers: MapMatcher{"X-City": String("A\u030arhus")},
+		}).
+		WillRespondWith(Response{
+			Status: 200,
+			Body: StructMatcher{
+				"name\u0301": Like("Cafe\u0301"),
+				"menu":       EachLike(map[string]interface{}{"dish": S("Crème brûle\u0301e")}, 1),
+				"rating":     Like(5),
+			},
+		})
+
+	composeAccents.normaliseInteraction(interaction)
+
+	for _, want := range []string{`"/cafés"`, `"generate":"Café"`, `"s":"^Café$"`, `"Århus"`} {
+		if !strings.Contains(jsonString(interaction.Request), want) {
+			t.Fatalf("expected the request to contain %s, got %s", want, jsonString(interaction.Request))
+		}
+	}
+	body := jsonString(interaction.Response.Body)
+	for _, want := range []string{`"namé"`, `"contents":"Café"`, `"contents":5`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected the response body to contain %s, got %s", want, body)
+		}
+	}
+}
+
+func TestUnicodeNormaliser_Body(t *testing.T) {
+	cases := []struct {
+		body, want string
+	}{
+		{"{\"name\": \"Cafe\u0301\", \"tags\": [\"A\u030arhus\", 1], \"e\u0301\": null}", `{"name":"Café","tags":["Århus",1],"é":null}`},
+		{"{\"name\": \"Caf\\u0065\\u0301 <&>\", \"price\": 1.50}", `{"name":"Café <&>","price":1.50}`},
+		{`{"name": "Café", "price": 1.50}`, `{"name": "Café", "price": 1.50}`},
+		{"Cafe\u0301 au lait", "Café au lait"},
+		{"\xff\xfe", "\xff\xfe"},
+	}
+
+	for _, c := range cases {
+		if got := string(composeAccents.normaliseBody([]byte(c.body))); got != c.want {
+			t.Fatalf("want %q, got %q", c.want, got)
+		}
+	}
+}
+
+func TestUnicodeNormaliser_RequestMiddleware(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	handler := composeAccents.requestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+
+	target := "/" + url.PathEscape("cafe\u0301s") + "?name=" + url.QueryEscape("Cafe\u0301") + "&page=1"
+	req := httptest.NewRequest("POST", target, strings.NewReader("{\"city\":\"A\u030arhus\"}"))
+	req.Header.Set("X-City", "A\u030arhus")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if received.URL.Path != "/cafés" || received.URL.Query().Get("name") != "Café" || received.URL.Query().Get("page") != "1" {
+		t.Fatalf("expected the path and query to be normalised, got %s", received.URL)
+	}
+	if received.Header.Get("X-City") != "Århus" || string(body) != `{"city":"Århus"}` || received.ContentLength != int64(len(body)) {
+		t.Fatalf("expected the headers and body to be normalised, got %v %s", received.Header, body)
+	}
+}
+
+func TestUnicodeNormaliser_ResponseMiddleware(t *testing.T) {
+	handler := composeAccents.responseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-City", "A\u030arhus")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "[\"Cafe\u0301\"]")
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/cafes", nil))
+
+	if recorder.Code != http.StatusCreated || recorder.Header().Get("X-City") != "Århus" || recorder.Body.String() != `["Café"]` {
+		t.Fatalf("expected the response to be normalised, got %d %v %s", recorder.Code, recorder.Header(), recorder.Body.String())
+	}
+}
@@ -0,0 +1,237 @@
+package dsl
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// v3MatchTypes are the matching rules only supported by version 3 (or later)
+// pacts
+var v3MatchTypes = map[string]bool{
+	"integer":     true,
+	"decimal":     true,
+	"number":      true,
+	"boolean":     true,
+	"null":        true,
+	"equality":    true,
+	"include":     true,
+	"date":        true,
+	"time":        true,
+	"timestamp":   true,
+	"contentType": true,
+	"values":      true,
+}
+
+// FieldError is a problem with a single field of an interaction
+type FieldError struct {
+	// Field is the path of the field e.g. "request.body" or
+	// "response.headers.Content-Type"
+	Field string
+
+	Message string
+}
+
+func (e FieldError) String() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError lists every problem found validating an interaction
+type ValidationError struct {
+	Description string
+	Errors      []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Errors))
+	for i, fieldError := range e.Errors {
+		problems[i] = fieldError.String()
+	}
+
+	return fmt.Sprintf("interaction '%s' is invalid:\n\t%s", e.Description, strings.Join(problems, "\n\t"))
+}
+
+// Validate checks the interaction for mistakes that would otherwise only be
+// found by the mock server or provider, such as a GET request with a body, a
+// response without a status, a body that isn't explicit when required by
+// ExplicitBodies, an example response that doesn't satisfy its own matching
+// rules when checked by ValidateExamples, or matching rules that the pact
+// specification version doesn't support. All problems found are reported
+// together.
+func (i *Interaction) Validate() error {
+	problems, errs := i.validate()
+	if errs = append(problems, errs...); len(errs) > 0 {
+		return &ValidationError{Description: i.Description, Errors: errs}
+	}
+
+	return nil
+}
+
+// validate returns the problems with the interaction that the mock server
+// tolerates, which Verify only reports as warnings unless StrictValidation is
+// set, and the errors that would otherwise fail the test later
+func (i *Interaction) validate() (problems []FieldError, errs []FieldError) {
+	add := func(field string, format string, args ...interface{}) {
+		problems = append(problems, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	fail := func(field string, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(i.Description) == "" {
+		add("description", "is required, see UponReceiving")
+	}
+
+	method := strings.ToUpper(i.Request.Method)
+	if method == "" {
+		add("request.method", "is required")
+	}
+	if i.Request.Path == nil {
+		add("request.path", "is required")
+	}
+	if hasBody(i.Request.Body) && (method == http.MethodGet || method == http.MethodHead) {
+		add("request.body", "a %s request should not have a body, use NoBody", method)
+	}
+
+	switch status := i.Response.Status; {
+	case status == 0:
+		add("response.status", "is required")
+	case status < 100 || status > 599:
+		add("response.status", "%d is not a valid HTTP status", status)
+	case hasBody(i.Response.Body) && (status == http.StatusNoContent || status == http.StatusNotModified):
+		add("response.body", "a %d response should not have a body, use NoBody", status)
+	case hasBody(i.Response.Body) && method == http.MethodHead:
+		add("response.body", "a HEAD response should not have a body, use NoBody")
+	}
+	errs = append(errs, i.bodyExpectationErrors()...)
+	errs = append(errs, i.bodyMatchingErrors()...)
+	errs = append(errs, i.numbersAsStringsErrors()...)
+	errs = append(errs, i.bodyTransformErrors()...)
+
+	problems = append(problems, headerErrors("request.headers", i.Request.Headers)...)
+	problems = append(problems, headerErrors("response.headers", i.Response.Headers)...)
+
+	if err := jsonBodyError(i.Request.Body); err != nil {
+		fail("request.body", "%v", err)
+	}
+	if err := jsonBodyError(i.Response.Body); err != nil {
+		fail("response.body", "%v", err)
+	}
+	if err := msgpackBodyError(i.Request.Body); err != nil {
+		fail("request.body", "%v", err)
+	}
+	if err := msgpackBodyError(i.Response.Body); err != nil {
+		fail("response.body", "%v", err)
+	}
+
+	if err := i.Request.MatchingRules.validate("body", "headers", "query", "path"); err != nil {
+		fail("request.matchingRules", "%v", err)
+	}
+	if err := i.Response.MatchingRules.validate("body", "headers"); err != nil {
+		fail("response.matchingRules", "%v", err)
+	}
+	if i.hasStateParams() && i.specificationVersion > 0 && i.specificationVersion < 3 {
+		add("providerStates", "provider states with parameters, or several provider states, require pact specification version 3, the pact is version %d", i.specificationVersion)
+	}
+	problems = append(problems, ruleVersionErrors("request.matchingRules", i.Request.MatchingRules, i.specificationVersion)...)
+	problems = append(problems, ruleVersionErrors("response.matchingRules", i.Response.MatchingRules, i.specificationVersion)...)
+	if i.validateExamples {
+		errs = append(errs, i.exampleErrors()...)
+	}
+
+	return problems, errs
+}
+
+// headerErrors checks header names and values for newlines, which would
+// otherwise split the header when sent
+func headerErrors(field string, headers MapMatcher) []FieldError {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []FieldError
+	for _, name := range names {
+		if strings.ContainsAny(name, "\r\n") {
+			errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf("header name %q contains a newline", name)})
+			continue
+		}
+		if value := headers[name]; value != nil && strings.ContainsAny(fmt.Sprint(value.GetValue()), "\r\n") {
+			errs = append(errs, FieldError{Field: field + "." + name, Message: "contains a newline"})
+		}
+	}
+
+	return errs
+}
+
+// ruleVersionErrors checks that each rule's match type is supported by the
+// specification version (defaulting to 2)
+func ruleVersionErrors(field string, rules MatchingRules, version int) []FieldError {
+	if version == 0 {
+		version = 2
+	}
+
+	paths := make([]string, 0, len(rules))
+	for path := range rules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var errs []FieldError
+	for _, path := range paths {
+		match, _ := rules[path]["match"].(string)
+		switch {
+		case match == "", match == "type", match == "regex":
+		case v3MatchTypes[match]:
+			if version < 3 {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("%s[%s]", field, path),
+					Message: fmt.Sprintf("the %s matcher requires pact specification version 3, the pact is version %d", match, version),
+				})
+			}
+		default:
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("%s[%s]", field, path),
+				Message: fmt.Sprintf("unknown matcher %q", match),
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateInteractions validates every interaction, so that all errors are
+// reported together before the mock server is used. Problems the mock server
+// tolerates are logged as warnings, unless StrictValidation is set.
+func (p *Pact) validateInteractions() error {
+	var errs []string
+	var first error
+	for _, interaction := range p.Interactions {
+		interaction.specificationVersion = p.SpecificationVersion
+		interaction.explicitBodies = p.ExplicitBodies
+		interaction.validateExamples = p.ValidateExamples
+
+		problems, fieldErrs := interaction.validate()
+		if p.StrictValidation {
+			fieldErrs = append(problems, fieldErrs...)
+		} else if len(problems) > 0 {
+			log.Println("[WARN]", &ValidationError{Description: interaction.Description, Errors: problems})
+		}
+		if len(fieldErrs) > 0 {
+			err := &ValidationError{Description: interaction.Description, Errors: fieldErrs}
+			if first == nil {
+				first = err
+			}
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 1 {
+		return fmt.Errorf("%d interactions are invalid:\n%s", len(errs), strings.Join(errs, "\n"))
+	}
+
+	return first
+}
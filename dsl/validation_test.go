@@ -0,0 +1,125 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestInteraction_Validate(t *testing.T) {
+	i := (&Interaction{}).
+		UponReceiving("a request for a user").
+		WithRequest(Request{Method: "GET", Path: String("/users/1")}).
+		WillRespondWith(Response{Status: 200, Body: Like(map[string]string{"name": "billy"})})
+
+	if err := i.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestInteraction_ValidateErrors(t *testing.T) {
+	i := (&Interaction{}).
+		WithRequest(Request{
+			Method:        "get",
+			Path:          String("/users/1"),
+			Headers:       MapMatcher{"X-Trace": String("a\r\nInjected: header")},
+			Body:          map[string]string{"name": "billy"},
+			MatchingRules: MatchingRules{"$.query.id": Rule{"match": "integer"}},
+		}).
+		WillRespondWith(Response{
+			Status:        204,
+			Body:          "unexpected",
+			MatchingRules: MatchingRules{"$.body.id": Rule{"match": "fuzzy"}},
+		})
+
+	err := i.Validate()
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+
+	want := []FieldError{
+		{Field: "description", Message: "is required, see UponReceiving"},
+		{Field: "request.body", Message: "a GET request should not have a body, use NoBody"},
+		{Field: "response.body", Message: "a 204 response should not have a body, use NoBody"},
+		{Field: "request.headers.X-Trace", Message: "contains a newline"},
+		{Field: "request.matchingRules[$.query.id]", Message: "the integer matcher requires pact specification version 3, the pact is version 2"},
+		{Field: "response.matchingRules[$.body.id]", Message: `unknown matcher "fuzzy"`},
+	}
+	if len(validationErr.Errors) != len(want) {
+		t.Fatalf("want %v, got %v", want, validationErr.Errors)
+	}
+	for n := range want {
+		if validationErr.Errors[n] != want[n] {
+			t.Fatalf("want %v, got %v", want[n], validationErr.Errors[n])
+		}
+	}
+
+	i.specificationVersion = 3
+	i.Description = "a request"
+	i.Request.Body = nil
+	i.Request.Headers = nil
+	i.Response.Status = 200
+	i.Response.MatchingRules = nil
+	if err = i.Validate(); err != nil {
+		t.Fatalf("expected version 3 matchers to be allowed in a version 3 pact, got %v", err)
+	}
+
+	i.Response.Status = 0
+	i.Request.Path = nil
+	if err = i.Validate(); err == nil || !strings.Contains(err.Error(), "request.path: is required\n\tresponse.status: is required") {
+		t.Fatalf("expected missing fields to be reported, got %v", err)
+	}
+}
+
+func TestPact_VerifyValidatesInteractions(t *testing.T) {
+	ms := setupMockServer(true, t)
+	defer ms.Close()
+
+	pact := &Pact{Server: &types.MockServer{Port: getPort(ms.URL)}, StrictValidation: true}
+	pact.AddInteraction().UponReceiving("first").WithRequest(Request{Method: "GET", Path: String("/")})
+	pact.AddInteraction().UponReceiving("second").WillRespondWith(Response{Status: 200})
+
+	called := false
+	err := pact.Verify(func() error {
+		called = true
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "2 interactions are invalid") || !strings.Contains(err.Error(), "interaction 'second' is invalid") {
+		t.Fatalf("expected both invalid interactions to be reported, got %v", err)
+	}
+	if called {
+		t.Fatal("expected the test not to run")
+	}
+	if len(pact.Interactions) != 0 {
+		t.Fatal("expected the invalid interactions to be cleared")
+	}
+}
+
+func TestPact_VerifyWarnsOfInvalidInteractions(t *testing.T) {
+	ms := setupMockServer(true, t)
+	defer ms.Close()
+
+	pact := &Pact{Server: &types.MockServer{Port: getPort(ms.URL)}}
+	pact.AddInteraction().UponReceiving("first").WithRequest(Request{Method: "GET", Path: String("/")})
+
+	var err error
+	res := captureOutput(func() {
+		err = pact.Verify(func() error { return nil })
+	})
+	if err != nil {
+		t.Fatalf("expected problems the mock server tolerates not to fail, got %v", err)
+	}
+	if !strings.Contains(res, "[WARN] interaction 'first' is invalid:\n\tresponse.status: is required") {
+		t.Fatalf("expected a warning, got %s", res)
+	}
+
+	pact.AddInteraction().
+		UponReceiving("second").
+		WithRequest(Request{Method: "GET", Path: String("/"), MatchingRules: MatchingRules{"$.unknown": Rule{"match": "type"}}}).
+		WillRespondWith(Response{Status: 200})
+	if err = pact.Verify(func() error { return nil }); err == nil || !strings.Contains(err.Error(), "request.matchingRules") {
+		t.Fatalf("expected invalid matching rules to fail, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package dsl
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// VerificationRunner is a long-running service that verifies the provider
+// against each pact as it changes, triggered by Pact Broker webhooks, and
+// publishes the results. Verifications are run one at a time, in the order
+// the webhooks arrive, and a pact queued more than once is verified once.
+//
+// Configure a webhook for the "contract_content_changed" event with
+// WebhookEventTemplate as its body, and serve Handler, or call ListenAndServe.
+type VerificationRunner struct {
+	// Pact verifies the provider
+	Pact *Pact
+
+	// Request is the verification to run for each pact. Its PactURLs are
+	// replaced by the pact that changed, and results are published unless
+	// NoPublish is set.
+	Request types.VerifyRequest
+
+	// NoPublish disables publishing of verification results
+	NoPublish bool
+
+	// Webhook authenticates the webhooks. Its Handle function is replaced,
+	// and Events defaults to ContractContentChanged.
+	Webhook WebhookHandler
+
+	// OnResult, if set, is called with the outcome of each verification
+	OnResult func(WebhookEvent, types.VerificationResult)
+
+	// verify runs a verification, defaulting to Pact.VerifyProviderRaw
+	verify func(types.VerifyRequest) ([]types.ProviderVerifierResponse, error)
+
+	mu      sync.Mutex
+	queue   []WebhookEvent
+	pending map[string]bool
+	wake    chan struct{}
+}
+
+// Handler returns the webhook handler, which queues a verification for each
+// event. Run must be running to process the queue.
+func (r *VerificationRunner) Handler() http.Handler {
+	handler := r.Webhook
+	if len(handler.Events) == 0 {
+		handler.Events = []WebhookEventName{ContractContentChanged}
+	}
+	handler.Handle = r.enqueue
+
+	return handler
+}
+
+// Run verifies queued pacts until stop is closed
+func (r *VerificationRunner) Run(stop <-chan struct{}) {
+	wake := r.init()
+	for {
+		for {
+			event, ok := r.next()
+			if !ok {
+				break
+			}
+			r.run(event)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-wake:
+		}
+	}
+}
+
+// ListenAndServe serves the webhook handler on the address, verifying pacts
+// until the server fails
+func (r *VerificationRunner) ListenAndServe(addr string) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go r.Run(stop)
+
+	log.Println("[INFO] listening for pact webhooks on", addr)
+	return http.ListenAndServe(addr, r.Handler())
+}
+
+func (r *VerificationRunner) init() chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.wake == nil {
+		r.wake = make(chan struct{}, 1)
+	}
+	if r.pending == nil {
+		r.pending = make(map[string]bool)
+	}
+
+	return r.wake
+}
+
+// enqueue queues a verification of the event's pact, unless one is already
+// queued
+func (r *VerificationRunner) enqueue(event WebhookEvent) error {
+	wake := r.init()
+
+	r.mu.Lock()
+	if !r.pending[event.PactURL] {
+		r.pending[event.PactURL] = true
+		r.queue = append(r.queue, event)
+		log.Printf("[INFO] queued verification of %s", event.PactURL)
+	}
+	r.mu.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (r *VerificationRunner) next() (WebhookEvent, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.queue) == 0 {
+		return WebhookEvent{}, false
+	}
+	event := r.queue[0]
+	r.queue = r.queue[1:]
+	delete(r.pending, event.PactURL)
+
+	return event, true
+}
+
+// run verifies the event's pact and reports the result
+func (r *VerificationRunner) run(event WebhookEvent) {
+	request := event.VerifyRequest(r.Request)
+	request.PublishVerificationResults = !r.NoPublish
+
+	verify := r.verify
+	if verify == nil {
+		verify = r.Pact.VerifyProviderRaw
+	}
+
+	log.Printf("[INFO] verifying %s (%s %s)", event.PactURL, event.ConsumerName, event.ConsumerVersionNumber)
+	res, err := verify(request)
+	result := types.NewVerificationResult(res, err)
+	if err != nil {
+		log.Printf("[WARN] verification of %s %s: %v", event.PactURL, result.Status, err)
+	} else {
+		log.Printf("[INFO] verification of %s %s", event.PactURL, result.Status)
+	}
+
+	if r.OnResult != nil {
+		r.OnResult(event, result)
+	}
+}
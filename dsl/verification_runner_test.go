@@ -0,0 +1,90 @@
+package dsl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestVerificationRunner(t *testing.T) {
+	verified := make(chan types.VerifyRequest, 10)
+	results := make(chan types.VerificationResult, 10)
+	release := make(chan struct{})
+
+	runner := &VerificationRunner{
+		Request: types.VerifyRequest{
+			ProviderBaseURL: "http://localhost:8000",
+			BrokerURL:       "http://broker",
+			Tags:            []string{"main"},
+		},
+		Webhook: WebhookHandler{Token: "token"},
+		OnResult: func(event WebhookEvent, result types.VerificationResult) {
+			results <- result
+		},
+	}
+	runner.verify = func(request types.VerifyRequest) ([]types.ProviderVerifierResponse, error) {
+		<-release
+		verified <- request
+		if request.PactURLs[0] == "http://broker/pacts/broken" {
+			return nil, fmt.Errorf("verification failed")
+		}
+		return []types.ProviderVerifierResponse{{}}, nil
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go runner.Run(stop)
+
+	handler := runner.Handler()
+	send := func(event string, pactURL string) int {
+		body := fmt.Sprintf(`{"eventName":%q,"pactUrl":%q,"providerName":"bobby"}`, event, pactURL)
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// The first verification blocks, so the rest queue behind it
+	for _, pactURL := range []string{"http://broker/pacts/1", "http://broker/pacts/broken", "http://broker/pacts/broken"} {
+		if status := send("contract_content_changed", pactURL); status != http.StatusAccepted {
+			t.Fatalf("want status 202, got %d", status)
+		}
+	}
+	send("contract_published", "http://broker/pacts/ignored")
+	close(release)
+
+	var requests []types.VerifyRequest
+	var statuses []types.Status
+	for i := 0; i < 2; i++ {
+		select {
+		case request := <-verified:
+			requests = append(requests, request)
+			statuses = append(statuses, (<-results).Status)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for verification %d", i+1)
+		}
+	}
+
+	if requests[0].PactURLs[0] != "http://broker/pacts/1" || requests[1].PactURLs[0] != "http://broker/pacts/broken" {
+		t.Fatalf("unexpected verifications %v, %v", requests[0].PactURLs, requests[1].PactURLs)
+	}
+	request := requests[0]
+	if request.BrokerURL != "" || request.Tags != nil || !request.PublishVerificationResults || request.Provider != "bobby" {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	if statuses[0] != types.StatusPassed || statuses[1] != types.StatusFailed {
+		t.Fatalf("want statuses passed and failed, got %v", statuses)
+	}
+
+	select {
+	case request := <-verified:
+		t.Fatalf("unexpected verification of %v", request.PactURLs)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
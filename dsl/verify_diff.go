@@ -0,0 +1,198 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// VerificationDiff compares the verification of the same pacts against two
+// versions of a provider, e.g. the version in production (the baseline) and
+// a release candidate
+type VerificationDiff struct {
+	Baseline  []types.ProviderVerifierResponse
+	Candidate []types.ProviderVerifierResponse
+
+	// Differences are the examples whose result differs between the two,
+	// ordered by consumer and description
+	Differences []VerificationDifference
+}
+
+// VerificationDifference is an example whose result differs between the
+// baseline and candidate provider versions
+type VerificationDifference struct {
+	Consumer    string
+	Description string
+
+	// Baseline and Candidate are the example's status against each version
+	// e.g. "passed", "failed" or "pending", or "missing" if it wasn't run
+	Baseline  string
+	Candidate string
+
+	// Message explains the failure, of the candidate if it failed,
+	// otherwise of the baseline
+	Message string
+}
+
+// Regression determines if the example passed against the baseline, but not
+// the candidate
+func (d VerificationDifference) Regression() bool {
+	return d.Baseline == "passed" && d.Candidate != "passed"
+}
+
+func (d VerificationDifference) String() string {
+	s := fmt.Sprintf("%s: %s: %s -> %s", d.Consumer, d.Description, d.Baseline, d.Candidate)
+	if d.Message != "" {
+		s += ": " + d.Message
+	}
+
+	return s
+}
+
+// Regressions returns the examples that passed against the baseline, but not
+// the candidate
+func (d VerificationDiff) Regressions() []VerificationDifference {
+	var regressions []VerificationDifference
+	for _, difference := range d.Differences {
+		if difference.Regression() {
+			regressions = append(regressions, difference)
+		}
+	}
+
+	return regressions
+}
+
+// String reports the differences, one per line
+func (d VerificationDiff) String() string {
+	if len(d.Differences) == 0 {
+		return "the baseline and candidate verification results are the same"
+	}
+
+	lines := make([]string, len(d.Differences))
+	for n, difference := range d.Differences {
+		lines[n] = difference.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// VerifyProviderDiff verifies the pacts selected by the request against the
+// baseline and candidate provider base URLs in turn, and reports the
+// examples whose result differs, to decide whether the candidate is safe to
+// roll out beyond whether it passes. The request's ProviderBaseURL is
+// ignored, and results are never published. An error is returned only if
+// either verification could not run.
+func (p *Pact) VerifyProviderDiff(request types.VerifyRequest, baseline string, candidate string) (VerificationDiff, error) {
+	if baseline == "" || candidate == "" {
+		return VerificationDiff{}, errors.New("both the baseline and candidate provider base URLs must be given")
+	}
+
+	request.PublishVerificationResults = false
+	request.SummaryFile = ""
+
+	verify := func(name string, baseURL string) ([]types.ProviderVerifierResponse, error) {
+		log.Printf("[INFO] verifying the %s provider at %s", name, baseURL)
+		versionRequest := request
+		versionRequest.ProviderBaseURL = baseURL
+
+		res, err := p.VerifyProviderRaw(versionRequest)
+		if err != nil && len(res) == 0 {
+			return nil, fmt.Errorf("unable to verify the %s provider at %s: %v", name, baseURL, err)
+		}
+
+		return res, nil
+	}
+
+	var diff VerificationDiff
+	var err error
+	if diff.Baseline, err = verify("baseline", baseline); err != nil {
+		return diff, err
+	}
+	if diff.Candidate, err = verify("candidate", candidate); err != nil {
+		return diff, err
+	}
+	diff.Differences = diffVerifications(diff.Baseline, diff.Candidate)
+
+	return diff, nil
+}
+
+// verifiedExample is the result of an example, identified by its pact and
+// full description
+type verifiedExample struct {
+	consumer    string
+	description string
+	status      string
+	message     string
+}
+
+// diffVerifications compares the results of the examples of two
+// verifications
+func diffVerifications(baseline, candidate []types.ProviderVerifierResponse) []VerificationDifference {
+	examples := func(responses []types.ProviderVerifierResponse) map[string]verifiedExample {
+		results := map[string]verifiedExample{}
+		for _, response := range responses {
+			for _, example := range response.Examples {
+				key := example.Pact.ConsumerName + "\x00" + example.FullDescription
+				results[key] = verifiedExample{
+					consumer:    example.Pact.ConsumerName,
+					description: example.FullDescription,
+					status:      example.Status,
+					message:     example.Exception.Message,
+				}
+			}
+		}
+		return results
+	}
+	before, after := examples(baseline), examples(candidate)
+
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var differences []VerificationDifference
+	for _, key := range sortedStrings(keys) {
+		b, inBaseline := before[key]
+		c, inCandidate := after[key]
+		if !inBaseline {
+			b = verifiedExample{consumer: c.consumer, description: c.description, status: "missing"}
+		}
+		if !inCandidate {
+			c = verifiedExample{consumer: b.consumer, description: b.description, status: "missing"}
+		}
+		if b.status == c.status {
+			continue
+		}
+
+		message := c.message
+		if message == "" {
+			message = b.message
+		}
+		differences = append(differences, VerificationDifference{
+			Consumer:    b.consumer,
+			Description: b.description,
+			Baseline:    b.status,
+			Candidate:   c.status,
+			Message:     message,
+		})
+	}
+
+	return differences
+}
+
+func sortedStrings(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
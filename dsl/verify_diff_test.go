@@ -0,0 +1,125 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func verifierResponse(t *testing.T, examples string) types.ProviderVerifierResponse {
+	var res types.ProviderVerifierResponse
+	if err := json.Unmarshal([]byte(`{"examples":[`+examples+`]}`), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+func TestDiffVerifications(t *testing.T) {
+	baseline := verifierResponse(t, `
+		{"full_description":"a request for foo has status 200","status":"passed","pact":{"consumer_name":"web"}},
+		{"full_description":"a request for bar has status 200","status":"failed","exception":{"message":"expected 200, got 500"},"pact":{"consumer_name":"web"}},
+		{"full_description":"a request for baz has status 200","status":"passed","pact":{"consumer_name":"web"}},
+		{"full_description":"a request for foo has status 200","status":"passed","pact":{"consumer_name":"mobile"}}`)
+	candidate := verifierResponse(t, `
+		{"full_description":"a request for foo has status 200","status":"failed","exception":{"message":"expected 200, got 404"},"pact":{"consumer_name":"web"}},
+		{"full_description":"a request for bar has status 200","status":"passed","pact":{"consumer_name":"web"}},
+		{"full_description":"a request for foo has status 200","status":"passed","pact":{"consumer_name":"mobile"}}`)
+
+	differences := diffVerifications([]types.ProviderVerifierResponse{baseline}, []types.ProviderVerifierResponse{candidate})
+
+	expected := []VerificationDifference{
+		{Consumer: "web", Description: "a request for bar has status 200", Baseline: "failed", Candidate: "passed", Message: "expected 200, got 500"},
+		{Consumer: "web", Description: "a request for baz has status 200", Baseline: "passed", Candidate: "missing"},
+		{Consumer: "web", Description: "a request for foo has status 200", Baseline: "passed", Candidate: "failed", Message: "expected 200, got 404"},
+	}
+	if !reflect.DeepEqual(differences, expected) {
+		t.Fatalf("want %+v, got %+v", expected, differences)
+	}
+
+	diff := VerificationDiff{Differences: differences}
+	if regressions := diff.Regressions(); len(regressions) != 2 || regressions[0].Candidate != "missing" {
+		t.Fatalf("expected the missing and failed examples to be regressions, got %+v", regressions)
+	}
+	if want := "web: a request for foo has status 200: passed -> failed: expected 200, got 404"; differences[2].String() != want {
+		t.Fatalf("want %q, got %q", want, differences[2].String())
+	}
+}
+
+// probingVerifierClient verifies a single example by requesting /foobar from
+// the provider
+type probingVerifierClient struct {
+	*mockClient
+	requests []types.VerifyRequest
+}
+
+func (c *probingVerifierClient) VerifyProvider(request types.VerifyRequest) ([]types.ProviderVerifierResponse, error) {
+	c.requests = append(c.requests, request)
+
+	var res *http.Response
+	var err error
+	for attempt := 0; attempt < 50; attempt++ {
+		if res, err = http.Get(request.ProviderBaseURL + "/foobar"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	status := "passed"
+	if res.StatusCode != 200 {
+		status = "failed"
+	}
+	var response types.ProviderVerifierResponse
+	json.Unmarshal([]byte(fmt.Sprintf(`{"examples":[{"full_description":"a request for foobar has status 200","status":"%s","exception":{"message":"got %d"},"pact":{"consumer_name":"web"}}]}`, status, res.StatusCode)), &response) // nolint:errcheck
+	if status == "failed" {
+		return []types.ProviderVerifierResponse{response}, fmt.Errorf("1 example failed")
+	}
+
+	return []types.ProviderVerifierResponse{response}, nil
+}
+
+func TestPact_VerifyProviderDiff(t *testing.T) {
+	production := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer production.Close()
+	candidate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer candidate.Close()
+	defer stubPorts()()
+
+	c := &probingVerifierClient{mockClient: newMockClient()}
+	pact := &Pact{LogLevel: "DEBUG", pactClient: c}
+
+	diff, err := pact.VerifyProviderDiff(types.VerifyRequest{
+		PactURLs:                   []string{"foo.json"},
+		PublishVerificationResults: true,
+		ProviderVersion:            "1.0.0",
+	}, production.URL, candidate.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	regressions := diff.Regressions()
+	if len(regressions) != 1 || regressions[0].Message != "got 404" || len(diff.Baseline) != 1 || len(diff.Candidate) != 1 {
+		t.Fatalf("expected the candidate's failure to be a regression, got %+v", diff)
+	}
+	for _, request := range c.requests {
+		if request.PublishVerificationResults {
+			t.Fatal("expected the results not to be published")
+		}
+	}
+
+	if _, err = pact.VerifyProviderDiff(types.VerifyRequest{PactURLs: []string{"foo.json"}}, production.URL, ""); err == nil {
+		t.Fatal("expected an error without a candidate")
+	}
+}
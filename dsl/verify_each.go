@@ -0,0 +1,36 @@
+package dsl
+
+import (
+	"testing"
+)
+
+// VerifyEach verifies each registered interaction on its own, as a subtest
+// named after its description: the mock server is set up with just that
+// interaction, the test is run against it and the interaction is verified.
+// A failure is reported against the interaction it concerns, and -run
+// selects interactions by description e.g.
+//
+//	go test -run 'TestConsumer/a_request_for_user_1'
+//
+// The test is run once per interaction, and is given the interaction so it
+// can make the matching request, e.g. by switching on its Description.
+// Interactions are cleared once verified, like Verify. The subtests must not
+// be run in parallel, as they share the mock server.
+func (p *Pact) VerifyEach(t *testing.T, test func(t *testing.T, interaction *Interaction) error) {
+	t.Helper()
+
+	interactions := p.Interactions
+	p.Interactions = make([]*Interaction, 0)
+
+	for _, interaction := range interactions {
+		interaction := interaction
+		t.Run(interaction.Description, func(t *testing.T) {
+			p.Interactions = []*Interaction{interaction}
+			if err := p.Verify(func() error { return test(t, interaction) }); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	p.Interactions = make([]*Interaction, 0)
+}
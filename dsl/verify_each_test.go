@@ -0,0 +1,74 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestPact_VerifyEach(t *testing.T) {
+	// Descriptions of the interactions registered with the mock server, for
+	// each verification
+	var registered [][]string
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") == "" {
+			fmt.Fprint(w, "{}")
+			return
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/interactions":
+			var interaction struct {
+				Description string `json:"description"`
+			}
+			json.NewDecoder(r.Body).Decode(&interaction) // nolint:errcheck
+			registered[len(registered)-1] = append(registered[len(registered)-1], interaction.Description)
+		case r.Method == "DELETE":
+			registered = append(registered, nil)
+		}
+		fmt.Fprintln(w, "ok")
+	}))
+	defer ms.Close()
+	registered = append(registered, nil)
+
+	pact := &Pact{
+		Server:   &types.MockServer{Port: getPort(ms.URL)},
+		Consumer: "My Consumer",
+		Provider: "My Provider",
+	}
+	for _, id := range []string{"1", "2"} {
+		pact.
+			AddInteraction().
+			UponReceiving("a request for user " + id).
+			WithRequest(Request{Method: "GET", Path: String("/users/" + id)}).
+			WillRespondWith(Response{Status: 200})
+	}
+
+	var requested []string
+	pact.VerifyEach(t, func(t *testing.T, interaction *Interaction) error {
+		if !strings.HasSuffix(t.Name(), strings.Replace(interaction.Description, " ", "_", -1)) {
+			t.Fatalf("expected the subtest to be named after '%s', got %s", interaction.Description, t.Name())
+		}
+		path := "/users/" + strings.TrimPrefix(interaction.Description, "a request for user ")
+		res, err := http.Get(fmt.Sprintf("http://localhost:%d%s", pact.Server.Port, path))
+		if err != nil {
+			return err
+		}
+		requested = append(requested, path)
+		return res.Body.Close()
+	})
+
+	if strings.Join(requested, ",") != "/users/1,/users/2" {
+		t.Fatalf("expected the test to be run for each interaction, got %v", requested)
+	}
+	if len(registered) < 2 || strings.Join(registered[0], ",") != "a request for user 1" || strings.Join(registered[1], ",") != "a request for user 2" {
+		t.Fatalf("expected each interaction to be verified on its own, got %v", registered)
+	}
+	if len(pact.Interactions) != 0 {
+		t.Fatalf("expected the interactions to be cleared, got %d", len(pact.Interactions))
+	}
+}
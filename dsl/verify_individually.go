@@ -0,0 +1,88 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// individualPacts lists the pacts to verify one at a time: the PactURLs
+// and, if a BrokerURL is given, the latest pacts the broker selects for the
+// provider (for each of the Tags, if any), with the work in progress pacts
+// since IncludeWIPPactsSince, marked pending if EnablePending is set. Only the URLs are fetched, each pact is read by the verifier in
+// turn.
+func individualPacts(request types.VerifyRequest) ([]PactForVerification, error) {
+	switch {
+	case len(request.ConsumerVersionSelectors) > 0:
+		return nil, errors.New("'VerifyPactsIndividually' is not supported with 'ConsumerVersionSelectors'")
+	case request.UseFFI:
+		return nil, errors.New("'VerifyPactsIndividually' is not supported with 'UseFFI'")
+	}
+
+	pacts := make([]PactForVerification, 0, len(request.PactURLs))
+	for _, pactURL := range request.PactURLs {
+		pacts = append(pacts, PactForVerification{URL: pactURL})
+	}
+	if request.BrokerURL != "" {
+		if request.Provider == "" {
+			return nil, errors.New("'Provider' must be supplied if 'BrokerURL' given")
+		}
+		brokerPacts, err := pactsForVerification(request)
+		if err != nil {
+			return nil, err
+		}
+		pacts = append(pacts, brokerPacts...)
+	}
+
+	if len(pacts) == 0 && request.FailIfNoPactsFound {
+		return nil, errors.New("no pacts found to verify")
+	}
+
+	return pacts, nil
+}
+
+// verifyPactsIndividually runs the verifier for each pact in turn, so that
+// only one is held in memory at a time. Every pact is verified even if some
+// fail, with the failures reported together. The failures of pending pacts
+// are reported as pending, and don't fail verification.
+func (p *Pact) verifyPactsIndividually(request types.VerifyRequest, pacts []PactForVerification) ([]types.ProviderVerifierResponse, error) {
+	res := make([]types.ProviderVerifierResponse, 0, len(pacts))
+	var failures []string
+
+	for n, pact := range pacts {
+		log.Printf("[INFO] verifying pact %d of %d: %s", n+1, len(pacts), pact.URL)
+
+		pactRequest := request
+		pactRequest.PactURLs = []string{pact.URL}
+		pactRequest.BrokerURL = ""
+		pactRequest.Tags = nil
+		pactRequest.EnablePending = false
+		pactRequest.IncludeWIPPactsSince = nil
+
+		responses, err := p.pactClient.VerifyProvider(pactRequest)
+		if pact.Pending || pact.WIP {
+			if markPending(responses, pact.WIP) && err != nil {
+				kind := "pending"
+				if pact.WIP {
+					kind = "work in progress"
+				}
+				log.Printf("[WARN] %s pact %d of %d failed verification, which does not fail the build: %s", kind, n+1, len(pacts), pact.URL)
+				err = nil
+			}
+		}
+		res = append(res, responses...)
+		if err != nil {
+			log.Printf("[INFO] pact %d of %d failed verification: %s", n+1, len(pacts), pact.URL)
+			failures = append(failures, fmt.Sprintf("%s: %v", pact.URL, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return res, fmt.Errorf("%d of %d pacts failed verification:\n%s", len(failures), len(pacts), strings.Join(failures, "\n"))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,155 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// recordingVerifierClient records each verification request, failing those
+// for the given pact
+type recordingVerifierClient struct {
+	*mockClient
+	requests []types.VerifyRequest
+	failing  string
+}
+
+func (c *recordingVerifierClient) VerifyProvider(request types.VerifyRequest) ([]types.ProviderVerifierResponse, error) {
+	c.requests = append(c.requests, request)
+	if request.PactURLs[0] == c.failing {
+		var res types.ProviderVerifierResponse
+		json.Unmarshal([]byte(`{"examples":[{"status":"passed"},{"status":"failed","pact":{"url":"`+c.failing+`"}}]}`), &res) // nolint:errcheck
+		return []types.ProviderVerifierResponse{res}, errors.New("1 example failed")
+	}
+
+	return []types.ProviderVerifierResponse{{}}, nil
+}
+
+func TestPact_VerifyPactsIndividually(t *testing.T) {
+	s := setupMockBroker(false)
+	defer s.Close()
+	defer stubPorts()()
+
+	c := &recordingVerifierClient{mockClient: newMockClient()}
+	c.failing = s.URL + "/pacts/provider/bobby/consumer/jessica/version/2.0.0"
+	pact := &Pact{LogLevel: "DEBUG", pactClient: c, Provider: "bobby"}
+
+	res, err := pact.VerifyProviderRaw(types.VerifyRequest{
+		ProviderBaseURL:         "http://www.foo.com",
+		PactURLs:                []string{"foo.json"},
+		BrokerURL:               s.URL,
+		ProviderVersion:         "1.0.0",
+		VerifyPactsIndividually: true,
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 pacts failed verification:\n"+c.failing+": 1 example failed") {
+		t.Fatalf("expected the failing pact to be reported, got %v", err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("expected a response for each pact, got %v", res)
+	}
+
+	want := []string{
+		"foo.json",
+		s.URL + "/pacts/provider/bobby/consumer/jessica/version/2.0.0",
+		s.URL + "/pacts/provider/loginprovider/consumer/jmarie/version/1.0.0",
+	}
+	if len(c.requests) != len(want) {
+		t.Fatalf("expected a verification for each pact, got %d", len(c.requests))
+	}
+	for n, request := range c.requests {
+		if len(request.PactURLs) != 1 || request.PactURLs[0] != want[n] || request.BrokerURL != "" {
+			t.Fatalf("expected pact %s to be verified alone, got %+v", want[n], request)
+		}
+	}
+}
+
+func TestPact_VerifyPactsIndividuallyPending(t *testing.T) {
+	s := setupMockBroker(false)
+	defer s.Close()
+	defer stubPorts()()
+
+	c := &recordingVerifierClient{mockClient: newMockClient()}
+	c.failing = s.URL + "/pacts/provider/bobby/consumer/jessica/version/2.0.0"
+	pact := &Pact{LogLevel: "DEBUG", pactClient: c, Provider: "bobby"}
+
+	res, err := pact.VerifyProviderRaw(types.VerifyRequest{
+		ProviderBaseURL:         "http://www.foo.com",
+		BrokerURL:               s.URL,
+		ProviderVersion:         "1.0.0",
+		EnablePending:           true,
+		VerifyPactsIndividually: true,
+	})
+	if err != nil {
+		t.Fatalf("expected the pending pact not to fail verification, got %v", err)
+	}
+	if len(res) != 2 || len(res[0].Examples) != 2 {
+		t.Fatalf("expected a response for each pact, got %+v", res)
+	}
+	for _, example := range res[0].Examples {
+		if !example.Pact.Pending {
+			t.Fatalf("expected the examples of the pending pact to be marked pending, got %+v", example)
+		}
+	}
+	if res[0].Examples[0].Status != "passed" || res[0].Examples[1].Status != "pending" {
+		t.Fatalf("expected the failure to be reported as pending, got %+v", res[0].Examples)
+	}
+	if result := types.NewVerificationResult(res, err); result.Status != types.StatusFailedPendingOnly {
+		t.Fatalf("expected only pending failures, got %+v", result)
+	}
+	for _, request := range c.requests {
+		if request.EnablePending {
+			t.Fatalf("expected each pact to be verified without the broker, got %+v", request)
+		}
+	}
+}
+
+func TestPact_VerifyPactsIndividuallyWIP(t *testing.T) {
+	s := setupMockBroker(false)
+	defer s.Close()
+	defer stubPorts()()
+
+	c := &recordingVerifierClient{mockClient: newMockClient()}
+	c.failing = s.URL + "/pacts/provider/loginprovider/consumer/jmarie/version/1.0.1"
+	pact := &Pact{LogLevel: "DEBUG", pactClient: c, Provider: "bobby"}
+
+	since := time.Now()
+	res, err := pact.VerifyProviderRaw(types.VerifyRequest{
+		ProviderBaseURL:         "http://www.foo.com",
+		BrokerURL:               s.URL,
+		ProviderVersion:         "1.0.0",
+		IncludeWIPPactsSince:    &since,
+		VerifyPactsIndividually: true,
+	})
+	if err != nil {
+		t.Fatalf("expected the work in progress pact not to fail verification, got %v", err)
+	}
+	if len(res) != 3 || len(res[2].Examples) != 2 || !res[2].Examples[1].Pact.WIP || res[2].Examples[1].Status != "pending" {
+		t.Fatalf("expected the failure of the work in progress pact to be pending, got %+v", res)
+	}
+	if result := types.NewVerificationResult(res, err); result.WIP != 1 || result.Pending != 1 {
+		t.Fatalf("expected a pending work in progress failure, got %+v", result)
+	}
+	for _, request := range c.requests {
+		if request.IncludeWIPPactsSince != nil {
+			t.Fatalf("expected each pact to be verified without the broker, got %+v", request)
+		}
+	}
+}
+
+func TestIndividualPacts_Errors(t *testing.T) {
+	for _, request := range []types.VerifyRequest{
+		{PactURLs: []string{"foo.json"}, ConsumerVersionSelectors: []types.ConsumerVersionSelector{{Tag: "prod"}}},
+		{PactURLs: []string{"foo.json"}, UseFFI: true},
+		{BrokerURL: "http://broker"},
+		{FailIfNoPactsFound: true},
+	} {
+		if _, err := individualPacts(request); err == nil {
+			t.Fatalf("expected an error for %+v", request)
+		}
+	}
+}
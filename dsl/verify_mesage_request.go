@@ -32,30 +32,78 @@ type VerifyMessageRequest struct {
 	// BrokerToken is required when authenticating using the Bearer token mechanism
 	BrokerToken string
 
+	// BrokerAuthenticator authenticates requests to the broker in place of
+	// the token, or username and password. Optional.
+	BrokerAuthenticator types.Authenticator
+
 	// PublishVerificationResults to the Pact Broker.
 	PublishVerificationResults bool
 
 	// ProviderVersion is the semantical version of the Provider API.
 	ProviderVersion string
 
+	// VersionDetection, if given, detects the ProviderVersion and
+	// ProviderBranch from CI environment variables or git, unless they are
+	// given explicitly
+	VersionDetection *types.VersionDetection
+
 	// ProviderTags is the set of tags to apply to the provider application version when results are published to the broker
 	ProviderTags []string
 
+	// ProviderBranch is the branch of the provider application version when
+	// results are published to the broker
+	ProviderBranch string
+
 	// MessageHandlers contains a mapped list of message handlers for a provider
 	// that will be rable to produce the correct message format for a given
 	// consumer interaction
 	MessageHandlers MessageHandlers
 
+	// MessageProducers produce the contents and metadata of messages, by
+	// description, for native verification (see
+	// VerifyMessageProviderNative). They take precedence over the
+	// MessageHandlers. Optional.
+	MessageProducers MessageProducers
+
 	// StateHandlers contain a mapped list of message states to functions
 	// that are used to setup a given provider state prior to the message
 	// verification step.
 	StateHandlers StateHandlers
 
+	// MessageBroker, if given, round trips each message produced by the
+	// MessageHandlers through a real broker (e.g. a test Kafka or NATS topic)
+	// before it is verified. Optional.
+	MessageBroker MessageBroker
+
+	// SummaryFile is written with a JSON summary of the verification (see
+	// types.VerificationResult), for CI pipelines to gate on. Optional.
+	SummaryFile string
+
 	// Arguments to the VerificationProvider
 	// Deprecated: This will be deleted after the native library replaces Ruby deps.
 	Args []string
 }
 
+// verifyRequest is the verification request for the provider, with the
+// pacts, broker and publishing options of the message request
+func (v *VerifyMessageRequest) verifyRequest(provider string) types.VerifyRequest {
+	return types.VerifyRequest{
+		PactURLs:                   v.PactURLs,
+		BrokerURL:                  v.BrokerURL,
+		Tags:                       v.Tags,
+		ConsumerVersionSelectors:   v.ConsumerVersionSelectors,
+		BrokerUsername:             v.BrokerUsername,
+		BrokerPassword:             v.BrokerPassword,
+		BrokerToken:                v.BrokerToken,
+		BrokerAuthenticator:        v.BrokerAuthenticator,
+		PublishVerificationResults: v.PublishVerificationResults,
+		ProviderVersion:            v.ProviderVersion,
+		ProviderTags:               v.ProviderTags,
+		ProviderBranch:             v.ProviderBranch,
+		Provider:                   provider,
+	}
+}
+
 // Validate checks that the minimum fields are provided.
 // Deprecated: This map be deleted after the native library replaces Ruby deps,
 // and should not be used outside of this library.
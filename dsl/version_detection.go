@@ -0,0 +1,129 @@
+package dsl
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// gitBranchVariables are the environment variables CI systems set to the
+// branch being built, checked before asking git. The source branches of
+// pull requests are checked first.
+var gitBranchVariables = []string{
+	"GITHUB_HEAD_REF",
+	"GITHUB_REF",
+	"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME",
+	"CI_COMMIT_BRANCH",
+	"CIRCLE_BRANCH",
+	"TRAVIS_PULL_REQUEST_BRANCH",
+	"TRAVIS_BRANCH",
+	"BUILDKITE_BRANCH",
+	"BRANCH_NAME",
+	"GIT_BRANCH",
+}
+
+// DetectVersion detects the version and branch of the application being
+// built, from the environment variables set by common CI systems (e.g.
+// GITHUB_SHA and GITHUB_REF, or CI_COMMIT_SHA and CI_COMMIT_BRANCH) or else
+// the local git repository. Either is "" if it can't be detected.
+func DetectVersion(detection types.VersionDetection) (version string, branch string) {
+	version = firstVariable(detection.VersionVariables)
+	if version == "" && detection.Describe {
+		version = gitOutput("describe", "--tags", "--always")
+	}
+	if version == "" {
+		version = gitSHA()
+	}
+
+	branch = firstVariable(detection.BranchVariables)
+	for _, variable := range gitBranchVariables {
+		if branch != "" {
+			break
+		}
+		branch = branchFromVariable(variable, os.Getenv(variable))
+	}
+	if branch == "" {
+		// A detached HEAD, as CI systems often check out, has no branch
+		if branch = gitOutput("rev-parse", "--abbrev-ref", "HEAD"); branch == "HEAD" {
+			branch = ""
+		}
+	}
+
+	if version != "" {
+		var metadata []string
+		if detection.IncludeBranch && branch != "" {
+			metadata = append(metadata, strings.Replace(branch, "/", "-", -1))
+		}
+		if detection.MarkDirty && gitStatus() != "" {
+			metadata = append(metadata, "dirty")
+		}
+		if len(metadata) > 0 {
+			version += "+" + strings.Join(metadata, ".")
+		}
+	}
+
+	return version, branch
+}
+
+// gitStatus lists the uncommitted changes in the git working tree
+var gitStatus = func() string {
+	return gitOutput("status", "--porcelain")
+}
+
+// detectVersion sets the version and branch, unless already given, if
+// detection is configured
+func detectVersion(detection *types.VersionDetection, version *string, branch *string) {
+	if detection == nil || (*version != "" && (branch == nil || *branch != "")) {
+		return
+	}
+
+	detectedVersion, detectedBranch := DetectVersion(*detection)
+	if *version == "" {
+		*version = detectedVersion
+	}
+	if branch != nil && *branch == "" {
+		*branch = detectedBranch
+	}
+	log.Printf("[INFO] detected version '%s' and branch '%s'\n", detectedVersion, detectedBranch)
+}
+
+// branchFromVariable extracts the branch from the value of a CI variable,
+// which for some is a ref, or prefixed with the remote
+func branchFromVariable(variable string, value string) string {
+	switch variable {
+	case "GITHUB_REF":
+		// Tags and pull requests have refs of their own
+		if !strings.HasPrefix(value, "refs/heads/") {
+			return ""
+		}
+		return strings.TrimPrefix(value, "refs/heads/")
+	case "GIT_BRANCH":
+		return strings.TrimPrefix(value, "origin/")
+	}
+
+	return value
+}
+
+func firstVariable(variables []string) string {
+	for _, variable := range variables {
+		if value := os.Getenv(variable); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// gitOutput runs git, returning its trimmed output or "" if it fails
+func gitOutput(args ...string) string {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		log.Printf("[DEBUG] unable to run git %s: %v\n", strings.Join(args, " "), err)
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,123 @@
+package dsl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// setCIVariables clears the CI variables used for version detection, then
+// sets the given ones, returning a function restoring them
+func setCIVariables(variables map[string]string) func() {
+	saved := make(map[string]string)
+	names := append(append([]string{"PACT_TEST_VERSION", "PACT_TEST_BRANCH"}, gitSHAVariables...), gitBranchVariables...)
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			saved[name] = value
+		}
+		os.Unsetenv(name)
+	}
+	for name, value := range variables {
+		os.Setenv(name, value)
+	}
+
+	return func() {
+		for _, name := range names {
+			os.Unsetenv(name)
+		}
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+func TestDetectVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		variables map[string]string
+		detection types.VersionDetection
+		version   string
+		branch    string
+	}{
+		{
+			name:      "GitHub push",
+			variables: map[string]string{"GITHUB_SHA": "0a1b2c3", "GITHUB_REF": "refs/heads/feature/login"},
+			version:   "0a1b2c3",
+			branch:    "feature/login",
+		},
+		{
+			name:      "GitHub pull request",
+			variables: map[string]string{"GITHUB_SHA": "0a1b2c3", "GITHUB_REF": "refs/pull/12/merge", "GITHUB_HEAD_REF": "feature/login"},
+			version:   "0a1b2c3",
+			branch:    "feature/login",
+		},
+		{
+			name:      "GitLab",
+			variables: map[string]string{"CI_COMMIT_SHA": "4d5e6f7", "CI_COMMIT_BRANCH": "main"},
+			version:   "4d5e6f7",
+			branch:    "main",
+		},
+		{
+			name:      "Jenkins",
+			variables: map[string]string{"GIT_COMMIT": "8a9b0c1", "GIT_BRANCH": "origin/main"},
+			version:   "8a9b0c1",
+			branch:    "main",
+		},
+		{
+			name:      "configured variables",
+			variables: map[string]string{"GITHUB_SHA": "0a1b2c3", "GITHUB_REF": "refs/heads/main", "PACT_TEST_VERSION": "1.2.3", "PACT_TEST_BRANCH": "release"},
+			detection: types.VersionDetection{VersionVariables: []string{"PACT_TEST_VERSION"}, BranchVariables: []string{"PACT_TEST_BRANCH"}},
+			version:   "1.2.3",
+			branch:    "release",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setCIVariables(tt.variables)()
+
+			version, branch := DetectVersion(tt.detection)
+			if version != tt.version || branch != tt.branch {
+				t.Fatalf("want version %q and branch %q, got %q and %q", tt.version, tt.branch, version, branch)
+			}
+		})
+	}
+}
+
+func TestDetectVersion_Overridden(t *testing.T) {
+	defer setCIVariables(map[string]string{"GITHUB_SHA": "0a1b2c3", "GITHUB_REF": "refs/heads/main"})()
+
+	version, branch := "1.0.0", ""
+	detectVersion(&types.VersionDetection{}, &version, &branch)
+	if version != "1.0.0" || branch != "main" {
+		t.Fatalf("expected only the branch to be detected, got %q and %q", version, branch)
+	}
+
+	version, branch = "", ""
+	detectVersion(nil, &version, &branch)
+	if version != "" || branch != "" {
+		t.Fatalf("expected nothing to be detected without detection, got %q and %q", version, branch)
+	}
+}
+
+func TestDetectVersion_BuildMetadata(t *testing.T) {
+	defer setCIVariables(map[string]string{"GITHUB_SHA": "0a1b2c3", "GITHUB_REF": "refs/heads/feature/login"})()
+	defer func(status func() string) { gitStatus = status }(gitStatus)
+
+	changes := ""
+	gitStatus = func() string { return changes }
+
+	detection := types.VersionDetection{IncludeBranch: true, MarkDirty: true}
+	if version, _ := DetectVersion(detection); version != "0a1b2c3+feature-login" {
+		t.Fatalf("expected the branch to be appended, got %q", version)
+	}
+
+	changes = " M dsl/pact.go"
+	if version, _ := DetectVersion(detection); version != "0a1b2c3+feature-login.dirty" {
+		t.Fatalf("expected the version to be marked dirty, got %q", version)
+	}
+	if version, _ := DetectVersion(types.VersionDetection{}); version != "0a1b2c3" {
+		t.Fatalf("expected no build metadata by default, got %q", version)
+	}
+}
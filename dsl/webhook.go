@@ -0,0 +1,245 @@
+package dsl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// WebhookEventName is the Pact Broker event that triggered a webhook, as
+// given by the ${pactbroker.eventName} placeholder
+type WebhookEventName string
+
+const (
+	// ContractContentChanged is sent when a pact is published with content
+	// that differs from the previous version
+	ContractContentChanged WebhookEventName = "contract_content_changed"
+
+	// ContractPublished is sent whenever a pact is published
+	ContractPublished WebhookEventName = "contract_published"
+
+	// ContractRequiringVerificationPublished is sent when a pact is published
+	// that needs verifying by a provider version
+	ContractRequiringVerificationPublished WebhookEventName = "contract_requiring_verification_published"
+
+	// ProviderVerificationPublished is sent whenever a verification result
+	// is published
+	ProviderVerificationPublished WebhookEventName = "provider_verification_published"
+
+	// ProviderVerificationSucceeded is sent when a successful verification
+	// result is published
+	ProviderVerificationSucceeded WebhookEventName = "provider_verification_succeeded"
+
+	// ProviderVerificationFailed is sent when a failed verification result is
+	// published
+	ProviderVerificationFailed WebhookEventName = "provider_verification_failed"
+)
+
+// WebhookEventTemplate is a webhook body for the Pact Broker that sends every
+// field of WebhookEvent
+const WebhookEventTemplate = `{
+  "eventName": "${pactbroker.eventName}",
+  "pactUrl": "${pactbroker.pactUrl}",
+  "verificationResultUrl": "${pactbroker.verificationResultUrl}",
+  "consumerName": "${pactbroker.consumerName}",
+  "consumerVersionNumber": "${pactbroker.consumerVersionNumber}",
+  "consumerVersionBranch": "${pactbroker.consumerVersionBranch}",
+  "consumerVersionTags": "${pactbroker.consumerVersionTags}",
+  "providerName": "${pactbroker.providerName}",
+  "providerVersionNumber": "${pactbroker.providerVersionNumber}",
+  "providerVersionBranch": "${pactbroker.providerVersionBranch}",
+  "providerVersionTags": "${pactbroker.providerVersionTags}"
+}`
+
+// WebhookEvent is the body of a Pact Broker webhook using
+// WebhookEventTemplate. Fields the broker has no value for are empty.
+type WebhookEvent struct {
+	EventName             WebhookEventName `json:"eventName"`
+	PactURL               string           `json:"pactUrl"`
+	VerificationResultURL string           `json:"verificationResultUrl"`
+	ConsumerName          string           `json:"consumerName"`
+	ConsumerVersionNumber string           `json:"consumerVersionNumber"`
+	ConsumerVersionBranch string           `json:"consumerVersionBranch"`
+	ConsumerVersionTags   string           `json:"consumerVersionTags"`
+	ProviderName          string           `json:"providerName"`
+	ProviderVersionNumber string           `json:"providerVersionNumber"`
+	ProviderVersionBranch string           `json:"providerVersionBranch"`
+	ProviderVersionTags   string           `json:"providerVersionTags"`
+}
+
+// Tags splits the comma separated consumer version tags
+func (e WebhookEvent) Tags() []string {
+	return splitTags(e.ConsumerVersionTags)
+}
+
+// ProviderTags splits the comma separated provider version tags
+func (e WebhookEvent) ProviderTags() []string {
+	return splitTags(e.ProviderVersionTags)
+}
+
+// VerifyRequest returns a copy of the request that verifies only the pact
+// that triggered the event, without selecting any other pacts from the
+// broker
+func (e WebhookEvent) VerifyRequest(request types.VerifyRequest) types.VerifyRequest {
+	request.PactURLs = []string{e.PactURL}
+	request.BrokerURL = ""
+	request.ConsumerVersionSelectors = nil
+	request.Tags = nil
+	request.EnablePending = false
+	request.IncludeWIPPactsSince = nil
+	if request.Provider == "" {
+		request.Provider = e.ProviderName
+	}
+
+	return request
+}
+
+// WebhookHandler is an http.Handler that receives Pact Broker webhooks,
+// authenticating each call and decoding its body into a WebhookEvent, so that
+// providers can verify pacts as soon as they change.
+//
+// Calls are rejected with 405 unless POSTed, 401 if not authenticated and 400
+// if the body isn't an event. If Handle returns an error the call fails with
+// a 500, and the broker will retry it.
+type WebhookHandler struct {
+	// Token, if set, must be sent as a bearer token in the Authorization
+	// header
+	Token string
+
+	// Secret, if set, is the key of the HMAC signature of the body that must
+	// be sent in SignatureHeader
+	Secret []byte
+
+	// SignatureHeader holds the signature. Defaults to "X-Pact-Signature"
+	SignatureHeader string
+
+	// SignaturePrefix precedes the hex encoded signature e.g. "sha256="
+	SignaturePrefix string
+
+	// Hash constructs the hash for the signature. Defaults to sha256.New
+	Hash func() hash.Hash
+
+	// Events, if set, are the only events passed to Handle. Other events are
+	// acknowledged and ignored.
+	Events []WebhookEventName
+
+	// Handle is called with each event
+	Handle func(WebhookEvent) error
+}
+
+func (h WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "webhooks must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read webhook: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authenticate(r, body); err != nil {
+		log.Println("[WARN] rejected webhook:", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event, err := ParseWebhookEvent(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.accepts(event.EventName) {
+		log.Printf("[DEBUG] ignoring webhook event '%s'", event.EventName)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if h.Handle != nil {
+		if err := h.Handle(event); err != nil {
+			log.Printf("[ERROR] unable to handle webhook event '%s': %v", event.EventName, err)
+			http.Error(w, fmt.Sprintf("unable to handle webhook: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ParseWebhookEvent decodes the body of a webhook using WebhookEventTemplate
+func ParseWebhookEvent(body []byte) (WebhookEvent, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return event, fmt.Errorf("webhook body is not a valid event: %v", err)
+	}
+	if event.EventName == "" {
+		return event, fmt.Errorf("webhook body has no eventName")
+	}
+
+	return event, nil
+}
+
+// authenticate checks the token and signature, if configured
+func (h WebhookHandler) authenticate(r *http.Request, body []byte) error {
+	if h.Token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) != 1 {
+			return fmt.Errorf("webhook has an invalid token")
+		}
+	}
+
+	if len(h.Secret) > 0 {
+		header := h.SignatureHeader
+		if header == "" {
+			header = "X-Pact-Signature"
+		}
+		signature := r.Header.Get(header)
+		if !strings.HasPrefix(signature, h.SignaturePrefix) {
+			return fmt.Errorf("webhook has an invalid signature")
+		}
+		sent, err := hex.DecodeString(strings.TrimPrefix(signature, h.SignaturePrefix))
+		if err != nil || !hmac.Equal(sent, h.sign(body)) {
+			return fmt.Errorf("webhook has an invalid signature")
+		}
+	}
+
+	return nil
+}
+
+// sign returns the signature expected for the body
+func (h WebhookHandler) sign(body []byte) []byte {
+	newHash := h.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	mac := hmac.New(newHash, h.Secret)
+	mac.Write(body) // nolint:errcheck
+
+	return mac.Sum(nil)
+}
+
+func (h WebhookHandler) accepts(name WebhookEventName) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, event := range h.Events {
+		if event == name {
+			return true
+		}
+	}
+
+	return false
+}
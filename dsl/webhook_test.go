@@ -0,0 +1,113 @@
+package dsl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func webhookRequest(method string, body string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(method, "/webhooks/pact", strings.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	return req
+}
+
+func webhookSignature(secret string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body)) // nolint:errcheck
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookHandler(t *testing.T) {
+	body := `{"eventName":"contract_content_changed","pactUrl":"http://broker/pacts/1","consumerName":"billy","consumerVersionTags":"main, prod","providerName":"bobby"}`
+
+	var handled []WebhookEvent
+	handler := WebhookHandler{
+		Token:           "token",
+		Secret:          []byte("secret"),
+		SignaturePrefix: "sha256=",
+		Events:          []WebhookEventName{ContractContentChanged},
+		Handle: func(event WebhookEvent) error {
+			handled = append(handled, event)
+			if event.ConsumerName == "broken" {
+				return fmt.Errorf("broken")
+			}
+			return nil
+		},
+	}
+	authenticated := func(body string) map[string]string {
+		return map[string]string{
+			"Authorization":    "Bearer token",
+			"X-Pact-Signature": webhookSignature("secret", body),
+		}
+	}
+
+	broken := strings.Replace(body, "billy", "broken", 1)
+	published := strings.Replace(body, "contract_content_changed", "contract_published", 1)
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"valid", webhookRequest("POST", body, authenticated(body)), http.StatusAccepted},
+		{"method", webhookRequest("GET", "", authenticated("")), http.StatusMethodNotAllowed},
+		{"token", webhookRequest("POST", body, map[string]string{"Authorization": "Bearer nope", "X-Pact-Signature": webhookSignature("secret", body)}), http.StatusUnauthorized},
+		{"signature", webhookRequest("POST", body, map[string]string{"Authorization": "Bearer token", "X-Pact-Signature": webhookSignature("nope", body)}), http.StatusUnauthorized},
+		{"unsigned", webhookRequest("POST", body, map[string]string{"Authorization": "Bearer token"}), http.StatusUnauthorized},
+		{"body", webhookRequest("POST", "nope", authenticated("nope")), http.StatusBadRequest},
+		{"ignored", webhookRequest("POST", published, authenticated(published)), http.StatusAccepted},
+		{"handler error", webhookRequest("POST", broken, authenticated(broken)), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, test.req)
+		if w.Code != test.status {
+			t.Fatalf("%s: want status %d, got %d: %s", test.name, test.status, w.Code, w.Body.String())
+		}
+	}
+
+	if len(handled) != 2 {
+		t.Fatalf("want 2 events handled, got %d", len(handled))
+	}
+	event := handled[0]
+	if event.EventName != ContractContentChanged || event.PactURL != "http://broker/pacts/1" || event.ProviderName != "bobby" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if tags := event.Tags(); len(tags) != 2 || tags[0] != "main" || tags[1] != "prod" {
+		t.Fatalf("want tags [main prod], got %v", tags)
+	}
+}
+
+func TestParseWebhookEvent_NoEventName(t *testing.T) {
+	if _, err := ParseWebhookEvent([]byte(`{"pactUrl":"http://broker/pacts/1"}`)); err == nil {
+		t.Fatalf("expected an error for an event without a name")
+	}
+}
+
+func TestWebhookEvent_VerifyRequest(t *testing.T) {
+	event := WebhookEvent{PactURL: "http://broker/pacts/1", ProviderName: "bobby"}
+	request := event.VerifyRequest(types.VerifyRequest{
+		ProviderBaseURL: "http://localhost:8000",
+		BrokerURL:       "http://broker",
+		PactURLs:        []string{"./pacts"},
+	})
+
+	if len(request.PactURLs) != 1 || request.PactURLs[0] != event.PactURL {
+		t.Fatalf("want only the event's pact, got %v", request.PactURLs)
+	}
+	if request.BrokerURL != "" || request.Provider != "bobby" || request.ProviderBaseURL != "http://localhost:8000" {
+		t.Fatalf("unexpected request %+v", request)
+	}
+}
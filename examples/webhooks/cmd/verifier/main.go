@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/pact-foundation/pact-go/dsl"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// Verifies the provider running at PROVIDER_BASE_URL each time the Pact Broker
+// reports that a pact has changed, publishing the results back to the broker.
+func main() {
+	runner := &dsl.VerificationRunner{
+		Pact: &dsl.Pact{
+			Provider: os.Getenv("PACT_PROVIDER"),
+		},
+		Request: types.VerifyRequest{
+			ProviderBaseURL:  os.Getenv("PROVIDER_BASE_URL"),
+			BrokerToken:      os.Getenv("PACT_BROKER_TOKEN"),
+			BrokerUsername:   os.Getenv("PACT_BROKER_USERNAME"),
+			BrokerPassword:   os.Getenv("PACT_BROKER_PASSWORD"),
+			ProviderVersion:  os.Getenv("PROVIDER_VERSION"),
+			VersionDetection: &types.VersionDetection{},
+		},
+		Webhook: dsl.WebhookHandler{
+			Token: os.Getenv("PACT_WEBHOOK_TOKEN"),
+		},
+		OnResult: func(event dsl.WebhookEvent, result types.VerificationResult) {
+			log.Printf("%s %s: %s", event.ConsumerName, event.ConsumerVersionNumber, result.Status)
+		},
+	}
+
+	addr := os.Getenv("ADDR")
+	if addr == "" {
+		addr = ":8090"
+	}
+	log.Fatal(runner.ListenAndServe(addr))
+}
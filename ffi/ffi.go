@@ -0,0 +1,196 @@
+/*
+Package ffi binds to the Rust pact core, libpact_ffi
+(https://github.com/pact-foundation/pact-reference), as an alternative to
+the Ruby mock service and verifier. The Rust mock server matches request
+bodies, headers and queries itself, and its verifier checks the provider's
+responses. Bodies are only matched by the Rust core within the mock server
+and verifier: there is no binding to match a body on its own, which is left
+to the dsl package.
+
+The binding requires cgo and is only compiled with the pact_ffi build tag,
+e.g.
+
+	CGO_LDFLAGS="-L/path/to/lib" go test -tags pact_ffi ./...
+
+with pact.h and libpact_ffi installed where the C toolchain can find them.
+Without the tag every function returns ErrUnavailable, so that code using
+the package still builds.
+*/
+package ffi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// ErrUnavailable is returned when pact-go is built without the pact_ffi tag
+var ErrUnavailable = errors.New("the pact FFI backend is unavailable: build with the pact_ffi tag and cgo enabled")
+
+// MockServer is a mock provider run by the Rust core, listening on Port
+type MockServer struct {
+	Port int
+}
+
+// URL is the base URL of the mock server
+func (m *MockServer) URL() string {
+	return fmt.Sprintf("http://127.0.0.1:%d", m.Port)
+}
+
+// mockServerMismatch is a mismatch reported by the Rust mock server
+type mockServerMismatch struct {
+	Type       string `json:"type"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Mismatches []struct {
+		Type      string          `json:"type"`
+		Path      string          `json:"path"`
+		Key       string          `json:"key"`
+		Parameter string          `json:"parameter"`
+		Expected  json.RawMessage `json:"expected"`
+		Actual    json.RawMessage `json:"actual"`
+		Mismatch  string          `json:"mismatch"`
+	} `json:"mismatches"`
+}
+
+// ParseMismatches converts the mismatches reported by the Rust mock server
+// to typed mismatches
+func ParseMismatches(content []byte) ([]types.InteractionMismatches, error) {
+	var reported []mockServerMismatch
+	if err := json.Unmarshal(content, &reported); err != nil {
+		return nil, fmt.Errorf("unable to parse the mock server mismatches: %v", err)
+	}
+
+	mismatches := make([]types.InteractionMismatches, 0, len(reported))
+	for _, r := range reported {
+		request := func(message string) types.InteractionMismatches {
+			return types.InteractionMismatches{
+				Mismatches: []types.Mismatch{types.RequestMismatch{Method: r.Method, Path: r.Path, Message: message}},
+			}
+		}
+
+		switch r.Type {
+		case "missing-request":
+			mismatches = append(mismatches, request("expected request was not received"))
+		case "request-not-found":
+			mismatches = append(mismatches, request("no interaction expected this request"))
+		case "request-mismatch":
+			m := types.InteractionMismatches{}
+			for _, diff := range r.Mismatches {
+				expected, actual := rawString(diff.Expected), rawString(diff.Actual)
+				switch diff.Type {
+				case "BodyMismatch", "BodyTypeMismatch":
+					m.Mismatches = append(m.Mismatches, types.BodyMismatch{Path: diff.Path, Expected: expected, Actual: actual, Message: diff.Mismatch})
+				case "HeaderMismatch":
+					m.Mismatches = append(m.Mismatches, types.HeaderMismatch{Key: diff.Key, Expected: expected, Actual: actual})
+				case "QueryMismatch":
+					m.Mismatches = append(m.Mismatches, types.QueryMismatch{Key: diff.Parameter, Expected: expected, Actual: actual})
+				default:
+					message := diff.Mismatch
+					if message == "" {
+						message = fmt.Sprintf("%s: expected '%s' but got '%s'", diff.Type, expected, actual)
+					}
+					m.Mismatches = append(m.Mismatches, types.RequestMismatch{Method: r.Method, Path: r.Path, Message: message})
+				}
+			}
+			mismatches = append(mismatches, m)
+		default:
+			mismatches = append(mismatches, request(fmt.Sprintf("unknown mismatch '%s'", r.Type)))
+		}
+	}
+
+	return mismatches, nil
+}
+
+// rawString returns a JSON string's value, or other JSON as written
+func rawString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	return string(raw)
+}
+
+// VerifierArgs returns the pact_verifier_cli arguments for the request
+func VerifierArgs(request types.VerifyRequest) ([]string, error) {
+	if len(request.PactURLs) == 0 && request.BrokerURL == "" {
+		return nil, fmt.Errorf("One of 'PactURLs' or 'BrokerURL' must be specified")
+	}
+
+	provider, err := url.Parse(request.ProviderBaseURL)
+	if err != nil || provider.Hostname() == "" {
+		return nil, fmt.Errorf("invalid ProviderBaseURL '%s'", request.ProviderBaseURL)
+	}
+
+	args := []string{"--scheme", provider.Scheme, "--hostname", provider.Hostname()}
+	if provider.Port() != "" {
+		args = append(args, "--port", provider.Port())
+	}
+	if path := strings.TrimSuffix(provider.Path, "/"); path != "" {
+		args = append(args, "--base-path", path)
+	}
+
+	for _, pactURL := range request.PactURLs {
+		if strings.HasPrefix(pactURL, "http://") || strings.HasPrefix(pactURL, "https://") {
+			args = append(args, "--url", pactURL)
+		} else {
+			args = append(args, "--file", pactURL)
+		}
+	}
+
+	token, username, password, err := request.BrokerCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := []struct{ name, value string }{
+		{"--broker-url", request.BrokerURL},
+		{"--user", username},
+		{"--password", password},
+		{"--token", token},
+		{"--provider-name", request.Provider},
+		{"--state-change-url", request.ProviderStatesSetupURL},
+		{"--provider-version", request.ProviderVersion},
+		{"--provider-branch", request.ProviderBranch},
+		{"--consumer-version-tags", strings.Join(request.Tags, ",")},
+		{"--provider-tags", strings.Join(request.ProviderTags, ",")},
+		{"--loglevel", strings.ToLower(request.PactLogLevel)},
+	}
+	for _, flag := range flags {
+		if flag.value != "" {
+			args = append(args, flag.name, flag.value)
+		}
+	}
+
+	for _, selector := range request.ConsumerVersionSelectors {
+		body, err := json.Marshal(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid consumer version selector specified: %v", err)
+		}
+		args = append(args, "--consumer-version-selectors", string(body))
+	}
+	for _, header := range request.CustomProviderHeaders {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid custom provider header '%s'", header)
+		}
+		args = append(args, "--header", strings.TrimSpace(parts[0])+"="+strings.TrimSpace(parts[1]))
+	}
+
+	if request.PublishVerificationResults {
+		args = append(args, "--publish")
+	}
+	if request.EnablePending {
+		args = append(args, "--enable-pending")
+	}
+	if request.IncludeWIPPactsSince != nil {
+		args = append(args, "--include-wip-pacts-since", request.IncludeWIPPactsSince.Format("2006-01-02"))
+	}
+
+	return args, nil
+}
@@ -0,0 +1,134 @@
+//go:build pact_ffi && cgo
+// +build pact_ffi,cgo
+
+package ffi
+
+/*
+#cgo LDFLAGS: -lpact_ffi
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+const char *pactffi_version(void);
+int32_t pactffi_create_mock_server(const char *pact_str, const char *addr_str, bool tls);
+bool pactffi_mock_server_matched(int32_t mock_server_port);
+char *pactffi_mock_server_mismatches(int32_t mock_server_port);
+bool pactffi_cleanup_mock_server(int32_t mock_server_port);
+int32_t pactffi_write_pact_file(int32_t mock_server_port, const char *directory, bool overwrite);
+int32_t pactffi_verify(const char *args);
+*/
+import "C"
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unsafe"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// Available reports whether the pact FFI backend is compiled in
+func Available() bool {
+	return true
+}
+
+// Version is the version of libpact_ffi
+func Version() string {
+	return C.GoString(C.pactffi_version())
+}
+
+// StartMockServer starts a Rust mock server for the interactions of a pact
+// document, listening on address e.g. "127.0.0.1:0" for any free port
+func StartMockServer(pact []byte, address string) (*MockServer, error) {
+	cPact := C.CString(string(pact))
+	defer C.free(unsafe.Pointer(cPact))
+	cAddress := C.CString(address)
+	defer C.free(unsafe.Pointer(cAddress))
+
+	port := int(C.pactffi_create_mock_server(cPact, cAddress, false))
+	if port > 0 {
+		log.Println("[DEBUG] pact ffi: started mock server on port", port)
+		return &MockServer{Port: port}, nil
+	}
+
+	switch port {
+	case -2:
+		return nil, fmt.Errorf("unable to start the mock server: the pact could not be parsed")
+	case -3:
+		return nil, fmt.Errorf("unable to start the mock server on '%s'", address)
+	case -5:
+		return nil, fmt.Errorf("unable to start the mock server: invalid address '%s'", address)
+	}
+
+	return nil, fmt.Errorf("unable to start the mock server: error %d", port)
+}
+
+// Matched reports whether every interaction was requested, and no request
+// mismatched
+func (m *MockServer) Matched() bool {
+	return bool(C.pactffi_mock_server_matched(C.int32_t(m.Port)))
+}
+
+// Mismatches returns the requests that matched no interaction, and the
+// interactions that weren't requested
+func (m *MockServer) Mismatches() ([]types.InteractionMismatches, error) {
+	// the mismatches are owned by the mock server, and must not be freed
+	mismatches := C.pactffi_mock_server_mismatches(C.int32_t(m.Port))
+	if mismatches == nil {
+		return nil, fmt.Errorf("no mock server is running on port %d", m.Port)
+	}
+
+	return ParseMismatches([]byte(C.GoString(mismatches)))
+}
+
+// WritePact writes the pact of the mock server's interactions to dir,
+// merging it with an existing pact unless overwrite is set
+func (m *MockServer) WritePact(dir string, overwrite bool) error {
+	cDir := C.CString(dir)
+	defer C.free(unsafe.Pointer(cDir))
+
+	switch res := int(C.pactffi_write_pact_file(C.int32_t(m.Port), cDir, C.bool(overwrite))); res {
+	case 0:
+		return nil
+	case 2:
+		return fmt.Errorf("unable to write the pact file to '%s'", dir)
+	case 3:
+		return fmt.Errorf("no mock server is running on port %d", m.Port)
+	default:
+		return fmt.Errorf("unable to write the pact file: error %d", res)
+	}
+}
+
+// Close stops the mock server
+func (m *MockServer) Close() error {
+	if !bool(C.pactffi_cleanup_mock_server(C.int32_t(m.Port))) {
+		return fmt.Errorf("no mock server is running on port %d", m.Port)
+	}
+
+	return nil
+}
+
+// Verify verifies the pacts of the request against the provider with the
+// Rust verifier
+func Verify(request types.VerifyRequest) error {
+	args, err := VerifierArgs(request)
+	if err != nil {
+		return err
+	}
+	log.Println("[DEBUG] pact ffi: verifying with arguments", args)
+
+	cArgs := C.CString(strings.Join(args, "\n"))
+	defer C.free(unsafe.Pointer(cArgs))
+
+	switch res := int(C.pactffi_verify(cArgs)); res {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("provider verification failed")
+	case 4:
+		return fmt.Errorf("the verifier rejected its arguments: %s", strings.Join(args, " "))
+	default:
+		return fmt.Errorf("provider verification errored: error %d", res)
+	}
+}
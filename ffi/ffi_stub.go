@@ -0,0 +1,46 @@
+//go:build !pact_ffi || !cgo
+// +build !pact_ffi !cgo
+
+package ffi
+
+import "github.com/pact-foundation/pact-go/types"
+
+// Available reports whether the pact FFI backend is compiled in
+func Available() bool {
+	return false
+}
+
+// Version is the version of libpact_ffi
+func Version() string {
+	return ""
+}
+
+// StartMockServer returns ErrUnavailable
+func StartMockServer(pact []byte, address string) (*MockServer, error) {
+	return nil, ErrUnavailable
+}
+
+// Matched returns false
+func (m *MockServer) Matched() bool {
+	return false
+}
+
+// Mismatches returns ErrUnavailable
+func (m *MockServer) Mismatches() ([]types.InteractionMismatches, error) {
+	return nil, ErrUnavailable
+}
+
+// WritePact returns ErrUnavailable
+func (m *MockServer) WritePact(dir string, overwrite bool) error {
+	return ErrUnavailable
+}
+
+// Close returns ErrUnavailable
+func (m *MockServer) Close() error {
+	return ErrUnavailable
+}
+
+// Verify returns ErrUnavailable
+func Verify(request types.VerifyRequest) error {
+	return ErrUnavailable
+}
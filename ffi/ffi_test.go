@@ -0,0 +1,100 @@
+package ffi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+func TestParseMismatches(t *testing.T) {
+	content := `[
+		{"type": "missing-request", "method": "GET", "path": "/orders/1", "request": {}},
+		{"type": "request-not-found", "method": "GET", "path": "/customers", "request": {}},
+		{"type": "request-mismatch", "method": "POST", "path": "/orders", "mismatches": [
+			{"type": "BodyMismatch", "path": "$.sku", "expected": "\"ABC\"", "actual": "7", "mismatch": "Expected 'ABC' to be equal to 7"},
+			{"type": "HeaderMismatch", "key": "Accept", "expected": "application/json", "actual": "text/plain", "mismatch": "Mismatch with header 'Accept'"},
+			{"type": "QueryMismatch", "parameter": "dry", "expected": "", "actual": "[\"true\"]", "mismatch": "Unexpected query parameter 'dry' received"},
+			{"type": "MethodMismatch", "expected": "POST", "actual": "PUT"}
+		]}
+	]`
+
+	mismatches, err := ParseMismatches([]byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, m := range mismatches {
+		for _, mismatch := range m.Mismatches {
+			got = append(got, fmt.Sprintf("%s|%s", mismatch.Type(), mismatch))
+		}
+	}
+	want := []string{
+		"request|request GET /orders/1: expected request was not received",
+		"request|request GET /customers: no interaction expected this request",
+		"body|body $.sku: Expected 'ABC' to be equal to 7",
+		"header|header Accept: expected 'application/json' but got 'text/plain'",
+		`query|query dry: expected '' but got '["true"]'`,
+		"request|request POST /orders: MethodMismatch: expected 'POST' but got 'PUT'",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	if _, err = ParseMismatches([]byte("nope")); err == nil {
+		t.Fatal("expected an error for invalid mismatches")
+	}
+}
+
+func TestVerifierArgs(t *testing.T) {
+	since := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	args, err := VerifierArgs(types.VerifyRequest{
+		ProviderBaseURL:            "http://localhost:8080/api/",
+		PactURLs:                   []string{"./pacts/web-orders.json", "https://example.com/pacts/web-orders.json"},
+		BrokerURL:                  "https://broker.example.com",
+		BrokerToken:                "token",
+		Provider:                   "orders",
+		ProviderVersion:            "1.0.0",
+		ProviderTags:               []string{"main", "prod"},
+		CustomProviderHeaders:      []string{"Authorization: Bearer 1234"},
+		PublishVerificationResults: true,
+		IncludeWIPPactsSince:       &since,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "--scheme http --hostname localhost --port 8080 --base-path /api " +
+		"--file ./pacts/web-orders.json --url https://example.com/pacts/web-orders.json " +
+		"--broker-url https://broker.example.com --token token --provider-name orders --provider-version 1.0.0 --provider-tags main,prod " +
+		"--header Authorization=Bearer 1234 --publish --include-wip-pacts-since 2020-05-01"
+	if got := strings.Join(args, " "); got != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	for _, request := range []types.VerifyRequest{
+		{ProviderBaseURL: "http://localhost:8080"},
+		{ProviderBaseURL: "localhost", PactURLs: []string{"pact.json"}},
+		{ProviderBaseURL: "http://localhost:8080", PactURLs: []string{"pact.json"}, CustomProviderHeaders: []string{"invalid"}},
+	} {
+		if _, err = VerifierArgs(request); err == nil {
+			t.Fatalf("expected an error for %+v", request)
+		}
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	if Available() {
+		t.Skip("built with the pact_ffi tag")
+	}
+
+	if _, err := StartMockServer([]byte("{}"), "127.0.0.1:0"); err != ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if err := Verify(types.VerifyRequest{}); err != ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
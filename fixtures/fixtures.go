@@ -0,0 +1,183 @@
+/*
+Package fixtures maps provider states to declarative fixtures, such as SQL
+seed files, JSON documents or Go functions, so that providers need not
+hand-roll state management around the verifier.
+
+Fixtures may depend on other fixtures, which are set up first. When a new state
+is set up, the fixtures of the previous state are torn down in reverse order:
+
+	registry := fixtures.New().
+		Add("users exist", fixtures.SQL(db, "testdata/users.sql", "testdata/truncate_users.sql")).
+		Add("user 1 has an order", fixtures.Func(createOrder, deleteOrders), "users exist")
+
+	pact.VerifyProvider(t, types.VerifyRequest{
+		...
+		StateHandlers: registry.StateHandlers(),
+		AfterEach:     registry.Teardown,
+	})
+*/
+package fixtures
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// Fixture sets up, and tears down, the data required by a provider state
+type Fixture interface {
+	// Setup creates the fixture
+	Setup() error
+
+	// Teardown removes the fixture
+	Teardown() error
+}
+
+type entry struct {
+	fixture   Fixture
+	dependsOn []string
+}
+
+// Registry maps provider states to fixtures
+type Registry struct {
+	mu       sync.Mutex
+	fixtures map[string]entry
+
+	// active are the fixtures currently set up, in the order they were set up
+	active []string
+}
+
+// New creates an empty Registry
+func New() *Registry {
+	return &Registry{fixtures: make(map[string]entry)}
+}
+
+// Add registers the fixture for the given provider state. Any fixtures it
+// depends on are set up before it.
+func (r *Registry) Add(state string, fixture Fixture, dependsOn ...string) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fixtures[state] = entry{fixture: fixture, dependsOn: dependsOn}
+
+	return r
+}
+
+// Setup tears down any active fixtures, then sets up the fixtures of the given
+// state and its dependencies, in dependency order.
+func (r *Registry) Setup(state string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.teardown(); err != nil {
+		return err
+	}
+
+	order, err := r.resolve(state)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		log.Println("[DEBUG] fixtures: setting up", name)
+		if err := r.fixtures[name].fixture.Setup(); err != nil {
+			return fmt.Errorf("unable to set up fixture '%s': %v", name, err)
+		}
+		r.active = append(r.active, name)
+	}
+
+	return nil
+}
+
+// Teardown tears down the active fixtures in the reverse order they were set
+// up. It may be used as an AfterEach hook.
+func (r *Registry) Teardown() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.teardown()
+}
+
+// StateHandlers returns a state handler for each registered state
+func (r *Registry) StateHandlers() types.StateHandlers {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	handlers := make(types.StateHandlers, len(r.fixtures))
+	for state := range r.fixtures {
+		s := state
+		handlers[s] = func() error {
+			return r.Setup(s)
+		}
+	}
+
+	return handlers
+}
+
+func (r *Registry) teardown() error {
+	var errs []string
+	for i := len(r.active) - 1; i >= 0; i-- {
+		name := r.active[i]
+		log.Println("[DEBUG] fixtures: tearing down", name)
+		if err := r.fixtures[name].fixture.Teardown(); err != nil {
+			errs = append(errs, fmt.Sprintf("'%s': %v", name, err))
+		}
+	}
+	r.active = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to tear down fixtures: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// resolve returns the state and its dependencies in the order they should be
+// set up, detecting unknown states and cycles
+func (r *Registry) resolve(state string) ([]string, error) {
+	var order []string
+	visited := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		if visited[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("fixture dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+
+		e, ok := r.fixtures[name]
+		if !ok {
+			if len(path) == 0 {
+				return fmt.Errorf("no fixture registered for state '%s'", name)
+			}
+			return fmt.Errorf("fixture '%s' depends on unknown fixture '%s'", path[len(path)-1], name)
+		}
+
+		visiting[name] = true
+		dependencies := append([]string{}, e.dependsOn...)
+		sort.Strings(dependencies)
+		for _, dependency := range dependencies {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		visited[name] = true
+		order = append(order, name)
+
+		return nil
+	}
+
+	if err := visit(state, nil); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
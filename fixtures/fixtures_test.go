@@ -0,0 +1,165 @@
+package fixtures
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) fixture(name string) Fixture {
+	return Func(func() error {
+		r.calls = append(r.calls, "setup "+name)
+		return nil
+	}, func() error {
+		r.calls = append(r.calls, "teardown "+name)
+		return nil
+	})
+}
+
+func TestRegistry_Setup(t *testing.T) {
+	r := &recorder{}
+	registry := New().
+		Add("orders exist", r.fixture("orders"), "users exist", "products exist").
+		Add("users exist", r.fixture("users")).
+		Add("products exist", r.fixture("products"), "users exist").
+		Add("nothing", r.fixture("nothing"))
+
+	if err := registry.Setup("orders exist"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Setup("nothing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Teardown(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"setup users", "setup products", "setup orders",
+		"teardown orders", "teardown products", "teardown users",
+		"setup nothing",
+		"teardown nothing",
+	}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Fatalf("want %v, got %v", expected, r.calls)
+	}
+}
+
+func TestRegistry_SetupErrors(t *testing.T) {
+	r := &recorder{}
+	registry := New().
+		Add("a", r.fixture("a"), "b").
+		Add("b", r.fixture("b"), "a").
+		Add("c", r.fixture("c"), "missing").
+		Add("broken", Func(func() error { return errors.New("boom") }, nil))
+
+	tests := []struct {
+		state string
+		want  string
+	}{
+		{state: "a", want: "cycle: a -> b -> a"},
+		{state: "c", want: "'c' depends on unknown fixture 'missing'"},
+		{state: "unknown", want: "no fixture registered for state 'unknown'"},
+		{state: "broken", want: "unable to set up fixture 'broken': boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			err := registry.Setup(tt.state)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRegistry_StateHandlers(t *testing.T) {
+	r := &recorder{}
+	registry := New().Add("users exist", r.fixture("users"))
+
+	handlers := registry.StateHandlers()
+	if err := handlers["users exist"](); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(r.calls, []string{"setup users"}) {
+		t.Fatalf("unexpected calls %v", r.calls)
+	}
+}
+
+type fakeDB struct {
+	queries []string
+}
+
+func (db *fakeDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	db.queries = append(db.queries, query)
+	return nil, nil
+}
+
+func TestSQL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	seed := filepath.Join(dir, "seed.sql")
+	ioutil.WriteFile(seed, []byte("INSERT INTO users VALUES (1);"), 0644)
+
+	db := &fakeDB{}
+	fixture := SQL(db, seed, "")
+	if err = fixture.Setup(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = fixture.Teardown(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(db.queries, []string{"INSERT INTO users VALUES (1);"}) {
+		t.Fatalf("unexpected queries %v", db.queries)
+	}
+
+	if err = SQL(db, filepath.Join(dir, "missing.sql"), "").Setup(); err == nil {
+		t.Fatal("expected an error for a missing seed file")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "user.json")
+	ioutil.WriteFile(valid, []byte(`{"id": 1}`), 0644)
+	invalid := filepath.Join(dir, "invalid.json")
+	ioutil.WriteFile(invalid, []byte(`{"id":`), 0644)
+
+	var loaded json.RawMessage
+	load := func(document json.RawMessage) error {
+		loaded = document
+		return nil
+	}
+
+	if err = JSON(valid, load, nil).Setup(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(loaded) != `{"id": 1}` {
+		t.Fatalf("unexpected document %s", loaded)
+	}
+
+	if err = JSON(invalid, load, nil).Setup(); err == nil {
+		t.Fatal("expected an error for an invalid document")
+	}
+}
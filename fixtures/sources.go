@@ -0,0 +1,75 @@
+package fixtures
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+type funcFixture struct {
+	setup    func() error
+	teardown func() error
+}
+
+func (f funcFixture) Setup() error {
+	if f.setup == nil {
+		return nil
+	}
+	return f.setup()
+}
+
+func (f funcFixture) Teardown() error {
+	if f.teardown == nil {
+		return nil
+	}
+	return f.teardown()
+}
+
+// Func creates a fixture from Go functions. Either function may be nil.
+func Func(setup func() error, teardown func() error) Fixture {
+	return funcFixture{setup: setup, teardown: teardown}
+}
+
+// Execer executes SQL statements, it is satisfied by *sql.DB and *sql.Tx
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// SQL creates a fixture that executes a SQL seed file on setup, and an
+// optional teardown file. Each file is executed as a single statement, so
+// drivers must support multiple statements if the files contain them.
+func SQL(db Execer, seedFile string, teardownFile string) Fixture {
+	exec := func(file string) func() error {
+		if file == "" {
+			return nil
+		}
+		return func() error {
+			query, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if _, err = db.Exec(string(query)); err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+			return nil
+		}
+	}
+
+	return Func(exec(seedFile), exec(teardownFile))
+}
+
+// JSON creates a fixture that reads a JSON document and passes it to load on
+// setup, e.g. to insert it into a document store. unload may be nil.
+func JSON(file string, load func(document json.RawMessage) error, unload func() error) Fixture {
+	return Func(func() error {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if !json.Valid(content) {
+			return fmt.Errorf("%s: invalid JSON document", file)
+		}
+		return load(json.RawMessage(content))
+	}, unload)
+}
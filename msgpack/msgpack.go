@@ -0,0 +1,387 @@
+/*
+Package msgpack is a minimal MessagePack (https://msgpack.org) codec for
+message and HTTP bodies in pacts.
+
+Values are encoded canonically: map keys are sorted and numbers use their
+smallest representation, so that equal structures always encode to the same
+bytes. This lets encoded bodies be compared on their decoded structure.
+
+Go values are converted using their JSON representation, so `json` struct
+tags apply.
+*/
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Marshal encodes the value as MessagePack
+func Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = encode(&buf, generic); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes MessagePack data into the value, a pointer
+func Unmarshal(data []byte, v interface{}) error {
+	generic, err := decodeAll(data)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(generic)
+	if err != nil {
+		return fmt.Errorf("msgpack: unable to convert to JSON: %v", err)
+	}
+
+	return json.Unmarshal(content, v)
+}
+
+// Canonical re-encodes MessagePack data in its canonical form
+func Canonical(data []byte) ([]byte, error) {
+	generic, err := decodeAll(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = encode(&buf, generic); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if value {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		encodeInt(buf, int64(value))
+	case int64:
+		encodeInt(buf, value)
+	case uint64:
+		encodeUint(buf, value)
+	case float64:
+		encodeFloat(buf, value)
+	case json.Number:
+		if i, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			encodeInt(buf, i)
+		} else if u, err := strconv.ParseUint(string(value), 10, 64); err == nil {
+			encodeUint(buf, u)
+		} else {
+			f, err := value.Float64()
+			if err != nil {
+				return fmt.Errorf("msgpack: invalid number %s", value)
+			}
+			encodeFloat(buf, f)
+		}
+	case string:
+		encodeLength(buf, len(value), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		buf.WriteString(value)
+	case []byte:
+		encodeLength(buf, len(value), 0, -1, 0xc4, 0xc5, 0xc6)
+		buf.Write(value)
+	case []interface{}:
+		encodeLength(buf, len(value), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, item := range value {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		encodeLength(buf, len(value), 0x80, 15, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			if err := encode(buf, key); err != nil {
+				return err
+			}
+			if err := encode(buf, value[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+// encodeLength writes the header of a string, binary, array or map. A fix
+// type is used if the length is at most fixMax, otherwise the 8, 16 or 32
+// bit form (a zero code means the form doesn't exist for the type).
+func encodeLength(buf *bytes.Buffer, length int, fixCode byte, fixMax int, code8 byte, code16 byte, code32 byte) {
+	switch {
+	case length <= fixMax:
+		buf.WriteByte(fixCode | byte(length))
+	case code8 != 0 && length <= math.MaxUint8:
+		buf.WriteByte(code8)
+		buf.WriteByte(byte(length))
+	case length <= math.MaxUint16:
+		buf.WriteByte(code16)
+		binary.Write(buf, binary.BigEndian, uint16(length)) // nolint:errcheck
+	default:
+		buf.WriteByte(code32)
+		binary.Write(buf, binary.BigEndian, uint32(length)) // nolint:errcheck
+	}
+}
+
+func encodeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		encodeUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i)) // nolint:errcheck
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i)) // nolint:errcheck
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i) // nolint:errcheck
+	}
+}
+
+func encodeUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 0x7f:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(u)) // nolint:errcheck
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(u)) // nolint:errcheck
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, u) // nolint:errcheck
+	}
+}
+
+// encodeFloat writes whole numbers as integers, so that they encode the
+// same way regardless of how they were decoded
+func encodeFloat(buf *bytes.Buffer, f float64) {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		encodeInt(buf, int64(f))
+		return
+	}
+
+	buf.WriteByte(0xcb)
+	binary.Write(buf, binary.BigEndian, f) // nolint:errcheck
+}
+
+var errTruncated = errors.New("msgpack: unexpected end of data")
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func decodeAll(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, fmt.Errorf("msgpack: %d unexpected trailing bytes", len(data)-d.pos)
+	}
+
+	return v, nil
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+
+	return b, nil
+}
+
+// uint reads a big endian unsigned integer of n bytes
+func (d *decoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+
+	return u, nil
+}
+
+func (d *decoder) decode() (interface{}, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	code := b[0]
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return d.decodeMap(int(code & 0x0f))
+	case code&0xf0 == 0x90:
+		return d.decodeArray(int(code & 0x0f))
+	case code&0xe0 == 0xa0:
+		return d.decodeString(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		length, err := d.uint(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(int(length))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case 0xca:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u <= math.MaxInt64 {
+			return int64(u), nil
+		}
+		return u, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		u, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := uint(64 - 8*size)
+		return int64(u<<shift) >> shift, nil
+	case 0xd9, 0xda, 0xdb:
+		length, err := d.uint(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(length))
+	case 0xdc, 0xdd:
+		length, err := d.uint(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(length))
+	case 0xde, 0xdf:
+		length, err := d.uint(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(length))
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported type code 0x%02x at offset %d", code, d.pos-1)
+}
+
+func (d *decoder) decodeString(length int) (interface{}, error) {
+	b, err := d.next(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (d *decoder) decodeArray(length int) (interface{}, error) {
+	if length > len(d.data)-d.pos {
+		return nil, errTruncated
+	}
+
+	items := make([]interface{}, length)
+	for i := range items {
+		item, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+func (d *decoder) decodeMap(length int) (interface{}, error) {
+	if length > len(d.data)-d.pos {
+		return nil, errTruncated
+	}
+
+	values := make(map[string]interface{}, length)
+	for i := 0; i < length; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		values[fmt.Sprint(key)] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,96 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "map", value: map[string]interface{}{"schema": 0, "compact": true}, want: "82a7636f6d70616374c3a6736368656d6100"},
+		{name: "nil", value: nil, want: "c0"},
+		{name: "negative fixint", value: -1, want: "ff"},
+		{name: "int8", value: -100, want: "d09c"},
+		{name: "uint8", value: 200, want: "ccc8"},
+		{name: "uint16", value: 1000, want: "cd03e8"},
+		{name: "int32", value: -100000, want: "d2fffe7960"},
+		{name: "uint64", value: uint64(math.MaxUint64), want: "cfffffffffffffffff"},
+		{name: "whole float", value: 2.0, want: "02"},
+		{name: "float", value: 1.5, want: "cb3ff8000000000000"},
+		{name: "array", value: []string{"a", "b"}, want: "92a161a162"},
+		{name: "str8", value: strings.Repeat("x", 32), want: "d920" + strings.Repeat("78", 32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hex.EncodeToString(data); got != tt.want {
+				t.Fatalf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Price float64  `json:"price"`
+		Tags  []string `json:"tags"`
+	}
+	type order struct {
+		ID    int64  `json:"id"`
+		Items []item `json:"items"`
+		Note  *string
+	}
+
+	in := order{ID: -42, Items: []item{{Name: "jumper", Price: 9.99, Tags: []string{"wool"}}}}
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out order
+	if err = Unmarshal(data, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("want %+v, got %+v", in, out)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	// {"schema":0,"compact":true} with the keys out of order and the integer
+	// in a wider form than required
+	data, _ := hex.DecodeString("82a6736368656d61d000a7636f6d70616374c3")
+
+	canonical, err := Canonical(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected, _ := Marshal(map[string]interface{}{"compact": true, "schema": 0})
+	if !bytes.Equal(canonical, expected) {
+		t.Fatalf("want %x, got %x", expected, canonical)
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	for _, input := range []string{"", "82a7636f6d70", "c1", "0101", "dc00ff"} {
+		data, _ := hex.DecodeString(input)
+		var v interface{}
+		if err := Unmarshal(data, &v); err == nil {
+			t.Fatalf("expected an error for %s", input)
+		}
+	}
+}
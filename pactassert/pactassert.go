@@ -0,0 +1,57 @@
+/*
+Package pactassert uses pact matchers as plain unit test assertions, e.g. to
+check the output of an HTTP handler against the body a consumer expects,
+without starting a mock server or provider verification.
+
+	expected := dsl.StructMatcher{
+		"id":    dsl.Like(1),
+		"email": dsl.Term("jane@example.com", `^\S+@\S+$`),
+	}
+	pactassert.MatchesPact(t, expected, recorder.Body.Bytes())
+*/
+package pactassert
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/dsl"
+)
+
+// MatchesPact asserts that the actual JSON body matches the expected body,
+// built with matchers, as the mock server and provider verifier would (see
+// dsl.MatchBody). The actual body may be a []byte, string or
+// json.RawMessage of JSON, or any other value, which is serialised to JSON.
+// Each mismatch is reported as a test error. It returns whether the body
+// matched.
+func MatchesPact(t testing.TB, expected interface{}, actual interface{}) bool {
+	t.Helper()
+
+	var content []byte
+	switch a := actual.(type) {
+	case []byte:
+		content = a
+	case json.RawMessage:
+		content = a
+	case string:
+		content = []byte(a)
+	default:
+		var err error
+		if content, err = json.Marshal(actual); err != nil {
+			t.Errorf("unable to serialise the actual body: %v", err)
+			return false
+		}
+	}
+
+	mismatches, err := dsl.MatchBody(expected, content)
+	if err != nil {
+		t.Errorf("unable to match the body: %v", err)
+		return false
+	}
+
+	for _, mismatch := range mismatches {
+		t.Errorf("%s", mismatch)
+	}
+
+	return len(mismatches) == 0
+}
@@ -0,0 +1,56 @@
+package pactassert
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/dsl"
+)
+
+// recordingT records the errors of a test
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMatchesPact(t *testing.T) {
+	expected := dsl.StructMatcher{
+		"id":   dsl.Like(1),
+		"name": dsl.Term("jane", "^[a-z]+$"),
+	}
+
+	for _, actual := range []interface{}{
+		[]byte(`{"id": 2, "name": "joe"}`),
+		`{"id": 2, "name": "joe"}`,
+		map[string]interface{}{"id": 2, "name": "joe"},
+	} {
+		rt := &recordingT{}
+		if !MatchesPact(rt, expected, actual) || len(rt.errors) != 0 {
+			t.Fatalf("expected %v to match, got %v", actual, rt.errors)
+		}
+	}
+}
+
+func TestMatchesPact_Mismatch(t *testing.T) {
+	rt := &recordingT{}
+	matched := MatchesPact(rt, dsl.StructMatcher{"id": dsl.Like(1), "name": dsl.Term("jane", "^[a-z]+$")}, `{"id": "2", "name": "Joe"}`)
+
+	if matched {
+		t.Fatal("expected the body not to match")
+	}
+	if len(rt.errors) != 2 || !strings.HasPrefix(rt.errors[0], "body $.id: ") || !strings.HasPrefix(rt.errors[1], "body $.name: ") {
+		t.Fatalf("expected an error for each mismatch, got %v", rt.errors)
+	}
+
+	rt = &recordingT{}
+	if MatchesPact(rt, dsl.Like(1), `not json`) || len(rt.errors) != 1 {
+		t.Fatalf("expected an error for an invalid body, got %v", rt.errors)
+	}
+}
@@ -0,0 +1,650 @@
+package pactfile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// identifierRegex matches keys that may be written in dot notation in a
+// version 2 matching rule path
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+// specificationVersions are the versions written to the pact metadata
+var specificationVersions = map[int]string{
+	2: "2.0.0",
+	3: "3.0.0",
+	4: "4.0",
+}
+
+// ConvertSpecification converts a serialised pact, of any specification
+// version, to the given specification version (2, 3 or 4).
+//
+// Matching rules that can't be represented in a version 2 pact are
+// downgraded where possible (e.g. an integer matcher becomes a type
+// matcher), otherwise conversion fails naming the interaction and matcher.
+// Generators are dropped from version 2 pacts. Synchronous messages can only
+// be written as version 4 pacts.
+func ConvertSpecification(content []byte, version int) ([]byte, error) {
+	if _, ok := specificationVersions[version]; !ok {
+		return nil, fmt.Errorf("unsupported pact specification version %d, expected 2, 3 or 4", version)
+	}
+
+	var doc map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid pact file: %v", err)
+	}
+
+	interactions, messages, synchronous, err := normalise(doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(synchronous) > 0 && version < 4 {
+		return nil, fmt.Errorf("synchronous message '%s' can't be written as a version %d pact", synchronous[0]["description"], version)
+	}
+
+	delete(doc, "interactions")
+	delete(doc, "messages")
+
+	switch version {
+	case 2:
+		stripV4(interactions, messages)
+		if len(messages) > 0 {
+			return nil, fmt.Errorf("message '%s' can't be written as a version 2 pact", messages[0]["description"])
+		}
+		for _, interaction := range interactions {
+			if err = toV2(interaction); err != nil {
+				return nil, err
+			}
+		}
+		doc["interactions"] = interactions
+	case 3:
+		stripV4(interactions, messages)
+		if len(interactions) > 0 {
+			doc["interactions"] = interactions
+		}
+		if len(messages) > 0 {
+			doc["messages"] = messages
+		}
+	case 4:
+		all := make([]map[string]interface{}, 0, len(interactions)+len(messages)+len(synchronous))
+		for _, interaction := range interactions {
+			all = append(all, toV4(interaction))
+		}
+		for _, message := range messages {
+			all = append(all, toV4Message(message))
+		}
+		for _, message := range synchronous {
+			all = append(all, toV4SynchronousMessage(message))
+		}
+		doc["interactions"] = all
+	}
+
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	delete(metadata, "pactSpecificationVersion")
+	metadata["pactSpecification"] = map[string]interface{}{"version": specificationVersions[version]}
+	doc["metadata"] = metadata
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// normalise converts the interactions and messages of a pact, of any
+// version, to the version 3 form. Synchronous messages, which only version 4
+// pacts have, are returned separately with their contents unwrapped and
+// their matching rules in the version 3 form.
+func normalise(doc map[string]interface{}) ([]map[string]interface{}, []map[string]interface{}, []map[string]interface{}, error) {
+	var interactions, messages, synchronous []map[string]interface{}
+
+	for _, raw := range asSlice(doc["interactions"]) {
+		interaction, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		kind, _ := interaction["type"].(string)
+		delete(interaction, "type")
+		if strings.HasPrefix(kind, "Asynchronous/Messages") {
+			messages = append(messages, normaliseV4Message(interaction))
+			continue
+		}
+		if strings.HasPrefix(kind, "Synchronous/Messages") {
+			if err := normaliseSynchronousMessage(interaction); err != nil {
+				return nil, nil, nil, err
+			}
+			synchronous = append(synchronous, interaction)
+			continue
+		}
+
+		if err := normaliseInteraction(interaction); err != nil {
+			return nil, nil, nil, err
+		}
+		interactions = append(interactions, interaction)
+	}
+
+	for _, raw := range asSlice(doc["messages"]) {
+		if message, ok := raw.(map[string]interface{}); ok {
+			rules, err := normaliseRules(message["matchingRules"])
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("message '%s': %v", message["description"], err)
+			}
+			setOrDelete(message, "matchingRules", rules)
+			messages = append(messages, message)
+		}
+	}
+
+	return interactions, messages, synchronous, nil
+}
+
+func normaliseInteraction(interaction map[string]interface{}) error {
+	var states []interface{}
+	for _, key := range []string{"providerState", "provider_state"} {
+		if state, ok := interaction[key].(string); ok && state != "" {
+			states = append(states, map[string]interface{}{"name": state})
+		}
+		delete(interaction, key)
+	}
+	if existing := asSlice(interaction["providerStates"]); len(existing) > 0 {
+		states = existing
+	}
+	setOrDelete(interaction, "providerStates", states)
+
+	for _, part := range []string{"request", "response"} {
+		message, _ := interaction[part].(map[string]interface{})
+		if message == nil {
+			continue
+		}
+
+		if body, ok := message["body"].(map[string]interface{}); ok && isV4Body(body) {
+			message["body"] = body["content"]
+		}
+
+		if query, ok := message["query"].(string); ok {
+			values, err := url.ParseQuery(query)
+			if err != nil {
+				return fmt.Errorf("interaction '%s': invalid query %q: %v", interaction["description"], query, err)
+			}
+			normalised := make(map[string]interface{}, len(values))
+			for key, v := range values {
+				items := make([]interface{}, len(v))
+				for i, item := range v {
+					items[i] = item
+				}
+				normalised[key] = items
+			}
+			message["query"] = normalised
+		}
+
+		rules, err := normaliseRules(message["matchingRules"])
+		if err != nil {
+			return fmt.Errorf("interaction '%s': %v", interaction["description"], err)
+		}
+		setOrDelete(message, "matchingRules", rules)
+	}
+
+	return nil
+}
+
+func normaliseV4Message(interaction map[string]interface{}) map[string]interface{} {
+	message := make(map[string]interface{}, len(interaction))
+	for key, value := range interaction {
+		message[key] = value
+	}
+
+	if contents, ok := message["contents"].(map[string]interface{}); ok && isV4Body(contents) {
+		message["contents"] = contents["content"]
+	}
+	if metadata, ok := message["metadata"]; ok {
+		message["metaData"] = metadata
+		delete(message, "metadata")
+	}
+
+	return message
+}
+
+func normaliseSynchronousMessage(interaction map[string]interface{}) error {
+	parts := asSlice(interaction["response"])
+	if request, ok := interaction["request"].(map[string]interface{}); ok {
+		parts = append([]interface{}{request}, parts...)
+	}
+
+	for _, raw := range parts {
+		part, _ := raw.(map[string]interface{})
+		if part == nil {
+			continue
+		}
+		if contents, ok := part["contents"].(map[string]interface{}); ok && isV4Body(contents) {
+			part["contents"] = contents["content"]
+		}
+
+		rules, err := normaliseRules(part["matchingRules"])
+		if err != nil {
+			return fmt.Errorf("synchronous message '%s': %v", interaction["description"], err)
+		}
+		setOrDelete(part, "matchingRules", rules)
+	}
+
+	return nil
+}
+
+// isV4Body determines if the body is in the version 4 form, wrapped with its
+// content type and encoding
+func isV4Body(body map[string]interface{}) bool {
+	_, hasContent := body["content"]
+	_, hasEncoded := body["encoded"]
+	_, hasContentType := body["contentType"]
+
+	return hasContent && (hasEncoded || hasContentType) && len(body) <= 4
+}
+
+// normaliseRules converts matching rules to the version 3 form, keyed by
+// category then path
+func normaliseRules(raw interface{}) (map[string]interface{}, error) {
+	rules, _ := raw.(map[string]interface{})
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	isV2 := false
+	for path := range rules {
+		if strings.HasPrefix(path, "$") {
+			isV2 = true
+		}
+	}
+	if !isV2 {
+		return rules, nil
+	}
+
+	nested := make(map[string]interface{})
+	for path, raw := range rules {
+		rule, _ := raw.(map[string]interface{})
+		matcher := make(map[string]interface{}, len(rule))
+		for key, value := range rule {
+			matcher[key] = value
+		}
+		if _, ok := matcher["match"]; !ok {
+			matcher["match"] = "type"
+		}
+		entry := map[string]interface{}{"matchers": []interface{}{matcher}, "combine": "AND"}
+
+		category, subPath, err := splitV2Path(path)
+		if err != nil {
+			return nil, err
+		}
+		if category == "path" {
+			nested["path"] = entry
+			continue
+		}
+
+		paths, _ := nested[category].(map[string]interface{})
+		if paths == nil {
+			paths = make(map[string]interface{})
+			nested[category] = paths
+		}
+		paths[subPath] = entry
+	}
+
+	return nested, nil
+}
+
+// splitV2Path splits a version 2 matching rule path into its category and
+// the path within it e.g. "$.body.id" is ("body", "$.id") and
+// "$.headers.Accept" is ("header", "Accept"). Trailers, sent after the body,
+// are matched like headers e.g. "$.trailers.X-Checksum" is
+// ("trailer", "X-Checksum").
+func splitV2Path(path string) (string, string, error) {
+	for _, category := range []string{"body", "headers", "header", "trailers", "query", "metadata", "path"} {
+		prefix := "$." + category
+		if path != prefix && !strings.HasPrefix(path, prefix+".") && !strings.HasPrefix(path, prefix+"[") {
+			continue
+		}
+		rest := strings.TrimPrefix(path, prefix)
+
+		switch category {
+		case "body":
+			return "body", "$" + rest, nil
+		case "path":
+			return "path", "", nil
+		default:
+			name := strings.TrimPrefix(rest, ".")
+			if strings.HasPrefix(name, "['") && strings.HasSuffix(name, "']") {
+				name = name[2 : len(name)-2]
+			}
+			if category == "headers" || category == "trailers" {
+				category = strings.TrimSuffix(category, "s")
+			}
+			return category, name, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unsupported matching rule path %s", path)
+}
+
+// toV2 converts a normalised interaction to the version 2 form
+func toV2(interaction map[string]interface{}) error {
+	description := interaction["description"]
+
+	states := asSlice(interaction["providerStates"])
+	delete(interaction, "providerStates")
+	switch {
+	case len(states) > 1:
+		return fmt.Errorf("interaction '%s' has multiple provider states, which can't be written as a version 2 pact", description)
+	case len(states) == 1:
+		state, _ := states[0].(map[string]interface{})
+		if params, ok := state["params"].(map[string]interface{}); ok && len(params) > 0 {
+			log.Printf("[WARN] interaction '%s': dropping provider state parameters, which can't be written as a version 2 pact", description)
+		}
+		interaction["providerState"] = state["name"]
+	}
+
+	for _, part := range []string{"request", "response"} {
+		message, _ := interaction[part].(map[string]interface{})
+		if message == nil {
+			continue
+		}
+
+		if query, ok := message["query"].(map[string]interface{}); ok {
+			values := url.Values{}
+			for key, v := range query {
+				for _, item := range asSlice(v) {
+					values.Add(key, fmt.Sprint(item))
+				}
+			}
+			message["query"] = values.Encode()
+		}
+
+		if _, ok := message["generators"]; ok {
+			log.Printf("[WARN] interaction '%s': dropping %s generators, which can't be written as a version 2 pact", description, part)
+			delete(message, "generators")
+		}
+
+		rules, err := v2Rules(message["matchingRules"])
+		if err != nil {
+			return fmt.Errorf("interaction '%s': %v", description, err)
+		}
+		setOrDelete(message, "matchingRules", rules)
+	}
+
+	return nil
+}
+
+// v2Rules converts version 3 matching rules to the flat version 2 form
+func v2Rules(raw interface{}) (map[string]interface{}, error) {
+	nested, _ := raw.(map[string]interface{})
+	rules := make(map[string]interface{})
+
+	for category, value := range nested {
+		entries, _ := value.(map[string]interface{})
+		if category == "path" {
+			rule, err := v2Rule("$.path", entries)
+			if err != nil {
+				return nil, err
+			}
+			if rule != nil {
+				rules["$.path"] = rule
+			}
+			continue
+		}
+
+		for subPath, entry := range entries {
+			var path string
+			switch category {
+			case "body":
+				path = "$.body" + strings.TrimPrefix(subPath, "$")
+			case "header", "headers", "trailer", "query":
+				switch category {
+				case "header":
+					category = "headers"
+				case "trailer":
+					category = "trailers"
+				}
+				if identifierRegex.MatchString(subPath) {
+					path = "$." + category + "." + subPath
+				} else {
+					path = "$." + category + "['" + subPath + "']"
+				}
+			default:
+				return nil, fmt.Errorf("%s matching rules can't be written as a version 2 pact", category)
+			}
+
+			values, _ := entry.(map[string]interface{})
+			rule, err := v2Rule(path, values)
+			if err != nil {
+				return nil, err
+			}
+			if rule != nil {
+				rules[path] = rule
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// v2Rule combines the matchers of a path into a single version 2 rule,
+// downgrading matchers where possible
+func v2Rule(path string, entry map[string]interface{}) (map[string]interface{}, error) {
+	matchers := asSlice(entry["matchers"])
+	if combine, _ := entry["combine"].(string); combine == "OR" && len(matchers) > 1 {
+		return nil, fmt.Errorf("matchers combined with OR at %s can't be written as a version 2 pact", path)
+	}
+
+	rule := make(map[string]interface{})
+	for _, raw := range matchers {
+		matcher, _ := raw.(map[string]interface{})
+		match, _ := matcher["match"].(string)
+
+		switch match {
+		case "", "type":
+			match = "type"
+		case "integer", "decimal", "number", "boolean":
+			log.Printf("[WARN] downgrading the %s matcher at %s to a type matcher for a version 2 pact", match, path)
+			match = "type"
+		case "equality":
+			continue
+		case "regex":
+		default:
+			return nil, fmt.Errorf("the %s matcher at %s can't be written as a version 2 pact", match, path)
+		}
+
+		if existing, ok := rule["match"]; ok && existing != match {
+			return nil, fmt.Errorf("the %s and %s matchers at %s can't be combined in a version 2 pact", existing, match, path)
+		}
+		rule["match"] = match
+		for _, key := range []string{"min", "max", "regex"} {
+			if value, ok := matcher[key]; ok {
+				rule[key] = value
+			}
+		}
+	}
+
+	if len(rule) == 0 {
+		return nil, nil
+	}
+
+	return rule, nil
+}
+
+// v4Fields are the interaction fields only written by version 4 pacts
+var v4Fields = []string{"key", "pending", "interactionMarkup", "pluginConfiguration"}
+
+// stripV4 removes the version 4 fields of interactions and messages written
+// as an earlier version
+func stripV4(interactions, messages []map[string]interface{}) {
+	for _, entries := range [][]map[string]interface{}{interactions, messages} {
+		for _, entry := range entries {
+			for _, field := range v4Fields {
+				delete(entry, field)
+			}
+		}
+	}
+}
+
+// toV4 converts a normalised interaction to the version 4 form
+func toV4(interaction map[string]interface{}) map[string]interface{} {
+	for _, part := range []string{"request", "response"} {
+		message, _ := interaction[part].(map[string]interface{})
+		if message == nil {
+			continue
+		}
+		if body, ok := message["body"]; ok {
+			message["body"] = v4Body(body, headerValue(message["headers"], "Content-Type"))
+		}
+		combineRules(message["matchingRules"])
+	}
+
+	return v4Interaction(interaction, "Synchronous/HTTP")
+}
+
+// toV4Message converts a version 3 message to a version 4 interaction
+func toV4Message(message map[string]interface{}) map[string]interface{} {
+	metadata, _ := message["metaData"].(map[string]interface{})
+	delete(message, "metaData")
+	if metadata != nil {
+		message["metadata"] = metadata
+	}
+
+	if contents, ok := message["contents"]; ok {
+		contentType, _ := metadata["contentType"].(string)
+		message["contents"] = v4Body(contents, contentType)
+	}
+	combineRules(message["matchingRules"])
+
+	return v4Interaction(message, "Asynchronous/Messages")
+}
+
+// toV4SynchronousMessage converts a normalised synchronous message back to
+// the version 4 form
+func toV4SynchronousMessage(message map[string]interface{}) map[string]interface{} {
+	parts := asSlice(message["response"])
+	if request, ok := message["request"].(map[string]interface{}); ok {
+		parts = append([]interface{}{request}, parts...)
+	}
+
+	for _, raw := range parts {
+		part, _ := raw.(map[string]interface{})
+		if part == nil {
+			continue
+		}
+		if contents, ok := part["contents"]; ok {
+			metadata, _ := part["metadata"].(map[string]interface{})
+			contentType, _ := metadata["contentType"].(string)
+			part["contents"] = v4Body(contents, contentType)
+		}
+		combineRules(part["matchingRules"])
+	}
+
+	return v4Interaction(message, "Synchronous/Messages")
+}
+
+// v4Interaction sets the type of an interaction, whether it is pending
+// (false unless already set), and a key identifying it, derived from its
+// contents unless already set
+func v4Interaction(interaction map[string]interface{}, kind string) map[string]interface{} {
+	if _, ok := interaction["pending"]; !ok {
+		interaction["pending"] = false
+	}
+
+	if key, _ := interaction["key"].(string); key == "" {
+		delete(interaction, "key")
+		content, _ := json.Marshal(interaction)
+		sum := sha256.Sum256(content)
+		interaction["key"] = hex.EncodeToString(sum[:8])
+	}
+
+	interaction["type"] = kind
+	return interaction
+}
+
+// combineRules writes how the matchers of each version 3 matching rule are
+// combined, which is explicit in version 4 pacts, defaulting to "AND"
+func combineRules(raw interface{}) {
+	categories, _ := raw.(map[string]interface{})
+	for category, value := range categories {
+		entries, _ := value.(map[string]interface{})
+		if category == "path" {
+			entries = map[string]interface{}{"": entries}
+		}
+		for _, entry := range entries {
+			if rule, ok := entry.(map[string]interface{}); ok {
+				if _, ok := rule["combine"]; !ok {
+					rule["combine"] = "AND"
+				}
+			}
+		}
+	}
+}
+
+// v4Body wraps a body with its content type, defaulting to JSON
+func v4Body(body interface{}, contentType string) map[string]interface{} {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	return map[string]interface{}{
+		"content":     body,
+		"contentType": contentType,
+		"encoded":     false,
+	}
+}
+
+// headerValue finds a header case insensitively
+func headerValue(raw interface{}, name string) string {
+	headers, _ := raw.(map[string]interface{})
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		switch value := headers[key].(type) {
+		case string:
+			return value
+		case []interface{}:
+			if len(value) > 0 {
+				return fmt.Sprint(value[0])
+			}
+		}
+	}
+
+	return ""
+}
+
+func asSlice(raw interface{}) []interface{} {
+	slice, _ := raw.([]interface{})
+	return slice
+}
+
+// setOrDelete sets the key if the value is non-empty, otherwise removes it
+func setOrDelete(m map[string]interface{}, key string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			m[key] = v
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			m[key] = v
+			return
+		}
+	}
+
+	delete(m, key)
+}
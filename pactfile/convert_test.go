@@ -0,0 +1,281 @@
+package pactfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func convert(t *testing.T, content string, version int) map[string]interface{} {
+	t.Helper()
+	out, err := ConvertSpecification([]byte(content), version)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err = json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid JSON written: %v", err)
+	}
+
+	return doc
+}
+
+func lookup(t *testing.T, doc interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	for _, key := range path {
+		switch k := key.(type) {
+		case string:
+			m, ok := doc.(map[string]interface{})
+			if !ok {
+				t.Fatalf("expected an object at %v, got %v", key, doc)
+			}
+			doc = m[k]
+		case int:
+			s, ok := doc.([]interface{})
+			if !ok || len(s) <= k {
+				t.Fatalf("expected an array at %v, got %v", key, doc)
+			}
+			doc = s[k]
+		}
+	}
+
+	return doc
+}
+
+func TestConvertSpecification_V2ToV3(t *testing.T) {
+	doc := convert(t, examplePact, 3)
+	interaction := lookup(t, doc, "interactions", 0)
+
+	states := lookup(t, interaction, "providerStates")
+	if !reflect.DeepEqual(states, []interface{}{map[string]interface{}{"name": "account 1 exists"}}) {
+		t.Fatalf("unexpected provider states %v", states)
+	}
+	if query := lookup(t, interaction, "request", "query", "page"); !reflect.DeepEqual(query, []interface{}{"1"}) {
+		t.Fatalf("unexpected query %v", query)
+	}
+	matchers := lookup(t, interaction, "response", "matchingRules", "body", "$.id", "matchers")
+	if !reflect.DeepEqual(matchers, []interface{}{map[string]interface{}{"match": "type"}}) {
+		t.Fatalf("unexpected matchers %v", matchers)
+	}
+	if version := lookup(t, doc, "metadata", "pactSpecification", "version"); version != "3.0.0" {
+		t.Fatalf("want version 3.0.0, got %v", version)
+	}
+}
+
+func TestConvertSpecification_V2ToV4(t *testing.T) {
+	doc := convert(t, examplePact, 4)
+	interaction := lookup(t, doc, "interactions", 0)
+
+	if kind := lookup(t, interaction, "type"); kind != "Synchronous/HTTP" {
+		t.Fatalf("want type Synchronous/HTTP, got %v", kind)
+	}
+	body := lookup(t, interaction, "response", "body")
+	want := map[string]interface{}{
+		"content":     map[string]interface{}{"id": float64(1)},
+		"contentType": "application/json",
+		"encoded":     false,
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Fatalf("want body %v, got %v", want, body)
+	}
+	if version := lookup(t, doc, "metadata", "pactSpecification", "version"); version != "4.0" {
+		t.Fatalf("want version 4.0, got %v", version)
+	}
+}
+
+func TestConvertSpecification_RoundTrip(t *testing.T) {
+	v4, err := ConvertSpecification([]byte(examplePact), 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc := convert(t, string(v4), 2)
+	interaction := lookup(t, doc, "interactions", 0)
+
+	if state := lookup(t, interaction, "providerState"); state != "account 1 exists" {
+		t.Fatalf("unexpected provider state %v", state)
+	}
+	if query := lookup(t, interaction, "request", "query"); query != "page=1" {
+		t.Fatalf("unexpected query %v", query)
+	}
+	if rule := lookup(t, interaction, "response", "matchingRules", "$.body.id", "match"); rule != "type" {
+		t.Fatalf("unexpected rule %v", rule)
+	}
+	if body := lookup(t, interaction, "response", "body", "id"); body != float64(1) {
+		t.Fatalf("unexpected body %v", body)
+	}
+}
+
+const v3Pact = `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "interactions": [
+    {
+      "description": "a request for an account",
+      "providerStates": [{"name": "account exists", "params": {"id": 1}}],
+      "request": {"method": "GET", "path": "/accounts/1"},
+      "response": {
+        "status": 200,
+        "headers": {"Content-Type": "application/json"},
+        "body": {"id": 1, "name": "x", "active": true},
+        "matchingRules": {
+          "body": {
+            "$.id": {"matchers": [{"match": "integer"}], "combine": "AND"},
+            "$.name": {"matchers": [{"match": "equality"}], "combine": "AND"},
+            "$.active": {"matchers": [{"match": "%s"}], "combine": "AND"}
+          },
+          "header": {"Content-Type": {"matchers": [{"match": "regex", "regex": "application/json.*"}]}}
+        },
+        "generators": {"body": {"$.id": {"type": "RandomInt"}}}
+      }
+    }
+  ],
+  "metadata": {"pactSpecification": {"version": "3.0.0"}}
+}`
+
+func TestConvertSpecification_Downgrade(t *testing.T) {
+	doc := convert(t, strings.Replace(v3Pact, "%s", "boolean", 1), 2)
+	response := lookup(t, doc, "interactions", 0, "response")
+
+	want := map[string]interface{}{
+		"$.body.id":              map[string]interface{}{"match": "type"},
+		"$.body.active":          map[string]interface{}{"match": "type"},
+		"$.headers.Content-Type": map[string]interface{}{"match": "regex", "regex": "application/json.*"},
+	}
+	if rules := lookup(t, response, "matchingRules"); !reflect.DeepEqual(rules, want) {
+		t.Fatalf("want rules %v, got %v", want, rules)
+	}
+	if generators := lookup(t, response, "generators"); generators != nil {
+		t.Fatalf("expected generators to be dropped, got %v", generators)
+	}
+	if state := lookup(t, doc, "interactions", 0, "providerState"); state != "account exists" {
+		t.Fatalf("unexpected provider state %v", state)
+	}
+}
+
+func TestConvertSpecification_Unsupported(t *testing.T) {
+	_, err := ConvertSpecification([]byte(strings.Replace(v3Pact, "%s", "timestamp", 1)), 2)
+	if err == nil || !strings.Contains(err.Error(), "timestamp matcher at $.body.active") {
+		t.Fatalf("expected an unsupported matcher error, got %v", err)
+	}
+
+	if _, err = ConvertSpecification([]byte(examplePact), 5); err == nil {
+		t.Fatal("expected an unsupported version error")
+	}
+
+	messages := `{"consumer": {"name": "a"}, "provider": {"name": "b"}, "messages": [{"description": "an event", "contents": {}}]}`
+	if _, err = ConvertSpecification([]byte(messages), 2); err == nil {
+		t.Fatal("expected an error writing messages as version 2")
+	}
+}
+
+func TestConvertSpecification_Messages(t *testing.T) {
+	messages := `{
+  "consumer": {"name": "a"},
+  "provider": {"name": "b"},
+  "messages": [{"description": "an event", "contents": {"id": 1}, "metaData": {"contentType": "application/vnd+json"}}],
+  "metadata": {"pactSpecification": {"version": "3.0.0"}}
+}`
+	doc := convert(t, messages, 4)
+	message := lookup(t, doc, "interactions", 0)
+
+	if kind := lookup(t, message, "type"); kind != "Asynchronous/Messages" {
+		t.Fatalf("want type Asynchronous/Messages, got %v", kind)
+	}
+	if contentType := lookup(t, message, "contents", "contentType"); contentType != "application/vnd+json" {
+		t.Fatalf("unexpected content type %v", contentType)
+	}
+
+	v3, _ := json.Marshal(doc)
+	doc = convert(t, string(v3), 3)
+	if contents := lookup(t, doc, "messages", 0, "contents", "id"); contents != float64(1) {
+		t.Fatalf("unexpected contents %v", contents)
+	}
+	if contentType := lookup(t, doc, "messages", 0, "metaData", "contentType"); contentType != "application/vnd+json" {
+		t.Fatalf("unexpected metadata %v", contentType)
+	}
+}
+
+func TestConvertSpecification_V4Fields(t *testing.T) {
+	doc := convert(t, strings.Replace(v3Pact, "%s", "boolean", 1), 4)
+	interaction := lookup(t, doc, "interactions", 0)
+
+	key, _ := lookup(t, interaction, "key").(string)
+	if len(key) != 16 || lookup(t, interaction, "pending") != false {
+		t.Fatalf("expected a key and pending flag, got %v and %v", key, lookup(t, interaction, "pending"))
+	}
+	if combine := lookup(t, interaction, "response", "matchingRules", "header", "Content-Type", "combine"); combine != "AND" {
+		t.Fatalf("expected the matchers to be combined with AND, got %v", combine)
+	}
+
+	// the key is stable, and kept when converted again
+	again := convert(t, strings.Replace(v3Pact, "%s", "boolean", 1), 4)
+	if other := lookup(t, again, "interactions", 0, "key"); other != key {
+		t.Fatalf("expected the key %s, got %v", key, other)
+	}
+	lookup(t, interaction, "response").(map[string]interface{})["status"] = 201
+	v4, _ := json.Marshal(doc)
+	if other := lookup(t, convert(t, string(v4), 4), "interactions", 0, "key"); other != key {
+		t.Fatalf("expected the key %s to be kept, got %v", key, other)
+	}
+
+	doc = convert(t, string(v4), 3)
+	for _, field := range []string{"key", "pending", "type"} {
+		if value, ok := lookup(t, doc, "interactions", 0).(map[string]interface{})[field]; ok {
+			t.Fatalf("expected %s to be removed from a version 3 pact, got %v", field, value)
+		}
+	}
+}
+
+func TestConvertSpecification_SynchronousMessages(t *testing.T) {
+	synchronous := `{
+  "consumer": {"name": "a"},
+  "provider": {"name": "b"},
+  "interactions": [{
+    "type": "Synchronous/Messages",
+    "description": "a request for an account",
+    "providerStates": [{"name": "account 1 exists"}],
+    "request": {
+      "contents": {"id": 1},
+      "metadata": {"contentType": "application/grpc"},
+      "matchingRules": {"$.body.id": {"match": "integer"}}
+    },
+    "response": [{
+      "contents": {"id": 1, "name": "Billy"},
+      "matchingRules": {"$.body.name": {"match": "type"}, "$.metadata.status": {"match": "regex", "regex": "^OK$"}}
+    }]
+  }]
+}`
+	doc := convert(t, synchronous, 4)
+	message := lookup(t, doc, "interactions", 0)
+
+	if kind := lookup(t, message, "type"); kind != "Synchronous/Messages" {
+		t.Fatalf("want type Synchronous/Messages, got %v", kind)
+	}
+	if contentType := lookup(t, message, "request", "contents", "contentType"); contentType != "application/grpc" {
+		t.Fatalf("unexpected content type %v", contentType)
+	}
+	if name := lookup(t, message, "response", 0, "contents", "content", "name"); name != "Billy" {
+		t.Fatalf("unexpected reply contents %v", name)
+	}
+	if combine := lookup(t, message, "request", "matchingRules", "body", "$.id", "combine"); combine != "AND" {
+		t.Fatalf("expected the request rules in the version 4 form, got %v", combine)
+	}
+	if match := lookup(t, message, "response", 0, "matchingRules", "metadata", "status", "matchers", 0, "match"); match != "regex" {
+		t.Fatalf("expected the reply metadata rules in the version 4 form, got %v", match)
+	}
+
+	v4, _ := json.Marshal(doc)
+	if again := lookup(t, convert(t, string(v4), 4), "interactions", 0, "request", "contents", "content", "id"); again != float64(1) {
+		t.Fatalf("expected the contents to be wrapped once, got %v", again)
+	}
+
+	for _, version := range []int{2, 3} {
+		_, err := ConvertSpecification([]byte(synchronous), version)
+		if want := fmt.Sprintf("synchronous message 'a request for an account' can't be written as a version %d pact", version); err == nil || err.Error() != want {
+			t.Fatalf("want %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package pactfile
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary describes a pact file in a pact directory
+type Summary struct {
+	// File is the path of the pact file
+	File string
+
+	Consumer string
+	Provider string
+
+	// SpecificationVersion is the version recorded in the pact metadata, if
+	// any e.g. "2.0.0"
+	SpecificationVersion string
+
+	// Interactions and Messages are the number of HTTP interactions and
+	// messages in the pact
+	Interactions int
+	Messages     int
+
+	// Modified is when the file was last written
+	Modified time.Time
+}
+
+// Problem is an issue found validating a pact file
+type Problem struct {
+	File    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.File, p.Message)
+}
+
+// CleanOptions select the stale pact files to remove from a pact directory.
+// A file is stale if it is selected by any of the options.
+type CleanOptions struct {
+	// ModifiedBefore selects files not written since the given time e.g. the
+	// start of the test run, such as the pacts of renamed providers
+	ModifiedBefore time.Time
+
+	// Providers, if set, selects the files for any other provider
+	Providers []string
+
+	// DryRun reports the stale files without removing them
+	DryRun bool
+}
+
+// pactFiles finds the pact (JSON) files in the directory and its
+// subdirectories, sorted by path
+func pactFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pact directory %s: %v", dir, err)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// List summarises each pact file in the directory and its subdirectories.
+// Files that can't be parsed are skipped with a warning, use Validate to
+// report them.
+func List(dir string) ([]Summary, error) {
+	files, err := pactFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]Summary, 0, len(files))
+	for _, file := range files {
+		summary, err := summarise(file)
+		if err != nil {
+			log.Println("[WARN] skipping pact file", file+":", err)
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// ReadAll reads each pact file in the directory and its subdirectories.
+// Files that can't be parsed are skipped with a warning, as by List.
+func ReadAll(dir string) ([]*Pact, error) {
+	files, err := pactFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pacts := make([]*Pact, 0, len(files))
+	for _, file := range files {
+		pact, err := Read(file)
+		if err != nil {
+			log.Println("[WARN] skipping pact file", file+":", err)
+			continue
+		}
+		pacts = append(pacts, pact)
+	}
+
+	return pacts, nil
+}
+
+func summarise(file string) (Summary, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	pact, err := Read(file)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{
+		File:                 file,
+		Consumer:             pact.Consumer.Name,
+		Provider:             pact.Provider.Name,
+		SpecificationVersion: pact.SpecificationVersion(),
+		Messages:             len(pact.Messages),
+		Modified:             info.ModTime(),
+	}
+	for _, interaction := range pact.Interactions {
+		if isMessage(interaction) {
+			summary.Messages++
+		} else {
+			summary.Interactions++
+		}
+	}
+
+	return summary, nil
+}
+
+// SpecificationVersion returns the specification version recorded in the
+// pact metadata, or an empty string if there is none
+func (p *Pact) SpecificationVersion() string {
+	for _, key := range []string{"pactSpecification", "pact-specification"} {
+		if spec, ok := p.Metadata[key].(map[string]interface{}); ok {
+			if version, ok := spec["version"].(string); ok {
+				return version
+			}
+		}
+	}
+	if version, ok := p.Metadata["pactSpecificationVersion"].(string); ok {
+		return version
+	}
+
+	return ""
+}
+
+// isMessage determines if a (version 4) interaction is a message, either
+// asynchronous or synchronous (request/reply)
+func isMessage(interaction Interaction) bool {
+	return strings.HasPrefix(interaction.Type, "Asynchronous/") || interaction.IsSynchronousMessage()
+}
+
+// Validate checks every pact file in the directory and its subdirectories,
+// such as before publishing, returning the problems found. The error is only
+// set if the directory can't be read.
+func Validate(dir string) ([]Problem, error) {
+	files, err := pactFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return []Problem{{File: dir, Message: "no pact files found"}}, nil
+	}
+
+	var problems []Problem
+	for _, file := range files {
+		for _, message := range validateFile(file) {
+			problems = append(problems, Problem{File: file, Message: message})
+		}
+	}
+
+	return problems, nil
+}
+
+// validateFile returns the problems with a single pact file
+func validateFile(file string) []string {
+	pact, err := Read(file)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	if pact.Consumer.Name == "" {
+		problems = append(problems, "missing consumer name")
+	}
+	if pact.Provider.Name == "" {
+		problems = append(problems, "missing provider name")
+	}
+	if len(pact.Interactions) == 0 && len(pact.Messages) == 0 {
+		problems = append(problems, "no interactions or messages")
+	}
+
+	seen := make(map[string]mergedEntry)
+	for i, interaction := range pact.Interactions {
+		name := fmt.Sprintf("interaction %d", i+1)
+		if interaction.Description == "" {
+			problems = append(problems, name+" has no description")
+		} else {
+			name = fmt.Sprintf("interaction '%s'", interaction.Description)
+		}
+
+		if !isMessage(interaction) {
+			if interaction.Request.Method == "" {
+				problems = append(problems, name+" has no request method")
+			}
+			if interaction.Request.Path == "" {
+				problems = append(problems, name+" has no request path")
+			}
+			if interaction.Response.Status == 0 {
+				problems = append(problems, name+" has no response status")
+			}
+		}
+
+		key := fmt.Sprintf("%s|%s|%s|%s", interaction.Description, interaction.ProviderState, interaction.LegacyProviderState, canonical(interaction.ProviderStates))
+		if _, err := mergeEntry(seen, key, file, interaction, interaction.Description); err != nil {
+			problems = append(problems, name+" is defined more than once, differently")
+		}
+	}
+
+	for i, message := range pact.Messages {
+		if message.Description == "" {
+			problems = append(problems, fmt.Sprintf("message %d has no description", i+1))
+		}
+	}
+
+	return problems
+}
+
+// Clean removes the stale pact files in the directory and its
+// subdirectories, returning the files removed (or that would be, for a dry
+// run). At least one of ModifiedBefore or Providers must be set.
+func Clean(dir string, options CleanOptions) ([]string, error) {
+	if options.ModifiedBefore.IsZero() && len(options.Providers) == 0 {
+		return nil, fmt.Errorf("no stale pact files selected, set a modification time or the current providers")
+	}
+
+	files, err := pactFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make(map[string]bool, len(options.Providers))
+	for _, provider := range options.Providers {
+		providers[provider] = true
+	}
+
+	var removed []string
+	for _, file := range files {
+		stale, err := isStale(file, options.ModifiedBefore, providers)
+		if err != nil {
+			return removed, err
+		}
+		if !stale {
+			continue
+		}
+
+		if !options.DryRun {
+			log.Println("[INFO] removing stale pact file", file)
+			if err = os.Remove(file); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, file)
+	}
+
+	return removed, nil
+}
+
+func isStale(file string, modifiedBefore time.Time, providers map[string]bool) (bool, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	if !modifiedBefore.IsZero() && info.ModTime().Before(modifiedBefore) {
+		return true, nil
+	}
+
+	if len(providers) == 0 {
+		return false, nil
+	}
+
+	pact, err := Read(file)
+	if err != nil {
+		log.Println("[WARN] not removing unparseable pact file", file+":", err)
+		return false, nil
+	}
+
+	return !providers[pact.Provider.Name], nil
+}
@@ -0,0 +1,141 @@
+package pactfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func pactDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "pact-go-directory")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err = ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("unable to write %s: %v", name, err)
+		}
+	}
+
+	return dir
+}
+
+func TestList(t *testing.T) {
+	v4, _ := ConvertSpecification([]byte(examplePact), 4)
+	dir := pactDir(t, map[string]string{
+		"billing-accounts.json":    examplePact,
+		"v4/billing-accounts.json": string(v4),
+		"notes.txt":                "not a pact",
+	})
+	defer os.RemoveAll(dir)
+
+	summaries, err := List(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("want 2 pacts, got %v", summaries)
+	}
+
+	s := summaries[0]
+	if s.Consumer != "billing" || s.Provider != "accounts" || s.SpecificationVersion != "2.0.0" || s.Interactions != 1 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	if summaries[1].SpecificationVersion != "4.0" || summaries[1].Interactions != 1 {
+		t.Fatalf("unexpected summary %+v", summaries[1])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": ""},
+  "interactions": [
+    {"description": "a request", "request": {"method": "GET", "path": "/"}, "response": {"status": 200}},
+    {"description": "a request", "request": {"method": "GET", "path": "/"}, "response": {"status": 404}},
+    {"description": "another request", "request": {"path": "/"}, "response": {}}
+  ]
+}`
+	dir := pactDir(t, map[string]string{
+		"billing-accounts.json": examplePact,
+		"billing-invalid.json":  invalid,
+		"broken.json":           `{"consumer":`,
+	})
+	defer os.RemoveAll(dir)
+
+	problems, err := Validate(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var messages []string
+	for _, problem := range problems {
+		if strings.HasSuffix(problem.File, "billing-accounts.json") {
+			t.Fatalf("unexpected problem with a valid pact: %v", problem)
+		}
+		messages = append(messages, problem.String())
+	}
+	all := strings.Join(messages, "\n")
+	for _, want := range []string{
+		"missing provider name",
+		"interaction 'a request' is defined more than once",
+		"interaction 'another request' has no request method",
+		"interaction 'another request' has no response status",
+		"broken.json: invalid pact file",
+	} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected a problem containing '%s', got:\n%s", want, all)
+		}
+	}
+}
+
+func TestValidate_Empty(t *testing.T) {
+	dir := pactDir(t, nil)
+	defer os.RemoveAll(dir)
+
+	problems, err := Validate(dir)
+	if err != nil || len(problems) != 1 {
+		t.Fatalf("expected a single problem, got %v and %v", problems, err)
+	}
+}
+
+func TestClean(t *testing.T) {
+	renamed := strings.Replace(examplePact, `"accounts"`, `"accounts-old"`, 1)
+	dir := pactDir(t, map[string]string{
+		"billing-accounts.json":     examplePact,
+		"billing-accounts-old.json": renamed,
+	})
+	defer os.RemoveAll(dir)
+
+	if _, err := Clean(dir, CleanOptions{}); err == nil {
+		t.Fatal("expected an error when no files are selected")
+	}
+
+	removed, err := Clean(dir, CleanOptions{Providers: []string{"accounts"}, DryRun: true})
+	if err != nil || len(removed) != 1 || !strings.HasSuffix(removed[0], "billing-accounts-old.json") {
+		t.Fatalf("unexpected dry run result %v, %v", removed, err)
+	}
+	if _, err = os.Stat(removed[0]); err != nil {
+		t.Fatalf("expected a dry run to keep the file: %v", err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "billing-accounts-old.json"), old, old)
+	removed, err = Clean(dir, CleanOptions{ModifiedBefore: time.Now().Add(-time.Minute)})
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("unexpected result %v, %v", removed, err)
+	}
+	if _, err = os.Stat(removed[0]); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale file to be removed: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "billing-accounts.json")); err != nil {
+		t.Fatalf("expected the current file to be kept: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package pactfile
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GenerationKey is the key of the pact file metadata recording when, and
+// from which version of the consumer, the pact was generated
+const GenerationKey = "generated"
+
+// Generation records when, and from which version of the consumer, a pact
+// file was generated
+type Generation struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// GitSHA is the commit of the consumer the pact was generated from
+	GitSHA string `json:"gitSha,omitempty"`
+
+	// Module and ModuleVersion are the consumer's Go module and its version
+	Module        string `json:"module,omitempty"`
+	ModuleVersion string `json:"moduleVersion,omitempty"`
+}
+
+// Generation returns when, and from which version of the consumer, the pact
+// was generated, if recorded
+func (p *Pact) Generation() (Generation, bool) {
+	var generation Generation
+
+	raw, ok := p.Metadata[GenerationKey]
+	if !ok {
+		return generation, false
+	}
+
+	content, err := json.Marshal(raw)
+	if err != nil {
+		return generation, false
+	}
+	if err = json.Unmarshal(content, &generation); err != nil || generation.Timestamp.IsZero() {
+		return generation, false
+	}
+
+	return generation, true
+}
@@ -0,0 +1,138 @@
+package pactfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Merge combines the interactions of multiple pact files for the same
+// consumer/provider pair (e.g. produced by different Go modules or test
+// binaries) into a single pact.
+//
+// Identical interactions are de-duplicated. It is an error for the files to
+// be for different pacticipants or specification versions, or for two
+// interactions with the same description and provider state to differ. The
+// metadata of the first file is kept.
+func Merge(files ...string) (*Pact, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no pact files to merge")
+	}
+
+	var merged *Pact
+	interactions := make(map[string]mergedEntry)
+	messages := make(map[string]mergedEntry)
+
+	for _, file := range files {
+		pact, err := Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read pact file %s: %v", file, err)
+		}
+
+		if merged == nil {
+			merged = &Pact{
+				Consumer: pact.Consumer,
+				Provider: pact.Provider,
+				Metadata: pact.Metadata,
+			}
+		} else if pact.Consumer.Name != merged.Consumer.Name || pact.Provider.Name != merged.Provider.Name {
+			return nil, fmt.Errorf("unable to merge pact file %s: it is between '%s' and '%s', expected '%s' and '%s'",
+				file, pact.Consumer.Name, pact.Provider.Name, merged.Consumer.Name, merged.Provider.Name)
+		} else if version, expected := pact.SpecificationVersion(), merged.SpecificationVersion(); version != expected {
+			return nil, fmt.Errorf("unable to merge pact file %s: it is a version %q pact, expected version %q", file, version, expected)
+		}
+
+		for _, interaction := range pact.Interactions {
+			key := fmt.Sprintf("%s|%s|%s|%s", interaction.Description, interaction.ProviderState, interaction.LegacyProviderState, canonical(interaction.ProviderStates))
+			added, err := mergeEntry(interactions, key, file, interaction, interaction.Description)
+			if err != nil {
+				return nil, err
+			}
+			if added {
+				merged.Interactions = append(merged.Interactions, interaction)
+			}
+		}
+
+		for _, message := range pact.Messages {
+			key := fmt.Sprintf("%s|%s", message.Description, canonical(message.ProviderStates))
+			added, err := mergeEntry(messages, key, file, message, message.Description)
+			if err != nil {
+				return nil, err
+			}
+			if added {
+				merged.Messages = append(merged.Messages, message)
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// Equivalent determines if two pacts contain the same pacticipants and
+// interactions, ignoring metadata and formatting
+func (p *Pact) Equivalent(other *Pact) bool {
+	contents := func(pact *Pact) string {
+		return canonical(Pact{
+			Consumer:     pact.Consumer,
+			Provider:     pact.Provider,
+			Interactions: pact.Interactions,
+			Messages:     pact.Messages,
+		})
+	}
+
+	return contents(p) == contents(other)
+}
+
+// Write serialises the pact to the given file
+func (p *Pact) Write(file string) error {
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+type mergedEntry struct {
+	file    string
+	content string
+}
+
+// mergeEntry records an interaction, returning true if it has not been seen
+// before, or an error if it conflicts with one that has
+func mergeEntry(seen map[string]mergedEntry, key, file string, value interface{}, description string) (bool, error) {
+	content := canonical(value)
+
+	existing, ok := seen[key]
+	if !ok {
+		seen[key] = mergedEntry{file: file, content: content}
+		return true, nil
+	}
+
+	if existing.content != content {
+		return false, fmt.Errorf("conflicting interaction '%s': it is defined differently in %s and %s", description, existing.file, file)
+	}
+
+	return false, nil
+}
+
+// canonical serialises a value in a form that is independent of the
+// formatting of any raw JSON it contains
+func canonical(value interface{}) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	// Numbers are kept as written, so large integers aren't rounded
+	var normalised interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err = decoder.Decode(&normalised); err != nil {
+		return string(content)
+	}
+
+	content, _ = json.Marshal(normalised)
+	return string(content)
+}
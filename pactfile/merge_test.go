@@ -0,0 +1,121 @@
+package pactfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePacts(t *testing.T, pacts ...string) ([]string, func()) {
+	dir, err := ioutil.TempDir("", "pactfile-merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make([]string, len(pacts))
+	for i, pact := range pacts {
+		files[i] = filepath.Join(dir, strings.Repeat("p", i+1)+".json")
+		if err = ioutil.WriteFile(files[i], []byte(pact), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return files, func() { os.RemoveAll(dir) }
+}
+
+const (
+	mergeA = `{"consumer":{"name":"billing"},"provider":{"name":"accounts"},"interactions":[
+		{"description":"get account","request":{"method":"GET","path":"/accounts/1"},"response":{"status":200,"body":{"id":1}}}
+	]}`
+	mergeB = `{"consumer":{"name":"billing"},"provider":{"name":"accounts"},"interactions":[
+		{"description":"get account","request":{"method":"GET","path":"/accounts/1"},"response":{"status":200,"body":{ "id": 1 }}},
+		{"description":"delete account","request":{"method":"DELETE","path":"/accounts/1"},"response":{"status":204}}
+	]}`
+	mergeConflict = `{"consumer":{"name":"billing"},"provider":{"name":"accounts"},"interactions":[
+		{"description":"get account","request":{"method":"GET","path":"/accounts/1"},"response":{"status":404}}
+	]}`
+	mergeOtherProvider = `{"consumer":{"name":"billing"},"provider":{"name":"payments"},"interactions":[]}`
+	mergeV3            = `{"consumer":{"name":"billing"},"provider":{"name":"accounts"},"interactions":[],"metadata":{"pactSpecification":{"version":"3.0.0"}}}`
+)
+
+func TestMerge(t *testing.T) {
+	files, cleanup := writePacts(t, mergeA, mergeB)
+	defer cleanup()
+
+	pact, err := Merge(files...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pact.Interactions) != 2 {
+		t.Fatalf("expected duplicate interactions to be removed, got %v", pact.Interactions)
+	}
+	if pact.Interactions[0].Description != "get account" || pact.Interactions[1].Description != "delete account" {
+		t.Fatalf("unexpected interactions %v", pact.Interactions)
+	}
+}
+
+func TestMerge_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		pacts []string
+		want  string
+	}{
+		{name: "conflicting interactions", pacts: []string{mergeA, mergeConflict}, want: "conflicting interaction 'get account'"},
+		{name: "different pacticipants", pacts: []string{mergeA, mergeOtherProvider}, want: "expected 'billing' and 'accounts'"},
+		{name: "different specification versions", pacts: []string{mergeA, mergeV3}, want: `it is a version "3.0.0" pact, expected version ""`},
+		{name: "invalid file", pacts: []string{mergeA, `{`}, want: "unable to read pact file"},
+		{name: "no files", want: "no pact files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, cleanup := writePacts(t, tt.pacts...)
+			defer cleanup()
+
+			_, err := Merge(files...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPact_Write(t *testing.T) {
+	files, cleanup := writePacts(t, mergeA)
+	defer cleanup()
+
+	pact, _ := Read(files[0])
+	out := files[0] + ".out"
+	if err := pact.Write(out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	written, err := Read(out)
+	if err != nil || len(written.Interactions) != 1 {
+		t.Fatalf("unexpected result %v, %v", written, err)
+	}
+}
+
+func TestPact_Equivalent(t *testing.T) {
+	a, _ := Parse([]byte(mergeA))
+	reformatted, _ := Parse([]byte(`{"provider":{"name":"accounts"},"consumer":{"name":"billing"},"metadata":{"pactSpecification":{"version":"2.0.0"}},"interactions":[
+		{"description":"get account","request":{"method":"GET","path":"/accounts/1"},"response":{"status":200,"body":{ "id" : 1 }}}
+	]}`))
+	conflict, _ := Parse([]byte(mergeConflict))
+
+	if !a.Equivalent(reformatted) {
+		t.Fatal("expected pacts differing only in formatting and metadata to be equivalent")
+	}
+	if a.Equivalent(conflict) {
+		t.Fatal("expected pacts with different interactions not to be equivalent")
+	}
+
+	large, _ := Parse([]byte(strings.Replace(mergeA, `"id":1`, `"id":9007199254740993`, 1)))
+	larger, _ := Parse([]byte(strings.Replace(mergeA, `"id":1`, `"id":9007199254740992`, 1)))
+	if large.Equivalent(larger) {
+		t.Fatal("expected pacts differing in a large number not to be equivalent")
+	}
+}
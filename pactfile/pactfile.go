@@ -0,0 +1,337 @@
+/*
+Package pactfile contains a model of a serialised pact file, and utilities to
+read and work with pact files written by the consumer DSL.
+
+Fields that this package does not need to interpret (e.g. bodies and matching
+rules) are kept in their raw form, and fields it doesn't model are kept in
+Extra, so that a file read and written again is not otherwise changed. Keys
+may be written in a different order.
+*/
+package pactfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+)
+
+// Pact is a serialised pact file
+type Pact struct {
+	Consumer     Pacticipant            `json:"consumer"`
+	Provider     Pacticipant            `json:"provider"`
+	Interactions []Interaction          `json:"interactions,omitempty"`
+	Messages     []Message              `json:"messages,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+
+	// Extra are the fields of the pact this package doesn't model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON writes the pact with its extra fields
+func (p Pact) MarshalJSON() ([]byte, error) {
+	type pact Pact
+	return encodeWithExtra(pact(p), p.Extra)
+}
+
+// UnmarshalJSON reads the pact, keeping the fields it doesn't model
+func (p *Pact) UnmarshalJSON(content []byte) (err error) {
+	type pact Pact
+	p.Extra, err = decodeWithExtra(content, (*pact)(p))
+	return err
+}
+
+// Pacticipant is a consumer or provider of a pact
+type Pacticipant struct {
+	Name string `json:"name"`
+}
+
+// Interaction is a single HTTP request/response interaction
+type Interaction struct {
+	// Type is the kind of interaction in version 4 pacts, where messages are
+	// also interactions e.g. "Synchronous/HTTP" or "Asynchronous/Messages"
+	Type string `json:"type,omitempty"`
+
+	Description    string          `json:"description"`
+	ProviderState  string          `json:"providerState,omitempty"`
+	ProviderStates json.RawMessage `json:"providerStates,omitempty"`
+
+	// LegacyProviderState is the provider state as written by older
+	// (version 1) pact files
+	LegacyProviderState string `json:"provider_state,omitempty"`
+
+	Request  Request           `json:"request"`
+	Response Response          `json:"response"`
+	Comments json.RawMessage   `json:"comments,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// BodyMatching is whether unexpected keys are allowed in the bodies of
+	// the interaction, "strict" or "postel"
+	BodyMatching string `json:"bodyMatching,omitempty"`
+
+	// BodyTransforms name the transforms applied to the "request" and
+	// "response" bodies of the interaction before they are matched
+	BodyTransforms map[string]string `json:"bodyTransforms,omitempty"`
+
+	// Key, Pending and InteractionMarkup are written by version 4 pacts: a
+	// key identifying the interaction, whether its failures are ignored
+	// while it is pending, and markup describing it (e.g. CommonMark)
+	Key               string          `json:"key,omitempty"`
+	Pending           bool            `json:"pending,omitempty"`
+	InteractionMarkup json.RawMessage `json:"interactionMarkup,omitempty"`
+
+	// Contents are the contents of a message, for "Asynchronous/Messages"
+	// interactions in version 4 pacts, which have no request or response
+	Contents json.RawMessage `json:"contents,omitempty"`
+
+	// MessageRequest and MessageResponses are the request and the possible
+	// replies of "Synchronous/Messages" interactions in version 4 pacts,
+	// written as their "request" and "response" in place of the HTTP ones
+	MessageRequest   *MessageContents  `json:"-"`
+	MessageResponses []MessageContents `json:"-"`
+
+	// Extra are the fields of the interaction this package doesn't model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MessageContents are the contents and metadata of the request or a reply of
+// a synchronous message
+type MessageContents struct {
+	Contents      json.RawMessage `json:"contents,omitempty"`
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	MatchingRules json.RawMessage `json:"matchingRules,omitempty"`
+	Generators    json.RawMessage `json:"generators,omitempty"`
+}
+
+// IsMessage determines if the interaction is a message in a version 4 pact
+func (i Interaction) IsMessage() bool {
+	return strings.HasPrefix(i.Type, "Asynchronous/Messages")
+}
+
+// IsSynchronousMessage determines if the interaction is a synchronous
+// (request/reply) message in a version 4 pact
+func (i Interaction) IsSynchronousMessage() bool {
+	return strings.HasPrefix(i.Type, "Synchronous/Messages")
+}
+
+// MarshalJSON omits the request and response of messages, and writes the
+// request and replies of synchronous messages in their place
+func (i Interaction) MarshalJSON() ([]byte, error) {
+	type interaction Interaction
+	content, err := encodeWithExtra(interaction(i), i.Extra)
+	if err != nil || !(i.IsMessage() || i.IsSynchronousMessage()) {
+		return content, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "request")
+	delete(fields, "response")
+
+	if i.IsSynchronousMessage() {
+		if i.MessageRequest != nil {
+			if fields["request"], err = json.Marshal(i.MessageRequest); err != nil {
+				return nil, err
+			}
+		}
+		if len(i.MessageResponses) > 0 {
+			if fields["response"], err = json.Marshal(i.MessageResponses); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON reads the request and replies of synchronous messages, whose
+// "response" is a list rather than a single HTTP response
+func (i *Interaction) UnmarshalJSON(content []byte) (err error) {
+	type interaction Interaction
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(content, &fields); err != nil {
+		return err
+	}
+	var kind string
+	if raw, ok := fields["type"]; ok {
+		if err = json.Unmarshal(raw, &kind); err != nil {
+			return err
+		}
+	}
+	if !strings.HasPrefix(kind, "Synchronous/Messages") {
+		i.Extra, err = decodeWithExtra(content, (*interaction)(i))
+		return err
+	}
+
+	request, response := fields["request"], fields["response"]
+	delete(fields, "request")
+	delete(fields, "response")
+	rest, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if i.Extra, err = decodeWithExtra(rest, (*interaction)(i)); err != nil {
+		return err
+	}
+
+	if len(request) > 0 {
+		i.MessageRequest = &MessageContents{}
+		if err = json.Unmarshal(request, i.MessageRequest); err != nil {
+			return err
+		}
+	}
+	if len(response) > 0 {
+		return json.Unmarshal(response, &i.MessageResponses)
+	}
+
+	return nil
+}
+
+// Request is the expected request of an interaction
+type Request struct {
+	Method        string          `json:"method"`
+	Path          string          `json:"path"`
+	Query         json.RawMessage `json:"query,omitempty"`
+	Headers       json.RawMessage `json:"headers,omitempty"`
+	Body          json.RawMessage `json:"body,omitempty"`
+	Trailers      json.RawMessage `json:"trailers,omitempty"`
+	MatchingRules json.RawMessage `json:"matchingRules,omitempty"`
+	Generators    json.RawMessage `json:"generators,omitempty"`
+
+	// Extra are the fields of the request this package doesn't model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON writes the request with its extra fields
+func (r Request) MarshalJSON() ([]byte, error) {
+	type request Request
+	return encodeWithExtra(request(r), r.Extra)
+}
+
+// UnmarshalJSON reads the request, keeping the fields it doesn't model
+func (r *Request) UnmarshalJSON(content []byte) (err error) {
+	type request Request
+	r.Extra, err = decodeWithExtra(content, (*request)(r))
+	return err
+}
+
+// Response is the expected response of an interaction
+type Response struct {
+	Status        int             `json:"status"`
+	Headers       json.RawMessage `json:"headers,omitempty"`
+	Body          json.RawMessage `json:"body,omitempty"`
+	Trailers      json.RawMessage `json:"trailers,omitempty"`
+	MatchingRules json.RawMessage `json:"matchingRules,omitempty"`
+	Generators    json.RawMessage `json:"generators,omitempty"`
+
+	// Extra are the fields of the response this package doesn't model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON writes the response with its extra fields
+func (r Response) MarshalJSON() ([]byte, error) {
+	type response Response
+	return encodeWithExtra(response(r), r.Extra)
+}
+
+// UnmarshalJSON reads the response, keeping the fields it doesn't model
+func (r *Response) UnmarshalJSON(content []byte) (err error) {
+	type response Response
+	r.Extra, err = decodeWithExtra(content, (*response)(r))
+	return err
+}
+
+// Message is a single asynchronous message interaction
+type Message struct {
+	Description    string          `json:"description"`
+	ProviderStates json.RawMessage `json:"providerStates,omitempty"`
+	Contents       json.RawMessage `json:"contents,omitempty"`
+	Metadata       json.RawMessage `json:"metaData,omitempty"`
+	MatchingRules  json.RawMessage `json:"matchingRules,omitempty"`
+
+	// Extra are the fields of the message this package doesn't model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON writes the message with its extra fields
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	return encodeWithExtra(message(m), m.Extra)
+}
+
+// UnmarshalJSON reads the message, keeping the fields it doesn't model
+func (m *Message) UnmarshalJSON(content []byte) (err error) {
+	type message Message
+	m.Extra, err = decodeWithExtra(content, (*message)(m))
+	return err
+}
+
+// decodeWithExtra decodes the JSON object into v, a pointer to a struct,
+// returning the fields that the struct has no field for
+func decodeWithExtra(content []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(content, v); err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(v).Elem()
+	for n := 0; n < t.NumField(); n++ {
+		name := strings.Split(t.Field(n).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			delete(fields, name)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return fields, nil
+}
+
+// encodeWithExtra encodes v as a JSON object, adding the extra fields that it
+// doesn't write itself
+func encodeWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	content, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return content, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// Read parses the pact file at the given location
+func Read(file string) (*Pact, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(content)
+}
+
+// Parse parses a serialised pact
+func Parse(content []byte) (*Pact, error) {
+	pact := &Pact{}
+	if err := json.Unmarshal(content, pact); err != nil {
+		return nil, fmt.Errorf("invalid pact file: %v", err)
+	}
+
+	return pact, nil
+}
@@ -0,0 +1,236 @@
+package pactfile
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+const examplePact = `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "interactions": [
+    {
+      "description": "a request for an account",
+      "providerState": "account 1 exists",
+      "request": {"method": "GET", "path": "/accounts/1", "query": "page=1"},
+      "response": {"status": 200, "body": {"id": 1}, "matchingRules": {"$.body.id": {"match": "type"}}}
+    }
+  ],
+  "metadata": {"pactSpecification": {"version": "2.0.0"}}
+}`
+
+func TestParse(t *testing.T) {
+	pact, err := Parse([]byte(examplePact))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pact.Consumer.Name != "billing" || pact.Provider.Name != "accounts" {
+		t.Fatalf("unexpected pacticipants %v and %v", pact.Consumer, pact.Provider)
+	}
+	if len(pact.Interactions) != 1 || pact.Interactions[0].Request.Path != "/accounts/1" {
+		t.Fatalf("unexpected interactions %v", pact.Interactions)
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	pact, _ := Parse([]byte(examplePact))
+	out, err := json.Marshal(pact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal([]byte(examplePact), &want)
+	json.Unmarshal(out, &got)
+
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if string(wantJSON) != string(gotJSON) {
+		t.Fatalf("want %s, got %s", wantJSON, gotJSON)
+	}
+}
+
+func TestParse_UnknownFields(t *testing.T) {
+	content := `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "_links": {"self": {"href": "https://broker.example.com/pacts/1"}},
+  "interactions": [
+    {
+      "description": "a request for an account",
+      "pluginConfiguration": {"protobuf": {"descriptorKey": "abc"}},
+      "request": {"method": "GET", "path": "/accounts/1", "x-transport": "h2"},
+      "response": {"status": 200, "x-latency": 10}
+    }
+  ],
+  "messages": [{"description": "an account event", "x-topic": "accounts"}]
+}`
+	pact, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := pact.Interactions[0].Extra["pluginConfiguration"]; !ok {
+		t.Fatalf("expected the unknown interaction field to be kept, got %v", pact.Interactions[0].Extra)
+	}
+
+	out, err := json.Marshal(pact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal([]byte(content), &want)
+	json.Unmarshal(out, &got)
+
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if string(wantJSON) != string(gotJSON) {
+		t.Fatalf("want %s, got %s", wantJSON, gotJSON)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	if _, err := Parse([]byte(`{"consumer":`)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestPact_Generation(t *testing.T) {
+	pact, _ := Parse([]byte(examplePact))
+	if _, ok := pact.Generation(); ok {
+		t.Fatal("expected no generation metadata")
+	}
+
+	pact.Metadata[GenerationKey] = map[string]interface{}{
+		"timestamp": "2020-01-02T03:04:05Z",
+		"gitSha":    "0a1b2c3",
+		"module":    "github.com/example/billing",
+	}
+	generation, ok := pact.Generation()
+	if !ok {
+		t.Fatal("expected generation metadata")
+	}
+	if !generation.Timestamp.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) || generation.GitSHA != "0a1b2c3" || generation.Module != "github.com/example/billing" {
+		t.Fatalf("unexpected generation %+v", generation)
+	}
+
+	pact.Metadata[GenerationKey] = map[string]interface{}{"gitSha": "0a1b2c3"}
+	if _, ok := pact.Generation(); ok {
+		t.Fatal("expected generation metadata without a timestamp to be ignored")
+	}
+}
+
+const v4Pact = `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "interactions": [
+    {
+      "type": "Synchronous/HTTP",
+      "key": "a1b2c3d4e5f60718",
+      "pending": true,
+      "description": "a request for an account",
+      "providerStates": [{"name": "account 1 exists"}],
+      "interactionMarkup": {"markup": "# Accounts", "markupType": "COMMON_MARK"},
+      "request": {"method": "GET", "path": "/accounts/1"},
+      "response": {
+        "status": 200,
+        "body": {"content": {"id": 1}, "contentType": "application/json", "encoded": false},
+        "matchingRules": {"body": {"$.id": {"combine": "AND", "matchers": [{"match": "integer"}]}}}
+      }
+    },
+    {
+      "type": "Asynchronous/Messages",
+      "key": "0f1e2d3c4b5a6978",
+      "description": "an account created event",
+      "contents": {"content": {"id": 1}, "contentType": "application/json", "encoded": false},
+      "metadata": {"contentType": "application/json"}
+    }
+  ],
+  "metadata": {"pactSpecification": {"version": "4.0"}}
+}`
+
+func TestParse_V4RoundTrip(t *testing.T) {
+	pact, err := Parse([]byte(v4Pact))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	http, message := pact.Interactions[0], pact.Interactions[1]
+	if http.IsMessage() || http.Key != "a1b2c3d4e5f60718" || !http.Pending || len(http.InteractionMarkup) == 0 {
+		t.Fatalf("unexpected interaction %+v", http)
+	}
+	if !message.IsMessage() || len(message.Contents) == 0 {
+		t.Fatalf("unexpected message %+v", message)
+	}
+
+	out, err := json.Marshal(pact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal([]byte(v4Pact), &want)
+	json.Unmarshal(out, &got)
+
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if string(wantJSON) != string(gotJSON) {
+		t.Fatalf("want %s, got %s", wantJSON, gotJSON)
+	}
+}
+
+const synchronousMessagePact = `{
+  "consumer": {"name": "billing"},
+  "provider": {"name": "accounts"},
+  "interactions": [
+    {
+      "type": "Synchronous/Messages",
+      "key": "1a2b3c4d5e6f7081",
+      "description": "a request for an account",
+      "providerStates": [{"name": "account 1 exists"}],
+      "request": {
+        "contents": {"content": {"id": 1}, "contentType": "application/json", "encoded": false},
+        "metadata": {"contentType": "application/json"}
+      },
+      "response": [
+        {
+          "contents": {"content": {"id": 1, "name": "Billy"}, "contentType": "application/json", "encoded": false},
+          "matchingRules": {"body": {"$.name": {"combine": "AND", "matchers": [{"match": "type"}]}}}
+        }
+      ]
+    }
+  ],
+  "metadata": {"pactSpecification": {"version": "4.0"}}
+}`
+
+func TestParse_SynchronousMessage(t *testing.T) {
+	pact, err := Parse([]byte(synchronousMessagePact))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message := pact.Interactions[0]
+	if !message.IsSynchronousMessage() || message.IsMessage() || message.MessageRequest == nil || len(message.MessageResponses) != 1 {
+		t.Fatalf("unexpected synchronous message %+v", message)
+	}
+	if len(message.MessageResponses[0].MatchingRules) == 0 || message.Request.Method != "" {
+		t.Fatalf("unexpected reply %+v", message.MessageResponses[0])
+	}
+
+	out, err := json.Marshal(pact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal([]byte(synchronousMessagePact), &want)
+	json.Unmarshal(out, &got)
+
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if string(wantJSON) != string(gotJSON) {
+		t.Fatalf("want %s, got %s", wantJSON, gotJSON)
+	}
+}
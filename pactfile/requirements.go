@@ -0,0 +1,210 @@
+package pactfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// Requirements are what a provider must implement to verify a set of pacts
+type Requirements struct {
+	// States are the provider states, by name
+	States []string
+
+	// Endpoints are the HTTP endpoints requested
+	Endpoints []Endpoint
+
+	// ContentTypes are the media types of requests, responses and messages
+	ContentTypes []string
+
+	// Messages are the descriptions of the messages to be produced
+	Messages []string
+}
+
+// Endpoint is an HTTP endpoint required by one or more consumers
+type Endpoint struct {
+	Method    string
+	Path      string
+	Consumers []string
+}
+
+// RequiredBy reports the provider states, endpoints, content types and
+// messages that the pacts require, without verifying them
+func RequiredBy(pacts ...*Pact) Requirements {
+	states := map[string]bool{}
+	contentTypes := map[string]bool{}
+	messages := map[string]bool{}
+	endpoints := map[string]*Endpoint{}
+
+	for _, pact := range pacts {
+		for _, interaction := range pact.Interactions {
+			for _, state := range interaction.States() {
+				states[state] = true
+			}
+
+			if interaction.IsSynchronousMessage() {
+				messages[interaction.Description] = true
+				if interaction.MessageRequest != nil {
+					if contentType := headerContentType(interaction.MessageRequest.Metadata); contentType != "" {
+						contentTypes[contentType] = true
+					}
+				}
+				continue
+			}
+
+			method := strings.ToUpper(interaction.Request.Method)
+			key := method + " " + interaction.Request.Path
+			endpoint, ok := endpoints[key]
+			if !ok {
+				endpoint = &Endpoint{Method: method, Path: interaction.Request.Path}
+				endpoints[key] = endpoint
+			}
+			endpoint.addConsumer(pact.Consumer.Name)
+
+			for _, headers := range []json.RawMessage{interaction.Request.Headers, interaction.Response.Headers} {
+				if contentType := headerContentType(headers); contentType != "" {
+					contentTypes[contentType] = true
+				}
+			}
+		}
+
+		for _, message := range pact.Messages {
+			for _, state := range stateNames(message.ProviderStates) {
+				states[state] = true
+			}
+			messages[message.Description] = true
+
+			if contentType := headerContentType(message.Metadata); contentType != "" {
+				contentTypes[contentType] = true
+			}
+		}
+	}
+
+	requirements := Requirements{
+		States:       sortedKeys(states),
+		ContentTypes: sortedKeys(contentTypes),
+		Messages:     sortedKeys(messages),
+	}
+	for _, key := range sortedKeys(endpointKeys(endpoints)) {
+		requirements.Endpoints = append(requirements.Endpoints, *endpoints[key])
+	}
+
+	return requirements
+}
+
+// String renders the requirements as a human readable report
+func (r Requirements) String() string {
+	var b strings.Builder
+
+	b.WriteString("Provider states:\n")
+	for _, state := range r.States {
+		fmt.Fprintf(&b, "  %s\n", state)
+	}
+	b.WriteString("Endpoints:\n")
+	for _, endpoint := range r.Endpoints {
+		fmt.Fprintf(&b, "  %s %s (%s)\n", endpoint.Method, endpoint.Path, strings.Join(endpoint.Consumers, ", "))
+	}
+	b.WriteString("Content types:\n")
+	for _, contentType := range r.ContentTypes {
+		fmt.Fprintf(&b, "  %s\n", contentType)
+	}
+	if len(r.Messages) > 0 {
+		b.WriteString("Messages:\n")
+		for _, message := range r.Messages {
+			fmt.Fprintf(&b, "  %s\n", message)
+		}
+	}
+
+	return b.String()
+}
+
+// States returns the names of the provider states of the interaction,
+// whichever pact specification version it was written with
+func (i Interaction) States() []string {
+	states := stateNames(i.ProviderStates)
+	for _, state := range []string{i.ProviderState, i.LegacyProviderState} {
+		if state != "" {
+			states = append(states, state)
+		}
+	}
+
+	return states
+}
+
+func (e *Endpoint) addConsumer(consumer string) {
+	for _, existing := range e.Consumers {
+		if existing == consumer {
+			return
+		}
+	}
+	e.Consumers = append(e.Consumers, consumer)
+	sort.Strings(e.Consumers)
+}
+
+// stateNames extracts the names of version 3 provider states
+func stateNames(raw json.RawMessage) []string {
+	var states []struct {
+		Name string `json:"name"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &states) != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(states))
+	for _, state := range states {
+		if state.Name != "" {
+			names = append(names, state.Name)
+		}
+	}
+
+	return names
+}
+
+// headerContentType returns the media type of the Content-Type header (or
+// contentType message metadata), without any parameters
+func headerContentType(raw json.RawMessage) string {
+	var headers map[string]interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &headers) != nil {
+		return ""
+	}
+
+	for name, value := range headers {
+		if !strings.EqualFold(name, "Content-Type") && name != "contentType" {
+			continue
+		}
+		if values, ok := value.([]interface{}); ok && len(values) > 0 {
+			value = values[0]
+		}
+		contentType, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			return mediaType
+		}
+		return contentType
+	}
+
+	return ""
+}
+
+func endpointKeys(endpoints map[string]*Endpoint) map[string]bool {
+	keys := make(map[string]bool, len(endpoints))
+	for key := range endpoints {
+		keys[key] = true
+	}
+
+	return keys
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
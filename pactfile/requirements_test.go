@@ -0,0 +1,79 @@
+package pactfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequiredBy(t *testing.T) {
+	web, err := Parse([]byte(`{
+		"consumer": {"name": "web"},
+		"provider": {"name": "orders"},
+		"interactions": [
+			{
+				"description": "a request for an order",
+				"providerState": "order 1 exists",
+				"request": {"method": "get", "path": "/orders/1", "headers": {"Accept": "application/json"}},
+				"response": {"status": 200, "headers": {"Content-Type": "application/json; charset=utf-8"}}
+			},
+			{
+				"description": "a request to create an order",
+				"providerStates": [{"name": "a customer exists"}, {"name": "stock is available"}],
+				"request": {"method": "POST", "path": "/orders", "headers": {"content-type": "application/vnd.orders+json"}},
+				"response": {"status": 201}
+			}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mobile, err := Parse([]byte(`{
+		"consumer": {"name": "mobile"},
+		"provider": {"name": "orders"},
+		"interactions": [
+			{
+				"description": "a request for an order",
+				"provider_state": "order 1 exists",
+				"request": {"method": "GET", "path": "/orders/1"},
+				"response": {"status": 200}
+			},
+			{
+				"type": "Synchronous/Messages",
+				"description": "a request to price an order",
+				"providerStates": [{"name": "order 1 exists"}],
+				"request": {"contents": {"id": 1}, "metadata": {"contentType": "application/grpc"}},
+				"response": [{"contents": {"total": 10}}]
+			}
+		],
+		"messages": [
+			{
+				"description": "an order created event",
+				"providerStates": [{"name": "an order is created"}],
+				"metaData": {"contentType": "application/avro"}
+			}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requirements := RequiredBy(web, mobile)
+
+	expected := Requirements{
+		States:       []string{"a customer exists", "an order is created", "order 1 exists", "stock is available"},
+		ContentTypes: []string{"application/avro", "application/grpc", "application/json", "application/vnd.orders+json"},
+		Messages:     []string{"a request to price an order", "an order created event"},
+		Endpoints: []Endpoint{
+			{Method: "GET", Path: "/orders/1", Consumers: []string{"mobile", "web"}},
+			{Method: "POST", Path: "/orders", Consumers: []string{"web"}},
+		},
+	}
+	if !reflect.DeepEqual(requirements, expected) {
+		t.Fatalf("want %+v, got %+v", expected, requirements)
+	}
+
+	if report := requirements.String(); !strings.Contains(report, "GET /orders/1 (mobile, web)") {
+		t.Fatalf("unexpected report:\n%s", report)
+	}
+}
@@ -0,0 +1,317 @@
+package pactfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Statistics describe a set of pacts, e.g. for a review of the contracts
+// between an organisation's services
+type Statistics struct {
+	Pacts        int `json:"pacts"`
+	Interactions int `json:"interactions"`
+	Messages     int `json:"messages"`
+
+	// Consumers are the interactions and messages of each consumer, sorted
+	// by name
+	Consumers []ConsumerStatistics `json:"consumers"`
+
+	// Matchers counts the matching rules by type e.g. "type" or "regex".
+	// Version 2 rules with only a min or max count as "type".
+	Matchers map[string]int `json:"matchers"`
+
+	// Endpoints are the HTTP endpoints requested, the most popular (used by
+	// the most consumers, then by the most interactions) first
+	Endpoints []EndpointStatistics `json:"endpoints"`
+
+	// The average sizes, in bytes, of the request and response bodies and
+	// message contents, of those that have one
+	AverageRequestBodySize  float64 `json:"averageRequestBodySize"`
+	AverageResponseBodySize float64 `json:"averageResponseBodySize"`
+	AverageMessageSize      float64 `json:"averageMessageSize"`
+}
+
+// ConsumerStatistics are the interactions and messages of a consumer, across
+// its providers
+type ConsumerStatistics struct {
+	Consumer     string   `json:"consumer"`
+	Providers    []string `json:"providers"`
+	Interactions int      `json:"interactions"`
+	Messages     int      `json:"messages"`
+}
+
+// EndpointStatistics are the interactions of all consumers with an endpoint
+type EndpointStatistics struct {
+	Method       string   `json:"method"`
+	Path         string   `json:"path"`
+	Consumers    []string `json:"consumers"`
+	Interactions int      `json:"interactions"`
+}
+
+// Stats computes statistics over the pacts
+func Stats(pacts ...*Pact) Statistics {
+	stats := Statistics{Pacts: len(pacts), Matchers: map[string]int{}}
+	consumers := map[string]*ConsumerStatistics{}
+	endpoints := map[string]*EndpointStatistics{}
+	var requests, responses, messages average
+
+	for _, pact := range pacts {
+		consumer, ok := consumers[pact.Consumer.Name]
+		if !ok {
+			consumer = &ConsumerStatistics{Consumer: pact.Consumer.Name}
+			consumers[pact.Consumer.Name] = consumer
+		}
+		consumer.Providers = addName(consumer.Providers, pact.Provider.Name)
+
+		for _, interaction := range pact.Interactions {
+			if interaction.IsMessage() {
+				consumer.Messages++
+				messages.add(bodySize(interaction.Contents))
+				continue
+			}
+			if interaction.IsSynchronousMessage() {
+				consumer.Messages++
+				parts := interaction.MessageResponses
+				if interaction.MessageRequest != nil {
+					parts = append([]MessageContents{*interaction.MessageRequest}, parts...)
+				}
+				for _, part := range parts {
+					messages.add(bodySize(part.Contents))
+					countMatchers(stats.Matchers, part.MatchingRules)
+				}
+				continue
+			}
+			consumer.Interactions++
+			requests.add(bodySize(interaction.Request.Body))
+			responses.add(bodySize(interaction.Response.Body))
+			for _, rules := range []json.RawMessage{interaction.Request.MatchingRules, interaction.Response.MatchingRules} {
+				countMatchers(stats.Matchers, rules)
+			}
+
+			method := strings.ToUpper(interaction.Request.Method)
+			key := method + " " + interaction.Request.Path
+			endpoint, ok := endpoints[key]
+			if !ok {
+				endpoint = &EndpointStatistics{Method: method, Path: interaction.Request.Path}
+				endpoints[key] = endpoint
+			}
+			endpoint.Consumers = addName(endpoint.Consumers, pact.Consumer.Name)
+			endpoint.Interactions++
+		}
+
+		for _, message := range pact.Messages {
+			consumer.Messages++
+			messages.add(bodySize(message.Contents))
+			countMatchers(stats.Matchers, message.MatchingRules)
+		}
+	}
+
+	for _, name := range sortedKeys(consumerKeys(consumers)) {
+		stats.Consumers = append(stats.Consumers, *consumers[name])
+		stats.Interactions += consumers[name].Interactions
+		stats.Messages += consumers[name].Messages
+	}
+
+	for _, endpoint := range endpoints {
+		stats.Endpoints = append(stats.Endpoints, *endpoint)
+	}
+	sort.Slice(stats.Endpoints, func(i, j int) bool {
+		a, b := stats.Endpoints[i], stats.Endpoints[j]
+		if len(a.Consumers) != len(b.Consumers) {
+			return len(a.Consumers) > len(b.Consumers)
+		}
+		if a.Interactions != b.Interactions {
+			return a.Interactions > b.Interactions
+		}
+		return a.Method+" "+a.Path < b.Method+" "+b.Path
+	})
+
+	stats.AverageRequestBodySize = requests.value()
+	stats.AverageResponseBodySize = responses.value()
+	stats.AverageMessageSize = messages.value()
+
+	return stats
+}
+
+// String renders the statistics as a human readable report
+func (s Statistics) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d pact(s), %d interaction(s), %d message(s)\n", s.Pacts, s.Interactions, s.Messages)
+	b.WriteString("Consumers:\n")
+	for _, consumer := range s.Consumers {
+		fmt.Fprintf(&b, "  %s: %d interaction(s), %d message(s) with %s\n", consumer.Consumer, consumer.Interactions, consumer.Messages, strings.Join(consumer.Providers, ", "))
+	}
+	b.WriteString("Matchers:\n")
+	for _, matcher := range sortedKeys(matcherKeys(s.Matchers)) {
+		fmt.Fprintf(&b, "  %s: %d\n", matcher, s.Matchers[matcher])
+	}
+	b.WriteString("Endpoints:\n")
+	for _, endpoint := range s.Endpoints {
+		fmt.Fprintf(&b, "  %s %s: %d interaction(s) (%s)\n", endpoint.Method, endpoint.Path, endpoint.Interactions, strings.Join(endpoint.Consumers, ", "))
+	}
+	fmt.Fprintf(&b, "Average sizes: request body %.1f bytes, response body %.1f bytes, message %.1f bytes\n", s.AverageRequestBodySize, s.AverageResponseBodySize, s.AverageMessageSize)
+
+	return b.String()
+}
+
+// WriteJSON writes the statistics as indented JSON
+func (s Statistics) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(s)
+}
+
+// WriteCSV writes the statistics as CSV with the columns statistic, key and
+// value, e.g. "endpoint_interactions,GET /users,3"
+func (s Statistics) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	row := func(statistic string, key string, value string) {
+		out.Write([]string{statistic, key, value}) // nolint:errcheck
+	}
+	count := func(statistic string, key string, value int) {
+		row(statistic, key, strconv.Itoa(value))
+	}
+	size := func(key string, value float64) {
+		row("average_size", key, strconv.FormatFloat(value, 'f', 1, 64))
+	}
+
+	row("statistic", "key", "value")
+	count("total", "pacts", s.Pacts)
+	count("total", "interactions", s.Interactions)
+	count("total", "messages", s.Messages)
+	for _, consumer := range s.Consumers {
+		count("consumer_interactions", consumer.Consumer, consumer.Interactions)
+		count("consumer_messages", consumer.Consumer, consumer.Messages)
+	}
+	for _, matcher := range sortedKeys(matcherKeys(s.Matchers)) {
+		count("matcher", matcher, s.Matchers[matcher])
+	}
+	for _, endpoint := range s.Endpoints {
+		count("endpoint_interactions", endpoint.Method+" "+endpoint.Path, endpoint.Interactions)
+		count("endpoint_consumers", endpoint.Method+" "+endpoint.Path, len(endpoint.Consumers))
+	}
+	size("request_body", s.AverageRequestBodySize)
+	size("response_body", s.AverageResponseBodySize)
+	size("message", s.AverageMessageSize)
+
+	out.Flush()
+
+	return out.Error()
+}
+
+// average is a running mean of sizes, ignoring missing (negative) ones
+type average struct {
+	total int
+	count int
+}
+
+func (a *average) add(size int) {
+	if size >= 0 {
+		a.total += size
+		a.count++
+	}
+}
+
+func (a average) value() float64 {
+	if a.count == 0 {
+		return 0
+	}
+
+	return float64(a.total) / float64(a.count)
+}
+
+// bodySize is the size of a body (or message contents) in bytes: the length
+// of a text body, or of the compacted JSON, unwrapping version 4 bodies.
+// Missing bodies have a size of -1.
+func bodySize(raw json.RawMessage) int {
+	var body interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &body) != nil || body == nil {
+		return -1
+	}
+
+	if wrapped, ok := body.(map[string]interface{}); ok && isV4Body(wrapped) {
+		if body = wrapped["content"]; body == nil {
+			return -1
+		}
+	}
+	if text, ok := body.(string); ok {
+		return len(text)
+	}
+
+	content, _ := json.Marshal(body)
+
+	return len(content)
+}
+
+// countMatchers counts the matching rules by type, in either the version 2
+// or 3 (and 4) form
+func countMatchers(counts map[string]int, raw json.RawMessage) {
+	var rules interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &rules) != nil {
+		return
+	}
+
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if match, ok := v["match"].(string); ok {
+				counts[match]++
+				return
+			}
+			_, min := v["min"]
+			_, max := v["max"]
+			_, matchers := v["matchers"]
+			if (min || max) && !matchers {
+				counts["type"]++
+				return
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(rules)
+}
+
+// addName adds the name to the sorted names, if it isn't already present
+func addName(names []string, name string) []string {
+	for _, existing := range names {
+		if existing == name {
+			return names
+		}
+	}
+	names = append(names, name)
+	sort.Strings(names)
+
+	return names
+}
+
+func consumerKeys(consumers map[string]*ConsumerStatistics) map[string]bool {
+	keys := make(map[string]bool, len(consumers))
+	for key := range consumers {
+		keys[key] = true
+	}
+
+	return keys
+}
+
+func matcherKeys(matchers map[string]int) map[string]bool {
+	keys := make(map[string]bool, len(matchers))
+	for key := range matchers {
+		keys[key] = true
+	}
+
+	return keys
+}
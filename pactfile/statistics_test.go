@@ -0,0 +1,100 @@
+package pactfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	web, err := Parse([]byte(`{
+		"consumer": {"name": "web"},
+		"provider": {"name": "orders"},
+		"interactions": [
+			{
+				"description": "a request for an order",
+				"request": {"method": "get", "path": "/orders/1"},
+				"response": {"status": 200, "body": {"id": 1}, "matchingRules": {"$.body.id": {"match": "type"}, "$.body": {"min": 1}}}
+			},
+			{
+				"description": "a request to create an order",
+				"request": {"method": "POST", "path": "/orders", "body": "text"},
+				"response": {"status": 201}
+			}
+		],
+		"metadata": {"pactSpecification": {"version": "2.0.0"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mobile, err := Parse([]byte(`{
+		"consumer": {"name": "mobile"},
+		"provider": {"name": "orders"},
+		"interactions": [
+			{
+				"type": "Synchronous/HTTP",
+				"description": "a request for an order",
+				"request": {"method": "GET", "path": "/orders/1"},
+				"response": {
+					"status": 200,
+					"body": {"content": {"id": 100}, "contentType": "application/json"},
+					"matchingRules": {"body": {"$.id": {"combine": "AND", "matchers": [{"match": "integer"}, {"match": "regex", "regex": "\\d+"}]}}}
+				}
+			},
+			{
+				"type": "Asynchronous/Messages",
+				"description": "an order created event",
+				"contents": {"content": {"id": 1}, "contentType": "application/json"}
+			}
+		],
+		"metadata": {"pactSpecification": {"version": "4.0"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := Stats(web, mobile)
+
+	expected := Statistics{
+		Pacts:        2,
+		Interactions: 3,
+		Messages:     1,
+		Consumers: []ConsumerStatistics{
+			{Consumer: "mobile", Providers: []string{"orders"}, Interactions: 1, Messages: 1},
+			{Consumer: "web", Providers: []string{"orders"}, Interactions: 2},
+		},
+		Matchers: map[string]int{"type": 2, "integer": 1, "regex": 1},
+		Endpoints: []EndpointStatistics{
+			{Method: "GET", Path: "/orders/1", Consumers: []string{"mobile", "web"}, Interactions: 2},
+			{Method: "POST", Path: "/orders", Consumers: []string{"web"}, Interactions: 1},
+		},
+		AverageRequestBodySize:  4,
+		AverageResponseBodySize: 9,
+		AverageMessageSize:      8,
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Fatalf("want %+v, got %+v", expected, stats)
+	}
+
+	if report := stats.String(); !strings.Contains(report, "GET /orders/1: 2 interaction(s) (mobile, web)") {
+		t.Fatalf("unexpected report:\n%s", report)
+	}
+
+	var out bytes.Buffer
+	if err = stats.WriteCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range []string{"statistic,key,value\n", "consumer_interactions,web,2\n", "matcher,type,2\n", "endpoint_consumers,GET /orders/1,2\n", "average_size,response_body,9.0\n"} {
+		if !strings.Contains(out.String(), row) {
+			t.Fatalf("expected the row %q in:\n%s", row, out.String())
+		}
+	}
+
+	out.Reset()
+	var decoded Statistics
+	if err = stats.WriteJSON(&out); err != nil || json.Unmarshal(out.Bytes(), &decoded) != nil || !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("expected the statistics as JSON, got %v:\n%s", err, out.String())
+	}
+}
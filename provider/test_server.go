@@ -0,0 +1,441 @@
+/*
+Package provider verifies pacts against a provider's http.Handler in ordinary
+Go tests, without the provider verifier, reporting each interaction as a
+subtest:
+
+	func TestProvider(t *testing.T) {
+		server := provider.NewTestServer(api.Handler(), "../pacts/web-orders.json")
+		defer server.Close()
+
+		server.StateHandlers = types.StateHandlers{
+			"order 1 exists": func() error { return db.Insert(order1) },
+		}
+		server.Verify(t)
+	}
+
+Responses are matched following the pact specification: the status and
+expected headers must be equal unless matched by a rule, and the body is
+matched by its rules (see dsl.ExplainRules), with every expected key
+required. Use dsl.VerifyProvider for full verification, publishing results
+to a Pact Broker.
+*/
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/dsl"
+	"github.com/pact-foundation/pact-go/pactfile"
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// TestServer is an httptest.Server serving the provider's handler, which
+// verifies the interactions of pact files against it
+type TestServer struct {
+	*httptest.Server
+
+	// PactFiles are the pact files (or directories of them) to verify
+	PactFiles []string
+
+	// StateHandlers set up the provider states of interactions. States
+	// without a handler are logged and otherwise ignored.
+	StateHandlers types.StateHandlers
+}
+
+// NewTestServer starts a server for the handler, to verify the pact files
+// (or directories of them) against with Verify. Close it when done.
+func NewTestServer(handler http.Handler, pactFiles ...string) *TestServer {
+	return &TestServer{
+		Server:    httptest.NewServer(handler),
+		PactFiles: pactFiles,
+	}
+}
+
+// pact is a pact file, in the version 3 form
+type pact struct {
+	Consumer struct {
+		Name string `json:"name"`
+	} `json:"consumer"`
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Description    string `json:"description"`
+	ProviderStates []struct {
+		Name string `json:"name"`
+	} `json:"providerStates"`
+	Request struct {
+		Method  string                 `json:"method"`
+		Path    string                 `json:"path"`
+		Query   map[string][]string    `json:"query"`
+		Headers map[string]interface{} `json:"headers"`
+		Body    json.RawMessage        `json:"body"`
+	} `json:"request"`
+	Response struct {
+		Status        int                        `json:"status"`
+		Headers       map[string]interface{}     `json:"headers"`
+		Body          json.RawMessage            `json:"body"`
+		MatchingRules map[string]json.RawMessage `json:"matchingRules"`
+	} `json:"response"`
+}
+
+// Verify verifies each interaction of the pact files against the handler,
+// as a subtest named after its consumer and description
+func (s *TestServer) Verify(t *testing.T) {
+	t.Helper()
+
+	files, err := s.files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no pact files found in %s", strings.Join(s.PactFiles, ", "))
+	}
+
+	for _, file := range files {
+		p, err := readPact(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(p.Consumer.Name, func(t *testing.T) {
+			for _, i := range p.Interactions {
+				i := i
+				t.Run(i.Description, func(t *testing.T) {
+					if err := s.setUp(t, i); err != nil {
+						t.Fatal(err)
+					}
+					mismatches, err := s.verify(i)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, mismatch := range mismatches {
+						t.Error(mismatch)
+					}
+				})
+			}
+		})
+	}
+}
+
+// files expands directories of pact files
+func (s *TestServer) files() ([]string, error) {
+	var files []string
+	for _, location := range s.PactFiles {
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, location)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(location, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
+
+// readPact reads a pact file of any specification version
+func readPact(file string) (*pact, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := pactfile.ConvertSpecification(content, 3)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pact file %s: %v", file, err)
+	}
+
+	var p pact
+	if err = json.Unmarshal(converted, &p); err != nil {
+		return nil, fmt.Errorf("unable to read pact file %s: %v", file, err)
+	}
+
+	return &p, nil
+}
+
+// setUp runs the state handlers of the interaction's provider states
+func (s *TestServer) setUp(t *testing.T, i interaction) error {
+	for _, state := range i.ProviderStates {
+		handler, ok := s.StateHandlers[state.Name]
+		if !ok {
+			t.Logf("no state handler for state '%s'", state.Name)
+			continue
+		}
+		if err := handler(); err != nil {
+			return fmt.Errorf("state handler for '%s' failed: %v", state.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// verify sends the interaction's request to the server, returning how the
+// response differs from the expected response
+func (s *TestServer) verify(i interaction) ([]string, error) {
+	req, err := i.request(s.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.mismatches(res.StatusCode, res.Header, body)
+}
+
+// request builds the interaction's request to the server
+func (i interaction) request(server string) (*http.Request, error) {
+	target := server + i.Request.Path
+	if len(i.Request.Query) > 0 {
+		target += "?" + url.Values(i.Request.Query).Encode()
+	}
+
+	var body []byte
+	if len(i.Request.Body) > 0 && string(i.Request.Body) != "null" {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, i.Request.Body); err != nil {
+			return nil, fmt.Errorf("invalid request body: %v", err)
+		}
+		body = compact.Bytes()
+
+		// Bodies that aren't JSON are recorded as strings
+		var text string
+		if json.Unmarshal(body, &text) == nil && !strings.Contains(headerValue(i.Request.Headers, "Content-Type"), "json") {
+			body = []byte(text)
+		}
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(i.Request.Method), target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name := range i.Request.Headers {
+		req.Header.Set(name, headerValue(i.Request.Headers, name))
+	}
+
+	return req, nil
+}
+
+// mismatches compares the response with the expected response
+func (i interaction) mismatches(status int, header http.Header, body []byte) ([]string, error) {
+	var mismatches []string
+	if i.Response.Status != 0 && status != i.Response.Status {
+		mismatches = append(mismatches, fmt.Sprintf("status: expected %d but got %d", i.Response.Status, status))
+	}
+
+	headerRules, bodyRules, err := i.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(i.Response.Headers))
+	for name := range i.Response.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		expected := headerValue(i.Response.Headers, name)
+		actual, ok := header[http.CanonicalHeaderKey(name)]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("header %s: expected %q but it was missing", name, expected))
+		case headerRules[strings.ToLower(name)] != nil:
+			rules := dsl.MatchingRules{dsl.HeaderPath(name).String(): headerRules[strings.ToLower(name)]}
+			mismatches = append(mismatches, explain(rules, dsl.HeaderPath(name), jsonString(expected), jsonString(strings.Join(actual, ", ")))...)
+		case normaliseHeader(expected) != normaliseHeader(strings.Join(actual, ", ")):
+			mismatches = append(mismatches, fmt.Sprintf("header %s: expected %q but got %q", name, expected, strings.Join(actual, ", ")))
+		}
+	}
+
+	if len(i.Response.Body) == 0 || string(i.Response.Body) == "null" {
+		return mismatches, nil
+	}
+
+	var expected, actual interface{}
+	if err = json.Unmarshal(i.Response.Body, &expected); err != nil {
+		return nil, fmt.Errorf("unable to parse the expected body: %v", err)
+	}
+	if json.Unmarshal(body, &actual) != nil {
+		if text, ok := expected.(string); !ok || text != string(body) {
+			mismatches = append(mismatches, fmt.Sprintf("body: expected %s but got %q", i.Response.Body, body))
+		}
+		return mismatches, nil
+	}
+
+	mismatches = append(mismatches, explain(bodyRules, dsl.BodyPath(), i.Response.Body, body)...)
+	missing, err := missingValues(bodyRules, dsl.BodyPath(), expected, actual)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(mismatches, missing...), nil
+}
+
+// rules reads the response's header rules, keyed by lower case header name,
+// and its body rules, rooted at "$.body"
+func (i interaction) rules() (map[string]dsl.Rule, dsl.MatchingRules, error) {
+	headers := make(map[string]dsl.Rule)
+	body := dsl.MatchingRules{}
+
+	for category, raw := range i.Response.MatchingRules {
+		if category != "header" && category != "body" {
+			continue
+		}
+		var rules map[string]dsl.Rule
+		if err := json.Unmarshal(raw, &rules); err != nil {
+			return nil, nil, fmt.Errorf("invalid %s matching rules: %v", category, err)
+		}
+		for path, rule := range rules {
+			if category == "header" {
+				headers[strings.ToLower(path)] = rule
+			} else {
+				body["$.body"+strings.TrimPrefix(path, "$")] = rule
+			}
+		}
+	}
+
+	return headers, body, nil
+}
+
+// explain applies the rules to the actual value, returning the values that
+// don't satisfy them
+func explain(rules dsl.MatchingRules, root dsl.RulePath, expected []byte, actual []byte) []string {
+	explanations, err := dsl.ExplainRules(rules, root, expected, actual)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var mismatches []string
+	for _, explanation := range explanations {
+		if !explanation.Passed {
+			mismatches = append(mismatches, explanation.String())
+		}
+	}
+
+	return mismatches
+}
+
+// missingValues finds the expected keys missing from the actual value,
+// objects and arrays of the wrong kind, and arrays without a rule of the
+// wrong length, which aren't found walking the actual value
+func missingValues(rules dsl.MatchingRules, path dsl.RulePath, expected interface{}, actual interface{}) ([]string, error) {
+	var missing []string
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object", path)}, nil
+		}
+		keys := make([]string, 0, len(e))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := a[key]
+			if !ok {
+				missing = append(missing, fmt.Sprintf("%s: expected a value but it was missing", path.Key(key)))
+				continue
+			}
+			nested, err := missingValues(rules, path.Key(key), e[key], value)
+			if err != nil {
+				return nil, err
+			}
+			missing = append(missing, nested...)
+		}
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array", path)}, nil
+		}
+		_, hasRule, err := rules.Resolve(path)
+		if err != nil {
+			return nil, err
+		}
+		if !hasRule && len(a) != len(e) {
+			missing = append(missing, fmt.Sprintf("%s: expected %d elements but got %d", path, len(e), len(a)))
+		}
+		for n, value := range a {
+			if len(e) == 0 {
+				break
+			}
+			// Elements beyond the example are compared with its first element
+			example := e[0]
+			if n < len(e) {
+				example = e[n]
+			}
+			nested, err := missingValues(rules, path.Index(n), example, value)
+			if err != nil {
+				return nil, err
+			}
+			missing = append(missing, nested...)
+		}
+	}
+
+	return missing, nil
+}
+
+// headerValue returns the value of a header, whose values may be recorded
+// as a list
+func headerValue(headers map[string]interface{}, name string) string {
+	for key, value := range headers {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		if values, ok := value.([]interface{}); ok {
+			parts := make([]string, len(values))
+			for n, v := range values {
+				parts[n] = fmt.Sprint(v)
+			}
+			return strings.Join(parts, ", ")
+		}
+		return fmt.Sprint(value)
+	}
+
+	return ""
+}
+
+// normaliseHeader removes the whitespace around the values of a header, so
+// that "a,b" and "a, b" are equal
+func normaliseHeader(value string) string {
+	parts := strings.Split(value, ",")
+	for n, part := range parts {
+		parts[n] = strings.TrimSpace(part)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func jsonString(value string) []byte {
+	content, _ := json.Marshal(value)
+
+	return content
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+const ordersPact = `{
+  "consumer": {"name": "web"},
+  "provider": {"name": "orders"},
+  "interactions": [
+    {
+      "description": "a request for order 1",
+      "providerState": "order 1 exists",
+      "request": {"method": "GET", "path": "/orders/1", "query": "expand=items"},
+      "response": {
+        "status": 200,
+        "headers": {"Content-Type": "application/json"},
+        "body": {"id": 1, "status": "open", "items": [{"sku": "ABC"}]},
+        "matchingRules": {
+          "$.headers.Content-Type": {"match": "regex", "regex": "^application/json"},
+          "$.body.id": {"match": "type"},
+          "$.body.status": {"match": "regex", "regex": "^(open|closed)$"},
+          "$.body.items": {"min": 1, "match": "type"}
+        }
+      }
+    },
+    {
+      "description": "a request to create an order",
+      "request": {"method": "POST", "path": "/orders", "headers": {"Content-Type": "application/json"}, "body": {"sku": "ABC"}},
+      "response": {"status": 201}
+    }
+  ],
+  "metadata": {"pactSpecification": {"version": "2.0.0"}}
+}`
+
+func ordersHandler(orders map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/orders/1" && r.URL.Query().Get("expand") == "items":
+			order, ok := orders["1"]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			fmt.Fprint(w, order)
+		case r.Method == "POST" && r.URL.Path == "/orders":
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != `{"sku":"ABC"}` {
+				http.Error(w, "unexpected body "+string(body), http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func writePact(t *testing.T, dir string) string {
+	file := filepath.Join(dir, "web-orders.json")
+	if err := ioutil.WriteFile(file, []byte(ordersPact), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestTestServer_Verify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writePact(t, dir)
+
+	orders := map[string]string{}
+	server := NewTestServer(ordersHandler(orders), dir)
+	defer server.Close()
+
+	server.StateHandlers = types.StateHandlers{
+		"order 1 exists": func() error {
+			orders["1"] = `{"id": 42, "status": "closed", "items": [{"sku": "X"}, {"sku": "Y"}], "total": 10}`
+			return nil
+		},
+	}
+	server.Verify(t)
+}
+
+func TestInteraction_Mismatches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := readPact(writePact(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Interactions) != 2 || p.Interactions[0].ProviderStates[0].Name != "order 1 exists" {
+		t.Fatalf("unexpected interactions %+v", p.Interactions)
+	}
+
+	header := http.Header{"Content-Type": []string{"text/plain"}}
+	mismatches, err := p.Interactions[0].mismatches(404, header, []byte(`{"id": "42", "status": "lost", "items": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(mismatches, "\n")
+	for _, want := range []string{
+		"status: expected 200 but got 404",
+		`$.headers.Content-Type = "text/plain"`,
+		`$.body.id = "42": {"combine":"AND","matchers":[{"match":"type"}]} from $.body.id (weight 8): expected a number (1) but got a string ("42")`,
+		`$.body.status = "lost"`,
+		"expected at least 1 elements but got 0",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected the mismatches to contain %q, got\n%s", want, got)
+		}
+	}
+
+	mismatches, err = p.Interactions[0].mismatches(200, http.Header{"Content-Type": []string{"application/json"}}, []byte(`{"id": 1, "items": [{"name": "ABC"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = strings.Join(mismatches, "\n")
+	for _, want := range []string{
+		"$.body.status: expected a value but it was missing",
+		"$.body.items[0].sku: expected a value but it was missing",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected the mismatches to contain %q, got\n%s", want, got)
+		}
+	}
+}
+
+func TestInteraction_Request(t *testing.T) {
+	var i interaction
+	if err := json.Unmarshal([]byte(`{"request": {"method": "post", "path": "/notes", "query": {"tag": ["a", "b"]},
+		"headers": {"Content-Type": "text/plain"}, "body": "hello"}}`), &i); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := i.request("http://localhost:1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	if req.Method != "POST" || req.URL.String() != "http://localhost:1234/notes?tag=a&tag=b" || string(body) != "hello" {
+		t.Fatalf("unexpected request %s %s %s", req.Method, req.URL, body)
+	}
+}
@@ -32,6 +32,11 @@ type Options struct {
 	// TargetPath is the path on the target to proxy
 	TargetPath string
 
+	// TargetHost overrides the Host header and TLS server name (SNI) sent to
+	// the target, for when the address connected to differs from the virtual
+	// host the target routes by e.g. a pod IP. Defaults to TargetAddress
+	TargetHost string
+
 	// ProxyPort is the port to make available for proxying
 	// Defaults to a random port
 	ProxyPort int
@@ -45,6 +50,35 @@ type Options struct {
 	// Custom TLS Configuration for communicating with a Provider
 	// Useful when verifying self-signed services, MASSL etc.
 	CustomTLSConfig *tls.Config
+
+	// Transport tunes the connections made to the target, which are reused
+	// across requests
+	Transport TransportOptions
+
+	// OnTargetError is called when a request can't be sent to the target,
+	// e.g. the connection was refused, before responding 502 Bad Gateway
+	OnTargetError func(r *http.Request, err error)
+}
+
+// TransportOptions tunes the connections made to the target
+type TransportOptions struct {
+	// MaxIdleConns limits the idle (keep-alive) connections kept open to the
+	// target, for reuse by later requests. Defaults to 100
+	MaxIdleConns int
+
+	// MaxConnsPerHost limits the connections to the target, including those
+	// in use. Defaults to no limit
+	MaxConnsPerHost int
+
+	// IdleConnTimeout closes connections idle for longer than this.
+	// Defaults to 90s
+	IdleConnTimeout time.Duration
+
+	// DisableKeepAlives opens a new connection for each request
+	DisableKeepAlives bool
+
+	// DisableCompression stops requesting gzip compressed responses
+	DisableCompression bool
 }
 
 // loggingMiddleware logs requests to the proxy
@@ -83,8 +117,15 @@ func HTTPReverseProxy(options Options) (int, error) {
 		Path:   options.TargetPath,
 	}
 
-	proxy := createProxy(url, options.InternalRequestPathPrefix)
-	proxy.Transport = customTransport{tlsConfig: options.CustomTLSConfig}
+	proxy := createProxy(url, options.InternalRequestPathPrefix, options.TargetHost)
+	proxy.Transport = newCustomTransport(options.CustomTLSConfig, serverName(options.TargetHost), options.Transport)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if options.OnTargetError != nil {
+			options.OnTargetError(r, err)
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintf(w, "provider unreachable: %v", err)
+	}
 
 	if port == 0 {
 		port, err = utils.GetFreePort()
@@ -106,36 +147,57 @@ func HTTPReverseProxy(options Options) (int, error) {
 // Set the proxy.Transport field to an implementation that dumps the request before delegating to the default transport:
 
 type customTransport struct {
-	tlsConfig *tls.Config
+	tlsConfig  *tls.Config
+	serverName string
+
+	// transport is shared by every request, so that connections to the
+	// target are reused rather than opened for each interaction
+	transport *http.Transport
 }
 
-func (c customTransport) RoundTrip(r *http.Request) (*http.Response, error) {
-	b, err := httputil.DumpRequestOut(r, false)
-	if err != nil {
-		return nil, err
+func newCustomTransport(tlsConfig *tls.Config, serverName string, options TransportOptions) customTransport {
+	c := customTransport{tlsConfig: tlsConfig, serverName: serverName}
+
+	maxIdleConns := options.MaxIdleConns
+	if maxIdleConns == 0 {
+		maxIdleConns = 100
+	}
+	idleConnTimeout := options.IdleConnTimeout
+	if idleConnTimeout == 0 {
+		idleConnTimeout = 90 * time.Second
 	}
-	log.Println("[TRACE] proxy outgoing request\n", string(b))
 
-	transport := &http.Transport{
+	c.transport = &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
 			DualStack: true,
 		}).DialContext,
-		MaxIdleConns:          100,
-		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns: maxIdleConns,
+		// All requests go to the one target, so it may use every idle
+		// connection rather than the default of 2
+		MaxIdleConnsPerHost:   maxIdleConns,
+		MaxConnsPerHost:       options.MaxConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		DisableKeepAlives:     options.DisableKeepAlives,
+		DisableCompression:    options.DisableCompression,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       c.clientTLSConfig(),
 	}
 
-	if c.tlsConfig != nil {
-		log.Println("[DEBUG] applying custom TLS config")
-		transport.TLSClientConfig = c.tlsConfig
+	return c
+}
+
+func (c customTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	b, err := httputil.DumpRequestOut(r, false)
+	if err != nil {
+		return nil, err
 	}
-	var DefaultTransport http.RoundTripper = transport
+	log.Println("[TRACE] proxy outgoing request\n", string(b))
 
-	res, err := DefaultTransport.RoundTrip(r)
+	res, err := c.transport.RoundTrip(r)
 	if err != nil {
 		log.Println("[ERROR]", err)
 		return nil, err
@@ -146,16 +208,50 @@ func (c customTransport) RoundTrip(r *http.Request) (*http.Response, error) {
 	return res, err
 }
 
+// clientTLSConfig returns the TLS configuration for connecting to the target,
+// applying any server name override
+func (c customTransport) clientTLSConfig() *tls.Config {
+	if c.tlsConfig != nil {
+		log.Println("[DEBUG] applying custom TLS config")
+	}
+	if c.serverName == "" {
+		return c.tlsConfig
+	}
+
+	config := &tls.Config{}
+	if c.tlsConfig != nil {
+		config = c.tlsConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = c.serverName
+	}
+
+	return config
+}
+
+// serverName strips any port from the host, for use as a TLS server name
+func serverName(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+
+	return host
+}
+
 // Adapted from https://github.com/golang/go/blob/master/src/net/http/httputil/reverseproxy.go
-func createProxy(target *url.URL, ignorePrefix string) *httputil.ReverseProxy {
+func createProxy(target *url.URL, ignorePrefix string, hostOverride string) *httputil.ReverseProxy {
 	targetQuery := target.RawQuery
+	host := target.Host
+	if hostOverride != "" {
+		host = hostOverride
+	}
 	director := func(req *http.Request) {
 		if !strings.HasPrefix(req.URL.Path, ignorePrefix) {
 			log.Println("[DEBUG] setting proxy to target")
 			log.Println("[DEBUG] incoming request", req.URL)
 			req.URL.Scheme = target.Scheme
 			req.URL.Host = target.Host
-			req.Host = target.Host
+			req.Host = host
 
 			req.URL.Path = singleJoiningSlash(target.Path, req.URL.Path)
 			log.Println("[DEBUG] outgoing request to target", req.URL)
@@ -1,9 +1,15 @@
 package proxy
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sync"
 	"testing"
+	"time"
 )
 
 func dummyHandler(header string) http.HandlerFunc {
@@ -88,3 +94,116 @@ func TestHTTPReverseProxy(t *testing.T) {
 		t.Errorf("want non-zero port, got %v", port)
 	}
 }
+
+func TestCreateProxy_HostOverride(t *testing.T) {
+	target, _ := url.Parse("http://10.0.0.12:8080")
+
+	tests := map[string]string{
+		"":                "10.0.0.12:8080",
+		"api.example.com": "api.example.com",
+	}
+	for override, want := range tests {
+		req, _ := http.NewRequest("GET", "http://localhost:1234/users", nil)
+		createProxy(target, "/__setup", override).Director(req)
+
+		if req.Host != want {
+			t.Errorf("want Host %s, got %s", want, req.Host)
+		}
+		if req.URL.Host != "10.0.0.12:8080" {
+			t.Errorf("expected to connect to the target address, got %s", req.URL.Host)
+		}
+	}
+}
+
+func TestCustomTransport_ServerName(t *testing.T) {
+	var sni string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			sni = hello.ServerName
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	transport := newCustomTransport(&tls.Config{InsecureSkipVerify: true}, serverName("api.example.com:443"), TransportOptions{})
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	if sni != "api.example.com" {
+		t.Fatalf("want server name api.example.com, got %q", sni)
+	}
+}
+
+func TestCustomTransport_ReusesConnections(t *testing.T) {
+	tests := map[bool]int{false: 1, true: 3}
+	for disableKeepAlives, want := range tests {
+		var mu sync.Mutex
+		connections := 0
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				mu.Lock()
+				connections++
+				mu.Unlock()
+			}
+		}
+		server.Start()
+
+		transport := newCustomTransport(nil, "", TransportOptions{DisableKeepAlives: disableKeepAlives})
+		for i := 0; i < 3; i++ {
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res.Body.Close()
+		}
+		server.Close()
+
+		if connections != want {
+			t.Errorf("with DisableKeepAlives %v, want %d connections, got %d", disableKeepAlives, want, connections)
+		}
+	}
+}
+
+func TestHTTPReverseProxy_OnTargetError(t *testing.T) {
+	// Find a port with nothing listening on it
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	target := listener.Addr().String()
+	listener.Close()
+
+	errs := make(chan string, 1)
+	port, err := HTTPReverseProxy(Options{
+		TargetScheme:  "http",
+		TargetAddress: target,
+		OnTargetError: func(r *http.Request, err error) { errs <- r.Method + " " + r.URL.Path },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var res *http.Response
+	for i := 0; i < 50; i++ {
+		if res, err = http.Get(fmt.Sprintf("http://localhost:%d/users/1", port)); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusBadGateway {
+		t.Fatalf("want status 502, got %d", res.StatusCode)
+	}
+	if got := <-errs; got != "GET /users/1" {
+		t.Fatalf("expected the failed request to be reported, got %s", got)
+	}
+}
@@ -0,0 +1,299 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// now is the clock used to timestamp signatures, replaced in tests
+var now = time.Now
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+)
+
+// SigV4Config configures AWS Signature Version 4 request signing
+type SigV4Config struct {
+	// AccessKeyID, SecretAccessKey and (optionally) SessionToken are the
+	// credentials to sign requests with
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Region and Service form the credential scope e.g. "us-east-1" and
+	// "execute-api"
+	Region  string
+	Service string
+
+	// Host is the host the provider receives requests on e.g. localhost:8000.
+	// The verifier's proxy rewrites the Host header after the request filter
+	// runs, so this must be set when signing during provider verification.
+	// Defaults to the Host of the request.
+	Host string
+}
+
+// Sign signs the request, replacing any existing signature. The request body
+// is read and restored.
+func (c SigV4Config) Sign(r *http.Request) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	if c.Host != "" {
+		r.Host = c.Host
+	}
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	t := now().UTC()
+	r.Header.Del("Authorization")
+	r.Header.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+	r.Header.Del("X-Amz-Security-Token")
+	if c.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+
+	payloadHash := hashHex(body)
+	if c.Service == "s3" {
+		r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers, signedHeaders := canonicalHeaders(r.Header, host)
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI(r.URL, c.Service != "s3"),
+		canonicalQuery(r.URL.Query()),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := t.Format("20060102")
+	scope := strings.Join([]string{date, c.Region, c.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		t.Format(sigV4TimeFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := []byte("AWS4" + c.SecretAccessKey)
+	for _, part := range []string{date, c.Region, c.Service, "aws4_request"} {
+		key = hmacSum(sha256.New, key, []byte(part))
+	}
+	signature := hex.EncodeToString(hmacSum(sha256.New, key, []byte(stringToSign)))
+
+	r.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, c.AccessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+// SigV4 is a request filter that signs each request to the provider using
+// AWS Signature Version 4, replacing the (expired) signature recorded in
+// the pact file.
+func SigV4(config SigV4Config) Middleware {
+	return signingMiddleware(config.Sign)
+}
+
+// HMACConfig configures generic HMAC request signing, as used by many
+// webhook and partner APIs
+type HMACConfig struct {
+	// Secret is the shared key used to sign requests
+	Secret []byte
+
+	// Header to write the signature to. Defaults to "X-Signature"
+	Header string
+
+	// Prefix is prepended to the encoded signature e.g. "sha256="
+	Prefix string
+
+	// Hash constructs the hash to use. Defaults to sha256.New
+	Hash func() hash.Hash
+
+	// Message returns the content to sign. Defaults to the request body
+	Message func(r *http.Request, body []byte) []byte
+
+	// Encode encodes the signature. Defaults to hex.EncodeToString
+	Encode func([]byte) string
+}
+
+// Sign signs the request, replacing any existing signature. The request body
+// is read and restored.
+func (c HMACConfig) Sign(r *http.Request) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	header := c.Header
+	if header == "" {
+		header = "X-Signature"
+	}
+	newHash := c.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	encode := c.Encode
+	if encode == nil {
+		encode = hex.EncodeToString
+	}
+	message := body
+	if c.Message != nil {
+		message = c.Message(r, body)
+	}
+
+	r.Header.Set(header, c.Prefix+encode(hmacSum(newHash, c.Secret, message)))
+
+	return nil
+}
+
+// HMAC is a request filter that signs each request to the provider with a
+// keyed hash, replacing the signature recorded in the pact file.
+func HMAC(config HMACConfig) Middleware {
+	return signingMiddleware(config.Sign)
+}
+
+// signingMiddleware signs each request before passing it on, failing the
+// request if it can't be signed
+func signingMiddleware(sign func(*http.Request) error) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := sign(r); err != nil {
+				log.Println("[ERROR] unable to sign request:", err)
+				http.Error(w, fmt.Sprintf("unable to sign request: %v", err), http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readBody reads the request body, replacing it so it may be read again
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return []byte{}, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func hmacSum(newHash func() hash.Hash, key []byte, data []byte) []byte {
+	mac := hmac.New(newHash, key)
+	mac.Write(data) // nolint:errcheck
+
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalURI encodes each segment of the path, twice for all services
+// other than S3
+func canonicalURI(u *url.URL, doubleEncode bool) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = awsURIEncode(segment)
+		if doubleEncode {
+			segment = awsURIEncode(segment)
+		}
+		segments[i] = segment
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery sorts and encodes the query string parameters
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, awsURIEncode(key)+"="+awsURIEncode(value))
+		}
+	}
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaders returns the canonical headers block and the list of
+// signed headers. The host, content type and any x-amz-* headers are signed.
+func canonicalHeaders(header http.Header, host string) (string, string) {
+	values := map[string]string{"host": host}
+	for name, v := range header {
+		name = strings.ToLower(name)
+		if name != "content-type" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, len(v))
+		for i, value := range v {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(values[name])
+		b.WriteString("\n")
+	}
+
+	return b.String(), strings.Join(names, ";")
+}
+
+// awsURIEncode percent encodes everything but the unreserved characters
+// A-Z, a-z, 0-9, '-', '_', '.' and '~' as required by SigV4
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
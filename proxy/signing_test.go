@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigV4Config_Sign(t *testing.T) {
+	defer func() { now = time.Now }()
+	now = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+
+	// Example request from the AWS Signature Version 4 documentation
+	req, _ := http.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 stale")
+
+	config := SigV4Config{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "iam",
+	}
+	if err := config.Sign(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Fatalf("want %s, got %s", expected, got)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Fatalf("unexpected X-Amz-Date %s", got)
+	}
+}
+
+func TestSigV4Config_Host(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://localhost:1234/users", nil)
+	config := SigV4Config{Region: "us-east-1", Service: "execute-api", Host: "localhost:8000", SessionToken: "token"}
+
+	if err := config.Sign(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Host != "localhost:8000" {
+		t.Fatalf("expected the configured host to be used, got %s", req.Host)
+	}
+	if !strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Fatalf("expected the session token to be signed, got %s", req.Header.Get("Authorization"))
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	var signature, body string
+	handler := HMAC(HMACConfig{Secret: []byte("secret"), Header: "X-Hub-Signature-256", Prefix: "sha256="})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signature = r.Header.Get("X-Hub-Signature-256")
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+		}))
+
+	req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(`{"id":1}`))
+	req.Header.Set("X-Hub-Signature-256", "sha256=stale")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`{"id":1}`))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if signature != expected {
+		t.Fatalf("want %s, got %s", expected, signature)
+	}
+	if body != `{"id":1}` {
+		t.Fatalf("expected the body to be restored, got %q", body)
+	}
+}
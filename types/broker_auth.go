@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authenticator authenticates requests to a Pact Broker. BearerToken and
+// BasicAuth cover the broker's own schemes, implement it for others e.g. to
+// fetch a short lived token from a secrets manager.
+//
+// The CLI tools only support bearer tokens and basic authentication, so they
+// are given the Authorization header the Authenticator sets.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate calls f(req)
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken authenticates with a token e.g. a Pactflow API token
+type BearerToken string
+
+// Authenticate sets the Authorization header to the token
+func (t BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+
+	return nil
+}
+
+// BasicAuth authenticates with a username and password
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Authenticate sets the Authorization header to the username and password
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+
+	return nil
+}
+
+// brokerAuth returns the authenticator if given, otherwise one for the token
+// or the username and password. It returns nil if none are given.
+func brokerAuth(authenticator Authenticator, token string, username string, password string) Authenticator {
+	switch {
+	case authenticator != nil:
+		return authenticator
+	case token != "":
+		return BearerToken(token)
+	case username != "":
+		return BasicAuth{Username: username, Password: password}
+	}
+
+	return nil
+}
+
+// brokerCredentials returns the token, or username and password, that the
+// authenticator sets for requests to the broker
+func brokerCredentials(authenticator Authenticator, brokerURL string) (string, string, string, error) {
+	req, err := http.NewRequest("GET", brokerURL, nil)
+	if err != nil {
+		return "", "", "", err
+	}
+	if err = authenticator.Authenticate(req); err != nil {
+		return "", "", "", fmt.Errorf("unable to authenticate with the broker: %v", err)
+	}
+
+	header := req.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer "), "", "", nil
+	}
+	if username, password, ok := req.BasicAuth(); ok {
+		return "", username, password, nil
+	}
+
+	return "", "", "", fmt.Errorf("the broker authenticator must set a bearer token or basic authentication for the CLI tools, got an Authorization header of '%s'", strings.SplitN(header, " ", 2)[0])
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBrokerAuth(t *testing.T) {
+	custom := AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Token abc")
+		return nil
+	})
+
+	cases := map[string]struct {
+		request VerifyRequest
+		header  string
+	}{
+		"none":          {VerifyRequest{}, ""},
+		"token":         {VerifyRequest{BrokerToken: "abc", BrokerUsername: "user", BrokerPassword: "pass"}, "Bearer abc"},
+		"basic":         {VerifyRequest{BrokerUsername: "user", BrokerPassword: "pass"}, "Basic dXNlcjpwYXNz"},
+		"authenticator": {VerifyRequest{BrokerToken: "ignored", BrokerAuthenticator: custom}, "Token abc"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://broker", nil)
+			if auth := c.request.BrokerAuth(); auth != nil {
+				if err := auth.Authenticate(req); err != nil {
+					t.Fatal(err)
+				}
+			} else if c.header != "" {
+				t.Fatal("expected an authenticator")
+			}
+			if got := req.Header.Get("Authorization"); got != c.header {
+				t.Fatalf("expected the Authorization header %q, got %q", c.header, got)
+			}
+		})
+	}
+}
+
+func TestBrokerCredentials_CLIArgs(t *testing.T) {
+	verify := func(authenticator Authenticator) (string, error) {
+		v := VerifyRequest{ProviderBaseURL: "http://localhost:8080", BrokerURL: "http://broker", ProviderVersion: "1.0.0", BrokerAuthenticator: authenticator}
+		err := v.Validate()
+		return strings.Join(v.Args, " "), err
+	}
+
+	if args, err := verify(BearerToken("abc")); err != nil || !strings.Contains(args, "--broker-token abc") {
+		t.Fatalf("expected the bearer token to be passed to the CLI, got %q and %v", args, err)
+	}
+	if args, err := verify(BasicAuth{Username: "user", Password: "pass"}); err != nil || !strings.Contains(args, "--broker-username user --broker-password pass") {
+		t.Fatalf("expected the basic credentials to be passed to the CLI, got %q and %v", args, err)
+	}
+	if _, err := verify(AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Token abc")
+		return nil
+	})); err == nil || !strings.Contains(err.Error(), "got an Authorization header of 'Token'") {
+		t.Fatalf("expected an unsupported scheme to be rejected, got %v", err)
+	}
+	if _, err := verify(AuthenticatorFunc(func(*http.Request) error {
+		return errors.New("expired")
+	})); err == nil || !strings.Contains(err.Error(), "unable to authenticate with the broker: expired") {
+		t.Fatalf("expected the authenticator's error, got %v", err)
+	}
+
+	p := PublishRequest{PactURLs: []string{"broker_auth_test.go"}, PactBroker: "http://broker", ConsumerVersion: "1.0.0", BrokerAuthenticator: BearerToken("abc")}
+	if err := p.Validate(); err != nil || !strings.Contains(strings.Join(p.Args, " "), "--broker-token abc") {
+		t.Fatalf("expected the publisher to be given the token, got %v and %v", p.Args, err)
+	}
+}
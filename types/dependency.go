@@ -0,0 +1,29 @@
+package types
+
+import "time"
+
+// Dependency is a provider dependency (e.g. a database container) that is
+// started before, and stopped after, a verification suite. It integrates
+// naturally with libraries such as testcontainers-go.
+type Dependency struct {
+	// Name of the dependency, used in logs and errors
+	Name string
+
+	// Start the dependency. Required.
+	Start Hook
+
+	// Stop the dependency. Optional.
+	Stop Hook
+
+	// Ready is polled after the dependency is started until it returns no
+	// error, or the ReadyTimeout elapses. Optional.
+	Ready Hook
+
+	// ReadyTimeout is how long to wait for the dependency to become ready.
+	// Defaults to 30s
+	ReadyTimeout time.Duration
+
+	// ReadyInterval is how long to wait between ready checks.
+	// Defaults to 500ms
+	ReadyInterval time.Duration
+}
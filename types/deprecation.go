@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// Deprecation marks a provider endpoint, or a field of one, as deprecated.
+// Verification will warn when pacts still rely on it.
+type Deprecation struct {
+	// Method of the endpoint, empty to match any method
+	Method string
+
+	// Path of the endpoint, which may be a template e.g. "/accounts/{id}".
+	// Required.
+	Path string
+
+	// Field is an optional path to a field within the request or response
+	// body, e.g. "$.legacyId" or "$.items[*].code". If empty, the entire
+	// endpoint is deprecated.
+	Field string
+
+	// Reason is shown alongside the warning e.g. "use /v2/accounts instead"
+	Reason string
+
+	// FailAfter causes verification to fail, rather than warn, if the
+	// deprecation is still relied upon after the given date. Optional.
+	FailAfter time.Time
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FailureCategory is the cause of a failed interaction, separating genuine
+// contract breaks from infrastructure problems
+type FailureCategory string
+
+const (
+	// FailureMismatch is a response that doesn't match the pact, a genuine
+	// contract break
+	FailureMismatch FailureCategory = "mismatch"
+
+	// FailureProviderUnreachable is a request that couldn't be sent to the
+	// provider e.g. the connection was refused or timed out
+	FailureProviderUnreachable FailureCategory = "provider_unreachable"
+
+	// FailureStateSetup is a provider state that couldn't be set up
+	FailureStateSetup FailureCategory = "state_setup"
+
+	// FailurePactParse is a pact that couldn't be read
+	FailurePactParse FailureCategory = "pact_parse"
+
+	// FailureGenerator is a generated value (see pact specification version
+	// 3) that couldn't be generated
+	FailureGenerator FailureCategory = "generator"
+
+	// FailureError is any other error raised by the verifier
+	FailureError FailureCategory = "error"
+)
+
+var (
+	stateSetupErrorRegex  = regexp.MustCompile(`SetUpProviderStateError|Error setting up provider state`)
+	unreachableErrorRegex = regexp.MustCompile(`(?i)ECONNREFUSED|ECONNRESET|EHOSTUNREACH|connection refused|Failed to open TCP connection|ConnectionFailed|OpenTimeout|ReadTimeout|provider unreachable`)
+	pactParseErrorRegex   = regexp.MustCompile(`JSON::ParserError|UnrecognizePactFormatError|unexpected token|Error reading file|unable to parse pact`)
+	generatorErrorRegex   = regexp.MustCompile(`(?i)generator`)
+)
+
+// ClassifyFailure categorises a failure from the class and message of the
+// exception reported by the verifier. Failed expectations are mismatches,
+// and exceptions that aren't recognised are errors.
+func ClassifyFailure(class string, message string) FailureCategory {
+	if category, ok := classifyText(class + "\n" + message); ok {
+		return category
+	}
+	if class == "" || strings.Contains(class, "ExpectationNotMetError") {
+		return FailureMismatch
+	}
+
+	return FailureError
+}
+
+// ClassifyError categorises an error preventing verification, such as a pact
+// that couldn't be parsed
+func ClassifyError(err error) FailureCategory {
+	if category, ok := classifyText(err.Error()); ok {
+		return category
+	}
+
+	return FailureError
+}
+
+func classifyText(text string) (FailureCategory, bool) {
+	switch {
+	case stateSetupErrorRegex.MatchString(text):
+		return FailureStateSetup, true
+	case unreachableErrorRegex.MatchString(text):
+		return FailureProviderUnreachable, true
+	case pactParseErrorRegex.MatchString(text):
+		return FailurePactParse, true
+	case generatorErrorRegex.MatchString(text):
+		return FailureGenerator, true
+	}
+
+	return "", false
+}
+
+// Failure is a failed interaction of a provider verification
+type Failure struct {
+	// Description is the full description of the failed test
+	Description string `json:"description"`
+	Consumer    string `json:"consumer"`
+
+	Category FailureCategory `json:"category"`
+	Message  string          `json:"message,omitempty"`
+}
+
+// Failures returns each failed (or errored) interaction of the verification,
+// with the category of its failure
+func (r ProviderVerifierResponse) Failures() []Failure {
+	var failures []Failure
+
+	for _, example := range r.Examples {
+		if example.Status != "failed" && example.Status != "errored" {
+			continue
+		}
+
+		category := example.Category
+		if category == "" {
+			category = ClassifyFailure(example.Exception.Class, example.Exception.Message)
+		}
+		failures = append(failures, Failure{
+			Description: example.FullDescription,
+			Consumer:    example.Pact.ConsumerName,
+			Category:    category,
+			Message:     example.Exception.Message,
+		})
+	}
+
+	return failures
+}
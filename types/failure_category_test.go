@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		class    string
+		message  string
+		category FailureCategory
+	}{
+		{class: "RSpec::Expectations::ExpectationNotMetError", message: "expected: 200\n     got: 404", category: FailureMismatch},
+		{message: "Actual: {}\n\nDescription of differences", category: FailureMismatch},
+		{class: "Pact::ProviderVerifier::SetUpProviderStateError", message: "Error setting up provider state 'a user' for consumer 'web'", category: FailureStateSetup},
+		{class: "Errno::ECONNREFUSED", message: "Failed to open TCP connection to localhost:8080 (Connection refused)", category: FailureProviderUnreachable},
+		{class: "Net::ReadTimeout", message: "Net::ReadTimeout", category: FailureProviderUnreachable},
+		{class: "JSON::ParserError", message: "767: unexpected token at '{'", category: FailurePactParse},
+		{class: "Pact::UnknownGeneratorError", message: "unknown generator type 'Foo'", category: FailureGenerator},
+		{class: "NoMethodError", message: "undefined method `each' for nil:NilClass", category: FailureError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.category, ClassifyFailure(tt.class, tt.message), tt.class)
+	}
+
+	assert.Equal(t, FailurePactParse, ClassifyError(errors.New("error verifying provider: JSON::ParserError")))
+	assert.Equal(t, FailureError, ClassifyError(errors.New("error verifying provider: exit status 2")))
+}
+
+func TestProviderVerifierResponse_Failures(t *testing.T) {
+	content := `{"examples": [
+		{"status": "passed", "full_description": "a request returns a response which has status code 200"},
+		{"status": "failed", "full_description": "a request returns a response which has status code 200",
+		 "pact": {"consumer_name": "web"}, "exception": {"message": "expected: 200\n     got: 502"}, "category": "provider_unreachable"},
+		{"status": "errored", "full_description": "a request returns a response which has a matching body",
+		 "exception": {"class": "Pact::ProviderVerifier::SetUpProviderStateError", "message": "Error setting up provider state"}}
+	]}`
+	var response ProviderVerifierResponse
+	assert.NoError(t, json.Unmarshal([]byte(content), &response))
+
+	assert.Equal(t, []Failure{
+		{Description: "a request returns a response which has status code 200", Consumer: "web", Category: FailureProviderUnreachable, Message: "expected: 200\n     got: 502"},
+		{Description: "a request returns a response which has a matching body", Category: FailureStateSetup, Message: "Error setting up provider state"},
+	}, response.Failures())
+
+	result := NewVerificationResult([]ProviderVerifierResponse{response}, nil)
+	assert.Equal(t, map[FailureCategory]int{FailureProviderUnreachable: 1, FailureStateSetup: 1}, result.Categories)
+	assert.Empty(t, result.ErrorCategory)
+
+	result = NewVerificationResult(nil, errors.New("Failed to open TCP connection to broker:443"))
+	assert.Equal(t, FailureProviderUnreachable, result.ErrorCategory)
+}
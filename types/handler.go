@@ -7,6 +7,14 @@ type StateHandler func() error
 // StateHandlers is a list of StateHandler's
 type StateHandlers map[string]StateHandler
 
+// StateHandlerWithParams is a provider function that sets up a given state,
+// with the parameters given to the state by the consumer e.g. {"id": 10},
+// before the provider interaction is validated
+type StateHandlerWithParams func(params map[string]interface{}) error
+
+// StateHandlersWithParams is a list of StateHandlerWithParams's
+type StateHandlersWithParams map[string]StateHandlerWithParams
+
 // State specifies how the system should be configured when
 // verified. e.g. "user A exists"
 type State struct {
@@ -0,0 +1,346 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mismatch is a single difference between an expected and actual request or
+// response
+type Mismatch interface {
+	// Type is the kind of mismatch: "body", "header", "trailer", "status",
+	// "query" or "request"
+	Type() string
+
+	String() string
+}
+
+// BodyMismatch is a difference in a request or response body
+type BodyMismatch struct {
+	// Path is the JSON path of the difference e.g. "$.items[0].id"
+	Path string `json:"path"`
+
+	Expected interface{} `json:"expected,omitempty"`
+	Actual   interface{} `json:"actual,omitempty"`
+
+	// Rule is the matching rule that failed: "equality", "type" or "regex".
+	// Empty if unknown e.g. for a missing key
+	Rule string `json:"rule,omitempty"`
+
+	// Message describes the difference, as reported by the mock server or
+	// verifier
+	Message string `json:"message,omitempty"`
+}
+
+// Type is "body"
+func (m BodyMismatch) Type() string { return "body" }
+
+func (m BodyMismatch) String() string {
+	if m.Message != "" {
+		return fmt.Sprintf("body %s: %s", m.Path, m.Message)
+	}
+	return fmt.Sprintf("body %s: expected %v but got %v", m.Path, m.Expected, m.Actual)
+}
+
+// HeaderMismatch is a difference in a request or response header
+type HeaderMismatch struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+
+	// Rule is the matching rule that failed: "equality" or "regex"
+	Rule string `json:"rule,omitempty"`
+}
+
+// Type is "header"
+func (m HeaderMismatch) Type() string { return "header" }
+
+func (m HeaderMismatch) String() string {
+	return fmt.Sprintf("header %s: expected '%s' but got '%s'", m.Key, m.Expected, m.Actual)
+}
+
+// TrailerMismatch is a difference in a request or response trailer, a
+// header sent after the body
+type TrailerMismatch struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+
+	// Rule is the matching rule that failed: "equality" or "regex"
+	Rule string `json:"rule,omitempty"`
+}
+
+// Type is "trailer"
+func (m TrailerMismatch) Type() string { return "trailer" }
+
+func (m TrailerMismatch) String() string {
+	return fmt.Sprintf("trailer %s: expected '%s' but got '%s'", m.Key, m.Expected, m.Actual)
+}
+
+// StatusMismatch is a difference in a response status code
+type StatusMismatch struct {
+	Expected int `json:"expected"`
+	Actual   int `json:"actual"`
+}
+
+// Type is "status"
+func (m StatusMismatch) Type() string { return "status" }
+
+func (m StatusMismatch) String() string {
+	return fmt.Sprintf("status: expected %d but got %d", m.Expected, m.Actual)
+}
+
+// QueryMismatch is a difference in a request query string parameter
+type QueryMismatch struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// Type is "query"
+func (m QueryMismatch) Type() string { return "query" }
+
+func (m QueryMismatch) String() string {
+	return fmt.Sprintf("query %s: expected '%s' but got '%s'", m.Key, m.Expected, m.Actual)
+}
+
+// RequestMismatch is a request that no interaction expected, or an expected
+// request that was not received
+type RequestMismatch struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Type is "request"
+func (m RequestMismatch) Type() string { return "request" }
+
+func (m RequestMismatch) String() string {
+	return fmt.Sprintf("request %s %s: %s", m.Method, m.Path, m.Message)
+}
+
+// InteractionMismatches are the mismatches found for a single interaction
+type InteractionMismatches struct {
+	// Description is the description of the interaction, or for provider
+	// verification, the full description of the failed test
+	Description string
+
+	// Consumer is the name of the consumer, for provider verification
+	Consumer string
+
+	Mismatches []Mismatch
+}
+
+var (
+	statusDescriptionRegex = regexp.MustCompile(`has status code (\d+)`)
+	headerDescriptionRegex = regexp.MustCompile(`includes headers "([^"]+)" which (equals|matches) (.*)$`)
+	expectedGotRegex       = regexp.MustCompile(`(?s)expected:?\s*(.*?)\n\s*got:?\s*(.*?)(\n|$)`)
+	bodyDifferenceRegex    = regexp.MustCompile(`^\* (.*) at (\$\S*)$`)
+	expectedButGotRegex    = regexp.MustCompile(`^Expected (.+) but got (.+)$`)
+)
+
+// Mismatches returns the structured mismatches of each failed verification
+func (r ProviderVerifierResponse) Mismatches() []InteractionMismatches {
+	var results []InteractionMismatches
+
+	for _, example := range r.Examples {
+		if example.Status != "failed" {
+			continue
+		}
+
+		var mismatches []Mismatch
+		message := example.Exception.Message
+		switch {
+		case statusDescriptionRegex.MatchString(example.FullDescription):
+			expected, _ := strconv.Atoi(statusDescriptionRegex.FindStringSubmatch(example.FullDescription)[1])
+			actual := 0
+			if m := expectedGotRegex.FindStringSubmatch(message); m != nil {
+				actual, _ = strconv.Atoi(strings.TrimSpace(m[2]))
+			}
+			mismatches = append(mismatches, StatusMismatch{Expected: expected, Actual: actual})
+		case headerDescriptionRegex.MatchString(example.FullDescription):
+			m := headerDescriptionRegex.FindStringSubmatch(example.FullDescription)
+			mismatch := HeaderMismatch{Key: m[1], Expected: unquote(m[3]), Rule: "equality"}
+			if m[2] == "matches" {
+				mismatch.Rule = "regex"
+			}
+			if got := expectedGotRegex.FindStringSubmatch(message); got != nil {
+				mismatch.Actual = unquote(strings.TrimSpace(got[2]))
+			}
+			mismatches = append(mismatches, mismatch)
+		default:
+			mismatches = parseBodyDifferences(message)
+		}
+
+		results = append(results, InteractionMismatches{
+			Description: example.FullDescription,
+			Consumer:    example.Pact.ConsumerName,
+			Mismatches:  mismatches,
+		})
+	}
+
+	return results
+}
+
+// parseBodyDifferences parses the "Description of differences" reported by
+// the verifier for a body mismatch e.g.
+// `* Expected "Mary" but got "Joe" at $.name`
+func parseBodyDifferences(message string) []Mismatch {
+	var mismatches []Mismatch
+
+	for _, line := range strings.Split(message, "\n") {
+		m := bodyDifferenceRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		mismatch := BodyMismatch{Path: m[2], Message: m[1]}
+		if got := expectedButGotRegex.FindStringSubmatch(m[1]); got != nil {
+			mismatch.Expected = parseValue(got[1])
+			mismatch.Actual = parseValue(got[2])
+			switch {
+			case strings.Contains(got[1], " matching /"):
+				mismatch.Rule = "regex"
+			case strings.HasPrefix(got[1], "a ") || strings.HasPrefix(got[1], "an "):
+				mismatch.Rule = "type"
+			default:
+				mismatch.Rule = "equality"
+			}
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+
+	return mismatches
+}
+
+// ParseInteractionDiffs parses the mismatches from the body of the mock
+// server's response to a request that matched no interaction, which contains
+// the differences from each candidate interaction
+func ParseInteractionDiffs(body []byte) ([]InteractionMismatches, error) {
+	var response struct {
+		InteractionDiffs []map[string]interface{} `json:"interaction_diffs"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("unable to parse mock server response: %v", err)
+	}
+
+	results := make([]InteractionMismatches, 0, len(response.InteractionDiffs))
+	for _, diff := range response.InteractionDiffs {
+		result := InteractionMismatches{}
+		result.Description, _ = diff["description"].(string)
+
+		walkDiff(diff["body"], "$", func(path string, leaf map[string]interface{}) {
+			mismatch := BodyMismatch{Path: path, Expected: leaf["EXPECTED"], Actual: leaf["ACTUAL"], Rule: "equality"}
+			if expected, ok := leaf["EXPECTED_TO_MATCH"]; ok {
+				mismatch.Expected, mismatch.Rule = expected, "regex"
+			}
+			if expected, ok := leaf["EXPECTED_TYPE"]; ok {
+				mismatch.Expected, mismatch.Actual, mismatch.Rule = expected, leaf["ACTUAL_TYPE"], "type"
+			}
+			result.Mismatches = append(result.Mismatches, mismatch)
+		})
+
+		for _, key := range sortedKeys(diff["headers"]) {
+			walkDiff(diff["headers"].(map[string]interface{})[key], "", func(_ string, leaf map[string]interface{}) {
+				mismatch := HeaderMismatch{Key: key, Expected: diffString(leaf["EXPECTED"]), Actual: diffString(leaf["ACTUAL"]), Rule: "equality"}
+				if expected, ok := leaf["EXPECTED_TO_MATCH"]; ok {
+					mismatch.Expected, mismatch.Rule = diffString(expected), "regex"
+				}
+				result.Mismatches = append(result.Mismatches, mismatch)
+			})
+		}
+
+		for _, key := range sortedKeys(diff["query"]) {
+			walkDiff(diff["query"].(map[string]interface{})[key], "", func(_ string, leaf map[string]interface{}) {
+				expected := leaf["EXPECTED"]
+				if regex, ok := leaf["EXPECTED_TO_MATCH"]; ok {
+					expected = regex
+				}
+				result.Mismatches = append(result.Mismatches, QueryMismatch{Key: key, Expected: diffString(expected), Actual: diffString(leaf["ACTUAL"])})
+			})
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// walkDiff calls leaf for each difference in a mock server diff, with its
+// JSON path
+func walkDiff(diff interface{}, path string, leaf func(path string, diff map[string]interface{})) {
+	switch d := diff.(type) {
+	case map[string]interface{}:
+		if isDiffLeaf(d) {
+			leaf(path, d)
+			return
+		}
+		for _, key := range sortedKeys(d) {
+			walkDiff(d[key], path+"."+key, leaf)
+		}
+	case []interface{}:
+		for i, item := range d {
+			walkDiff(item, fmt.Sprintf("%s[%d]", path, i), leaf)
+		}
+	}
+}
+
+func isDiffLeaf(diff map[string]interface{}) bool {
+	for _, key := range []string{"EXPECTED", "ACTUAL", "EXPECTED_TO_MATCH", "EXPECTED_TYPE"} {
+		if _, ok := diff[key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(raw interface{}) []string {
+	m, _ := raw.(map[string]interface{})
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// diffString formats a header or query value from a mock server diff
+func diffString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parseValue decodes a value reported by the verifier as JSON, returning
+// the text unchanged if it isn't valid JSON (e.g. a description of a type)
+func parseValue(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		return value
+	}
+
+	return s
+}
+
+// unquote removes the quotes around a value reported by the verifier
+func unquote(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	if s == "nil" {
+		return ""
+	}
+
+	return s
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderVerifierResponse_Mismatches(t *testing.T) {
+	content := `{"examples": [
+		{"status": "passed", "full_description": "a request returns a response which has status code 200"},
+		{"status": "failed", "full_description": "a request returns a response which has status code 200",
+		 "pact": {"consumer_name": "web"}, "exception": {"message": "\nexpected: 200\n     got: 404\n\n(compared using eql?)\n"}},
+		{"status": "failed", "full_description": "a request returns a response which includes headers \"Content-Type\" which equals \"application/json\"",
+		 "exception": {"message": "\nexpected: \"application/json\"\n     got: \"text/plain\"\n"}},
+		{"status": "failed", "full_description": "a request returns a response which has a matching body",
+		 "exception": {"message": "Actual: {\"id\":\"x\"}\n\nDescription of differences\n--------------------------------------\n* Expected \"Mary\" but got \"Joe\" at $.name\n* Expected a Fixnum (like 1) but got a String (\"x\") at $.id\n* Could not find key \"age\" (keys present are: id, name) at $\n"}}
+	]}`
+	var response ProviderVerifierResponse
+	assert.NoError(t, json.Unmarshal([]byte(content), &response))
+
+	results := response.Mismatches()
+	assert.Len(t, results, 3)
+	assert.Equal(t, "web", results[0].Consumer)
+	assert.Equal(t, []Mismatch{StatusMismatch{Expected: 200, Actual: 404}}, results[0].Mismatches)
+	assert.Equal(t, []Mismatch{HeaderMismatch{Key: "Content-Type", Expected: "application/json", Actual: "text/plain", Rule: "equality"}}, results[1].Mismatches)
+	assert.Equal(t, []Mismatch{
+		BodyMismatch{Path: "$.name", Expected: "Mary", Actual: "Joe", Rule: "equality", Message: `Expected "Mary" but got "Joe"`},
+		BodyMismatch{Path: "$.id", Expected: "a Fixnum (like 1)", Actual: `a String ("x")`, Rule: "type", Message: `Expected a Fixnum (like 1) but got a String ("x")`},
+		BodyMismatch{Path: "$", Message: `Could not find key "age" (keys present are: id, name)`},
+	}, results[2].Mismatches)
+	assert.Equal(t, "body", results[2].Mismatches[0].Type())
+}
+
+func TestParseInteractionDiffs(t *testing.T) {
+	body := `{"message": "No interaction found for GET /users?page=2", "interaction_diffs": [{
+		"description": "a request for users",
+		"body": {"items": [{"id": {"EXPECTED_TYPE": "Integer", "ACTUAL_TYPE": "String"}}], "name": {"EXPECTED": "Mary", "ACTUAL": "Joe"}},
+		"headers": {"Authorization": {"EXPECTED_TO_MATCH": "/^Bearer /", "ACTUAL": "Basic x"}},
+		"query": {"page": [{"EXPECTED": "1", "ACTUAL": "2"}]}
+	}]}`
+
+	results, err := ParseInteractionDiffs([]byte(body))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "a request for users", results[0].Description)
+	assert.Equal(t, []Mismatch{
+		BodyMismatch{Path: "$.items[0].id", Expected: "Integer", Actual: "String", Rule: "type"},
+		BodyMismatch{Path: "$.name", Expected: "Mary", Actual: "Joe", Rule: "equality"},
+		HeaderMismatch{Key: "Authorization", Expected: "/^Bearer /", Actual: "Basic x", Rule: "regex"},
+		QueryMismatch{Key: "page", Expected: "1", Actual: "2"},
+	}, results[0].Mismatches)
+
+	_, err = ParseInteractionDiffs([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -7,6 +7,9 @@ type ProviderState struct {
 	Consumer string   `json:"consumer"`
 	State    string   `json:"state"`
 	States   []string `json:"states"`
+
+	// Params are the parameters of the state, in version 3 pacts
+	Params map[string]interface{} `json:"params,omitempty"`
 }
 
 // ProviderStates is a mapping of consumers to all known states. This is usually
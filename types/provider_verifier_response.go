@@ -19,12 +19,25 @@ type ProviderVerifierResponse struct {
 			ProviderName     string `json:"provider_name"`
 			URL              string `json:"url"`
 			ShortDescription string `json:"short_description"`
+
+			// Pending is set if the broker marked the pact as pending (see
+			// VerifyRequest.EnablePending): its failures are reported as
+			// pending, and don't fail the build.
+			Pending bool `json:"pending,omitempty"`
+
+			// WIP is set if the pact is a work in progress pact (see
+			// VerifyRequest.IncludeWIPPactsSince), which is also pending
+			WIP bool `json:"wip,omitempty"`
 		} `json:"pact"`
 		Exception struct {
 			Class     string   `json:"class"`
 			Message   string   `json:"message"`
 			Backtrace []string `json:"backtrace"`
 		} `json:"exception,omitempty"`
+
+		// Category is the cause of a failed example, set once verification
+		// completes. See Failures.
+		Category FailureCategory `json:"category,omitempty"`
 	} `json:"examples"`
 	Summary struct {
 		Duration                     float64 `json:"duration"`
@@ -22,13 +22,42 @@ type PublishRequest struct {
 	// BrokerToken is required when authenticating using the Bearer token mechanism
 	BrokerToken string
 
+	// BrokerAuthenticator authenticates requests to the broker in place of
+	// the token, or username and password. Optional.
+	BrokerAuthenticator Authenticator
+
 	// ConsumerVersion is the semantical version of the consumer API.
 	ConsumerVersion string
 
+	// Branch of the consumer version. Optional.
+	Branch string
+
+	// VersionDetection, if given, detects the ConsumerVersion and Branch
+	// from CI environment variables or git, unless they are given
+	// explicitly
+	VersionDetection *VersionDetection
+
 	// Tags help you organise your Pacts for different testing purposes.
 	// e.g. "production", "master" and "development" are some common examples.
 	Tags []string
 
+	// Consumer is the name of the application publishing the pacts. If given,
+	// publication fails if any pact is for a different consumer. Optional.
+	Consumer string
+
+	// SkipConsistencyChecks publishes all pacts as given. By default,
+	// publication fails if there is more than one pact file for a
+	// consumer/provider pair, or if a pact differs from the one already
+	// published for the ConsumerVersion, and pacts that are unchanged since
+	// they were last published are skipped. The ConsumerVersion (and its tags
+	// and branch) is not registered with the broker for skipped pacts, so set
+	// this to check it with can-i-deploy.
+	SkipConsistencyChecks bool
+
+	// SummaryFile is written with a JSON summary of the publication (see
+	// PublishResult), for CI pipelines to gate on. Optional.
+	SummaryFile string
+
 	// Verbose increases verbosity of output
 	// Deprecated
 	Verbose bool
@@ -38,6 +67,24 @@ type PublishRequest struct {
 	Args []string
 }
 
+// BrokerAuth returns the authenticator for requests to the broker: the
+// BrokerAuthenticator if given, otherwise one for the BrokerToken or the
+// BrokerUsername and BrokerPassword, or nil
+func (p *PublishRequest) BrokerAuth() Authenticator {
+	return brokerAuth(p.BrokerAuthenticator, p.BrokerToken, p.BrokerUsername, p.BrokerPassword)
+}
+
+// BrokerCredentials returns the token, or username and password, for the
+// CLI tools to authenticate with the broker, taking them from the
+// BrokerAuthenticator if it is given
+func (p *PublishRequest) BrokerCredentials() (token string, username string, password string, err error) {
+	if p.BrokerAuthenticator == nil {
+		return p.BrokerToken, p.BrokerUsername, p.BrokerPassword, nil
+	}
+
+	return brokerCredentials(p.BrokerAuthenticator, p.PactBroker)
+}
+
 // Validate checks that the minimum fields are provided.
 // Deprecated: This map be deleted after the native library replaces Ruby deps,
 // and should not be used outside of this library.
@@ -50,14 +97,6 @@ func (p *PublishRequest) Validate() error {
 		return fmt.Errorf("'PactURLs' is mandatory")
 	}
 
-	if p.BrokerUsername != "" {
-		p.Args = append(p.Args, "--broker-username", p.BrokerUsername)
-	}
-
-	if p.BrokerPassword != "" {
-		p.Args = append(p.Args, "--broker-password", p.BrokerPassword)
-	}
-
 	if p.PactBroker != "" && ((p.BrokerUsername == "" && p.BrokerPassword != "") || (p.BrokerUsername != "" && p.BrokerPassword == "")) {
 		return errors.New("both 'BrokerUsername' and 'BrokerPassword' must be supplied if one given")
 	}
@@ -65,10 +104,24 @@ func (p *PublishRequest) Validate() error {
 	if p.PactBroker == "" {
 		return fmt.Errorf("'PactBroker' is mandatory")
 	}
+
+	token, username, password, err := p.BrokerCredentials()
+	if err != nil {
+		return err
+	}
+
+	if username != "" {
+		p.Args = append(p.Args, "--broker-username", username)
+	}
+
+	if password != "" {
+		p.Args = append(p.Args, "--broker-password", password)
+	}
+
 	p.Args = append(p.Args, "--broker-base-url", p.PactBroker)
 
-	if p.BrokerToken != "" {
-		p.Args = append(p.Args, "--broker-token", p.BrokerToken)
+	if token != "" {
+		p.Args = append(p.Args, "--broker-token", token)
 	}
 
 	if p.ConsumerVersion == "" {
@@ -76,6 +129,10 @@ func (p *PublishRequest) Validate() error {
 	}
 	p.Args = append(p.Args, "--consumer-app-version", p.ConsumerVersion)
 
+	if p.Branch != "" {
+		p.Args = append(p.Args, "--branch", p.Branch)
+	}
+
 	if len(p.Tags) > 0 {
 		for _, t := range p.Tags {
 			p.Args = append(p.Args, "--tag", t)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -114,4 +115,18 @@ func TestPublishRequest_Validate(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error: %v", err)
 	}
+
+	p = PublishRequest{
+		PactBroker:      "http://foo.com",
+		PactURLs:        []string{testFile},
+		ConsumerVersion: "1.0.0",
+		Branch:          "main",
+	}
+
+	if err = p.Validate(); err != nil {
+		t.Fatalf("Expected no error but got '%s'", err.Error())
+	}
+	if args := strings.Join(p.Args, " "); !strings.Contains(args, "--branch main") {
+		t.Fatalf("Expected the branch to be published but got '%s'", args)
+	}
 }
@@ -0,0 +1,135 @@
+package types
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// Status is the overall outcome of a verification or publication, allowing
+// CI pipelines to branch without parsing logs
+type Status string
+
+const (
+	// StatusPassed is returned when all interactions were verified
+	StatusPassed Status = "passed"
+
+	// StatusFailed is returned when verification failed, or could not be run
+	StatusFailed Status = "failed"
+
+	// StatusFailedPendingOnly is returned when the only failures were of
+	// pending pacts, which don't fail the build
+	StatusFailedPendingOnly Status = "failed_pending_only"
+
+	// StatusNothingToVerify is returned when no pacts were found to verify
+	StatusNothingToVerify Status = "nothing_to_verify"
+
+	// StatusPublished is returned when pacts were published
+	StatusPublished Status = "published"
+
+	// StatusPublishSkipped is returned when no pacts were published, as
+	// they were unchanged since they were last published
+	StatusPublishSkipped Status = "publish_skipped"
+)
+
+// VerificationResult summarises a provider verification
+type VerificationResult struct {
+	Status       Status `json:"status"`
+	Pacts        int    `json:"pacts"`
+	Interactions int    `json:"interactions"`
+	Failures     int    `json:"failures"`
+	Pending      int    `json:"pending"`
+
+	// WIP counts the pending interactions of work in progress pacts, which
+	// are also counted in Pending
+	WIP int `json:"wip"`
+
+	// Errored interactions could not be verified, as their provider state
+	// could not be set up
+	Errored int `json:"errored"`
+
+	// Categories counts the failed and errored interactions by the cause of
+	// their failure, and ErrorCategory is the cause of Error
+	Categories    map[FailureCategory]int `json:"categories,omitempty"`
+	Error         string                  `json:"error,omitempty"`
+	ErrorCategory FailureCategory         `json:"errorCategory,omitempty"`
+}
+
+// NewVerificationResult summarises the output of a verification
+func NewVerificationResult(responses []ProviderVerifierResponse, err error) VerificationResult {
+	result := VerificationResult{Pacts: len(responses)}
+
+	for _, response := range responses {
+		for _, failure := range response.Failures() {
+			if result.Categories == nil {
+				result.Categories = make(map[FailureCategory]int)
+			}
+			result.Categories[failure.Category]++
+		}
+
+		for _, example := range response.Examples {
+			result.Interactions++
+			switch example.Status {
+			case "failed":
+				result.Failures++
+			case "errored":
+				result.Errored++
+			case "pending":
+				result.Pending++
+				if example.Pact.WIP {
+					result.WIP++
+				}
+			}
+		}
+	}
+
+	switch {
+	case result.Failures > 0, result.Errored > 0, err != nil:
+		result.Status = StatusFailed
+	case result.Pacts == 0:
+		result.Status = StatusNothingToVerify
+	case result.Pending > 0:
+		result.Status = StatusFailedPendingOnly
+	default:
+		result.Status = StatusPassed
+	}
+	if err != nil {
+		result.Error = err.Error()
+
+		// Failed interactions explain the error, otherwise verification
+		// couldn't run at all
+		if result.Failures == 0 && result.Errored == 0 {
+			result.ErrorCategory = ClassifyError(err)
+		}
+	}
+
+	return result
+}
+
+// WriteFile writes the result as JSON to the given file
+func (r VerificationResult) WriteFile(file string) error {
+	return writeResult(file, r)
+}
+
+// PublishResult summarises the publication of pacts to a broker
+type PublishResult struct {
+	Status Status `json:"status"`
+
+	// Published are the pact files sent to the broker
+	Published []string `json:"published"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// WriteFile writes the result as JSON to the given file
+func (r PublishResult) WriteFile(file string) error {
+	return writeResult(file, r)
+}
+
+func writeResult(file string, result interface{}) error {
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, content, 0644)
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func verifierResponse(t *testing.T, statuses ...string) ProviderVerifierResponse {
+	examples := make([]map[string]string, len(statuses))
+	for i, status := range statuses {
+		examples[i] = map[string]string{"status": status}
+	}
+	content, _ := json.Marshal(map[string]interface{}{"examples": examples})
+
+	var response ProviderVerifierResponse
+	if err := json.Unmarshal(content, &response); err != nil {
+		t.Fatal(err)
+	}
+
+	return response
+}
+
+func TestNewVerificationResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []ProviderVerifierResponse
+		err       error
+		want      Status
+	}{
+		{name: "passed", responses: []ProviderVerifierResponse{verifierResponse(t, "passed", "passed")}, want: StatusPassed},
+		{name: "failed", responses: []ProviderVerifierResponse{verifierResponse(t, "passed"), verifierResponse(t, "failed", "pending")}, err: errors.New("verification failed"), want: StatusFailed},
+		{name: "errored", responses: []ProviderVerifierResponse{verifierResponse(t, "passed", "errored")}, want: StatusFailed},
+		{name: "failed only pending", responses: []ProviderVerifierResponse{verifierResponse(t, "passed", "pending")}, want: StatusFailedPendingOnly},
+		{name: "nothing to verify", want: StatusNothingToVerify},
+		{name: "unable to verify", err: errors.New("no broker"), want: StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewVerificationResult(tt.responses, tt.err).Status)
+		})
+	}
+
+	result := NewVerificationResult([]ProviderVerifierResponse{verifierResponse(t, "passed", "failed", "pending", "errored")}, errors.New("boom"))
+	assert.Equal(t, VerificationResult{Status: StatusFailed, Pacts: 1, Interactions: 4, Failures: 1, Pending: 1, Errored: 1, Categories: map[FailureCategory]int{FailureMismatch: 2}, Error: "boom"}, result)
+}
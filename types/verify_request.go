@@ -20,6 +20,11 @@ type VerifyRequest struct {
 	// URL to hit during provider verification.
 	ProviderBaseURL string
 
+	// ProviderHost overrides the Host header and TLS server name (SNI) sent
+	// to the Provider API, when ProviderBaseURL is an address (e.g. a pod IP)
+	// that differs from the virtual host the provider routes by.
+	ProviderHost string
+
 	// Local/HTTP paths to Pact files.
 	// NOTE: if specified alongside BrokerURL it will run the verification once for
 	// each dynamic pact (Broker) discovered and user specified (URL) pact.
@@ -38,6 +43,18 @@ type VerifyRequest struct {
 	// Retrieve the latest pacts with this consumer version tag
 	Tags []string
 
+	// MetadataFilter only verifies the interactions whose metadata contains
+	// all of the given key/value pairs e.g. {"team": "payments"}. Only
+	// supported for local PactURLs.
+	MetadataFilter map[string]string
+
+	// InteractionTags only verifies the interactions tagged with any of the
+	// given tags (see Interaction.WithTags) e.g. {"smoke"}, and
+	// SkipInteractionTags skips those tagged with any of its tags e.g.
+	// {"slow"}. Only supported for local PactURLs.
+	InteractionTags     []string
+	SkipInteractionTags []string
+
 	// Tags to apply to the provider application version
 	ProviderTags []string
 
@@ -62,6 +79,10 @@ type VerifyRequest struct {
 	// BrokerToken is required when authenticating using the Bearer token mechanism
 	BrokerToken string
 
+	// BrokerAuthenticator authenticates requests to the broker in place of
+	// the token, or username and password. Optional.
+	BrokerAuthenticator Authenticator
+
 	// FailIfNoPactsFound configures the framework to return an error
 	// if no pacts were found when looking up from a broker
 	FailIfNoPactsFound bool
@@ -72,6 +93,11 @@ type VerifyRequest struct {
 	// ProviderVersion is the semantical version of the Provider API.
 	ProviderVersion string
 
+	// VersionDetection, if given, detects the ProviderVersion and
+	// ProviderBranch from CI environment variables or git, unless they are
+	// given explicitly
+	VersionDetection *VersionDetection
+
 	// CustomProviderHeaders are headers to add during pact verification `requests`.
 	// eg 'Authorization: Basic cGFjdDpwYWN0'.
 	//
@@ -87,6 +113,20 @@ type VerifyRequest struct {
 	// verification step.
 	StateHandlers StateHandlers
 
+	// StateHandlersWithParams set up the provider states given parameters by
+	// the consumer (see Interaction.GivenWithParams), and are called with
+	// the parameters. A state with handlers in both is set up by this one.
+	StateHandlersWithParams StateHandlersWithParams
+
+	// StateSetupRetries retries a failed provider state setup (a state
+	// handler error, or a 5xx response from ProviderStatesSetupURL) up to
+	// this many times, waiting StateSetupRetryDelay (default 1s) between
+	// attempts, e.g. when the setup races with database migrations.
+	// Interactions whose state could not be set up are reported with the
+	// status "errored", distinct from "failed" mismatches.
+	StateSetupRetries    int
+	StateSetupRetryDelay time.Duration
+
 	// BeforeEach allows you to configure your provider prior to the individual test execution
 	// e.g. setup temporary tokens, prepare data
 	BeforeEach Hook
@@ -107,10 +147,50 @@ type VerifyRequest struct {
 	// the Provider API. Useful for setting custom certificates, MASSL etc.
 	CustomTLSConfig *tls.Config
 
+	// Transport tunes the connections made to the Provider API, e.g. to
+	// limit them for a provider with a rate limiter. Connections are kept
+	// alive and reused across interactions by default.
+	Transport proxy.TransportOptions
+
+	// ClientCertFile and ClientKeyFile are a PEM encoded certificate and key
+	// presented to the Provider API for mutual TLS.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// ClientCertificate provides the client certificate for mutual TLS from
+	// memory (e.g. a secrets manager) instead of from files. It is called on
+	// each TLS handshake, so certificates may be rotated.
+	ClientCertificate func() (*tls.Certificate, error)
+
+	// Dependencies of the provider (e.g. databases, queues) that are started
+	// before, and stopped after, the verification suite. They are started in
+	// order and stopped in reverse order.
+	Dependencies []Dependency
+
+	// Deprecations are endpoints or fields that consumers should stop relying
+	// on. Local pact files still using them produce a warning, or an error if
+	// past the deprecation's FailAfter date.
+	Deprecations []Deprecation
+
+	// MaxPactAge warns when a local pact file was generated longer ago than
+	// this, highlighting consumers that may have been abandoned. Only pacts
+	// recording when they were generated (see Pact.RecordGeneration) are
+	// checked.
+	MaxPactAge time.Duration
+
+	// VerifyPactsIndividually runs the verifier once for each pact, rather
+	// than once for all of them, so that memory use stays flat however many
+	// pacts the broker returns. Progress is logged as each pact is verified.
+	// Pending and work in progress pacts are marked as such when the pacts
+	// are fetched. Not supported with ConsumerVersionSelectors, which rely on
+	// the broker selecting the pacts, or UseFFI.
+	VerifyPactsIndividually bool
+
 	// Allow pending pacts to be included in verification (see pact.io/pending)
 	EnablePending bool
 
-	// Pull in new WIP pacts from _any_ tag (see pact.io/wip)
+	// Pull in new WIP pacts from _any_ tag (see pact.io/wip). WIP pacts are
+	// verified as pending, and their examples marked WIP in the results.
 	IncludeWIPPactsSince *time.Time
 
 	// Specify an output directory to log all of the verification request/responses
@@ -118,6 +198,44 @@ type VerifyRequest struct {
 	// and API
 	PactLogDir string
 
+	// SummaryFile is written with a JSON summary of the verification (see
+	// VerificationResult), for CI pipelines to gate on. Optional.
+	SummaryFile string
+
+	// TriageDir is written with a triage bundle if verification fails, to
+	// attach to a ticket or reproduce the failures locally: a summary, each
+	// failure with the requests sent to the provider and its responses, the
+	// failing interactions of each pact, and the provider's logs. The bundle
+	// is written as a zip archive if TriageDir ends in ".zip". Optional.
+	TriageDir string
+
+	// ProviderLogs returns the provider's logs for the triage bundle, e.g.
+	// from a buffer the provider logs to during verification. Optional.
+	ProviderLogs func() ([]byte, error)
+
+	// SchemaDriftFile stores a fingerprint of the shape of the provider's
+	// JSON response to each interaction. Responses whose fields have been
+	// removed or changed type since the previous verification are reported,
+	// even if they still satisfy the pact. Optional.
+	SchemaDriftFile string
+
+	// FailOnSchemaDrift fails the interactions whose responses have drifted
+	// since the previous verification, see SchemaDriftFile
+	FailOnSchemaDrift bool
+
+	// UnicodeNormalization normalises the text of the provider's responses
+	// to a single Unicode normalisation form before they are checked, e.g.
+	// norm.NFC.String from golang.org/x/text/unicode/norm. Pacts should be
+	// written in the same form, see the consumer's UnicodeNormalization.
+	// Optional.
+	UnicodeNormalization func(string) string
+
+	// UseFFI verifies the pacts with the Rust pact core (libpact_ffi) instead
+	// of the Ruby verifier. Requires pact-go to be built with the pact_ffi tag
+	// (see package ffi). Results are not reported per interaction, so it
+	// can't be combined with VerifyPactsIndividually.
+	UseFFI bool
+
 	// Specify the log verbosity of the CLI verifier process spawned through verification
 	// Useful for debugging issues with the framework itself
 	PactLogLevel string
@@ -131,6 +249,24 @@ type VerifyRequest struct {
 	Args []string
 }
 
+// BrokerAuth returns the authenticator for requests to the broker: the
+// BrokerAuthenticator if given, otherwise one for the BrokerToken or the
+// BrokerUsername and BrokerPassword, or nil
+func (v *VerifyRequest) BrokerAuth() Authenticator {
+	return brokerAuth(v.BrokerAuthenticator, v.BrokerToken, v.BrokerUsername, v.BrokerPassword)
+}
+
+// BrokerCredentials returns the token, or username and password, for the
+// CLI tools to authenticate with the broker, taking them from the
+// BrokerAuthenticator if it is given
+func (v *VerifyRequest) BrokerCredentials() (token string, username string, password string, err error) {
+	if v.BrokerAuthenticator == nil {
+		return v.BrokerToken, v.BrokerUsername, v.BrokerPassword, nil
+	}
+
+	return brokerCredentials(v.BrokerAuthenticator, v.BrokerURL)
+}
+
 // Validate checks that the minimum fields are provided.
 // Deprecated: This map be deleted after the native library replaces Ruby deps,
 // and should not be used outside of this library.
@@ -178,24 +314,29 @@ func (v *VerifyRequest) Validate() error {
 		v.Args = append(v.Args, "--provider-states-setup-url", v.ProviderStatesSetupURL)
 	}
 
-	if v.BrokerUsername != "" {
-		v.Args = append(v.Args, "--broker-username", v.BrokerUsername)
+	if v.BrokerURL != "" && ((v.BrokerUsername == "" && v.BrokerPassword != "") || (v.BrokerUsername != "" && v.BrokerPassword == "")) {
+		return errors.New("both 'BrokerUsername' and 'BrokerPassword' must be supplied if one given")
+	}
+
+	token, username, password, err := v.BrokerCredentials()
+	if err != nil {
+		return err
 	}
 
-	if v.BrokerPassword != "" {
-		v.Args = append(v.Args, "--broker-password", v.BrokerPassword)
+	if username != "" {
+		v.Args = append(v.Args, "--broker-username", username)
 	}
 
-	if v.BrokerURL != "" && ((v.BrokerUsername == "" && v.BrokerPassword != "") || (v.BrokerUsername != "" && v.BrokerPassword == "")) {
-		return errors.New("both 'BrokerUsername' and 'BrokerPassword' must be supplied if one given")
+	if password != "" {
+		v.Args = append(v.Args, "--broker-password", password)
 	}
 
 	if v.BrokerURL != "" {
 		v.Args = append(v.Args, "--pact-broker-base-url", v.BrokerURL)
 	}
 
-	if v.BrokerToken != "" {
-		v.Args = append(v.Args, "--broker-token", v.BrokerToken)
+	if token != "" {
+		v.Args = append(v.Args, "--broker-token", token)
 	}
 
 	if v.BrokerURL != "" && v.ProviderVersion == "" {
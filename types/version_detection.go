@@ -0,0 +1,26 @@
+package types
+
+// VersionDetection detects the version and branch of the application being
+// built, when they are not given explicitly, from the environment variables
+// set by common CI systems or else the local git repository.
+type VersionDetection struct {
+	// Describe uses the output of `git describe --tags --always` as the
+	// version (e.g. "v1.2.0-3-gabc1234") rather than the commit SHA
+	Describe bool
+
+	// VersionVariables and BranchVariables are environment variables
+	// checked for the version and branch before those of common CI systems
+	// (e.g. GITHUB_SHA and GITHUB_REF). Optional.
+	VersionVariables []string
+	BranchVariables  []string
+
+	// IncludeBranch appends the branch to the detected version as build
+	// metadata e.g. "0a1b2c3+main", so that builds of a commit on different
+	// branches publish different versions
+	IncludeBranch bool
+
+	// MarkDirty appends "dirty" to the detected version's build metadata
+	// e.g. "0a1b2c3+dirty" when the git working tree has uncommitted changes,
+	// as pacts generated from them may differ from those of the commit
+	MarkDirty bool
+}